@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/llm-testing/internal/kserve"
+)
+
+func newCleanupCmd() *cobra.Command {
+	var (
+		ttl       time.Duration
+		all       bool
+		dryRun    bool
+		inCluster bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "cleanup",
+		Short: "Tear down forgotten model deployments",
+		Long: `Tear down the KServe InferenceServices managed by llm-testing that are older
+than --ttl, or all of them with --all, so that forgotten deployments stop
+holding GPUs.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			namespace, _ := cmd.Flags().GetString("namespace")
+			kubeconfig, _ := cmd.Flags().GetString("kubeconfig")
+
+			manager, err := kserve.NewManager(namespace, kubeconfig, inCluster)
+			if err != nil {
+				return err
+			}
+
+			results, err := manager.Cleanup(cmd.Context(), kserve.CleanupOptions{TTL: ttl, All: all, DryRun: dryRun})
+			if err != nil {
+				return err
+			}
+
+			if len(results) == 0 {
+				fmt.Println("No InferenceServices to clean up.")
+				return nil
+			}
+
+			failed := 0
+			for _, r := range results {
+				switch {
+				case r.Error != "":
+					failed++
+					fmt.Printf("  - %s (age %s): failed: %s\n", r.Name, r.Age, r.Error)
+				case dryRun:
+					fmt.Printf("  - %s (age %s): would be deleted\n", r.Name, r.Age)
+				default:
+					fmt.Printf("  - %s (age %s): deleted\n", r.Name, r.Age)
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("failed to clean up %d of %d InferenceServices", failed, len(results))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().DurationVar(&ttl, "ttl", 24*time.Hour, "Tear down InferenceServices older than this")
+	cmd.Flags().BoolVar(&all, "all", false, "Tear down all managed InferenceServices regardless of age")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Only list the InferenceServices that would be torn down")
+	cmd.Flags().BoolVar(&inCluster, "in-cluster", false, "Use in-cluster Kubernetes authentication")
+
+	return cmd
+}
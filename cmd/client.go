@@ -1,23 +1,178 @@
 package cmd
 
 import (
+	"fmt"
+	"log/slog"
+	"net/url"
 	"os"
+	"time"
 
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/llm-testing/internal/identity"
 	"github.com/giantswarm/llm-testing/internal/llm"
 )
 
-// newLLMClientFromFlags creates an LLM client from common CLI flags.
-// It checks the endpoint and apiKey flags, falling back to the OPENAI_API_KEY
-// environment variable when no explicit key is provided.
-func newLLMClientFromFlags(endpoint, apiKey string) llm.Client {
-	var opts []llm.Option
+// newLLMClientFromFlags creates an LLM client from common CLI flags. The
+// provider is looked up in the provider registry; the endpoint, apiKey, and
+// apiKeyFile flags override its settings, otherwise the API key comes from
+// the provider's environment variable (e.g. OPENAI_API_KEY or
+// ANTHROPIC_API_KEY). Extra options, e.g. of debugLLMOptions, are applied
+// last.
+func newLLMClientFromFlags(providers *llm.Registry, provider, endpoint, apiKey, apiKeyFile string, extra ...llm.Option) (llm.Client, error) {
+	opts := extra
 	if endpoint != "" {
 		opts = append(opts, llm.WithBaseURL(endpoint))
 	}
 	if apiKey != "" {
 		opts = append(opts, llm.WithAPIKey(apiKey))
-	} else if envKey := os.Getenv("OPENAI_API_KEY"); envKey != "" {
-		opts = append(opts, llm.WithAPIKey(envKey))
 	}
-	return llm.NewOpenAIClient(opts...)
+	if apiKeyFile != "" {
+		opts = append(opts, llm.WithAPIKeyFile(apiKeyFile))
+	}
+	return providers.Client(provider, opts...)
+}
+
+// debugLLMOptions returns the client options of the --debug-llm flag: the
+// full payloads of requests are recorded in the run's transcripts.
+func debugLLMOptions(debug bool) []llm.Option {
+	if !debug {
+		return nil
+	}
+	return []llm.Option{llm.WithRequestLogger(llm.TranscriptLogger)}
+}
+
+// openLLMCache opens the response cache of the --llm-cache-dir flag. An
+// empty dir disables caching and returns a nil cache.
+func openLLMCache(dir string) (*llm.Cache, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	return llm.NewCache(dir)
+}
+
+// llmFixtureFlags are the --llm-record and --llm-replay flags, which record
+// the LLM responses of a command to a fixture file or replay them from one
+// without calling any API.
+type llmFixtureFlags struct {
+	record   string
+	replay   string
+	fixtures *llm.Fixtures
+}
+
+func (f *llmFixtureFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.record, "llm-record", "", "Record all LLM responses to this fixture file")
+	cmd.Flags().StringVar(&f.replay, "llm-replay", "", "Answer LLM requests from a fixture file written by --llm-record, without calling any API")
+}
+
+// open loads the fixtures to replay or starts a recording.
+func (f *llmFixtureFlags) open() error {
+	switch {
+	case f.record != "" && f.replay != "":
+		return fmt.Errorf("--llm-record and --llm-replay are mutually exclusive")
+	case f.replay != "":
+		fixtures, err := llm.LoadFixtures(f.replay)
+		if err != nil {
+			return err
+		}
+		f.fixtures = fixtures
+	case f.record != "":
+		f.fixtures = llm.NewFixtures()
+	}
+	return nil
+}
+
+// wrap returns the client to use instead of client.
+func (f *llmFixtureFlags) wrap(client llm.Client) llm.Client {
+	switch {
+	case f.fixtures == nil:
+		return client
+	case f.replay != "":
+		return f.fixtures.Replay()
+	default:
+		return f.fixtures.Record(client)
+	}
+}
+
+// save writes a recording; responses recorded before a failure are kept.
+func (f *llmFixtureFlags) save() {
+	if f.record == "" || f.fixtures == nil {
+		return
+	}
+	if err := f.fixtures.Save(f.record); err != nil {
+		slog.Warn("failed to save LLM fixtures", "path", f.record, "error", err)
+		return
+	}
+	fmt.Printf("Recorded %d LLM responses to %s\n", f.fixtures.Len(), f.record)
+}
+
+// llmHTTPFlags are the --llm-timeout, --llm-http-retries, and --llm-proxy
+// flags, which configure the HTTP requests of all LLM clients of a command.
+// They override the settings of the provider registry.
+type llmHTTPFlags struct {
+	timeout time.Duration
+	retries int
+	backoff time.Duration
+	proxy   string
+}
+
+func (f *llmHTTPFlags) register(cmd *cobra.Command) {
+	cmd.Flags().DurationVar(&f.timeout, "llm-timeout", 0, "Time limit of each LLM request including its response, e.g. 5m; 0 leaves it to the provider's timeout or the run's")
+	cmd.Flags().IntVar(&f.retries, "llm-http-retries", 0, "Retries of LLM requests failing with a network error, 429, or a 5xx status, with exponential backoff and Retry-After")
+	cmd.Flags().DurationVar(&f.backoff, "llm-retry-backoff", llm.DefaultRetryBackoff, "Wait before the first retry of --llm-http-retries, doubled after each")
+	cmd.Flags().StringVar(&f.proxy, "llm-proxy", "", "HTTP(S) proxy URL for LLM requests, instead of HTTP_PROXY and HTTPS_PROXY")
+}
+
+// options returns the client options of the flags that are set.
+func (f *llmHTTPFlags) options() ([]llm.Option, error) {
+	var opts []llm.Option
+	if f.timeout < 0 || f.retries < 0 || f.backoff < 0 {
+		return nil, fmt.Errorf("--llm-timeout, --llm-http-retries, and --llm-retry-backoff must not be negative")
+	}
+	if f.timeout > 0 {
+		opts = append(opts, llm.WithHTTPTimeout(f.timeout))
+	}
+	if f.retries > 0 {
+		opts = append(opts, llm.WithRetry(f.retries, f.backoff))
+	}
+	if f.proxy != "" {
+		proxy, err := url.Parse(f.proxy)
+		if err != nil || proxy.Host == "" || (proxy.Scheme != "http" && proxy.Scheme != "https") {
+			return nil, fmt.Errorf("invalid --llm-proxy %q: expected an http:// or https:// URL", f.proxy)
+		}
+		opts = append(opts, llm.WithProxy(proxy))
+	}
+	return opts, nil
+}
+
+// circuitBreakerFlags are the --circuit-breaker flags, which make the
+// requests to a dead endpoint fail fast instead of each timing out.
+type circuitBreakerFlags struct {
+	failures int
+	cooldown time.Duration
+}
+
+func (f *circuitBreakerFlags) register(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&f.failures, "circuit-breaker", 5, "Consecutive endpoint failures (timeouts, 5xx, connection errors) after which requests to the tested model fail fast for --circuit-breaker-cooldown; 0 disables")
+	cmd.Flags().DurationVar(&f.cooldown, "circuit-breaker-cooldown", time.Minute, "How long requests fail fast once the circuit breaker opens, before the endpoint is tried again")
+}
+
+// wrap returns client behind a new circuit breaker.
+func (f *circuitBreakerFlags) wrap(client llm.Client) llm.Client {
+	return llm.NewCircuitBreaker(f.failures, f.cooldown).Wrap(client)
+}
+
+// loadProviderRegistry loads the LLM provider registry from an optional file
+// and the LLM_PROVIDER_* environment variables.
+func loadProviderRegistry(path string) (*llm.Registry, error) {
+	return llm.LoadRegistry(path, os.Environ())
+}
+
+// loadModelRegistry loads the model identity registry if a path is given.
+// A nil registry resolves every model name to itself.
+func loadModelRegistry(path string) (*identity.Registry, error) {
+	if path == "" {
+		return nil, nil
+	}
+	return identity.LoadRegistry(path)
 }
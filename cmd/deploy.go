@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/llm-testing/internal/kserve"
+)
+
+func newDeployCmd() *cobra.Command {
+	var (
+		modelURI      string
+		gpuCount      int
+		backend       string
+		ggufFile      string
+		runtime       string
+		runtimeArgs   []string
+		quantization  string
+		argsPreset    string
+		env           map[string]string
+		cpu           string
+		memory        string
+		hfTokenSecret string
+		rawDeployment bool
+		dryRun        bool
+		output        string
+		inCluster     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "deploy <model-name>",
+		Short: "Deploy a model as a KServe InferenceService",
+		Long: `Deploy a model as a KServe InferenceService and wait for it to become ready.
+
+With --dry-run, print the InferenceService manifest instead, for review or
+check-in to a GitOps repository, without touching the cluster.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			namespace, _ := cmd.Flags().GetString("namespace")
+			kubeconfig, _ := cmd.Flags().GetString("kubeconfig")
+
+			b, err := kserve.ParseBackend(backend)
+			if err != nil {
+				return err
+			}
+			cfg := kserve.DefaultModelConfig(args[0], modelURI)
+			cfg.UseBackend(b)
+			if runtime != "" {
+				cfg.Runtime = runtime
+			}
+			cfg.GGUFFile = ggufFile
+			if cmd.Flags().Changed("gpu-count") {
+				cfg.GPUCount = gpuCount
+			}
+			if cpu != "" {
+				cfg.CPURequest = cpu
+			}
+			if memory != "" {
+				cfg.MemoryRequest = memory
+			}
+			cfg.RuntimeArgs = runtimeArgs
+			cfg.Quantization, cfg.ArgsPreset = quantization, argsPreset
+			cfg.Env = env
+			cfg.HFTokenSecret = hfTokenSecret
+			cfg.RawDeployment = rawDeployment
+
+			if dryRun {
+				data, err := kserve.RenderManifest(cfg, namespace, output)
+				if err != nil {
+					return err
+				}
+				_, err = os.Stdout.Write(data)
+				return err
+			}
+
+			manager, err := kserve.NewManager(namespace, kubeconfig, inCluster)
+			if err != nil {
+				return err
+			}
+			cfg.URLMode = kserve.DefaultURLMode(inCluster)
+			defer manager.StopPortForwards()
+
+			status, err := manager.Deploy(cmd.Context(), cfg)
+			if err != nil {
+				return err
+			}
+			data, err := json.MarshalIndent(status, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal status: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&modelURI, "model-uri", "", "Model storage URI: hf://<org>/<model>, pvc://<claim>/<path>, or s3://<bucket>/<path> (required)")
+	cmd.Flags().IntVar(&gpuCount, "gpu-count", 1, "Number of GPUs to request (none for the CPU backends)")
+	cmd.Flags().StringVar(&backend, "backend", string(kserve.BackendVLLM), "Model server: vllm, ollama, or llamacpp")
+	cmd.Flags().StringVar(&ggufFile, "gguf-file", "", "GGUF file of the hf:// repository to serve with ollama or llamacpp (default: the whole repository)")
+	cmd.Flags().StringVar(&runtime, "runtime", "", "KServe ServingRuntime to serve the model with (default: the backend's)")
+	cmd.Flags().StringArrayVar(&runtimeArgs, "runtime-arg", nil, "Additional runtime argument (repeatable, e.g. --runtime-arg=--max-model-len=4096)")
+	cmd.Flags().StringVar(&quantization, "quantization", "", "vLLM quantization preset: awq, gptq, fp8, or bitsandbytes")
+	cmd.Flags().StringVar(&argsPreset, "args-preset", "", "Curated vLLM runtime args: long-context, throughput, or low-latency")
+	cmd.Flags().StringToStringVar(&env, "env", nil, "Environment variables for the model container (e.g. VLLM_LOGGING_LEVEL=DEBUG)")
+	cmd.Flags().StringVar(&cpu, "cpu", "", "CPU request (e.g. 8)")
+	cmd.Flags().StringVar(&memory, "memory", "", "Memory request (e.g. 64Gi)")
+	cmd.Flags().StringVar(&hfTokenSecret, "hf-token-secret", "", "Kubernetes Secret with an HF_TOKEN key for downloading gated models")
+	cmd.Flags().BoolVar(&rawDeployment, "raw-deployment", false, "Deploy in KServe's RawDeployment mode instead of as a Knative service")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the InferenceService manifest instead of deploying it")
+	cmd.Flags().StringVarP(&output, "output", "o", "yaml", "Manifest format with --dry-run: yaml or json")
+	cmd.Flags().BoolVar(&inCluster, "in-cluster", false, "Use in-cluster Kubernetes authentication")
+	_ = cmd.MarkFlagRequired("model-uri")
+
+	return cmd
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/llm-testing/internal/generator"
+	"github.com/giantswarm/llm-testing/internal/llm"
+)
+
+func newGenerateSuiteCmd() *cobra.Command {
+	var (
+		model       string
+		endpoint    string
+		apiKey      string
+		apiKeyFile  string
+		provider    string
+		providers   string
+		temperature float64
+		perDocument int
+		outputDir   string
+		suiteName   string
+		description string
+		force       bool
+		fixtures    llmFixtureFlags
+		maxDocChars int
+		cacheDir    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "generate-suite <docs-dir-or-url>...",
+		Short: "Draft a test suite from reference documents using an LLM",
+		Long: `Draft questions and expected answers from reference documents to bootstrap
+a domain-specific test suite.
+
+Each argument is a directory, a file, or an http(s) URL. Directories are read
+recursively for markdown (.md, .markdown), text (.txt), and reStructuredText
+(.rst) files; a urls.txt file in a directory lists further URLs to fetch, one
+per line. Long documents are split into parts, and --questions-per-doc
+questions are requested per document part.
+
+The questions are written to questions.csv in the output directory, together
+with a config.yaml. The suite is a draft: review every question and expected
+answer, and run validate-suite, before using it.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if model == "" {
+				return fmt.Errorf("--model is required: specify the model drafting the questions")
+			}
+			if temperature < 0 {
+				return fmt.Errorf("--temperature must not be negative")
+			}
+
+			ctx := cmd.Context()
+			// The CLI fetches with the network access of its user, so
+			// documents on internal hosts are fine.
+			docs, err := generator.LoadDocuments(ctx, args, generator.FetchOptions{AllowPrivateNetworks: true})
+			if err != nil {
+				return err
+			}
+
+			registry, err := loadProviderRegistry(providers)
+			if err != nil {
+				return err
+			}
+			client, err := newLLMClientFromFlags(registry, provider, endpoint, apiKey, apiKeyFile)
+			if err != nil {
+				return err
+			}
+			cache, err := openLLMCache(cacheDir)
+			if err != nil {
+				return err
+			}
+			client = cache.Wrap(client, provider+"|"+endpoint)
+			if err := fixtures.open(); err != nil {
+				return err
+			}
+			defer fixtures.save()
+			client = fixtures.wrap(client)
+
+			g := generator.New(client, generator.Config{
+				Model:                model,
+				QuestionsPerDocument: perDocument,
+				MaxDocumentChars:     maxDocChars,
+				Temperature:          llm.Float64Ptr(temperature),
+			})
+			g.SetProgressFunc(func(completed, total int) {
+				fmt.Printf("\r  Drafting questions from document part %d/%d...", completed, total)
+			})
+
+			fmt.Printf("Documents: %d\n", len(docs))
+			result, err := g.Generate(ctx, docs)
+			fmt.Println()
+			if err != nil {
+				return err
+			}
+
+			if suiteName == "" {
+				suiteName = filepath.Base(outputDir)
+			}
+			info := generator.SuiteInfo{Name: suiteName, Description: description, Model: model, Sources: args}
+			if err := generator.WriteSuite(outputDir, info, result.Questions, force); err != nil {
+				return err
+			}
+
+			if len(result.Failed) > 0 {
+				names := make([]string, 0, len(result.Failed))
+				for name := range result.Failed {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				fmt.Printf("No questions drafted from %d document part(s):\n", len(names))
+				for _, name := range names {
+					fmt.Printf("  - %s: %s\n", name, result.Failed[name])
+				}
+			}
+			fmt.Printf("Drafted %d questions to %s\n", len(result.Questions), filepath.Join(outputDir, "questions.csv"))
+			fmt.Printf("Review the questions and run: llm-testing validate-suite %s\n", outputDir)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&model, "model", "", "Model drafting the questions (required)")
+	cmd.Flags().StringVar(&endpoint, "endpoint", "", "LLM API endpoint URL")
+	cmd.Flags().StringVar(&apiKey, "api-key", "", "API key (or set OPENAI_API_KEY, or the provider's API key variable)")
+	cmd.Flags().StringVar(&apiKeyFile, "api-key-file", "", "File holding the API key, e.g. a mounted Kubernetes Secret; re-read when it changes")
+	cmd.Flags().StringVar(&provider, "provider", llm.ProviderOpenAI, "Provider of the model: openai (OpenAI-compatible), anthropic, gemini, vertexai, azure, or a name from --providers")
+	cmd.Flags().StringVar(&providers, "providers", "", "Provider registry file naming providers with their type, endpoint, and API key variable")
+	fixtures.register(cmd)
+	cmd.Flags().StringVar(&cacheDir, "llm-cache-dir", "", "Cache the model's answers to requests with temperature 0 in this directory and reuse them on re-runs")
+	cmd.Flags().Float64Var(&temperature, "temperature", 0.0, "Temperature for generation")
+	cmd.Flags().IntVar(&perDocument, "questions-per-doc", generator.DefaultQuestionsPerDocument, "Questions to draft per document (part)")
+	cmd.Flags().IntVar(&maxDocChars, "max-doc-chars", generator.DefaultMaxDocumentChars, "Split documents into parts of at most this many characters")
+	cmd.Flags().StringVar(&outputDir, "output-dir", "", "Suite directory to write config.yaml and questions.csv to")
+	cmd.Flags().StringVar(&suiteName, "name", "", "Suite name (default: output directory name)")
+	cmd.Flags().StringVar(&description, "description", "", "Suite description")
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite an existing config.yaml and questions.csv")
+	_ = cmd.MarkFlagRequired("output-dir")
+
+	return cmd
+}
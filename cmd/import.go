@@ -0,0 +1,322 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/llm-testing/internal/importer"
+)
+
+func newImportCmd() *cobra.Command {
+	var (
+		format         string
+		separator      string
+		outputDir      string
+		suiteName      string
+		questionCol    string
+		answerCol      string
+		sectionCol     string
+		idCol          string
+		defaultSection string
+		hfConfig       string
+		hfSplit        string
+		limit          int
+		nonInteractive bool
+		force          bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "import <export-file>",
+		Short: "Import a question bank from an Anki, Quizlet, CSV, or benchmark export",
+		Long: `Convert a flashcard or quiz export, or a benchmark dataset, into a test suite.
+
+Supported formats:
+  - anki: "Notes in Plain Text" export (.txt)
+  - quizlet: export with tab-separated term and definition
+  - csv: delimited file with a header row
+  - mmlu: MMLU CSV file, or a directory of them, without a header row; the
+    section is the subject from the file name
+  - hf-dataset: HuggingFace dataset rows as JSON Lines or a JSON array, or
+    hf://<owner>/<dataset> to fetch them from the Hub (see --hf-config and
+    --hf-split)
+
+Multiple-choice rows get their options appended to the question, and the
+correct option, e.g. "B. Mitochondria", as the expected answer.
+
+The columns holding the question, expected answer, section, and ID are
+suggested from the column names and confirmed interactively. Use the column
+flags (name or 1-based index) or --non-interactive to skip the prompts.
+
+The questions are written to questions.csv in the output directory, together
+with a config.yaml template if the directory has none yet.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := importer.ValidateFormat(format); err != nil {
+				return err
+			}
+			opts := importer.Options{}
+			if separator != "" {
+				sep := []rune(strings.ReplaceAll(separator, `\t`, "\t"))
+				if len(sep) != 1 {
+					return fmt.Errorf("separator must be a single character")
+				}
+				opts.Separator = sep[0]
+			}
+
+			var table *importer.Table
+			var err error
+			if format == importer.FormatHFDataset && strings.HasPrefix(args[0], importer.HFDatasetPrefix) {
+				table, err = importer.FetchHFDataset(cmd.Context(), args[0], hfConfig, hfSplit, limit)
+			} else {
+				table, err = readExport(args[0], format, opts)
+			}
+			if err != nil {
+				return err
+			}
+			if limit > 0 && len(table.Rows) > limit {
+				table.Rows = table.Rows[:limit]
+			}
+			if len(table.Rows) == 0 {
+				return fmt.Errorf("no rows found in %s", args[0])
+			}
+
+			mapping := importer.SuggestMapping(table.Header)
+			refs := []struct {
+				label    string
+				ref      string
+				target   *int
+				optional bool
+			}{
+				{"Question", questionCol, &mapping.Question, false},
+				{"Expected answer", answerCol, &mapping.Answer, false},
+				{"Section", sectionCol, &mapping.Section, true},
+				{"ID", idCol, &mapping.ID, true},
+			}
+
+			out := cmd.OutOrStdout()
+			in := bufio.NewReader(cmd.InOrStdin())
+			interactive := !nonInteractive
+			if interactive {
+				printColumns(out, table)
+			}
+			for _, r := range refs {
+				if r.ref != "" {
+					idx, err := importer.Column(table.Header, r.ref)
+					if err != nil {
+						return fmt.Errorf("%s column: %w", strings.ToLower(r.label), err)
+					}
+					*r.target = idx
+					continue
+				}
+				if interactive {
+					idx, err := promptColumn(in, out, table.Header, r.label, *r.target, r.optional)
+					if err != nil {
+						return err
+					}
+					*r.target = idx
+				}
+			}
+
+			if suiteName == "" {
+				suiteName = filepath.Base(outputDir)
+			}
+			if defaultSection == "" {
+				defaultSection = suiteName
+			}
+
+			questions, skipped, err := importer.Convert(table, mapping, importer.ConvertOptions{DefaultSection: defaultSection})
+			if err != nil {
+				return err
+			}
+			if len(questions) == 0 {
+				return fmt.Errorf("no questions with both a question and an answer found")
+			}
+
+			if err := os.MkdirAll(outputDir, 0o755); err != nil {
+				return fmt.Errorf("failed to create output directory: %w", err)
+			}
+
+			questionsPath := filepath.Join(outputDir, "questions.csv")
+			if _, err := os.Stat(questionsPath); err == nil && !force {
+				return fmt.Errorf("%s already exists (use --force to overwrite)", questionsPath)
+			}
+			qf, err := os.Create(questionsPath)
+			if err != nil {
+				return fmt.Errorf("failed to create questions file: %w", err)
+			}
+			if err := importer.WriteQuestions(qf, questions); err != nil {
+				_ = qf.Close()
+				return err
+			}
+			if err := qf.Close(); err != nil {
+				return fmt.Errorf("failed to write questions file: %w", err)
+			}
+
+			configPath := filepath.Join(outputDir, "config.yaml")
+			if _, err := os.Stat(configPath); errors.Is(err, os.ErrNotExist) {
+				if err := os.WriteFile(configPath, []byte(suiteConfigTemplate(suiteName, table.MultipleChoice)), 0o644); err != nil {
+					return fmt.Errorf("failed to write config.yaml: %w", err)
+				}
+				_, _ = fmt.Fprintf(out, "Created %s; review the system prompt before running the suite.\n", configPath)
+			}
+
+			_, _ = fmt.Fprintf(out, "Imported %d questions to %s", len(questions), questionsPath)
+			if skipped > 0 {
+				_, _ = fmt.Fprintf(out, " (skipped %d rows without a question or answer)", skipped)
+			}
+			_, _ = fmt.Fprintln(out)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", importer.FormatCSV, "Export format: anki, quizlet, csv, mmlu, or hf-dataset")
+	cmd.Flags().StringVar(&separator, "separator", "", `Field separator, overriding the format default (e.g. ";" or "\t")`)
+	cmd.Flags().StringVar(&outputDir, "output-dir", "", "Suite directory to write questions.csv (and config.yaml) to")
+	cmd.Flags().StringVar(&suiteName, "name", "", "Suite name for a new config.yaml (default: output directory name)")
+	cmd.Flags().StringVar(&questionCol, "question-column", "", "Column holding the question (name or 1-based index)")
+	cmd.Flags().StringVar(&answerCol, "answer-column", "", "Column holding the expected answer (name or 1-based index)")
+	cmd.Flags().StringVar(&sectionCol, "section-column", "", "Column holding the section (name or 1-based index)")
+	cmd.Flags().StringVar(&idCol, "id-column", "", "Column holding the question ID (name or 1-based index; default: sequential)")
+	cmd.Flags().StringVar(&defaultSection, "section", "", "Section for questions without one (default: suite name)")
+	cmd.Flags().StringVar(&hfConfig, "hf-config", "default", "Dataset config (subset) to fetch from the HuggingFace Hub, e.g. an MMLU subject")
+	cmd.Flags().StringVar(&hfSplit, "hf-split", "test", "Dataset split to fetch from the HuggingFace Hub")
+	cmd.Flags().IntVar(&limit, "limit", 0, "Import at most this many rows (0 for all)")
+	cmd.Flags().BoolVar(&nonInteractive, "non-interactive", false, "Use the suggested column mapping without prompting")
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite an existing questions.csv")
+	_ = cmd.MarkFlagRequired("output-dir")
+
+	return cmd
+}
+
+// readExport reads an export file. For the mmlu format, path may also be a
+// directory, whose CSV files are read in name order with each file's subject
+// as the section.
+func readExport(path, format string, opts importer.Options) (*importer.Table, error) {
+	paths := []string{path}
+	if format == importer.FormatMMLU {
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			paths, err = filepath.Glob(filepath.Join(path, "*.csv"))
+			if err != nil {
+				return nil, fmt.Errorf("failed to list %s: %w", path, err)
+			}
+			if len(paths) == 0 {
+				return nil, fmt.Errorf("no CSV files found in %s", path)
+			}
+		}
+	}
+
+	var table *importer.Table
+	for _, p := range paths {
+		if format == importer.FormatMMLU {
+			opts.Section = importer.MMLUSubject(p)
+		}
+		t, err := readExportFile(p, format, opts)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		if table == nil {
+			table = t
+		} else {
+			table.Rows = append(table.Rows, t.Rows...)
+		}
+	}
+	return table, nil
+}
+
+func readExportFile(path, format string, opts importer.Options) (*importer.Table, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open export: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	return importer.Read(f, format, opts)
+}
+
+// printColumns lists the columns of the export with a sample value.
+func printColumns(out io.Writer, table *importer.Table) {
+	_, _ = fmt.Fprintf(out, "Columns (%d rows):\n", len(table.Rows))
+	for i, col := range table.Header {
+		sample := ""
+		if i < len(table.Rows[0]) {
+			sample = table.Rows[0][i]
+		}
+		if len(sample) > 60 {
+			sample = sample[:57] + "..."
+		}
+		_, _ = fmt.Fprintf(out, "  %d. %s: %q\n", i+1, col, sample)
+	}
+	_, _ = fmt.Fprintln(out)
+}
+
+// promptColumn asks for the column of a field, defaulting to the suggestion.
+// Optional fields accept "-" to leave them unmapped.
+func promptColumn(in *bufio.Reader, out io.Writer, header []string, label string, suggested int, optional bool) (int, error) {
+	for {
+		def := "-"
+		if suggested >= 0 {
+			def = fmt.Sprintf("%d", suggested+1)
+		}
+		hint := ""
+		if optional {
+			hint = ", - for none"
+		}
+		_, _ = fmt.Fprintf(out, "%s column [%s%s]: ", label, def, hint)
+
+		line, err := in.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return -1, fmt.Errorf("failed to read input: %w", err)
+		}
+		answer := strings.TrimSpace(line)
+
+		switch {
+		case answer == "":
+			if suggested < 0 && !optional {
+				if errors.Is(err, io.EOF) {
+					return -1, fmt.Errorf("%s column is required", strings.ToLower(label))
+				}
+				continue
+			}
+			return suggested, nil
+		case answer == "-" && optional:
+			return -1, nil
+		}
+
+		idx, colErr := importer.Column(header, answer)
+		if colErr == nil {
+			return idx, nil
+		}
+		_, _ = fmt.Fprintln(out, colErr)
+		if errors.Is(err, io.EOF) {
+			return -1, colErr
+		}
+	}
+}
+
+// suiteConfigTemplate returns a config.yaml for an imported suite. Suites of
+// multiple-choice questions ask for the correct option.
+func suiteConfigTemplate(name string, multipleChoice bool) string {
+	instruction := "Answer each question accurately and concisely."
+	if multipleChoice {
+		instruction = "Answer each question with the letter and text of the correct option."
+	}
+	return fmt.Sprintf(`# Test Suite Configuration (imported)
+
+name: %q
+description: "Imported question bank"
+version: "1"
+strategy: "qa"
+questions_file: "questions.csv"
+
+prompt:
+  role: "assistant"
+  system_message: |
+    You are an expert assistant. %s
+`, name, instruction)
+}
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -9,12 +11,17 @@ import (
 )
 
 func newListCmd() *cobra.Command {
-	var suitesDir string
+	var suites suitesFlags
 
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List available test suites",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			suitesDir, err := suites.resolve(cmd.Context())
+			if err != nil {
+				return err
+			}
+
 			names, err := testsuite.List(suitesDir)
 			if err != nil {
 				return fmt.Errorf("failed to list test suites: %w", err)
@@ -36,14 +43,27 @@ func newListCmd() *cobra.Command {
 				fmt.Printf("    Description: %s\n", suite.Description)
 				fmt.Printf("    Version: %s\n", suite.Version)
 				fmt.Printf("    Strategy: %s\n", suite.Strategy)
-				fmt.Printf("    Questions: %d\n\n", len(suite.Questions))
+				if profiles := suite.ProfileNames(); len(profiles) > 0 {
+					fmt.Printf("    Prompt profiles: %s\n", strings.Join(profiles, ", "))
+				}
+				fmt.Printf("    Questions: %d\n", len(suite.Questions))
+				stats := suite.Stats()
+				for _, section := range stats.Sections {
+					fmt.Printf("      %s: %d\n", section.Section, section.Questions)
+				}
+				fmt.Printf("    Mean length: question %d, expected answer %d characters\n", stats.MeanQuestionChars, stats.MeanAnswerChars)
+				fmt.Printf("    Estimated prompt tokens per model: %d\n", stats.PromptTokens)
+				if modified, err := testsuite.LastModified(name, suitesDir); err == nil && !modified.IsZero() {
+					fmt.Printf("    Last modified: %s\n", modified.Format(time.RFC3339))
+				}
+				fmt.Println()
 			}
 
 			return nil
 		},
 	}
 
-	cmd.Flags().StringVar(&suitesDir, "suites-dir", "", "External test suites directory")
+	suites.register(cmd)
 
 	return cmd
 }
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/llm-testing/internal/llm"
+)
+
+func newModelsCmd() *cobra.Command {
+	var (
+		endpoint  string
+		apiKey    string
+		keyFile   string
+		provider  string
+		providers string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "models",
+		Short: "List the models an LLM endpoint serves",
+		Long: `List the models an OpenAI-compatible endpoint serves through its /models API,
+e.g. to find the exact model name a vLLM server expects for --model.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			registry, err := loadProviderRegistry(providers)
+			if err != nil {
+				return err
+			}
+			client, err := newLLMClientFromFlags(registry, provider, endpoint, apiKey, keyFile)
+			if err != nil {
+				return err
+			}
+
+			models, err := llm.ListModels(cmd.Context(), client)
+			if err != nil {
+				return err
+			}
+			if len(models) == 0 {
+				fmt.Println("No models found.")
+				return nil
+			}
+			for _, m := range models {
+				fmt.Println(m)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&endpoint, "endpoint", "", "LLM API endpoint URL")
+	cmd.Flags().StringVar(&apiKey, "api-key", "", "API key (or set OPENAI_API_KEY, or the provider's API key variable)")
+	cmd.Flags().StringVar(&keyFile, "api-key-file", "", "File holding the API key, e.g. a mounted Kubernetes Secret; re-read when it changes")
+	cmd.Flags().StringVar(&provider, "provider", llm.ProviderOpenAI, "Provider of the endpoint: openai (OpenAI-compatible), azure, or a name from --providers")
+	cmd.Flags().StringVar(&providers, "providers", "", "Provider registry file naming providers with their type, endpoint, and API key variable")
+
+	return cmd
+}
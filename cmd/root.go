@@ -1,11 +1,15 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/llm-testing/internal/tracing"
 )
 
 var rootCmd = &cobra.Command{
@@ -51,6 +55,8 @@ func SetBuildInfo(commit, date string) {
 func Execute() {
 	rootCmd.SetVersionTemplate(`{{printf "llm-testing version %s\n" .Version}}`)
 
+	flushTraces := setupTracing()
+
 	// Default to the serve command when invoked without arguments.
 	// We use Run (not RunE) to print the help text directing the user to use
 	// an explicit subcommand, since the root command cannot parse serve-specific
@@ -61,16 +67,35 @@ func Execute() {
 		fmt.Fprintln(os.Stderr)
 		if err := serveCmd.RunE(serveCmd, args); err != nil {
 			slog.Error("serve failed", "error", err)
+			flushTraces()
 			os.Exit(1)
 		}
 	}
 
 	err := rootCmd.Execute()
+	flushTraces()
 	if err != nil {
 		os.Exit(1)
 	}
 }
 
+// setupTracing starts exporting traces if the OTEL_* environment variables
+// configure it, and returns a function that flushes the remaining spans.
+func setupTracing() func() {
+	shutdown, err := tracing.Setup(context.Background(), rootCmd.Version)
+	if err != nil {
+		slog.Warn("tracing disabled", "error", err)
+		return func() {}
+	}
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			slog.Warn("failed to flush traces", "error", err)
+		}
+	}
+}
+
 func init() {
 	serveCmd = newServeCmd()
 	rootCmd.AddCommand(newVersionCmd())
@@ -78,6 +103,13 @@ func init() {
 	rootCmd.AddCommand(newRunCmd())
 	rootCmd.AddCommand(newScoreCmd())
 	rootCmd.AddCommand(newListCmd())
+	rootCmd.AddCommand(newValidateSuiteCmd())
+	rootCmd.AddCommand(newModelsCmd())
+	rootCmd.AddCommand(newImportCmd())
+	rootCmd.AddCommand(newGenerateSuiteCmd())
+	rootCmd.AddCommand(newSuiteCmd())
+	rootCmd.AddCommand(newCleanupCmd())
+	rootCmd.AddCommand(newDeployCmd())
 
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Enable verbose output")
 	rootCmd.PersistentFlags().String("kubeconfig", "", "Path to kubeconfig file")
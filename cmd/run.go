@@ -2,13 +2,18 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/giantswarm/llm-testing/internal/llm"
 	"github.com/giantswarm/llm-testing/internal/runner"
+	"github.com/giantswarm/llm-testing/internal/scorer"
 	"github.com/giantswarm/llm-testing/internal/testsuite"
 )
 
@@ -17,10 +22,32 @@ func newRunCmd() *cobra.Command {
 		model       string
 		endpoint    string
 		apiKey      string
+		apiKeyFile  string
+		provider    string
+		providers   string
+		debugLLM    bool
+		cacheDir    string
+		fixtures    llmFixtureFlags
 		temperature float64
+		maxRetries  int
+		effort      string
+		extraParams string
+		breaker     circuitBreakerFlags
+		httpFlags   llmHTTPFlags
 		outputDir   string
-		suitesDir   string
+		suites      suitesFlags
+		tags        string
+		profile     string
+		noExamples  bool
+		blind       bool
 		timeout     time.Duration
+
+		judge           bool
+		scoringModel    string
+		scoringEndpoint string
+		scoringAPIKey   string
+		scoringKeyFile  string
+		scoringProvider string
 	)
 
 	cmd := &cobra.Command{
@@ -36,6 +63,18 @@ Results are written to the output directory as text files with a JSON metadata m
 			if model == "" {
 				return fmt.Errorf("--model is required: specify the model to test")
 			}
+			if temperature < 0 {
+				return fmt.Errorf("--temperature must not be negative")
+			}
+			if err := llm.ValidateReasoningEffort(effort); err != nil {
+				return err
+			}
+			var extra map[string]any
+			if extraParams != "" {
+				if err := json.Unmarshal([]byte(extraParams), &extra); err != nil {
+					return fmt.Errorf("invalid --extra-params JSON: %w", err)
+				}
+			}
 
 			ctx := cmd.Context()
 			if timeout > 0 {
@@ -46,15 +85,53 @@ Results are written to the output directory as text files with a JSON metadata m
 
 			suiteName := args[0]
 
+			suitesDir, err := suites.resolve(ctx)
+			if err != nil {
+				return err
+			}
 			suite, err := testsuite.Load(suiteName, suitesDir)
 			if err != nil {
 				return fmt.Errorf("failed to load test suite: %w", err)
 			}
+			if err := suite.SelectTags(testsuite.ParseTags(tags)); err != nil {
+				return err
+			}
+			if err := suite.SelectProfile(profile); err != nil {
+				return err
+			}
+			if noExamples {
+				suite.Examples = nil
+			}
+			if blind {
+				suite.Blind = true
+			}
 
-			models := []testsuite.Model{{Name: model, Temperature: temperature}}
+			models := []testsuite.Model{{Name: model, Temperature: temperature, MaxRetries: maxRetries, ReasoningEffort: effort, ExtraParams: extra}}
 
 			// Set up LLM client.
-			client := newLLMClientFromFlags(endpoint, apiKey)
+			registry, err := loadProviderRegistry(providers)
+			if err != nil {
+				return err
+			}
+			httpOpts, err := httpFlags.options()
+			if err != nil {
+				return err
+			}
+			llmOpts := append(debugLLMOptions(debugLLM), httpOpts...)
+			client, err := newLLMClientFromFlags(registry, provider, endpoint, apiKey, apiKeyFile, llmOpts...)
+			if err != nil {
+				return err
+			}
+			cache, err := openLLMCache(cacheDir)
+			if err != nil {
+				return err
+			}
+			client = cache.Wrap(breaker.wrap(client), provider+"|"+endpoint)
+			if err := fixtures.open(); err != nil {
+				return err
+			}
+			defer fixtures.save()
+			client = fixtures.wrap(client)
 
 			strategy, err := runner.GetStrategy(suite.Strategy)
 			if err != nil {
@@ -62,12 +139,39 @@ Results are written to the output directory as text files with a JSON metadata m
 			}
 
 			r := runner.NewRunner(client, strategy, outputDir)
+			r.SetTranscripts(debugLLM)
+			var liveScore testsuite.LiveScore
 			r.SetProgressFunc(func(modelName string, idx, total int) {
+				if judge {
+					fmt.Printf("\r  [%s] Processing question %d/%d (accuracy: %d/%d, %.1f%%)...",
+						modelName, idx, total, liveScore.Correct, liveScore.Judged, liveScore.Percent)
+					return
+				}
 				fmt.Printf("\r  [%s] Processing question %d/%d...", modelName, idx, total)
 			})
+			if judge {
+				judgeClient, err := newLLMClientFromFlags(registry, scoringProvider, scoringEndpoint, scoringAPIKey, scoringKeyFile, llmOpts...)
+				if err != nil {
+					return err
+				}
+				s := scorer.NewScorer(fixtures.wrap(judgeClient), scorer.Config{Model: scoringModel, Repetitions: 1})
+				r.SetJudgeFunc(s.JudgeResult)
+				r.SetScoreProgressFunc(func(_ string, score testsuite.LiveScore) {
+					liveScore = score
+				})
+			}
 
 			fmt.Printf("Test Suite: %s\n", suite.Name)
 			fmt.Printf("Description: %s\n", suite.Description)
+			if suite.Profile != "" {
+				fmt.Printf("Prompt profile: %s\n", suite.Profile)
+			}
+			if len(suite.Examples) > 0 {
+				fmt.Printf("Few-shot examples: %d\n", len(suite.Examples))
+			}
+			if suite.Blind {
+				fmt.Printf("Blind: expected answers are written to %s, not the results files\n", testsuite.AnswerKeyFile)
+			}
 			fmt.Printf("Model: %s (temperature: %.1f)\n", model, temperature)
 			fmt.Println()
 
@@ -82,6 +186,17 @@ Results are written to the output directory as text files with a JSON metadata m
 			fmt.Printf("Results:\n")
 			for _, m := range run.Models {
 				fmt.Printf("  - %s: %s\n", m.ModelName, m.ResultsFile)
+				if m.LiveScore != nil {
+					fmt.Printf("    Live score: %d/%d correct (%.2f%%)\n",
+						m.LiveScore.Correct, m.LiveScore.Judged, m.LiveScore.Percent)
+				}
+				if m.Usage != nil {
+					fmt.Printf("    Tokens: %d prompt, %d completion (%d answers truncated)\n",
+						m.Usage.PromptTokens, m.Usage.CompletionTokens, m.Usage.Truncated)
+				}
+				if len(m.Errors) > 0 || m.Retries > 0 {
+					fmt.Printf("    Failed questions: %s (retries: %d)\n", formatErrorCounts(m.Errors), m.Retries)
+				}
 			}
 
 			slog.Info("test run complete", "run_id", run.ID)
@@ -91,11 +206,50 @@ Results are written to the output directory as text files with a JSON metadata m
 
 	cmd.Flags().StringVar(&model, "model", "", "Model name to test (required)")
 	cmd.Flags().StringVar(&endpoint, "endpoint", "", "LLM API endpoint URL")
-	cmd.Flags().StringVar(&apiKey, "api-key", "", "API key (or set OPENAI_API_KEY)")
+	cmd.Flags().StringVar(&apiKey, "api-key", "", "API key (or set OPENAI_API_KEY, or the provider's API key variable)")
+	cmd.Flags().StringVar(&apiKeyFile, "api-key-file", "", "File holding the API key, e.g. a mounted Kubernetes Secret; re-read when it changes")
+	cmd.Flags().StringVar(&provider, "provider", llm.ProviderOpenAI, "Provider of the model: openai (OpenAI-compatible), anthropic, gemini, vertexai, azure, or a name from --providers")
+	cmd.Flags().StringVar(&providers, "providers", "", "Provider registry file naming providers with their type, endpoint, and API key variable")
+	cmd.Flags().BoolVar(&debugLLM, "debug-llm", false, "Record the full LLM request and response payloads (API keys redacted) in the run's transcripts/ directory")
+	fixtures.register(cmd)
+	cmd.Flags().StringVar(&cacheDir, "llm-cache-dir", "", "Cache the model's answers to requests with temperature 0 in this directory and reuse them on re-runs")
 	cmd.Flags().Float64Var(&temperature, "temperature", 0.0, "Temperature for generation")
+	cmd.Flags().StringVar(&effort, "reasoning-effort", "", "Reasoning effort: low, medium, or high (for reasoning models, e.g. o-series, Claude, Gemini)")
+	cmd.Flags().StringVar(&extraParams, "extra-params", "", `JSON object of fields added to every request payload, e.g. '{"chat_template_kwargs":{"enable_thinking":true}}'`)
+	cmd.Flags().IntVar(&maxRetries, "max-retries", 0, "Retry budget for timeouts and 5xx errors across the whole run")
+	breaker.register(cmd)
+	httpFlags.register(cmd)
 	cmd.Flags().StringVar(&outputDir, "output-dir", "results", "Directory for test results")
-	cmd.Flags().StringVar(&suitesDir, "suites-dir", "", "External test suites directory")
+	suites.register(cmd)
+	cmd.Flags().StringVar(&profile, "profile", "", "Prompt profile of the suite to run with (e.g. concise); default: the suite's prompt")
+	cmd.Flags().BoolVar(&blind, "blind", false, "Keep expected answers out of the results files, in a separate answer key joined locally when scoring per question")
+	cmd.Flags().BoolVar(&noExamples, "no-examples", false, "Ask the questions without the suite's few-shot examples")
+	cmd.Flags().StringVar(&tags, "tags", "", "Only run questions having any of these comma-separated tags (e.g. networking,storage)")
+	cmd.Flags().BoolVar(&judge, "judge", false, "Judge each answer immediately and show running accuracy")
+	cmd.Flags().StringVar(&scoringModel, "scoring-model", scorer.DefaultScoringModel, "Scoring model name (with --judge)")
+	cmd.Flags().StringVar(&scoringEndpoint, "scoring-endpoint", "", "Scoring LLM endpoint URL (with --judge)")
+	cmd.Flags().StringVar(&scoringAPIKey, "scoring-api-key", "", "Scoring API key (with --judge, or set OPENAI_API_KEY or ANTHROPIC_API_KEY)")
+	cmd.Flags().StringVar(&scoringKeyFile, "scoring-api-key-file", "", "File holding the scoring API key (with --judge), e.g. a mounted Kubernetes Secret; re-read when it changes")
+	cmd.Flags().StringVar(&scoringProvider, "scoring-provider", llm.ProviderOpenAI, "Provider of the scoring model (with --judge): openai, anthropic, gemini, vertexai, azure, or a name from --providers")
 	cmd.Flags().DurationVar(&timeout, "timeout", 0, "Overall timeout for the test run (e.g. 30m, 1h). 0 means no timeout")
 
 	return cmd
 }
+
+// formatErrorCounts renders error counts as "class=count" pairs in a stable order.
+func formatErrorCounts(counts map[string]int) string {
+	if len(counts) == 0 {
+		return "none"
+	}
+	classes := make([]string, 0, len(counts))
+	for class := range counts {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+
+	parts := make([]string, 0, len(classes))
+	for _, class := range classes {
+		parts = append(parts, fmt.Sprintf("%s=%d", class, counts[class]))
+	}
+	return strings.Join(parts, ", ")
+}
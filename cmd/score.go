@@ -2,10 +2,15 @@ package cmd
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
 
+	"github.com/giantswarm/llm-testing/internal/history"
+	"github.com/giantswarm/llm-testing/internal/llm"
 	"github.com/giantswarm/llm-testing/internal/scorer"
 )
 
@@ -14,7 +19,23 @@ func newScoreCmd() *cobra.Command {
 		scoringModel    string
 		scoringEndpoint string
 		scoringAPIKey   string
+		scoringKeyFile  string
+		provider        string
+		providers       string
 		repetitions     int
+		temperature     float64
+		maxTokens       int
+		reasoningEffort string
+		mode            string
+		judges          []string
+		consensus       string
+		rescore         bool
+		modelRegistry   string
+		hallucinations  bool
+		format          string
+		judgePrices     string
+		fixtures        llmFixtureFlags
+		httpFlags       llmHTTPFlags
 	)
 
 	cmd := &cobra.Command{
@@ -31,19 +52,92 @@ JSON scores.`,
 				return fmt.Errorf("results file not found: %s", resultsFile)
 			}
 
-			client := newLLMClientFromFlags(scoringEndpoint, scoringAPIKey)
+			if err := llm.ValidateReasoningEffort(reasoningEffort); err != nil {
+				return err
+			}
+			if temperature < 0 {
+				return fmt.Errorf("--temperature must not be negative")
+			}
+			if err := scorer.ValidateMode(mode); err != nil {
+				return err
+			}
+			if err := scorer.ValidateConsensus(consensus); err != nil {
+				return err
+			}
+			judgeList, err := parseJudgeFlags(judges)
+			if err != nil {
+				return err
+			}
+			if (len(judgeList) > 0 || consensus != "") && mode != scorer.ModePerQuestion {
+				return fmt.Errorf("--judges and --consensus require --mode %s", scorer.ModePerQuestion)
+			}
+			if err := scorer.ValidateFormat(format); err != nil {
+				return err
+			}
+			if format == scorer.FormatJUnit && mode != scorer.ModePerQuestion {
+				return fmt.Errorf("--format %s requires --mode %s", scorer.FormatJUnit, scorer.ModePerQuestion)
+			}
 
-			s := scorer.NewScorer(client, scorer.Config{
-				Model:       scoringModel,
-				Repetitions: repetitions,
-			})
+			var prices scorer.PriceTable
+			if judgePrices != "" {
+				if prices, err = scorer.LoadPriceTable(judgePrices); err != nil {
+					return err
+				}
+			}
+
+			// Load the model registry before scoring, so that a broken file
+			// fails fast rather than after paying for the judge.
+			registry, err := loadModelRegistry(modelRegistry)
+			if err != nil {
+				return err
+			}
+
+			providerRegistry, err := loadProviderRegistry(providers)
+			if err != nil {
+				return err
+			}
+			httpOpts, err := httpFlags.options()
+			if err != nil {
+				return err
+			}
+			client, err := newLLMClientFromFlags(providerRegistry, provider, scoringEndpoint, scoringAPIKey, scoringKeyFile, httpOpts...)
+			if err != nil {
+				return err
+			}
+			if err := fixtures.open(); err != nil {
+				return err
+			}
+			defer fixtures.save()
+			client = fixtures.wrap(client)
+
+			cfg := scorer.Config{
+				Model:           scoringModel,
+				Repetitions:     repetitions,
+				MaxTokens:       maxTokens,
+				ReasoningEffort: reasoningEffort,
+				Mode:            mode,
+				Judges:          judgeList,
+				Consensus:       consensus,
+
+				HallucinationCheck: hallucinations,
+				Prices:             prices,
+			}
+			if cmd.Flags().Changed("temperature") {
+				cfg.Temperature = llm.Float64Ptr(temperature)
+			}
+			s := scorer.NewScorer(client, cfg)
 
 			fmt.Printf("Scoring: %s\n", resultsFile)
 			fmt.Printf("Model: %s\n", scoringModel)
 			fmt.Printf("Repetitions: %d\n", repetitions)
 			fmt.Println()
 
-			output, err := s.ScoreFile(cmd.Context(), resultsFile)
+			var output *scorer.ScoreOutput
+			if rescore {
+				output, err = s.RescoreFile(cmd.Context(), resultsFile)
+			} else {
+				output, err = s.ScoreFile(cmd.Context(), resultsFile)
+			}
 			if err != nil {
 				return err
 			}
@@ -54,6 +148,36 @@ JSON scores.`,
 			}
 
 			fmt.Printf("\nScores written to: %s\n", scoresFile)
+			if format == scorer.FormatJUnit {
+				junitFile := scorer.JUnitFilePath(resultsFile)
+				if err := scorer.WriteJUnitFile(junitFile, "", []*scorer.ScoreOutput{output}); err != nil {
+					return err
+				}
+				fmt.Printf("JUnit report written to: %s\n", junitFile)
+			}
+			if rescore {
+				fmt.Printf("Rescored runs: %v\n", output.Metadata.RescoredRuns)
+			}
+			for _, warning := range output.Metadata.Warnings {
+				fmt.Printf("Warning: %s\n", warning)
+			}
+			if u := output.Metadata.Usage; u != nil {
+				fmt.Printf("Judge usage: %d calls, %d prompt + %d completion tokens\n",
+					u.Calls, u.PromptTokens, u.CompletionTokens)
+				if u.Cost != nil {
+					fmt.Printf("Judge cost: $%.4f\n", *u.Cost)
+					if len(u.UnpricedModels) > 0 {
+						fmt.Printf("  (excluding models without a price: %s)\n", strings.Join(u.UnpricedModels, ", "))
+					}
+				}
+			}
+
+			entry, err := history.Record(resultsFile, scoresFile, output, registry)
+			if err != nil {
+				slog.Warn("failed to record score history", "error", err)
+			} else if entry.SuiteChanged {
+				fmt.Printf("\nWarning: the test suite content changed since the previous scored run of %s; scores are not directly comparable.\n", entry.Model)
+			}
 
 			if output.Summary.MeanCorrect != nil && output.Summary.MeanPercent != nil {
 				fmt.Printf("\nSummary:\n")
@@ -69,11 +193,30 @@ JSON scores.`,
 					*output.Summary.MeanCorrect,
 					total,
 					*output.Summary.MeanPercent)
+				if output.Summary.MeanWeightedCorrect != nil && output.Summary.WeightedTotal != nil {
+					fmt.Printf("  Weighted Score: %.2f/%g (%.2f%%)\n",
+						*output.Summary.MeanWeightedCorrect,
+						*output.Summary.WeightedTotal,
+						*output.Summary.MeanWeightedPercent)
+				}
 				if output.Summary.MinCorrect != nil && output.Summary.MaxCorrect != nil {
 					fmt.Printf("  Range: %d-%d correct\n",
 						*output.Summary.MinCorrect,
 						*output.Summary.MaxCorrect)
 				}
+				if output.Summary.HallucinationRate != nil {
+					fmt.Printf("  Hallucination Rate: %.2f%% (%d/%d answers)\n",
+						*output.Summary.HallucinationRate,
+						output.Hallucinations.Hallucinated,
+						output.Hallucinations.Checked)
+				}
+				if len(output.Summary.UnstableQuestions) > 0 {
+					fmt.Printf("  Unstable verdicts (judged correct in some repetitions only):\n")
+					for _, q := range output.Summary.UnstableQuestions {
+						fmt.Printf("    - NO. %s (%s): correct in %d/%d runs\n",
+							q.ID, q.Section, q.CorrectRuns, q.JudgedRuns)
+					}
+				}
 			}
 
 			return nil
@@ -82,8 +225,46 @@ JSON scores.`,
 
 	cmd.Flags().StringVar(&scoringModel, "scoring-model", scorer.DefaultScoringModel, "Scoring model name")
 	cmd.Flags().StringVar(&scoringEndpoint, "scoring-endpoint", "", "Scoring LLM endpoint URL")
-	cmd.Flags().StringVar(&scoringAPIKey, "api-key", "", "Scoring API key (or set OPENAI_API_KEY)")
+	cmd.Flags().StringVar(&scoringAPIKey, "api-key", "", "Scoring API key (or set OPENAI_API_KEY, or the provider's API key variable)")
+	cmd.Flags().StringVar(&scoringKeyFile, "api-key-file", "", "File holding the scoring API key, e.g. a mounted Kubernetes Secret; re-read when it changes")
+	cmd.Flags().StringVar(&provider, "provider", llm.ProviderOpenAI, "Provider of the scoring model: openai (OpenAI-compatible), anthropic, gemini, vertexai, azure, or a name from --providers")
+	cmd.Flags().StringVar(&providers, "providers", "", "Provider registry file naming providers with their type, endpoint, and API key variable")
+	fixtures.register(cmd)
+	httpFlags.register(cmd)
 	cmd.Flags().IntVar(&repetitions, "repetitions", 3, "Number of scoring repetitions")
+	cmd.Flags().Float64Var(&temperature, "temperature", 0.0, "Judge temperature")
+	cmd.Flags().IntVar(&maxTokens, "max-tokens", 0, "Maximum tokens the judge may generate. 0 means server default")
+	cmd.Flags().StringVar(&modelRegistry, "model-registry", "", "Model identity registry file mapping aliases and URIs to canonical model IDs")
+	cmd.Flags().BoolVar(&rescore, "rescore", false, "Only re-run failed or unparsed repetitions from the existing scores file")
+	cmd.Flags().StringVar(&mode, "mode", scorer.ModeAggregate, "Scoring mode: aggregate or per_question")
+	cmd.Flags().StringSliceVar(&judges, "judges", nil, "Judge models for per_question mode as model[=weight], weight being calibration accuracy (0-1)")
+	cmd.Flags().StringVar(&consensus, "consensus", "", "Rule combining judge votes in per_question mode: majority, unanimous, or weighted")
+	cmd.Flags().StringVar(&format, "format", scorer.FormatJSON, "Output format: json, or junit to also write a JUnit XML report (requires --mode per_question)")
+	cmd.Flags().BoolVar(&hallucinations, "hallucination-check", false, "Run a second pass checking answers for fabricated resource names, flags, or API versions")
+	cmd.Flags().StringVar(&judgePrices, "judge-prices", "", "Price table file (USD per million tokens per judge model) for reporting scoring cost")
+	cmd.Flags().StringVar(&reasoningEffort, "reasoning-effort", "", "Judge reasoning effort: low, medium, or high (for models that support it)")
 
 	return cmd
 }
+
+// parseJudgeFlags parses --judges values of the form "model" or "model=weight".
+func parseJudgeFlags(values []string) ([]scorer.Judge, error) {
+	judges := make([]scorer.Judge, 0, len(values))
+	for _, v := range values {
+		model, weightStr, hasWeight := strings.Cut(v, "=")
+		model = strings.TrimSpace(model)
+		if model == "" {
+			return nil, fmt.Errorf("invalid judge %q: model cannot be empty", v)
+		}
+		judge := scorer.Judge{Model: model}
+		if hasWeight {
+			weight, err := strconv.ParseFloat(strings.TrimSpace(weightStr), 64)
+			if err != nil || weight < 0 || weight > 1 {
+				return nil, fmt.Errorf("invalid judge %q: weight must be a number between 0 and 1", v)
+			}
+			judge.Weight = weight
+		}
+		judges = append(judges, judge)
+	}
+	return judges, nil
+}
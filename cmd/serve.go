@@ -1,27 +1,44 @@
 package cmd
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	mcpserver "github.com/mark3labs/mcp-go/server"
 	"github.com/spf13/cobra"
+	"github.com/valkey-io/valkey-go"
 
+	"github.com/giantswarm/llm-testing/internal/jobs"
 	"github.com/giantswarm/llm-testing/internal/kserve"
 	"github.com/giantswarm/llm-testing/internal/llm"
 	mcptools "github.com/giantswarm/llm-testing/internal/mcp"
+	"github.com/giantswarm/llm-testing/internal/runner"
 	"github.com/giantswarm/llm-testing/internal/scorer"
 	"github.com/giantswarm/llm-testing/internal/server"
+	"github.com/giantswarm/llm-testing/internal/testsuite"
+	"github.com/giantswarm/llm-testing/internal/webhook"
 )
 
 // Note: Debug logging is controlled via the global --verbose/-v flag on the root command.
 
+// reapInterval is how often expired InferenceServices are torn down when
+// --model-ttl is set.
+const reapInterval = 5 * time.Minute
+
+// retentionInterval is how often runs older than --results-retention are
+// deleted.
+const retentionInterval = time.Hour
+
 const (
 	transportStdio          = "stdio"
 	transportStreamableHTTP = "streamable-http"
@@ -29,23 +46,52 @@ const (
 
 func newServeCmd() *cobra.Command {
 	var (
+		configFile      string
 		transport       string
 		httpAddr        string
 		httpEndpoint    string
 		inCluster       bool
 		outputDir       string
-		suitesDir       string
+		suites          suitesFlags
 		scoringModel    string
 		scoringEndpoint string
 		apiKey          string
+		apiKeyFile      string
+		breaker         circuitBreakerFlags
+		httpFlags       llmHTTPFlags
+		provider        string
+		providers       string
+		debugLLM        bool
+		cacheDir        string
+		modelRegistry   string
+		modelAliases    string
+		gpuMemory       float64
+		capacityCheck   string
+		modelTTL        time.Duration
+		extraNamespaces []string
+		retention       string
+		urlMode         string
+		hfToken         string
+		hfTokenSecret   string
+		runTemplates    string
+		webhookSecret   string
+		judgePrices     string
+		readOnly        bool
+		allowPrivate    bool
+		maxRuns         int
+		drainTimeout    time.Duration
+		stateStore      string
+		stateKeyPrefix  string
 
 		// OAuth options (simplified from mcp-kubernetes).
 		enableOAuth     bool
 		oauthBaseURL    string
 		oauthProvider   string
+		oauth           oauthConfig
 		dexIssuerURL    string
 		dexClientID     string
 		dexClientSecret string
+		writerGroups    []string
 	)
 
 	cmd := &cobra.Command{
@@ -57,18 +103,101 @@ Supports multiple transport types:
   - stdio: Standard input/output (default, for IDE integration)
   - streamable-http: HTTP with streaming support (for remote access)
 
-When using streamable-http transport, OAuth 2.1 authentication can be enabled.`,
+When using streamable-http transport, OAuth 2.1 authentication can be enabled.
+
+Flags can also be set in a YAML file given with --config, and with
+LLM_TESTING_<FLAG> environment variables (e.g. LLM_TESTING_HTTP_ADDR).
+Command-line flags take precedence over environment variables, and these
+over the file.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := applyServeConfig(cmd, configFile); err != nil {
+				return err
+			}
+
 			namespace, _ := cmd.Flags().GetString("namespace")
 			kubeconfig, _ := cmd.Flags().GetString("kubeconfig")
 
+			suitesDir, err := suites.resolve(cmd.Context())
+			if err != nil {
+				return err
+			}
+
 			// Build server context.
 			sc := &server.ServerContext{
-				Namespace:    namespace,
-				OutputDir:    outputDir,
-				SuitesDir:    suitesDir,
-				ScoringModel: scoringModel,
-				LLMAPIKey:    apiKey,
+				Namespace:     namespace,
+				OutputDir:     outputDir,
+				SuitesDir:     suitesDir,
+				ScoringModel:  scoringModel,
+				LLMAPIKey:     apiKey,
+				LLMAPIKeyFile: apiKeyFile,
+
+				CircuitBreakerFailures: breaker.failures,
+				CircuitBreakerCooldown: breaker.cooldown,
+
+				HFTokenSecret:    hfTokenSecret,
+				AllowPrivateURLs: allowPrivate,
+
+				Access:   server.AccessPolicy{ReadOnly: readOnly, WriterGroups: writerGroups},
+				Drain:    server.NewDrain(),
+				Sessions: server.NewSessions(),
+			}
+			if len(writerGroups) > 0 && !enableOAuth {
+				return fmt.Errorf("--oauth-writer-groups requires --enable-oauth")
+			}
+			if maxRuns < 1 {
+				return fmt.Errorf("--max-concurrent-runs must be at least 1")
+			}
+			if readOnly && runTemplates != "" {
+				return fmt.Errorf("--run-templates cannot be used with --read-only")
+			}
+
+			storeOpt, err := server.ParseStateStore(stateStore)
+			if err != nil {
+				return err
+			}
+
+			check, err := kserve.ParseCapacityCheck(capacityCheck)
+			if err != nil {
+				return err
+			}
+			sc.GPUCapacityCheck = check
+
+			mode, err := kserve.ParseURLMode(urlMode)
+			if err != nil {
+				return err
+			}
+			if mode == "" {
+				mode = kserve.DefaultURLMode(inCluster)
+			}
+			sc.EndpointURLMode = mode
+
+			registry, err := loadModelRegistry(modelRegistry)
+			if err != nil {
+				return err
+			}
+			sc.ModelRegistry = registry
+
+			if modelAliases != "" {
+				if sc.ModelAliases, err = testsuite.LoadModelAliases(modelAliases); err != nil {
+					return err
+				}
+				sc.ScoringModel = sc.ModelAliases.ModelName(sc.ScoringModel)
+			}
+
+			if judgePrices != "" {
+				prices, err := scorer.LoadPriceTable(judgePrices)
+				if err != nil {
+					return err
+				}
+				sc.JudgePrices = prices
+			}
+
+			if gpuMemory > 0 {
+				if hfToken == "" {
+					hfToken = os.Getenv("HF_TOKEN")
+				}
+				sc.ModelMetadata = kserve.NewHFMetadataClient(hfToken)
+				sc.GPUMemoryGiB = gpuMemory
 			}
 
 			// Create KServe manager if in-cluster or kubeconfig is available.
@@ -82,22 +211,36 @@ When using streamable-http transport, OAuth 2.1 authentication can be enabled.`,
 				} else {
 					sc.KServeManager = ksManager
 					slog.Info("KServe InferenceService CRD detected, model management enabled")
+					ksManager.SetModelTTL(modelTTL)
 				}
 			}
 
 			// Create default LLM client (for scoring; test runs may use different endpoints).
-			clientOpts := make([]llm.Option, 0, 2)
-			if scoringEndpoint != "" {
-				clientOpts = append(clientOpts, llm.WithBaseURL(scoringEndpoint))
+			sc.Providers, err = loadProviderRegistry(providers)
+			if err != nil {
+				return err
 			}
-			if apiKey != "" {
-				clientOpts = append(clientOpts, llm.WithAPIKey(apiKey))
+			httpOpts, err := httpFlags.options()
+			if err != nil {
+				return err
+			}
+			sc.LLMOptions = append(debugLLMOptions(debugLLM), httpOpts...)
+			sc.DebugLLM = debugLLM
+			sc.LLMCache, err = openLLMCache(cacheDir)
+			if err != nil {
+				return err
+			}
+			sc.LLMClient, err = newLLMClientFromFlags(sc.Providers, provider, scoringEndpoint, apiKey, apiKeyFile, sc.LLMOptions...)
+			if err != nil {
+				return err
 			}
-			sc.LLMClient = llm.NewOpenAIClient(clientOpts...)
 
 			// Create MCP server.
 			mcpSrv := mcpserver.NewMCPServer("llm-testing", rootCmd.Version,
-				mcpserver.WithToolCapabilities(true),
+				append([]mcpserver.ServerOption{
+					mcpserver.WithToolCapabilities(true),
+					mcpserver.WithLogging(),
+				}, slices.Concat(mcptools.AccessOptions(sc), mcptools.DrainOptions(sc), mcptools.SessionOptions(sc))...)...,
 			)
 
 			if err := mcptools.RegisterTools(mcpSrv, sc); err != nil {
@@ -109,48 +252,217 @@ When using streamable-http transport, OAuth 2.1 authentication can be enabled.`,
 				os.Interrupt, syscall.SIGTERM)
 			defer cancel()
 
+			// The state store is closed last, after the interrupted runs
+			// recorded their state in it.
+			var storeClient valkey.Client
+			if storeOpt != nil {
+				storeClient, err = valkey.NewClient(*storeOpt)
+				if err != nil {
+					return fmt.Errorf("failed to connect to state store: %w", err)
+				}
+				defer storeClient.Close()
+			}
+			defer teardownTracked(sc)
+
+			// On shutdown, the runs in flight get --drain-timeout to finish;
+			// then the rest are cancelled and their models torn down, and the
+			// server stops. A second signal stops the server right away.
+			serveCtx, stopServing := context.WithCancel(context.Background())
+			defer stopServing()
+			go func() {
+				<-shutdownCtx.Done()
+				cancel()
+				drainRuns(sc, drainTimeout)
+				stopServing()
+			}()
+
+			sc.Jobs = jobs.NewManager(sc.Drain.Context())
+			sc.Jobs.SetMaxConcurrent(maxRuns)
+			if storeClient != nil {
+				replica, err := os.Hostname()
+				if err != nil {
+					return fmt.Errorf("failed to determine replica name: %w", err)
+				}
+				sc.Jobs.SetStore(jobs.NewRedisStore(storeClient, stateKeyPrefix+"jobs:"), replica, mcptools.ResumeRun(sc))
+				slog.Info("sharing jobs through the state store", "replica", replica, "address", storeOpt.InitAddress)
+			}
+
+			if sc.KServeManager != nil && modelTTL > 0 {
+				slog.Info("reaping expired InferenceServices", "ttl", modelTTL, "interval", reapInterval)
+				go sc.KServeManager.RunReaper(shutdownCtx, reapInterval, extraNamespaces)
+			}
+
+			if retention != "" {
+				age, err := runner.ParseAge(retention)
+				if err != nil {
+					return fmt.Errorf("--results-retention: %w", err)
+				}
+				slog.Info("deleting expired test runs", "retention", age, "interval", retentionInterval)
+				go runner.RunRetention(shutdownCtx, sc.OutputDir, age, retentionInterval)
+			}
+
+			if sc.SuitesDir != "" && !testsuite.IsRemote(suites.dir) {
+				go func() {
+					slog.Info("watching test suites directory for changes", "dir", sc.SuitesDir)
+					if err := mcptools.WatchSuites(shutdownCtx, mcpSrv, sc); err != nil {
+						slog.Warn("not watching test suites directory", "dir", sc.SuitesDir, "error", err)
+					}
+				}()
+			}
+
+			routes, err := webhookRoutes(sc, storeClient, stateKeyPrefix, runTemplates, webhookSecret)
+			if err != nil {
+				return err
+			}
+			if len(routes) > 0 && transport != transportStreamableHTTP {
+				slog.Warn("run templates are only triggerable with the streamable-http transport")
+			}
+			if routes == nil {
+				routes = make(map[string]http.Handler)
+			}
+			routes[server.ReadyPath] = server.ReadyHandler(sc)
+
 			switch transport {
 			case transportStdio:
-				return runStdioServer(mcpSrv, shutdownCtx)
+				return runStdioServer(mcpSrv, serveCtx)
 			case transportStreamableHTTP:
 				fmt.Printf("Starting llm-testing MCP server with %s transport...\n", transport)
 				if enableOAuth {
-					return runOAuthHTTPServer(mcpSrv, httpAddr, httpEndpoint, shutdownCtx, oauthConfig{
-						baseURL:         oauthBaseURL,
-						provider:        oauthProvider,
-						dexIssuerURL:    dexIssuerURL,
-						dexClientID:     dexClientID,
-						dexClientSecret: dexClientSecret,
-					})
+					oauth.baseURL = oauthBaseURL
+					oauth.provider = oauthProvider
+					oauth.dexIssuerURL = dexIssuerURL
+					oauth.dexClientID = dexClientID
+					oauth.dexClientSecret = dexClientSecret
+					oauth.stateStore = storeOpt
+					oauth.stateKeyPrefix = stateKeyPrefix
+					return runOAuthHTTPServer(mcpSrv, httpAddr, httpEndpoint, serveCtx, routes, oauth)
 				}
-				return runHTTPServer(mcpSrv, httpAddr, httpEndpoint, shutdownCtx)
+				return runHTTPServer(mcpSrv, httpAddr, httpEndpoint, serveCtx, routes)
 			default:
 				return fmt.Errorf("unsupported transport: %s (supported: stdio, streamable-http)", transport)
 			}
 		},
 	}
 
+	cmd.Flags().StringVar(&configFile, "config", "", "YAML configuration file setting any of these flags (falls back to LLM_TESTING_CONFIG)")
 	cmd.Flags().StringVar(&transport, "transport", transportStdio, "Transport type: stdio or streamable-http")
 	cmd.Flags().StringVar(&httpAddr, "http-addr", ":8080", "HTTP server address (for streamable-http)")
 	cmd.Flags().StringVar(&httpEndpoint, "http-endpoint", "/mcp", "HTTP endpoint path (for streamable-http)")
 	cmd.Flags().BoolVar(&inCluster, "in-cluster", false, "Use in-cluster Kubernetes authentication")
 	cmd.Flags().StringVar(&outputDir, "output-dir", "results", "Directory for test results")
-	cmd.Flags().StringVar(&suitesDir, "suites-dir", "", "External test suites directory (optional)")
+	suites.register(cmd)
 	cmd.Flags().StringVar(&scoringModel, "scoring-model", scorer.DefaultScoringModel, "Default model for LLM-as-judge scoring")
 	cmd.Flags().StringVar(&scoringEndpoint, "scoring-endpoint", "", "Default LLM endpoint URL for scoring and endpoint-based test runs")
-	cmd.Flags().StringVar(&apiKey, "api-key", "", "API key for the default LLM client (falls back to OPENAI_API_KEY)")
+	cmd.Flags().StringVar(&apiKey, "api-key", "", "API key for the default LLM client (falls back to OPENAI_API_KEY, or the provider's API key variable)")
+	cmd.Flags().StringVar(&apiKeyFile, "api-key-file", "", "File holding the API key for the default LLM client and endpoint-based test runs, e.g. a mounted Kubernetes Secret; re-read when it changes")
+	cmd.Flags().StringVar(&provider, "provider", llm.ProviderOpenAI, "Provider of the default (scoring) client: openai (OpenAI-compatible), anthropic, gemini, vertexai, azure, or a name from --providers")
+	cmd.Flags().StringVar(&providers, "providers", "", "Provider registry file naming providers with their type, endpoint, and API key variable, for models with a provider")
+	cmd.Flags().BoolVar(&debugLLM, "debug-llm", false, "Record the full LLM request and response payloads (API keys redacted) in the transcripts/ directory of each test run")
+	breaker.register(cmd)
+	httpFlags.register(cmd)
+	cmd.Flags().StringVar(&cacheDir, "llm-cache-dir", "", "Cache the answers of tested models to requests with temperature 0 in this directory and reuse them in later runs")
+	cmd.Flags().StringVar(&judgePrices, "judge-prices", "", "Price table file (USD per million tokens per judge model) for reporting scoring cost")
+	cmd.Flags().Float64Var(&gpuMemory, "gpu-memory", 80, "Memory per GPU in GiB, used to recommend GPU counts for hf:// models (0 disables)")
+	cmd.Flags().StringVar(&urlMode, "endpoint-url-mode", "", "How to reach deployed models: cluster-local, external, or port-forward (default: cluster-local with --in-cluster, external otherwise)")
+	cmd.Flags().DurationVar(&modelTTL, "model-ttl", 0, "Stamp deployed InferenceServices with this lifetime and periodically tear down expired ones (0 disables)")
+	cmd.Flags().StringSliceVar(&extraNamespaces, "extra-namespaces", nil, "Further namespaces models may be deployed to, where expired InferenceServices are torn down too")
+	cmd.Flags().StringVar(&retention, "results-retention", "", "Periodically delete test runs older than this age, e.g. 30d or 72h (default: keep all)")
+	cmd.Flags().StringVar(&capacityCheck, "gpu-capacity-check", string(kserve.CapacityCheckWarn), "Check for free GPUs before deploying: off, warn (deploy anyway), or enforce (refuse)")
+	cmd.Flags().StringVar(&hfToken, "hf-token", "", "HuggingFace token for reading gated model metadata (falls back to HF_TOKEN)")
+	cmd.Flags().StringVar(&hfTokenSecret, "hf-token-secret", "", "Default Kubernetes Secret with an HF_TOKEN key, injected into deployed models for downloading gated models")
+	cmd.Flags().StringVar(&runTemplates, "run-templates", "", "Run templates file; enables the signed "+webhook.TriggerPath+" endpoint (streamable-http only)")
+	cmd.Flags().StringVar(&webhookSecret, "webhook-secret", "", "HMAC secret for verifying webhook triggers (falls back to WEBHOOK_SECRET)")
+	cmd.Flags().IntVar(&maxRuns, "max-concurrent-runs", jobs.DefaultMaxConcurrent, "Number of test runs executed at a time; further runs wait in a queue")
+	cmd.Flags().StringVar(&stateStore, "state-store", "", "Redis or Valkey URL (redis:// or rediss://) keeping the OAuth state and run jobs, so that they survive restarts and are shared by replicas (default: in memory)")
+	cmd.Flags().StringVar(&stateKeyPrefix, "state-key-prefix", server.DefaultStateKeyPrefix, "Prefix of the keys in the --state-store, to share one Redis between deployments")
+	cmd.Flags().DurationVar(&drainTimeout, "drain-timeout", 0, "On shutdown, refuse new runs and let the runs in flight finish for up to this long before cancelling them (0 cancels them right away)")
+	cmd.Flags().BoolVar(&readOnly, "read-only", false, "Hide the tools that deploy or tear down models, run or score tests, or change results, and refuse their calls")
+	cmd.Flags().BoolVar(&allowPrivate, "allow-private-urls", false, "Allow generate_suite to fetch documents from loopback, private, and link-local addresses")
+	cmd.Flags().StringVar(&modelRegistry, "model-registry", "", "Model identity registry file mapping aliases and URIs to canonical model IDs")
+	cmd.Flags().StringVar(&modelAliases, "model-aliases", "", "Model aliases file naming models with their provider and deployment settings, e.g. judge-default, for use wherever tools take a model name")
 
 	// OAuth flags.
 	cmd.Flags().BoolVar(&enableOAuth, "enable-oauth", false, "Enable OAuth 2.1 authentication (for HTTP transport)")
 	cmd.Flags().StringVar(&oauthBaseURL, "oauth-base-url", "", "OAuth base URL (e.g. https://llm-testing.example.com)")
-	cmd.Flags().StringVar(&oauthProvider, "oauth-provider", "dex", "OAuth provider: dex")
-	cmd.Flags().StringVar(&dexIssuerURL, "dex-issuer-url", "", "Dex OIDC issuer URL")
-	cmd.Flags().StringVar(&dexClientID, "dex-client-id", "", "Dex OAuth client ID")
-	cmd.Flags().StringVar(&dexClientSecret, "dex-client-secret", "", "Dex OAuth client secret")
+	cmd.Flags().StringVar(&oauthProvider, "oauth-provider", server.OAuthProviderDex, "OAuth provider: "+strings.Join(server.OAuthProviders(), ", ")+" (oidc: any OIDC issuer)")
+	cmd.Flags().StringVar(&oauth.issuerURL, "oauth-issuer-url", "", "OIDC issuer URL, e.g. https://example.okta.com/oauth2/default (falls back to --dex-issuer-url)")
+	cmd.Flags().StringVar(&oauth.clientID, "oauth-client-id", "", "OAuth client ID (falls back to --dex-client-id)")
+	cmd.Flags().StringVar(&oauth.clientSecret, "oauth-client-secret", "", "OAuth client secret (falls back to --dex-client-secret)")
+	cmd.Flags().StringSliceVar(&oauth.scopes, "oauth-scopes", nil, "Scopes requested when users log in (default: the provider's, e.g. openid, profile, email, offline_access)")
+	cmd.Flags().StringVar(&oauth.groupsClaim, "oauth-groups-claim", server.DefaultGroupsClaim, "Token or userinfo claim listing the groups of a user, e.g. https://example.com/groups for Auth0 or roles for Entra ID")
+	cmd.Flags().StringSliceVar(&oauth.audiences, "oauth-audience", nil, "Audiences accepted in JWT access tokens (default: the client ID, and api://default for okta); auth0 requests the first one at login")
+	cmd.Flags().StringVar(&dexIssuerURL, "dex-issuer-url", "", "Dex OIDC issuer URL (falls back to DEX_ISSUER_URL)")
+	cmd.Flags().StringVar(&dexClientID, "dex-client-id", "", "Dex OAuth client ID (falls back to DEX_CLIENT_ID)")
+	cmd.Flags().StringVar(&dexClientSecret, "dex-client-secret", "", "Dex OAuth client secret (falls back to DEX_CLIENT_SECRET)")
+	cmd.Flags().StringSliceVar(&writerGroups, "oauth-writer-groups", nil, "Allow the tools that change state, e.g. deploy_model or run_test_suite, only to OAuth users in one of these groups (default: all users)")
 
 	return cmd
 }
 
+// applyServeConfig sets the flags of cmd that were not given on the command
+// line from their LLM_TESTING_* environment variables or the config file.
+func applyServeConfig(cmd *cobra.Command, path string) error {
+	if path == "" {
+		path = os.Getenv(server.EnvName("config"))
+	}
+	settings := map[string]string{}
+	if path != "" {
+		var err error
+		settings, err = server.LoadConfigFile(path)
+		if err != nil {
+			return err
+		}
+		if _, ok := settings["config"]; ok {
+			return fmt.Errorf("config cannot be set in the config file %s", path)
+		}
+	}
+	if err := server.ApplyConfig(cmd.Flags(), settings, os.LookupEnv); err != nil {
+		return err
+	}
+	if path != "" {
+		slog.Info("loaded config file", "path", path, "settings", len(settings))
+	}
+	return nil
+}
+
+// drainRuns refuses new runs, and lets the tool calls and runs in flight
+// finish for up to timeout; then the rest are cancelled, writing the answers
+// so far to their results.
+func drainRuns(sc *server.ServerContext, timeout time.Duration) {
+	sc.Drain.Start()
+	if timeout > 0 {
+		slog.Info("draining runs in flight", "timeout", timeout)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	var wg sync.WaitGroup
+	wg.Go(func() { _ = sc.Drain.Wait(ctx) })
+	wg.Go(func() { _ = sc.Jobs.Drain(ctx) })
+	wg.Wait()
+	if timeout > 0 && ctx.Err() != nil {
+		slog.Warn("drain timeout elapsed, cancelling the runs in flight", "timeout", timeout)
+	}
+	sc.Drain.Cancel()
+}
+
+// teardownTracked tears down models deployed by test runs that were
+// interrupted by the shutdown, so that they do not keep holding GPUs.
+func teardownTracked(sc *server.ServerContext) {
+	if sc.KServeManager == nil {
+		return
+	}
+	defer sc.KServeManager.StopPortForwards()
+	if len(sc.KServeManager.TrackedDeployments()) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	slog.Info("tearing down models of interrupted test runs", "models", sc.KServeManager.TrackedDeployments())
+	if err := sc.KServeManager.TeardownTracked(ctx); err != nil {
+		slog.Error("failed to tear down models of interrupted test runs", "error", err)
+	}
+}
+
 func runStdioServer(mcpSrv *mcpserver.MCPServer, ctx context.Context) error {
 	serverDone := make(chan error, 1)
 	go func() {
@@ -172,13 +484,46 @@ func runStdioServer(mcpSrv *mcpserver.MCPServer, ctx context.Context) error {
 	}
 }
 
-func runHTTPServer(mcpSrv *mcpserver.MCPServer, addr, endpoint string, ctx context.Context) error {
+// webhookRoutes returns the webhook trigger route when run templates are
+// configured. With a state store (optional), the replicas share the
+// signatures of accepted triggers, so that each is accepted once.
+func webhookRoutes(sc *server.ServerContext, storeClient valkey.Client, keyPrefix, templatesPath, secret string) (map[string]http.Handler, error) {
+	if templatesPath == "" {
+		return nil, nil
+	}
+	if secret == "" {
+		secret = os.Getenv("WEBHOOK_SECRET")
+	}
+	if secret == "" {
+		return nil, fmt.Errorf("--webhook-secret (or WEBHOOK_SECRET) is required with --run-templates")
+	}
+
+	templates, err := webhook.LoadTemplates(templatesPath)
+	if err != nil {
+		return nil, err
+	}
+
+	handler := webhook.NewHandler(secret, templates, func(ctx context.Context, t webhook.Template) (string, error) {
+		return mcptools.RunTemplate(ctx, sc, t)
+	})
+	if storeClient != nil {
+		handler.SetReplayCache(webhook.NewRedisReplayCache(storeClient, keyPrefix+"webhook:"))
+	}
+	slog.Info("webhook triggers enabled", "path", webhook.TriggerPath, "templates", len(templates))
+	return map[string]http.Handler{webhook.TriggerPath: handler}, nil
+}
+
+func runHTTPServer(mcpSrv *mcpserver.MCPServer, addr, endpoint string, ctx context.Context, routes map[string]http.Handler) error {
 	mcpHandler := mcpserver.NewStreamableHTTPServer(mcpSrv,
 		mcpserver.WithEndpointPath(endpoint),
 	)
 
 	mux := http.NewServeMux()
 	mux.Handle(endpoint, mcpHandler)
+	for pattern, handler := range routes {
+		mux.Handle(pattern, handler)
+		fmt.Printf("  Route: %s\n", pattern)
+	}
 
 	// Health check.
 	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
@@ -224,52 +569,67 @@ func runHTTPServer(mcpSrv *mcpserver.MCPServer, addr, endpoint string, ctx conte
 }
 
 type oauthConfig struct {
-	baseURL         string
-	provider        string
+	baseURL      string
+	provider     string
+	issuerURL    string
+	clientID     string
+	clientSecret string
+	scopes       []string
+	groupsClaim  string
+	audiences    []string
+
+	stateStore     *valkey.ClientOption
+	stateKeyPrefix string
+
+	// The Dex flags and their environment variables configure the client
+	// of any provider unless the generic flags are set.
 	dexIssuerURL    string
 	dexClientID     string
 	dexClientSecret string
 }
 
-func runOAuthHTTPServer(mcpSrv *mcpserver.MCPServer, addr, endpoint string, ctx context.Context, cfg oauthConfig) error {
+func runOAuthHTTPServer(mcpSrv *mcpserver.MCPServer, addr, endpoint string, ctx context.Context, routes map[string]http.Handler, cfg oauthConfig) error {
 	// Load credentials from env vars if not set via flags.
-	if cfg.dexIssuerURL == "" {
-		cfg.dexIssuerURL = os.Getenv("DEX_ISSUER_URL")
-	}
-	if cfg.dexClientID == "" {
-		cfg.dexClientID = os.Getenv("DEX_CLIENT_ID")
-	}
-	if cfg.dexClientSecret == "" {
-		cfg.dexClientSecret = os.Getenv("DEX_CLIENT_SECRET")
-	}
+	cfg.issuerURL = cmp.Or(cfg.issuerURL, cfg.dexIssuerURL, os.Getenv("DEX_ISSUER_URL"))
+	cfg.clientID = cmp.Or(cfg.clientID, cfg.dexClientID, os.Getenv("DEX_CLIENT_ID"))
+	cfg.clientSecret = cmp.Or(cfg.clientSecret, cfg.dexClientSecret, os.Getenv("DEX_CLIENT_SECRET"))
 
 	if cfg.baseURL == "" {
 		return fmt.Errorf("--oauth-base-url is required when --enable-oauth is set")
 	}
-	if cfg.dexIssuerURL == "" {
-		return fmt.Errorf("dex issuer URL is required (--dex-issuer-url or DEX_ISSUER_URL)")
+	if cfg.issuerURL == "" {
+		return fmt.Errorf("OAuth issuer URL is required (--oauth-issuer-url, --dex-issuer-url, or DEX_ISSUER_URL)")
 	}
-	if cfg.dexClientID == "" {
-		return fmt.Errorf("dex client ID is required (--dex-client-id or DEX_CLIENT_ID)")
+	if cfg.clientID == "" {
+		return fmt.Errorf("OAuth client ID is required (--oauth-client-id, --dex-client-id, or DEX_CLIENT_ID)")
 	}
-	if cfg.dexClientSecret == "" {
-		return fmt.Errorf("dex client secret is required (--dex-client-secret or DEX_CLIENT_SECRET)")
+	if cfg.clientSecret == "" {
+		return fmt.Errorf("OAuth client secret is required (--oauth-client-secret, --dex-client-secret, or DEX_CLIENT_SECRET)")
 	}
 
 	oauthSrv, err := server.NewOAuthHTTPServer(mcpSrv, endpoint, server.OAuthConfig{
-		BaseURL:         cfg.baseURL,
-		Provider:        cfg.provider,
-		DexIssuerURL:    cfg.dexIssuerURL,
-		DexClientID:     cfg.dexClientID,
-		DexClientSecret: cfg.dexClientSecret,
+		BaseURL:      cfg.baseURL,
+		Provider:     cfg.provider,
+		IssuerURL:    cfg.issuerURL,
+		ClientID:     cfg.clientID,
+		ClientSecret: cfg.clientSecret,
+		Scopes:       cfg.scopes,
+		GroupsClaim:  cfg.groupsClaim,
+		Audiences:    cfg.audiences,
+
+		StateStore:     cfg.stateStore,
+		StateKeyPrefix: cfg.stateKeyPrefix,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create OAuth HTTP server: %w", err)
 	}
+	for pattern, handler := range routes {
+		oauthSrv.Handle(pattern, handler)
+	}
 
 	fmt.Printf("OAuth-enabled HTTP server starting on %s\n", addr)
 	fmt.Printf("  Base URL: %s\n", cfg.baseURL)
-	fmt.Printf("  Provider: %s\n", cfg.provider)
+	fmt.Printf("  Provider: %s (%s)\n", cfg.provider, cfg.issuerURL)
 	fmt.Printf("  MCP endpoint: %s (requires OAuth Bearer token)\n", endpoint)
 	fmt.Printf("  Health: /healthz\n")
 	fmt.Printf("  OAuth endpoints:\n")
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/llm-testing/internal/testsuite"
+)
+
+func newSuiteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "suite",
+		Short: "Manage test suites",
+	}
+	cmd.AddCommand(newSuiteExportCmd())
+	cmd.AddCommand(newImportCmd())
+	return cmd
+}
+
+func newSuiteExportCmd() *cobra.Command {
+	var (
+		suites suitesFlags
+		force  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export <name> <dir>",
+		Short: "Copy a test suite to a directory as a starting point for customization",
+		Long: `Copy the config.yaml and questions of a test suite, e.g. one embedded in the
+binary, to a directory. Edit the copy and use it with --suites-dir, or run
+validate-suite on it.
+
+A suite that extends or includes other suites is copied as is and still
+refers to them by name.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			suitesDir, err := suites.resolve(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if err := testsuite.Export(args[0], suitesDir, args[1], force); err != nil {
+				return err
+			}
+			fmt.Printf("Exported test suite %s to %s\n", args[0], args[1])
+			return nil
+		},
+	}
+
+	suites.register(cmd)
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite existing files in the directory")
+
+	return cmd
+}
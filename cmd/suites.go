@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/llm-testing/internal/testsuite"
+)
+
+// suitesFlags are the --suites-dir flags. The external suites directory may
+// be a local path, a git URL, or an HTTPS tarball URL, which is fetched into
+// --suites-cache-dir.
+type suitesFlags struct {
+	dir      string
+	cacheDir string
+}
+
+func (f *suitesFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.dir, "suites-dir", "", "External test suites directory: a local path, a git URL (git::https://host/repo.git//suites?ref=v1), or an HTTPS tarball URL with a checksum (https://host/suites.tar.gz?checksum=sha256:<hex>)")
+	cmd.Flags().StringVar(&f.cacheDir, "suites-cache-dir", "", "Directory to fetch remote --suites-dir URLs into (default: the user cache directory)")
+}
+
+// resolve returns the local external suites directory, fetching a remote
+// one first.
+func (f *suitesFlags) resolve(ctx context.Context) (string, error) {
+	if !testsuite.IsRemote(f.dir) {
+		return f.dir, nil
+	}
+	cacheDir := f.cacheDir
+	if cacheDir == "" {
+		base, err := os.UserCacheDir()
+		if err != nil {
+			base = os.TempDir()
+		}
+		cacheDir = filepath.Join(base, "llm-testing", "suites")
+	}
+	dir, err := testsuite.Fetch(ctx, f.dir, cacheDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch test suites: %w", err)
+	}
+	return dir, nil
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/llm-testing/internal/llm"
+	"github.com/giantswarm/llm-testing/internal/runner"
+	"github.com/giantswarm/llm-testing/internal/scorer"
+)
+
+func newValidateSuiteCmd() *cobra.Command {
+	var (
+		asJSON bool
+
+		lint            bool
+		scoringModel    string
+		scoringEndpoint string
+		scoringAPIKey   string
+		scoringKeyFile  string
+		scoringProvider string
+		providers       string
+		fixtures        llmFixtureFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:   "validate-suite <dir>",
+		Short: "Check a test suite directory for problems",
+		Long: `Check the config.yaml and questions.csv of a test suite directory before
+running it: YAML syntax and unknown fields, the evaluation strategy, missing
+columns, duplicate IDs, empty questions or answers, invalid weights, and
+encoding problems. Problems are reported with file and line number, together
+with an estimate of the prompt sizes.
+
+With --lint, the scoring model also reviews every expected answer and flags
+those likely to produce unstable verdicts: answers that are too short, one of
+several valid phrasings, or dependent on environment-specific details. Flagged
+answers are reported as warnings with the judge's suggestion.
+
+Exits with an error if any problem is an error rather than a warning.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			report := runner.ValidateSuite(os.DirFS(args[0]))
+
+			if lint && report.Questions > 0 {
+				registry, err := loadProviderRegistry(providers)
+				if err != nil {
+					return err
+				}
+				client, err := newLLMClientFromFlags(registry, scoringProvider, scoringEndpoint, scoringAPIKey, scoringKeyFile)
+				if err != nil {
+					return err
+				}
+				if err := fixtures.open(); err != nil {
+					return err
+				}
+				defer fixtures.save()
+				s := scorer.NewScorer(fixtures.wrap(client), scorer.Config{Model: scoringModel})
+				s.LintSuite(cmd.Context(), report)
+			}
+
+			if asJSON {
+				data, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal report: %w", err)
+				}
+				fmt.Println(string(data))
+			} else {
+				for _, issue := range report.Issues {
+					fmt.Println(issue)
+				}
+				if len(report.Issues) > 0 {
+					fmt.Println()
+				}
+				fmt.Printf("Suite: %s\n", report.Name)
+				fmt.Printf("Strategy: %s\n", report.Strategy)
+				fmt.Printf("Questions: %d\n", report.Questions)
+				fmt.Printf("Estimated prompt tokens: mean %d, max %d", report.Prompts.MeanTokens, report.Prompts.MaxTokens)
+				if report.Prompts.MaxQuestionID != "" {
+					fmt.Printf(" (question %s)", report.Prompts.MaxQuestionID)
+				}
+				fmt.Println()
+				if l := report.Lint; l != nil {
+					fmt.Printf("Lint (%s): %d of %d expected answers flagged", l.Model, l.Flagged, l.Checked)
+					if l.Failed > 0 {
+						fmt.Printf(", %d could not be checked", l.Failed)
+					}
+					fmt.Println()
+				}
+			}
+
+			if n := report.Errors(); n > 0 {
+				return fmt.Errorf("test suite has %d error(s)", n)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the report as JSON")
+	cmd.Flags().BoolVar(&lint, "lint", false, "Have the scoring model flag expected answers likely to produce unstable verdicts")
+	cmd.Flags().StringVar(&scoringModel, "scoring-model", scorer.DefaultScoringModel, "Scoring model name (with --lint)")
+	cmd.Flags().StringVar(&scoringEndpoint, "scoring-endpoint", "", "Scoring LLM endpoint URL (with --lint)")
+	cmd.Flags().StringVar(&scoringAPIKey, "scoring-api-key", "", "Scoring API key (with --lint, or set OPENAI_API_KEY or ANTHROPIC_API_KEY)")
+	cmd.Flags().StringVar(&scoringKeyFile, "scoring-api-key-file", "", "File holding the scoring API key (with --lint), e.g. a mounted Kubernetes Secret; re-read when it changes")
+	cmd.Flags().StringVar(&scoringProvider, "scoring-provider", llm.ProviderOpenAI, "Provider of the scoring model (with --lint): openai, anthropic, gemini, vertexai, azure, or a name from --providers")
+	cmd.Flags().StringVar(&providers, "providers", "", "Provider registry file naming providers with their type, endpoint, and API key variable")
+	fixtures.register(cmd)
+
+	return cmd
+}
@@ -0,0 +1,266 @@
+package generator
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// URLListFile is the name of a file in a documents directory that lists
+// the URLs of further reference documents, one per line.
+const URLListFile = "urls.txt"
+
+const (
+	// maxDocumentBytes bounds the size of a downloaded document.
+	maxDocumentBytes = 10 << 20
+
+	// fetchTimeout bounds the download of a document, redirects included.
+	fetchTimeout = 30 * time.Second
+
+	// maxFetchRedirects is the number of redirects followed per document.
+	maxFetchRedirects = 5
+)
+
+// documentExtensions are the file extensions read from a documents directory.
+var documentExtensions = map[string]bool{
+	".md":       true,
+	".markdown": true,
+	".txt":      true,
+	".rst":      true,
+}
+
+// Document is a reference document to draft questions from.
+type Document struct {
+	// Name identifies the document: its path relative to the documents
+	// directory, or its URL.
+	Name    string
+	Content string
+}
+
+// FetchOptions control how LoadDocuments downloads documents.
+type FetchOptions struct {
+	// AllowPrivateNetworks allows URLs, and redirects, to loopback, private,
+	// and link-local addresses, which are refused by default so that a
+	// server does not fetch internal endpoints on behalf of its clients.
+	AllowPrivateNetworks bool
+}
+
+// LoadDocuments reads the reference documents of the given sources. A source
+// is an http(s) URL, a file, or a directory whose markdown and text files
+// are read recursively; a urls.txt file in a directory lists URLs to fetch,
+// one per line, with "#" starting a comment.
+func LoadDocuments(ctx context.Context, sources []string, opts FetchOptions) ([]Document, error) {
+	client := newFetchClient(opts)
+	var docs []Document
+	for _, src := range sources {
+		if isURL(src) {
+			doc, err := fetchDocument(ctx, client, src)
+			if err != nil {
+				return nil, err
+			}
+			docs = append(docs, doc)
+			continue
+		}
+
+		info, err := os.Stat(src)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read documents: %w", err)
+		}
+		if !info.IsDir() {
+			content, err := os.ReadFile(src)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read document: %w", err)
+			}
+			docs = append(docs, Document{Name: filepath.Base(src), Content: string(content)})
+			continue
+		}
+
+		dirDocs, err := loadDirectory(ctx, client, src)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, dirDocs...)
+	}
+
+	// Drop documents without text, e.g. empty files or pages of markup only.
+	nonEmpty := docs[:0]
+	for _, doc := range docs {
+		if strings.TrimSpace(doc.Content) != "" {
+			nonEmpty = append(nonEmpty, doc)
+		}
+	}
+	if len(nonEmpty) == 0 {
+		return nil, fmt.Errorf("no reference documents found in %s", strings.Join(sources, ", "))
+	}
+	return nonEmpty, nil
+}
+
+func loadDirectory(ctx context.Context, client *http.Client, dir string) ([]Document, error) {
+	var docs []Document
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		if d.Name() == URLListFile {
+			urls, err := readURLList(path)
+			if err != nil {
+				return err
+			}
+			for _, u := range urls {
+				doc, err := fetchDocument(ctx, client, u)
+				if err != nil {
+					return err
+				}
+				docs = append(docs, doc)
+			}
+			return nil
+		}
+		if !documentExtensions[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		docs = append(docs, Document{Name: filepath.ToSlash(rel), Content: string(content)})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read documents directory: %w", err)
+	}
+	return docs, nil
+}
+
+// readURLList returns the URLs listed in a urls.txt file.
+func readURLList(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var urls []string
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !isURL(line) {
+			return nil, fmt.Errorf("%s:%d: %q is not an http(s) URL", filepath.Base(path), n, line)
+		}
+		urls = append(urls, line)
+	}
+	return urls, scanner.Err()
+}
+
+func isURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+// newFetchClient returns the HTTP client downloading documents: it gives up
+// after fetchTimeout and maxFetchRedirects, and unless opts allow private
+// networks it refuses to connect to such addresses. The check is made on
+// the address dialed, after DNS resolution, so that neither redirects nor
+// DNS names pointing to internal addresses get around it.
+func newFetchClient(opts FetchOptions) *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !opts.AllowPrivateNetworks {
+		dialer.Control = refusePrivateAddress
+		// Through a proxy, the address dialed would be the proxy's.
+		transport.Proxy = nil
+	}
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   fetchTimeout,
+		Transport: transport,
+		CheckRedirect: func(_ *http.Request, via []*http.Request) error {
+			if len(via) >= maxFetchRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxFetchRedirects)
+			}
+			return nil
+		},
+	}
+}
+
+// refusePrivateAddress is a net.Dialer Control function refusing to connect
+// to loopback, private, link-local, and unspecified addresses.
+func refusePrivateAddress(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("refusing to connect to %s: %w", address, err)
+	}
+	addr := addrPort.Addr().Unmap()
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsUnspecified() {
+		return fmt.Errorf("refusing to connect to private address %s", addr)
+	}
+	return nil
+}
+
+// fetchDocument downloads a document. HTML pages are converted to plain text.
+func fetchDocument(ctx context.Context, client *http.Client, url string) (Document, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Document{}, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return Document{}, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return Document{}, fmt.Errorf("failed to fetch %s: %s", url, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDocumentBytes))
+	if err != nil {
+		return Document{}, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	content := string(data)
+	if strings.Contains(resp.Header.Get("Content-Type"), "html") {
+		content = htmlToText(content)
+	}
+	return Document{Name: url, Content: content}, nil
+}
+
+var (
+	htmlSkipPattern  = regexp.MustCompile(`(?is)<(script|style|nav|header|footer)\b.*?</(script|style|nav|header|footer)>`)
+	htmlBreakPattern = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|li|h[1-6]|pre|tr)>`)
+	htmlTagPattern   = regexp.MustCompile(`<[^>]*>`)
+	blankLinePattern = regexp.MustCompile(`\n\s*\n+`)
+)
+
+// htmlToText reduces an HTML page to its text, dropping scripts, styles,
+// and navigation.
+func htmlToText(s string) string {
+	s = htmlSkipPattern.ReplaceAllString(s, "")
+	s = htmlBreakPattern.ReplaceAllString(s, "\n")
+	s = htmlTagPattern.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	return strings.TrimSpace(blankLinePattern.ReplaceAllString(s, "\n\n"))
+}
@@ -0,0 +1,82 @@
+package generator
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadDocuments(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/page":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte(`<html><head><style>p {}</style></head><body><nav>Menu</nav><h1>Services</h1><p>A Service exposes &amp; balances pods.</p></body></html>`))
+		case "/notes.md":
+			_, _ = w.Write([]byte("# Notes"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "guides"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "guides", "pods.md"), []byte("# Pods"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".git", "HEAD.md"), []byte("hidden"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "empty.md"), []byte("  \n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "image.png"), []byte("binary"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, URLListFile), []byte("# references\n"+srv.URL+"/page\n"), 0o644))
+
+	docs, err := LoadDocuments(context.Background(), []string{dir, srv.URL + "/notes.md"}, FetchOptions{AllowPrivateNetworks: true})
+	require.NoError(t, err)
+	require.Len(t, docs, 3)
+	assert.Equal(t, Document{Name: "guides/pods.md", Content: "# Pods"}, docs[0])
+	assert.Equal(t, Document{Name: srv.URL + "/page", Content: "Services\nA Service exposes & balances pods."}, docs[1])
+	assert.Equal(t, Document{Name: srv.URL + "/notes.md", Content: "# Notes"}, docs[2])
+
+	_, err = LoadDocuments(context.Background(), []string{srv.URL + "/missing"}, FetchOptions{AllowPrivateNetworks: true})
+	assert.ErrorContains(t, err, "404")
+
+	_, err = LoadDocuments(context.Background(), []string{filepath.Join(dir, "guides", "missing")}, FetchOptions{AllowPrivateNetworks: true})
+	assert.Error(t, err)
+}
+
+func TestLoadDocumentsRejectsInvalidURLList(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, URLListFile), []byte("ftp://example.com/doc\n"), 0o644))
+
+	_, err := LoadDocuments(context.Background(), []string{dir}, FetchOptions{})
+	assert.ErrorContains(t, err, "urls.txt:1")
+}
+
+func TestLoadDocumentsRefusesPrivateAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("internal"))
+	}))
+	defer srv.Close()
+
+	_, err := LoadDocuments(context.Background(), []string{srv.URL + "/secret"}, FetchOptions{})
+	assert.ErrorContains(t, err, "refusing to connect to private address 127.0.0.1")
+
+	docs, err := LoadDocuments(context.Background(), []string{srv.URL + "/secret"}, FetchOptions{AllowPrivateNetworks: true})
+	require.NoError(t, err)
+	assert.Equal(t, "internal", docs[0].Content)
+}
+
+func TestLoadDocumentsLimitsRedirects(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, r.URL.Path+"x", http.StatusFound)
+	}))
+	defer srv.Close()
+
+	_, err := LoadDocuments(context.Background(), []string{srv.URL + "/loop"}, FetchOptions{AllowPrivateNetworks: true})
+	assert.ErrorContains(t, err, "stopped after 5 redirects")
+}
@@ -0,0 +1,227 @@
+// Package generator drafts test suite questions from reference documents
+// using an LLM, to bootstrap domain-specific suites that are then reviewed
+// by a human before use.
+package generator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/giantswarm/llm-testing/internal/llm"
+	"github.com/giantswarm/llm-testing/internal/testsuite"
+)
+
+// Defaults of Config.
+const (
+	DefaultQuestionsPerDocument = 5
+	DefaultMaxDocumentChars     = 24000
+)
+
+// Config configures question generation.
+type Config struct {
+	// Model drafts the questions.
+	Model string
+
+	// QuestionsPerDocument is the number of questions requested per
+	// document, or per part of documents longer than MaxDocumentChars.
+	QuestionsPerDocument int
+
+	// MaxDocumentChars is the size of the parts long documents are split
+	// into, to fit the model's context.
+	MaxDocumentChars int
+
+	// Temperature for generation. Nil means 0.
+	Temperature *float64
+}
+
+// Result holds the drafted questions and the documents that failed.
+type Result struct {
+	Questions []testsuite.Question
+
+	// Failed maps the name of each document (part) that yielded no
+	// questions to the reason.
+	Failed map[string]string
+}
+
+// ProgressFunc is called after each document part with the number of
+// completed and total parts.
+type ProgressFunc func(completed, total int)
+
+// Generator drafts questions from reference documents.
+type Generator struct {
+	client   llm.Client
+	config   Config
+	progress ProgressFunc // optional
+}
+
+// New creates a Generator.
+func New(client llm.Client, config Config) *Generator {
+	if config.QuestionsPerDocument <= 0 {
+		config.QuestionsPerDocument = DefaultQuestionsPerDocument
+	}
+	if config.MaxDocumentChars <= 0 {
+		config.MaxDocumentChars = DefaultMaxDocumentChars
+	}
+	if config.Temperature == nil {
+		config.Temperature = llm.Float64Ptr(0)
+	}
+	return &Generator{client: client, config: config}
+}
+
+// SetProgressFunc sets the callback reporting completed document parts.
+func (g *Generator) SetProgressFunc(fn ProgressFunc) {
+	g.progress = fn
+}
+
+// Generate drafts questions from each document. Questions are numbered in
+// document order, and questions asked twice are dropped. Documents that
+// fail are recorded in the result; it is an error if none yields a question.
+func (g *Generator) Generate(ctx context.Context, docs []Document) (*Result, error) {
+	var parts []Document
+	for _, doc := range docs {
+		parts = append(parts, split(doc, g.config.MaxDocumentChars)...)
+	}
+
+	result := &Result{}
+	seen := make(map[string]bool)
+	for i, part := range parts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		drafted, err := g.draft(ctx, part)
+		if err != nil {
+			slog.Warn("failed to generate questions", "document", part.Name, "error", err)
+			if result.Failed == nil {
+				result.Failed = make(map[string]string)
+			}
+			result.Failed[part.Name] = err.Error()
+		}
+		for _, q := range drafted {
+			key := strings.ToLower(strings.TrimSpace(q.QuestionText))
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			q.ID = strconv.Itoa(len(result.Questions) + 1)
+			result.Questions = append(result.Questions, q)
+		}
+
+		if g.progress != nil {
+			g.progress(i+1, len(parts))
+		}
+	}
+
+	if len(result.Questions) == 0 {
+		return nil, fmt.Errorf("no questions generated from %d document(s)", len(docs))
+	}
+	return result, nil
+}
+
+// draftedQuestion is a question as returned by the model.
+type draftedQuestion struct {
+	Question   string   `json:"question"`
+	Answer     string   `json:"answer"`
+	Section    string   `json:"section"`
+	Difficulty string   `json:"difficulty"`
+	Tags       []string `json:"tags"`
+}
+
+// draft asks the model for questions on one document part.
+func (g *Generator) draft(ctx context.Context, doc Document) ([]testsuite.Question, error) {
+	resp, err := g.client.ChatCompletion(ctx, llm.ChatRequest{
+		Model:         g.config.Model,
+		SystemMessage: GenerationPrompt,
+		UserMessage: fmt.Sprintf("Write %d questions on the following document.\n\nDOCUMENT: %s\n\n%s",
+			g.config.QuestionsPerDocument, doc.Name, doc.Content),
+		Temperature: g.config.Temperature,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("generation failed: %w", err)
+	}
+	return parseQuestions(resp.Content, doc.Name)
+}
+
+// parseQuestions parses the model's JSON array of questions. Text around the
+// array, e.g. a markdown code fence, is ignored. Questions without a question
+// or answer are dropped, and those without a section get defaultSection.
+func parseQuestions(text, defaultSection string) ([]testsuite.Question, error) {
+	start, end := strings.Index(text, "["), strings.LastIndex(text, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("could not find a JSON array of questions in output: %q", truncate(text, 200))
+	}
+	var drafted []draftedQuestion
+	if err := json.Unmarshal([]byte(text[start:end+1]), &drafted); err != nil {
+		return nil, fmt.Errorf("could not parse questions from output: %w", err)
+	}
+
+	var questions []testsuite.Question
+	for _, d := range drafted {
+		q := testsuite.Question{
+			Section:        strings.TrimSpace(d.Section),
+			QuestionText:   strings.TrimSpace(d.Question),
+			ExpectedAnswer: strings.TrimSpace(d.Answer),
+			Difficulty:     strings.ToLower(strings.TrimSpace(d.Difficulty)),
+		}
+		if q.QuestionText == "" || q.ExpectedAnswer == "" {
+			continue
+		}
+		if q.Section == "" {
+			q.Section = defaultSection
+		}
+		for _, tag := range d.Tags {
+			q.Tags = append(q.Tags, testsuite.ParseTags(tag)...)
+		}
+		questions = append(questions, q)
+	}
+	if len(questions) == 0 {
+		return nil, fmt.Errorf("output contains no questions with an answer")
+	}
+	return questions, nil
+}
+
+// split divides a document into parts of at most maxChars, preferably at
+// paragraph boundaries. Parts are named "<name> (part n)".
+func split(doc Document, maxChars int) []Document {
+	if len(doc.Content) <= maxChars {
+		return []Document{doc}
+	}
+
+	var parts []Document
+	rest := doc.Content
+	for len(rest) > 0 {
+		cut := len(rest)
+		if cut > maxChars {
+			cut = maxChars
+			if i := strings.LastIndex(rest[:cut], "\n\n"); i > maxChars/2 {
+				cut = i
+			}
+			for cut > 0 && !utf8.RuneStart(rest[cut]) {
+				cut--
+			}
+			if cut == 0 {
+				_, cut = utf8.DecodeRuneInString(rest)
+			}
+		}
+		if content := strings.TrimSpace(rest[:cut]); content != "" {
+			parts = append(parts, Document{
+				Name:    fmt.Sprintf("%s (part %d)", doc.Name, len(parts)+1),
+				Content: content,
+			})
+		}
+		rest = rest[cut:]
+	}
+	return parts
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,99 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/giantswarm/llm-testing/internal/testsuite"
+	"github.com/giantswarm/llm-testing/internal/testutil"
+)
+
+func TestGenerate(t *testing.T) {
+	client := &testutil.MockLLMClient{
+		Errors: []error{errors.New("timeout")},
+		DefaultResponse: "```json\n" + `[
+  {"question": "What does kubectl get pods list?", "answer": "The pods in the namespace", "section": "Pods", "difficulty": "Easy", "tags": ["kubectl", "pods"]},
+  {"question": "Which object runs a pod on every node?", "answer": "A DaemonSet", "tags": ["workloads"]},
+  {"question": "Unanswered?", "answer": ""}
+]` + "\n```",
+	}
+	g := New(client, Config{Model: "drafter", QuestionsPerDocument: 3})
+	var progress []int
+	g.SetProgressFunc(func(completed, total int) { progress = append(progress, completed) })
+
+	docs := []Document{
+		{Name: "broken.md", Content: "first"},
+		{Name: "pods.md", Content: "second"},
+		{Name: "again.md", Content: "third"},
+	}
+	result, err := g.Generate(context.Background(), docs)
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{1, 2, 3}, progress)
+	assert.Equal(t, "drafter", client.LastRequest.Model)
+	assert.Contains(t, client.LastRequest.UserMessage, "Write 3 questions")
+	assert.Contains(t, result.Failed["broken.md"], "timeout")
+
+	require.Len(t, result.Questions, 2, "unanswered and repeated questions are dropped")
+	assert.Equal(t, testsuite.Question{
+		ID:             "1",
+		Section:        "Pods",
+		QuestionText:   "What does kubectl get pods list?",
+		ExpectedAnswer: "The pods in the namespace",
+		Difficulty:     "easy",
+		Tags:           []string{"kubectl", "pods"},
+	}, result.Questions[0])
+	assert.Equal(t, "2", result.Questions[1].ID)
+	assert.Equal(t, "pods.md", result.Questions[1].Section, "the document names questions without a section")
+}
+
+func TestGenerateWithoutQuestions(t *testing.T) {
+	g := New(&testutil.MockLLMClient{DefaultResponse: "I cannot help with that."}, Config{})
+	_, err := g.Generate(context.Background(), []Document{{Name: "a.md", Content: "text"}})
+	assert.Error(t, err)
+}
+
+func TestSplit(t *testing.T) {
+	doc := Document{Name: "long.md", Content: strings.Repeat("a", 60) + "\n\n" + strings.Repeat("b", 30) + strings.Repeat("é", 20)}
+
+	parts := split(doc, 80)
+	require.Len(t, parts, 2)
+	assert.Equal(t, "long.md (part 1)", parts[0].Name)
+	assert.Equal(t, strings.Repeat("a", 60), parts[0].Content, "split at the paragraph break")
+	assert.Equal(t, strings.Repeat("b", 30)+strings.Repeat("é", 20), parts[1].Content)
+
+	for _, part := range split(Document{Name: "x", Content: strings.Repeat("é", 10)}, 3) {
+		assert.Equal(t, "é", part.Content, "runes are not split")
+	}
+
+	assert.Equal(t, []Document{{Name: "short.md", Content: "short"}}, split(Document{Name: "short.md", Content: "short"}, 80))
+}
+
+func TestWriteSuite(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "generated")
+	questions := []testsuite.Question{{ID: "1", Section: "Pods", QuestionText: "Q?", ExpectedAnswer: "A", Tags: []string{"pods"}}}
+	info := SuiteInfo{Name: "Generated", Model: "drafter", Sources: []string{"docs/pods.md"}}
+
+	require.NoError(t, WriteSuite(dir, info, questions, false))
+
+	config, err := os.ReadFile(filepath.Join(dir, "config.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(config), "drafted by drafter")
+	assert.Contains(t, string(config), "#   - docs/pods.md")
+
+	suite, err := testsuite.Load("generated", filepath.Dir(dir))
+	require.NoError(t, err)
+	assert.Equal(t, "Generated", suite.Name)
+	assert.Equal(t, questions, suite.Questions)
+
+	assert.ErrorIs(t, WriteSuite(dir, info, questions, false), fs.ErrExist, "existing suites are not overwritten")
+	assert.NoError(t, WriteSuite(dir, info, questions, true))
+}
@@ -0,0 +1,22 @@
+package generator
+
+// GenerationPrompt is the system prompt used to draft questions from a
+// reference document. The user message holds the document and the number
+// of questions to draft.
+const GenerationPrompt = `You are an expert exam author, writing questions that test a candidate's practical knowledge of the subject of a reference document.
+
+Write questions that:
+- can be answered from the document alone, without referring to it ("according to the document" is not allowed),
+- test understanding and practical use rather than trivia or wording,
+- have one short, unambiguous expected answer, e.g. a command, a value, or one or two sentences.
+
+Reply with a JSON array only, no other text. Each element is an object with these fields:
+- "question": the question
+- "answer": the expected answer
+- "section": a short topic name the question belongs to
+- "difficulty": "easy", "medium", or "hard"
+- "tags": an array of one to three lowercase topic tags
+
+Example output:
+
+[{"question": "Which kubectl command shows the rollout history of the deployment 'web'?", "answer": "kubectl rollout history deployment/web", "section": "Deployments", "difficulty": "easy", "tags": ["kubectl", "deployments"]}]`
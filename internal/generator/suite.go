@@ -0,0 +1,85 @@
+package generator
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/giantswarm/llm-testing/internal/importer"
+	"github.com/giantswarm/llm-testing/internal/testsuite"
+)
+
+// SuiteInfo describes a generated suite for its config.yaml.
+type SuiteInfo struct {
+	Name        string
+	Description string
+	Model       string   // model that drafted the questions
+	Sources     []string // reference documents the questions were drafted from
+}
+
+// WriteSuite writes a suite directory with a config.yaml and the questions
+// in questions.csv. Existing files are only replaced if force is set.
+func WriteSuite(dir string, info SuiteInfo, questions []testsuite.Question, force bool) error {
+	configPath := filepath.Join(dir, "config.yaml")
+	questionsPath := filepath.Join(dir, "questions.csv")
+	if !force {
+		for _, path := range []string{configPath, questionsPath} {
+			if _, err := os.Stat(path); err == nil {
+				return fmt.Errorf("%w: %s (use force to overwrite)", fs.ErrExist, path)
+			}
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create suite directory: %w", err)
+	}
+
+	qf, err := os.Create(questionsPath)
+	if err != nil {
+		return fmt.Errorf("failed to create questions file: %w", err)
+	}
+	if err := importer.WriteQuestions(qf, questions); err != nil {
+		_ = qf.Close()
+		return err
+	}
+	if err := qf.Close(); err != nil {
+		return fmt.Errorf("failed to write questions file: %w", err)
+	}
+
+	if err := os.WriteFile(configPath, []byte(configTemplate(info)), 0o644); err != nil {
+		return fmt.Errorf("failed to write config.yaml: %w", err)
+	}
+	return nil
+}
+
+// configTemplate returns the config.yaml of a generated suite, noting how
+// it was generated so that reviewers know to check every question.
+func configTemplate(info SuiteInfo) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Test Suite Configuration (generated)\n")
+	fmt.Fprintf(&b, "#\n")
+	fmt.Fprintf(&b, "# The questions were drafted by %s from:\n", info.Model)
+	for _, src := range info.Sources {
+		fmt.Fprintf(&b, "#   - %s\n", src)
+	}
+	fmt.Fprintf(&b, "# Review every question and expected answer before using the suite.\n\n")
+
+	description := info.Description
+	if description == "" {
+		description = "Generated from reference documents"
+	}
+	fmt.Fprintf(&b, `name: %q
+description: %q
+version: "1"
+strategy: "qa"
+questions_file: "questions.csv"
+
+prompt:
+  role: "assistant"
+  system_message: |
+    You are an expert assistant. Answer each question accurately and concisely.
+`, info.Name, description)
+	return b.String()
+}
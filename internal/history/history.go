@@ -0,0 +1,347 @@
+// Package history records scoring summaries over time so that score trends
+// across runs of the same suite and model can be inspected.
+package history
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/giantswarm/llm-testing/internal/identity"
+	"github.com/giantswarm/llm-testing/internal/scorer"
+)
+
+// DirName is the name of the history directory inside the results output directory.
+const DirName = "history"
+
+// Entry is a single scoring summary in a model's history.
+type Entry struct {
+	Timestamp     string   `json:"timestamp"`
+	RunID         string   `json:"run_id"`
+	Suite         string   `json:"suite"`
+	Model         string   `json:"model"`
+	ModelID       string   `json:"model_id"` // canonical identity (see identity.Registry)
+	ModelURI      string   `json:"model_uri,omitempty"`
+	ScoringModel  string   `json:"scoring_model"`
+	ScoresFile    string   `json:"scores_file"`
+	MeanCorrect   *float64 `json:"mean_correct"`
+	MeanPercent   *float64 `json:"mean_percentage"`
+	Variance      *float64 `json:"variance"`
+	AllRunsParsed bool     `json:"all_runs_parsed"`
+
+	HallucinationRate *float64 `json:"hallucination_rate,omitempty"`
+
+	// SuiteVersion and SuiteHash identify the suite content the run was
+	// executed against. SuiteChanged is set when the hash differs from the
+	// previous entry's, so that the scores are not directly comparable.
+	SuiteVersion string `json:"suite_version,omitempty"`
+	SuiteHash    string `json:"suite_hash,omitempty"`
+	SuiteChanged bool   `json:"suite_changed,omitempty"`
+}
+
+// runMetadata is the subset of resultset.json needed to attribute a score.
+type runMetadata struct {
+	ID           string `json:"id"`
+	Suite        string `json:"suite"`
+	SuiteVersion string `json:"suite_version"`
+	SuiteHash    string `json:"suite_hash"`
+	Models       []struct {
+		ModelName   string `json:"model_name"`
+		ModelURI    string `json:"model_uri"`
+		ResultsFile string `json:"results_file"`
+	} `json:"models"`
+}
+
+// Record adds the summary of a scored results file to the history of its
+// suite and model, replacing the entry of an earlier scoring of the same
+// scores file, e.g. before a rescore. The run is identified from the
+// resultset.json next to the results file, and the history is stored in the
+// output directory that contains the run. The model is keyed by its
+// canonical ID as resolved by the registry, which may be nil. The entry is
+// marked SuiteChanged if the previous entry was scored against different
+// suite content.
+func Record(resultsFile, scoresFile string, output *scorer.ScoreOutput, registry *identity.Registry) (*Entry, error) {
+	runDir := filepath.Dir(resultsFile)
+
+	data, err := os.ReadFile(filepath.Join(runDir, "resultset.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run metadata: %w", err)
+	}
+	var meta runMetadata
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("failed to parse run metadata: %w", err)
+	}
+
+	model, modelURI := "", ""
+	for _, m := range meta.Models {
+		if filepath.Base(m.ResultsFile) == filepath.Base(resultsFile) {
+			model, modelURI = m.ModelName, m.ModelURI
+			break
+		}
+	}
+	if model == "" {
+		return nil, fmt.Errorf("results file %s is not part of run %s", filepath.Base(resultsFile), meta.ID)
+	}
+
+	entry := Entry{
+		Timestamp:     output.Metadata.Timestamp,
+		RunID:         meta.ID,
+		Suite:         meta.Suite,
+		Model:         model,
+		ModelID:       registry.Resolve(model, modelURI),
+		ModelURI:      modelURI,
+		ScoringModel:  output.Metadata.ScoringModel,
+		ScoresFile:    scoresFile,
+		MeanCorrect:   output.Summary.MeanCorrect,
+		MeanPercent:   output.Summary.MeanPercent,
+		Variance:      output.Summary.Variance,
+		AllRunsParsed: output.Summary.AllRunsParsed,
+
+		HallucinationRate: output.Summary.HallucinationRate,
+
+		SuiteVersion: meta.SuiteVersion,
+		SuiteHash:    meta.SuiteHash,
+	}
+
+	dir := filepath.Join(filepath.Dir(runDir), DirName)
+	if entry.SuiteHash != "" {
+		modelID := entry.ModelID
+		if modelID == "" {
+			modelID = entry.Model
+		}
+		previous, err := Load(dir, entry.Suite, modelID)
+		if err != nil {
+			return nil, err
+		}
+		previous = slices.DeleteFunc(previous, entry.sameScores)
+		if prev := lastHashed(previous); prev != nil && prev.SuiteHash != entry.SuiteHash {
+			entry.SuiteChanged = true
+		}
+	}
+
+	if err := Put(dir, entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// sameScores reports whether other records the scores of the same scores
+// file of the same run as e.
+func (e Entry) sameScores(other Entry) bool {
+	return other.RunID == e.RunID && other.ScoresFile == e.ScoresFile
+}
+
+// Append adds an entry to the history file for its suite and model ID.
+// History files are JSON Lines stored at <dir>/<suite>/<model-id>.jsonl.
+// Entries without a model ID are keyed by model name.
+func Append(dir string, entry Entry) error {
+	suiteDir := filepath.Join(dir, sanitize(entry.Suite))
+	if err := os.MkdirAll(suiteDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	if entry.ModelID == "" {
+		entry.ModelID = entry.Model
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history entry: %w", err)
+	}
+
+	path := filepath.Join(suiteDir, sanitize(entry.ModelID)+".jsonl")
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write history entry: %w", err)
+	}
+	return nil
+}
+
+// Put adds an entry like Append, but replaces the entry with the same run
+// and scores file if the history has one.
+func Put(dir string, entry Entry) error {
+	if entry.ModelID == "" {
+		entry.ModelID = entry.Model
+	}
+	path := filepath.Join(dir, sanitize(entry.Suite), sanitize(entry.ModelID)+".jsonl")
+	entries, err := readFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Append(dir, entry)
+	}
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(entries, entry.sameScores)
+	if i < 0 {
+		return Append(dir, entry)
+	}
+	entries[i] = entry
+
+	var data []byte
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("failed to marshal history entry: %w", err)
+		}
+		data = append(append(data, line...), '\n')
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+	return nil
+}
+
+// Load returns the history entries matching the given suite and canonical
+// model ID, ordered by timestamp (oldest first). Empty suite or model ID match all.
+// Entries are marked SuiteChanged by their position in this order, so that
+// entries recorded out of order are compared with their actual predecessor.
+func Load(dir, suite, modelID string) ([]Entry, error) {
+	suiteDirs, err := matchingDirs(dir, suite)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, suiteDir := range suiteDirs {
+		files, err := os.ReadDir(suiteDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read history directory: %w", err)
+		}
+		for _, f := range files {
+			if f.IsDir() || !strings.HasSuffix(f.Name(), ".jsonl") {
+				continue
+			}
+			if modelID != "" && f.Name() != sanitize(modelID)+".jsonl" {
+				continue
+			}
+			fileEntries, err := readFile(filepath.Join(suiteDir, f.Name()))
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, fileEntries...)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp < entries[j].Timestamp
+	})
+	markSuiteChanges(entries)
+	return entries, nil
+}
+
+// markSuiteChanges sets SuiteChanged on the entries whose suite hash differs
+// from that of the previous hashed entry of the same suite and model.
+func markSuiteChanges(entries []Entry) {
+	previous := map[[2]string]string{}
+	for i := range entries {
+		e := &entries[i]
+		if e.SuiteHash == "" {
+			continue
+		}
+		key := [2]string{e.Suite, cmp.Or(e.ModelID, e.Model)}
+		prev, ok := previous[key]
+		e.SuiteChanged = ok && prev != e.SuiteHash
+		previous[key] = e.SuiteHash
+	}
+}
+
+// lastHashed returns the most recent entry recording a suite hash, or nil.
+func lastHashed(entries []Entry) *Entry {
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].SuiteHash != "" {
+			return &entries[i]
+		}
+	}
+	return nil
+}
+
+func matchingDirs(dir, suite string) ([]string, error) {
+	if suite != "" {
+		path := filepath.Join(dir, sanitize(suite))
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read history directory: %w", err)
+	}
+	var dirs []string
+	for _, e := range entries {
+		if e.IsDir() {
+			dirs = append(dirs, filepath.Join(dir, e.Name()))
+		}
+	}
+	return dirs, nil
+}
+
+func readFile(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			return nil, fmt.Errorf("failed to parse history entry in %s: %w", filepath.Base(path), err)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+	return entries, nil
+}
+
+// sanitize converts a suite or model name into a safe file name.
+func sanitize(name string) string {
+	replacer := strings.NewReplacer(
+		"/", "_",
+		"\\", "_",
+		":", "_",
+		"*", "_",
+		"?", "_",
+		"\"", "_",
+		"<", "_",
+		">", "_",
+		"|", "_",
+		" ", "_",
+		"..", "__",
+	)
+	// Dots within names are kept, e.g. in versions, but a leading one would
+	// hide the file, and ".." would leave the history directory.
+	safe := replacer.Replace(name)
+	if strings.HasPrefix(safe, ".") {
+		safe = "_" + safe[1:]
+	}
+	return safe
+}
@@ -0,0 +1,192 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/giantswarm/llm-testing/internal/identity"
+	"github.com/giantswarm/llm-testing/internal/scorer"
+)
+
+func writeRun(t *testing.T, outputDir, runID string) string {
+	t.Helper()
+	return writeHashedRun(t, outputDir, runID, "")
+}
+
+// writeHashedRun writes a run recording the given suite hash, if not empty.
+func writeHashedRun(t *testing.T, outputDir, runID, suiteHash string) string {
+	t.Helper()
+	runDir := filepath.Join(outputDir, runID)
+	require.NoError(t, os.MkdirAll(runDir, 0o755))
+
+	resultsFile := filepath.Join(runDir, "org_model.txt")
+	require.NoError(t, os.WriteFile(resultsFile, []byte("results"), 0o644))
+
+	metadata := `{"id": "` + runID + `", "suite": "Kubernetes CKA", "suite_hash": "` + suiteHash + `", "models": [{"model_name": "org/model", "results_file": "` + resultsFile + `"}]}`
+	require.NoError(t, os.WriteFile(filepath.Join(runDir, "resultset.json"), []byte(metadata), 0o644))
+	return resultsFile
+}
+
+func scoreOutput(timestamp string, percent float64) *scorer.ScoreOutput {
+	return &scorer.ScoreOutput{
+		Metadata: scorer.ScoreMetadata{Timestamp: timestamp, ScoringModel: "judge"},
+		Summary:  scorer.Summary{MeanPercent: &percent, AllRunsParsed: true},
+	}
+}
+
+func TestRecordAndLoad(t *testing.T) {
+	outputDir := t.TempDir()
+
+	// Record out of order to verify sorting by timestamp.
+	later := writeRun(t, outputDir, "run-2")
+	_, err := Record(later, later+"_scores.json", scoreOutput("2026-02-01T00:00:00Z", 70), nil)
+	require.NoError(t, err)
+
+	earlier := writeRun(t, outputDir, "run-1")
+	entry, err := Record(earlier, earlier+"_scores.json", scoreOutput("2026-01-01T00:00:00Z", 80), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Kubernetes CKA", entry.Suite)
+	assert.Equal(t, "org/model", entry.Model)
+	assert.Equal(t, "org/model", entry.ModelID)
+	assert.Equal(t, "run-1", entry.RunID)
+
+	dir := filepath.Join(outputDir, DirName)
+	assert.FileExists(t, filepath.Join(dir, "Kubernetes_CKA", "org_model.jsonl"))
+
+	entries, err := Load(dir, "Kubernetes CKA", "org/model")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "run-1", entries[0].RunID)
+	assert.Equal(t, "run-2", entries[1].RunID)
+	require.NotNil(t, entries[1].MeanPercent)
+	assert.InDelta(t, 70.0, *entries[1].MeanPercent, 0.01)
+
+	all, err := Load(dir, "", "")
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+
+	none, err := Load(dir, "Other Suite", "")
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
+
+func TestRecordReplacesRescoredEntry(t *testing.T) {
+	outputDir := t.TempDir()
+	first := writeHashedRun(t, outputDir, "run-1", "sha256:aaa")
+	_, err := Record(first, first+"_scores.json", scoreOutput("2026-01-01T00:00:00Z", 80), nil)
+	require.NoError(t, err)
+	second := writeHashedRun(t, outputDir, "run-2", "sha256:bbb")
+	_, err = Record(second, second+"_scores.json", scoreOutput("2026-01-02T00:00:00Z", 60), nil)
+	require.NoError(t, err)
+
+	entry, err := Record(second, second+"_scores.json", scoreOutput("2026-01-03T00:00:00Z", 70), nil)
+	require.NoError(t, err)
+	assert.True(t, entry.SuiteChanged, "compared with run-1, not the replaced entry")
+
+	entries, err := Load(filepath.Join(outputDir, DirName), "Kubernetes CKA", "org/model")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "run-2", entries[1].RunID)
+	assert.InDelta(t, 70.0, *entries[1].MeanPercent, 0.01)
+}
+
+func TestRecordFlagsSuiteChange(t *testing.T) {
+	outputDir := t.TempDir()
+
+	first := writeHashedRun(t, outputDir, "run-1", "sha256:aaa")
+	entry, err := Record(first, "", scoreOutput("2026-01-01T00:00:00Z", 80), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:aaa", entry.SuiteHash)
+	assert.False(t, entry.SuiteChanged, "the first run has nothing to compare with")
+
+	unhashed := writeRun(t, outputDir, "run-2")
+	entry, err = Record(unhashed, "", scoreOutput("2026-01-02T00:00:00Z", 80), nil)
+	require.NoError(t, err)
+	assert.False(t, entry.SuiteChanged, "runs without a hash are not compared")
+
+	same := writeHashedRun(t, outputDir, "run-3", "sha256:aaa")
+	entry, err = Record(same, "", scoreOutput("2026-01-03T00:00:00Z", 75), nil)
+	require.NoError(t, err)
+	assert.False(t, entry.SuiteChanged)
+
+	changed := writeHashedRun(t, outputDir, "run-4", "sha256:bbb")
+	entry, err = Record(changed, "", scoreOutput("2026-01-04T00:00:00Z", 90), nil)
+	require.NoError(t, err)
+	assert.True(t, entry.SuiteChanged)
+
+	entries, err := Load(filepath.Join(outputDir, DirName), "Kubernetes CKA", "org/model")
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+	assert.True(t, entries[3].SuiteChanged)
+}
+
+func TestLoadFlagsSuiteChangeOfEntriesRecordedOutOfOrder(t *testing.T) {
+	outputDir := t.TempDir()
+	newer := writeHashedRun(t, outputDir, "run-2", "sha256:bbb")
+	entry, err := Record(newer, "", scoreOutput("2026-01-02T00:00:00Z", 60), nil)
+	require.NoError(t, err)
+	assert.False(t, entry.SuiteChanged)
+
+	// An older run scored later precedes the newer one in the history.
+	older := writeHashedRun(t, outputDir, "run-1", "sha256:aaa")
+	_, err = Record(older, "", scoreOutput("2026-01-01T00:00:00Z", 80), nil)
+	require.NoError(t, err)
+
+	entries, err := Load(filepath.Join(outputDir, DirName), "Kubernetes CKA", "org/model")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "run-1", entries[0].RunID)
+	assert.False(t, entries[0].SuiteChanged)
+	assert.True(t, entries[1].SuiteChanged, "run-2 changed the suite of run-1")
+}
+
+func TestRecordUnknownResultsFile(t *testing.T) {
+	outputDir := t.TempDir()
+	writeRun(t, outputDir, "run-1")
+
+	other := filepath.Join(outputDir, "run-1", "other.txt")
+	_, err := Record(other, "", scoreOutput("2026-01-01T00:00:00Z", 50), nil)
+	assert.Error(t, err)
+}
+
+func TestSanitize(t *testing.T) {
+	for name, want := range map[string]string{
+		"kubernetes-cka":     "kubernetes-cka",
+		"org/model:v1.5":     "org_model_v1.5",
+		"..":                 "__",
+		"../../etc/passwd":   "______etc_passwd",
+		".hidden":            "_hidden",
+		"model..v2":          "model__v2",
+		`C:\models\my model`: "C__models_my_model",
+	} {
+		assert.Equal(t, want, sanitize(name), name)
+	}
+}
+
+func TestLoadMissingDir(t *testing.T) {
+	entries, err := Load(filepath.Join(t.TempDir(), "missing"), "", "")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestRecordResolvesModelIdentity(t *testing.T) {
+	outputDir := t.TempDir()
+	registry, err := identity.NewRegistry([]identity.Model{
+		{ID: "canonical", Aliases: []string{"org/model"}},
+	})
+	require.NoError(t, err)
+
+	resultsFile := writeRun(t, outputDir, "run-1")
+	entry, err := Record(resultsFile, "", scoreOutput("2026-01-01T00:00:00Z", 80), registry)
+	require.NoError(t, err)
+	assert.Equal(t, "org/model", entry.Model)
+	assert.Equal(t, "canonical", entry.ModelID)
+
+	entries, err := Load(filepath.Join(outputDir, DirName), "Kubernetes CKA", "canonical")
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
@@ -0,0 +1,132 @@
+// Package identity resolves the different names a model is known by
+// (InferenceService name, HuggingFace URI, gateway alias) to one canonical ID,
+// so that results across runs can be attributed to the same model.
+package identity
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Model is a canonical model identity and the names and URIs that refer to it.
+type Model struct {
+	// ID is the canonical identifier used to key history and comparisons.
+	ID string `yaml:"id" json:"id"`
+
+	// Aliases are alternative model names (e.g. InferenceService or gateway names).
+	Aliases []string `yaml:"aliases" json:"aliases,omitempty"`
+
+	// URIs are storage URIs identifying the model, optionally pinned to a
+	// revision or digest (e.g. "hf://org/model" or "hf://org/model@<sha>").
+	URIs []string `yaml:"uris" json:"uris,omitempty"`
+}
+
+// Registry maps model aliases and URIs to canonical model IDs.
+// A nil Registry resolves every name to itself.
+type Registry struct {
+	byName map[string]string
+	byURI  map[string]string
+}
+
+type registryFile struct {
+	Models []Model `yaml:"models"`
+}
+
+// LoadRegistry reads a registry from a YAML file of the form:
+//
+//	models:
+//	  - id: mistral-7b-instruct-v0.3
+//	    aliases: [mistral-7b, mistral]
+//	    uris: [hf://mistralai/Mistral-7B-Instruct-v0.3]
+func LoadRegistry(path string) (*Registry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read model registry: %w", err)
+	}
+
+	var f registryFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse model registry: %w", err)
+	}
+
+	return NewRegistry(f.Models)
+}
+
+// NewRegistry builds a registry from model identities. Names and URIs must
+// map to exactly one canonical ID.
+func NewRegistry(models []Model) (*Registry, error) {
+	r := &Registry{
+		byName: make(map[string]string),
+		byURI:  make(map[string]string),
+	}
+
+	for _, m := range models {
+		if strings.TrimSpace(m.ID) == "" {
+			return nil, fmt.Errorf("model identity without id")
+		}
+		for _, name := range append([]string{m.ID}, m.Aliases...) {
+			if err := register(r.byName, normalizeName(name), m.ID); err != nil {
+				return nil, fmt.Errorf("alias %q: %w", name, err)
+			}
+		}
+		for _, uri := range m.URIs {
+			if err := register(r.byURI, normalizeURI(uri), m.ID); err != nil {
+				return nil, fmt.Errorf("uri %q: %w", uri, err)
+			}
+		}
+	}
+
+	return r, nil
+}
+
+func register(index map[string]string, key, id string) error {
+	if existing, ok := index[key]; ok && existing != id {
+		return fmt.Errorf("already assigned to model %q", existing)
+	}
+	index[key] = id
+	return nil
+}
+
+// Resolve returns the canonical ID for a model. The model URI takes precedence
+// over the name; unknown models resolve to their name unchanged.
+func (r *Registry) Resolve(name, modelURI string) string {
+	if r == nil {
+		return name
+	}
+	if modelURI != "" {
+		uri := normalizeURI(modelURI)
+		if id, ok := r.byURI[uri]; ok {
+			return id
+		}
+		// An unregistered revision still belongs to the registered repository.
+		if base, _, pinned := strings.Cut(uri, "@"); pinned {
+			if id, ok := r.byURI[base]; ok {
+				return id
+			}
+		}
+	}
+	if id, ok := r.byName[normalizeName(name)]; ok {
+		return id
+	}
+	return name
+}
+
+// normalizeName makes alias matching case-insensitive.
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// normalizeURI ignores trailing slashes and the case of the URI scheme and repository,
+// but keeps any "@revision" suffix significant.
+func normalizeURI(uri string) string {
+	uri = strings.TrimSpace(uri)
+	base, revision, hasRevision := strings.Cut(uri, "@")
+	base = strings.ToLower(strings.TrimRight(base, "/"))
+	if hasRevision {
+		return base + "@" + revision
+	}
+	return base
+}
@@ -0,0 +1,72 @@
+package identity
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	r, err := NewRegistry([]Model{
+		{
+			ID:      "mistral-7b-instruct-v0.3",
+			Aliases: []string{"mistral-7b", "Mistral"},
+			URIs:    []string{"hf://mistralai/Mistral-7B-Instruct-v0.3"},
+		},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name, model, uri, want string
+	}{
+		{name: "canonical id", model: "mistral-7b-instruct-v0.3", want: "mistral-7b-instruct-v0.3"},
+		{name: "alias", model: "mistral-7b", want: "mistral-7b-instruct-v0.3"},
+		{name: "alias case-insensitive", model: "MISTRAL", want: "mistral-7b-instruct-v0.3"},
+		{name: "uri wins over name", model: "isvc-123", uri: "hf://mistralai/Mistral-7B-Instruct-v0.3/", want: "mistral-7b-instruct-v0.3"},
+		{name: "pinned revision", model: "x", uri: "hf://mistralai/mistral-7b-instruct-v0.3@abc123", want: "mistral-7b-instruct-v0.3"},
+		{name: "unknown", model: "llama", uri: "hf://meta/llama", want: "llama"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, r.Resolve(tt.model, tt.uri))
+		})
+	}
+}
+
+func TestResolveNilRegistry(t *testing.T) {
+	var r *Registry
+	assert.Equal(t, "model", r.Resolve("model", "hf://org/model"))
+}
+
+func TestNewRegistryConflicts(t *testing.T) {
+	_, err := NewRegistry([]Model{
+		{ID: "a", Aliases: []string{"shared"}},
+		{ID: "b", Aliases: []string{"shared"}},
+	})
+	assert.Error(t, err)
+
+	_, err = NewRegistry([]Model{{Aliases: []string{"x"}}})
+	assert.Error(t, err)
+}
+
+func TestLoadRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "models.yaml")
+	content := `models:
+  - id: qwen-2.5-7b
+    aliases: [qwen, qwen2.5-7b-instruct]
+    uris: [hf://Qwen/Qwen2.5-7B-Instruct]
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	r, err := LoadRegistry(path)
+	require.NoError(t, err)
+	assert.Equal(t, "qwen-2.5-7b", r.Resolve("qwen2.5-7b-instruct", ""))
+	assert.Equal(t, "qwen-2.5-7b", r.Resolve("", "hf://qwen/qwen2.5-7b-instruct"))
+
+	_, err = LoadRegistry(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,268 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// HFDatasetPrefix marks a dataset on the HuggingFace Hub, e.g.
+// "hf://cais/mmlu", as opposed to a local export file.
+const HFDatasetPrefix = "hf://"
+
+// hfRowsURL is the dataset viewer API returning the rows of a dataset split.
+var hfRowsURL = "https://datasets-server.huggingface.co/rows"
+
+// hfPageSize is the maximum number of rows the dataset viewer returns per request.
+const hfPageSize = 100
+
+// MMLUSubject returns the subject of an MMLU file from its name, e.g.
+// "high school biology" for "high_school_biology_test.csv".
+func MMLUSubject(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	for _, split := range []string{"_test", "_dev", "_val"} {
+		name = strings.TrimSuffix(name, split)
+	}
+	return strings.ReplaceAll(name, "_", " ")
+}
+
+// readMMLU reads an MMLU CSV file: rows of a question, its options, and the
+// letter of the correct option, without a header row.
+func readMMLU(r io.Reader, opts Options) (*Table, error) {
+	sep := opts.Separator
+	if sep == 0 {
+		sep = ','
+	}
+	rows, err := readRows(r, sep)
+	if err != nil {
+		return nil, err
+	}
+
+	table := &Table{Header: []string{"Subject", "Question", "Answer"}, MultipleChoice: true}
+	for i, row := range rows {
+		if len(row) < 4 {
+			return nil, fmt.Errorf("row %d has %d fields, expected a question, at least two options, and an answer", i+1, len(row))
+		}
+		choices := row[1 : len(row)-1]
+		question, answer, err := multipleChoice(row[0], choices, row[len(row)-1])
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+1, err)
+		}
+		table.Rows = append(table.Rows, []string{opts.Section, question, answer})
+	}
+	return table, nil
+}
+
+// readHFDataset reads dataset rows in JSON Lines or as a JSON array, as
+// written by the datasets library's to_json.
+func readHFDataset(r io.Reader) (*Table, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dataset: %w", err)
+	}
+
+	var records []map[string]any
+	if trimmed := bytes.TrimSpace(data); bytes.HasPrefix(trimmed, []byte("[")) {
+		dec := json.NewDecoder(bytes.NewReader(trimmed))
+		dec.UseNumber()
+		if err := dec.Decode(&records); err != nil {
+			return nil, fmt.Errorf("failed to parse dataset: %w", err)
+		}
+	} else {
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		for line := 1; scanner.Scan(); line++ {
+			if strings.TrimSpace(scanner.Text()) == "" {
+				continue
+			}
+			dec := json.NewDecoder(strings.NewReader(scanner.Text()))
+			dec.UseNumber()
+			var record map[string]any
+			if err := dec.Decode(&record); err != nil {
+				return nil, fmt.Errorf("failed to parse dataset line %d: %w", line, err)
+			}
+			records = append(records, record)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read dataset: %w", err)
+		}
+	}
+	return recordsTable(records, nil)
+}
+
+// recordsTable converts dataset records to a table. The columns are the
+// given ones, or else the records' fields sorted by name. Multiple-choice
+// records, with a "choices" list and the "answer" index or letter, get the
+// options appended to the question and the correct option as the answer.
+func recordsTable(records []map[string]any, columns []string) (*Table, error) {
+	if len(columns) == 0 {
+		for _, record := range records {
+			for name := range record {
+				if !slices.Contains(columns, name) {
+					columns = append(columns, name)
+				}
+			}
+		}
+		slices.Sort(columns)
+	}
+
+	questionCol := slices.Index(columns, "question")
+	answerCol := slices.Index(columns, "answer")
+	table := &Table{Header: columns}
+	for i, record := range records {
+		row := make([]string, len(columns))
+		for j, name := range columns {
+			row[j] = stringify(record[name])
+		}
+		if choices, ok := record["choices"].([]any); ok && questionCol >= 0 && answerCol >= 0 {
+			options := make([]string, len(choices))
+			for j, c := range choices {
+				options[j] = stringify(c)
+			}
+			question, answer, err := multipleChoice(row[questionCol], options, row[answerCol])
+			if err != nil {
+				return nil, fmt.Errorf("record %d: %w", i+1, err)
+			}
+			row[questionCol], row[answerCol] = question, answer
+			table.MultipleChoice = true
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table, nil
+}
+
+// stringify returns a field value as text: strings as they are, and other
+// values as JSON.
+func stringify(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
+
+// multipleChoice formats a question with its lettered options, and returns
+// the correct option, given by letter or 0-based index, as the answer,
+// e.g. "B. Mitochondria".
+func multipleChoice(question string, choices []string, correct string) (string, string, error) {
+	if len(choices) > 26 {
+		return "", "", fmt.Errorf("too many options: %d", len(choices))
+	}
+	correct = strings.TrimSpace(correct)
+	index := -1
+	if n, err := strconv.Atoi(correct); err == nil {
+		index = n
+	} else if len(correct) == 1 {
+		index = int(strings.ToUpper(correct)[0]) - 'A'
+	}
+	if index < 0 || index >= len(choices) {
+		return "", "", fmt.Errorf("answer %q is not one of %d options", correct, len(choices))
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.TrimSpace(question))
+	b.WriteString("\n")
+	for i, choice := range choices {
+		fmt.Fprintf(&b, "\n%c. %s", 'A'+i, strings.TrimSpace(choice))
+	}
+	return b.String(), fmt.Sprintf("%c. %s", 'A'+index, strings.TrimSpace(choices[index])), nil
+}
+
+// FetchHFDataset downloads up to limit rows (all if limit is zero) of a
+// split of a dataset on the HuggingFace Hub through the dataset viewer API.
+// ref names the dataset as "hf://<owner>/<dataset>"; config selects the
+// dataset's subset, e.g. the MMLU subject.
+func FetchHFDataset(ctx context.Context, ref, config, split string, limit int) (*Table, error) {
+	dataset := strings.TrimPrefix(ref, HFDatasetPrefix)
+	if dataset == "" || strings.Count(dataset, "/") > 1 {
+		return nil, fmt.Errorf("invalid dataset %q: expected %s<owner>/<dataset>", ref, HFDatasetPrefix)
+	}
+
+	var columns []string
+	var records []map[string]any
+	for offset := 0; limit <= 0 || offset < limit; offset += hfPageSize {
+		length := hfPageSize
+		if limit > 0 {
+			length = min(length, limit-offset)
+		}
+		page, err := fetchHFRows(ctx, dataset, config, split, offset, length)
+		if err != nil {
+			return nil, err
+		}
+		if columns == nil {
+			for _, f := range page.Features {
+				columns = append(columns, f.Name)
+			}
+		}
+		for _, r := range page.Rows {
+			records = append(records, r.Row)
+		}
+		if len(page.Rows) < length || offset+len(page.Rows) >= page.NumRowsTotal {
+			break
+		}
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("dataset %s (config %s, split %s) has no rows", dataset, config, split)
+	}
+	return recordsTable(records, columns)
+}
+
+// hfRowsPage is a response of the dataset viewer rows API.
+type hfRowsPage struct {
+	Features []struct {
+		Name string `json:"name"`
+	} `json:"features"`
+	Rows []struct {
+		Row map[string]any `json:"row"`
+	} `json:"rows"`
+	NumRowsTotal int `json:"num_rows_total"`
+}
+
+func fetchHFRows(ctx context.Context, dataset, config, split string, offset, length int) (*hfRowsPage, error) {
+	query := url.Values{
+		"dataset": {dataset},
+		"config":  {config},
+		"split":   {split},
+		"offset":  {strconv.Itoa(offset)},
+		"length":  {strconv.Itoa(length)},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, hfRowsURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch dataset %s: %w", dataset, err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch dataset %s: %w", dataset, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("failed to fetch dataset %s (config %s, split %s): %s: %s", dataset, config, split, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber()
+	var page hfRowsPage
+	if err := dec.Decode(&page); err != nil {
+		return nil, fmt.Errorf("failed to parse rows of dataset %s: %w", dataset, err)
+	}
+	return &page, nil
+}
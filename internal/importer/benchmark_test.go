@@ -0,0 +1,103 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/giantswarm/llm-testing/internal/testsuite"
+)
+
+func TestReadMMLU(t *testing.T) {
+	export := "Which organelle produces ATP?,Nucleus,Mitochondria,Ribosome,Golgi apparatus,B\n" +
+		"\"What is 2, plus 2?\",3,4,5,6,b\n"
+
+	table, err := Read(strings.NewReader(export), FormatMMLU, Options{Section: MMLUSubject("data/test/high_school_biology_test.csv")})
+	require.NoError(t, err)
+	assert.True(t, table.MultipleChoice)
+
+	questions, skipped, err := Convert(table, SuggestMapping(table.Header), ConvertOptions{})
+	require.NoError(t, err)
+	assert.Zero(t, skipped)
+	require.Len(t, questions, 2)
+	assert.Equal(t, testsuite.Question{
+		ID:             "1",
+		Section:        "high school biology",
+		QuestionText:   "Which organelle produces ATP?\n\nA. Nucleus\nB. Mitochondria\nC. Ribosome\nD. Golgi apparatus",
+		ExpectedAnswer: "B. Mitochondria",
+	}, questions[0])
+	assert.Equal(t, "B. 4", questions[1].ExpectedAnswer)
+
+	_, err = Read(strings.NewReader("Question,A,B,E\n"), FormatMMLU, Options{})
+	assert.ErrorContains(t, err, `answer "E" is not one of 2 options`)
+}
+
+func TestReadHFDataset(t *testing.T) {
+	jsonl := `{"question": "Which port does the API server use?", "subject": "networking", "choices": ["80", "6443", "2379"], "answer": 1}
+{"question": "What stores cluster state?", "subject": "storage", "choices": ["etcd", "kubelet"], "answer": "A"}
+`
+	array := `[{"question": "What is a Pod?", "answer": "The smallest deployable unit", "id": 7}]`
+
+	table, err := Read(strings.NewReader(jsonl), FormatHFDataset, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"answer", "choices", "question", "subject"}, table.Header)
+	assert.True(t, table.MultipleChoice)
+
+	questions, _, err := Convert(table, SuggestMapping(table.Header), ConvertOptions{})
+	require.NoError(t, err)
+	require.Len(t, questions, 2)
+	assert.Equal(t, "networking", questions[0].Section)
+	assert.Equal(t, "Which port does the API server use?\n\nA. 80\nB. 6443\nC. 2379", questions[0].QuestionText)
+	assert.Equal(t, "B. 6443", questions[0].ExpectedAnswer)
+	assert.Equal(t, "A. etcd", questions[1].ExpectedAnswer)
+
+	table, err = Read(strings.NewReader(array), FormatHFDataset, Options{})
+	require.NoError(t, err)
+	assert.False(t, table.MultipleChoice)
+	questions, _, err = Convert(table, SuggestMapping(table.Header), ConvertOptions{})
+	require.NoError(t, err)
+	require.Len(t, questions, 1)
+	assert.Equal(t, "7", questions[0].ID)
+	assert.Equal(t, "The smallest deployable unit", questions[0].ExpectedAnswer)
+}
+
+func TestFetchHFDataset(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("dataset") != "cais/mmlu" || q.Get("config") != "anatomy" || q.Get("split") != "test" {
+			http.Error(w, "unknown dataset", http.StatusNotFound)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"features": [{"name": "question"}, {"name": "subject"}, {"name": "choices"}, {"name": "answer"}],
+"rows": [{"row": {"question": "Q%s", "subject": "anatomy", "choices": ["x", "y"], "answer": 0}}],
+"num_rows_total": 3}`, q.Get("offset"))
+	}))
+	defer srv.Close()
+
+	orig := hfRowsURL
+	hfRowsURL = srv.URL
+	defer func() { hfRowsURL = orig }()
+
+	table, err := FetchHFDataset(context.Background(), "hf://cais/mmlu", "anatomy", "test", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"question", "subject", "choices", "answer"}, table.Header)
+	require.Len(t, table.Rows, 1)
+	assert.Equal(t, "A. x", table.Rows[0][3])
+
+	_, err = FetchHFDataset(context.Background(), "hf://cais/mmlu", "anatomy", "train", 0)
+	assert.ErrorContains(t, err, "404")
+
+	_, err = FetchHFDataset(context.Background(), "hf://", "", "test", 0)
+	assert.ErrorContains(t, err, "invalid dataset")
+}
+
+func TestMMLUSubject(t *testing.T) {
+	assert.Equal(t, "anatomy", MMLUSubject("anatomy_test.csv"))
+	assert.Equal(t, "college computer science", MMLUSubject("/data/dev/college_computer_science_dev.csv"))
+}
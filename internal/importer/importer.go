@@ -0,0 +1,408 @@
+// Package importer converts flashcard and quiz exports (Anki, Quizlet, CSV)
+// and benchmark datasets (MMLU, HuggingFace datasets) into the test suite
+// question schema.
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/giantswarm/llm-testing/internal/testsuite"
+)
+
+// Supported import formats.
+const (
+	// FormatAnki is Anki's "Notes in Plain Text" export, with optional
+	// "#key:value" header lines describing the separator and columns.
+	FormatAnki = "anki"
+	// FormatQuizlet is Quizlet's export: one card per line, term and
+	// definition separated by a tab.
+	FormatQuizlet = "quizlet"
+	// FormatCSV is a delimited file whose first row names the columns.
+	FormatCSV = "csv"
+	// FormatMMLU is the MMLU benchmark's CSV layout: no header row, each row
+	// a question, its options, and the letter of the correct option.
+	FormatMMLU = "mmlu"
+	// FormatHFDataset is a HuggingFace dataset exported as JSON Lines or a
+	// JSON array of rows, or fetched from the Hub (see FetchHFDataset).
+	FormatHFDataset = "hf-dataset"
+)
+
+// Table is the raw content of an export: column names and rows of fields.
+type Table struct {
+	Header []string
+	Rows   [][]string
+
+	// HTML is set when fields contain HTML markup (Anki "#html:true").
+	HTML bool
+
+	// MultipleChoice is set when the questions list lettered options and the
+	// answers name the correct one.
+	MultipleChoice bool
+}
+
+// Options configure how an export is read.
+type Options struct {
+	// Separator overrides the field separator. Zero uses the format default
+	// (tab for Anki and Quizlet, comma for CSV and MMLU).
+	Separator rune
+
+	// Section is the section of every MMLU row, usually the subject from
+	// the file name (see MMLUSubject).
+	Section string
+}
+
+// ValidateFormat returns an error if format is not a supported import format.
+func ValidateFormat(format string) error {
+	switch format {
+	case FormatAnki, FormatQuizlet, FormatCSV, FormatMMLU, FormatHFDataset:
+		return nil
+	default:
+		return fmt.Errorf("invalid format %q (supported: anki, quizlet, csv, mmlu, hf-dataset)", format)
+	}
+}
+
+// Read parses an export in the given format.
+func Read(r io.Reader, format string, opts Options) (*Table, error) {
+	switch format {
+	case FormatAnki:
+		return readAnki(r, opts)
+	case FormatQuizlet:
+		sep := opts.Separator
+		if sep == 0 {
+			sep = '\t'
+		}
+		rows, err := readRows(r, sep)
+		if err != nil {
+			return nil, err
+		}
+		return &Table{Header: []string{"Term", "Definition"}, Rows: rows}, nil
+	case FormatCSV:
+		sep := opts.Separator
+		if sep == 0 {
+			sep = ','
+		}
+		rows, err := readRows(r, sep)
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) == 0 {
+			return nil, fmt.Errorf("CSV file is empty")
+		}
+		header := make([]string, len(rows[0]))
+		for i, col := range rows[0] {
+			header[i] = strings.TrimSpace(col)
+		}
+		return &Table{Header: header, Rows: rows[1:]}, nil
+	case FormatMMLU:
+		return readMMLU(r, opts)
+	case FormatHFDataset:
+		return readHFDataset(r)
+	default:
+		return nil, ValidateFormat(format)
+	}
+}
+
+// ankiSeparators maps the named values of Anki's "#separator:" header.
+var ankiSeparators = map[string]rune{
+	"tab":       '\t',
+	"comma":     ',',
+	"semicolon": ';',
+	"pipe":      '|',
+	"space":     ' ',
+	"colon":     ':',
+}
+
+func readAnki(r io.Reader, opts Options) (*Table, error) {
+	br := bufio.NewReader(r)
+	sep := '\t'
+	isHTML := false
+	var columns []string
+	named := make(map[int]string) // 1-based column index -> name from "#<name> column:N"
+
+	// Header lines come first and start with "#".
+	for {
+		peek, err := br.Peek(1)
+		if err != nil || peek[0] != '#' {
+			break
+		}
+		line, err := br.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to read Anki header: %w", err)
+		}
+		key, value, _ := strings.Cut(strings.TrimRight(strings.TrimPrefix(line, "#"), "\r\n"), ":")
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		switch {
+		case key == "separator":
+			if s, ok := ankiSeparators[strings.ToLower(value)]; ok {
+				sep = s
+			} else if len([]rune(value)) == 1 {
+				sep = []rune(value)[0]
+			}
+		case key == "html":
+			isHTML = value == "true"
+		case key == "columns":
+			columns = strings.Split(value, string(sep))
+		case strings.HasSuffix(key, " column"):
+			if n, err := strconv.Atoi(value); err == nil {
+				named[n] = capitalize(strings.TrimSuffix(key, " column"))
+			}
+		}
+	}
+	if opts.Separator != 0 {
+		sep = opts.Separator
+	}
+
+	rows, err := readRows(br, sep)
+	if err != nil {
+		return nil, err
+	}
+
+	width := len(columns)
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+	header := make([]string, width)
+	for i := range header {
+		switch {
+		case i < len(columns):
+			header[i] = strings.TrimSpace(columns[i])
+		case named[i+1] != "":
+			header[i] = named[i+1]
+		default:
+			header[i] = fmt.Sprintf("Field %d", i+1)
+		}
+	}
+
+	return &Table{Header: header, Rows: rows, HTML: isHTML}, nil
+}
+
+func readRows(r io.Reader, sep rune) ([][]string, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = sep
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = -1
+	reader.Comment = 0
+
+	var rows [][]string
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read row %d: %w", line, err)
+		}
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		rows = append(rows, record)
+	}
+	return rows, nil
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// Mapping selects the table columns holding each question field.
+// Indices are 0-based; -1 means the field is not mapped.
+type Mapping struct {
+	Question int
+	Answer   int
+	Section  int
+	ID       int
+}
+
+// columnHints are lower-case column names recognized for each field, in order of preference.
+var columnHints = map[string][]string{
+	"question": {"question", "front", "term", "prompt", "text"},
+	"answer":   {"expectedanswer", "expected answer", "answer", "back", "definition"},
+	"section":  {"section", "category", "subject", "topic", "deck", "tags"},
+	"id":       {"id", "no", "number", "guid"},
+}
+
+// SuggestMapping guesses a mapping from the column names. When no question or
+// answer column is recognized, the first columns not mapped to another field are used.
+func SuggestMapping(header []string) Mapping {
+	find := func(field string) int {
+		for _, hint := range columnHints[field] {
+			for i, col := range header {
+				if strings.EqualFold(strings.TrimSpace(col), hint) {
+					return i
+				}
+			}
+		}
+		return -1
+	}
+
+	m := Mapping{
+		Question: find("question"),
+		Answer:   find("answer"),
+		Section:  find("section"),
+		ID:       find("id"),
+	}
+
+	unused := func() int {
+		for i := range header {
+			if i != m.Question && i != m.Answer && i != m.Section && i != m.ID {
+				return i
+			}
+		}
+		return -1
+	}
+	if m.Question < 0 {
+		m.Question = unused()
+	}
+	if m.Answer < 0 {
+		m.Answer = unused()
+	}
+	return m
+}
+
+// Column resolves a column reference, either a column name (case-insensitive)
+// or a 1-based index, to a 0-based index.
+func Column(header []string, ref string) (int, error) {
+	ref = strings.TrimSpace(ref)
+	for i, col := range header {
+		if strings.EqualFold(strings.TrimSpace(col), ref) {
+			return i, nil
+		}
+	}
+	if n, err := strconv.Atoi(ref); err == nil && n >= 1 && n <= len(header) {
+		return n - 1, nil
+	}
+	return -1, fmt.Errorf("unknown column %q (available: %s)", ref, strings.Join(header, ", "))
+}
+
+// ConvertOptions configure the conversion of table rows to questions.
+type ConvertOptions struct {
+	// DefaultSection is used when no section column is mapped or the field is empty.
+	DefaultSection string
+}
+
+// Convert turns the table rows into questions using the mapping. Rows without
+// a question or answer are skipped and counted. Questions without an ID are
+// numbered sequentially, skipping the numbers other rows use as their ID;
+// duplicate IDs are an error.
+func Convert(table *Table, m Mapping, opts ConvertOptions) ([]testsuite.Question, int, error) {
+	if m.Question < 0 || m.Answer < 0 {
+		return nil, 0, fmt.Errorf("question and answer columns must be mapped")
+	}
+	if m.Question == m.Answer {
+		return nil, 0, fmt.Errorf("question and answer must be different columns")
+	}
+
+	field := func(row []string, i int) string {
+		if i < 0 || i >= len(row) {
+			return ""
+		}
+		v := row[i]
+		if table.HTML {
+			v = stripHTML(v)
+		}
+		return strings.TrimSpace(v)
+	}
+
+	// Numbers are assigned after the explicit IDs of all rows are known, so
+	// that they do not collide with the IDs of later rows.
+	explicit := make(map[string]bool)
+	for _, row := range table.Rows {
+		if id := field(row, m.ID); id != "" {
+			explicit[id] = true
+		}
+	}
+	next := 0
+	nextID := func() string {
+		for {
+			next++
+			if id := strconv.Itoa(next); !explicit[id] {
+				return id
+			}
+		}
+	}
+
+	var questions []testsuite.Question
+	skipped := 0
+	seen := make(map[string]bool)
+	for _, row := range table.Rows {
+		q := testsuite.Question{
+			QuestionText:   field(row, m.Question),
+			ExpectedAnswer: field(row, m.Answer),
+			Section:        field(row, m.Section),
+			ID:             field(row, m.ID),
+		}
+		if q.QuestionText == "" || q.ExpectedAnswer == "" {
+			skipped++
+			continue
+		}
+		if q.Section == "" {
+			q.Section = opts.DefaultSection
+		}
+		if q.ID == "" {
+			q.ID = nextID()
+		}
+		if seen[q.ID] {
+			return nil, 0, fmt.Errorf("duplicate question ID %q", q.ID)
+		}
+		seen[q.ID] = true
+		questions = append(questions, q)
+	}
+	return questions, skipped, nil
+}
+
+var (
+	htmlBreakPattern = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|li)>`)
+	htmlTagPattern   = regexp.MustCompile(`<[^>]*>`)
+)
+
+// stripHTML converts Anki's HTML fields to plain text.
+func stripHTML(s string) string {
+	s = htmlBreakPattern.ReplaceAllString(s, "\n")
+	s = htmlTagPattern.ReplaceAllString(s, "")
+	return html.UnescapeString(s)
+}
+
+// WriteQuestions writes questions in the suite questions.csv format. The
+// Difficulty and Tags columns are added if any question has them.
+func WriteQuestions(w io.Writer, questions []testsuite.Question) error {
+	withMeta := false
+	for _, q := range questions {
+		if q.Difficulty != "" || len(q.Tags) > 0 {
+			withMeta = true
+			break
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	header := []string{"ID", "Section", "Question", "ExpectedAnswer"}
+	if withMeta {
+		header = append(header, "Difficulty", "Tags")
+	}
+	if err := cw.Write(header); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	for _, q := range questions {
+		record := []string{q.ID, q.Section, q.QuestionText, q.ExpectedAnswer}
+		if withMeta {
+			record = append(record, q.Difficulty, strings.Join(q.Tags, ";"))
+		}
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("failed to write question %s: %w", q.ID, err)
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,161 @@
+package importer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/giantswarm/llm-testing/internal/testsuite"
+)
+
+func TestReadAnki(t *testing.T) {
+	export := "#separator:tab\n#html:true\n#guid column:1\n#deck column:4\n" +
+		"abc\tWhat is a <b>Pod</b>?\tSmallest unit<br>of compute &amp; scheduling\tK8s\n" +
+		"def\t\"Quoted\tfront\"\tback\tK8s\n"
+
+	table, err := Read(strings.NewReader(export), FormatAnki, Options{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"Guid", "Field 2", "Field 3", "Deck"}, table.Header)
+	assert.True(t, table.HTML)
+	require.Len(t, table.Rows, 2)
+	assert.Equal(t, "Quoted\tfront", table.Rows[1][1])
+
+	m := SuggestMapping(table.Header)
+	assert.Equal(t, Mapping{Question: 1, Answer: 2, Section: 3, ID: 0}, m)
+
+	questions, skipped, err := Convert(table, m, ConvertOptions{})
+	require.NoError(t, err)
+	assert.Zero(t, skipped)
+	require.Len(t, questions, 2)
+	assert.Equal(t, testsuite.Question{
+		ID:             "abc",
+		Section:        "K8s",
+		QuestionText:   "What is a Pod?",
+		ExpectedAnswer: "Smallest unit\nof compute & scheduling",
+	}, questions[0])
+}
+
+func TestReadAnkiColumnsHeader(t *testing.T) {
+	export := "#separator:semicolon\n#columns:Front;Back;Tags\nQ1;A1;networking\n"
+
+	table, err := Read(strings.NewReader(export), FormatAnki, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Front", "Back", "Tags"}, table.Header)
+	assert.Equal(t, Mapping{Question: 0, Answer: 1, Section: 2, ID: -1}, SuggestMapping(table.Header))
+}
+
+func TestReadQuizlet(t *testing.T) {
+	export := "kubectl get pods\tList pods\n\nkubectl logs\tShow container logs\n"
+
+	table, err := Read(strings.NewReader(export), FormatQuizlet, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Term", "Definition"}, table.Header)
+	require.Len(t, table.Rows, 2)
+
+	questions, _, err := Convert(table, SuggestMapping(table.Header), ConvertOptions{DefaultSection: "CLI"})
+	require.NoError(t, err)
+	require.Len(t, questions, 2)
+	assert.Equal(t, "2", questions[1].ID)
+	assert.Equal(t, "CLI", questions[1].Section)
+	assert.Equal(t, "kubectl logs", questions[1].QuestionText)
+	assert.Equal(t, "Show container logs", questions[1].ExpectedAnswer)
+}
+
+func TestReadCSVWithSeparator(t *testing.T) {
+	export := "Topic;Prompt;Answer\nStorage;What is a PVC?;A claim for storage\nStorage;;missing question\n"
+
+	table, err := Read(strings.NewReader(export), FormatCSV, Options{Separator: ';'})
+	require.NoError(t, err)
+
+	m := SuggestMapping(table.Header)
+	assert.Equal(t, Mapping{Question: 1, Answer: 2, Section: 0, ID: -1}, m)
+
+	questions, skipped, err := Convert(table, m, ConvertOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, skipped)
+	require.Len(t, questions, 1)
+	assert.Equal(t, "Storage", questions[0].Section)
+}
+
+func TestConvertErrors(t *testing.T) {
+	table := &Table{Header: []string{"ID", "Q", "A"}, Rows: [][]string{{"1", "q", "a"}, {"1", "q2", "a2"}}}
+
+	_, _, err := Convert(table, Mapping{Question: 1, Answer: -1, Section: -1, ID: -1}, ConvertOptions{})
+	assert.ErrorContains(t, err, "must be mapped")
+
+	_, _, err = Convert(table, Mapping{Question: 1, Answer: 1, Section: -1, ID: -1}, ConvertOptions{})
+	assert.ErrorContains(t, err, "different columns")
+
+	_, _, err = Convert(table, Mapping{Question: 1, Answer: 2, Section: -1, ID: 0}, ConvertOptions{})
+	assert.ErrorContains(t, err, "duplicate question ID")
+}
+
+func TestConvertNumbersAroundExplicitIDs(t *testing.T) {
+	table := &Table{Header: []string{"ID", "Q", "A"}, Rows: [][]string{
+		{"", "q1", "a1"},
+		{"1", "q2", "a2"},
+		{"", "q3", "a3"},
+		{"3", "q4", "a4"},
+	}}
+
+	questions, _, err := Convert(table, Mapping{Question: 1, Answer: 2, Section: -1, ID: 0}, ConvertOptions{})
+	require.NoError(t, err)
+	var ids []string
+	for _, q := range questions {
+		ids = append(ids, q.ID)
+	}
+	assert.Equal(t, []string{"2", "1", "4", "3"}, ids)
+}
+
+func TestColumn(t *testing.T) {
+	header := []string{"Front", "Back"}
+
+	idx, err := Column(header, "back")
+	require.NoError(t, err)
+	assert.Equal(t, 1, idx)
+
+	idx, err = Column(header, "1")
+	require.NoError(t, err)
+	assert.Equal(t, 0, idx)
+
+	_, err = Column(header, "3")
+	assert.ErrorContains(t, err, "unknown column")
+}
+
+func TestWriteQuestionsRoundTrip(t *testing.T) {
+	questions := []testsuite.Question{
+		{ID: "1", Section: "Basics", QuestionText: "What is a \"Pod\"?", ExpectedAnswer: "A group,\nof containers"},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteQuestions(&buf, questions))
+
+	table, err := Read(&buf, FormatCSV, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ID", "Section", "Question", "ExpectedAnswer"}, table.Header)
+
+	got, _, err := Convert(table, SuggestMapping(table.Header), ConvertOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, questions, got)
+}
+
+func TestWriteQuestionsMetadata(t *testing.T) {
+	questions := []testsuite.Question{
+		{ID: "1", Section: "Basics", QuestionText: "Q1", ExpectedAnswer: "A1", Difficulty: "easy", Tags: []string{"networking", "storage"}},
+		{ID: "2", Section: "Basics", QuestionText: "Q2", ExpectedAnswer: "A2"},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteQuestions(&buf, questions))
+	assert.Equal(t, "ID,Section,Question,ExpectedAnswer,Difficulty,Tags\n1,Basics,Q1,A1,easy,networking;storage\n2,Basics,Q2,A2,,\n", buf.String())
+}
+
+func TestValidateFormat(t *testing.T) {
+	assert.NoError(t, ValidateFormat(FormatAnki))
+	assert.NoError(t, ValidateFormat(FormatHFDataset))
+	assert.Error(t, ValidateFormat("xlsx"))
+}
@@ -0,0 +1,699 @@
+// Package jobs runs test runs in the background and tracks their progress,
+// so that MCP clients can start long runs without waiting for them and
+// cancel runs in flight. Runs beyond the manager's concurrency limit wait in
+// a queue, so that concurrent runs do not compete for the same GPUs.
+//
+// With a Store, the replicas of a server share their jobs: each replica
+// reports on all jobs and forwards cancellations to the replica running a
+// job, and the queued jobs of a replica that stopped are taken over. The
+// concurrency limit then holds across the replicas: a job runs only once
+// its replica claimed one of the slots in the store.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/llm-testing/internal/testsuite"
+)
+
+// State is the lifecycle state of a job.
+type State string
+
+// Job states.
+const (
+	StateQueued    State = "queued"
+	StateRunning   State = "running"
+	StateSucceeded State = "succeeded"
+	StateFailed    State = "failed"
+	StateCancelled State = "cancelled"
+)
+
+// Retention is how long finished jobs are kept for status queries.
+const Retention = 24 * time.Hour
+
+// DefaultMaxConcurrent is the number of jobs a manager runs at a time unless
+// set otherwise.
+const DefaultMaxConcurrent = 1
+
+const (
+	// heartbeatInterval is how often a manager with a store marks its
+	// replica as alive, renews the slots of its running jobs, forwards
+	// cancellations, takes over the jobs of stopped replicas, and retries
+	// to start its queued jobs. A replica is considered stopped, and its
+	// slots free, after three missed heartbeats.
+	heartbeatInterval = 10 * time.Second
+
+	// storeTimeout bounds the requests to a store.
+	storeTimeout = 5 * time.Second
+
+	// drainPollInterval is how often Drain checks for running jobs.
+	drainPollInterval = 100 * time.Millisecond
+)
+
+// ErrNotFound is returned for IDs of unknown jobs.
+var ErrNotFound = errors.New("run not found")
+
+// ErrDraining is returned for jobs started while the manager drains.
+var ErrDraining = errors.New("the server is shutting down")
+
+// ErrAlreadyRunning is returned for IDs of jobs still queued or running.
+var ErrAlreadyRunning = errors.New("already running")
+
+// ModelProgress is the progress of a job on one model.
+type ModelProgress struct {
+	Model              string               `json:"model"`
+	CompletedQuestions int                  `json:"completed_questions"`
+	TotalQuestions     int                  `json:"total_questions"`
+	LiveScore          *testsuite.LiveScore `json:"live_score,omitempty"`
+}
+
+// Status is a snapshot of a job.
+type Status struct {
+	ID    string `json:"run_id"`
+	State State  `json:"state"`
+	// QueuePosition is the 1-based position of a queued job in the queue.
+	QueuePosition int             `json:"queue_position,omitempty"`
+	QueuedAt      time.Time       `json:"queued_at"`
+	StartedAt     *time.Time      `json:"started_at,omitempty"`
+	FinishedAt    *time.Time      `json:"finished_at,omitempty"`
+	Progress      []ModelProgress `json:"progress"`
+	Error         string          `json:"error,omitempty"`
+	Result        any             `json:"result,omitempty"`
+}
+
+// Func is the work of a job. It reports its progress on the job and returns
+// the job's result. It must return when ctx is cancelled; the result, e.g.
+// of the work done until then, is kept.
+type Func func(ctx context.Context, job *Job) (any, error)
+
+// Job is a test run queued, in flight, or finished.
+type Job struct {
+	manager *Manager // nil for jobs of other replicas
+	cancel  context.CancelFunc
+	ready   chan struct{} // closed when the job may run
+	done    chan struct{}
+	spec    json.RawMessage
+	replica string
+
+	mu     sync.Mutex
+	status Status
+	err    error
+}
+
+// ReportProgress records that completed of total questions were asked to a model.
+func (j *Job) ReportProgress(model string, completed, total int) {
+	j.mu.Lock()
+	p := j.model(model)
+	p.CompletedQuestions, p.TotalQuestions = completed, total
+	j.mu.Unlock()
+	j.manager.persist(j)
+}
+
+// ReportScore records the running accuracy of a model's judged answers.
+func (j *Job) ReportScore(model string, score testsuite.LiveScore) {
+	j.mu.Lock()
+	j.model(model).LiveScore = &score
+	j.mu.Unlock()
+	j.manager.persist(j)
+}
+
+// model returns the progress of a model, adding it on first use. j.mu must be held.
+func (j *Job) model(name string) *ModelProgress {
+	for i := range j.status.Progress {
+		if j.status.Progress[i].Model == name {
+			return &j.status.Progress[i]
+		}
+	}
+	j.status.Progress = append(j.status.Progress, ModelProgress{Model: name})
+	return &j.status.Progress[len(j.status.Progress)-1]
+}
+
+// Status returns a snapshot of the job.
+func (j *Job) Status() Status {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	status := j.status
+	status.Progress = slices.Clone(j.status.Progress)
+	if status.State == StateQueued && j.manager != nil {
+		status.QueuePosition = j.manager.position(j)
+	}
+	return status
+}
+
+// Err returns the error the job finished with. It is nil for jobs of other
+// replicas, whose Status only has its message.
+func (j *Job) Err() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.err
+}
+
+// Done is closed when the job has finished.
+func (j *Job) Done() <-chan struct{} {
+	return j.done
+}
+
+func (j *Job) start() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now()
+	j.status.State = StateRunning
+	j.status.StartedAt = &now
+}
+
+func (j *Job) finish(result any, err error, cancelled bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now()
+	j.status.FinishedAt = &now
+	j.status.Result = result
+	switch {
+	case cancelled:
+		j.status.State = StateCancelled
+	case err == nil:
+		j.status.State = StateSucceeded
+	default:
+		j.status.State = StateFailed
+	}
+	if err != nil {
+		j.status.Error = err.Error()
+	}
+	j.err = err
+	close(j.done)
+}
+
+// Manager runs jobs, at most maxConcurrent at a time, and keeps them, once
+// finished, for Retention.
+type Manager struct {
+	ctx context.Context
+
+	mu   sync.Mutex
+	jobs map[string]*Job
+
+	// dispatchMu serializes dispatch, so that a job claims its slot in the
+	// store only once.
+	dispatchMu sync.Mutex
+
+	// queueMu guards the queue, the count of running jobs, and whether the
+	// manager drains. It is never
+	// held while acquiring another lock.
+	queueMu       sync.Mutex
+	queue         []*Job
+	running       int
+	maxConcurrent int
+	draining      bool
+
+	// store shares the jobs with other replicas (optional).
+	store   Store
+	replica string
+	resume  ResumeFunc
+}
+
+// NewManager returns a manager whose jobs are cancelled when ctx is done,
+// e.g. on server shutdown. It runs DefaultMaxConcurrent jobs at a time.
+func NewManager(ctx context.Context) *Manager {
+	return &Manager{ctx: ctx, jobs: make(map[string]*Job), maxConcurrent: DefaultMaxConcurrent}
+}
+
+// SetMaxConcurrent sets the number of jobs run at a time; further jobs are
+// queued. n below 1 means 1.
+func (m *Manager) SetMaxConcurrent(n int) {
+	m.queueMu.Lock()
+	m.maxConcurrent = max(n, 1)
+	m.queueMu.Unlock()
+	m.dispatch()
+}
+
+// MaxConcurrent returns the number of jobs run at a time.
+func (m *Manager) MaxConcurrent() int {
+	m.queueMu.Lock()
+	defer m.queueMu.Unlock()
+	return m.maxConcurrent
+}
+
+// SetStore shares the jobs with the other replicas of the server through
+// store, under the name replica. The manager then takes over the unfinished
+// jobs of replicas that stopped: it resumes their queued jobs that have a
+// spec with resume (optional), and fails the others. Its concurrency limit
+// becomes the limit of all replicas together, which should therefore be
+// configured alike.
+func (m *Manager) SetStore(store Store, replica string, resume ResumeFunc) {
+	m.store, m.replica, m.resume = store, replica, resume
+	go func() {
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+		for {
+			m.heartbeat()
+			select {
+			case <-m.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Start queues fn to run in the background as the job with the given ID,
+// and runs it once fewer than the maximum of jobs are running. It fails if
+// a job with the ID is still queued or running.
+func (m *Manager) Start(id string, fn Func) (*Job, error) {
+	return m.StartResumable(id, nil, fn)
+}
+
+// StartResumable is like Start, but keeps spec, which describes fn, with the
+// job in the store while the job is queued. Should this replica stop before
+// running the job, another one resumes it from the spec.
+func (m *Manager) StartResumable(id string, spec json.RawMessage, fn Func) (*Job, error) {
+	if m.runningElsewhere(id) {
+		return nil, fmt.Errorf("run %s is %w", id, ErrAlreadyRunning)
+	}
+	return m.start(id, spec, fn, time.Now())
+}
+
+// runningElsewhere reports whether the store has the job with the given ID
+// as still queued or running on another replica. Jobs taken over from
+// stopped replicas are started without this check.
+func (m *Manager) runningElsewhere(id string) bool {
+	if m.store == nil {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(m.ctx, storeTimeout)
+	defer cancel()
+	record, err := m.store.Get(ctx, id)
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			slog.Warn("failed to get run job", "run_id", id, "error", err)
+		}
+		return false
+	}
+	return record.Status.FinishedAt == nil && record.Replica != m.replica
+}
+
+func (m *Manager) start(id string, spec json.RawMessage, fn Func, queuedAt time.Time) (*Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prune()
+	if existing, ok := m.jobs[id]; ok && existing.Status().FinishedAt == nil {
+		return nil, fmt.Errorf("run %s is %w", id, ErrAlreadyRunning)
+	}
+	if m.isDraining() {
+		return nil, ErrDraining
+	}
+
+	ctx, cancel := context.WithCancel(m.ctx)
+	job := &Job{
+		manager: m,
+		cancel:  cancel,
+		ready:   make(chan struct{}),
+		done:    make(chan struct{}),
+		spec:    spec,
+		replica: m.replica,
+		status:  Status{ID: id, State: StateQueued, QueuedAt: queuedAt, Progress: []ModelProgress{}},
+	}
+	m.jobs[id] = job
+	m.persist(job)
+	m.queueMu.Lock()
+	m.queue = append(m.queue, job)
+	m.queueMu.Unlock()
+	m.dispatch()
+
+	go func() {
+		defer cancel()
+		select {
+		case <-job.ready:
+		case <-ctx.Done():
+			if m.dequeue(job) {
+				job.finish(nil, errors.New("cancelled while queued"), true)
+				if m.ctx.Err() != nil && job.spec != nil {
+					// On shutdown, the job stays queued in the store for
+					// another replica, or this one after the restart.
+					return
+				}
+				m.persist(job)
+				slog.Info("run job cancelled while queued", "run_id", id)
+				return
+			}
+			// The job was started meanwhile; fn returns right away.
+		}
+		defer m.release(job)
+
+		result, err := fn(ctx, job)
+		job.finish(result, err, ctx.Err() != nil)
+		m.persist(job)
+		status := job.Status()
+		slog.Info("run job finished", "run_id", id, "state", status.State, "duration", status.FinishedAt.Sub(*status.StartedAt).String())
+	}()
+	return job, nil
+}
+
+// dispatch starts queued jobs, oldest first, while fewer than the maximum
+// of jobs are running, and, with a store, the job claimed a slot in it. Jobs
+// that found no free slot wait for one of this replica's jobs to finish, or
+// the next heartbeat. Once the manager drains or is shut down, queued jobs
+// stay queued, to be resumed from the store.
+func (m *Manager) dispatch() {
+	m.dispatchMu.Lock()
+	defer m.dispatchMu.Unlock()
+	for {
+		m.queueMu.Lock()
+		if m.running >= m.maxConcurrent || len(m.queue) == 0 || m.draining || m.ctx.Err() != nil {
+			m.queueMu.Unlock()
+			return
+		}
+		job, limit := m.queue[0], m.maxConcurrent
+		m.queueMu.Unlock()
+
+		if !m.acquireSlot(job, limit) {
+			return
+		}
+		m.queueMu.Lock()
+		// The job may have been cancelled while it claimed its slot.
+		queued := len(m.queue) > 0 && m.queue[0] == job
+		if queued {
+			m.queue = m.queue[1:]
+			m.running++
+		}
+		m.queueMu.Unlock()
+		if !queued {
+			m.releaseSlot(job)
+			continue
+		}
+
+		job.start()
+		m.persist(job)
+		close(job.ready)
+	}
+}
+
+// acquireSlot reports whether a job claimed one of the limit slots in the
+// store, or the manager has no store.
+func (m *Manager) acquireSlot(job *Job, limit int) bool {
+	if m.store == nil {
+		return true
+	}
+	ctx, cancel := context.WithTimeout(m.ctx, storeTimeout)
+	defer cancel()
+	acquired, err := m.store.AcquireSlot(ctx, job.status.ID, limit, 3*heartbeatInterval)
+	if err != nil {
+		slog.Warn("failed to claim a slot for run job", "run_id", job.status.ID, "error", err)
+		return false
+	}
+	return acquired
+}
+
+// releaseSlot frees the slot of a job in the store, if any.
+func (m *Manager) releaseSlot(job *Job) {
+	if m.store == nil {
+		return
+	}
+	// Jobs finishing on shutdown still free their slots.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(m.ctx), storeTimeout)
+	defer cancel()
+	if err := m.store.ReleaseSlot(ctx, job.status.ID); err != nil {
+		slog.Warn("failed to free the slot of run job", "run_id", job.status.ID, "error", err)
+	}
+}
+
+// Drain stops starting jobs, and waits until the running jobs finished or
+// ctx is done. Queued jobs are cancelled, or left in the store for another
+// replica, once the manager's context is done.
+func (m *Manager) Drain(ctx context.Context) error {
+	m.queueMu.Lock()
+	m.draining = true
+	m.queueMu.Unlock()
+
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for {
+		m.queueMu.Lock()
+		running := m.running
+		m.queueMu.Unlock()
+		if running == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *Manager) isDraining() bool {
+	m.queueMu.Lock()
+	defer m.queueMu.Unlock()
+	return m.draining
+}
+
+// release frees the slot of a finished job for the next queued one.
+func (m *Manager) release(job *Job) {
+	m.releaseSlot(job)
+	m.queueMu.Lock()
+	m.running--
+	m.queueMu.Unlock()
+	m.dispatch()
+}
+
+// dequeue removes a job from the queue. It reports false if the job was
+// not queued, i.e. it has been started.
+func (m *Manager) dequeue(job *Job) bool {
+	m.queueMu.Lock()
+	defer m.queueMu.Unlock()
+	i := slices.Index(m.queue, job)
+	if i < 0 {
+		return false
+	}
+	m.queue = slices.Delete(m.queue, i, i+1)
+	return true
+}
+
+// position returns the 1-based position of a job in the queue, or 0 if it
+// is not queued.
+func (m *Manager) position(job *Job) int {
+	m.queueMu.Lock()
+	defer m.queueMu.Unlock()
+	return slices.Index(m.queue, job) + 1
+}
+
+// Get returns the job with the given ID. Jobs of other replicas, or from
+// before a restart, are snapshots of their status in the store.
+func (m *Manager) Get(id string) (*Job, error) {
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	m.mu.Unlock()
+	if ok {
+		return job, nil
+	}
+	if m.store == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, storeTimeout)
+	defer cancel()
+	record, err := m.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	job = &Job{done: make(chan struct{}), replica: record.Replica, status: record.Status}
+	if record.Status.FinishedAt != nil {
+		close(job.done)
+	}
+	return job, nil
+}
+
+// Cancel cancels a queued or running job. The job finishes, in state
+// cancelled, once its work has stopped. Jobs of other replicas are
+// cancelled by their replica on its next heartbeat.
+func (m *Manager) Cancel(id string) (*Job, error) {
+	job, err := m.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if state := job.Status().State; state != StateQueued && state != StateRunning {
+		return nil, fmt.Errorf("run %s is not running (state: %s)", id, state)
+	}
+	if job.manager == nil {
+		ctx, cancel := context.WithTimeout(m.ctx, storeTimeout)
+		defer cancel()
+		if err := m.store.RequestCancel(ctx, id); err != nil {
+			return nil, fmt.Errorf("failed to cancel run %s on replica %s: %w", id, job.replica, err)
+		}
+		return job, nil
+	}
+	job.cancel()
+	return job, nil
+}
+
+// List returns the jobs, most recently queued first.
+func (m *Manager) List() []Status {
+	m.mu.Lock()
+	statuses := make([]Status, 0, len(m.jobs))
+	local := make(map[string]bool, len(m.jobs))
+	for id, job := range m.jobs {
+		statuses = append(statuses, job.Status())
+		local[id] = true
+	}
+	m.mu.Unlock()
+
+	if m.store != nil {
+		ctx, cancel := context.WithTimeout(m.ctx, storeTimeout)
+		defer cancel()
+		records, err := m.store.List(ctx)
+		if err != nil {
+			slog.Warn("failed to list the run jobs of other replicas", "error", err)
+		}
+		for _, record := range records {
+			if !local[record.Status.ID] {
+				statuses = append(statuses, record.Status)
+			}
+		}
+	}
+	slices.SortFunc(statuses, func(a, b Status) int {
+		return b.QueuedAt.Compare(a.QueuedAt)
+	})
+	return statuses
+}
+
+// prune removes jobs finished more than Retention ago. m.mu must be held.
+func (m *Manager) prune() {
+	for id, job := range m.jobs {
+		status := job.Status()
+		if status.FinishedAt != nil && time.Since(*status.FinishedAt) > Retention {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+// persist saves a job in the store, if any. Failures are logged, as the job
+// goes on regardless.
+func (m *Manager) persist(job *Job) {
+	if m == nil || m.store == nil {
+		return
+	}
+	record := Record{Status: job.Status(), Replica: m.replica}
+	record.Status.QueuePosition = 0
+	if record.Status.State == StateQueued {
+		record.Spec = job.spec
+	}
+	// Jobs finishing on shutdown are still saved.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(m.ctx), storeTimeout)
+	defer cancel()
+	if err := m.store.Save(ctx, record); err != nil {
+		slog.Warn("failed to save run job", "run_id", record.Status.ID, "error", err)
+	}
+}
+
+// heartbeat marks the replica as alive, cancels the jobs whose cancellation
+// other replicas requested, and takes over the unfinished jobs of stopped
+// replicas, including those of this replica from before a restart.
+func (m *Manager) heartbeat() {
+	ctx, cancel := context.WithTimeout(m.ctx, storeTimeout)
+	defer cancel()
+	if err := m.store.Heartbeat(ctx, m.replica, 3*heartbeatInterval); err != nil {
+		slog.Warn("failed to send replica heartbeat", "replica", m.replica, "error", err)
+		return
+	}
+	m.renewSlots(ctx)
+	defer m.dispatch()
+
+	records, err := m.store.List(ctx)
+	if err != nil {
+		slog.Warn("failed to list run jobs", "error", err)
+		return
+	}
+
+	alive := map[string]bool{m.replica: true}
+	for _, record := range records {
+		id := record.Status.ID
+		if record.Status.FinishedAt != nil {
+			continue
+		}
+		m.mu.Lock()
+		job, local := m.jobs[id]
+		m.mu.Unlock()
+		if local {
+			if requested, err := m.store.CancelRequested(ctx, id); err == nil && requested {
+				job.cancel()
+			}
+			continue
+		}
+
+		if m.isDraining() {
+			// Jobs of stopped replicas are left to the replicas staying.
+			continue
+		}
+		if record.Replica != m.replica {
+			isAlive, ok := alive[record.Replica]
+			if !ok {
+				if isAlive, err = m.store.Alive(ctx, record.Replica); err != nil {
+					continue
+				}
+				alive[record.Replica] = isAlive
+			}
+			if isAlive {
+				continue
+			}
+		}
+		if claimed, err := m.store.Claim(ctx, id, record.Replica, m.replica, 3*heartbeatInterval); err != nil || !claimed {
+			continue
+		}
+		m.takeOver(ctx, record)
+	}
+}
+
+// renewSlots extends the claims of the running jobs on their slots, which
+// expire once the replica stopped.
+func (m *Manager) renewSlots(ctx context.Context) {
+	m.mu.Lock()
+	var running []*Job
+	for _, job := range m.jobs {
+		if job.Status().State == StateRunning {
+			running = append(running, job)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, job := range running {
+		// A running job keeps its slot even if its claim expired meanwhile,
+		// or the limit was lowered.
+		if _, err := m.store.AcquireSlot(ctx, job.status.ID, 0, 3*heartbeatInterval); err != nil {
+			slog.Warn("failed to renew the slot of run job", "run_id", job.status.ID, "error", err)
+		}
+	}
+}
+
+// takeOver resumes a queued job of a stopped replica, or fails it if it was
+// running or cannot be resumed.
+func (m *Manager) takeOver(ctx context.Context, record Record) {
+	id := record.Status.ID
+	if record.Status.State == StateQueued && record.Spec != nil && m.resume != nil {
+		fn, err := m.resume(id, record.Spec)
+		if err == nil {
+			_, err = m.start(id, record.Spec, fn, record.Status.QueuedAt)
+		}
+		if err == nil {
+			slog.Info("resumed queued run job", "run_id", id, "from_replica", record.Replica)
+			return
+		}
+		slog.Warn("failed to resume queued run job", "run_id", id, "error", err)
+	}
+
+	from := record.Replica
+	now := time.Now()
+	record.Status.State = StateFailed
+	record.Status.FinishedAt = &now
+	record.Status.Error = fmt.Sprintf("interrupted: replica %s stopped", from)
+	record.Replica, record.Spec = m.replica, nil
+	if err := m.store.Save(ctx, record); err != nil {
+		slog.Warn("failed to save run job", "run_id", id, "error", err)
+		return
+	}
+	slog.Info("failed run job of stopped replica", "run_id", id, "from_replica", from)
+}
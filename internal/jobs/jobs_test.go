@@ -0,0 +1,390 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/giantswarm/llm-testing/internal/testsuite"
+)
+
+func TestManagerRunsJob(t *testing.T) {
+	m := NewManager(context.Background())
+
+	job, err := m.Start("run-1", func(_ context.Context, job *Job) (any, error) {
+		job.ReportProgress("model-a", 1, 2)
+		job.ReportProgress("model-a", 2, 2)
+		job.ReportScore("model-a", testsuite.LiveScore{Judged: 2, Correct: 1, Percent: 50})
+		return "summary", nil
+	})
+	require.NoError(t, err)
+	<-job.Done()
+
+	status := job.Status()
+	assert.Equal(t, "run-1", status.ID)
+	assert.Equal(t, StateSucceeded, status.State)
+	assert.NotNil(t, status.FinishedAt)
+	assert.Equal(t, "summary", status.Result)
+	require.Len(t, status.Progress, 1)
+	assert.Equal(t, 2, status.Progress[0].CompletedQuestions)
+	assert.Equal(t, 1, status.Progress[0].LiveScore.Correct)
+
+	got, err := m.Get("run-1")
+	require.NoError(t, err)
+	assert.Same(t, job, got)
+	_, err = m.Get("run-2")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestManagerReportsFailure(t *testing.T) {
+	m := NewManager(context.Background())
+
+	job, err := m.Start("run-1", func(context.Context, *Job) (any, error) {
+		return nil, errors.New("deploy failed")
+	})
+	require.NoError(t, err)
+	<-job.Done()
+
+	assert.Equal(t, StateFailed, job.Status().State)
+	assert.Equal(t, "deploy failed", job.Status().Error)
+	assert.EqualError(t, job.Err(), "deploy failed")
+}
+
+func TestManagerCancel(t *testing.T) {
+	m := NewManager(context.Background())
+
+	started := make(chan struct{})
+	job, err := m.Start("run-1", func(ctx context.Context, _ *Job) (any, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	require.NoError(t, err)
+	<-started
+
+	_, err = m.Start("run-1", func(context.Context, *Job) (any, error) { return nil, nil })
+	assert.ErrorIs(t, err, ErrAlreadyRunning)
+	assert.ErrorContains(t, err, "run run-1 is already running")
+
+	_, err = m.Cancel("run-1")
+	require.NoError(t, err)
+	<-job.Done()
+	assert.Equal(t, StateCancelled, job.Status().State)
+
+	_, err = m.Cancel("run-1")
+	assert.ErrorContains(t, err, "is not running (state: cancelled)")
+	assert.Len(t, m.List(), 1)
+}
+
+func TestManagerQueuesJobs(t *testing.T) {
+	m := NewManager(context.Background())
+
+	release := make(chan struct{})
+	blocking := func(ctx context.Context, _ *Job) (any, error) {
+		select {
+		case <-release:
+			return "done", nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	first, err := m.Start("run-1", blocking)
+	require.NoError(t, err)
+	second, err := m.Start("run-2", blocking)
+	require.NoError(t, err)
+	third, err := m.Start("run-3", blocking)
+	require.NoError(t, err)
+
+	assert.Equal(t, StateRunning, first.Status().State)
+	assert.NotNil(t, first.Status().StartedAt)
+	assert.Equal(t, StateQueued, second.Status().State)
+	assert.Equal(t, 1, second.Status().QueuePosition)
+	assert.Nil(t, second.Status().StartedAt)
+	assert.Equal(t, 2, third.Status().QueuePosition)
+
+	// A queued job is cancelled without running.
+	_, err = m.Cancel("run-2")
+	require.NoError(t, err)
+	<-second.Done()
+	assert.Equal(t, StateCancelled, second.Status().State)
+	assert.Nil(t, second.Status().StartedAt)
+	assert.Equal(t, 1, third.Status().QueuePosition)
+
+	// The next job starts once the running one finished.
+	release <- struct{}{}
+	<-first.Done()
+	assert.Eventually(t, func() bool { return third.Status().State == StateRunning }, time.Second, time.Millisecond)
+	assert.Zero(t, third.Status().QueuePosition)
+	close(release)
+	<-third.Done()
+	assert.Equal(t, StateSucceeded, third.Status().State)
+}
+
+func TestManagerMaxConcurrent(t *testing.T) {
+	m := NewManager(context.Background())
+	m.SetMaxConcurrent(2)
+	assert.Equal(t, 2, m.MaxConcurrent())
+
+	release := make(chan struct{})
+	var all []*Job
+	for _, id := range []string{"run-1", "run-2", "run-3"} {
+		job, err := m.Start(id, func(context.Context, *Job) (any, error) {
+			<-release
+			return nil, nil
+		})
+		require.NoError(t, err)
+		all = append(all, job)
+	}
+	assert.Equal(t, StateRunning, all[0].Status().State)
+	assert.Equal(t, StateRunning, all[1].Status().State)
+	assert.Equal(t, StateQueued, all[2].Status().State)
+
+	close(release)
+	for _, job := range all {
+		<-job.Done()
+		assert.Equal(t, StateSucceeded, job.Status().State)
+	}
+}
+
+// memoryStore is a Store in memory.
+type memoryStore struct {
+	mu       sync.Mutex
+	records  map[string]Record
+	replicas map[string]bool
+	claims   map[string]bool
+	cancels  map[string]bool
+	slots    map[string]bool
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{records: map[string]Record{}, replicas: map[string]bool{}, claims: map[string]bool{}, cancels: map[string]bool{}, slots: map[string]bool{}}
+}
+
+func (s *memoryStore) Save(_ context.Context, record Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[record.Status.ID] = record
+	return nil
+}
+
+func (s *memoryStore) Get(_ context.Context, id string) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.records[id]
+	if !ok {
+		return Record{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return record, nil
+}
+
+func (s *memoryStore) List(context.Context) ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	records := make([]Record, 0, len(s.records))
+	for _, record := range s.records {
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func (s *memoryStore) Heartbeat(_ context.Context, replica string, _ time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.replicas[replica] = true
+	return nil
+}
+
+func (s *memoryStore) Alive(_ context.Context, replica string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.replicas[replica], nil
+}
+
+func (s *memoryStore) Claim(_ context.Context, id, from, _ string, _ time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := id + ":" + from
+	if s.claims[key] {
+		return false, nil
+	}
+	s.claims[key] = true
+	return true, nil
+}
+
+func (s *memoryStore) RequestCancel(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cancels[id] = true
+	return nil
+}
+
+func (s *memoryStore) CancelRequested(_ context.Context, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cancels[id], nil
+}
+
+func (s *memoryStore) AcquireSlot(_ context.Context, id string, limit int, _ time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if limit > 0 && !s.slots[id] && len(s.slots) >= limit {
+		return false, nil
+	}
+	s.slots[id] = true
+	return true, nil
+}
+
+func (s *memoryStore) ReleaseSlot(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.slots, id)
+	return nil
+}
+
+func (s *memoryStore) slotsTaken() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.slots)
+}
+
+func (s *memoryStore) stop(replica string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.replicas, replica)
+}
+
+func TestManagerSharesJobsThroughStore(t *testing.T) {
+	store := newMemoryStore()
+	blocking := func(ctx context.Context, _ *Job) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	ctxA, stopA := context.WithCancel(context.Background())
+	a := NewManager(ctxA)
+	a.SetStore(store, "replica-a", nil)
+	b := NewManager(context.Background())
+	b.SetStore(store, "replica-b", func(id string, spec json.RawMessage) (Func, error) {
+		return func(context.Context, *Job) (any, error) { return "resumed " + string(spec), nil }, nil
+	})
+
+	running, err := a.Start("run-1", blocking)
+	require.NoError(t, err)
+	queued, err := a.StartResumable("run-2", json.RawMessage(`"suite"`), blocking)
+	require.NoError(t, err)
+
+	// Other replicas report on the jobs.
+	job, err := b.Get("run-1")
+	require.NoError(t, err)
+	assert.Equal(t, StateRunning, job.Status().State)
+	assert.Len(t, b.List(), 2)
+	_, err = b.Get("run-3")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Nor do they start jobs with the same IDs.
+	_, err = b.Start("run-1", blocking)
+	assert.ErrorIs(t, err, ErrAlreadyRunning)
+	assert.ErrorContains(t, err, "run run-1 is already running")
+
+	// When replica-a stops, its running job is cancelled, and its queued
+	// one is resumed by replica-b.
+	stopA()
+	<-running.Done()
+	<-queued.Done()
+	store.stop("replica-a")
+	require.NoError(t, store.Save(context.Background(), Record{Status: Status{ID: "run-0", State: StateRunning}, Replica: "replica-c"}))
+	b.heartbeat()
+
+	job, err = b.Get("run-2")
+	require.NoError(t, err)
+	<-job.Done()
+	assert.Equal(t, StateSucceeded, job.Status().State)
+	assert.Equal(t, `resumed "suite"`, job.Status().Result)
+	record, err := store.Get(context.Background(), "run-1")
+	require.NoError(t, err)
+	assert.Equal(t, StateCancelled, record.Status.State)
+	record, err = store.Get(context.Background(), "run-0")
+	require.NoError(t, err)
+	assert.Equal(t, StateFailed, record.Status.State)
+	assert.Equal(t, "interrupted: replica replica-c stopped", record.Status.Error)
+
+	// Cancellations are forwarded to the replica of a job.
+	running, err = b.Start("run-3", blocking)
+	require.NoError(t, err)
+	restarted := NewManager(context.Background())
+	restarted.SetStore(store, "replica-a", nil)
+	_, err = restarted.Cancel("run-3")
+	require.NoError(t, err)
+	b.heartbeat()
+	<-running.Done()
+	assert.Equal(t, StateCancelled, running.Status().State)
+}
+
+func TestManagerLimitsJobsAcrossReplicas(t *testing.T) {
+	store := newMemoryStore()
+	for _, replica := range []string{"replica-a", "replica-b"} {
+		require.NoError(t, store.Heartbeat(context.Background(), replica, time.Minute))
+	}
+	a := NewManager(context.Background())
+	a.SetStore(store, "replica-a", nil)
+	b := NewManager(context.Background())
+	b.SetStore(store, "replica-b", nil)
+
+	release := make(chan struct{})
+	running, err := a.Start("run-1", func(context.Context, *Job) (any, error) {
+		<-release
+		return "done", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, StateRunning, running.Status().State)
+
+	// Replica b has no job running, but the only slot is taken by a.
+	queued, err := b.Start("run-2", func(context.Context, *Job) (any, error) { return "done", nil })
+	require.NoError(t, err)
+	assert.Equal(t, StateQueued, queued.Status().State)
+
+	// Once the job of a finished, b starts its job on the next heartbeat.
+	close(release)
+	require.Eventually(t, func() bool { return store.slotsTaken() == 0 }, time.Second, time.Millisecond)
+	b.heartbeat()
+	<-queued.Done()
+	assert.Equal(t, StateSucceeded, queued.Status().State)
+	require.Eventually(t, func() bool { return store.slotsTaken() == 0 }, time.Second, time.Millisecond)
+}
+
+func TestManagerDrain(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := NewManager(ctx)
+	release := make(chan struct{})
+	running, err := m.Start("run-1", func(context.Context, *Job) (any, error) {
+		<-release
+		return "done", nil
+	})
+	require.NoError(t, err)
+	queued, err := m.Start("run-2", func(context.Context, *Job) (any, error) { return "done", nil })
+	require.NoError(t, err)
+
+	drainCtx, stop := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer stop()
+	assert.ErrorIs(t, m.Drain(drainCtx), context.DeadlineExceeded)
+	_, err = m.Start("run-3", func(context.Context, *Job) (any, error) { return nil, nil })
+	assert.ErrorIs(t, err, ErrDraining)
+
+	// The running job finishes; the queued one is not started.
+	close(release)
+	require.NoError(t, m.Drain(context.Background()))
+	assert.Equal(t, StateSucceeded, running.Status().State)
+	assert.Equal(t, StateQueued, queued.Status().State)
+
+	cancel()
+	<-queued.Done()
+	assert.Equal(t, StateCancelled, queued.Status().State)
+}
@@ -0,0 +1,175 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/valkey-io/valkey-go"
+)
+
+// RedisStore is a Store in Redis or Valkey.
+//
+// A job is a JSON Record under <prefix>job:<id>, which expires Retention
+// after the job finished; the set <prefix>jobs indexes the jobs. The sorted
+// set <prefix>slots has the jobs holding slots, scored by when their claims
+// expire.
+type RedisStore struct {
+	client valkey.Client
+	prefix string
+}
+
+var _ Store = (*RedisStore)(nil)
+
+// NewRedisStore returns a store whose keys start with prefix.
+func NewRedisStore(client valkey.Client, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+// Save adds or updates a job.
+func (s *RedisStore) Save(ctx context.Context, record Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode job: %w", err)
+	}
+	set := s.client.B().Set().Key(s.jobKey(record.Status.ID)).Value(string(data))
+	cmd := set.Build()
+	if record.Status.FinishedAt != nil {
+		cmd = set.Ex(Retention).Build()
+	}
+	for _, result := range s.client.DoMulti(ctx,
+		cmd,
+		s.client.B().Sadd().Key(s.prefix+"jobs").Member(record.Status.ID).Build(),
+	) {
+		if err := result.Error(); err != nil {
+			return fmt.Errorf("failed to save job %s: %w", record.Status.ID, err)
+		}
+	}
+	return nil
+}
+
+// Get returns the job with the given ID.
+func (s *RedisStore) Get(ctx context.Context, id string) (Record, error) {
+	data, err := s.client.Do(ctx, s.client.B().Get().Key(s.jobKey(id)).Build()).ToString()
+	if valkey.IsValkeyNil(err) {
+		return Record{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if err != nil {
+		return Record{}, fmt.Errorf("failed to get job %s: %w", id, err)
+	}
+	var record Record
+	if err := json.Unmarshal([]byte(data), &record); err != nil {
+		return Record{}, fmt.Errorf("failed to decode job %s: %w", id, err)
+	}
+	return record, nil
+}
+
+// List returns all jobs, and drops expired jobs from the index.
+func (s *RedisStore) List(ctx context.Context) ([]Record, error) {
+	ids, err := s.client.Do(ctx, s.client.B().Smembers().Key(s.prefix+"jobs").Build()).AsStrSlice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	gets := make(valkey.Commands, len(ids))
+	for i, id := range ids {
+		gets[i] = s.client.B().Get().Key(s.jobKey(id)).Build()
+	}
+	records := make([]Record, 0, len(ids))
+	var expired []string
+	for i, result := range s.client.DoMulti(ctx, gets...) {
+		data, err := result.ToString()
+		if valkey.IsValkeyNil(err) {
+			expired = append(expired, ids[i])
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get job %s: %w", ids[i], err)
+		}
+		var record Record
+		if err := json.Unmarshal([]byte(data), &record); err != nil {
+			return nil, fmt.Errorf("failed to decode job %s: %w", ids[i], err)
+		}
+		records = append(records, record)
+	}
+	if len(expired) > 0 {
+		if err := s.client.Do(ctx, s.client.B().Srem().Key(s.prefix+"jobs").Member(expired...).Build()).Error(); err != nil {
+			return nil, fmt.Errorf("failed to drop expired jobs: %w", err)
+		}
+	}
+	return records, nil
+}
+
+// Heartbeat marks a replica as alive for ttl.
+func (s *RedisStore) Heartbeat(ctx context.Context, replica string, ttl time.Duration) error {
+	return s.client.Do(ctx, s.client.B().Set().Key(s.prefix+"replica:"+replica).Value("alive").Ex(ttl).Build()).Error()
+}
+
+// Alive reports whether the heartbeat of a replica is current.
+func (s *RedisStore) Alive(ctx context.Context, replica string) (bool, error) {
+	n, err := s.client.Do(ctx, s.client.B().Exists().Key(s.prefix+"replica:"+replica).Build()).AsInt64()
+	return n > 0, err
+}
+
+// Claim reports whether replica is the first to take over a job from the
+// replica from.
+func (s *RedisStore) Claim(ctx context.Context, id, from, replica string, ttl time.Duration) (bool, error) {
+	err := s.client.Do(ctx, s.client.B().Set().Key(s.prefix+"claim:"+id+":"+from).Value(replica).Nx().Ex(ttl).Build()).Error()
+	if valkey.IsValkeyNil(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// RequestCancel asks the replica of a job to cancel it.
+func (s *RedisStore) RequestCancel(ctx context.Context, id string) error {
+	return s.client.Do(ctx, s.client.B().Set().Key(s.prefix+"cancel:"+id).Value("requested").Ex(Retention).Build()).Error()
+}
+
+// CancelRequested reports whether the cancellation of a job was requested.
+func (s *RedisStore) CancelRequested(ctx context.Context, id string) (bool, error) {
+	n, err := s.client.Do(ctx, s.client.B().Exists().Key(s.prefix+"cancel:"+id).Build()).AsInt64()
+	return n > 0, err
+}
+
+// acquireSlot claims a slot for the job ARGV[3] in the sorted set KEYS[1]
+// for ARGV[2] milliseconds, unless ARGV[1] (> 0) slots are taken by other
+// jobs. Expired claims are dropped first.
+var acquireSlot = valkey.NewLuaScript(`
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now)
+local limit, ttl = tonumber(ARGV[1]), tonumber(ARGV[2])
+if limit > 0 and not redis.call('ZSCORE', KEYS[1], ARGV[3]) and redis.call('ZCARD', KEYS[1]) >= limit then
+  return 0
+end
+redis.call('ZADD', KEYS[1], now + ttl, ARGV[3])
+redis.call('PEXPIRE', KEYS[1], ttl)
+return 1
+`)
+
+// AcquireSlot reports whether a job holds one of limit slots, claiming one
+// if it does not yet.
+func (s *RedisStore) AcquireSlot(ctx context.Context, id string, limit int, ttl time.Duration) (bool, error) {
+	n, err := acquireSlot.Exec(ctx, s.client, []string{s.prefix + "slots"}, []string{
+		strconv.Itoa(limit), strconv.FormatInt(ttl.Milliseconds(), 10), id,
+	}).AsInt64()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim a slot for job %s: %w", id, err)
+	}
+	return n == 1, nil
+}
+
+// ReleaseSlot frees the slot of a job.
+func (s *RedisStore) ReleaseSlot(ctx context.Context, id string) error {
+	return s.client.Do(ctx, s.client.B().Zrem().Key(s.prefix+"slots").Member(id).Build()).Error()
+}
+
+func (s *RedisStore) jobKey(id string) string {
+	return s.prefix + "job:" + id
+}
@@ -0,0 +1,62 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Record is a job as kept in a Store.
+type Record struct {
+	Status Status `json:"status"`
+
+	// Replica is the name of the server replica that runs the job.
+	Replica string `json:"replica"`
+
+	// Spec describes the work of a queued job, so that another replica, or
+	// the replica after a restart, can resume it (optional).
+	Spec json.RawMessage `json:"spec,omitempty"`
+}
+
+// Store keeps the jobs of the replicas of a server, so that each replica
+// can report on all jobs, and jobs survive restarts. A store keeps finished
+// jobs for Retention.
+type Store interface {
+	// Save adds or updates a job.
+	Save(ctx context.Context, record Record) error
+
+	// Get returns the job with the given ID, or ErrNotFound.
+	Get(ctx context.Context, id string) (Record, error)
+
+	// List returns all jobs.
+	List(ctx context.Context) ([]Record, error)
+
+	// Heartbeat marks a replica as alive for ttl.
+	Heartbeat(ctx context.Context, replica string, ttl time.Duration) error
+
+	// Alive reports whether the heartbeat of a replica is current.
+	Alive(ctx context.Context, replica string) (bool, error)
+
+	// Claim reports whether replica is the first to take over the job with
+	// the given ID from the replica from; a claim holds for ttl.
+	Claim(ctx context.Context, id, from, replica string, ttl time.Duration) (bool, error)
+
+	// RequestCancel asks the replica of a job to cancel it.
+	RequestCancel(ctx context.Context, id string) error
+
+	// CancelRequested reports whether the cancellation of a job was requested.
+	CancelRequested(ctx context.Context, id string) (bool, error)
+
+	// AcquireSlot reports whether the job with the given ID holds one of
+	// limit slots shared by the replicas, claiming a free one if it does
+	// not yet. A claim holds for ttl; claiming again renews it. A limit of
+	// 0 claims a slot regardless of the others, e.g. for a running job.
+	AcquireSlot(ctx context.Context, id string, limit int, ttl time.Duration) (bool, error)
+
+	// ReleaseSlot frees the slot of the job with the given ID.
+	ReleaseSlot(ctx context.Context, id string) error
+}
+
+// ResumeFunc returns the work of a queued job with the given ID and spec
+// that a replica takes over.
+type ResumeFunc func(id string, spec json.RawMessage) (Func, error)
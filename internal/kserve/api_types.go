@@ -22,9 +22,41 @@ type InferenceServiceSpec struct {
 	Predictor PredictorSpec `json:"predictor"`
 }
 
-// PredictorSpec defines the model serving configuration.
+// PredictorSpec defines the model serving configuration and the pod-level
+// scheduling fields KServe inlines into the predictor.
 type PredictorSpec struct {
 	Model *ISvcModelSpec `json:"model,omitempty"`
+
+	// WorkerSpec runs the model across multiple nodes (RawDeployment only).
+	WorkerSpec *WorkerSpec `json:"workerSpec,omitempty"`
+
+	NodeSelector     map[string]string   `json:"nodeSelector,omitempty"`
+	Tolerations      []corev1.Toleration `json:"tolerations,omitempty"`
+	Affinity         *corev1.Affinity    `json:"affinity,omitempty"`
+	RuntimeClassName *string             `json:"runtimeClassName,omitempty"`
+
+	Volumes []corev1.Volume `json:"volumes,omitempty"`
+
+	ServiceAccountName string                        `json:"serviceAccountName,omitempty"`
+	ImagePullSecrets   []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// Autoscaling of the predictor. A MinReplicas of 0 enables scale-to-zero
+	// in serverless mode; KServe defaults it to 1.
+	MinReplicas *int   `json:"minReplicas,omitempty"`
+	MaxReplicas int    `json:"maxReplicas,omitempty"`
+	ScaleTarget int    `json:"scaleTarget,omitempty"`
+	ScaleMetric string `json:"scaleMetric,omitempty"`
+}
+
+// WorkerSpec configures the worker pods of a multi-node predictor.
+type WorkerSpec struct {
+	// PipelineParallelSize is the number of nodes, including the head.
+	PipelineParallelSize *int `json:"pipelineParallelSize,omitempty"`
+
+	// TensorParallelSize is the number of GPUs used on each node.
+	TensorParallelSize *int `json:"tensorParallelSize,omitempty"`
+
+	Containers []corev1.Container `json:"containers,omitempty"`
 }
 
 // ISvcModelSpec defines the model format, storage, runtime, and resource requirements
@@ -39,11 +71,35 @@ type ISvcModelSpec struct {
 	// StorageURI points to the model location (e.g. "hf://org/model").
 	StorageURI *string `json:"storageUri,omitempty"`
 
+	// Storage references the model via an entry of KServe's storage-config
+	// Secret, as an alternative to StorageURI.
+	Storage *StorageSpec `json:"storage,omitempty"`
+
 	// Resources defines compute resource requirements for the model container.
 	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
 
 	// Args are additional arguments passed to the serving runtime.
 	Args []string `json:"args,omitempty"`
+
+	// Env sets environment variables on the model container. KServe's
+	// storage initializer also reads HF_TOKEN from it.
+	Env []corev1.EnvVar `json:"env,omitempty"`
+
+	// VolumeMounts mounts predictor volumes into the model container.
+	VolumeMounts []corev1.VolumeMount `json:"volumeMounts,omitempty"`
+}
+
+// StorageSpec locates a model in storage configured in KServe's
+// storage-config Secret.
+type StorageSpec struct {
+	// Key is the entry of the storage-config Secret to use.
+	Key string `json:"key,omitempty"`
+
+	// Path is the model location within the storage (e.g. the object prefix).
+	Path string `json:"path,omitempty"`
+
+	// Parameters override values of the storage config, e.g. "bucket".
+	Parameters map[string]string `json:"parameters,omitempty"`
 }
 
 // ModelFormat identifies the model format by name and optional version.
@@ -58,7 +114,29 @@ type InferenceServiceStatus struct {
 	Conditions []StatusCondition `json:"conditions,omitempty"`
 
 	// URL is the endpoint URL assigned by the InferenceService controller.
+	// Behind Knative or Istio it is the external address.
 	URL string `json:"url,omitempty"`
+
+	// Address holds the cluster-local address of the InferenceService.
+	Address *Addressable `json:"address,omitempty"`
+
+	// ObservedGeneration is the generation the conditions were computed for.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Components holds the status of each component, keyed by "predictor" etc.
+	Components map[string]ComponentStatus `json:"components,omitempty"`
+}
+
+// Addressable is a Knative addressable status.
+type Addressable struct {
+	URL string `json:"url,omitempty"`
+}
+
+// ComponentStatus is the observed state of an InferenceService component.
+type ComponentStatus struct {
+	LatestCreatedRevision string `json:"latestCreatedRevision,omitempty"`
+	LatestReadyRevision   string `json:"latestReadyRevision,omitempty"`
+	URL                   string `json:"url,omitempty"`
 }
 
 // StatusCondition represents a single condition on an InferenceService,
@@ -89,3 +167,35 @@ func (s *InferenceServiceStatus) GetReadyCondition() *StatusCondition {
 	}
 	return nil
 }
+
+// ServingRuntime represents a KServe ServingRuntime or ClusterServingRuntime
+// resource (serving.kserve.io/v1alpha1). Both kinds share the same spec.
+type ServingRuntime struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ServingRuntimeSpec `json:"spec,omitempty"`
+}
+
+// ServingRuntimeSpec defines the model formats a runtime serves and its containers.
+type ServingRuntimeSpec struct {
+	SupportedModelFormats []SupportedModelFormat `json:"supportedModelFormats,omitempty"`
+
+	// ProtocolVersions lists the inference protocols the runtime speaks (e.g. "v2").
+	ProtocolVersions []string `json:"protocolVersions,omitempty"`
+
+	// Disabled marks a runtime that must not be selected.
+	Disabled *bool `json:"disabled,omitempty"`
+
+	Containers []corev1.Container `json:"containers"`
+}
+
+// SupportedModelFormat is a model format a ServingRuntime can serve.
+type SupportedModelFormat struct {
+	Name    string  `json:"name"`
+	Version *string `json:"version,omitempty"`
+
+	// AutoSelect allows KServe to pick the runtime for InferenceServices
+	// that do not name one explicitly.
+	AutoSelect *bool `json:"autoSelect,omitempty"`
+}
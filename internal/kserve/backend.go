@@ -0,0 +1,260 @@
+package kserve
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Backend is the model server a ServingRuntime runs.
+type Backend string
+
+const (
+	// BackendVLLM serves models on GPUs with vLLM (the default).
+	BackendVLLM Backend = "vllm"
+	// BackendOllama serves a GGUF model on CPUs with Ollama.
+	BackendOllama Backend = "ollama"
+	// BackendLlamaCPP serves a GGUF model on CPUs with the llama.cpp server.
+	BackendLlamaCPP Backend = "llamacpp"
+)
+
+const (
+	// DefaultOllamaRuntime is the ServingRuntime name used for Ollama models.
+	DefaultOllamaRuntime = "kserve-ollama"
+	// DefaultLlamaCPPRuntime is the ServingRuntime name used for llama.cpp models.
+	DefaultLlamaCPPRuntime = "kserve-llamacpp"
+
+	// DefaultOllamaImage is the Ollama image used for Ollama runtimes.
+	DefaultOllamaImage = "ollama/ollama"
+	// DefaultLlamaCPPImage is the llama.cpp server image used for llama.cpp runtimes.
+	DefaultLlamaCPPImage = "ghcr.io/ggml-org/llama.cpp"
+)
+
+// backendProfile describes how models are served with a backend.
+type backendProfile struct {
+	modelFormat string
+	runtime     string
+
+	image    string
+	imageTag string // default tag; empty if it must be given
+
+	// gpu is set for backends that need GPUs; CPU backends get cpu and
+	// memory requests instead.
+	gpu         bool
+	cpuRequest  string
+	memRequest  string
+	runtimeArgs bool // whether RuntimeArgs are passed to the server
+
+	// container returns the command, args, and env of the runtime container.
+	container func(extraArgs []string) ([]string, []string, []corev1.EnvVar)
+}
+
+// firstGGUF expands to the first GGUF file the storage initializer
+// downloaded: the GGUF file of ModelConfig.GGUFFile, the only one, or for
+// split models the first part, which llama.cpp loads the others from.
+const firstGGUF = `"$(ls /mnt/models/*.gguf /mnt/models/*/*.gguf 2>/dev/null | head -n 1)"`
+
+var backends = map[Backend]backendProfile{
+	BackendVLLM: {
+		modelFormat: "vLLM",
+		runtime:     DefaultRuntime,
+		image:       DefaultVLLMImage,
+		gpu:         true,
+		runtimeArgs: true,
+		container: func(extraArgs []string) ([]string, []string, []corev1.EnvVar) {
+			args := append([]string{"--port=8080", "--model=/mnt/models", "--served-model-name={{.Name}}"}, extraArgs...)
+			return []string{"python3", "-m", "vllm.entrypoints.openai.api_server"}, args, nil
+		},
+	},
+	BackendOllama: {
+		modelFormat: "gguf",
+		runtime:     DefaultOllamaRuntime,
+		image:       DefaultOllamaImage,
+		imageTag:    "latest",
+		cpuRequest:  "2",
+		memRequest:  "8Gi",
+		container: func([]string) ([]string, []string, []corev1.EnvVar) {
+			// Ollama serves models from its own store, so the downloaded
+			// file is imported under the InferenceService name first.
+			script := `/bin/ollama serve & pid=$!
+until /bin/ollama list >/dev/null 2>&1; do sleep 1; done
+printf 'FROM %s\n' ` + firstGGUF + ` > /tmp/Modelfile
+/bin/ollama create "$1" -f /tmp/Modelfile || exit 1
+wait $pid`
+			env := []corev1.EnvVar{{Name: "OLLAMA_HOST", Value: "0.0.0.0:8080"}}
+			return []string{"sh", "-c", script}, []string{"ollama", "{{.Name}}"}, env
+		},
+	},
+	BackendLlamaCPP: {
+		modelFormat: "gguf",
+		runtime:     DefaultLlamaCPPRuntime,
+		image:       DefaultLlamaCPPImage,
+		imageTag:    "server",
+		cpuRequest:  "2",
+		memRequest:  "8Gi",
+		runtimeArgs: true,
+		container: func(extraArgs []string) ([]string, []string, []corev1.EnvVar) {
+			script := `exec /app/llama-server --model ` + firstGGUF + ` "$@"`
+			args := append([]string{"llama-server", "--host", "0.0.0.0", "--port", "8080", "--alias", "{{.Name}}"}, extraArgs...)
+			return []string{"sh", "-c", script}, args, nil
+		},
+	},
+}
+
+// ggufPreference ranks the quantizations of GGUF files, best first, for
+// picking one of a repository: medium 4- and 5-bit quantizations balance
+// size and quality on CPUs.
+var ggufPreference = []string{"Q4_K_M", "Q5_K_M", "Q4_K_S", "Q5_K_S", "Q4_0", "Q6_K", "Q8_0", "Q3_K_M", "Q3_K_L", "Q2_K", "F16", "BF16"}
+
+// splitGGUF matches the parts of a GGUF file split across several files.
+var splitGGUF = regexp.MustCompile(`-\d{5}-of-\d{5}\.gguf$`)
+
+// GGUFLister lists the GGUF files of a model repository. A
+// ModelMetadataSource that implements it lets ResolveGGUFFile pick one.
+type GGUFLister interface {
+	GGUFFiles(ctx context.Context, modelURI string) ([]string, error)
+}
+
+// ResolveGGUFFile sets cfg.GGUFFile for a CPU backend serving an hf://
+// repository without one, to the file of the best quantization among the
+// repository's unsplit GGUF files, so that only it is downloaded. It leaves
+// cfg unchanged if src cannot list the files or the repository has only
+// split files, which are downloaded whole.
+func ResolveGGUFFile(ctx context.Context, src ModelMetadataSource, cfg *ModelConfig) error {
+	lister, ok := src.(GGUFLister)
+	if !ok || cfg.Backend.profile().gpu || cfg.GGUFFile != "" || !strings.HasPrefix(cfg.ModelURI, "hf://") {
+		return nil
+	}
+	files, err := lister.GGUFFiles(ctx, cfg.ModelURI)
+	if err != nil {
+		return err
+	}
+	cfg.GGUFFile = pickGGUF(files)
+	return nil
+}
+
+// pickGGUF returns the unsplit GGUF file of the best quantization, or ""
+// if there is none.
+func pickGGUF(files []string) string {
+	var candidates []string
+	for _, f := range files {
+		if strings.HasSuffix(strings.ToLower(f), ".gguf") && !splitGGUF.MatchString(f) {
+			candidates = append(candidates, f)
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	rank := func(f string) int {
+		name := strings.ToUpper(path.Base(f))
+		for i, q := range ggufPreference {
+			if strings.Contains(name, q) {
+				return i
+			}
+		}
+		return len(ggufPreference)
+	}
+	slices.SortStableFunc(candidates, func(a, b string) int {
+		if c := rank(a) - rank(b); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	})
+	return candidates[0]
+}
+
+// ggufStorageURI returns the storage URI downloading only the GGUF file of
+// an hf:// repository, hf://<org>/<model>[@<revision>].
+func ggufStorageURI(modelURI, file string) string {
+	repo, revision, _ := strings.Cut(strings.TrimPrefix(modelURI, "hf://"), "@")
+	if revision == "" {
+		revision = "main"
+	}
+	return "https://huggingface.co/" + strings.Trim(repo, "/") + "/resolve/" + revision + "/" + file
+}
+
+// ParseBackend validates a backend; empty means BackendVLLM.
+func ParseBackend(s string) (Backend, error) {
+	if s == "" {
+		return BackendVLLM, nil
+	}
+	if _, ok := backends[Backend(s)]; !ok {
+		return "", fmt.Errorf("invalid backend %q (expected vllm, ollama, or llamacpp)", s)
+	}
+	return Backend(s), nil
+}
+
+func (b Backend) profile() backendProfile {
+	if p, ok := backends[b]; ok {
+		return p
+	}
+	return backends[BackendVLLM]
+}
+
+// UseBackend switches the config to serve the model with b, replacing the
+// vLLM defaults of DefaultModelConfig: CPU backends get their runtime and
+// CPU and memory requests instead of a GPU and a /dev/shm volume.
+func (c *ModelConfig) UseBackend(b Backend) {
+	c.Backend = b
+	p := b.profile()
+	if c.Runtime == "" || c.Runtime == DefaultRuntime {
+		c.Runtime = p.runtime
+	}
+	if p.gpu {
+		return
+	}
+	c.GPUCount = 0
+	c.ShmSize = ""
+	if c.CPURequest == "" {
+		c.CPURequest = p.cpuRequest
+	}
+	if c.MemoryRequest == "" {
+		c.MemoryRequest = p.memRequest
+	}
+}
+
+// validateBackend rejects vLLM options for the CPU backends.
+func (c ModelConfig) validateBackend() error {
+	if c.Backend == "" || c.Backend == BackendVLLM {
+		return c.validateGGUFFile()
+	}
+	p, ok := backends[c.Backend]
+	if !ok {
+		_, err := ParseBackend(string(c.Backend))
+		return err
+	}
+	switch {
+	case c.GPUCount > 0 || c.ShmSize != "":
+		return fmt.Errorf("the %s backend runs on CPUs; GPUs and a /dev/shm size are only supported by the vllm backend", c.Backend)
+	case c.Quantization != "" || c.ArgsPreset != "":
+		return fmt.Errorf("quantization and args presets are only supported by the vllm backend")
+	case c.TensorParallelSize > 0 || c.Nodes() > 1:
+		return fmt.Errorf("tensor parallelism and multi-node deployments are only supported by the vllm backend")
+	case len(c.RuntimeArgs) > 0 && !p.runtimeArgs:
+		return fmt.Errorf("the %s backend takes no runtime args; configure it with environment variables (e.g. OLLAMA_CONTEXT_LENGTH)", c.Backend)
+	}
+	return c.validateGGUFFile()
+}
+
+// validateGGUFFile checks that a GGUF file is one file of an hf://
+// repository that a CPU backend can serve alone.
+func (c ModelConfig) validateGGUFFile() error {
+	switch {
+	case c.GGUFFile == "":
+		return nil
+	case c.Backend.profile().gpu:
+		return fmt.Errorf("a GGUF file is only supported by the ollama and llamacpp backends")
+	case !strings.HasPrefix(c.ModelURI, "hf://"):
+		return fmt.Errorf("a GGUF file is only supported for hf:// model URIs; point other URIs at the file itself")
+	case !strings.HasSuffix(strings.ToLower(c.GGUFFile), ".gguf") || strings.HasPrefix(c.GGUFFile, "/") || slices.Contains(strings.Split(c.GGUFFile, "/"), ".."):
+		return fmt.Errorf("invalid GGUF file %q: expected the path of a .gguf file in the repository", c.GGUFFile)
+	case splitGGUF.MatchString(c.GGUFFile):
+		return fmt.Errorf("GGUF file %q is part of a split model, which cannot be downloaded alone; omit the GGUF file to download the repository", c.GGUFFile)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package kserve
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBackend(t *testing.T) {
+	b, err := ParseBackend("")
+	require.NoError(t, err)
+	assert.Equal(t, BackendVLLM, b)
+
+	b, err = ParseBackend("llamacpp")
+	require.NoError(t, err)
+	assert.Equal(t, BackendLlamaCPP, b)
+
+	_, err = ParseBackend("tgi")
+	assert.ErrorContains(t, err, `invalid backend "tgi" (expected vllm, ollama, or llamacpp)`)
+}
+
+func TestUseBackend(t *testing.T) {
+	cfg := DefaultModelConfig("qwen", "hf://Qwen/Qwen2.5-0.5B-Instruct-GGUF")
+	cfg.UseBackend(BackendOllama)
+	require.NoError(t, cfg.Validate())
+
+	assert.Equal(t, DefaultOllamaRuntime, cfg.Runtime)
+	assert.Zero(t, cfg.GPUCount)
+	assert.Empty(t, cfg.ShmSize)
+
+	isvc := BuildInferenceService(cfg, "llm-testing")
+	model := isvc.Spec.Predictor.Model
+	assert.Equal(t, "gguf", model.ModelFormat.Name)
+	assert.Equal(t, DefaultOllamaRuntime, *model.Runtime)
+	assert.NotContains(t, model.Resources.Limits, gpuResource)
+	cpu := model.Resources.Requests["cpu"]
+	memory := model.Resources.Requests["memory"]
+	assert.Equal(t, "2", cpu.String())
+	assert.Equal(t, "8Gi", memory.String())
+	assert.Empty(t, isvc.Spec.Predictor.Volumes)
+
+	// Explicit runtimes and resources are kept.
+	cfg = DefaultModelConfig("qwen", "pvc://models/qwen")
+	cfg.Runtime, cfg.MemoryRequest = "llamacpp-cuda", "32Gi"
+	cfg.UseBackend(BackendLlamaCPP)
+	assert.Equal(t, "llamacpp-cuda", cfg.Runtime)
+	assert.Equal(t, "32Gi", cfg.MemoryRequest)
+	assert.Equal(t, "2", cfg.CPURequest)
+}
+
+func TestValidateBackend(t *testing.T) {
+	cfg := DefaultModelConfig("m", "hf://org/model")
+	cfg.UseBackend(BackendLlamaCPP)
+	cfg.RuntimeArgs = []string{"--ctx-size", "4096", "--max-model-len=0"}
+	assert.NoError(t, cfg.Validate(), "vLLM flags are not checked for llama.cpp")
+
+	cfg.Quantization = "awq"
+	assert.ErrorContains(t, cfg.Validate(), "only supported by the vllm backend")
+
+	cfg = DefaultModelConfig("m", "hf://org/model")
+	cfg.UseBackend(BackendOllama)
+	cfg.WorkerCount = 1
+	assert.ErrorContains(t, cfg.Validate(), "multi-node deployments are only supported by the vllm backend")
+
+	cfg = DefaultModelConfig("m", "hf://org/model")
+	cfg.UseBackend(BackendOllama)
+	cfg.RuntimeArgs = []string{"--verbose"}
+	assert.ErrorContains(t, cfg.Validate(), "the ollama backend takes no runtime args")
+
+	cfg = DefaultModelConfig("m", "hf://org/model")
+	cfg.Backend = "tgi"
+	assert.ErrorContains(t, cfg.Validate(), `invalid backend "tgi"`)
+}
+
+func TestValidateGGUFFile(t *testing.T) {
+	cfg := DefaultModelConfig("m", "hf://org/model-GGUF")
+	cfg.UseBackend(BackendLlamaCPP)
+	cfg.GGUFFile = "q4/model-Q4_K_M.gguf"
+	assert.NoError(t, cfg.Validate())
+
+	for file, want := range map[string]string{
+		"model.bin":                    "invalid GGUF file",
+		"../model.gguf":                "invalid GGUF file",
+		"/model.gguf":                  "invalid GGUF file",
+		"model-00001-of-00002.gguf":    "part of a split model",
+		"q8/model-00002-of-00002.gguf": "part of a split model",
+	} {
+		cfg.GGUFFile = file
+		assert.ErrorContains(t, cfg.Validate(), want, file)
+	}
+
+	cfg.GGUFFile, cfg.ModelURI = "model.gguf", "pvc://models/qwen"
+	assert.ErrorContains(t, cfg.Validate(), "only supported for hf:// model URIs")
+
+	cfg = DefaultModelConfig("m", "hf://org/model")
+	cfg.GGUFFile = "model.gguf"
+	assert.ErrorContains(t, cfg.Validate(), "only supported by the ollama and llamacpp backends")
+
+	// GPUs and /dev/shm set after UseBackend are rejected, not re-added.
+	cfg = DefaultModelConfig("m", "hf://org/model")
+	cfg.UseBackend(BackendOllama)
+	cfg.GPUCount = 1
+	assert.ErrorContains(t, cfg.Validate(), "the ollama backend runs on CPUs")
+
+	cfg = DefaultModelConfig("m", "hf://org/model")
+	cfg.UseBackend(BackendOllama)
+	cfg.ShmSize = "2Gi"
+	assert.ErrorContains(t, cfg.Validate(), "the ollama backend runs on CPUs")
+}
+
+func TestGGUFFileStorageURI(t *testing.T) {
+	cfg := DefaultModelConfig("qwen", "hf://Qwen/Qwen2.5-0.5B-Instruct-GGUF")
+	cfg.UseBackend(BackendLlamaCPP)
+	cfg.GGUFFile = "qwen2.5-0.5b-instruct-q4_k_m.gguf"
+	isvc := BuildInferenceService(cfg, "llm-testing")
+	assert.Equal(t, "https://huggingface.co/Qwen/Qwen2.5-0.5B-Instruct-GGUF/resolve/main/qwen2.5-0.5b-instruct-q4_k_m.gguf",
+		*isvc.Spec.Predictor.Model.StorageURI)
+
+	cfg.ModelURI = "hf://Qwen/Qwen2.5-0.5B-Instruct-GGUF@abc123"
+	isvc = BuildInferenceService(cfg, "llm-testing")
+	assert.Equal(t, "https://huggingface.co/Qwen/Qwen2.5-0.5B-Instruct-GGUF/resolve/abc123/qwen2.5-0.5b-instruct-q4_k_m.gguf",
+		*isvc.Spec.Predictor.Model.StorageURI)
+}
+
+func TestPickGGUF(t *testing.T) {
+	assert.Equal(t, "model-q4_k_m.gguf", pickGGUF([]string{
+		"model-q8_0.gguf", "model-f16.gguf", "model-q4_k_m.gguf", "model-q5_k_m.gguf", "README.md",
+	}))
+	assert.Equal(t, "model-Q5_K_M.gguf", pickGGUF([]string{
+		"Q4_K_M/model-Q4_K_M-00001-of-00002.gguf", "Q4_K_M/model-Q4_K_M-00002-of-00002.gguf", "model-Q5_K_M.gguf",
+	}), "split files are skipped")
+	assert.Equal(t, "a.gguf", pickGGUF([]string{"b.gguf", "a.gguf"}), "unknown quantizations are ordered by name")
+	assert.Empty(t, pickGGUF([]string{"model-00001-of-00002.gguf", "model-00002-of-00002.gguf"}))
+}
@@ -0,0 +1,250 @@
+package kserve
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var nodeGVR = schema.GroupVersionResource{Version: "v1", Resource: "nodes"}
+
+// CapacityCheck selects how Deploy reacts to insufficient GPU capacity.
+type CapacityCheck string
+
+const (
+	// CapacityCheckOff skips the check.
+	CapacityCheckOff CapacityCheck = "off"
+	// CapacityCheckWarn deploys anyway and reports a warning in ModelStatus.
+	CapacityCheckWarn CapacityCheck = "warn"
+	// CapacityCheckEnforce refuses to deploy.
+	CapacityCheckEnforce CapacityCheck = "enforce"
+)
+
+// ParseCapacityCheck validates a capacity check mode; empty means off.
+func ParseCapacityCheck(s string) (CapacityCheck, error) {
+	switch c := CapacityCheck(s); c {
+	case "":
+		return CapacityCheckOff, nil
+	case CapacityCheckOff, CapacityCheckWarn, CapacityCheckEnforce:
+		return c, nil
+	}
+	return "", fmt.Errorf("invalid GPU capacity check %q (expected off, warn, or enforce)", s)
+}
+
+// NodeGPUs is the GPU capacity of a schedulable node.
+type NodeGPUs struct {
+	Name        string `json:"name"`
+	Allocatable int    `json:"allocatable"`
+	Requested   int    `json:"requested"`
+	Free        int    `json:"free"`
+}
+
+// GPUCapacity is the GPU capacity of the nodes a model can be scheduled on.
+type GPUCapacity struct {
+	Nodes   []NodeGPUs `json:"nodes"`
+	Free    int        `json:"free"`    // free GPUs across all eligible nodes
+	Largest int        `json:"largest"` // most free GPUs on a single node
+}
+
+// NodesWithFree returns the number of nodes with at least gpus free GPUs.
+func (c *GPUCapacity) NodesWithFree(gpus int) int {
+	n := 0
+	for _, node := range c.Nodes {
+		if node.Free >= gpus {
+			n++
+		}
+	}
+	return n
+}
+
+// InsufficientCapacityError reports that too few eligible nodes have enough
+// free GPUs for a deployment's predictor pods.
+type InsufficientCapacityError struct {
+	Requested int // GPUs per node
+	Nodes     int // nodes needed
+	Capacity  *GPUCapacity
+}
+
+func (e *InsufficientCapacityError) Error() string {
+	if e.Nodes > 1 {
+		return fmt.Sprintf("insufficient GPU capacity: %d nodes with %d free GPUs each requested, but only %d eligible nodes have them",
+			e.Nodes, e.Requested, e.Capacity.NodesWithFree(e.Requested))
+	}
+	return fmt.Sprintf("insufficient GPU capacity: %d GPUs requested, but at most %d are free on a single eligible node (%d free across %d nodes)",
+		e.Requested, e.Capacity.Largest, e.Capacity.Free, len(e.Capacity.Nodes))
+}
+
+// GPUCapacity returns the free GPUs of the ready, schedulable nodes that
+// match cfg's node selector and whose NoSchedule and NoExecute taints cfg
+// tolerates. GPUs requested by cfg's own predictor pods count as free, since
+// a redeploy replaces them. Node affinity is not evaluated.
+func (m *Manager) GPUCapacity(ctx context.Context, cfg ModelConfig) (*GPUCapacity, error) {
+	nodeList, err := m.client.Resource(nodeGVR).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(cfg.NodeSelector).String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	requested, err := m.gpuRequests(ctx, sanitizeName(cfg.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	capacity := &GPUCapacity{Nodes: []NodeGPUs{}}
+	for _, item := range nodeList.Items {
+		var node corev1.Node
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &node); err != nil {
+			continue
+		}
+		if !nodeEligible(node, cfg.Tolerations) {
+			continue
+		}
+		allocatable := node.Status.Allocatable[gpuResource]
+		n := NodeGPUs{
+			Name:        node.Name,
+			Allocatable: int(allocatable.Value()),
+			Requested:   requested[node.Name],
+		}
+		if n.Allocatable == 0 {
+			continue
+		}
+		n.Free = max(n.Allocatable-n.Requested, 0)
+		capacity.Nodes = append(capacity.Nodes, n)
+		capacity.Free += n.Free
+		capacity.Largest = max(capacity.Largest, n.Free)
+	}
+	sort.Slice(capacity.Nodes, func(i, j int) bool { return capacity.Nodes[i].Name < capacity.Nodes[j].Name })
+	return capacity, nil
+}
+
+// checkCapacity applies cfg.CapacityCheck before a deployment. It returns a
+// warning in warn mode, and an error in enforce mode, when the requested GPUs
+// cannot be scheduled: fewer than cfg.Nodes() eligible nodes have
+// cfg.NodeGPUs() free GPUs. Failures to determine the capacity only log a warning.
+func (m *Manager) checkCapacity(ctx context.Context, cfg ModelConfig) (string, error) {
+	if cfg.CapacityCheck == "" || cfg.CapacityCheck == CapacityCheckOff || cfg.NodeGPUs() <= 0 {
+		return "", nil
+	}
+
+	capacity, err := m.GPUCapacity(ctx, cfg)
+	if err != nil {
+		slog.Warn("failed to check GPU capacity", "name", cfg.Name, "error", err)
+		return "", nil
+	}
+	if capacity.NodesWithFree(cfg.NodeGPUs()) >= cfg.Nodes() {
+		return "", nil
+	}
+
+	capErr := &InsufficientCapacityError{Requested: cfg.NodeGPUs(), Nodes: cfg.Nodes(), Capacity: capacity}
+	if cfg.CapacityCheck == CapacityCheckEnforce {
+		return "", capErr
+	}
+	slog.Warn("deploying despite insufficient GPU capacity", "name", cfg.Name, "error", capErr)
+	return capErr.Error(), nil
+}
+
+// gpuRequests returns the GPUs held by the running pods per node, except
+// for the predictor pods of the InferenceService own in the manager's
+// namespace, if not empty.
+func (m *Manager) gpuRequests(ctx context.Context, own string) (map[string]int, error) {
+	podList, err := m.client.Resource(podGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	requested := map[string]int{}
+	for _, item := range podList.Items {
+		var pod corev1.Pod
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &pod); err != nil {
+			continue
+		}
+		if pod.Spec.NodeName == "" || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if own != "" && pod.Namespace == m.namespace && pod.Labels["serving.kserve.io/inferenceservice"] == own {
+			continue
+		}
+		requested[pod.Spec.NodeName] += podGPURequest(pod)
+	}
+	return requested, nil
+}
+
+// podGPURequest returns the GPUs a pod holds: the larger of the sum over its
+// containers and the largest init container request.
+func podGPURequest(pod corev1.Pod) int {
+	var sum, initMax int64
+	for _, c := range pod.Spec.Containers {
+		sum += gpuQuantity(c.Resources)
+	}
+	for _, c := range pod.Spec.InitContainers {
+		initMax = max(initMax, gpuQuantity(c.Resources))
+	}
+	return int(max(sum, initMax))
+}
+
+// gpuQuantity returns a container's GPU request, which defaults to its limit.
+func gpuQuantity(r corev1.ResourceRequirements) int64 {
+	q, ok := r.Requests[gpuResource]
+	if !ok {
+		q = r.Limits[gpuResource]
+	}
+	return q.Value()
+}
+
+// nodeEligible reports whether a node is ready, schedulable, and has no
+// untolerated NoSchedule or NoExecute taints.
+func nodeEligible(node corev1.Node, tolerations []corev1.Toleration) bool {
+	if node.Spec.Unschedulable {
+		return false
+	}
+	ready := false
+	for _, c := range node.Status.Conditions {
+		if c.Type == corev1.NodeReady {
+			ready = c.Status == corev1.ConditionTrue
+		}
+	}
+	if !ready {
+		return false
+	}
+	for _, taint := range node.Spec.Taints {
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		tolerated := false
+		for _, t := range tolerations {
+			if tolerates(t, taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return false
+		}
+	}
+	return true
+}
+
+// tolerates reports whether a toleration matches a taint, following the
+// scheduler's rules: an empty key with operator Exists matches all taints.
+func tolerates(t corev1.Toleration, taint corev1.Taint) bool {
+	if t.Effect != "" && t.Effect != taint.Effect {
+		return false
+	}
+	if t.Key != "" && t.Key != taint.Key {
+		return false
+	}
+	switch t.Operator {
+	case corev1.TolerationOpExists:
+		return true
+	case corev1.TolerationOpEqual, "":
+		return t.Key != "" && t.Value == taint.Value
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package kserve
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func toObject(t *testing.T, obj interface{}) *unstructured.Unstructured {
+	t.Helper()
+	data, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	require.NoError(t, err)
+	return &unstructured.Unstructured{Object: data}
+}
+
+func gpuNode(name string, gpus string, labels map[string]string, taints ...corev1.Taint) *corev1.Node {
+	return &corev1.Node{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Node"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Spec:       corev1.NodeSpec{Taints: taints},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{gpuResource: resource.MustParse(gpus)},
+			Conditions:  []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+func gpuPod(name, namespace, node, gpus string, labels map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		Spec: corev1.PodSpec{
+			NodeName: node,
+			Containers: []corev1.Container{{
+				Name:      "main",
+				Resources: corev1.ResourceRequirements{Limits: corev1.ResourceList{gpuResource: resource.MustParse(gpus)}},
+			}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+func TestGPUCapacity(t *testing.T) {
+	gpuTaint := corev1.Taint{Key: "nvidia.com/gpu", Effect: corev1.TaintEffectNoSchedule}
+	cordoned := gpuNode("cordoned", "8", nil)
+	cordoned.Spec.Unschedulable = true
+
+	m := newFakeManager(t,
+		toObject(t, gpuNode("a100", "4", map[string]string{"gpu": "a100"})),
+		toObject(t, gpuNode("h100", "8", map[string]string{"gpu": "h100"}, gpuTaint)),
+		toObject(t, gpuNode("cpu", "0", nil)),
+		toObject(t, cordoned),
+		toObject(t, gpuPod("other", "team", "a100", "3", nil)),
+		toObject(t, gpuPod("own", "test-namespace", "a100", "1", map[string]string{"serving.kserve.io/inferenceservice": "model"})),
+	)
+
+	cfg := DefaultModelConfig("model", "hf://org/model")
+	capacity, err := m.GPUCapacity(context.Background(), cfg)
+	require.NoError(t, err)
+	// The tainted H100 node is not eligible without a toleration, and the
+	// model's own pod does not count against the A100 node.
+	assert.Equal(t, []NodeGPUs{{Name: "a100", Allocatable: 4, Requested: 3, Free: 1}}, capacity.Nodes)
+	assert.Equal(t, 1, capacity.Largest)
+
+	cfg.Tolerations = []corev1.Toleration{{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists}}
+	capacity, err = m.GPUCapacity(context.Background(), cfg)
+	require.NoError(t, err)
+	assert.Len(t, capacity.Nodes, 2)
+	assert.Equal(t, 9, capacity.Free)
+	assert.Equal(t, 8, capacity.Largest)
+}
+
+func TestDeployCapacityCheck(t *testing.T) {
+	m := newFakeManager(t, toObject(t, gpuNode("node", "2", nil)))
+
+	cfg := DefaultModelConfig("big", "hf://org/model")
+	cfg.GPUCount = 4
+	cfg.ReadyTimeout = 100 * time.Millisecond
+	cfg.CapacityCheck = CapacityCheckEnforce
+
+	_, err := m.Deploy(context.Background(), cfg)
+	var capErr *InsufficientCapacityError
+	require.True(t, errors.As(err, &capErr))
+	assert.Equal(t, 2, capErr.Capacity.Largest)
+	assert.Empty(t, deployActions(m), "nothing should be created")
+
+	// In warn mode the deployment proceeds.
+	cfg.CapacityCheck = CapacityCheckWarn
+	_, err = m.Deploy(context.Background(), cfg)
+	assert.ErrorContains(t, err, "not ready")
+	assert.Equal(t, []string{"create"}, deployActions(m))
+
+	warning, err := m.checkCapacity(context.Background(), cfg)
+	require.NoError(t, err)
+	assert.Contains(t, warning, "4 GPUs requested, but at most 2 are free")
+}
+
+func TestCheckCapacityMultiNode(t *testing.T) {
+	m := newFakeManager(t,
+		toObject(t, gpuNode("node-1", "8", nil)),
+		toObject(t, gpuNode("node-2", "8", nil)),
+		toObject(t, gpuPod("busy", "team", "node-2", "4", nil)),
+	)
+
+	cfg := DefaultModelConfig("big", "hf://org/model")
+	cfg.TensorParallelSize = 8
+	cfg.WorkerCount = 1
+	cfg.CapacityCheck = CapacityCheckEnforce
+
+	_, err := m.checkCapacity(context.Background(), cfg)
+	assert.ErrorContains(t, err, "2 nodes with 8 free GPUs each requested, but only 1 eligible nodes have them")
+
+	cfg.TensorParallelSize = 4
+	_, err = m.checkCapacity(context.Background(), cfg)
+	assert.NoError(t, err)
+}
+
+func TestTolerates(t *testing.T) {
+	taint := corev1.Taint{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}
+	tests := []struct {
+		name       string
+		toleration corev1.Toleration
+		expected   bool
+	}{
+		{"exists", corev1.Toleration{Key: "dedicated", Operator: corev1.TolerationOpExists}, true},
+		{"equal", corev1.Toleration{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}, true},
+		{"wrong value", corev1.Toleration{Key: "dedicated", Value: "cpu"}, false},
+		{"wrong effect", corev1.Toleration{Key: "dedicated", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute}, false},
+		{"wildcard", corev1.Toleration{Operator: corev1.TolerationOpExists}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tolerates(tt.toleration, taint))
+		})
+	}
+}
+
+func TestParseCapacityCheck(t *testing.T) {
+	check, err := ParseCapacityCheck("")
+	require.NoError(t, err)
+	assert.Equal(t, CapacityCheckOff, check)
+
+	check, err = ParseCapacityCheck("enforce")
+	require.NoError(t, err)
+	assert.Equal(t, CapacityCheckEnforce, check)
+
+	_, err = ParseCapacityCheck("strict")
+	assert.ErrorContains(t, err, `invalid GPU capacity check "strict"`)
+}
@@ -0,0 +1,77 @@
+package kserve
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CleanupOptions selects the InferenceServices Cleanup tears down.
+type CleanupOptions struct {
+	// TTL is the minimum age of an InferenceService to be torn down.
+	TTL time.Duration
+
+	// All tears down every managed InferenceService regardless of age.
+	All bool
+
+	// Expired tears down the InferenceServices whose TTL annotation has
+	// passed, in addition to those older than TTL.
+	Expired bool
+
+	// DryRun only reports what would be torn down.
+	DryRun bool
+}
+
+// CleanupResult is the outcome of tearing down one InferenceService.
+type CleanupResult struct {
+	Name    string `json:"name"`
+	Age     string `json:"age"`
+	Deleted bool   `json:"deleted"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Cleanup tears down the InferenceServices managed by llm-testing that are
+// older than opts.TTL or, with opts.Expired, past their TTL annotation, or
+// all of them with opts.All. A failed teardown is
+// recorded in its result and does not stop the others.
+func (m *Manager) Cleanup(ctx context.Context, opts CleanupOptions) ([]CleanupResult, error) {
+	if !opts.All && !opts.Expired && opts.TTL <= 0 {
+		return nil, fmt.Errorf("a positive TTL is required unless all or expired models are cleaned up")
+	}
+
+	list, err := m.client.Resource(isvcGVR).Namespace(m.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "app.kubernetes.io/managed-by=" + managedBy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list InferenceServices: %w", m.accessError(err, "list", "inferenceservices"))
+	}
+
+	now := time.Now()
+	results := []CleanupResult{}
+	for _, item := range list.Items {
+		age := now.Sub(item.GetCreationTimestamp().Time)
+		expiresAt, hasExpiry := expiry(item)
+		switch {
+		case opts.All:
+		case opts.TTL > 0 && age >= opts.TTL:
+		case opts.Expired && hasExpiry && !now.Before(expiresAt):
+		default:
+			continue
+		}
+
+		r := CleanupResult{Name: item.GetName(), Age: age.Round(time.Second).String()}
+		if !opts.DryRun {
+			if err := m.Teardown(ctx, item.GetName()); err != nil {
+				r.Error = err.Error()
+			} else {
+				r.Deleted = true
+			}
+		}
+		slog.Info("InferenceService cleanup", "name", r.Name, "age", r.Age, "dry_run", opts.DryRun, "error", r.Error)
+		results = append(results, r)
+	}
+	return results, nil
+}
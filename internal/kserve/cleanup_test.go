@@ -0,0 +1,55 @@
+package kserve
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func agedISVC(name string, age time.Duration) runtime.Object {
+	obj := makeISVC(name, "test-namespace", true)
+	obj.SetCreationTimestamp(metav1.NewTime(time.Now().Add(-age)))
+	return obj
+}
+
+func TestManagerCleanup(t *testing.T) {
+	foreign := makeISVC("foreign", "test-namespace", true)
+	foreign.SetLabels(nil)
+	foreign.SetCreationTimestamp(metav1.NewTime(time.Now().Add(-48 * time.Hour)))
+
+	m := newFakeManager(t, agedISVC("old", 48*time.Hour), agedISVC("fresh", time.Hour), foreign)
+
+	results, err := m.Cleanup(context.Background(), CleanupOptions{TTL: 24 * time.Hour, DryRun: true})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "old", results[0].Name)
+	assert.False(t, results[0].Deleted)
+	assert.Empty(t, deployActions(m))
+
+	results, err = m.Cleanup(context.Background(), CleanupOptions{TTL: 24 * time.Hour})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.True(t, results[0].Deleted)
+
+	results, err = m.Cleanup(context.Background(), CleanupOptions{All: true})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "fresh", results[0].Name)
+
+	statuses, err := m.List(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, statuses)
+	_, err = m.Get(context.Background(), "foreign")
+	assert.NoError(t, err, "unmanaged InferenceServices are left alone")
+}
+
+func TestManagerCleanupRequiresTTL(t *testing.T) {
+	m := newFakeManager(t)
+	_, err := m.Cleanup(context.Background(), CleanupOptions{})
+	assert.ErrorContains(t, err, "positive TTL is required")
+}
@@ -0,0 +1,264 @@
+package kserve
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	deploymentGVR = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	crdGVR        = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+	configMapGVR  = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+)
+
+const (
+	// kserveControllerSelector selects the KServe controller Deployment.
+	kserveControllerSelector = "control-plane=kserve-controller-manager"
+
+	// kserveConfigMap holds KServe's settings, in the controller's namespace.
+	kserveConfigMap = "inferenceservice-config"
+
+	// Custom resources whose definitions tell whether Knative Serving and
+	// Istio are installed.
+	knativeServiceCRD  = "services.serving.knative.dev"
+	istioVirtualSvcCRD = "virtualservices.networking.istio.io"
+
+	// DeploymentModeServerless and DeploymentModeRawDeployment are KServe's
+	// deployment modes. Serverless mode runs on Knative Serving.
+	DeploymentModeServerless    = "Serverless"
+	DeploymentModeRawDeployment = "RawDeployment"
+)
+
+// ClusterInfo describes what the cluster can serve, to choose runtimes and
+// GPU counts before deploying. Facts that could not be determined, e.g.
+// for lack of permissions, are left out and explained in Warnings.
+type ClusterInfo struct {
+	Runtimes []RuntimeInfo `json:"runtimes"`
+
+	// GPUNodes are the nodes with GPUs, and GPUTypes sum them up by product.
+	GPUNodes []GPUNode    `json:"gpu_nodes"`
+	GPUTypes []GPUTypeSum `json:"gpu_types"`
+
+	KServeVersion string `json:"kserve_version,omitempty"`
+
+	// DefaultDeploymentMode is the mode of InferenceServices that do not
+	// choose one: Serverless or RawDeployment.
+	DefaultDeploymentMode string `json:"default_deployment_mode,omitempty"`
+
+	KnativeServing *bool `json:"knative_serving,omitempty"`
+	Istio          *bool `json:"istio,omitempty"`
+
+	// ScaleToZero reports whether deployments with min_replicas 0 scale to
+	// zero: they need Knative Serving and serverless mode.
+	ScaleToZero *bool `json:"scale_to_zero,omitempty"`
+
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// GPUNode is the GPU capacity of a node.
+type GPUNode struct {
+	NodeGPUs
+	GPUProduct   string `json:"gpu_product,omitempty"`
+	InstanceType string `json:"instance_type,omitempty"`
+
+	// Schedulable is false for nodes that are not ready or cordoned.
+	Schedulable bool `json:"schedulable"`
+
+	// Taints lists the NoSchedule and NoExecute taints, which deployments
+	// must tolerate to run on the node.
+	Taints []string `json:"taints,omitempty"`
+}
+
+// GPUTypeSum sums up the GPUs of a product across schedulable nodes.
+type GPUTypeSum struct {
+	Product     string `json:"product"`
+	Nodes       int    `json:"nodes"`
+	Allocatable int    `json:"allocatable"`
+	Free        int    `json:"free"`
+	LargestFree int    `json:"largest_free"` // most free GPUs on a single node
+}
+
+// ClusterInfo returns the serving runtimes, GPU capacity, KServe version,
+// and serverless setup of the cluster. Only the runtimes are required; the
+// other facts are best effort.
+func (m *Manager) ClusterInfo(ctx context.Context) (*ClusterInfo, error) {
+	runtimes, err := m.ListRuntimes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	info := &ClusterInfo{Runtimes: runtimes, GPUNodes: []GPUNode{}, GPUTypes: []GPUTypeSum{}}
+
+	if err := m.addGPUNodes(ctx, info); err != nil {
+		info.Warnings = append(info.Warnings, fmt.Sprintf("GPU capacity unknown: %v", err))
+	}
+
+	controllerNamespace, err := m.addKServeVersion(ctx, info)
+	if err != nil {
+		info.Warnings = append(info.Warnings, fmt.Sprintf("KServe version unknown: %v", err))
+	}
+	if controllerNamespace != "" {
+		if err := m.addDeploymentMode(ctx, info, controllerNamespace); err != nil {
+			info.Warnings = append(info.Warnings, fmt.Sprintf("default deployment mode unknown: %v", err))
+		}
+	}
+
+	info.KnativeServing, err = m.crdInstalled(ctx, knativeServiceCRD)
+	if err != nil {
+		info.Warnings = append(info.Warnings, fmt.Sprintf("Knative Serving installation unknown: %v", err))
+	}
+	info.Istio, err = m.crdInstalled(ctx, istioVirtualSvcCRD)
+	if err != nil {
+		info.Warnings = append(info.Warnings, fmt.Sprintf("Istio installation unknown: %v", err))
+	}
+
+	switch {
+	case info.KnativeServing != nil && !*info.KnativeServing:
+		info.ScaleToZero = new(bool)
+	case info.KnativeServing != nil && info.DefaultDeploymentMode != "":
+		scaleToZero := info.DefaultDeploymentMode == DeploymentModeServerless
+		info.ScaleToZero = &scaleToZero
+	}
+	return info, nil
+}
+
+// addGPUNodes adds the nodes with GPUs to info, and sums them up by product.
+func (m *Manager) addGPUNodes(ctx context.Context, info *ClusterInfo) error {
+	nodeList, err := m.client.Resource(nodeGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list nodes: %w", err)
+	}
+	requested, err := m.gpuRequests(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	types := map[string]*GPUTypeSum{}
+	for _, item := range nodeList.Items {
+		var node corev1.Node
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &node); err != nil {
+			continue
+		}
+		allocatable := node.Status.Allocatable[gpuResource]
+		if allocatable.Value() == 0 {
+			continue
+		}
+		n := GPUNode{
+			NodeGPUs: NodeGPUs{
+				Name:        node.Name,
+				Allocatable: int(allocatable.Value()),
+				Requested:   requested[node.Name],
+			},
+			GPUProduct:   node.Labels[gpuProductLabel],
+			InstanceType: node.Labels[instanceTypeLabel],
+			// Tolerating all taints leaves readiness and cordoning.
+			Schedulable: nodeEligible(node, []corev1.Toleration{{Operator: corev1.TolerationOpExists}}),
+		}
+		n.Free = max(n.Allocatable-n.Requested, 0)
+		for _, taint := range node.Spec.Taints {
+			if taint.Effect != corev1.TaintEffectPreferNoSchedule {
+				n.Taints = append(n.Taints, taint.ToString())
+			}
+		}
+		info.GPUNodes = append(info.GPUNodes, n)
+
+		if !n.Schedulable {
+			continue
+		}
+		sum, ok := types[n.GPUProduct]
+		if !ok {
+			sum = &GPUTypeSum{Product: n.GPUProduct}
+			types[n.GPUProduct] = sum
+		}
+		sum.Nodes++
+		sum.Allocatable += n.Allocatable
+		sum.Free += n.Free
+		sum.LargestFree = max(sum.LargestFree, n.Free)
+	}
+	sort.Slice(info.GPUNodes, func(i, j int) bool { return info.GPUNodes[i].Name < info.GPUNodes[j].Name })
+	for _, sum := range types {
+		if sum.Product == "" {
+			sum.Product = "unknown"
+		}
+		info.GPUTypes = append(info.GPUTypes, *sum)
+	}
+	sort.Slice(info.GPUTypes, func(i, j int) bool { return info.GPUTypes[i].Product < info.GPUTypes[j].Product })
+	return nil
+}
+
+// addKServeVersion sets the KServe version from the controller Deployment:
+// its version label, or else the image tag of its manager container. It
+// returns the controller's namespace.
+func (m *Manager) addKServeVersion(ctx context.Context, info *ClusterInfo) (string, error) {
+	list, err := m.client.Resource(deploymentGVR).List(ctx, metav1.ListOptions{LabelSelector: kserveControllerSelector})
+	if err != nil {
+		return "", fmt.Errorf("failed to list deployments: %w", err)
+	}
+	if len(list.Items) == 0 {
+		return "", fmt.Errorf("KServe controller deployment not found")
+	}
+	controller := list.Items[0]
+
+	if version := controller.GetLabels()["app.kubernetes.io/version"]; version != "" {
+		info.KServeVersion = version
+		return controller.GetNamespace(), nil
+	}
+	containers, _, _ := unstructured.NestedSlice(controller.Object, "spec", "template", "spec", "containers")
+	for _, c := range containers {
+		container, _ := c.(map[string]interface{})
+		if name, _ := container["name"].(string); name != "manager" {
+			continue
+		}
+		image, _ := container["image"].(string)
+		if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+			info.KServeVersion = image[i+1:]
+		}
+	}
+	if info.KServeVersion == "" {
+		return controller.GetNamespace(), fmt.Errorf("controller deployment %s/%s has no version label or tagged manager image", controller.GetNamespace(), controller.GetName())
+	}
+	return controller.GetNamespace(), nil
+}
+
+// addDeploymentMode sets the default deployment mode from KServe's
+// ConfigMap in namespace.
+func (m *Manager) addDeploymentMode(ctx context.Context, info *ClusterInfo, namespace string) error {
+	cm, err := m.client.Resource(configMapGVR).Namespace(namespace).Get(ctx, kserveConfigMap, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get ConfigMap %s/%s: %w", namespace, kserveConfigMap, err)
+	}
+	data, _, _ := unstructured.NestedString(cm.Object, "data", "deploy")
+	var deploy struct {
+		DefaultDeploymentMode string `json:"defaultDeploymentMode"`
+	}
+	if data != "" {
+		if err := json.Unmarshal([]byte(data), &deploy); err != nil {
+			return fmt.Errorf("invalid deploy setting in ConfigMap %s/%s: %w", namespace, kserveConfigMap, err)
+		}
+	}
+	// KServe defaults to serverless mode.
+	info.DefaultDeploymentMode = deploy.DefaultDeploymentMode
+	if info.DefaultDeploymentMode == "" {
+		info.DefaultDeploymentMode = DeploymentModeServerless
+	}
+	return nil
+}
+
+// crdInstalled reports whether the CustomResourceDefinition name exists.
+func (m *Manager) crdInstalled(ctx context.Context, name string) (*bool, error) {
+	_, err := m.client.Resource(crdGVR).Get(ctx, name, metav1.GetOptions{})
+	installed := err == nil
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get CustomResourceDefinition %s: %w", name, err)
+	}
+	return &installed, nil
+}
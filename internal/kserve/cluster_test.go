@@ -0,0 +1,87 @@
+package kserve
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestManagerClusterInfo(t *testing.T) {
+	gpuTaint := corev1.Taint{Key: "nvidia.com/gpu", Value: "present", Effect: corev1.TaintEffectNoSchedule}
+	cordoned := gpuNode("h100-b", "8", map[string]string{gpuProductLabel: "NVIDIA-H100-80GB-HBM3"})
+	cordoned.Spec.Unschedulable = true
+	controller := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":      "kserve-controller-manager",
+			"namespace": "kserve",
+			"labels":    map[string]interface{}{"control-plane": "kserve-controller-manager"},
+		},
+		"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{
+			"containers": []interface{}{map[string]interface{}{"name": "manager", "image": "kserve/kserve-controller:v0.14.1"}},
+		}}},
+	}}
+	config := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": kserveConfigMap, "namespace": "kserve"},
+		"data":       map[string]interface{}{"deploy": `{"defaultDeploymentMode": "Serverless"}`},
+	}}
+	knative := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]interface{}{"name": knativeServiceCRD},
+	}}
+
+	m := newFakeManager(t,
+		makeRuntime(KindClusterServingRuntime, "kserve-vllm", "", "kserve/huggingfaceserver:v0.14.1", false),
+		toObject(t, gpuNode("a100", "4", map[string]string{gpuProductLabel: "NVIDIA-A100-SXM4-80GB", instanceTypeLabel: "p4d.24xlarge"})),
+		toObject(t, gpuNode("h100-a", "8", map[string]string{gpuProductLabel: "NVIDIA-H100-80GB-HBM3"}, gpuTaint)),
+		toObject(t, cordoned),
+		toObject(t, gpuNode("cpu", "0", nil)),
+		toObject(t, gpuPod("other", "team", "a100", "3", nil)),
+		controller, config, knative,
+	)
+
+	info, err := m.ClusterInfo(context.Background())
+	require.NoError(t, err)
+	require.Len(t, info.Runtimes, 1)
+	assert.Equal(t, []GPUNode{
+		{NodeGPUs: NodeGPUs{Name: "a100", Allocatable: 4, Requested: 3, Free: 1}, GPUProduct: "NVIDIA-A100-SXM4-80GB", InstanceType: "p4d.24xlarge", Schedulable: true},
+		{NodeGPUs: NodeGPUs{Name: "h100-a", Allocatable: 8, Free: 8}, GPUProduct: "NVIDIA-H100-80GB-HBM3", Schedulable: true, Taints: []string{"nvidia.com/gpu=present:NoSchedule"}},
+		{NodeGPUs: NodeGPUs{Name: "h100-b", Allocatable: 8, Free: 8}, GPUProduct: "NVIDIA-H100-80GB-HBM3"},
+	}, info.GPUNodes)
+	// Cordoned nodes do not count.
+	assert.Equal(t, []GPUTypeSum{
+		{Product: "NVIDIA-A100-SXM4-80GB", Nodes: 1, Allocatable: 4, Free: 1, LargestFree: 1},
+		{Product: "NVIDIA-H100-80GB-HBM3", Nodes: 1, Allocatable: 8, Free: 8, LargestFree: 8},
+	}, info.GPUTypes)
+	assert.Equal(t, "v0.14.1", info.KServeVersion)
+	assert.Equal(t, DeploymentModeServerless, info.DefaultDeploymentMode)
+	require.NotNil(t, info.KnativeServing)
+	assert.True(t, *info.KnativeServing)
+	require.NotNil(t, info.Istio)
+	assert.False(t, *info.Istio)
+	require.NotNil(t, info.ScaleToZero)
+	assert.True(t, *info.ScaleToZero)
+	assert.Empty(t, info.Warnings)
+}
+
+func TestManagerClusterInfoWithoutKServeController(t *testing.T) {
+	m := newFakeManager(t, makeRuntime(KindServingRuntime, "custom-vllm", "test-namespace", "vllm/vllm-openai:v0.6.3", false))
+
+	info, err := m.ClusterInfo(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, info.GPUNodes)
+	assert.Empty(t, info.KServeVersion)
+	assert.Empty(t, info.DefaultDeploymentMode)
+	assert.Equal(t, []string{"KServe version unknown: KServe controller deployment not found"}, info.Warnings)
+	// Without Knative Serving, nothing scales to zero.
+	require.NotNil(t, info.ScaleToZero)
+	assert.False(t, *info.ScaleToZero)
+}
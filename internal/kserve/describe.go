@@ -0,0 +1,148 @@
+package kserve
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ModelDetails is the detailed state of a deployed model.
+type ModelDetails struct {
+	ModelStatus
+
+	ModelURI   string `json:"model_uri,omitempty"`
+	Runtime    string `json:"runtime,omitempty"`
+	GPUCount   int    `json:"gpu_count"` // across all nodes
+	Nodes      int    `json:"nodes"`
+	Generation int64  `json:"generation,omitempty"`
+
+	// LatestCreatedRevision and LatestReadyRevision are the predictor's
+	// Knative revisions; they differ while a new revision rolls out.
+	LatestCreatedRevision string `json:"latest_created_revision,omitempty"`
+	LatestReadyRevision   string `json:"latest_ready_revision,omitempty"`
+
+	Conditions []StatusCondition `json:"conditions"`
+
+	// PodStatus summarizes the predictor pods, e.g. "downloading model".
+	PodStatus  string       `json:"pod_status,omitempty"`
+	PodMessage string       `json:"pod_message,omitempty"`
+	Pods       []PodDetails `json:"pods"`
+}
+
+// PodDetails is the state of a predictor pod.
+type PodDetails struct {
+	Name     string `json:"name"`
+	Phase    string `json:"phase"`
+	Ready    bool   `json:"ready"`
+	Node     string `json:"node,omitempty"`
+	Restarts int32  `json:"restarts"`
+	GPUs     int    `json:"gpus"`
+	Age      string `json:"age,omitempty"`
+}
+
+// Describe returns the detailed state of an InferenceService and its
+// predictor pods. Pod details are omitted when the pods cannot be listed.
+func (m *Manager) Describe(ctx context.Context, name string) (*ModelDetails, error) {
+	sanitized := sanitizeName(name)
+	item, err := m.client.Resource(isvcGVR).Namespace(m.namespace).Get(
+		ctx, sanitized, metav1.GetOptions{},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get InferenceService %s: %w", sanitized, m.accessError(err, "get", "inferenceservices"))
+	}
+
+	isvc, err := fromUnstructured(item)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert InferenceService %s: %w", sanitized, err)
+	}
+
+	d := &ModelDetails{
+		ModelStatus: m.statusFromISVC(isvc),
+		Generation:  isvc.Generation,
+		Conditions:  isvc.Status.Conditions,
+		Pods:        []PodDetails{},
+	}
+	if d.Conditions == nil {
+		d.Conditions = []StatusCondition{}
+	}
+	if model := isvc.Spec.Predictor.Model; model != nil {
+		if model.StorageURI != nil {
+			d.ModelURI = *model.StorageURI
+		} else if model.Storage != nil {
+			d.ModelURI = fmt.Sprintf("%s://%s/%s", SchemeS3, model.Storage.Parameters["bucket"], model.Storage.Path)
+		}
+		if model.Runtime != nil {
+			d.Runtime = *model.Runtime
+		}
+		gpus := model.Resources.Limits[gpuResource]
+		d.GPUCount = int(gpus.Value())
+	}
+	d.Nodes = 1
+	if ws := isvc.Spec.Predictor.WorkerSpec; ws != nil && ws.PipelineParallelSize != nil {
+		d.Nodes = *ws.PipelineParallelSize
+	}
+	d.GPUCount *= d.Nodes
+	if predictor, ok := isvc.Status.Components["predictor"]; ok {
+		d.LatestCreatedRevision = predictor.LatestCreatedRevision
+		d.LatestReadyRevision = predictor.LatestReadyRevision
+	}
+
+	pods, err := m.predictorPods(ctx, sanitized)
+	if err != nil {
+		d.PodMessage = err.Error()
+		return d, nil
+	}
+	d.PodStatus, d.PodMessage = summarizePods(pods)
+	for _, pod := range pods {
+		d.Pods = append(d.Pods, podDetails(pod))
+	}
+	return d, nil
+}
+
+// predictorPods lists the predictor pods of an InferenceService.
+func (m *Manager) predictorPods(ctx context.Context, name string) ([]corev1.Pod, error) {
+	list, err := m.client.Resource(podGVR).Namespace(m.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "serving.kserve.io/inferenceservice=" + name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list predictor pods: %w", err)
+	}
+
+	pods := make([]corev1.Pod, 0, len(list.Items))
+	for _, item := range list.Items {
+		var pod corev1.Pod
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &pod); err != nil {
+			continue
+		}
+		pods = append(pods, pod)
+	}
+	return pods, nil
+}
+
+func podDetails(pod corev1.Pod) PodDetails {
+	p := PodDetails{
+		Name:  pod.Name,
+		Phase: string(pod.Status.Phase),
+		Node:  pod.Spec.NodeName,
+	}
+	if !pod.CreationTimestamp.IsZero() {
+		p.Age = time.Since(pod.CreationTimestamp.Time).Round(time.Second).String()
+	}
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			p.Ready = c.Status == corev1.ConditionTrue
+		}
+	}
+	for _, cs := range pod.Status.ContainerStatuses {
+		p.Restarts += cs.RestartCount
+	}
+	for _, c := range pod.Spec.Containers {
+		gpus := c.Resources.Limits[gpuResource]
+		p.GPUs += int(gpus.Value())
+	}
+	return p
+}
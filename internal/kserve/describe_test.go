@@ -0,0 +1,87 @@
+package kserve
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestManagerDescribe(t *testing.T) {
+	cfg := DefaultModelConfig("described", "hf://org/model")
+	cfg.GPUCount = 2
+	isvc := deployedISVC(t, cfg, true)
+	require.NoError(t, unstructured.SetNestedField(isvc.Object, map[string]interface{}{
+		"predictor": map[string]interface{}{
+			"latestCreatedRevision": "described-predictor-00002",
+			"latestReadyRevision":   "described-predictor-00001",
+		},
+	}, "status", "components"))
+
+	pod := &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "described-predictor-abc",
+			Namespace:         "test-namespace",
+			Labels:            map[string]string{"serving.kserve.io/inferenceservice": "described"},
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Minute)),
+		},
+		Spec: corev1.PodSpec{
+			NodeName: "gpu-node-1",
+			Containers: []corev1.Container{{
+				Name: "kserve-container",
+				Resources: corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{gpuResource: resource.MustParse("2")},
+				},
+			}},
+		},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:         "kserve-container",
+				Ready:        true,
+				RestartCount: 1,
+				State:        corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+			}},
+		},
+	}
+	podObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(pod)
+	require.NoError(t, err)
+
+	m := newFakeManager(t, isvc, &unstructured.Unstructured{Object: podObj})
+
+	d, err := m.Describe(context.Background(), "described")
+	require.NoError(t, err)
+	assert.Equal(t, "described", d.Name)
+	assert.True(t, d.Ready)
+	assert.Equal(t, "hf://org/model", d.ModelURI)
+	assert.Equal(t, DefaultRuntime, d.Runtime)
+	assert.Equal(t, 2, d.GPUCount)
+	assert.Equal(t, "described-predictor-00002", d.LatestCreatedRevision)
+	assert.Equal(t, "described-predictor-00001", d.LatestReadyRevision)
+	require.Len(t, d.Conditions, 1)
+	assert.Equal(t, "Ready", d.Conditions[0].Type)
+
+	assert.Equal(t, "Running", d.PodStatus)
+	require.Len(t, d.Pods, 1)
+	p := d.Pods[0]
+	assert.Equal(t, "described-predictor-abc", p.Name)
+	assert.True(t, p.Ready)
+	assert.Equal(t, "gpu-node-1", p.Node)
+	assert.Equal(t, int32(1), p.Restarts)
+	assert.Equal(t, 2, p.GPUs)
+}
+
+func TestManagerDescribeNotFound(t *testing.T) {
+	m := newFakeManager(t)
+	_, err := m.Describe(context.Background(), "missing")
+	assert.ErrorContains(t, err, "failed to get InferenceService missing")
+}
@@ -0,0 +1,193 @@
+package kserve
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// diagnosticLogLines is the number of log lines collected per container.
+	diagnosticLogLines = 50
+
+	// diagnosticTimeout bounds diagnostic collection after a failed deploy,
+	// which may happen after the deploy context has expired.
+	diagnosticTimeout = 30 * time.Second
+)
+
+// Diagnostics is the state of a failed deployment's predictor pods.
+type Diagnostics struct {
+	Name string
+	Pods []PodDiagnostics
+}
+
+// PodDiagnostics holds the recent events and logs of a predictor pod.
+type PodDiagnostics struct {
+	Name   string
+	Phase  string
+	Events []string
+	Logs   []ContainerLog
+}
+
+// ContainerLog is the tail of a container's log.
+type ContainerLog struct {
+	Container string
+	Log       string
+	Error     string // set when the log could not be fetched
+}
+
+// Report renders the diagnostics as plain text.
+func (d *Diagnostics) Report() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Diagnostics for InferenceService %s\n", d.Name)
+	if len(d.Pods) == 0 {
+		b.WriteString("\nNo predictor pods found.\n")
+	}
+	for _, pod := range d.Pods {
+		fmt.Fprintf(&b, "\nPod %s (%s)\n", pod.Name, pod.Phase)
+		if len(pod.Events) > 0 {
+			b.WriteString("Events:\n")
+			for _, e := range pod.Events {
+				fmt.Fprintf(&b, "  %s\n", e)
+			}
+		}
+		for _, l := range pod.Logs {
+			fmt.Fprintf(&b, "Logs of container %s (last %d lines):\n", l.Container, diagnosticLogLines)
+			if l.Error != "" {
+				fmt.Fprintf(&b, "  unavailable: %s\n", l.Error)
+				continue
+			}
+			for _, line := range strings.Split(strings.TrimRight(l.Log, "\n"), "\n") {
+				fmt.Fprintf(&b, "  %s\n", line)
+			}
+		}
+	}
+	return b.String()
+}
+
+// DeployError is returned by Deploy when an InferenceService does not become
+// ready. It carries the diagnostics collected from its predictor pods, if any.
+type DeployError struct {
+	Name        string
+	Err         error
+	Diagnostics *Diagnostics
+}
+
+func (e *DeployError) Error() string {
+	msg := fmt.Sprintf("InferenceService %s not ready: %v", e.Name, e.Err)
+	if e.Diagnostics != nil {
+		msg += "\n\n" + e.Diagnostics.Report()
+	}
+	return msg
+}
+
+func (e *DeployError) Unwrap() error {
+	return e.Err
+}
+
+// DiagnosticReport returns the diagnostics report, or "" when none was collected.
+func (e *DeployError) DiagnosticReport() string {
+	if e.Diagnostics == nil {
+		return ""
+	}
+	return e.Diagnostics.Report()
+}
+
+// CollectDiagnostics gathers the events and recent logs of the predictor pods
+// of an InferenceService. It requires a typed Kubernetes client.
+func (m *Manager) CollectDiagnostics(ctx context.Context, name string) (*Diagnostics, error) {
+	if m.kube == nil {
+		return nil, fmt.Errorf("no Kubernetes client configured for collecting diagnostics")
+	}
+	name = sanitizeName(name)
+
+	pods, err := m.kube.CoreV1().Pods(m.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "serving.kserve.io/inferenceservice=" + name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list predictor pods: %w", err)
+	}
+
+	d := &Diagnostics{Name: name}
+	for _, pod := range pods.Items {
+		pd := PodDiagnostics{Name: pod.Name, Phase: string(pod.Status.Phase)}
+		pd.Events = m.podEvents(ctx, pod.Name)
+
+		containers := make([]corev1.Container, 0, len(pod.Spec.InitContainers)+len(pod.Spec.Containers))
+		containers = append(containers, pod.Spec.InitContainers...)
+		containers = append(containers, pod.Spec.Containers...)
+		for _, c := range containers {
+			if !containerStarted(pod, c.Name) {
+				continue
+			}
+			pd.Logs = append(pd.Logs, m.containerLog(ctx, pod.Name, c.Name))
+		}
+		d.Pods = append(d.Pods, pd)
+	}
+	return d, nil
+}
+
+// podEvents returns the pod's events in chronological order.
+func (m *Manager) podEvents(ctx context.Context, pod string) []string {
+	events, err := m.kube.CoreV1().Events(m.namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: "involvedObject.kind=Pod,involvedObject.name=" + pod,
+	})
+	if err != nil {
+		return []string{fmt.Sprintf("failed to list events: %v", err)}
+	}
+
+	items := events.Items
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].LastTimestamp.Before(&items[j].LastTimestamp)
+	})
+	lines := make([]string, 0, len(items))
+	for _, e := range items {
+		line := fmt.Sprintf("%s %s: %s", e.Type, e.Reason, strings.TrimSpace(e.Message))
+		if e.Count > 1 {
+			line += fmt.Sprintf(" (x%d)", e.Count)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func (m *Manager) containerLog(ctx context.Context, pod, container string) ContainerLog {
+	tail := int64(diagnosticLogLines)
+	l := ContainerLog{Container: container}
+
+	stream, err := m.kube.CoreV1().Pods(m.namespace).GetLogs(pod, &corev1.PodLogOptions{
+		Container: container,
+		TailLines: &tail,
+	}).Stream(ctx)
+	if err != nil {
+		l.Error = err.Error()
+		return l
+	}
+	defer func() { _ = stream.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(stream, 64<<10))
+	if err != nil {
+		l.Error = err.Error()
+		return l
+	}
+	l.Log = string(data)
+	return l
+}
+
+// containerStarted reports whether a container has run, i.e. has logs.
+func containerStarted(pod corev1.Pod, name string) bool {
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, cs := range statuses {
+		if cs.Name != name {
+			continue
+		}
+		return cs.State.Running != nil || cs.State.Terminated != nil || cs.LastTerminationState.Terminated != nil
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package kserve
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func predictorPod(isvc string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      isvc + "-predictor-abc",
+			Namespace: "test-namespace",
+			Labels:    map[string]string{"serving.kserve.io/inferenceservice": isvc},
+		},
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: storageInitializer}},
+			Containers:     []corev1.Container{{Name: "kserve-container"}},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			InitContainerStatuses: []corev1.ContainerStatus{{
+				Name:  storageInitializer,
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0}},
+			}},
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:                 "kserve-container",
+				State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+				LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137}},
+			}},
+		},
+	}
+}
+
+func podEvent(pod, name, reason, message string, count int32, at time.Time) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "test-namespace"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: pod, Namespace: "test-namespace"},
+		Type:           corev1.EventTypeWarning,
+		Reason:         reason,
+		Message:        message,
+		Count:          count,
+		LastTimestamp:  metav1.NewTime(at),
+	}
+}
+
+func TestCollectDiagnostics(t *testing.T) {
+	m := newFakeManager(t)
+	now := time.Now()
+	pod := predictorPod("diag-test")
+	m.SetKubeClient(kubefake.NewSimpleClientset(
+		pod,
+		podEvent(pod.Name, "e2", "BackOff", "Back-off restarting failed container", 5, now),
+		podEvent(pod.Name, "e1", "Pulled", "Successfully pulled image", 1, now.Add(-time.Minute)),
+	))
+
+	d, err := m.CollectDiagnostics(context.Background(), "diag-test")
+	require.NoError(t, err)
+	require.Len(t, d.Pods, 1)
+
+	pd := d.Pods[0]
+	assert.Equal(t, "Running", pd.Phase)
+	assert.Equal(t, []string{
+		"Warning Pulled: Successfully pulled image",
+		"Warning BackOff: Back-off restarting failed container (x5)",
+	}, pd.Events)
+	require.Len(t, pd.Logs, 2)
+	assert.Equal(t, storageInitializer, pd.Logs[0].Container)
+	assert.Equal(t, "kserve-container", pd.Logs[1].Container)
+	assert.Equal(t, "fake logs", pd.Logs[1].Log)
+
+	report := d.Report()
+	assert.Contains(t, report, "Pod diag-test-predictor-abc (Running)")
+	assert.Contains(t, report, "BackOff: Back-off restarting failed container (x5)")
+	assert.Contains(t, report, "Logs of container kserve-container")
+}
+
+func TestCollectDiagnosticsSkipsUnstartedContainers(t *testing.T) {
+	m := newFakeManager(t)
+	pod := predictorPod("diag-test")
+	pod.Status.InitContainerStatuses = nil
+	pod.Status.ContainerStatuses = nil
+	m.SetKubeClient(kubefake.NewSimpleClientset(pod))
+
+	d, err := m.CollectDiagnostics(context.Background(), "diag-test")
+	require.NoError(t, err)
+	require.Len(t, d.Pods, 1)
+	assert.Empty(t, d.Pods[0].Logs)
+}
+
+func TestCollectDiagnosticsNoPods(t *testing.T) {
+	m := newFakeManager(t)
+	m.SetKubeClient(kubefake.NewSimpleClientset())
+
+	d, err := m.CollectDiagnostics(context.Background(), "missing")
+	require.NoError(t, err)
+	assert.Empty(t, d.Pods)
+	assert.Contains(t, d.Report(), "No predictor pods found.")
+}
+
+func TestCollectDiagnosticsWithoutClient(t *testing.T) {
+	m := newFakeManager(t)
+	_, err := m.CollectDiagnostics(context.Background(), "diag-test")
+	assert.ErrorContains(t, err, "no Kubernetes client")
+}
+
+func TestDeployErrorIncludesDiagnostics(t *testing.T) {
+	m := newFakeManager(t)
+	m.SetKubeClient(kubefake.NewSimpleClientset(predictorPod("deploy-test")))
+
+	cfg := DefaultModelConfig("deploy-test", "hf://org/model")
+	cfg.ReadyTimeout = 100 * time.Millisecond
+
+	_, err := m.Deploy(context.Background(), cfg)
+	require.Error(t, err)
+
+	var deployErr *DeployError
+	require.True(t, errors.As(err, &deployErr))
+	require.NotNil(t, deployErr.Diagnostics)
+	assert.Contains(t, deployErr.DiagnosticReport(), "fake logs")
+	assert.Contains(t, err.Error(), "not ready")
+}
@@ -0,0 +1,99 @@
+package kserve
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// URLMode selects the address at which a deployed model is reached.
+type URLMode string
+
+const (
+	// URLModeClusterLocal uses the cluster-local service address, which is
+	// only reachable from within the cluster.
+	URLModeClusterLocal URLMode = "cluster-local"
+	// URLModeExternal uses the external URL from the InferenceService
+	// status, e.g. the Knative or Istio ingress address. Without one, it
+	// falls back to URLModePortForward, so that a server running outside
+	// the cluster works without ingress.
+	URLModeExternal URLMode = "external"
+	// URLModePortForward forwards a local port to the predictor pod.
+	URLModePortForward URLMode = "port-forward"
+)
+
+// ParseURLMode validates a URL mode; empty means the default, which is the
+// status URL and otherwise the cluster-local address.
+func ParseURLMode(s string) (URLMode, error) {
+	switch m := URLMode(s); m {
+	case "", URLModeClusterLocal, URLModeExternal, URLModePortForward:
+		return m, nil
+	}
+	return "", fmt.Errorf("invalid endpoint URL mode %q (expected cluster-local, external, or port-forward)", s)
+}
+
+// DefaultURLMode returns the URL mode for a server running in or outside
+// the cluster.
+func DefaultURLMode(inCluster bool) URLMode {
+	if inCluster {
+		return URLModeClusterLocal
+	}
+	return URLModeExternal
+}
+
+// ModelEndpoint returns the OpenAI base URL of a deployed model in the
+// given URL mode. In port-forward mode it starts forwarding a local port to
+// the predictor pod, which lasts until the model is torn down.
+func (m *Manager) ModelEndpoint(ctx context.Context, name string, mode URLMode) (string, error) {
+	sanitized := sanitizeName(name)
+	item, err := m.client.Resource(isvcGVR).Namespace(m.namespace).Get(ctx, sanitized, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get InferenceService %s: %w", sanitized, m.accessError(err, "get", "inferenceservices"))
+	}
+	isvc, err := fromUnstructured(item)
+	if err != nil {
+		return "", fmt.Errorf("failed to convert InferenceService %s: %w", sanitized, err)
+	}
+
+	switch mode {
+	case "":
+		return endpointURL(isvc, m.namespace), nil
+	case URLModeClusterLocal:
+		if isvc.Status.Address != nil && isvc.Status.Address.URL != "" {
+			return openAIBaseURL(isvc.Status.Address.URL), nil
+		}
+		return EndpointURL(sanitized, m.namespace), nil
+	case URLModeExternal:
+		if isvc.Status.URL == "" || isClusterLocal(isvc.Status.URL) {
+			slog.Info("InferenceService has no external URL, using port-forward", "name", sanitized, "url", isvc.Status.URL)
+			return m.portForward(ctx, sanitized)
+		}
+		return openAIBaseURL(isvc.Status.URL), nil
+	case URLModePortForward:
+		return m.portForward(ctx, sanitized)
+	}
+	return "", fmt.Errorf("invalid endpoint URL mode %q", mode)
+}
+
+// isClusterLocal reports whether a URL points at a cluster-local service.
+func isClusterLocal(u string) bool {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return false
+	}
+	host := parsed.Hostname()
+	return strings.HasSuffix(host, ".svc") || strings.HasSuffix(host, ".cluster.local")
+}
+
+// openAIBaseURL returns the OpenAI API base URL of a model server address.
+func openAIBaseURL(u string) string {
+	u = strings.TrimSuffix(u, "/")
+	if strings.HasSuffix(u, "/v1") {
+		return u
+	}
+	return u + "/v1"
+}
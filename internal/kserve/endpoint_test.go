@@ -0,0 +1,136 @@
+package kserve
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestParseURLMode(t *testing.T) {
+	for _, s := range []string{"", "cluster-local", "external", "port-forward"} {
+		mode, err := ParseURLMode(s)
+		require.NoError(t, err)
+		assert.Equal(t, URLMode(s), mode)
+	}
+	_, err := ParseURLMode("ingress")
+	assert.ErrorContains(t, err, "invalid endpoint URL mode")
+
+	assert.Equal(t, URLModeClusterLocal, DefaultURLMode(true))
+	assert.Equal(t, URLModeExternal, DefaultURLMode(false))
+}
+
+func TestManagerModelEndpoint(t *testing.T) {
+	knative := makeISVC("knative", "test-namespace", true)
+	require.NoError(t, unstructured.SetNestedField(knative.Object, "https://knative.example.com", "status", "url"))
+	require.NoError(t, unstructured.SetNestedField(knative.Object, "http://knative-predictor.test-namespace.svc.cluster.local", "status", "address", "url"))
+
+	pending := makeISVC("pending", "test-namespace", false)
+	unstructured.RemoveNestedField(pending.Object, "status", "url")
+
+	m := newFakeManager(t, knative, pending)
+	ctx := context.Background()
+
+	tests := []struct {
+		name, isvc string
+		mode       URLMode
+		want       string
+	}{
+		{"default uses the status URL", "knative", "", "https://knative.example.com/v1"},
+		{"external", "knative", URLModeExternal, "https://knative.example.com/v1"},
+		{"cluster-local address", "knative", URLModeClusterLocal, "http://knative-predictor.test-namespace.svc.cluster.local/v1"},
+		{"cluster-local fallback", "pending", URLModeClusterLocal, "http://pending.test-namespace.svc.cluster.local/v1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.ModelEndpoint(ctx, tt.isvc, tt.mode)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := m.ModelEndpoint(ctx, "pending", URLModeExternal)
+	assert.ErrorContains(t, err, "requires a Kubernetes client configuration", "falls back to port-forward")
+	_, err = m.ModelEndpoint(ctx, "knative", URLModePortForward)
+	assert.ErrorContains(t, err, "requires a Kubernetes client configuration")
+	_, err = m.ModelEndpoint(ctx, "missing", URLModeExternal)
+	assert.Error(t, err)
+}
+
+func TestForwardTarget(t *testing.T) {
+	ready := []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+	pod := func(name, component string, conditions []corev1.PodCondition, ports ...int32) corev1.Pod {
+		p := corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"component": component}},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "kserve-container"}}},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning, Conditions: conditions},
+		}
+		for _, port := range ports {
+			p.Spec.Containers[0].Ports = append(p.Spec.Containers[0].Ports, corev1.ContainerPort{ContainerPort: port})
+		}
+		return p
+	}
+
+	target, port, err := forwardTarget([]corev1.Pod{
+		pod("starting", "predictor", nil),
+		pod("worker", "predictor-worker", ready),
+		pod("head", "predictor", ready, 8000),
+	}, intstr.IntOrString{})
+	require.NoError(t, err)
+	assert.Equal(t, "head", target.Name)
+	assert.EqualValues(t, 8000, port)
+
+	_, port, err = forwardTarget([]corev1.Pod{pod("head", "", ready)}, intstr.IntOrString{})
+	require.NoError(t, err)
+	assert.EqualValues(t, defaultModelPort, port)
+
+	_, port, err = forwardTarget([]corev1.Pod{pod("head", "", ready, 8000)}, intstr.FromInt32(8012))
+	require.NoError(t, err)
+	assert.EqualValues(t, 8012, port)
+
+	named := pod("head", "", ready, 8000)
+	named.Spec.Containers[0].Ports[0].Name = "http1"
+	_, port, err = forwardTarget([]corev1.Pod{named}, intstr.FromString("http1"))
+	require.NoError(t, err)
+	assert.EqualValues(t, 8000, port)
+	_, _, err = forwardTarget([]corev1.Pod{named}, intstr.FromString("grpc"))
+	assert.ErrorContains(t, err, "has no port grpc")
+
+	_, _, err = forwardTarget([]corev1.Pod{pod("starting", "predictor", nil)}, intstr.IntOrString{})
+	assert.ErrorContains(t, err, "no ready predictor pod")
+}
+
+func TestManagerPredictorService(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "svc-test-predictor", Namespace: "test-namespace"},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"app": "svc-test-predictor"},
+			Ports:    []corev1.ServicePort{{Port: 80, TargetPort: intstr.FromInt32(8080)}},
+		},
+	}
+	selected := predictorPod("svc-test")
+	selected.Labels["app"] = "svc-test-predictor"
+	other := predictorPod("other")
+	other.Name = "other-predictor-abc"
+
+	m := newFakeManager(t)
+	m.SetKubeClient(kubefake.NewSimpleClientset(svc, selected, other))
+
+	pods, targetPort, err := m.predictorService(context.Background(), "svc-test")
+	require.NoError(t, err)
+	require.Len(t, pods, 1)
+	assert.Equal(t, selected.Name, pods[0].Name)
+	assert.Equal(t, intstr.FromInt32(8080), targetPort)
+}
+
+func TestIsClusterLocal(t *testing.T) {
+	assert.True(t, isClusterLocal("http://model.ns.svc.cluster.local"))
+	assert.True(t, isClusterLocal("http://model-predictor.ns.svc:8080"))
+	assert.False(t, isClusterLocal("https://model.ns.example.com"))
+}
@@ -1,7 +1,11 @@
 package kserve
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -16,11 +20,21 @@ const (
 	apiVersion = "serving.kserve.io/v1beta1"
 	kind       = "InferenceService"
 	managedBy  = "llm-testing"
+
+	// deploymentModeAnnotation selects KServe's deployment mode; multi-node
+	// predictors require RawDeployment.
+	deploymentModeAnnotation = "serving.kserve.io/deploymentMode"
+
+	gpuResource corev1.ResourceName = "nvidia.com/gpu"
 )
 
 // BuildInferenceService creates a typed InferenceService object from a ModelConfig.
+// The config must be valid (see ModelConfig.Validate).
 func BuildInferenceService(cfg ModelConfig, namespace string) *InferenceService {
 	storageURI := cfg.ModelURI
+	if cfg.GGUFFile != "" {
+		storageURI = ggufStorageURI(cfg.ModelURI, cfg.GGUFFile)
+	}
 
 	isvc := &InferenceService{
 		TypeMeta: metav1.TypeMeta{
@@ -39,7 +53,7 @@ func BuildInferenceService(cfg ModelConfig, namespace string) *InferenceService
 			Predictor: PredictorSpec{
 				Model: &ISvcModelSpec{
 					ModelFormat: ModelFormat{
-						Name: "vLLM",
+						Name: cfg.Backend.profile().modelFormat,
 					},
 					StorageURI: &storageURI,
 				},
@@ -47,30 +61,188 @@ func BuildInferenceService(cfg ModelConfig, namespace string) *InferenceService
 		},
 	}
 
+	if cfg.StorageKey != "" {
+		if loc, err := ParseStorageURI(cfg.ModelURI); err == nil {
+			isvc.Spec.Predictor.Model.StorageURI = nil
+			isvc.Spec.Predictor.Model.Storage = &StorageSpec{
+				Key:        cfg.StorageKey,
+				Path:       loc.Path,
+				Parameters: map[string]string{"bucket": loc.Source},
+			}
+		}
+	}
+
 	if cfg.Runtime != "" {
-		rt := cfg.Runtime
+		rt := sanitizeName(cfg.Runtime) // as named by CreateRuntime
 		isvc.Spec.Predictor.Model.Runtime = &rt
 	}
 
-	if cfg.GPUCount > 0 {
-		gpuQty := resource.MustParse(strconv.Itoa(cfg.GPUCount))
-		isvc.Spec.Predictor.Model.Resources = corev1.ResourceRequirements{
-			Requests: corev1.ResourceList{
-				"nvidia.com/gpu": gpuQty,
-			},
-			Limits: corev1.ResourceList{
-				"nvidia.com/gpu": gpuQty,
+	if gpus := cfg.NodeGPUs(); gpus > 0 {
+		gpuQty := resource.MustParse(strconv.Itoa(gpus))
+		setResource(&isvc.Spec.Predictor.Model.Resources.Requests, gpuResource, gpuQty)
+		setResource(&isvc.Spec.Predictor.Model.Resources.Limits, gpuResource, gpuQty)
+	}
+	if cfg.CPURequest != "" {
+		setResource(&isvc.Spec.Predictor.Model.Resources.Requests, corev1.ResourceCPU, resource.MustParse(cfg.CPURequest))
+	}
+	if cfg.CPULimit != "" {
+		setResource(&isvc.Spec.Predictor.Model.Resources.Limits, corev1.ResourceCPU, resource.MustParse(cfg.CPULimit))
+	}
+	if cfg.MemoryRequest != "" {
+		setResource(&isvc.Spec.Predictor.Model.Resources.Requests, corev1.ResourceMemory, resource.MustParse(cfg.MemoryRequest))
+	}
+	if cfg.MemoryLimit != "" {
+		setResource(&isvc.Spec.Predictor.Model.Resources.Limits, corev1.ResourceMemory, resource.MustParse(cfg.MemoryLimit))
+	}
+
+	if cfg.ShmSize != "" {
+		shmSize := resource.MustParse(cfg.ShmSize)
+		isvc.Spec.Predictor.Volumes = []corev1.Volume{{
+			Name: "shm",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{
+					Medium:    corev1.StorageMediumMemory,
+					SizeLimit: &shmSize,
+				},
 			},
+		}}
+		isvc.Spec.Predictor.Model.VolumeMounts = []corev1.VolumeMount{{
+			Name:      "shm",
+			MountPath: "/dev/shm",
+		}}
+	}
+
+	if args := cfg.modelArgs(); len(args) > 0 {
+		isvc.Spec.Predictor.Model.Args = args
+	}
+
+	if cfg.Nodes() > 1 {
+		setMultiNode(isvc, cfg)
+	} else if cfg.TensorParallelSize > 1 && !hasRuntimeArg(cfg.RuntimeArgs, "--tensor-parallel-size") {
+		isvc.Spec.Predictor.Model.Args = append(isvc.Spec.Predictor.Model.Args, fmt.Sprintf("--tensor-parallel-size=%d", cfg.TensorParallelSize))
+	}
+
+	if cfg.RawDeployment {
+		isvc.Annotations = mergeStrings(isvc.Annotations, map[string]string{deploymentModeAnnotation: "RawDeployment"})
+	}
+
+	names := make([]string, 0, len(cfg.Env))
+	for name := range cfg.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		isvc.Spec.Predictor.Model.Env = append(isvc.Spec.Predictor.Model.Env, corev1.EnvVar{Name: name, Value: cfg.Env[name]})
+	}
+
+	if cfg.HFTokenSecret != "" {
+		key := cfg.HFTokenSecretKey
+		if key == "" {
+			key = DefaultHFTokenSecretKey
 		}
+		isvc.Spec.Predictor.Model.Env = append(isvc.Spec.Predictor.Model.Env, corev1.EnvVar{
+			Name: "HF_TOKEN",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: cfg.HFTokenSecret},
+					Key:                  key,
+				},
+			},
+		})
+	}
+	isvc.Spec.Predictor.ServiceAccountName = cfg.ServiceAccountName
+	for _, name := range cfg.ImagePullSecrets {
+		isvc.Spec.Predictor.ImagePullSecrets = append(isvc.Spec.Predictor.ImagePullSecrets, corev1.LocalObjectReference{Name: name})
+	}
+
+	isvc.Spec.Predictor.NodeSelector = cfg.NodeSelector
+	isvc.Spec.Predictor.Tolerations = cfg.Tolerations
+	isvc.Spec.Predictor.Affinity = cfg.Affinity
+	if cfg.RuntimeClassName != "" {
+		rc := cfg.RuntimeClassName
+		isvc.Spec.Predictor.RuntimeClassName = &rc
 	}
 
-	if len(cfg.RuntimeArgs) > 0 {
-		isvc.Spec.Predictor.Model.Args = cfg.RuntimeArgs
+	if cfg.MinReplicas != nil {
+		minReplicas := *cfg.MinReplicas
+		isvc.Spec.Predictor.MinReplicas = &minReplicas
 	}
+	isvc.Spec.Predictor.MaxReplicas = cfg.MaxReplicas
+	isvc.Spec.Predictor.ScaleTarget = cfg.ScaleTarget
+	isvc.Spec.Predictor.ScaleMetric = cfg.ScaleMetric
 
 	return isvc
 }
 
+// setMultiNode renders the workerSpec of a predictor spanning cfg.Nodes()
+// nodes with cfg.NodeGPUs() GPUs each. The multi-node runtime serves the
+// huggingface model format in RawDeployment mode.
+func setMultiNode(isvc *InferenceService, cfg ModelConfig) {
+	nodes, gpus := cfg.Nodes(), cfg.NodeGPUs()
+	gpuQty := resource.MustParse(strconv.Itoa(gpus))
+
+	isvc.Annotations = mergeStrings(isvc.Annotations, map[string]string{deploymentModeAnnotation: "RawDeployment"})
+	isvc.Spec.Predictor.Model.ModelFormat.Name = "huggingface"
+	if cfg.Runtime == "" || cfg.Runtime == DefaultRuntime {
+		rt := DefaultMultiNodeRuntime
+		isvc.Spec.Predictor.Model.Runtime = &rt
+	}
+	isvc.Spec.Predictor.WorkerSpec = &WorkerSpec{
+		PipelineParallelSize: &nodes,
+		TensorParallelSize:   &gpus,
+		Containers: []corev1.Container{{
+			Name: "worker-container",
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{gpuResource: gpuQty},
+				Limits:   corev1.ResourceList{gpuResource: gpuQty},
+			},
+		}},
+	}
+}
+
+// specHash returns a short digest of an InferenceService spec and its
+// deployment mode, which is set by annotation.
+func specHash(isvc *InferenceService) (string, error) {
+	data, err := json.Marshal(struct {
+		Spec           InferenceServiceSpec `json:"spec"`
+		DeploymentMode string               `json:"deploymentMode,omitempty"`
+	}{isvc.Spec, isvc.Annotations[deploymentModeAnnotation]})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal InferenceService spec: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// isReadyAndCurrent reports whether the InferenceService is ready and its
+// status reflects the latest spec, i.e. not a previous revision after an update.
+func isReadyAndCurrent(isvc *InferenceService) bool {
+	if !isvc.Status.IsReady() {
+		return false
+	}
+	observed := isvc.Status.ObservedGeneration
+	return observed == 0 || observed >= isvc.Generation
+}
+
+// mergeStrings returns base with the entries of overrides set.
+func mergeStrings(base, overrides map[string]string) map[string]string {
+	merged := make(map[string]string, len(base)+len(overrides))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range overrides {
+		merged[k] = v
+	}
+	return merged
+}
+
+func setResource(list *corev1.ResourceList, name corev1.ResourceName, qty resource.Quantity) {
+	if *list == nil {
+		*list = corev1.ResourceList{}
+	}
+	(*list)[name] = qty
+}
+
 // toUnstructured converts a typed InferenceService to an unstructured object
 // for use with the dynamic Kubernetes client.
 func toUnstructured(isvc *InferenceService) (*unstructured.Unstructured, error) {
@@ -90,6 +262,18 @@ func fromUnstructured(obj *unstructured.Unstructured) (*InferenceService, error)
 	return isvc, nil
 }
 
+// RevisionName returns the InferenceService name of a revision of a base
+// model, e.g. "llama-3-8b-awq" for base "llama-3-8b" and revision "awq".
+// The base is shortened when needed so that the revision suffix is kept.
+func RevisionName(base, revision string) string {
+	suffix := "-" + strings.TrimPrefix(sanitizeName("r-"+revision), "r-")
+	name := sanitizeName(base)
+	if limit := 63 - len(suffix); len(name) > limit {
+		name = strings.TrimRight(name[:limit], "-")
+	}
+	return name + suffix
+}
+
 // sanitizeName converts a model name to a valid Kubernetes resource name.
 func sanitizeName(name string) string {
 	result := make([]byte, 0, len(name))
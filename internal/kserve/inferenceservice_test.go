@@ -6,6 +6,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 )
 
 func TestBuildInferenceService(t *testing.T) {
@@ -66,6 +67,272 @@ func TestBuildInferenceServiceWithArgs(t *testing.T) {
 	assert.Equal(t, "4", gpuReq.String())
 }
 
+func TestBuildInferenceServiceWithPlacement(t *testing.T) {
+	cfg := DefaultModelConfig("llama-70b", "hf://meta-llama/Llama-3.1-70B-Instruct")
+	cfg.NodeSelector = map[string]string{"nvidia.com/gpu.product": "NVIDIA-H100-80GB-HBM3"}
+	cfg.Tolerations = []corev1.Toleration{{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}}
+	cfg.RuntimeClassName = "nvidia"
+
+	isvc := BuildInferenceService(cfg, "llm-testing")
+	p := isvc.Spec.Predictor
+	assert.Equal(t, cfg.NodeSelector, p.NodeSelector)
+	assert.Equal(t, cfg.Tolerations, p.Tolerations)
+	assert.Nil(t, p.Affinity)
+	require.NotNil(t, p.RuntimeClassName)
+	assert.Equal(t, "nvidia", *p.RuntimeClassName)
+
+	obj, err := toUnstructured(isvc)
+	require.NoError(t, err)
+	predictor := obj.Object["spec"].(map[string]interface{})["predictor"].(map[string]interface{})
+	assert.Contains(t, predictor, "nodeSelector")
+	assert.Contains(t, predictor, "tolerations")
+	assert.Equal(t, "nvidia", predictor["runtimeClassName"])
+	assert.NotContains(t, predictor, "affinity")
+}
+
+func TestBuildInferenceServiceWithResources(t *testing.T) {
+	cfg := DefaultModelConfig("llama-70b", "hf://meta-llama/Llama-3.1-70B-Instruct")
+	cfg.GPUCount = 4
+	cfg.CPURequest = "8"
+	cfg.CPULimit = "16"
+	cfg.MemoryRequest = "64Gi"
+	cfg.MemoryLimit = "96Gi"
+	cfg.ShmSize = "8Gi"
+
+	isvc := BuildInferenceService(cfg, "llm-testing")
+	res := isvc.Spec.Predictor.Model.Resources
+
+	gpu := res.Requests["nvidia.com/gpu"]
+	assert.Equal(t, "4", gpu.String())
+	cpuReq := res.Requests[corev1.ResourceCPU]
+	assert.Equal(t, "8", cpuReq.String())
+	cpuLim := res.Limits[corev1.ResourceCPU]
+	assert.Equal(t, "16", cpuLim.String())
+	memReq := res.Requests[corev1.ResourceMemory]
+	assert.Equal(t, "64Gi", memReq.String())
+	memLim := res.Limits[corev1.ResourceMemory]
+	assert.Equal(t, "96Gi", memLim.String())
+
+	require.Len(t, isvc.Spec.Predictor.Volumes, 1)
+	vol := isvc.Spec.Predictor.Volumes[0]
+	require.NotNil(t, vol.EmptyDir)
+	assert.Equal(t, corev1.StorageMediumMemory, vol.EmptyDir.Medium)
+	assert.Equal(t, "8Gi", vol.EmptyDir.SizeLimit.String())
+	assert.Equal(t, []corev1.VolumeMount{{Name: "shm", MountPath: "/dev/shm"}}, isvc.Spec.Predictor.Model.VolumeMounts)
+}
+
+func TestBuildInferenceServiceWithoutShm(t *testing.T) {
+	cfg := DefaultModelConfig("m", "hf://org/model")
+	cfg.ShmSize = ""
+
+	isvc := BuildInferenceService(cfg, "llm-testing")
+	assert.Empty(t, isvc.Spec.Predictor.Volumes)
+	assert.Empty(t, isvc.Spec.Predictor.Model.VolumeMounts)
+	assert.NotContains(t, isvc.Spec.Predictor.Model.Resources.Requests, corev1.ResourceCPU)
+}
+
+func TestBuildInferenceServiceWithCredentials(t *testing.T) {
+	cfg := DefaultModelConfig("llama-8b", "hf://meta-llama/Llama-3.1-8B-Instruct")
+	cfg.HFTokenSecret = "hf-secret"
+	cfg.ServiceAccountName = "models"
+	cfg.ImagePullSecrets = []string{"registry"}
+
+	isvc := BuildInferenceService(cfg, "llm-testing")
+
+	env := isvc.Spec.Predictor.Model.Env
+	require.Len(t, env, 1)
+	assert.Equal(t, "HF_TOKEN", env[0].Name)
+	require.NotNil(t, env[0].ValueFrom)
+	require.NotNil(t, env[0].ValueFrom.SecretKeyRef)
+	assert.Equal(t, "hf-secret", env[0].ValueFrom.SecretKeyRef.Name)
+	assert.Equal(t, DefaultHFTokenSecretKey, env[0].ValueFrom.SecretKeyRef.Key)
+
+	assert.Equal(t, "models", isvc.Spec.Predictor.ServiceAccountName)
+	assert.Equal(t, []corev1.LocalObjectReference{{Name: "registry"}}, isvc.Spec.Predictor.ImagePullSecrets)
+
+	cfg.HFTokenSecretKey = "token"
+	isvc = BuildInferenceService(cfg, "llm-testing")
+	assert.Equal(t, "token", isvc.Spec.Predictor.Model.Env[0].ValueFrom.SecretKeyRef.Key)
+}
+
+func TestBuildInferenceServiceFromPVC(t *testing.T) {
+	cfg := DefaultModelConfig("llama-8b", "pvc://models/meta-llama/Llama-3.1-8B-Instruct")
+
+	isvc := BuildInferenceService(cfg, "llm-testing")
+	require.NotNil(t, isvc.Spec.Predictor.Model.StorageURI)
+	assert.Equal(t, "pvc://models/meta-llama/Llama-3.1-8B-Instruct", *isvc.Spec.Predictor.Model.StorageURI)
+	assert.Nil(t, isvc.Spec.Predictor.Model.Storage)
+}
+
+func TestBuildInferenceServiceWithStorageKey(t *testing.T) {
+	cfg := DefaultModelConfig("llama-8b", "s3://models/meta-llama/Llama-3.1-8B-Instruct")
+	cfg.StorageKey = "minio"
+
+	isvc := BuildInferenceService(cfg, "llm-testing")
+	assert.Nil(t, isvc.Spec.Predictor.Model.StorageURI)
+	assert.Equal(t, &StorageSpec{
+		Key:        "minio",
+		Path:       "meta-llama/Llama-3.1-8B-Instruct",
+		Parameters: map[string]string{"bucket": "models"},
+	}, isvc.Spec.Predictor.Model.Storage)
+
+	obj, err := toUnstructured(isvc)
+	require.NoError(t, err)
+	model := obj.Object["spec"].(map[string]interface{})["predictor"].(map[string]interface{})["model"].(map[string]interface{})
+	assert.NotContains(t, model, "storageUri")
+	assert.Contains(t, model, "storage")
+}
+
+func TestBuildInferenceServiceWithScaling(t *testing.T) {
+	cfg := DefaultModelConfig("m", "hf://org/model")
+	isvc := BuildInferenceService(cfg, "llm-testing")
+	assert.Nil(t, isvc.Spec.Predictor.MinReplicas)
+
+	zero := 0
+	cfg.MinReplicas = &zero
+	cfg.MaxReplicas = 2
+	cfg.ScaleTarget = 4
+	cfg.ScaleMetric = "concurrency"
+
+	isvc = BuildInferenceService(cfg, "llm-testing")
+	obj, err := toUnstructured(isvc)
+	require.NoError(t, err)
+	predictor := obj.Object["spec"].(map[string]interface{})["predictor"].(map[string]interface{})
+	assert.EqualValues(t, 0, predictor["minReplicas"])
+	assert.EqualValues(t, 2, predictor["maxReplicas"])
+	assert.EqualValues(t, 4, predictor["scaleTarget"])
+	assert.Equal(t, "concurrency", predictor["scaleMetric"])
+}
+
+func TestBuildInferenceServiceMultiNode(t *testing.T) {
+	cfg := DefaultModelConfig("llama-405b", "pvc://models/llama-405b")
+	cfg.TensorParallelSize = 8
+	cfg.PipelineParallelSize = 2
+	require.NoError(t, cfg.Validate())
+	assert.Equal(t, 16, cfg.TotalGPUs())
+
+	isvc := BuildInferenceService(cfg, "llm-testing")
+	assert.Equal(t, "RawDeployment", isvc.Annotations[deploymentModeAnnotation])
+	model := isvc.Spec.Predictor.Model
+	assert.Equal(t, "huggingface", model.ModelFormat.Name)
+	assert.Equal(t, DefaultMultiNodeRuntime, *model.Runtime)
+	gpus := model.Resources.Limits[gpuResource]
+	assert.Equal(t, "8", gpus.String())
+
+	ws := isvc.Spec.Predictor.WorkerSpec
+	require.NotNil(t, ws)
+	assert.Equal(t, 2, *ws.PipelineParallelSize)
+	assert.Equal(t, 8, *ws.TensorParallelSize)
+	require.Len(t, ws.Containers, 1)
+	workerGPUs := ws.Containers[0].Resources.Limits[gpuResource]
+	assert.Equal(t, "8", workerGPUs.String())
+
+	// A worker count is equivalent to the pipeline parallel size.
+	cfg.PipelineParallelSize = 0
+	cfg.WorkerCount = 1
+	assert.Equal(t, 2, *BuildInferenceService(cfg, "llm-testing").Spec.Predictor.WorkerSpec.PipelineParallelSize)
+}
+
+func TestBuildInferenceServiceTensorParallel(t *testing.T) {
+	cfg := DefaultModelConfig("llama-70b", "hf://meta-llama/Llama-3.1-70B-Instruct")
+	cfg.TensorParallelSize = 4
+
+	isvc := BuildInferenceService(cfg, "llm-testing")
+	assert.Nil(t, isvc.Spec.Predictor.WorkerSpec)
+	assert.Equal(t, []string{"--tensor-parallel-size=4"}, isvc.Spec.Predictor.Model.Args)
+	gpus := isvc.Spec.Predictor.Model.Resources.Limits[gpuResource]
+	assert.Equal(t, "4", gpus.String())
+}
+
+func TestBuildInferenceServiceQuantization(t *testing.T) {
+	cfg := DefaultModelConfig("m", "hf://org/model-awq")
+	cfg.Quantization = "awq"
+	cfg.RuntimeArgs = []string{"--max-model-len=4096"}
+	require.NoError(t, cfg.Validate())
+
+	isvc := BuildInferenceService(cfg, "llm-testing")
+	assert.Equal(t, []string{"--max-model-len=4096", "--quantization=awq", "--dtype=half"}, isvc.Spec.Predictor.Model.Args)
+	assert.Equal(t, []string{"--max-model-len=4096"}, cfg.RuntimeArgs)
+
+	// Flags set explicitly take precedence over the preset.
+	cfg.Quantization = "bitsandbytes"
+	cfg.RuntimeArgs = []string{"--load-format", "auto"}
+	isvc = BuildInferenceService(cfg, "llm-testing")
+	assert.Equal(t, []string{"--load-format", "auto", "--quantization=bitsandbytes"}, isvc.Spec.Predictor.Model.Args)
+}
+
+func TestBuildInferenceServiceRawDeploymentWithEnv(t *testing.T) {
+	cfg := DefaultModelConfig("m", "hf://org/model")
+	isvc := BuildInferenceService(cfg, "llm-testing")
+	assert.Empty(t, isvc.Annotations[deploymentModeAnnotation])
+	assert.Empty(t, isvc.Spec.Predictor.Model.Env)
+
+	cfg.RawDeployment = true
+	cfg.Env = map[string]string{"VLLM_ATTENTION_BACKEND": "FLASHINFER", "VLLM_LOGGING_LEVEL": "DEBUG"}
+	cfg.HFTokenSecret = "hf-token"
+	require.NoError(t, cfg.Validate())
+
+	isvc = BuildInferenceService(cfg, "llm-testing")
+	assert.Equal(t, "RawDeployment", isvc.Annotations[deploymentModeAnnotation])
+	env := isvc.Spec.Predictor.Model.Env
+	require.Len(t, env, 3)
+	assert.Equal(t, corev1.EnvVar{Name: "VLLM_ATTENTION_BACKEND", Value: "FLASHINFER"}, env[0])
+	assert.Equal(t, corev1.EnvVar{Name: "VLLM_LOGGING_LEVEL", Value: "DEBUG"}, env[1])
+	assert.Equal(t, "HF_TOKEN", env[2].Name)
+}
+
+func TestModelConfigValidate(t *testing.T) {
+	cfg := DefaultModelConfig("m", "hf://org/model")
+	assert.NoError(t, cfg.Validate())
+
+	cfg.MemoryLimit = "lots"
+	assert.ErrorContains(t, cfg.Validate(), `invalid memory limit "lots"`)
+
+	cfg = DefaultModelConfig("m", "gs://bucket/model")
+	assert.NoError(t, cfg.Validate())
+
+	cfg = DefaultModelConfig("m", "org/model")
+	assert.ErrorContains(t, cfg.Validate(), "missing scheme")
+
+	cfg = DefaultModelConfig("m", "pvc://models/org/model")
+	cfg.StorageKey = "minio"
+	assert.ErrorContains(t, cfg.Validate(), "only supported for s3://")
+
+	cfg = DefaultModelConfig("m", "hf://org/model")
+	three := 3
+	cfg.MinReplicas, cfg.MaxReplicas = &three, 2
+	assert.ErrorContains(t, cfg.Validate(), "must not exceed max replicas")
+
+	cfg = DefaultModelConfig("m", "hf://org/model")
+	cfg.ScaleMetric = "gpu"
+	assert.ErrorContains(t, cfg.Validate(), `invalid scale metric "gpu"`)
+
+	cfg = DefaultModelConfig("m", "hf://org/model")
+	cfg.PipelineParallelSize, cfg.WorkerCount = 3, 1
+	assert.ErrorContains(t, cfg.Validate(), "must be worker count (1) + 1")
+
+	cfg = DefaultModelConfig("m", "hf://org/model")
+	cfg.WorkerCount, cfg.MaxReplicas = 1, 2
+	assert.ErrorContains(t, cfg.Validate(), "autoscaling is not supported for multi-node")
+
+	cfg = DefaultModelConfig("m", "hf://org/model")
+	cfg.Env = map[string]string{"1BAD": "x"}
+	assert.ErrorContains(t, cfg.Validate(), `invalid environment variable name "1BAD"`)
+
+	cfg = DefaultModelConfig("m", "hf://org/model")
+	cfg.Env, cfg.HFTokenSecret = map[string]string{"HF_TOKEN": "x"}, "hf-token"
+	assert.ErrorContains(t, cfg.Validate(), "conflicts with the HuggingFace token Secret")
+
+	cfg = DefaultModelConfig("m", "hf://org/model")
+	zero := 0
+	cfg.RawDeployment, cfg.MinReplicas = true, &zero
+	assert.ErrorContains(t, cfg.Validate(), "not supported in raw deployment mode")
+
+	cfg = DefaultModelConfig("m", "hf://org/model")
+	cfg.Quantization = "int4"
+	assert.ErrorContains(t, cfg.Validate(), `unsupported quantization "int4" (expected one of awq, bitsandbytes, fp8, gptq)`)
+}
+
 func TestBuildInferenceServiceNoRuntime(t *testing.T) {
 	cfg := ModelConfig{
 		Name:     "test-model",
@@ -142,6 +409,15 @@ func TestSanitizeName(t *testing.T) {
 	}
 }
 
+func TestRevisionName(t *testing.T) {
+	assert.Equal(t, "llama-3-8b-awq", RevisionName("llama-3-8b", "awq"))
+	assert.Equal(t, "llama-3-8b-max-len-8k", RevisionName("Llama_3_8B", "max_len.8k"))
+
+	long := RevisionName(strings.Repeat("a", 70), "baseline")
+	assert.Len(t, long, 63)
+	assert.True(t, strings.HasSuffix(long, "-baseline"))
+}
+
 func TestEndpointURL(t *testing.T) {
 	url := EndpointURL("mistral-7b", "llm-testing")
 	assert.Equal(t, "http://mistral-7b.llm-testing.svc.cluster.local/v1", url)
@@ -153,4 +429,5 @@ func TestDefaultModelConfig(t *testing.T) {
 	assert.Equal(t, "hf://org/model", cfg.ModelURI)
 	assert.Equal(t, "kserve-vllm", cfg.Runtime)
 	assert.Equal(t, 1, cfg.GPUCount)
+	assert.Equal(t, DefaultShmSize, cfg.ShmSize)
 }
@@ -6,14 +6,18 @@ import (
 	"log/slog"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/giantswarm/llm-testing/internal/tracing"
 )
 
 var isvcGVR = schema.GroupVersionResource{
@@ -22,10 +26,45 @@ var isvcGVR = schema.GroupVersionResource{
 	Resource: "inferenceservices",
 }
 
+// specHashAnnotation records a hash of the spec Deploy applied, so that a
+// redeploy can tell whether the desired spec changed. Comparing specs
+// directly does not work, as KServe fills in defaults.
+const specHashAnnotation = "llm-testing.giantswarm.io/spec-hash"
+
+// Deploy actions reported in ModelStatus.Action.
+const (
+	ActionCreated   = "created"
+	ActionUpdated   = "updated"
+	ActionUnchanged = "unchanged"
+	ActionRecreated = "recreated"
+)
+
+// deletePollInterval and deleteTimeout bound waiting for an InferenceService
+// to be deleted before it is recreated.
+var (
+	deletePollInterval = 2 * time.Second
+	deleteTimeout      = 5 * time.Minute
+)
+
+// readyPollInterval and readyPollMaxInterval bound the backoff of polling
+// for readiness when watching is not allowed; watchRetryDelay is the pause
+// before re-establishing a closed watch.
+var (
+	readyPollInterval    = 2 * time.Second
+	readyPollMaxInterval = 15 * time.Second
+	watchRetryDelay      = time.Second
+)
+
 // Manager handles KServe InferenceService lifecycle.
 type Manager struct {
 	client    dynamic.Interface
+	kube      kubernetes.Interface // optional: pod logs and events for diagnostics
 	namespace string
+	tracked   *deploymentTracker // shared with namespaced copies
+	ttl       time.Duration      // stamped as expiresAtAnnotation when positive
+	probe     ProbeFunc
+	config    *rest.Config  // optional: port-forwarding to predictor pods
+	forwards  *portForwards // shared with namespaced copies
 }
 
 // NewManager creates a new KServe manager.
@@ -53,9 +92,19 @@ func NewManager(namespace string, kubeconfig string, inCluster bool) (*Manager,
 		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
 	}
 
+	kube, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
 	return &Manager{
 		client:    client,
+		kube:      kube,
 		namespace: namespace,
+		tracked:   newDeploymentTracker(),
+		probe:     probeOpenAI,
+		config:    config,
+		forwards:  newPortForwards(),
 	}, nil
 }
 
@@ -64,7 +113,41 @@ func NewManagerWithClient(client dynamic.Interface, namespace string) *Manager {
 	return &Manager{
 		client:    client,
 		namespace: namespace,
+		tracked:   newDeploymentTracker(),
+		probe:     probeOpenAI,
+		forwards:  newPortForwards(),
+	}
+}
+
+// Namespace returns the namespace the Manager operates in.
+func (m *Manager) Namespace() string {
+	return m.namespace
+}
+
+// WithNamespace returns a Manager sharing m's clients that operates in
+// namespace, or m itself when namespace is empty or unchanged.
+func (m *Manager) WithNamespace(namespace string) *Manager {
+	if namespace == "" || namespace == m.namespace {
+		return m
+	}
+	c := *m
+	c.namespace = namespace
+	return &c
+}
+
+// accessError explains a Forbidden error, which usually means the service
+// account has no Role in a namespace other than its default one.
+func (m *Manager) accessError(err error, verb, resource string) error {
+	if !apierrors.IsForbidden(err) {
+		return err
 	}
+	return fmt.Errorf("%w (llm-testing is not allowed to %s %s in namespace %q; grant its service account a Role there, e.g. via the Helm chart's kserve.extraNamespaces)",
+		err, verb, resource, m.namespace)
+}
+
+// SetKubeClient sets the typed client used to collect diagnostics (for testing).
+func (m *Manager) SetKubeClient(kube kubernetes.Interface) {
+	m.kube = kube
 }
 
 // CheckCRDAvailable verifies that the InferenceService CRD is installed in the cluster.
@@ -80,48 +163,245 @@ func (m *Manager) CheckCRDAvailable(ctx context.Context) error {
 }
 
 // Deploy creates an InferenceService and waits for it to become ready.
+// An existing InferenceService of the same name is reused when its spec is
+// unchanged and updated when it differs, or deleted and created anew when
+// cfg.Recreate is set. The returned status reports which of these happened.
 func (m *Manager) Deploy(ctx context.Context, cfg ModelConfig) (*ModelStatus, error) {
+	ctx, span := tracing.Start(ctx, "kserve.deploy",
+		tracing.Model.String(cfg.Name),
+		tracing.Namespace.String(m.namespace),
+	)
+	status, err := m.deploy(ctx, cfg)
+	if status != nil {
+		span.SetAttributes(attribute.String("llm_testing.deploy_action", status.Action))
+	}
+	tracing.End(span, err)
+	return status, err
+}
+
+// deploy executes Deploy in the span of the deployment.
+func (m *Manager) deploy(ctx context.Context, cfg ModelConfig) (*ModelStatus, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
 	isvc := BuildInferenceService(cfg, m.namespace)
 	name := isvc.Name
 
+	hash, err := specHash(isvc)
+	if err != nil {
+		return nil, err
+	}
+	isvc.Annotations = mergeStrings(isvc.Annotations, map[string]string{specHashAnnotation: hash})
+	if m.ttl > 0 {
+		expiresAt := time.Now().Add(m.ttl).UTC().Format(time.RFC3339)
+		isvc.Annotations[expiresAtAnnotation] = expiresAt
+	}
+
 	obj, err := toUnstructured(isvc)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert InferenceService: %w", err)
 	}
 
+	capacityWarning, err := m.checkCapacity(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.ensureRuntime(ctx, cfg); err != nil {
+		return nil, err
+	}
+
 	slog.Info("deploying InferenceService",
 		"name", name,
 		"model_uri", cfg.ModelURI,
-		"gpu_count", cfg.GPUCount,
+		"gpu_count", cfg.NodeGPUs(),
+		"nodes", cfg.Nodes(),
 	)
 
-	// Create the InferenceService.
-	created, err := m.client.Resource(isvcGVR).Namespace(m.namespace).Create(
-		ctx, obj, metav1.CreateOptions{},
-	)
+	applied, action, err := m.apply(ctx, obj, cfg.Recreate)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create InferenceService %s: %w", name, err)
+		return nil, err
+	}
+	// An ephemeral deployment belongs to its run only if the run created it.
+	// One that existed before, e.g. from deploy_model, is used but not tracked,
+	// so that the run leaves it in place.
+	if cfg.Ephemeral && action == ActionCreated {
+		m.tracked.add(m.namespace, name)
+	}
+	status := &ModelStatus{
+		Name:        name,
+		Ready:       true,
+		EndpointURL: endpointURL(isvc, m.namespace),
+		CreatedAt:   applied.GetCreationTimestamp().Format(time.RFC3339),
+		Action:      action,
+		SpecHash:    hash,
+
+		CapacityWarning: capacityWarning,
 	}
 
-	slog.Info("InferenceService created, waiting for ready",
+	if action == ActionUnchanged {
+		if current, err := fromUnstructured(applied); err == nil && isReadyAndCurrent(current) {
+			slog.Info("InferenceService unchanged and ready", "name", name)
+			return m.serving(ctx, status, cfg, newProgressReporter(name, cfg.Progress))
+		}
+	}
+
+	slog.Info("waiting for InferenceService to become ready",
 		"name", name,
+		"action", action,
 	)
 
-	// Wait for ready.
-	if err := m.waitForReady(ctx, name, cfg.ReadyTimeout); err != nil {
-		return nil, fmt.Errorf("InferenceService %s not ready: %w", name, err)
+	// Wait for ready, then for the endpoint to answer.
+	progress := newProgressReporter(name, cfg.Progress)
+	readyCtx, readySpan := tracing.Start(ctx, "kserve.wait_ready", tracing.Model.String(name))
+	err = m.waitForReady(readyCtx, name, cfg.ReadyTimeout, progress)
+	tracing.End(readySpan, err)
+	if err != nil {
+		return nil, m.deployError(ctx, name, err)
 	}
+	return m.serving(ctx, status, cfg, progress)
+}
 
-	return &ModelStatus{
-		Name:        name,
-		Ready:       true,
-		EndpointURL: endpointURL(isvc, m.namespace),
-		CreatedAt:   created.GetCreationTimestamp().Format(time.RFC3339),
-	}, nil
+// serving resolves the endpoint of a ready InferenceService in cfg.URLMode
+// and waits for it to answer.
+func (m *Manager) serving(ctx context.Context, status *ModelStatus, cfg ModelConfig, progress *progressReporter) (*ModelStatus, error) {
+	endpoint, err := m.ModelEndpoint(ctx, status.Name, cfg.URLMode)
+	if err != nil {
+		return nil, m.deployError(ctx, status.Name, err)
+	}
+	status.EndpointURL = endpoint
+
+	endpointCtx, endpointSpan := tracing.Start(ctx, "kserve.wait_endpoint", tracing.Model.String(status.Name))
+	err = m.waitForEndpoint(endpointCtx, endpoint, cfg, progress)
+	tracing.End(endpointSpan, err)
+	if err != nil {
+		return nil, m.deployError(ctx, status.Name, err)
+	}
+	return status, nil
+}
+
+// apply creates the InferenceService or reconciles an existing one of the
+// same name. Only InferenceServices managed by llm-testing are modified.
+func (m *Manager) apply(ctx context.Context, obj *unstructured.Unstructured, recreate bool) (*unstructured.Unstructured, string, error) {
+	res := m.client.Resource(isvcGVR).Namespace(m.namespace)
+	name := obj.GetName()
+
+	existing, err := res.Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		created, err := res.Create(ctx, obj, metav1.CreateOptions{})
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to create InferenceService %s: %w", name, m.accessError(err, "create", "inferenceservices"))
+		}
+		return created, ActionCreated, nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get InferenceService %s: %w", name, m.accessError(err, "get", "inferenceservices"))
+	}
+	if existing.GetLabels()["app.kubernetes.io/managed-by"] != managedBy {
+		return nil, "", fmt.Errorf("InferenceService %s already exists and is not managed by %s", name, managedBy)
+	}
+
+	// KServe cannot change the deployment mode of an InferenceService.
+	modeChanged := existing.GetAnnotations()[deploymentModeAnnotation] != obj.GetAnnotations()[deploymentModeAnnotation]
+
+	switch {
+	case recreate || modeChanged:
+		if modeChanged {
+			slog.Info("recreating InferenceService to change its deployment mode", "name", name,
+				"from", existing.GetAnnotations()[deploymentModeAnnotation], "to", obj.GetAnnotations()[deploymentModeAnnotation])
+		}
+		if err := m.Teardown(ctx, name); err != nil {
+			return nil, "", err
+		}
+		if err := m.waitForDeletion(ctx, name); err != nil {
+			return nil, "", err
+		}
+		created, err := res.Create(ctx, obj, metav1.CreateOptions{})
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to recreate InferenceService %s: %w", name, m.accessError(err, "create", "inferenceservices"))
+		}
+		return created, ActionRecreated, nil
+
+	case existing.GetAnnotations()[specHashAnnotation] == obj.GetAnnotations()[specHashAnnotation]:
+		// Deploying again extends the lifetime of an expiring one.
+		if expiresAt, ok := obj.GetAnnotations()[expiresAtAnnotation]; ok {
+			existing, err = m.extendExpiry(ctx, existing, expiresAt)
+			if err != nil {
+				return nil, "", err
+			}
+		}
+		return existing, ActionUnchanged, nil
+
+	default:
+		existing.Object["spec"] = obj.Object["spec"]
+		existing.SetLabels(mergeStrings(existing.GetLabels(), obj.GetLabels()))
+		annotations := mergeStrings(existing.GetAnnotations(), obj.GetAnnotations())
+		// The deployment mode is set by the spec alone, e.g. RawDeployment
+		// only while multi-node or raw_deployment asks for it.
+		if _, ok := obj.GetAnnotations()[deploymentModeAnnotation]; !ok {
+			delete(annotations, deploymentModeAnnotation)
+		}
+		existing.SetAnnotations(annotations)
+		updated, err := res.Update(ctx, existing, metav1.UpdateOptions{})
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to update InferenceService %s: %w", name, m.accessError(err, "update", "inferenceservices"))
+		}
+		return updated, ActionUpdated, nil
+	}
+}
+
+// waitForDeletion polls until the InferenceService is gone, which with
+// foreground deletion happens only after its dependents are removed.
+func (m *Manager) waitForDeletion(ctx context.Context, name string) error {
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(deletePollInterval)
+	defer ticker.Stop()
+
+	for {
+		_, err := m.client.Resource(isvcGVR).Namespace(m.namespace).Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timeout waiting for InferenceService %s to be deleted", name)
+		case <-ticker.C:
+		}
+	}
+}
+
+// deployError wraps a readiness failure with diagnostics from the predictor pods.
+func (m *Manager) deployError(ctx context.Context, name string, err error) error {
+	deployErr := &DeployError{Name: name, Err: err}
+	if m.kube == nil {
+		return deployErr
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), diagnosticTimeout)
+	defer cancel()
+	diagnostics, diagErr := m.CollectDiagnostics(ctx, name)
+	if diagErr != nil {
+		slog.Warn("failed to collect deployment diagnostics", "name", name, "error", diagErr)
+		return deployErr
+	}
+	deployErr.Diagnostics = diagnostics
+	return deployErr
 }
 
 // Teardown deletes an InferenceService with graceful shutdown.
 func (m *Manager) Teardown(ctx context.Context, name string) error {
+	ctx, span := tracing.Start(ctx, "kserve.teardown",
+		tracing.Model.String(name),
+		tracing.Namespace.String(m.namespace),
+	)
+	err := m.teardown(ctx, name)
+	tracing.End(span, err)
+	return err
+}
+
+// teardown executes Teardown in the span of the teardown.
+func (m *Manager) teardown(ctx context.Context, name string) error {
 	sanitized := sanitizeName(name)
 	slog.Info("tearing down InferenceService", "name", sanitized)
 
@@ -134,13 +414,12 @@ func (m *Manager) Teardown(ctx context.Context, name string) error {
 			PropagationPolicy:  &propagation,
 		},
 	)
-	if err != nil {
-		if apierrors.IsNotFound(err) {
-			return nil
-		}
-		return fmt.Errorf("failed to delete InferenceService %s: %w", sanitized, err)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete InferenceService %s: %w", sanitized, m.accessError(err, "delete", "inferenceservices"))
 	}
 
+	m.tracked.remove(m.namespace, sanitized)
+	m.forwards.stop(m.namespace, sanitized)
 	return nil
 }
 
@@ -150,7 +429,7 @@ func (m *Manager) List(ctx context.Context) ([]ModelStatus, error) {
 		LabelSelector: "app.kubernetes.io/managed-by=" + managedBy,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to list InferenceServices: %w", err)
+		return nil, fmt.Errorf("failed to list InferenceServices: %w", m.accessError(err, "list", "inferenceservices"))
 	}
 
 	statuses := make([]ModelStatus, 0, len(list.Items))
@@ -173,7 +452,7 @@ func (m *Manager) Get(ctx context.Context, name string) (*ModelStatus, error) {
 		ctx, sanitized, metav1.GetOptions{},
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get InferenceService %s: %w", sanitized, err)
+		return nil, fmt.Errorf("failed to get InferenceService %s: %w", sanitized, m.accessError(err, "get", "inferenceservices"))
 	}
 
 	isvc, err := fromUnstructured(item)
@@ -190,6 +469,10 @@ func (m *Manager) statusFromISVC(isvc *InferenceService) ModelStatus {
 	status := ModelStatus{
 		Name:      isvc.Name,
 		CreatedAt: isvc.CreationTimestamp.Format(time.RFC3339),
+		SpecHash:  isvc.Annotations[specHashAnnotation],
+	}
+	if status.SpecHash == "" { // not deployed by Deploy
+		status.SpecHash, _ = specHash(isvc)
 	}
 
 	if isvc.Status.IsReady() {
@@ -202,62 +485,131 @@ func (m *Manager) statusFromISVC(isvc *InferenceService) ModelStatus {
 	return status
 }
 
-func (m *Manager) waitForReady(ctx context.Context, name string, timeout time.Duration) error {
+// waitForReady watches the InferenceService until it is ready, reporting its
+// conditions and, periodically, the state of its predictor pods. A closed
+// watch is re-established; when watching is not allowed, it polls instead.
+func (m *Manager) waitForReady(ctx context.Context, name string, timeout time.Duration, progress *progressReporter) error {
 	if timeout <= 0 {
 		timeout = 10 * time.Minute
 	}
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	watcher, err := m.client.Resource(isvcGVR).Namespace(m.namespace).Watch(ctx, metav1.ListOptions{
-		FieldSelector: "metadata.name=" + name,
-	})
-	if err != nil {
-		return fmt.Errorf("failed to watch InferenceService: %w", err)
-	}
-	defer watcher.Stop()
+	ticker := time.NewTicker(podPollInterval)
+	defer ticker.Stop()
+
+	var (
+		watcher  watch.Interface
+		pollC    <-chan time.Time
+		interval = readyPollInterval
+	)
+	defer func() {
+		if watcher != nil {
+			watcher.Stop()
+		}
+	}()
 
 	for {
+		var events <-chan watch.Event
+		if watcher == nil && pollC == nil {
+			w, err := m.client.Resource(isvcGVR).Namespace(m.namespace).Watch(ctx, metav1.ListOptions{
+				FieldSelector: "metadata.name=" + name,
+			})
+			switch {
+			case apierrors.IsForbidden(err) || apierrors.IsMethodNotSupported(err):
+				slog.Info("watching InferenceServices is not allowed, polling instead", "name", name, "error", err)
+				pollC = time.After(0)
+			case err != nil:
+				return fmt.Errorf("failed to watch InferenceService: %w", m.accessError(err, "watch", "inferenceservices"))
+			default:
+				watcher = w
+			}
+		}
+		if watcher != nil {
+			events = watcher.ResultChan()
+		}
+
 		select {
 		case <-ctx.Done():
-			return fmt.Errorf("timeout waiting for InferenceService %s to become ready", name)
-		case event, ok := <-watcher.ResultChan():
-			if !ok {
-				return fmt.Errorf("watch channel closed for InferenceService %s", name)
+			return &readyTimeoutError{name: name, last: progress.last}
+		case <-ticker.C:
+			progress.report(m.podProgress(ctx, name))
+		case <-pollC:
+			item, err := m.client.Resource(isvcGVR).Namespace(m.namespace).Get(ctx, name, metav1.GetOptions{})
+			if apierrors.IsForbidden(err) {
+				return fmt.Errorf("failed to get InferenceService %s: %w", name, m.accessError(err, "get", "inferenceservices"))
+			}
+			if err == nil && checkReady(item, progress) {
+				return nil
+			}
+			if err != nil {
+				slog.Debug("failed to poll InferenceService", "name", name, "error", err)
+			}
+			pollC = time.After(interval)
+			interval = min(interval*2, readyPollMaxInterval)
+		case event, ok := <-events:
+			if !ok || event.Type == watch.Error {
+				// The API server closes watches after a while; watch anew.
+				slog.Debug("watch of InferenceService ended, reconnecting", "name", name)
+				watcher.Stop()
+				watcher = nil
+				select {
+				case <-ctx.Done():
+				case <-time.After(watchRetryDelay):
+				}
+				continue
 			}
-
 			if event.Type == watch.Modified || event.Type == watch.Added {
 				obj, ok := event.Object.(*unstructured.Unstructured)
-				if !ok {
-					continue
-				}
-
-				isvc, err := fromUnstructured(obj)
-				if err != nil {
-					slog.Warn("failed to convert watch event", "error", err)
-					continue
-				}
-
-				if isvc.Status.IsReady() {
-					slog.Info("InferenceService ready", "name", name)
+				if ok && checkReady(obj, progress) {
 					return nil
 				}
-
-				if cond := isvc.Status.GetReadyCondition(); cond != nil && cond.Status == "False" {
-					slog.Debug("InferenceService not ready yet",
-						"name", name,
-						"reason", cond.Reason,
-						"message", cond.Message,
-					)
-				}
 			}
 		}
 	}
 }
 
+// readyTimeoutError reports that an InferenceService did not become ready in time.
+type readyTimeoutError struct {
+	name string
+	last string // last reported status
+}
+
+func (e *readyTimeoutError) Error() string {
+	if e.last != "" {
+		return fmt.Sprintf("timeout waiting for InferenceService %s to become ready (last status: %s)", e.name, e.last)
+	}
+	return fmt.Sprintf("timeout waiting for InferenceService %s to become ready", e.name)
+}
+
+// checkReady reports whether an observed InferenceService is ready and
+// current, and reports why it is not ready otherwise.
+func checkReady(obj *unstructured.Unstructured, progress *progressReporter) bool {
+	isvc, err := fromUnstructured(obj)
+	if err != nil {
+		slog.Warn("failed to convert InferenceService", "error", err)
+		return false
+	}
+
+	if isReadyAndCurrent(isvc) {
+		slog.Info("InferenceService ready", "name", isvc.Name)
+		return true
+	}
+
+	if cond := isvc.Status.GetReadyCondition(); cond != nil && cond.Status == "False" {
+		slog.Debug("InferenceService not ready yet",
+			"name", isvc.Name,
+			"reason", cond.Reason,
+			"message", cond.Message,
+		)
+		progress.report(cond.Reason, cond.Message)
+	}
+	return false
+}
+
 func endpointURL(isvc *InferenceService, namespace string) string {
 	if isvc.Status.URL != "" {
-		return isvc.Status.URL
+		return openAIBaseURL(isvc.Status.URL)
 	}
 	return EndpointURL(isvc.Name, namespace)
 }
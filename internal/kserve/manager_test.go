@@ -2,15 +2,18 @@ package kserve
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
 	dynamicfake "k8s.io/client-go/dynamic/fake"
 	k8stesting "k8s.io/client-go/testing"
 )
@@ -20,11 +23,18 @@ func newFakeManager(t *testing.T, objects ...runtime.Object) *Manager {
 	scheme := runtime.NewScheme()
 	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme,
 		map[schema.GroupVersionResource]string{
-			isvcGVR: "InferenceServiceList",
+			isvcGVR:                  "InferenceServiceList",
+			servingRuntimeGVR:        "ServingRuntimeList",
+			clusterServingRuntimeGVR: "ClusterServingRuntimeList",
+			podGVR:                   "PodList",
+			nodeGVR:                  "NodeList",
+			deploymentGVR:            "DeploymentList",
 		},
 		objects...,
 	)
-	return NewManagerWithClient(client, "test-namespace")
+	m := NewManagerWithClient(client, "test-namespace")
+	m.SetProbeFunc(func(context.Context, string, bool) error { return nil })
+	return m
 }
 
 func makeISVC(name, namespace string, ready bool) *unstructured.Unstructured {
@@ -195,6 +205,276 @@ func TestManagerDeploy(t *testing.T) {
 	assert.True(t, createFound, "create action should have been called")
 }
 
+// deployedISVC returns the object Deploy would have created for cfg, with
+// the given Ready condition.
+func deployedISVC(t *testing.T, cfg ModelConfig, ready bool) *unstructured.Unstructured {
+	t.Helper()
+	isvc := BuildInferenceService(cfg, "test-namespace")
+	hash, err := specHash(isvc)
+	require.NoError(t, err)
+	isvc.Annotations = mergeStrings(isvc.Annotations, map[string]string{specHashAnnotation: hash})
+
+	obj, err := toUnstructured(isvc)
+	require.NoError(t, err)
+	obj.Object["status"] = makeISVC(cfg.Name, "test-namespace", ready).Object["status"]
+	return obj
+}
+
+// readyOnCreate makes the InferenceServices created through m ready at
+// once, and has Deploy poll for readiness, since the fake client does not
+// send watch events for them.
+func readyOnCreate(t *testing.T, m *Manager) {
+	t.Helper()
+	fastReadyPolling(t)
+	fake := m.client.(*dynamicfake.FakeDynamicClient)
+	fake.PrependReactor("create", "inferenceservices", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		obj := action.(k8stesting.CreateAction).GetObject().(*unstructured.Unstructured)
+		obj.Object["status"] = makeISVC(obj.GetName(), obj.GetNamespace(), true).Object["status"]
+		return false, nil, nil
+	})
+	fake.PrependWatchReactor("inferenceservices", func(k8stesting.Action) (bool, watch.Interface, error) {
+		return true, nil, apierrors.NewForbidden(isvcGVR.GroupResource(), "", errors.New("watch not allowed"))
+	})
+}
+
+// deployActions returns the verbs of the InferenceService writes made through m.
+func deployActions(m *Manager) []string {
+	var verbs []string
+	for _, a := range m.client.(*dynamicfake.FakeDynamicClient).Actions() {
+		if a.GetResource() == isvcGVR && a.GetVerb() != "get" && a.GetVerb() != "list" && a.GetVerb() != "watch" {
+			verbs = append(verbs, a.GetVerb())
+		}
+	}
+	return verbs
+}
+
+func TestManagerDeployCreatesCPURuntime(t *testing.T) {
+	m := newFakeManager(t)
+	readyOnCreate(t, m)
+
+	cfg := DefaultModelConfig("qwen", "hf://Qwen/Qwen2.5-0.5B-Instruct-GGUF")
+	cfg.UseBackend(BackendLlamaCPP)
+	cfg.ReadyTimeout = time.Second
+	_, err := m.Deploy(context.Background(), cfg)
+	require.NoError(t, err)
+
+	sr, err := m.client.Resource(servingRuntimeGVR).Namespace("test-namespace").Get(context.Background(), DefaultLlamaCPPRuntime, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, managedBy, sr.GetLabels()["app.kubernetes.io/managed-by"])
+
+	// The runtime is created once; custom runtimes are never created.
+	cfg.Name = "qwen-2"
+	_, err = m.Deploy(context.Background(), cfg)
+	require.NoError(t, err)
+	cfg.Name, cfg.Runtime = "qwen-3", "llamacpp-cuda"
+	_, err = m.Deploy(context.Background(), cfg)
+	require.NoError(t, err)
+
+	var creates int
+	for _, a := range m.client.(*dynamicfake.FakeDynamicClient).Actions() {
+		if a.GetResource() == servingRuntimeGVR && a.GetVerb() == "create" {
+			creates++
+		}
+	}
+	assert.Equal(t, 1, creates)
+}
+
+func TestManagerDeployUnchanged(t *testing.T) {
+	cfg := DefaultModelConfig("existing", "hf://org/model")
+	cfg.ReadyTimeout = 100 * time.Millisecond
+	m := newFakeManager(t, deployedISVC(t, cfg, true))
+
+	status, err := m.Deploy(context.Background(), cfg)
+	require.NoError(t, err)
+	assert.Equal(t, ActionUnchanged, status.Action)
+	assert.True(t, status.Ready)
+	assert.Equal(t, "http://existing.test-namespace.example.com/v1", status.EndpointURL)
+	assert.Empty(t, deployActions(m))
+
+	got, err := m.Get(context.Background(), "existing")
+	require.NoError(t, err)
+	assert.NotEmpty(t, status.SpecHash)
+	assert.Equal(t, status.SpecHash, got.SpecHash)
+}
+
+func TestManagerDeployUpdatesChangedSpec(t *testing.T) {
+	cfg := DefaultModelConfig("existing", "hf://org/model")
+	m := newFakeManager(t, deployedISVC(t, cfg, true))
+
+	cfg.GPUCount = 2
+	cfg.ReadyTimeout = 100 * time.Millisecond
+	_, err := m.Deploy(context.Background(), cfg)
+	// The fake client sends no watch events for the new revision.
+	require.Error(t, err)
+	assert.Equal(t, []string{"update"}, deployActions(m))
+
+	obj, err := m.client.Resource(isvcGVR).Namespace("test-namespace").Get(context.Background(), "existing", metav1.GetOptions{})
+	require.NoError(t, err)
+	isvc, err := fromUnstructured(obj)
+	require.NoError(t, err)
+	gpus := isvc.Spec.Predictor.Model.Resources.Limits["nvidia.com/gpu"]
+	assert.Equal(t, "2", gpus.String())
+	wantHash, err := specHash(BuildInferenceService(cfg, "test-namespace"))
+	require.NoError(t, err)
+	assert.Equal(t, wantHash, obj.GetAnnotations()[specHashAnnotation])
+}
+
+func TestManagerDeployRecreate(t *testing.T) {
+	cfg := DefaultModelConfig("existing", "hf://org/model")
+	m := newFakeManager(t, deployedISVC(t, cfg, true))
+
+	cfg.Recreate = true
+	cfg.ReadyTimeout = 100 * time.Millisecond
+	_, err := m.Deploy(context.Background(), cfg)
+	require.Error(t, err)
+	assert.Equal(t, []string{"delete", "create"}, deployActions(m))
+}
+
+func TestManagerDeployRecreatesForDeploymentMode(t *testing.T) {
+	cfg := DefaultModelConfig("existing", "hf://org/model")
+	m := newFakeManager(t, deployedISVC(t, cfg, true))
+
+	cfg.RawDeployment = true
+	cfg.ReadyTimeout = 100 * time.Millisecond
+	_, err := m.Deploy(context.Background(), cfg)
+	require.Error(t, err)
+	assert.Equal(t, []string{"delete", "create"}, deployActions(m))
+
+	obj, err := m.client.Resource(isvcGVR).Namespace("test-namespace").Get(context.Background(), "existing", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "RawDeployment", obj.GetAnnotations()[deploymentModeAnnotation])
+}
+
+func TestManagerDeployDropsRawDeploymentOfMultiNode(t *testing.T) {
+	cfg := DefaultModelConfig("existing", "hf://org/model")
+	cfg.PipelineParallelSize = 2
+	m := newFakeManager(t, deployedISVC(t, cfg, true))
+
+	cfg.PipelineParallelSize = 0
+	cfg.ReadyTimeout = 100 * time.Millisecond
+	_, _ = m.Deploy(context.Background(), cfg)
+
+	obj, err := m.client.Resource(isvcGVR).Namespace("test-namespace").Get(context.Background(), "existing", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, obj.GetAnnotations(), deploymentModeAnnotation)
+
+	// raw_deployment keeps the mode of the single-node model.
+	cfg.PipelineParallelSize, cfg.RawDeployment = 2, true
+	m = newFakeManager(t, deployedISVC(t, cfg, true))
+	cfg.PipelineParallelSize = 0
+	_, _ = m.Deploy(context.Background(), cfg)
+	assert.Equal(t, []string{"update"}, deployActions(m))
+	obj, err = m.client.Resource(isvcGVR).Namespace("test-namespace").Get(context.Background(), "existing", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "RawDeployment", obj.GetAnnotations()[deploymentModeAnnotation])
+}
+
+func TestManagerDeployRefusesUnmanaged(t *testing.T) {
+	existing := makeISVC("foreign", "test-namespace", true)
+	existing.SetLabels(nil)
+	m := newFakeManager(t, existing)
+
+	_, err := m.Deploy(context.Background(), DefaultModelConfig("foreign", "hf://org/model"))
+	assert.ErrorContains(t, err, "not managed by llm-testing")
+	assert.Empty(t, deployActions(m))
+}
+
+func fastReadyPolling(t *testing.T) {
+	t.Helper()
+	poll, maxPoll, retry := readyPollInterval, readyPollMaxInterval, watchRetryDelay
+	readyPollInterval, readyPollMaxInterval, watchRetryDelay = 10*time.Millisecond, 20*time.Millisecond, 10*time.Millisecond
+	t.Cleanup(func() { readyPollInterval, readyPollMaxInterval, watchRetryDelay = poll, maxPoll, retry })
+}
+
+func TestWaitForReadyPollsWhenWatchForbidden(t *testing.T) {
+	fastReadyPolling(t)
+	isvc := makeISVC("poll-test", "test-namespace", false)
+	m := newFakeManager(t, isvc)
+	fake := m.client.(*dynamicfake.FakeDynamicClient)
+	fake.PrependWatchReactor("inferenceservices", func(k8stesting.Action) (bool, watch.Interface, error) {
+		return true, nil, apierrors.NewForbidden(isvcGVR.GroupResource(), "", errors.New("watch not allowed"))
+	})
+
+	gets := 0
+	fake.PrependReactor("get", "inferenceservices", func(k8stesting.Action) (bool, runtime.Object, error) {
+		if gets++; gets < 3 {
+			return true, makeISVC("poll-test", "test-namespace", false), nil
+		}
+		return true, makeISVC("poll-test", "test-namespace", true), nil
+	})
+
+	err := m.waitForReady(context.Background(), "poll-test", time.Second, newProgressReporter("poll-test", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 3, gets)
+}
+
+func TestWaitForReadyReconnectsClosedWatch(t *testing.T) {
+	fastReadyPolling(t)
+	m := newFakeManager(t, makeISVC("watch-test", "test-namespace", false))
+
+	watches := 0
+	m.client.(*dynamicfake.FakeDynamicClient).PrependWatchReactor("inferenceservices", func(k8stesting.Action) (bool, watch.Interface, error) {
+		watches++
+		w := watch.NewFakeWithChanSize(1, false)
+		if watches == 1 {
+			w.Stop() // closed by the API server
+		} else {
+			w.Modify(makeISVC("watch-test", "test-namespace", true))
+		}
+		return true, w, nil
+	})
+
+	err := m.waitForReady(context.Background(), "watch-test", time.Second, newProgressReporter("watch-test", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 2, watches)
+}
+
+func TestIsReadyAndCurrent(t *testing.T) {
+	isvc := &InferenceService{Status: InferenceServiceStatus{Conditions: []StatusCondition{{Type: "Ready", Status: "True"}}}}
+	assert.True(t, isReadyAndCurrent(isvc))
+
+	isvc.Generation = 2
+	isvc.Status.ObservedGeneration = 1
+	assert.False(t, isReadyAndCurrent(isvc))
+
+	isvc.Status.ObservedGeneration = 2
+	assert.True(t, isReadyAndCurrent(isvc))
+}
+
+func TestManagerWithNamespace(t *testing.T) {
+	m := newFakeManager(t,
+		makeISVC("default-model", "test-namespace", true),
+		makeISVC("team-model", "team-a", true),
+	)
+	assert.Same(t, m, m.WithNamespace(""))
+	assert.Same(t, m, m.WithNamespace("test-namespace"))
+
+	team := m.WithNamespace("team-a")
+	assert.Equal(t, "team-a", team.Namespace())
+	assert.Equal(t, "test-namespace", m.Namespace())
+
+	statuses, err := team.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	assert.Equal(t, "team-model", statuses[0].Name)
+
+	_, err = m.Get(context.Background(), "team-model")
+	assert.Error(t, err)
+}
+
+func TestManagerForbiddenError(t *testing.T) {
+	m := newFakeManager(t)
+	m.client.(*dynamicfake.FakeDynamicClient).PrependReactor("list", "inferenceservices",
+		func(k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewForbidden(isvcGVR.GroupResource(), "", errors.New("no access"))
+		})
+
+	_, err := m.WithNamespace("team-a").List(context.Background())
+	require.Error(t, err)
+	assert.True(t, apierrors.IsForbidden(err))
+	assert.Contains(t, err.Error(), `not allowed to list inferenceservices in namespace "team-a"`)
+}
+
 func TestManagerCheckCRDAvailable(t *testing.T) {
 	m := newFakeManager(t)
 
@@ -0,0 +1,43 @@
+package kserve
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// RenderManifest returns the InferenceService BuildInferenceService creates
+// for cfg in namespace as "yaml" or "json", for review or check-in to a
+// GitOps repository. It does not contact the cluster, so the annotations
+// Deploy adds (spec hash, expiry) are not included.
+func RenderManifest(cfg ModelConfig, namespace, format string) ([]byte, error) {
+	if format != "yaml" && format != "json" {
+		return nil, fmt.Errorf("unsupported output format %q (expected yaml or json)", format)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	obj, err := toUnstructured(BuildInferenceService(cfg, namespace))
+	if err != nil {
+		return nil, err
+	}
+	// Drop the fields that only the API server sets.
+	unstructured.RemoveNestedField(obj.Object, "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(obj.Object, "status")
+
+	if format == "json" {
+		data, err := json.MarshalIndent(obj.Object, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal InferenceService: %w", err)
+		}
+		return append(data, '\n'), nil
+	}
+	data, err := yaml.Marshal(obj.Object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal InferenceService: %w", err)
+	}
+	return data, nil
+}
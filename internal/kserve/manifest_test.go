@@ -0,0 +1,46 @@
+package kserve
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+func TestRenderManifestYAML(t *testing.T) {
+	cfg := DefaultModelConfig("mistral-7b", "hf://mistralai/Mistral-7B-Instruct-v0.3")
+	cfg.RuntimeArgs = []string{"--max-model-len=4096"}
+
+	data, err := RenderManifest(cfg, "llm-testing", "yaml")
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "creationTimestamp")
+	assert.NotContains(t, string(data), "status:")
+
+	var isvc InferenceService
+	require.NoError(t, yaml.UnmarshalStrict(data, &isvc))
+	assert.Equal(t, BuildInferenceService(cfg, "llm-testing"), &isvc)
+}
+
+func TestRenderManifestJSON(t *testing.T) {
+	cfg := DefaultModelConfig("mistral-7b", "hf://mistralai/Mistral-7B-Instruct-v0.3")
+
+	data, err := RenderManifest(cfg, "llm-testing", "json")
+	require.NoError(t, err)
+
+	var obj map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &obj))
+	assert.Equal(t, "InferenceService", obj["kind"])
+	assert.Equal(t, "llm-testing", obj["metadata"].(map[string]interface{})["namespace"])
+}
+
+func TestRenderManifestErrors(t *testing.T) {
+	cfg := DefaultModelConfig("m", "hf://org/model")
+	_, err := RenderManifest(cfg, "llm-testing", "toml")
+	assert.ErrorContains(t, err, `unsupported output format "toml"`)
+
+	cfg.Quantization = "int4"
+	_, err = RenderManifest(cfg, "llm-testing", "yaml")
+	assert.ErrorContains(t, err, "unsupported quantization")
+}
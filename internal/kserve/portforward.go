@@ -0,0 +1,224 @@
+package kserve
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+// defaultModelPort is the port of KServe's model server container.
+const defaultModelPort = 8080
+
+// portForwards holds the active port-forwards to predictor pods, keyed by
+// "namespace/name" of the InferenceService.
+type portForwards struct {
+	mu     sync.Mutex
+	active map[string]*activeForward
+}
+
+type activeForward struct {
+	localPort uint16
+	stopCh    chan struct{}
+	done      chan struct{}
+}
+
+func newPortForwards() *portForwards {
+	return &portForwards{active: map[string]*activeForward{}}
+}
+
+func (p *portForwards) get(namespace, name string) *activeForward {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	f := p.active[namespace+"/"+name]
+	if f == nil {
+		return nil
+	}
+	select {
+	case <-f.done:
+		delete(p.active, namespace+"/"+name)
+		return nil
+	default:
+		return f
+	}
+}
+
+func (p *portForwards) put(namespace, name string, f *activeForward) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.active[namespace+"/"+name] = f
+}
+
+func (p *portForwards) stop(namespace, name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if f := p.active[namespace+"/"+name]; f != nil {
+		close(f.stopCh)
+		delete(p.active, namespace+"/"+name)
+	}
+}
+
+// StopPortForwards stops all port-forwards started for port-forward URLs.
+func (m *Manager) StopPortForwards() {
+	m.forwards.mu.Lock()
+	defer m.forwards.mu.Unlock()
+	for key, f := range m.forwards.active {
+		close(f.stopCh)
+		delete(m.forwards.active, key)
+	}
+}
+
+// portForward returns the base URL of a local port forwarded to a ready pod
+// behind the predictor Service of the InferenceService, reusing an active
+// forward. A forward whose pod went away is replaced on the next call.
+func (m *Manager) portForward(ctx context.Context, name string) (string, error) {
+	if f := m.forwards.get(m.namespace, name); f != nil {
+		return fmt.Sprintf("http://127.0.0.1:%d/v1", f.localPort), nil
+	}
+	if m.config == nil || m.kube == nil {
+		return "", fmt.Errorf("port-forwarding to InferenceService %s requires a Kubernetes client configuration", name)
+	}
+
+	pods, targetPort, err := m.predictorService(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	pod, port, err := forwardTarget(pods, targetPort)
+	if err != nil {
+		return "", fmt.Errorf("failed to port-forward to InferenceService %s: %w", name, err)
+	}
+
+	transport, upgrader, err := spdy.RoundTripperFor(m.config)
+	if err != nil {
+		return "", fmt.Errorf("failed to create port-forward transport: %w", err)
+	}
+	url := m.kube.CoreV1().RESTClient().Post().
+		Resource("pods").Namespace(m.namespace).Name(pod.Name).SubResource("portforward").URL()
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, url)
+
+	f := &activeForward{stopCh: make(chan struct{}), done: make(chan struct{})}
+	readyCh := make(chan struct{})
+	fw, err := portforward.NewOnAddresses(dialer, []string{"127.0.0.1"}, []string{fmt.Sprintf("0:%d", port)},
+		f.stopCh, readyCh, io.Discard, io.Discard)
+	if err != nil {
+		return "", fmt.Errorf("failed to port-forward to pod %s: %w", pod.Name, err)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		defer close(f.done)
+		if err := fw.ForwardPorts(); err != nil {
+			slog.Warn("port-forward stopped", "name", name, "pod", pod.Name, "error", err)
+			errCh <- err
+		}
+	}()
+
+	select {
+	case <-readyCh:
+	case err := <-errCh:
+		return "", fmt.Errorf("failed to port-forward to pod %s: %w", pod.Name, m.accessError(err, "create", "pods/portforward"))
+	case <-ctx.Done():
+		close(f.stopCh)
+		return "", ctx.Err()
+	}
+
+	ports, err := fw.GetPorts()
+	if err != nil || len(ports) == 0 {
+		close(f.stopCh)
+		return "", fmt.Errorf("failed to determine the local port forwarded to pod %s: %w", pod.Name, err)
+	}
+	f.localPort = ports[0].Local
+	m.forwards.put(m.namespace, name, f)
+
+	slog.Info("forwarding local port to predictor pod", "name", name, "pod", pod.Name, "local_port", f.localPort, "port", port)
+	return fmt.Sprintf("http://127.0.0.1:%d/v1", f.localPort), nil
+}
+
+// predictorService returns the pods behind the predictor Service of an
+// InferenceService and the Service's target port. Without the Service, e.g.
+// while Knative has not created it, it falls back to the predictor pods.
+func (m *Manager) predictorService(ctx context.Context, name string) ([]corev1.Pod, intstr.IntOrString, error) {
+	svc, err := m.kube.CoreV1().Services(m.namespace).Get(ctx, name+"-predictor", metav1.GetOptions{})
+	if err != nil || len(svc.Spec.Selector) == 0 {
+		if err != nil && !apierrors.IsNotFound(err) {
+			slog.Debug("failed to get predictor service", "name", name, "error", err)
+		}
+		pods, err := m.predictorPods(ctx, name)
+		if err != nil {
+			return nil, intstr.IntOrString{}, m.accessError(err, "list", "pods")
+		}
+		return pods, intstr.IntOrString{}, nil
+	}
+
+	list, err := m.kube.CoreV1().Pods(m.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(svc.Spec.Selector).String(),
+	})
+	if err != nil {
+		return nil, intstr.IntOrString{}, fmt.Errorf("failed to list predictor pods: %w", m.accessError(err, "list", "pods"))
+	}
+	var targetPort intstr.IntOrString
+	if len(svc.Spec.Ports) > 0 {
+		targetPort = svc.Spec.Ports[0].TargetPort
+	}
+	return list.Items, targetPort, nil
+}
+
+// forwardTarget picks a running, ready predictor pod that is not a
+// multi-node worker, and resolves the Service target port on it. Without a
+// target port, the port of the model server container is used.
+func forwardTarget(pods []corev1.Pod, targetPort intstr.IntOrString) (corev1.Pod, int32, error) {
+	for _, pod := range pods {
+		if pod.Status.Phase != corev1.PodRunning || !podReady(pod) {
+			continue
+		}
+		if c := pod.Labels["component"]; c != "" && c != "predictor" {
+			continue
+		}
+		if port, ok := resolvePort(pod, targetPort); ok {
+			return pod, port, nil
+		}
+		return corev1.Pod{}, 0, fmt.Errorf("pod %s has no port %s", pod.Name, targetPort.String())
+	}
+	return corev1.Pod{}, 0, fmt.Errorf("no ready predictor pod found")
+}
+
+// resolvePort resolves a numeric or named target port on a pod.
+func resolvePort(pod corev1.Pod, targetPort intstr.IntOrString) (int32, bool) {
+	if targetPort.Type == intstr.Int && targetPort.IntVal > 0 {
+		return targetPort.IntVal, true
+	}
+	for _, c := range pod.Spec.Containers {
+		for _, p := range c.Ports {
+			if targetPort.Type == intstr.String && targetPort.StrVal != "" {
+				if p.Name == targetPort.StrVal {
+					return p.ContainerPort, true
+				}
+			} else if c.Name == "kserve-container" {
+				return p.ContainerPort, true
+			}
+		}
+	}
+	if targetPort.Type == intstr.String && targetPort.StrVal != "" {
+		return 0, false
+	}
+	return defaultModelPort, true
+}
+
+func podReady(pod corev1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package kserve
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultProbeTimeout is how long Deploy waits for a ready model's endpoint
+// to answer.
+const DefaultProbeTimeout = 2 * time.Minute
+
+// probeInterval is how often the endpoint is probed until it answers.
+var probeInterval = 5 * time.Second
+
+// ProbeFunc checks once whether the OpenAI-compatible API at endpoint (the
+// model's base URL) answers. With completion set, it also requests a tiny
+// completion.
+type ProbeFunc func(ctx context.Context, endpoint string, completion bool) error
+
+// SetProbeFunc replaces the endpoint probe (for testing).
+func (m *Manager) SetProbeFunc(fn ProbeFunc) {
+	m.probe = fn
+}
+
+// waitForEndpoint probes the endpoint of a ready InferenceService until it
+// answers. KServe reports Ready once the predictor passes its readiness
+// probe, which does not guarantee that the OpenAI API serves requests yet.
+func (m *Manager) waitForEndpoint(ctx context.Context, endpoint string, cfg ModelConfig, progress *progressReporter) error {
+	if cfg.ProbeTimeout <= 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, cfg.ProbeTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(probeInterval)
+	defer ticker.Stop()
+
+	for {
+		err := m.probe(ctx, endpoint, cfg.ProbeCompletion)
+		if err == nil {
+			slog.Info("model endpoint answers", "name", progress.name, "endpoint", endpoint)
+			return nil
+		}
+		progress.report("probing endpoint", err.Error())
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timeout waiting for endpoint %s to answer: %w", endpoint, err)
+		case <-ticker.C:
+		}
+	}
+}
+
+// probeOpenAI lists the served models and, with completion set, requests a
+// one-token completion from the first of them.
+func probeOpenAI(ctx context.Context, endpoint string, completion bool) error {
+	base := openAIBaseURL(endpoint)
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	var models struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := probeRequest(ctx, client, http.MethodGet, base+"/models", nil, &models); err != nil {
+		return err
+	}
+	if len(models.Data) == 0 {
+		return fmt.Errorf("%s/models lists no models", base)
+	}
+	if !completion {
+		return nil
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"model":      models.Data[0].ID,
+		"prompt":     "Hello",
+		"max_tokens": 1,
+	})
+	if err != nil {
+		return err
+	}
+	return probeRequest(ctx, client, http.MethodPost, base+"/completions", body, nil)
+}
+
+// probeRequest sends a request and decodes a successful JSON response into out, if set.
+func probeRequest(ctx context.Context, client *http.Client, method, url string, body []byte, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create probe request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s failed: %w", method, url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s returned status %d: %s", method, url, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to parse response of %s %s: %w", method, url, err)
+	}
+	return nil
+}
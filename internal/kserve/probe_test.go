@@ -0,0 +1,96 @@
+package kserve
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProbeOpenAI(t *testing.T) {
+	var completion map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/models":
+			_, _ = w.Write([]byte(`{"data":[{"id":"served-model"}]}`))
+		case "/v1/completions":
+			_ = json.NewDecoder(r.Body).Decode(&completion)
+			_, _ = w.Write([]byte(`{"choices":[{"text":"!"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	require.NoError(t, probeOpenAI(context.Background(), srv.URL, false))
+	assert.Nil(t, completion)
+
+	require.NoError(t, probeOpenAI(context.Background(), srv.URL+"/v1/", true))
+	assert.Equal(t, "served-model", completion["model"])
+	assert.EqualValues(t, 1, completion["max_tokens"])
+}
+
+func TestProbeOpenAIErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/models" {
+			_, _ = w.Write([]byte(`{"data":[]}`))
+			return
+		}
+		http.Error(w, "model loading", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	assert.ErrorContains(t, probeOpenAI(context.Background(), srv.URL, false), "lists no models")
+	assert.ErrorContains(t, probeOpenAI(context.Background(), srv.URL+"/other", false), "status 503: model loading")
+}
+
+func TestManagerDeployWaitsForEndpoint(t *testing.T) {
+	interval := probeInterval
+	probeInterval = 10 * time.Millisecond
+	t.Cleanup(func() { probeInterval = interval })
+
+	cfg := DefaultModelConfig("existing", "hf://org/model")
+	cfg.ProbeCompletion = true
+	m := newFakeManager(t, deployedISVC(t, cfg, true))
+
+	probes := 0
+	m.SetProbeFunc(func(_ context.Context, endpoint string, completion bool) error {
+		assert.Equal(t, "http://existing.test-namespace.example.com/v1", endpoint)
+		assert.True(t, completion)
+		if probes++; probes < 3 {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+
+	var progress []string
+	cfg.Progress = func(p DeployProgress) { progress = append(progress, p.Status) }
+	status, err := m.Deploy(context.Background(), cfg)
+	require.NoError(t, err)
+	assert.True(t, status.Ready)
+	assert.Equal(t, 3, probes)
+	assert.Equal(t, []string{"probing endpoint"}, progress)
+}
+
+func TestManagerDeployEndpointTimeout(t *testing.T) {
+	interval := probeInterval
+	probeInterval = 10 * time.Millisecond
+	t.Cleanup(func() { probeInterval = interval })
+
+	cfg := DefaultModelConfig("existing", "hf://org/model")
+	cfg.ProbeTimeout = 50 * time.Millisecond
+	m := newFakeManager(t, deployedISVC(t, cfg, true))
+	m.SetProbeFunc(func(context.Context, string, bool) error { return errors.New("connection refused") })
+
+	_, err := m.Deploy(context.Background(), cfg)
+	var deployErr *DeployError
+	require.ErrorAs(t, err, &deployErr)
+	assert.ErrorContains(t, err, "timeout waiting for endpoint")
+	assert.ErrorContains(t, err, "connection refused")
+}
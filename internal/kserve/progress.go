@@ -0,0 +1,140 @@
+package kserve
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var podGVR = schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+
+// podPollInterval is how often predictor pods are inspected while waiting
+// for an InferenceService to become ready.
+var podPollInterval = 10 * time.Second
+
+// storageInitializer is the init container in which KServe downloads the model.
+const storageInitializer = "storage-initializer"
+
+// DeployProgress is an intermediate status of a deployment.
+type DeployProgress struct {
+	Name    string        `json:"name"`
+	Elapsed time.Duration `json:"elapsed"`
+
+	// Status is a short state such as "Pending", "downloading model",
+	// "loading model", or a container reason like "ImagePullBackOff".
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// String formats the progress for logs and notifications.
+func (p DeployProgress) String() string {
+	s := fmt.Sprintf("%s: %s (%s)", p.Name, p.Status, p.Elapsed.Round(time.Second))
+	if p.Message != "" {
+		s += ": " + p.Message
+	}
+	return s
+}
+
+// ProgressFunc receives deployment progress updates.
+type ProgressFunc func(DeployProgress)
+
+// progressReporter forwards changed statuses to the callback and the log.
+type progressReporter struct {
+	name    string
+	start   time.Time
+	fn      ProgressFunc
+	last    string
+	lastMsg string
+}
+
+func newProgressReporter(name string, fn ProgressFunc) *progressReporter {
+	return &progressReporter{name: name, start: time.Now(), fn: fn}
+}
+
+func (r *progressReporter) report(status, message string) {
+	if status == "" || (status == r.last && message == r.lastMsg) {
+		return
+	}
+	r.last, r.lastMsg = status, message
+
+	p := DeployProgress{Name: r.name, Elapsed: time.Since(r.start), Status: status, Message: message}
+	slog.Info("InferenceService progress", "name", r.name, "status", status, "message", message, "elapsed", p.Elapsed.Round(time.Second).String())
+	if r.fn != nil {
+		r.fn(p)
+	}
+}
+
+// podProgress inspects the predictor pods of an InferenceService.
+// Failures to list pods (e.g. missing RBAC) are logged and yield no status.
+func (m *Manager) podProgress(ctx context.Context, name string) (string, string) {
+	pods, err := m.predictorPods(ctx, name)
+	if err != nil {
+		slog.Debug("failed to list predictor pods", "name", name, "error", err)
+		return "", ""
+	}
+	return summarizePods(pods)
+}
+
+// summarizePods derives a status from the newest predictor pod. Container
+// problems (image pulls, crashes, OOM kills) take precedence over the phase.
+func summarizePods(pods []corev1.Pod) (string, string) {
+	if len(pods) == 0 {
+		return "waiting for predictor pod", ""
+	}
+	pod := pods[0]
+	for _, p := range pods[1:] {
+		if p.CreationTimestamp.After(pod.CreationTimestamp.Time) {
+			pod = p
+		}
+	}
+
+	for _, cs := range pod.Status.InitContainerStatuses {
+		switch {
+		case cs.State.Waiting != nil && cs.State.Waiting.Reason != "" && cs.State.Waiting.Reason != "PodInitializing":
+			return cs.State.Waiting.Reason, containerMessage(cs.Name, cs.State.Waiting.Message, cs.LastTerminationState)
+		case cs.State.Terminated != nil && cs.State.Terminated.ExitCode != 0:
+			return "init container failed", containerMessage(cs.Name, cs.State.Terminated.Reason+" "+cs.State.Terminated.Message, corev1.ContainerState{})
+		case cs.State.Running != nil:
+			if cs.Name == storageInitializer {
+				return "downloading model", ""
+			}
+			return "initializing", cs.Name
+		}
+	}
+
+	for _, cs := range pod.Status.ContainerStatuses {
+		switch {
+		case cs.State.Waiting != nil && cs.State.Waiting.Reason != "":
+			return cs.State.Waiting.Reason, containerMessage(cs.Name, cs.State.Waiting.Message, cs.LastTerminationState)
+		case cs.State.Terminated != nil:
+			return cs.State.Terminated.Reason, containerMessage(cs.Name, cs.State.Terminated.Message, corev1.ContainerState{})
+		case cs.State.Running != nil && !cs.Ready:
+			return "loading model", containerMessage(cs.Name, "", cs.LastTerminationState)
+		}
+	}
+
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodScheduled && c.Status == corev1.ConditionFalse {
+			return "Unschedulable", c.Message
+		}
+	}
+	return string(pod.Status.Phase), ""
+}
+
+// containerMessage combines a container's message with the reason it last
+// terminated, which reveals OOM kills behind a CrashLoopBackOff.
+func containerMessage(container, message string, last corev1.ContainerState) string {
+	parts := []string{"container " + container}
+	if m := strings.TrimSpace(message); m != "" {
+		parts = append(parts, m)
+	}
+	if last.Terminated != nil && last.Terminated.Reason != "" {
+		parts = append(parts, "last terminated: "+last.Terminated.Reason)
+	}
+	return strings.Join(parts, ": ")
+}
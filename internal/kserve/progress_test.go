@@ -0,0 +1,143 @@
+package kserve
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestSummarizePods(t *testing.T) {
+	tests := []struct {
+		name    string
+		pods    []corev1.Pod
+		status  string
+		message string
+	}{
+		{
+			name:   "no pods",
+			status: "waiting for predictor pod",
+		},
+		{
+			name: "downloading",
+			pods: []corev1.Pod{{Status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				InitContainerStatuses: []corev1.ContainerStatus{{
+					Name:  storageInitializer,
+					State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+				}},
+			}}},
+			status: "downloading model",
+		},
+		{
+			name: "image pull failure",
+			pods: []corev1.Pod{{Status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:  "kserve-container",
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "Back-off pulling image"}},
+				}},
+			}}},
+			status:  "ImagePullBackOff",
+			message: "container kserve-container: Back-off pulling image",
+		},
+		{
+			name: "OOM crash loop",
+			pods: []corev1.Pod{{Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:                 "kserve-container",
+					State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+					LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137}},
+				}},
+			}}},
+			status:  "CrashLoopBackOff",
+			message: "container kserve-container: last terminated: OOMKilled",
+		},
+		{
+			name: "loading",
+			pods: []corev1.Pod{{Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:  "kserve-container",
+					State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+				}},
+			}}},
+			status:  "loading model",
+			message: "container kserve-container",
+		},
+		{
+			name: "unschedulable",
+			pods: []corev1.Pod{{Status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				Conditions: []corev1.PodCondition{{
+					Type: corev1.PodScheduled, Status: corev1.ConditionFalse,
+					Message: "0/3 nodes are available: 3 Insufficient nvidia.com/gpu.",
+				}},
+			}}},
+			status:  "Unschedulable",
+			message: "0/3 nodes are available: 3 Insufficient nvidia.com/gpu.",
+		},
+		{
+			name: "newest pod wins",
+			pods: []corev1.Pod{
+				{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(time.Unix(100, 0))}, Status: corev1.PodStatus{Phase: corev1.PodFailed}},
+				{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(time.Unix(200, 0))}, Status: corev1.PodStatus{Phase: corev1.PodPending}},
+			},
+			status: "Pending",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, message := summarizePods(tt.pods)
+			assert.Equal(t, tt.status, status)
+			assert.Equal(t, tt.message, message)
+		})
+	}
+}
+
+func TestDeployReportsPodProgress(t *testing.T) {
+	old := podPollInterval
+	podPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { podPollInterval = old })
+
+	pod := &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "progress-test-predictor-abc",
+			Namespace: "test-namespace",
+			Labels:    map[string]string{"serving.kserve.io/inferenceservice": "progress-test"},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodPending,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "kserve-container",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ErrImagePull"}},
+			}},
+		},
+	}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(pod)
+	require.NoError(t, err)
+	m := newFakeManager(t, &unstructured.Unstructured{Object: obj})
+
+	var updates []DeployProgress
+	cfg := DefaultModelConfig("progress-test", "hf://org/model")
+	cfg.ReadyTimeout = 200 * time.Millisecond
+	cfg.Progress = func(p DeployProgress) { updates = append(updates, p) }
+
+	_, err = m.Deploy(context.Background(), cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "last status: ErrImagePull")
+
+	require.Len(t, updates, 1, "unchanged statuses are reported once")
+	assert.Equal(t, "progress-test", updates[0].Name)
+	assert.Equal(t, "ErrImagePull", updates[0].Status)
+	assert.Contains(t, updates[0].String(), "progress-test: ErrImagePull")
+}
@@ -0,0 +1,57 @@
+package kserve
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// quantizationPreset is the vLLM configuration for a quantization method.
+type quantizationPreset struct {
+	// args are added unless the runtime args already set the same flags.
+	args []string
+
+	// bytesPerParam is the weight size vLLM quantizes unquantized
+	// checkpoints to when loading them; zero for methods that require a
+	// quantized checkpoint, whose size the metadata already reflects.
+	bytesPerParam float64
+}
+
+// quantizationPresets maps the supported ModelConfig.Quantization values to
+// their vLLM configuration. AWQ and GPTQ kernels run in float16.
+var quantizationPresets = map[string]quantizationPreset{
+	"awq":          {args: []string{"--quantization=awq", "--dtype=half"}},
+	"gptq":         {args: []string{"--quantization=gptq", "--dtype=half"}},
+	"fp8":          {args: []string{"--quantization=fp8"}, bytesPerParam: 1},
+	"bitsandbytes": {args: []string{"--quantization=bitsandbytes", "--load-format=bitsandbytes"}, bytesPerParam: 0.5},
+}
+
+// QuantizationMethods returns the supported quantization methods, sorted.
+func QuantizationMethods() []string {
+	methods := make([]string, 0, len(quantizationPresets))
+	for m := range quantizationPresets {
+		methods = append(methods, m)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+func (c ModelConfig) validateQuantization() error {
+	if c.Quantization == "" {
+		return nil
+	}
+	if _, ok := quantizationPresets[c.Quantization]; !ok {
+		return fmt.Errorf("unsupported quantization %q (expected one of %s)", c.Quantization, strings.Join(QuantizationMethods(), ", "))
+	}
+	return nil
+}
+
+// quantizedBytes returns the size of weights after loading them with the
+// quantization method, which only shrinks unquantized checkpoints.
+func quantizedBytes(quantization string, info *WeightInfo) int64 {
+	perParam := quantizationPresets[quantization].bytesPerParam
+	if perParam == 0 || info.Parameters == 0 {
+		return info.Bytes
+	}
+	return min(info.Bytes, int64(float64(info.Parameters)*perParam))
+}
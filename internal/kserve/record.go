@@ -0,0 +1,123 @@
+package kserve
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// gpuProductLabel is set on GPU nodes by NVIDIA GPU feature discovery.
+	gpuProductLabel   = "nvidia.com/gpu.product"
+	instanceTypeLabel = "node.kubernetes.io/instance-type"
+)
+
+// DeploymentRecord describes how a model was served, so that evaluation
+// results can be reproduced and attributed to the serving configuration.
+type DeploymentRecord struct {
+	Name        string               `json:"name"`
+	Namespace   string               `json:"namespace"`
+	Annotations map[string]string    `json:"annotations,omitempty"`
+	Spec        InferenceServiceSpec `json:"spec"`
+	Runtime     *RuntimeInfo         `json:"runtime,omitempty"`
+	Pods        []PodRecord          `json:"pods,omitempty"`
+
+	// Deployment timing, set by the caller of Deploy.
+	Action       string    `json:"action,omitempty"`
+	StartedAt    time.Time `json:"started_at,omitempty"`
+	ReadyAt      time.Time `json:"ready_at,omitempty"`
+	ReadySeconds float64   `json:"ready_seconds,omitempty"`
+}
+
+// PodRecord is a predictor pod of a deployment and the node it ran on.
+type PodRecord struct {
+	Name         string `json:"name"`
+	Node         string `json:"node,omitempty"`
+	GPUProduct   string `json:"gpu_product,omitempty"`
+	InstanceType string `json:"instance_type,omitempty"`
+	Image        string `json:"image,omitempty"`
+	ImageID      string `json:"image_id,omitempty"` // resolved digest
+}
+
+// RecordDeployment returns the spec, serving runtime, and predictor pods of
+// a deployed InferenceService. Only the InferenceService is required; the
+// runtime and the nodes' GPU types are left out if they cannot be read.
+func (m *Manager) RecordDeployment(ctx context.Context, name string) (*DeploymentRecord, error) {
+	sanitized := sanitizeName(name)
+	item, err := m.client.Resource(isvcGVR).Namespace(m.namespace).Get(ctx, sanitized, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get InferenceService %s: %w", sanitized, m.accessError(err, "get", "inferenceservices"))
+	}
+	isvc, err := fromUnstructured(item)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert InferenceService %s: %w", sanitized, err)
+	}
+
+	record := &DeploymentRecord{
+		Name:        isvc.Name,
+		Namespace:   isvc.Namespace,
+		Annotations: isvc.Annotations,
+		Spec:        isvc.Spec,
+	}
+	if model := isvc.Spec.Predictor.Model; model != nil && model.Runtime != nil {
+		record.Runtime = m.runtimeInfo(ctx, *model.Runtime)
+	}
+
+	pods, err := m.predictorPods(ctx, sanitized)
+	if err != nil {
+		slog.Warn("failed to record predictor pods", "name", sanitized, "error", err)
+		return record, nil
+	}
+	nodes := m.nodeLabels(ctx)
+	for _, pod := range pods {
+		p := PodRecord{Name: pod.Name, Node: pod.Spec.NodeName}
+		if labels, ok := nodes[pod.Spec.NodeName]; ok {
+			p.GPUProduct, p.InstanceType = labels[gpuProductLabel], labels[instanceTypeLabel]
+		}
+		for _, c := range pod.Status.ContainerStatuses {
+			if c.Name == "kserve-container" {
+				p.Image, p.ImageID = c.Image, c.ImageID
+			}
+		}
+		record.Pods = append(record.Pods, p)
+	}
+	return record, nil
+}
+
+// runtimeInfo returns the serving runtime with the given name, or nil.
+func (m *Manager) runtimeInfo(ctx context.Context, name string) *RuntimeInfo {
+	runtimes, err := m.ListRuntimes(ctx)
+	if err != nil {
+		slog.Warn("failed to record serving runtime", "runtime", name, "error", err)
+		return nil
+	}
+	for _, rt := range runtimes {
+		if rt.Name == name {
+			return &rt
+		}
+	}
+	return nil
+}
+
+// nodeLabels returns the labels of the cluster's nodes by node name.
+func (m *Manager) nodeLabels(ctx context.Context) map[string]map[string]string {
+	list, err := m.client.Resource(nodeGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		slog.Warn("failed to record node GPU types", "error", err)
+		return nil
+	}
+	labels := make(map[string]map[string]string, len(list.Items))
+	for _, item := range list.Items {
+		var node corev1.Node
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &node); err != nil {
+			continue
+		}
+		labels[node.Name] = node.Labels
+	}
+	return labels
+}
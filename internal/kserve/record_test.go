@@ -0,0 +1,56 @@
+package kserve
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRecordDeployment(t *testing.T) {
+	cfg := DefaultModelConfig("record-test", "hf://org/model")
+	cfg.Runtime = "vllm-next"
+
+	pod := predictorPod("record-test")
+	pod.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"}
+	pod.Spec.NodeName = "gpu-1"
+	pod.Status.ContainerStatuses[0].Image = "vllm/vllm-openai:v0.8.0"
+	pod.Status.ContainerStatuses[0].ImageID = "docker.io/vllm/vllm-openai@sha256:abc"
+
+	m := newFakeManager(t,
+		deployedISVC(t, cfg, true),
+		toObject(t, pod),
+		toObject(t, gpuNode("gpu-1", "8", map[string]string{
+			gpuProductLabel:   "NVIDIA-H100-80GB-HBM3",
+			instanceTypeLabel: "p5.48xlarge",
+		})),
+		makeRuntime(KindServingRuntime, "vllm-next", "test-namespace", "vllm/vllm-openai:v0.8.0", false),
+	)
+
+	record, err := m.RecordDeployment(context.Background(), "record-test")
+	require.NoError(t, err)
+
+	assert.Equal(t, "record-test", record.Name)
+	assert.Equal(t, "test-namespace", record.Namespace)
+	assert.Equal(t, BuildInferenceService(cfg, "test-namespace").Spec, record.Spec)
+	assert.Contains(t, record.Annotations, specHashAnnotation)
+	require.NotNil(t, record.Runtime)
+	assert.Equal(t, "vllm/vllm-openai:v0.8.0", record.Runtime.Image)
+	require.Len(t, record.Pods, 1)
+	assert.Equal(t, PodRecord{
+		Name:         "record-test-predictor-abc",
+		Node:         "gpu-1",
+		GPUProduct:   "NVIDIA-H100-80GB-HBM3",
+		InstanceType: "p5.48xlarge",
+		Image:        "vllm/vllm-openai:v0.8.0",
+		ImageID:      "docker.io/vllm/vllm-openai@sha256:abc",
+	}, record.Pods[0])
+}
+
+func TestRecordDeploymentNotFound(t *testing.T) {
+	m := newFakeManager(t)
+	_, err := m.RecordDeployment(context.Background(), "missing")
+	assert.ErrorContains(t, err, "failed to get InferenceService missing")
+}
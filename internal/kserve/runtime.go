@@ -0,0 +1,266 @@
+package kserve
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	runtimeAPIVersion = "serving.kserve.io/v1alpha1"
+
+	// KindServingRuntime is a namespaced serving runtime.
+	KindServingRuntime = "ServingRuntime"
+	// KindClusterServingRuntime is a cluster-wide serving runtime.
+	KindClusterServingRuntime = "ClusterServingRuntime"
+
+	// DefaultVLLMImage is the vLLM OpenAI-compatible server image used for
+	// custom runtimes.
+	DefaultVLLMImage = "vllm/vllm-openai"
+)
+
+var (
+	servingRuntimeGVR = schema.GroupVersionResource{
+		Group:    "serving.kserve.io",
+		Version:  "v1alpha1",
+		Resource: "servingruntimes",
+	}
+	clusterServingRuntimeGVR = schema.GroupVersionResource{
+		Group:    "serving.kserve.io",
+		Version:  "v1alpha1",
+		Resource: "clusterservingruntimes",
+	}
+)
+
+// RuntimeInfo summarizes a serving runtime available in the cluster.
+type RuntimeInfo struct {
+	Name         string   `json:"name"`
+	Kind         string   `json:"kind"`
+	Namespace    string   `json:"namespace,omitempty"`
+	ModelFormats []string `json:"model_formats,omitempty"`
+	Image        string   `json:"image,omitempty"`
+	Disabled     bool     `json:"disabled,omitempty"`
+	Managed      bool     `json:"managed"` // created by llm-testing
+}
+
+// RuntimeTemplate describes a custom ServingRuntime.
+type RuntimeTemplate struct {
+	// Name is the ServingRuntime name, referenced by ModelConfig.Runtime.
+	Name string
+
+	// Backend is the model server the runtime runs (default: BackendVLLM).
+	Backend Backend
+
+	// Image is the image repository (default: DefaultVLLMImage,
+	// DefaultOllamaImage, or DefaultLlamaCPPImage for the backend).
+	Image string
+
+	// ImageTag is the image tag (e.g. "v0.6.3"). It is required for vLLM;
+	// the CPU backends default to their latest server image.
+	ImageTag string
+
+	// Args are additional server arguments applied to every model served
+	// by the runtime.
+	Args []string
+}
+
+// BuildServingRuntime creates a ServingRuntime running the OpenAI-compatible
+// server of the template's backend. KServe's storage initializer downloads
+// the model to /mnt/models, and the model is served under the
+// InferenceService name.
+func BuildServingRuntime(t RuntimeTemplate, namespace string) (*ServingRuntime, error) {
+	if strings.TrimSpace(t.Name) == "" {
+		return nil, fmt.Errorf("runtime name is required")
+	}
+	backend, err := ParseBackend(string(t.Backend))
+	if err != nil {
+		return nil, err
+	}
+	p := backend.profile()
+	tag := t.ImageTag
+	if strings.TrimSpace(tag) == "" {
+		if p.imageTag == "" {
+			return nil, fmt.Errorf("vLLM image tag is required")
+		}
+		tag = p.imageTag
+	}
+	if len(t.Args) > 0 && !p.runtimeArgs {
+		return nil, fmt.Errorf("the %s backend takes no server arguments", backend)
+	}
+	image := t.Image
+	if image == "" {
+		image = p.image
+	}
+	command, args, env := p.container(t.Args)
+
+	autoSelect := false
+	return &ServingRuntime{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: runtimeAPIVersion,
+			Kind:       KindServingRuntime,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      sanitizeName(t.Name),
+			Namespace: namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": managedBy,
+			},
+		},
+		Spec: ServingRuntimeSpec{
+			SupportedModelFormats: []SupportedModelFormat{
+				{Name: p.modelFormat, AutoSelect: &autoSelect},
+			},
+			Containers: []corev1.Container{
+				{
+					Name:    "kserve-container",
+					Image:   image + ":" + tag,
+					Command: command,
+					Args:    args,
+					Env:     env,
+					Ports: []corev1.ContainerPort{
+						{ContainerPort: 8080, Protocol: corev1.ProtocolTCP},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+// ListRuntimes returns the ServingRuntimes in the manager's namespace and the
+// ClusterServingRuntimes. Runtimes of a kind that cannot be listed (e.g. missing
+// RBAC for cluster-scoped resources) are skipped with a warning; an error is
+// returned only if neither kind can be listed.
+func (m *Manager) ListRuntimes(ctx context.Context) ([]RuntimeInfo, error) {
+	var runtimes []RuntimeInfo
+
+	namespaced, nsErr := m.client.Resource(servingRuntimeGVR).Namespace(m.namespace).List(ctx, metav1.ListOptions{})
+	if nsErr != nil {
+		slog.Warn("failed to list ServingRuntimes", "namespace", m.namespace, "error", nsErr)
+	} else {
+		runtimes = append(runtimes, runtimeInfos(namespaced.Items, KindServingRuntime)...)
+	}
+
+	cluster, clusterErr := m.client.Resource(clusterServingRuntimeGVR).List(ctx, metav1.ListOptions{})
+	if clusterErr != nil {
+		slog.Warn("failed to list ClusterServingRuntimes", "error", clusterErr)
+	} else {
+		runtimes = append(runtimes, runtimeInfos(cluster.Items, KindClusterServingRuntime)...)
+	}
+
+	if nsErr != nil && clusterErr != nil {
+		return nil, fmt.Errorf("failed to list serving runtimes: %w", nsErr)
+	}
+	return runtimes, nil
+}
+
+// CheckRuntime returns an error if no enabled runtime with the given name is
+// available to InferenceServices in the manager's namespace. The name is
+// sanitized as by CreateRuntime and BuildInferenceService.
+func (m *Manager) CheckRuntime(ctx context.Context, name string) error {
+	name = sanitizeName(name)
+	runtimes, err := m.ListRuntimes(ctx)
+	if err != nil {
+		return err
+	}
+
+	var available []string
+	for _, rt := range runtimes {
+		if rt.Disabled {
+			continue
+		}
+		if rt.Name == name {
+			return nil
+		}
+		available = append(available, rt.Name)
+	}
+	slices.Sort(available)
+	return fmt.Errorf("serving runtime %q not found (available: %s)", name, strings.Join(available, ", "))
+}
+
+// ensureRuntime creates the default runtime of a CPU backend in the
+// manager's namespace if no runtime of its name exists, so that models of
+// the backend deploy without create_runtime. Other runtimes must exist.
+func (m *Manager) ensureRuntime(ctx context.Context, cfg ModelConfig) error {
+	p := cfg.Backend.profile()
+	if p.gpu || cfg.Runtime != p.runtime {
+		return nil
+	}
+	exists := func() bool {
+		runtimes, err := m.ListRuntimes(ctx)
+		return err != nil || slices.ContainsFunc(runtimes, func(rt RuntimeInfo) bool { return rt.Name == p.runtime })
+	}
+	if exists() {
+		return nil
+	}
+	if _, err := m.CreateRuntime(ctx, RuntimeTemplate{Name: p.runtime, Backend: cfg.Backend}); err != nil && !exists() {
+		return fmt.Errorf("failed to create the %s runtime: %w", cfg.Backend, err)
+	}
+	return nil
+}
+
+// CreateRuntime creates a custom vLLM ServingRuntime in the manager's namespace.
+func (m *Manager) CreateRuntime(ctx context.Context, t RuntimeTemplate) (*RuntimeInfo, error) {
+	sr, err := BuildServingRuntime(t, m.namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(sr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert ServingRuntime to unstructured: %w", err)
+	}
+
+	slog.Info("creating ServingRuntime", "name", sr.Name, "image", sr.Spec.Containers[0].Image)
+	created, err := m.client.Resource(servingRuntimeGVR).Namespace(m.namespace).Create(
+		ctx, &unstructured.Unstructured{Object: obj}, metav1.CreateOptions{},
+	)
+	if err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			return nil, fmt.Errorf("ServingRuntime %s already exists", sr.Name)
+		}
+		return nil, fmt.Errorf("failed to create ServingRuntime %s: %w", sr.Name, m.accessError(err, "create", "servingruntimes"))
+	}
+
+	infos := runtimeInfos([]unstructured.Unstructured{*created}, KindServingRuntime)
+	if len(infos) == 0 {
+		return nil, fmt.Errorf("failed to convert ServingRuntime %s", sr.Name)
+	}
+	return &infos[0], nil
+}
+
+// runtimeInfos converts listed runtime objects, skipping those that cannot be converted.
+func runtimeInfos(items []unstructured.Unstructured, kind string) []RuntimeInfo {
+	infos := make([]RuntimeInfo, 0, len(items))
+	for _, item := range items {
+		var sr ServingRuntime
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &sr); err != nil {
+			slog.Warn("failed to convert serving runtime", "kind", kind, "name", item.GetName(), "error", err)
+			continue
+		}
+
+		info := RuntimeInfo{
+			Name:      sr.Name,
+			Kind:      kind,
+			Namespace: sr.Namespace,
+			Disabled:  sr.Spec.Disabled != nil && *sr.Spec.Disabled,
+			Managed:   sr.Labels["app.kubernetes.io/managed-by"] == managedBy,
+		}
+		for _, f := range sr.Spec.SupportedModelFormats {
+			info.ModelFormats = append(info.ModelFormats, f.Name)
+		}
+		if len(sr.Spec.Containers) > 0 {
+			info.Image = sr.Spec.Containers[0].Image
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
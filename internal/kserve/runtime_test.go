@@ -0,0 +1,150 @@
+package kserve
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func makeRuntime(kind, name, namespace, image string, disabled bool) *unstructured.Unstructured {
+	metadata := map[string]interface{}{"name": name}
+	if namespace != "" {
+		metadata["namespace"] = namespace
+	}
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": runtimeAPIVersion,
+			"kind":       kind,
+			"metadata":   metadata,
+			"spec": map[string]interface{}{
+				"disabled": disabled,
+				"supportedModelFormats": []interface{}{
+					map[string]interface{}{"name": "huggingface"},
+				},
+				"containers": []interface{}{
+					map[string]interface{}{"name": "kserve-container", "image": image},
+				},
+			},
+		},
+	}
+}
+
+func TestBuildServingRuntime(t *testing.T) {
+	sr, err := BuildServingRuntime(RuntimeTemplate{
+		Name:     "vllm-0.6.3",
+		ImageTag: "v0.6.3",
+		Args:     []string{"--max-model-len=8192"},
+	}, "test-namespace")
+	require.NoError(t, err)
+
+	assert.Equal(t, "vllm-0-6-3", sr.Name)
+	assert.Equal(t, "test-namespace", sr.Namespace)
+	assert.Equal(t, managedBy, sr.Labels["app.kubernetes.io/managed-by"])
+	require.Len(t, sr.Spec.SupportedModelFormats, 1)
+	assert.Equal(t, "vLLM", sr.Spec.SupportedModelFormats[0].Name)
+
+	require.Len(t, sr.Spec.Containers, 1)
+	c := sr.Spec.Containers[0]
+	assert.Equal(t, "vllm/vllm-openai:v0.6.3", c.Image)
+	assert.Contains(t, c.Args, "--model=/mnt/models")
+	assert.Contains(t, c.Args, "--served-model-name={{.Name}}")
+	assert.Equal(t, "--max-model-len=8192", c.Args[len(c.Args)-1])
+}
+
+func TestBuildServingRuntimeValidation(t *testing.T) {
+	_, err := BuildServingRuntime(RuntimeTemplate{ImageTag: "v0.6.3"}, "ns")
+	assert.ErrorContains(t, err, "name is required")
+
+	_, err = BuildServingRuntime(RuntimeTemplate{Name: "vllm"}, "ns")
+	assert.ErrorContains(t, err, "image tag is required")
+
+	_, err = BuildServingRuntime(RuntimeTemplate{Name: "ollama", Backend: BackendOllama, Args: []string{"--verbose"}}, "ns")
+	assert.ErrorContains(t, err, "the ollama backend takes no server arguments")
+
+	_, err = BuildServingRuntime(RuntimeTemplate{Name: "tgi", Backend: "tgi"}, "ns")
+	assert.ErrorContains(t, err, `invalid backend "tgi"`)
+}
+
+func TestBuildServingRuntimeCPUBackends(t *testing.T) {
+	sr, err := BuildServingRuntime(RuntimeTemplate{
+		Name:    DefaultLlamaCPPRuntime,
+		Backend: BackendLlamaCPP,
+		Args:    []string{"--ctx-size", "4096"},
+	}, "ns")
+	require.NoError(t, err)
+	assert.Equal(t, "gguf", sr.Spec.SupportedModelFormats[0].Name)
+	c := sr.Spec.Containers[0]
+	assert.Equal(t, "ghcr.io/ggml-org/llama.cpp:server", c.Image)
+	assert.Equal(t, []string{"sh", "-c"}, c.Command[:2])
+	assert.Contains(t, c.Command[2], "exec /app/llama-server --model")
+	assert.Equal(t, []string{"llama-server", "--host", "0.0.0.0", "--port", "8080", "--alias", "{{.Name}}", "--ctx-size", "4096"}, c.Args)
+
+	sr, err = BuildServingRuntime(RuntimeTemplate{Name: DefaultOllamaRuntime, Backend: BackendOllama, ImageTag: "0.6.5"}, "ns")
+	require.NoError(t, err)
+	assert.Equal(t, "gguf", sr.Spec.SupportedModelFormats[0].Name)
+	c = sr.Spec.Containers[0]
+	assert.Equal(t, "ollama/ollama:0.6.5", c.Image)
+	assert.Contains(t, c.Command[2], `/bin/ollama create "$1"`)
+	assert.Equal(t, []string{"ollama", "{{.Name}}"}, c.Args)
+	require.Len(t, c.Env, 1)
+	assert.Equal(t, "OLLAMA_HOST", c.Env[0].Name)
+}
+
+func TestManagerListRuntimes(t *testing.T) {
+	m := newFakeManager(t,
+		makeRuntime(KindServingRuntime, "custom-vllm", "test-namespace", "vllm/vllm-openai:v0.6.3", false),
+		makeRuntime(KindServingRuntime, "other-ns", "other-namespace", "img", false),
+		makeRuntime(KindClusterServingRuntime, "kserve-huggingfaceserver", "", "kserve/huggingfaceserver:v0.14.0", false),
+		makeRuntime(KindClusterServingRuntime, "kserve-old", "", "img", true),
+	)
+
+	runtimes, err := m.ListRuntimes(context.Background())
+	require.NoError(t, err)
+	require.Len(t, runtimes, 3)
+
+	byName := make(map[string]RuntimeInfo)
+	for _, rt := range runtimes {
+		byName[rt.Name] = rt
+	}
+	assert.Equal(t, KindServingRuntime, byName["custom-vllm"].Kind)
+	assert.Equal(t, "vllm/vllm-openai:v0.6.3", byName["custom-vllm"].Image)
+	assert.Equal(t, KindClusterServingRuntime, byName["kserve-huggingfaceserver"].Kind)
+	assert.Equal(t, []string{"huggingface"}, byName["kserve-huggingfaceserver"].ModelFormats)
+	assert.True(t, byName["kserve-old"].Disabled)
+
+	assert.NoError(t, m.CheckRuntime(context.Background(), "kserve-huggingfaceserver"))
+	assert.ErrorContains(t, m.CheckRuntime(context.Background(), "kserve-old"), "not found")
+	assert.ErrorContains(t, m.CheckRuntime(context.Background(), "missing"), "available: custom-vllm, kserve-huggingfaceserver")
+}
+
+func TestManagerCreateRuntime(t *testing.T) {
+	m := newFakeManager(t)
+
+	info, err := m.CreateRuntime(context.Background(), RuntimeTemplate{Name: "vllm-next", ImageTag: "nightly"})
+	require.NoError(t, err)
+	assert.Equal(t, "vllm-next", info.Name)
+	assert.Equal(t, "vllm/vllm-openai:nightly", info.Image)
+	assert.True(t, info.Managed)
+
+	runtimes, err := m.ListRuntimes(context.Background())
+	require.NoError(t, err)
+	require.Len(t, runtimes, 1)
+
+	_, err = m.CreateRuntime(context.Background(), RuntimeTemplate{Name: "vllm-next", ImageTag: "nightly"})
+	assert.ErrorContains(t, err, "already exists")
+}
+
+func TestCheckRuntimeOfSanitizedName(t *testing.T) {
+	m := newFakeManager(t)
+	_, err := m.CreateRuntime(context.Background(), RuntimeTemplate{Name: "vLLM_Nightly", ImageTag: "nightly"})
+	require.NoError(t, err)
+
+	// The runtime is found, and referenced, by the name it was created with.
+	assert.NoError(t, m.CheckRuntime(context.Background(), "vLLM_Nightly"))
+	cfg := DefaultModelConfig("qwen", "hf://Qwen/Qwen2.5-0.5B")
+	cfg.Runtime = "vLLM_Nightly"
+	assert.Equal(t, "vllm-nightly", *BuildInferenceService(cfg, "ns").Spec.Predictor.Model.Runtime)
+}
@@ -0,0 +1,141 @@
+package kserve
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxModelLen bounds --max-model-len far beyond the context of any released
+// model; larger values are typos.
+const maxModelLen = 1 << 24
+
+// argsPresets maps the supported ModelConfig.ArgsPreset values to curated
+// vLLM runtime args.
+var argsPresets = map[string][]string{
+	// Long prompts: prefill in chunks and halve the KV cache to fit them.
+	"long-context": {"--enable-chunked-prefill", "--kv-cache-dtype=fp8", "--gpu-memory-utilization=0.95"},
+	// Many concurrent requests: large batches and shared prompt prefixes.
+	"throughput": {"--max-num-seqs=512", "--max-num-batched-tokens=16384", "--enable-prefix-caching", "--gpu-memory-utilization=0.95"},
+	// Few concurrent requests: small batches keep per-token latency low.
+	"low-latency": {"--max-num-seqs=16", "--max-num-batched-tokens=4096"},
+}
+
+// ArgsPresets returns the names of the supported runtime args presets, sorted.
+func ArgsPresets() []string {
+	names := make([]string, 0, len(argsPresets))
+	for name := range argsPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// modelArgs returns the runtime args of the model container: RuntimeArgs
+// followed by those of the quantization and args presets that RuntimeArgs
+// does not set yet.
+func (c ModelConfig) modelArgs() []string {
+	args := slices.Clone(c.RuntimeArgs)
+	if c.Quantization != "" {
+		args = append(args, presetArgs(quantizationPresets[c.Quantization].args, args)...)
+	}
+	if c.ArgsPreset != "" {
+		args = append(args, presetArgs(argsPresets[c.ArgsPreset], args)...)
+	}
+	return args
+}
+
+// presetArgs returns the preset args whose flags args does not set yet.
+func presetArgs(preset, args []string) []string {
+	var extra []string
+	for _, arg := range preset {
+		flag, _, _ := strings.Cut(arg, "=")
+		if !hasRuntimeArg(args, flag) {
+			extra = append(extra, arg)
+		}
+	}
+	return extra
+}
+
+// runtimeArgValue returns the value of a flag given as "--flag=value" or
+// "--flag value".
+func runtimeArgValue(args []string, name string) (string, bool) {
+	for i, a := range args {
+		if v, ok := strings.CutPrefix(a, name+"="); ok {
+			return v, true
+		}
+		if a == name && i+1 < len(args) {
+			return args[i+1], true
+		}
+	}
+	return "", false
+}
+
+// validateRuntimeArgs checks the values of common vLLM flags, including
+// those added by presets, against each other and the rest of the config.
+func (c ModelConfig) validateRuntimeArgs() error {
+	if c.ArgsPreset != "" {
+		if _, ok := argsPresets[c.ArgsPreset]; !ok {
+			return fmt.Errorf("unsupported args preset %q (expected one of %s)", c.ArgsPreset, strings.Join(ArgsPresets(), ", "))
+		}
+	}
+	if c.Backend != "" && c.Backend != BackendVLLM {
+		return nil // the flags below are vLLM's
+	}
+	args := c.modelArgs()
+
+	parallelism := []struct {
+		flag string
+		want int
+		what string
+	}{
+		{"--tensor-parallel-size", c.NodeGPUs(), "GPU count per node"},
+		{"--pipeline-parallel-size", c.Nodes(), "node count"},
+	}
+	for _, p := range parallelism {
+		v, ok := runtimeArgValue(args, p.flag)
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid %s %q: must be a positive integer", p.flag, v)
+		}
+		if n != p.want {
+			return fmt.Errorf("%s (%d) must equal the %s (%d)", p.flag, n, p.what, p.want)
+		}
+	}
+
+	if v, ok := runtimeArgValue(args, "--max-model-len"); ok {
+		n, err := parseTokenCount(v)
+		if err != nil || n < 1 || n > maxModelLen {
+			return fmt.Errorf("invalid --max-model-len %q: must be between 1 and %d tokens", v, maxModelLen)
+		}
+	}
+	if v, ok := runtimeArgValue(args, "--gpu-memory-utilization"); ok {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f <= 0 || f > 1 {
+			return fmt.Errorf("invalid --gpu-memory-utilization %q: must be greater than 0 and at most 1", v)
+		}
+	}
+	return nil
+}
+
+// parseTokenCount parses a token count the way vLLM does: an integer with an
+// optional decimal (k, m) or binary (K, M) suffix, e.g. "32k" or "128K".
+func parseTokenCount(s string) (int, error) {
+	multipliers := map[string]int{"k": 1000, "m": 1000 * 1000, "K": 1 << 10, "M": 1 << 20}
+	mult := 1
+	if len(s) > 0 {
+		if m, ok := multipliers[s[len(s)-1:]]; ok {
+			s, mult = s[:len(s)-1], m
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, err
+	}
+	return n * mult, nil
+}
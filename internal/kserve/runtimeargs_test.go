@@ -0,0 +1,121 @@
+package kserve
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildInferenceServiceArgsPreset(t *testing.T) {
+	cfg := DefaultModelConfig("m", "hf://org/model")
+	cfg.ArgsPreset = "low-latency"
+	cfg.RuntimeArgs = []string{"--max-num-seqs", "8"}
+	require.NoError(t, cfg.Validate())
+
+	isvc := BuildInferenceService(cfg, "llm-testing")
+	assert.Equal(t, []string{"--max-num-seqs", "8", "--max-num-batched-tokens=4096"}, isvc.Spec.Predictor.Model.Args)
+
+	// Quantization args come first, so they win over the preset.
+	cfg.RuntimeArgs = nil
+	cfg.ArgsPreset, cfg.Quantization = "long-context", "fp8"
+	isvc = BuildInferenceService(cfg, "llm-testing")
+	assert.Equal(t, []string{
+		"--quantization=fp8",
+		"--enable-chunked-prefill", "--kv-cache-dtype=fp8", "--gpu-memory-utilization=0.95",
+	}, isvc.Spec.Predictor.Model.Args)
+}
+
+func TestValidateRuntimeArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*ModelConfig)
+		wantErr string
+	}{
+		{
+			name: "tensor parallel matches GPUs",
+			modify: func(c *ModelConfig) {
+				c.GPUCount, c.RuntimeArgs = 4, []string{"--tensor-parallel-size=4"}
+			},
+		},
+		{
+			name: "tensor parallel mismatch",
+			modify: func(c *ModelConfig) {
+				c.GPUCount, c.RuntimeArgs = 2, []string{"--tensor-parallel-size", "4"}
+			},
+			wantErr: "--tensor-parallel-size (4) must equal the GPU count per node (2)",
+		},
+		{
+			name: "tensor parallel not a number",
+			modify: func(c *ModelConfig) {
+				c.RuntimeArgs = []string{"--tensor-parallel-size=all"}
+			},
+			wantErr: `invalid --tensor-parallel-size "all"`,
+		},
+		{
+			name: "pipeline parallel mismatch",
+			modify: func(c *ModelConfig) {
+				c.TensorParallelSize, c.WorkerCount = 8, 1
+				c.RuntimeArgs = []string{"--pipeline-parallel-size=3"}
+			},
+			wantErr: "--pipeline-parallel-size (3) must equal the node count (2)",
+		},
+		{
+			name: "max model len with suffix",
+			modify: func(c *ModelConfig) {
+				c.RuntimeArgs = []string{"--max-model-len=32k"}
+			},
+		},
+		{
+			name: "max model len zero",
+			modify: func(c *ModelConfig) {
+				c.RuntimeArgs = []string{"--max-model-len=0"}
+			},
+			wantErr: `invalid --max-model-len "0"`,
+		},
+		{
+			name: "max model len too large",
+			modify: func(c *ModelConfig) {
+				c.RuntimeArgs = []string{"--max-model-len=40000000"}
+			},
+			wantErr: "must be between 1 and 16777216 tokens",
+		},
+		{
+			name: "gpu memory utilization as percentage",
+			modify: func(c *ModelConfig) {
+				c.RuntimeArgs = []string{"--gpu-memory-utilization=90"}
+			},
+			wantErr: `invalid --gpu-memory-utilization "90"`,
+		},
+		{
+			name: "unknown preset",
+			modify: func(c *ModelConfig) {
+				c.ArgsPreset = "fast"
+			},
+			wantErr: `unsupported args preset "fast" (expected one of long-context, low-latency, throughput)`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultModelConfig("m", "hf://org/model")
+			tt.modify(&cfg)
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseTokenCount(t *testing.T) {
+	for in, want := range map[string]int{"4096": 4096, "32k": 32000, "128K": 131072, "1m": 1000000} {
+		n, err := parseTokenCount(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, n, in)
+	}
+	_, err := parseTokenCount("k")
+	assert.Error(t, err)
+}
@@ -0,0 +1,233 @@
+package kserve
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// weightOverhead accounts for KV cache, activations, and CUDA context on
+	// top of the raw weights.
+	weightOverhead = 1.2
+
+	// gpuMemoryUtilization matches vLLM's default --gpu-memory-utilization.
+	gpuMemoryUtilization = 0.9
+
+	bytesPerGiB = 1 << 30
+)
+
+// dtypeBytes maps safetensors dtypes to their size in bytes.
+var dtypeBytes = map[string]float64{
+	"F64": 8, "I64": 8,
+	"F32": 4, "I32": 4,
+	"F16": 2, "BF16": 2, "I16": 2,
+	"F8_E4M3": 1, "F8_E5M2": 1, "I8": 1, "U8": 1,
+}
+
+// WeightInfo describes the size of a model's weights.
+type WeightInfo struct {
+	Parameters int64
+	Bytes      int64
+}
+
+// ModelMetadataSource looks up the size of a model's weights from its storage URI.
+type ModelMetadataSource interface {
+	WeightInfo(ctx context.Context, modelURI string) (*WeightInfo, error)
+}
+
+// GPURecommendation describes the GPUs estimated to be needed to serve a model.
+type GPURecommendation struct {
+	Parameters   int64   `json:"parameters"`
+	WeightsGiB   float64 `json:"weights_gib"`
+	RequiredGiB  float64 `json:"required_gib"`
+	GPUMemoryGiB float64 `json:"gpu_memory_gib"`
+	GPUCount     int     `json:"recommended_gpu_count"`
+	Applied      bool    `json:"applied"`
+	Warning      string  `json:"warning,omitempty"`
+}
+
+// RecommendGPUs estimates the GPU count needed to serve cfg.ModelURI on GPUs
+// with gpuMemoryGiB of memory each. When explicit is false and no parallelism
+// is configured, the recommendation
+// is applied to cfg, including a matching --tensor-parallel-size runtime
+// argument; otherwise a warning is set if the requested count looks insufficient.
+// It returns nil for storage URIs the source cannot size.
+func RecommendGPUs(ctx context.Context, src ModelMetadataSource, cfg *ModelConfig, gpuMemoryGiB float64, explicit bool) (*GPURecommendation, error) {
+	if src == nil || gpuMemoryGiB <= 0 || !strings.HasPrefix(cfg.ModelURI, "hf://") || !cfg.Backend.profile().gpu {
+		return nil, nil
+	}
+
+	info, err := src.WeightInfo(ctx, cfg.ModelURI)
+	if err != nil {
+		return nil, err
+	}
+
+	weightsGiB := float64(quantizedBytes(cfg.Quantization, info)) / bytesPerGiB
+	requiredGiB := weightsGiB * weightOverhead
+	count := int(math.Ceil(requiredGiB / (gpuMemoryGiB * gpuMemoryUtilization)))
+	count = max(nextPowerOfTwo(count), 1) // tensor parallelism needs a divisor of the attention heads
+
+	rec := &GPURecommendation{
+		Parameters:   info.Parameters,
+		WeightsGiB:   math.Round(weightsGiB*100) / 100,
+		RequiredGiB:  math.Round(requiredGiB*100) / 100,
+		GPUMemoryGiB: gpuMemoryGiB,
+		GPUCount:     count,
+	}
+
+	// An explicit parallelism layout is never overridden.
+	if explicit || cfg.TensorParallelSize > 0 || cfg.Nodes() > 1 || hasRuntimeArg(cfg.RuntimeArgs, "--tensor-parallel-size") {
+		if cfg.TotalGPUs() < count {
+			rec.Warning = fmt.Sprintf("requested %d GPU(s) but the model needs an estimated %.1f GiB; %d GPU(s) of %.0f GiB are recommended",
+				cfg.TotalGPUs(), rec.RequiredGiB, count, gpuMemoryGiB)
+		}
+		return rec, nil
+	}
+
+	cfg.GPUCount = count
+	if count > 1 && !hasRuntimeArg(cfg.RuntimeArgs, "--tensor-parallel-size") {
+		cfg.RuntimeArgs = append(cfg.RuntimeArgs, fmt.Sprintf("--tensor-parallel-size=%d", count))
+	}
+	rec.Applied = true
+	return rec, nil
+}
+
+func nextPowerOfTwo(n int) int {
+	p := 1
+	for p < n {
+		p *= 2
+	}
+	return p
+}
+
+func hasRuntimeArg(args []string, name string) bool {
+	for _, a := range args {
+		if a == name || strings.HasPrefix(a, name+"=") {
+			return true
+		}
+	}
+	return false
+}
+
+// HFMetadataClient reads model weight sizes from the HuggingFace Hub API.
+type HFMetadataClient struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// NewHFMetadataClient creates a HuggingFace Hub metadata client. The token is
+// optional and only needed for gated or private models.
+func NewHFMetadataClient(token string) *HFMetadataClient {
+	return &HFMetadataClient{
+		baseURL:    "https://huggingface.co",
+		token:      token,
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+type hfModelInfo struct {
+	Siblings []struct {
+		RFilename string `json:"rfilename"`
+	} `json:"siblings"`
+	Private     bool        `json:"private"`
+	Gated       interface{} `json:"gated"` // false, or "auto" or "manual"
+	Safetensors *struct {
+		Parameters map[string]int64 `json:"parameters"`
+		Total      int64            `json:"total"`
+	} `json:"safetensors"`
+}
+
+// WeightInfo returns the parameter count and weight size of an hf:// model URI,
+// computed from the safetensors metadata of the repository.
+func (c *HFMetadataClient) WeightInfo(ctx context.Context, modelURI string) (*WeightInfo, error) {
+	info, repo, err := c.modelInfo(ctx, modelURI)
+	if err != nil {
+		return nil, err
+	}
+	if info.Safetensors == nil || len(info.Safetensors.Parameters) == 0 {
+		return nil, fmt.Errorf("no safetensors metadata available for %s", repo)
+	}
+
+	weights := &WeightInfo{Parameters: info.Safetensors.Total}
+	var bytes float64
+	var params int64
+	for dtype, count := range info.Safetensors.Parameters {
+		size, ok := dtypeBytes[strings.ToUpper(dtype)]
+		if !ok {
+			size = 2 // assume 16-bit for unknown dtypes
+		}
+		bytes += float64(count) * size
+		params += count
+	}
+	if weights.Parameters == 0 {
+		weights.Parameters = params
+	}
+	weights.Bytes = int64(bytes)
+	return weights, nil
+}
+
+// GGUFFiles returns the paths of the GGUF files in the public repository of
+// an hf:// model URI.
+func (c *HFMetadataClient) GGUFFiles(ctx context.Context, modelURI string) ([]string, error) {
+	info, repo, err := c.modelInfo(ctx, modelURI)
+	if err != nil {
+		return nil, err
+	}
+	// Single files are downloaded over HTTPS, without the token.
+	if gated, isBool := info.Gated.(bool); info.Private || (info.Gated != nil && (!isBool || gated)) {
+		return nil, fmt.Errorf("cannot download single files of the gated or private repository %s", repo)
+	}
+	var files []string
+	for _, sibling := range info.Siblings {
+		if strings.HasSuffix(strings.ToLower(sibling.RFilename), ".gguf") {
+			files = append(files, sibling.RFilename)
+		}
+	}
+	return files, nil
+}
+
+// modelInfo fetches the metadata of the repository of an hf:// model URI,
+// returning it with the name of the repository.
+func (c *HFMetadataClient) modelInfo(ctx context.Context, modelURI string) (*hfModelInfo, string, error) {
+	repo, revision, _ := strings.Cut(strings.TrimPrefix(modelURI, "hf://"), "@")
+	repo = strings.Trim(repo, "/")
+	if repo == "" {
+		return nil, "", fmt.Errorf("invalid HuggingFace model URI %q", modelURI)
+	}
+
+	endpoint := c.baseURL + "/api/models/" + repo
+	if revision != "" {
+		endpoint += "/revision/" + url.PathEscape(revision)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create HuggingFace request: %w", err)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch HuggingFace metadata for %s: %w", repo, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("HuggingFace metadata for %s returned status %d", repo, resp.StatusCode)
+	}
+
+	var info hfModelInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, "", fmt.Errorf("failed to parse HuggingFace metadata for %s: %w", repo, err)
+	}
+	return &info, repo, nil
+}
@@ -0,0 +1,215 @@
+package kserve
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticMetadata struct {
+	info *WeightInfo
+}
+
+func (s staticMetadata) WeightInfo(context.Context, string) (*WeightInfo, error) {
+	return s.info, nil
+}
+
+func TestRecommendGPUsAppliesCount(t *testing.T) {
+	// 70B parameters in BF16 is ~130 GiB of weights.
+	src := staticMetadata{info: &WeightInfo{Parameters: 70e9, Bytes: 140e9}}
+	cfg := DefaultModelConfig("llama-70b", "hf://meta-llama/Llama-3.1-70B-Instruct")
+
+	rec, err := RecommendGPUs(context.Background(), src, &cfg, 80, false)
+	require.NoError(t, err)
+	require.NotNil(t, rec)
+
+	assert.Equal(t, 4, rec.GPUCount)
+	assert.True(t, rec.Applied)
+	assert.Empty(t, rec.Warning)
+	assert.Equal(t, 4, cfg.GPUCount)
+	assert.Contains(t, cfg.RuntimeArgs, "--tensor-parallel-size=4")
+}
+
+func TestRecommendGPUsKeepsExplicitTensorParallel(t *testing.T) {
+	src := staticMetadata{info: &WeightInfo{Parameters: 70e9, Bytes: 140e9}}
+	cfg := DefaultModelConfig("llama-70b", "hf://meta-llama/Llama-3.1-70B-Instruct")
+	cfg.GPUCount, cfg.RuntimeArgs = 8, []string{"--tensor-parallel-size=8"}
+
+	_, err := RecommendGPUs(context.Background(), src, &cfg, 80, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"--tensor-parallel-size=8"}, cfg.RuntimeArgs)
+	assert.Equal(t, 8, cfg.GPUCount)
+}
+
+func TestRecommendGPUsQuantizedOnLoad(t *testing.T) {
+	src := staticMetadata{info: &WeightInfo{Parameters: 70e9, Bytes: 140e9}}
+	cfg := DefaultModelConfig("llama-70b", "hf://meta-llama/Llama-3.1-70B-Instruct")
+	cfg.Quantization = "fp8"
+
+	rec, err := RecommendGPUs(context.Background(), src, &cfg, 80, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, rec.GPUCount)
+
+	// AWQ checkpoints are already quantized; their size is taken as is.
+	cfg = DefaultModelConfig("llama-70b", "hf://meta-llama/Llama-3.1-70B-Instruct")
+	cfg.Quantization = "awq"
+	rec, err = RecommendGPUs(context.Background(), src, &cfg, 80, false)
+	require.NoError(t, err)
+	assert.Equal(t, 4, rec.GPUCount)
+}
+
+func TestRecommendGPUsSkipsCPUBackends(t *testing.T) {
+	src := staticMetadata{info: &WeightInfo{Parameters: 7e9, Bytes: 14e9}}
+	cfg := DefaultModelConfig("mistral-7b", "hf://mistralai/Mistral-7B-Instruct-v0.3")
+	cfg.UseBackend(BackendLlamaCPP)
+
+	rec, err := RecommendGPUs(context.Background(), src, &cfg, 80, false)
+	require.NoError(t, err)
+	assert.Nil(t, rec)
+	assert.Zero(t, cfg.GPUCount)
+}
+
+func TestRecommendGPUsSmallModel(t *testing.T) {
+	src := staticMetadata{info: &WeightInfo{Parameters: 7e9, Bytes: 14e9}}
+	cfg := DefaultModelConfig("mistral-7b", "hf://mistralai/Mistral-7B-Instruct-v0.3")
+
+	rec, err := RecommendGPUs(context.Background(), src, &cfg, 80, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, rec.GPUCount)
+	assert.Equal(t, 1, cfg.GPUCount)
+	assert.Empty(t, cfg.RuntimeArgs)
+}
+
+func TestRecommendGPUsWarnsOnExplicitCount(t *testing.T) {
+	src := staticMetadata{info: &WeightInfo{Parameters: 70e9, Bytes: 140e9}}
+	cfg := DefaultModelConfig("llama-70b", "hf://meta-llama/Llama-3.1-70B-Instruct")
+	cfg.GPUCount = 1
+
+	rec, err := RecommendGPUs(context.Background(), src, &cfg, 80, true)
+	require.NoError(t, err)
+	assert.False(t, rec.Applied)
+	assert.Contains(t, rec.Warning, "requested 1 GPU(s)")
+	assert.Equal(t, 1, cfg.GPUCount)
+}
+
+func TestRecommendGPUsKeepsMultiNodeLayout(t *testing.T) {
+	// 405B parameters in BF16 is ~750 GiB of weights.
+	src := staticMetadata{info: &WeightInfo{Parameters: 405e9, Bytes: 810e9}}
+	cfg := DefaultModelConfig("llama-405b", "hf://meta-llama/Llama-3.1-405B-Instruct")
+	cfg.TensorParallelSize = 8
+	cfg.WorkerCount = 1
+
+	rec, err := RecommendGPUs(context.Background(), src, &cfg, 80, false)
+	require.NoError(t, err)
+	assert.False(t, rec.Applied)
+	assert.Empty(t, rec.Warning, "16 GPUs across two nodes suffice")
+	assert.Equal(t, 1, cfg.GPUCount)
+
+	cfg.WorkerCount = 0
+	rec, err = RecommendGPUs(context.Background(), src, &cfg, 80, false)
+	require.NoError(t, err)
+	assert.Contains(t, rec.Warning, "requested 8 GPU(s)")
+}
+
+func TestRecommendGPUsSkipsUnsupported(t *testing.T) {
+	src := staticMetadata{info: &WeightInfo{Bytes: 140e9}}
+
+	cfg := DefaultModelConfig("local", "pvc://models/llama")
+	rec, err := RecommendGPUs(context.Background(), src, &cfg, 80, false)
+	require.NoError(t, err)
+	assert.Nil(t, rec)
+
+	cfg = DefaultModelConfig("llama", "hf://meta-llama/Llama-3.1-70B-Instruct")
+	rec, err = RecommendGPUs(context.Background(), nil, &cfg, 80, false)
+	require.NoError(t, err)
+	assert.Nil(t, rec)
+
+	rec, err = RecommendGPUs(context.Background(), src, &cfg, 0, false)
+	require.NoError(t, err)
+	assert.Nil(t, rec)
+}
+
+func TestHFMetadataClientWeightInfo(t *testing.T) {
+	var gotPath, gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotAuth = r.URL.Path, r.Header.Get("Authorization")
+		_, _ = w.Write([]byte(`{"safetensors":{"parameters":{"BF16":1000,"F32":10},"total":1010}}`))
+	}))
+	defer srv.Close()
+
+	c := NewHFMetadataClient("secret")
+	c.baseURL = srv.URL
+
+	info, err := c.WeightInfo(context.Background(), "hf://org/model@abc123")
+	require.NoError(t, err)
+
+	assert.Equal(t, "/api/models/org/model/revision/abc123", gotPath)
+	assert.Equal(t, "Bearer secret", gotAuth)
+	assert.Equal(t, int64(1010), info.Parameters)
+	assert.Equal(t, int64(2040), info.Bytes)
+}
+
+func TestHFMetadataClientErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/models/org/gated" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"org/nosafetensors"}`))
+	}))
+	defer srv.Close()
+
+	c := NewHFMetadataClient("")
+	c.baseURL = srv.URL
+
+	_, err := c.WeightInfo(context.Background(), "hf://org/gated")
+	assert.ErrorContains(t, err, "status 401")
+
+	_, err = c.WeightInfo(context.Background(), "hf://org/nosafetensors")
+	assert.ErrorContains(t, err, "no safetensors metadata")
+
+	_, err = c.WeightInfo(context.Background(), "hf://")
+	assert.ErrorContains(t, err, "invalid HuggingFace model URI")
+}
+
+func TestResolveGGUFFile(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/models/org/gated-GGUF":
+			_, _ = w.Write([]byte(`{"gated":"manual","siblings":[{"rfilename":"model-Q4_K_M.gguf"}]}`))
+		default:
+			_, _ = w.Write([]byte(`{"gated":false,"siblings":[{"rfilename":"README.md"},{"rfilename":"model-Q8_0.gguf"},{"rfilename":"model-Q4_K_M.gguf"}]}`))
+		}
+	}))
+	defer srv.Close()
+	c := NewHFMetadataClient("")
+	c.baseURL = srv.URL
+
+	cfg := DefaultModelConfig("m", "hf://org/model-GGUF")
+	cfg.UseBackend(BackendOllama)
+	require.NoError(t, ResolveGGUFFile(context.Background(), c, &cfg))
+	assert.Equal(t, "model-Q4_K_M.gguf", cfg.GGUFFile)
+
+	// An explicit file is kept.
+	cfg.GGUFFile = "model-Q8_0.gguf"
+	require.NoError(t, ResolveGGUFFile(context.Background(), c, &cfg))
+	assert.Equal(t, "model-Q8_0.gguf", cfg.GGUFFile)
+
+	// Gated repositories are downloaded whole, with the token.
+	cfg = DefaultModelConfig("m", "hf://org/gated-GGUF")
+	cfg.UseBackend(BackendOllama)
+	assert.ErrorContains(t, ResolveGGUFFile(context.Background(), c, &cfg), "gated or private repository org/gated-GGUF")
+	assert.Empty(t, cfg.GGUFFile)
+
+	// vLLM models and sources that cannot list files are left alone.
+	cfg = DefaultModelConfig("m", "hf://org/model-GGUF")
+	require.NoError(t, ResolveGGUFFile(context.Background(), c, &cfg))
+	assert.Empty(t, cfg.GGUFFile)
+	cfg.UseBackend(BackendOllama)
+	require.NoError(t, ResolveGGUFFile(context.Background(), staticMetadata{}, &cfg))
+	assert.Empty(t, cfg.GGUFFile)
+}
@@ -0,0 +1,65 @@
+package kserve
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Model storage URI schemes that are validated, since deployments treat
+// them specially: HuggingFace tokens, pre-staged PVCs, and S3 storage keys.
+const (
+	SchemeHuggingFace = "hf"
+	SchemePVC         = "pvc"
+	SchemeS3          = "s3"
+)
+
+// StorageLocation is a parsed model storage URI.
+type StorageLocation struct {
+	// Scheme is SchemeHuggingFace, SchemePVC, SchemeS3, or another scheme
+	// KServe's storage initializer supports, such as gs, https, or oci.
+	Scheme string
+
+	// Source is the HuggingFace repository owner, the PVC name, the bucket,
+	// or the host of the URI.
+	Source string
+
+	// Path is the remainder of the URI: the repository name, or the model
+	// directory within the PVC or bucket. It is required for hf:// URIs.
+	Path string
+}
+
+// ParseStorageURI parses a model storage URI. URIs of the form
+// "hf://<org>/<model>", "pvc://<claim>[/<path>]", and "s3://<bucket>[/<path>]"
+// are validated; URIs of other schemes, e.g. gs://, https://, oci://, or
+// Azure blob URLs, are left to KServe's storage initializer.
+func ParseStorageURI(uri string) (*StorageLocation, error) {
+	scheme, rest, ok := strings.Cut(uri, "://")
+	if !ok || scheme == "" {
+		return nil, fmt.Errorf("invalid model URI %q: missing scheme, e.g. hf://, pvc://, or s3://", uri)
+	}
+
+	source, path, _ := strings.Cut(rest, "/")
+	path = strings.Trim(path, "/")
+	switch scheme {
+	case SchemeHuggingFace, SchemePVC, SchemeS3:
+	default:
+		return &StorageLocation{Scheme: scheme, Source: source, Path: path}, nil
+	}
+	if source == "" {
+		return nil, fmt.Errorf("invalid model URI %q: missing %s", uri, sourceName(scheme))
+	}
+	if scheme == SchemeHuggingFace && path == "" {
+		return nil, fmt.Errorf("invalid model URI %q: expected hf://<org>/<model>", uri)
+	}
+	return &StorageLocation{Scheme: scheme, Source: source, Path: path}, nil
+}
+
+func sourceName(scheme string) string {
+	switch scheme {
+	case SchemePVC:
+		return "PVC name"
+	case SchemeS3:
+		return "bucket"
+	}
+	return "repository"
+}
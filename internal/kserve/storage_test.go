@@ -0,0 +1,41 @@
+package kserve
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStorageURI(t *testing.T) {
+	tests := []struct {
+		uri      string
+		expected *StorageLocation
+		err      string
+	}{
+		{uri: "hf://mistralai/Mistral-7B-Instruct-v0.3", expected: &StorageLocation{Scheme: SchemeHuggingFace, Source: "mistralai", Path: "Mistral-7B-Instruct-v0.3"}},
+		{uri: "pvc://models/org/model/", expected: &StorageLocation{Scheme: SchemePVC, Source: "models", Path: "org/model"}},
+		{uri: "pvc://models", expected: &StorageLocation{Scheme: SchemePVC, Source: "models"}},
+		{uri: "s3://bucket/prefix/model", expected: &StorageLocation{Scheme: SchemeS3, Source: "bucket", Path: "prefix/model"}},
+		{uri: "mistralai/Mistral-7B", err: "missing scheme"},
+		{uri: "gs://bucket/model", expected: &StorageLocation{Scheme: "gs", Source: "bucket", Path: "model"}},
+		{uri: "https://example.com/models/model.tar.gz", expected: &StorageLocation{Scheme: "https", Source: "example.com", Path: "models/model.tar.gz"}},
+		{uri: "oci://registry.example.com/models/llama:v1", expected: &StorageLocation{Scheme: "oci", Source: "registry.example.com", Path: "models/llama:v1"}},
+		{uri: "://bucket/model", err: "missing scheme"},
+		{uri: "hf://mistralai", err: "expected hf://<org>/<model>"},
+		{uri: "pvc:///model", err: "missing PVC name"},
+		{uri: "s3://", err: "missing bucket"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			loc, err := ParseStorageURI(tt.uri)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, loc)
+		})
+	}
+}
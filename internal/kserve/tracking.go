@@ -0,0 +1,75 @@
+package kserve
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// deploymentTracker records the ephemeral InferenceServices deployed through
+// a Manager and its namespaced copies that have not been torn down yet.
+type deploymentTracker struct {
+	mu   sync.Mutex
+	keys map[string]struct{} // "namespace/name"
+}
+
+func newDeploymentTracker() *deploymentTracker {
+	return &deploymentTracker{keys: map[string]struct{}{}}
+}
+
+func (t *deploymentTracker) add(namespace, name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.keys[namespace+"/"+name] = struct{}{}
+}
+
+func (t *deploymentTracker) remove(namespace, name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.keys, namespace+"/"+name)
+}
+
+func (t *deploymentTracker) contains(namespace, name string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, ok := t.keys[namespace+"/"+name]
+	return ok
+}
+
+func (t *deploymentTracker) list() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	keys := make([]string, 0, len(t.keys))
+	for k := range t.keys {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// TrackedDeployments returns the ephemeral InferenceServices that were
+// deployed and not torn down yet, as "namespace/name".
+func (m *Manager) TrackedDeployments() []string {
+	return m.tracked.list()
+}
+
+// IsTracked reports whether the InferenceService is an ephemeral deployment
+// that has not been torn down yet.
+func (m *Manager) IsTracked(name string) bool {
+	return m.tracked.contains(m.namespace, sanitizeName(name))
+}
+
+// TeardownTracked tears down all ephemeral InferenceServices that have not
+// been torn down yet, e.g. on shutdown after an interrupted test run.
+func (m *Manager) TeardownTracked(ctx context.Context) error {
+	var errs []error
+	for _, key := range m.tracked.list() {
+		namespace, name, _ := strings.Cut(key, "/")
+		if err := m.WithNamespace(namespace).Teardown(ctx, name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,69 @@
+package kserve
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManagerTracksEphemeralDeployments(t *testing.T) {
+	cfg := DefaultModelConfig("run-model", "hf://org/model")
+	m := newFakeManager(t, makeISVC("team-model", "team-a", true))
+	readyOnCreate(t, m)
+
+	_, err := m.Deploy(context.Background(), DefaultModelConfig("kept-model", "hf://org/model"))
+	require.NoError(t, err)
+	assert.False(t, m.IsTracked("kept-model"))
+
+	cfg.Ephemeral = true
+	_, err = m.Deploy(context.Background(), cfg)
+	require.NoError(t, err)
+	assert.True(t, m.IsTracked("run-model"))
+	assert.False(t, m.WithNamespace("team-a").IsTracked("run-model"))
+
+	m.WithNamespace("team-a").tracked.add("team-a", "team-model")
+	assert.Equal(t, []string{"team-a/team-model", "test-namespace/run-model"}, m.TrackedDeployments())
+
+	require.NoError(t, m.TeardownTracked(context.Background()))
+	assert.Empty(t, m.TrackedDeployments())
+
+	statuses, err := m.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	assert.Equal(t, "kept-model", statuses[0].Name)
+	statuses, err = m.WithNamespace("team-a").List(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, statuses)
+}
+
+func TestManagerDoesNotTrackExistingDeployments(t *testing.T) {
+	cfg := DefaultModelConfig("user-model", "hf://org/model")
+	m := newFakeManager(t, deployedISVC(t, cfg, true))
+
+	cfg.Ephemeral = true
+	status, err := m.Deploy(context.Background(), cfg)
+	require.NoError(t, err)
+	assert.Equal(t, ActionUnchanged, status.Action)
+	assert.False(t, m.IsTracked("user-model"))
+
+	require.NoError(t, m.TeardownTracked(context.Background()))
+	_, err = m.Get(context.Background(), "user-model")
+	assert.NoError(t, err)
+}
+
+func TestManagerTeardownUntracks(t *testing.T) {
+	cfg := DefaultModelConfig("run-model", "hf://org/model")
+	cfg.Ephemeral = true
+	m := newFakeManager(t)
+	readyOnCreate(t, m)
+
+	_, err := m.Deploy(context.Background(), cfg)
+	require.NoError(t, err)
+	require.True(t, m.IsTracked("run-model"))
+
+	require.NoError(t, m.Teardown(context.Background(), "run-model"))
+	assert.False(t, m.IsTracked("run-model"))
+	assert.Empty(t, m.TrackedDeployments())
+}
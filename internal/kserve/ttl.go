@@ -0,0 +1,92 @@
+package kserve
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// expiresAtAnnotation records when a deployed InferenceService expires, as
+// an RFC 3339 timestamp. Expired InferenceServices are torn down by the
+// reaper, or by Cleanup with CleanupOptions.Expired.
+const expiresAtAnnotation = "llm-testing.giantswarm.io/expires-at"
+
+// SetModelTTL sets the lifetime stamped on InferenceServices deployed from
+// now on. Zero, the default, deploys them without expiry.
+func (m *Manager) SetModelTTL(ttl time.Duration) {
+	m.ttl = ttl
+}
+
+// RunReaper tears down expired InferenceServices every interval until ctx
+// is cancelled, in the manager's namespace, the extra namespaces models may
+// be deployed to, and the namespaces of the ephemeral deployments.
+func (m *Manager) RunReaper(ctx context.Context, interval time.Duration, extraNamespaces []string) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for _, namespace := range m.reapNamespaces(extraNamespaces) {
+			if _, err := m.WithNamespace(namespace).Cleanup(ctx, CleanupOptions{Expired: true}); err != nil {
+				slog.Warn("failed to reap expired InferenceServices", "namespace", namespace, "error", err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// reapNamespaces returns the namespaces the reaper looks for expired
+// InferenceServices in.
+func (m *Manager) reapNamespaces(extraNamespaces []string) []string {
+	namespaces := append([]string{m.namespace}, extraNamespaces...)
+	for _, key := range m.tracked.list() {
+		namespace, _, _ := strings.Cut(key, "/")
+		namespaces = append(namespaces, namespace)
+	}
+	slices.Sort(namespaces)
+	return slices.Compact(namespaces)
+}
+
+// extendExpiry sets the expiry of an existing InferenceService deployed
+// again, so that it lives for the TTL from now on.
+func (m *Manager) extendExpiry(ctx context.Context, existing *unstructured.Unstructured, expiresAt string) (*unstructured.Unstructured, error) {
+	if existing.GetAnnotations()[expiresAtAnnotation] == expiresAt {
+		return existing, nil
+	}
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{"annotations": map[string]string{expiresAtAnnotation: expiresAt}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	patched, err := m.client.Resource(isvcGVR).Namespace(m.namespace).Patch(ctx, existing.GetName(), types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to extend the expiry of InferenceService %s: %w", existing.GetName(), m.accessError(err, "patch", "inferenceservices"))
+	}
+	return patched, nil
+}
+
+// expiry returns the expiry of an InferenceService from its TTL annotation.
+func expiry(obj unstructured.Unstructured) (time.Time, bool) {
+	value, ok := obj.GetAnnotations()[expiresAtAnnotation]
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		slog.Warn("ignoring invalid expiry annotation", "name", obj.GetName(), "value", value)
+		return time.Time{}, false
+	}
+	return t, true
+}
@@ -0,0 +1,113 @@
+package kserve
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func expiringISVC(name string, expiresIn time.Duration) runtime.Object {
+	return expiringISVCIn(name, "test-namespace", expiresIn)
+}
+
+func expiringISVCIn(name, namespace string, expiresIn time.Duration) runtime.Object {
+	obj := makeISVC(name, namespace, true)
+	obj.SetAnnotations(map[string]string{expiresAtAnnotation: time.Now().Add(expiresIn).UTC().Format(time.RFC3339)})
+	return obj
+}
+
+func TestManagerDeployStampsExpiry(t *testing.T) {
+	m := newFakeManager(t)
+	m.SetModelTTL(2 * time.Hour)
+
+	cfg := DefaultModelConfig("ttl-test", "hf://org/model")
+	cfg.ReadyTimeout = 100 * time.Millisecond
+	_, err := m.Deploy(context.Background(), cfg)
+	require.ErrorContains(t, err, "not ready")
+
+	obj, err := m.client.Resource(isvcGVR).Namespace("test-namespace").Get(context.Background(), "ttl-test", metav1.GetOptions{})
+	require.NoError(t, err)
+	expiresAt, ok := expiry(*obj)
+	require.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(2*time.Hour), expiresAt, time.Minute)
+}
+
+func TestManagerRedeployExtendsExpiry(t *testing.T) {
+	m := newFakeManager(t)
+	readyOnCreate(t, m)
+	cfg := DefaultModelConfig("ttl-test", "hf://org/model")
+
+	m.SetModelTTL(time.Hour)
+	_, err := m.Deploy(context.Background(), cfg)
+	require.NoError(t, err)
+
+	m.SetModelTTL(3 * time.Hour)
+	status, err := m.Deploy(context.Background(), cfg)
+	require.NoError(t, err)
+	assert.Equal(t, ActionUnchanged, status.Action)
+
+	obj, err := m.client.Resource(isvcGVR).Namespace("test-namespace").Get(context.Background(), "ttl-test", metav1.GetOptions{})
+	require.NoError(t, err)
+	expiresAt, ok := expiry(*obj)
+	require.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(3*time.Hour), expiresAt, time.Minute)
+}
+
+func TestManagerCleanupExpired(t *testing.T) {
+	invalid := makeISVC("invalid", "test-namespace", true)
+	invalid.SetAnnotations(map[string]string{expiresAtAnnotation: "tomorrow"})
+
+	m := newFakeManager(t,
+		expiringISVC("expired", -time.Minute),
+		expiringISVC("live", time.Hour),
+		makeISVC("no-ttl", "test-namespace", true),
+		invalid,
+	)
+
+	results, err := m.Cleanup(context.Background(), CleanupOptions{Expired: true})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "expired", results[0].Name)
+	assert.True(t, results[0].Deleted)
+
+	statuses, err := m.List(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, statuses, 3)
+}
+
+func TestManagerRunReaperStopsOnCancel(t *testing.T) {
+	m := newFakeManager(t, expiringISVC("expired", -time.Minute))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	m.RunReaper(ctx, time.Hour, nil)
+
+	_, err := m.Get(context.Background(), "expired")
+	assert.Error(t, err, "the reaper runs once before it stops")
+}
+
+func TestManagerRunReaperCoversExtraNamespaces(t *testing.T) {
+	m := newFakeManager(t,
+		expiringISVC("expired", -time.Minute),
+		expiringISVCIn("expired", "team-a", -time.Minute),
+		expiringISVCIn("expired", "team-b", -time.Minute),
+		expiringISVCIn("expired", "elsewhere", -time.Minute),
+	)
+	m.tracked.add("team-b", "expired")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	m.RunReaper(ctx, time.Hour, []string{"team-a"})
+
+	for _, namespace := range []string{"test-namespace", "team-a", "team-b"} {
+		_, err := m.WithNamespace(namespace).Get(context.Background(), "expired")
+		assert.Error(t, err, namespace)
+	}
+	_, err := m.WithNamespace("elsewhere").Get(context.Background(), "expired")
+	assert.NoError(t, err)
+}
@@ -1,26 +1,169 @@
 package kserve
 
-import "time"
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// DefaultRuntime is the serving runtime used when none is selected.
+	DefaultRuntime = "kserve-vllm"
+
+	// DefaultMultiNodeRuntime is KServe's runtime for vLLM across nodes,
+	// used instead of DefaultRuntime for multi-node deployments.
+	DefaultMultiNodeRuntime = "kserve-huggingfaceserver-multinode"
+
+	// DefaultShmSize is the default size of the /dev/shm volume.
+	DefaultShmSize = "2Gi"
+
+	// DefaultHFTokenSecretKey is the default key of the HuggingFace token in its Secret.
+	DefaultHFTokenSecretKey = "HF_TOKEN"
+)
 
 // ModelConfig defines a model to be served via KServe InferenceService.
 type ModelConfig struct {
 	// Name is the identifier for the InferenceService resource.
 	Name string
 
-	// ModelURI is the model storage URI (e.g. "hf://mistralai/Mistral-7B-Instruct-v0.3").
+	// ModelURI is the model storage URI: "hf://<org>/<model>",
+	// "pvc://<claim>/<path>" for models pre-staged on a PersistentVolumeClaim,
+	// "s3://<bucket>/<path>", or any other URI KServe's storage initializer
+	// supports, such as gs:// or https:// (see ParseStorageURI).
 	ModelURI string
 
-	// Runtime is the KServe serving runtime (default: "kserve-vllm").
+	// StorageKey selects an entry of KServe's storage-config Secret holding
+	// the S3 endpoint and credentials. When set, the model is referenced via
+	// the storage spec instead of storageUri. Only valid for s3:// URIs;
+	// without it, S3 credentials come from the ServiceAccountName's Secrets.
+	StorageKey string
+
+	// Runtime is the KServe serving runtime (default: DefaultRuntime).
 	Runtime string
 
-	// GPUCount is the number of GPUs to request.
+	// GPUCount is the number of GPUs to request. When TensorParallelSize is
+	// set, it determines the GPUs per node instead (see NodeGPUs).
 	GPUCount int
 
+	// TensorParallelSize is the number of GPUs a model is sharded across on
+	// each node, and PipelineParallelSize the number of nodes (the head plus
+	// workers). With more than one node, BuildInferenceService renders a
+	// KServe workerSpec; WorkerCount may be given instead of
+	// PipelineParallelSize and equals PipelineParallelSize-1.
+	TensorParallelSize   int
+	PipelineParallelSize int
+	WorkerCount          int
+
 	// RuntimeArgs are additional arguments passed to the vLLM runtime.
 	RuntimeArgs []string
 
+	// Backend is the model server the runtime runs (default: BackendVLLM).
+	// Use UseBackend to switch to a CPU backend with its defaults.
+	Backend Backend
+
+	// GGUFFile is the path of the GGUF file within the hf:// repository of
+	// ModelURI that a CPU backend serves, e.g. "model-Q4_K_M.gguf"; only it
+	// is downloaded. Empty downloads the whole repository, unless
+	// ResolveGGUFFile picks a file.
+	GGUFFile string
+
+	// Quantization selects a vLLM quantization preset: "awq", "gptq",
+	// "fp8", or "bitsandbytes". It adds the matching runtime args unless
+	// RuntimeArgs already set them.
+	Quantization string
+
+	// ArgsPreset names a curated set of vLLM runtime args: "long-context",
+	// "throughput", or "low-latency". Like Quantization, it only adds flags
+	// that RuntimeArgs does not set.
+	ArgsPreset string
+
+	// Env sets additional environment variables on the model container,
+	// e.g. VLLM_ATTENTION_BACKEND.
+	Env map[string]string
+
+	// RawDeployment deploys the predictor as a plain Kubernetes Deployment
+	// instead of a Knative service. Multi-node deployments always use it.
+	RawDeployment bool
+
+	// CPU and memory requests and limits as Kubernetes quantities
+	// (e.g. "8", "500m", "64Gi"). Empty values are not set.
+	CPURequest    string
+	CPULimit      string
+	MemoryRequest string
+	MemoryLimit   string
+
+	// ShmSize is the size of the in-memory volume mounted at /dev/shm, which
+	// vLLM needs for tensor-parallel communication. Empty disables the volume.
+	ShmSize string
+
+	// HFTokenSecret names a Secret holding a HuggingFace token, injected as
+	// HF_TOKEN so that gated models can be downloaded. HFTokenSecretKey is the
+	// key within the Secret (default: DefaultHFTokenSecretKey).
+	HFTokenSecret    string
+	HFTokenSecretKey string
+
+	// ServiceAccountName is the service account of the predictor pod.
+	ServiceAccountName string
+
+	// ImagePullSecrets name Secrets used to pull private runtime images.
+	ImagePullSecrets []string
+
+	// NodeSelector, Tolerations, and Affinity constrain the nodes the
+	// predictor is scheduled on, e.g. to a GPU type.
+	NodeSelector map[string]string
+	Tolerations  []corev1.Toleration
+	Affinity     *corev1.Affinity
+
+	// RuntimeClassName selects the container runtime class (e.g. "nvidia").
+	RuntimeClassName string
+
+	// MinReplicas and MaxReplicas bound the predictor's replicas. A
+	// MinReplicas of 0 lets an idle deployment scale to zero and release its
+	// GPUs between runs; nil keeps KServe's default of 1.
+	MinReplicas *int
+	MaxReplicas int
+
+	// ScaleTarget is the per-replica value of ScaleMetric ("concurrency",
+	// "rps", "cpu", or "memory") the autoscaler aims for. Zero uses KServe's default.
+	ScaleTarget int
+	ScaleMetric string
+
+	// CapacityCheck controls whether Deploy first checks that a node has
+	// NodeGPUs free GPUs on each node it needs (default: off).
+	CapacityCheck CapacityCheck
+
+	// Ephemeral marks a deployment made for a single test run. The Manager
+	// tracks it until it is torn down, so that TeardownTracked can clean up
+	// after an interrupted run. An InferenceService that already existed is
+	// not tracked: it does not belong to the run.
+	Ephemeral bool
+
+	// Recreate deletes an existing InferenceService of the same name and
+	// creates it anew, instead of updating it in place.
+	Recreate bool
+
 	// ReadyTimeout is how long to wait for the InferenceService to become ready.
 	ReadyTimeout time.Duration
+
+	// URLMode selects the endpoint URL reported for the deployed model
+	// (default: the status URL, otherwise the cluster-local address).
+	URLMode URLMode
+
+	// ProbeTimeout is how long to wait for the model's OpenAI endpoint to
+	// answer once the InferenceService is ready. Zero skips the probe.
+	ProbeTimeout time.Duration
+
+	// ProbeCompletion additionally requests a one-token completion when
+	// probing the endpoint.
+	ProbeCompletion bool
+
+	// Progress, if set, receives status updates while waiting for readiness.
+	Progress ProgressFunc
 }
 
 // ModelStatus represents the observed state of a deployed model.
@@ -30,6 +173,21 @@ type ModelStatus struct {
 	EndpointURL string `json:"endpoint_url,omitempty"`
 	CreatedAt   string `json:"created_at,omitempty"`
 	Message     string `json:"message,omitempty"`
+
+	// Action is what Deploy did: ActionCreated, ActionUpdated,
+	// ActionUnchanged, or ActionRecreated.
+	Action string `json:"action,omitempty"`
+
+	// SpecHash is a digest of the deployed spec, which changes with the
+	// deployment settings of the model.
+	SpecHash string `json:"spec_hash,omitempty"`
+
+	// CapacityWarning is set when the model was deployed although no node
+	// had enough free GPUs (see CapacityCheckWarn).
+	CapacityWarning string `json:"capacity_warning,omitempty"`
+
+	// GPURecommendation is the GPU sizing estimate made before deploying, if any.
+	GPURecommendation *GPURecommendation `json:"gpu_recommendation,omitempty"`
 }
 
 // DefaultModelConfig returns sensible defaults for a model config.
@@ -37,8 +195,126 @@ func DefaultModelConfig(name, modelURI string) ModelConfig {
 	return ModelConfig{
 		Name:         name,
 		ModelURI:     modelURI,
-		Runtime:      "kserve-vllm",
+		Runtime:      DefaultRuntime,
 		GPUCount:     1,
+		ShmSize:      DefaultShmSize,
 		ReadyTimeout: 10 * time.Minute,
+		ProbeTimeout: DefaultProbeTimeout,
+	}
+}
+
+// Validate checks the model URI and that the resource quantities of the
+// config can be parsed.
+func (c ModelConfig) Validate() error {
+	loc, err := ParseStorageURI(c.ModelURI)
+	if err != nil {
+		return err
+	}
+	if c.StorageKey != "" && loc.Scheme != SchemeS3 {
+		return fmt.Errorf("storage key is only supported for s3:// model URIs")
+	}
+	if err := c.validateBackend(); err != nil {
+		return err
+	}
+	if err := c.validateScaling(); err != nil {
+		return err
+	}
+	if err := c.validateParallelism(); err != nil {
+		return err
+	}
+	if err := c.validateQuantization(); err != nil {
+		return err
+	}
+	if err := c.validateRuntimeArgs(); err != nil {
+		return err
+	}
+	for name := range c.Env {
+		if errs := validation.IsEnvVarName(name); len(errs) > 0 {
+			return fmt.Errorf("invalid environment variable name %q: %s", name, strings.Join(errs, "; "))
+		}
+		if name == "HF_TOKEN" && c.HFTokenSecret != "" {
+			return fmt.Errorf("environment variable HF_TOKEN conflicts with the HuggingFace token Secret")
+		}
+	}
+	if c.RawDeployment && c.MinReplicas != nil && *c.MinReplicas == 0 {
+		return fmt.Errorf("scale to zero (min replicas 0) is not supported in raw deployment mode")
+	}
+
+	quantities := []struct{ field, value string }{
+		{"cpu request", c.CPURequest},
+		{"cpu limit", c.CPULimit},
+		{"memory request", c.MemoryRequest},
+		{"memory limit", c.MemoryLimit},
+		{"shm size", c.ShmSize},
+	}
+	for _, q := range quantities {
+		if q.value == "" {
+			continue
+		}
+		if _, err := resource.ParseQuantity(q.value); err != nil {
+			return fmt.Errorf("invalid %s %q: %w", q.field, q.value, err)
+		}
+	}
+	return nil
+}
+
+// scaleMetrics are the metrics KServe's autoscaler supports.
+var scaleMetrics = []string{"concurrency", "rps", "cpu", "memory"}
+
+func (c ModelConfig) validateScaling() error {
+	if c.MinReplicas != nil && *c.MinReplicas < 0 {
+		return fmt.Errorf("min replicas must not be negative")
+	}
+	if c.MaxReplicas < 0 || c.ScaleTarget < 0 {
+		return fmt.Errorf("max replicas and scale target must not be negative")
+	}
+	if c.MinReplicas != nil && c.MaxReplicas > 0 && *c.MinReplicas > c.MaxReplicas {
+		return fmt.Errorf("min replicas (%d) must not exceed max replicas (%d)", *c.MinReplicas, c.MaxReplicas)
+	}
+	if c.ScaleMetric != "" && !slices.Contains(scaleMetrics, c.ScaleMetric) {
+		return fmt.Errorf("invalid scale metric %q (expected one of %s)", c.ScaleMetric, strings.Join(scaleMetrics, ", "))
+	}
+	return nil
+}
+
+// Nodes returns the number of nodes a deployment spans.
+func (c ModelConfig) Nodes() int {
+	switch {
+	case c.PipelineParallelSize > 0:
+		return c.PipelineParallelSize
+	case c.WorkerCount > 0:
+		return c.WorkerCount + 1
+	}
+	return 1
+}
+
+// NodeGPUs returns the GPUs requested on each node.
+func (c ModelConfig) NodeGPUs() int {
+	if c.TensorParallelSize > 0 {
+		return c.TensorParallelSize
+	}
+	return c.GPUCount
+}
+
+// TotalGPUs returns the GPUs requested across all nodes.
+func (c ModelConfig) TotalGPUs() int {
+	return c.NodeGPUs() * c.Nodes()
+}
+
+func (c ModelConfig) validateParallelism() error {
+	if c.TensorParallelSize < 0 || c.PipelineParallelSize < 0 || c.WorkerCount < 0 {
+		return fmt.Errorf("tensor parallel size, pipeline parallel size, and worker count must not be negative")
+	}
+	if c.PipelineParallelSize > 0 && c.WorkerCount > 0 && c.PipelineParallelSize != c.WorkerCount+1 {
+		return fmt.Errorf("pipeline parallel size (%d) must be worker count (%d) + 1", c.PipelineParallelSize, c.WorkerCount)
+	}
+	if c.Nodes() > 1 {
+		if c.NodeGPUs() < 1 {
+			return fmt.Errorf("multi-node deployments need at least one GPU per node")
+		}
+		if c.MinReplicas != nil || c.MaxReplicas > 0 || c.ScaleTarget > 0 {
+			return fmt.Errorf("autoscaling is not supported for multi-node deployments")
+		}
 	}
+	return nil
 }
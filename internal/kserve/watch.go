@@ -0,0 +1,148 @@
+package kserve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Watch outcomes reported in WatchResult.Outcome.
+const (
+	OutcomeReady   = "ready"
+	OutcomeFailed  = "failed"
+	OutcomeTimeout = "timeout"
+)
+
+// failureStatuses are pod statuses that do not resolve without changing the
+// deployment, so Watch reports them as failures instead of waiting on.
+var failureStatuses = map[string]bool{
+	"CrashLoopBackOff":           true,
+	"ImagePullBackOff":           true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"init container failed":      true,
+}
+
+// WatchResult is the outcome of watching an InferenceService.
+type WatchResult struct {
+	Name        string `json:"name"`
+	Outcome     string `json:"outcome"`
+	Elapsed     string `json:"elapsed"`
+	EndpointURL string `json:"endpoint_url,omitempty"`
+	Message     string `json:"message,omitempty"`
+
+	// Transitions are the status changes and pod events observed, in order.
+	Transitions []DeployProgress `json:"transitions"`
+}
+
+// watchFailure cancels waiting for readiness on a failure status.
+type watchFailure struct {
+	progress DeployProgress
+}
+
+func (f *watchFailure) Error() string {
+	return f.progress.String()
+}
+
+// Watch follows an InferenceService until it is ready, its predictor pods
+// fail, or the timeout expires. Status transitions and, with a typed
+// client, the events of its predictor pods are passed to fn as they occur.
+func (m *Manager) Watch(ctx context.Context, name string, timeout time.Duration, fn ProgressFunc) (*WatchResult, error) {
+	name = sanitizeName(name)
+	if _, err := m.client.Resource(isvcGVR).Namespace(m.namespace).Get(ctx, name, metav1.GetOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to get InferenceService %s: %w", name, m.accessError(err, "get", "inferenceservices"))
+	}
+
+	result := &WatchResult{Name: name, Transitions: []DeployProgress{}}
+	var mu sync.Mutex
+	emit := func(p DeployProgress) {
+		mu.Lock()
+		defer mu.Unlock()
+		result.Transitions = append(result.Transitions, p)
+		if fn != nil {
+			fn(p)
+		}
+	}
+
+	watchCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	progress := newProgressReporter(name, func(p DeployProgress) {
+		emit(p)
+		if failureStatuses[p.Status] {
+			cancel(&watchFailure{progress: p})
+		}
+	})
+
+	var wg sync.WaitGroup
+	if m.kube != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.streamPodEvents(watchCtx, name, progress.start, emit)
+		}()
+	}
+	err := m.waitForReady(watchCtx, name, timeout, progress)
+	cancel(nil)
+	wg.Wait()
+	result.Elapsed = time.Since(progress.start).Round(time.Second).String()
+
+	var (
+		failure  *watchFailure
+		timedOut *readyTimeoutError
+	)
+	switch {
+	case err == nil:
+		result.Outcome = OutcomeReady
+		if status, err := m.Get(ctx, name); err == nil {
+			result.EndpointURL = status.EndpointURL
+		}
+	case errors.As(context.Cause(watchCtx), &failure):
+		result.Outcome = OutcomeFailed
+		result.Message = failure.progress.Status
+		if failure.progress.Message != "" {
+			result.Message += ": " + failure.progress.Message
+		}
+	case ctx.Err() != nil:
+		return nil, ctx.Err()
+	case errors.As(err, &timedOut):
+		result.Outcome = OutcomeTimeout
+		result.Message = err.Error()
+	default:
+		return nil, err
+	}
+	return result, nil
+}
+
+// streamPodEvents passes new events of the predictor pods to emit every
+// podPollInterval until ctx is done.
+func (m *Manager) streamPodEvents(ctx context.Context, name string, start time.Time, emit ProgressFunc) {
+	ticker := time.NewTicker(podPollInterval)
+	defer ticker.Stop()
+
+	seen := map[string]bool{}
+	for {
+		pods, err := m.predictorPods(ctx, name)
+		if err == nil {
+			for _, pod := range pods {
+				for _, event := range m.podEvents(ctx, pod.Name) {
+					key := pod.Name + "/" + event
+					if seen[key] {
+						continue
+					}
+					seen[key] = true
+					emit(DeployProgress{Name: name, Elapsed: time.Since(start), Status: "event", Message: pod.Name + ": " + event})
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package kserve
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func fastPodPolling(t *testing.T) {
+	t.Helper()
+	old := podPollInterval
+	podPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { podPollInterval = old })
+}
+
+func TestManagerWatchReportsFailure(t *testing.T) {
+	fastPodPolling(t)
+	pod := predictorPod("watch-test")
+	pod.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"}
+	m := newFakeManager(t, makeISVC("watch-test", "test-namespace", false), toObject(t, pod))
+	m.SetKubeClient(kubefake.NewSimpleClientset(pod,
+		podEvent(pod.Name, "e1", "BackOff", "Back-off restarting failed container", 3, time.Now())))
+
+	var notified []string
+	result, err := m.Watch(context.Background(), "watch-test", 5*time.Second, func(p DeployProgress) {
+		notified = append(notified, p.Status)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, OutcomeFailed, result.Outcome)
+	assert.Contains(t, result.Message, "CrashLoopBackOff")
+	assert.Contains(t, result.Message, "last terminated: OOMKilled")
+	assert.Contains(t, notified, "CrashLoopBackOff")
+	assert.Len(t, result.Transitions, len(notified))
+
+	var events []string
+	for _, p := range result.Transitions {
+		if p.Status == "event" {
+			events = append(events, p.Message)
+		}
+	}
+	// The failure may be detected before the first events are listed.
+	for _, e := range events {
+		assert.Equal(t, pod.Name+": Warning BackOff: Back-off restarting failed container (x3)", e)
+	}
+}
+
+func TestManagerWatchReady(t *testing.T) {
+	m := newFakeManager(t, makeISVC("watch-test", "test-namespace", false))
+	m.client.(*dynamicfake.FakeDynamicClient).PrependWatchReactor("inferenceservices", func(k8stesting.Action) (bool, watch.Interface, error) {
+		w := watch.NewFakeWithChanSize(2, false)
+		w.Modify(makeISVC("watch-test", "test-namespace", false))
+		w.Modify(makeISVC("watch-test", "test-namespace", true))
+		return true, w, nil
+	})
+
+	result, err := m.Watch(context.Background(), "watch-test", 5*time.Second, nil)
+	require.NoError(t, err)
+	assert.Equal(t, OutcomeReady, result.Outcome)
+	require.Len(t, result.Transitions, 1)
+	assert.Equal(t, "Pending", result.Transitions[0].Status)
+}
+
+func TestManagerWatchTimeout(t *testing.T) {
+	m := newFakeManager(t, makeISVC("watch-test", "test-namespace", false))
+
+	result, err := m.Watch(context.Background(), "watch-test", 50*time.Millisecond, nil)
+	require.NoError(t, err)
+	assert.Equal(t, OutcomeTimeout, result.Outcome)
+	assert.Contains(t, result.Message, "timeout waiting for InferenceService watch-test")
+}
+
+func TestManagerWatchNotFound(t *testing.T) {
+	m := newFakeManager(t)
+	_, err := m.Watch(context.Background(), "missing", time.Second, nil)
+	assert.ErrorContains(t, err, "failed to get InferenceService missing")
+}
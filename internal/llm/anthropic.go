@@ -0,0 +1,323 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultAnthropicBaseURL is the base URL of Anthropic's API.
+	DefaultAnthropicBaseURL = "https://api.anthropic.com/v1"
+
+	// anthropicVersion is the Messages API version sent with every request.
+	anthropicVersion = "2023-06-01"
+
+	// defaultAnthropicMaxTokens is sent when a request sets no MaxTokens,
+	// since the Messages API requires a limit.
+	defaultAnthropicMaxTokens = 4096
+)
+
+// thinkingBudgets maps ChatRequest.ReasoningEffort to extended thinking
+// token budgets.
+var thinkingBudgets = map[string]int{
+	ReasoningEffortLow:    1024,
+	ReasoningEffortMedium: 4096,
+	ReasoningEffortHigh:   16384,
+}
+
+// AnthropicClient implements Client using Anthropic's Messages API.
+type AnthropicClient struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+	limiter    *RateLimiter
+}
+
+// NewAnthropicClient creates a client for Anthropic's Messages API.
+func NewAnthropicClient(opts ...Option) *AnthropicClient {
+	cfg := &clientConfig{baseURL: DefaultAnthropicBaseURL}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return &AnthropicClient{
+		baseURL:    strings.TrimSuffix(cfg.baseURL, "/"),
+		apiKey:     cfg.apiKey,
+		httpClient: cfg.httpClient(cfg.apiKeyTransport("X-Api-Key", "")),
+		limiter:    cfg.rateLimiter,
+	}
+}
+
+// AnthropicError is an error response of the Messages API.
+type AnthropicError struct {
+	StatusCode int
+	Type       string // e.g. "invalid_request_error", "overloaded_error"
+	Message    string
+}
+
+func (e *AnthropicError) Error() string {
+	return fmt.Sprintf("anthropic API error (status %d, %s): %s", e.StatusCode, e.Type, e.Message)
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicThinking struct {
+	Type         string `json:"type"`
+	BudgetTokens int    `json:"budget_tokens"`
+}
+
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	System      string             `json:"system,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+	MaxTokens   int                `json:"max_tokens"`
+	Temperature *float64           `json:"temperature,omitempty"`
+	Thinking    *anthropicThinking `json:"thinking,omitempty"`
+	Stream      bool               `json:"stream,omitempty"`
+}
+
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+func (u anthropicUsage) usage() Usage {
+	return Usage{PromptTokens: u.InputTokens, CompletionTokens: u.OutputTokens, TotalTokens: u.InputTokens + u.OutputTokens}
+}
+
+type anthropicResponse struct {
+	Model   string `json:"model"`
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	StopReason string         `json:"stop_reason"`
+	Usage      anthropicUsage `json:"usage"`
+}
+
+// anthropicFinishReason normalizes a Messages API stop reason.
+func anthropicFinishReason(reason string) string {
+	switch reason {
+	case "end_turn", "stop_sequence":
+		return FinishReasonStop
+	case "max_tokens":
+		return FinishReasonLength
+	case "refusal":
+		return FinishReasonContentFilter
+	}
+	return reason
+}
+
+// buildAnthropicRequest converts a ChatRequest into the Messages API format,
+// which takes system messages separately from the conversation. A reasoning
+// effort enables extended thinking, whose budget comes on top
+// of MaxTokens; the API then requires the default temperature.
+func buildAnthropicRequest(req ChatRequest, stream bool) anthropicRequest {
+	r := anthropicRequest{
+		Model:       req.Model,
+		MaxTokens:   req.MaxTokens,
+		Temperature: req.Temperature,
+		Stream:      stream,
+	}
+	var system []string
+	for _, m := range req.Conversation() {
+		if m.Role == RoleSystem {
+			system = append(system, m.Content)
+			continue
+		}
+		r.Messages = append(r.Messages, anthropicMessage{Role: m.Role, Content: m.Content})
+	}
+	r.System = strings.Join(system, "\n\n")
+	if r.MaxTokens <= 0 {
+		r.MaxTokens = defaultAnthropicMaxTokens
+	}
+	if budget, ok := thinkingBudgets[req.ReasoningEffort]; ok {
+		r.Thinking = &anthropicThinking{Type: "enabled", BudgetTokens: budget}
+		r.MaxTokens += budget
+		r.Temperature = nil
+	}
+	return r
+}
+
+// ChatCompletion sends a non-streaming Messages API request.
+func (c *AnthropicClient) ChatCompletion(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	return traceChat(ctx, ProviderAnthropic, req, c.chatCompletion)
+}
+
+func (c *AnthropicClient) chatCompletion(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("chat completion failed: %w", err)
+	}
+	resp, err := c.send(ctx, buildAnthropicRequest(req, false), req.ExtraParams)
+	if err != nil {
+		return nil, fmt.Errorf("chat completion failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var out anthropicResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("chat completion failed: %w", err)
+	}
+
+	var b strings.Builder
+	texts := 0
+	for _, block := range out.Content {
+		if block.Type == "text" {
+			b.WriteString(block.Text)
+			texts++
+		}
+	}
+	if texts == 0 {
+		return nil, ErrNoChoices
+	}
+	usage := out.Usage.usage()
+	c.limiter.Charge(usage)
+	return &ChatResponse{
+		Content:      b.String(),
+		Usage:        usage,
+		FinishReason: anthropicFinishReason(out.StopReason),
+		Model:        out.Model,
+	}, nil
+}
+
+// ChatCompletionStream sends a streaming Messages API request.
+func (c *AnthropicClient) ChatCompletionStream(ctx context.Context, req ChatRequest) (*StreamReader, error) {
+	return traceStream(ctx, ProviderAnthropic, req, c.chatCompletionStream)
+}
+
+func (c *AnthropicClient) chatCompletionStream(ctx context.Context, req ChatRequest) (*StreamReader, error) {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("chat completion stream failed: %w", err)
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	started := time.Now()
+	resp, err := c.send(ctx, buildAnthropicRequest(req, true), req.ExtraParams)
+	if err != nil {
+		cancel(nil)
+		return nil, fmt.Errorf("chat completion stream failed: %w", err)
+	}
+	return newAnthropicStreamReader(resp.Body).open(ctx, cancel, started, c.limiter), nil
+}
+
+// send posts a request with extra params to the Messages API and returns
+// the response of a successful request; error responses are returned as
+// *AnthropicError.
+func (c *AnthropicClient) send(ctx context.Context, r anthropicRequest, extra map[string]any) (*http.Response, error) {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	if body, err = withExtraParams(body, extra); err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/messages", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("anthropic-version", anthropicVersion)
+	if c.apiKey != "" {
+		httpReq.Header.Set("x-api-key", c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		return nil, anthropicError(resp.StatusCode, data)
+	}
+	return resp, nil
+}
+
+// anthropicError decodes an error body, falling back to the raw body.
+func anthropicError(status int, data []byte) *AnthropicError {
+	var body struct {
+		Error struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil || body.Error.Message == "" {
+		return &AnthropicError{StatusCode: status, Message: strings.TrimSpace(string(data))}
+	}
+	return &AnthropicError{StatusCode: status, Type: body.Error.Type, Message: body.Error.Message}
+}
+
+// anthropicEvent is a server-sent event of a streaming Messages API response.
+type anthropicEvent struct {
+	Type    string `json:"type"`
+	Message struct {
+		Model string         `json:"model"`
+		Usage anthropicUsage `json:"usage"`
+	} `json:"message"`
+	Delta struct {
+		Type       string `json:"type"`
+		Text       string `json:"text"`
+		StopReason string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage anthropicUsage `json:"usage"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// newAnthropicStreamReader reads the text deltas of a streaming response.
+// The prompt tokens and model are reported at the start of the stream, the
+// completion tokens and stop reason at its end.
+func newAnthropicStreamReader(body io.ReadCloser) *StreamReader {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	done := false
+	return &StreamReader{
+		next: func(meta *streamMeta) (string, error) {
+			for !done && scanner.Scan() {
+				data, ok := strings.CutPrefix(scanner.Text(), "data:")
+				if !ok {
+					continue // event names, comments, and blank lines
+				}
+				var event anthropicEvent
+				if err := json.Unmarshal([]byte(strings.TrimSpace(data)), &event); err != nil {
+					return "", err
+				}
+				switch event.Type {
+				case "message_start":
+					meta.model = event.Message.Model
+					meta.usage.PromptTokens = event.Message.Usage.InputTokens
+				case "content_block_delta":
+					if event.Delta.Type == "text_delta" {
+						return event.Delta.Text, nil
+					}
+				case "message_delta":
+					meta.usage.CompletionTokens = event.Usage.OutputTokens
+					meta.usage.TotalTokens = meta.usage.PromptTokens + meta.usage.CompletionTokens
+					meta.finishReason = anthropicFinishReason(event.Delta.StopReason)
+				case "message_stop":
+					done = true
+				case "error":
+					return "", &AnthropicError{Type: event.Error.Type, Message: event.Error.Message}
+				}
+			}
+			if err := scanner.Err(); err != nil {
+				return "", err
+			}
+			if !done {
+				return "", io.ErrUnexpectedEOF
+			}
+			return "", io.EOF
+		},
+		closer: body,
+	}
+}
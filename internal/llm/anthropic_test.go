@@ -0,0 +1,183 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildAnthropicRequest(t *testing.T) {
+	req := buildAnthropicRequest(ChatRequest{
+		Model:         "claude",
+		SystemMessage: "system",
+		UserMessage:   "user",
+		Temperature:   Float64Ptr(0.5),
+	}, false)
+
+	assert.Equal(t, "system", req.System)
+	assert.Equal(t, []anthropicMessage{{Role: "user", Content: "user"}}, req.Messages)
+	assert.Equal(t, defaultAnthropicMaxTokens, req.MaxTokens)
+	require.NotNil(t, req.Temperature)
+	assert.Nil(t, req.Thinking)
+
+	req = buildAnthropicRequest(ChatRequest{
+		Model:           "claude",
+		UserMessage:     "user",
+		Temperature:     Float64Ptr(0.5),
+		MaxTokens:       256,
+		ReasoningEffort: ReasoningEffortLow,
+	}, true)
+
+	require.NotNil(t, req.Thinking)
+	assert.Equal(t, thinkingBudgets[ReasoningEffortLow], req.Thinking.BudgetTokens)
+	assert.Equal(t, 256+thinkingBudgets[ReasoningEffortLow], req.MaxTokens)
+	assert.Nil(t, req.Temperature)
+	assert.True(t, req.Stream)
+}
+
+func TestAnthropicChatCompletion(t *testing.T) {
+	var got anthropicRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/messages", r.URL.Path)
+		assert.Equal(t, "sk-test", r.Header.Get("x-api-key"))
+		assert.Equal(t, anthropicVersion, r.Header.Get("anthropic-version"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		fmt.Fprint(w, `{"model":"claude-sonnet-4-5-20250929","content":[{"type":"thinking","thinking":"hmm"},{"type":"text","text":"Hello"},{"type":"text","text":" world"}],"stop_reason":"end_turn","usage":{"input_tokens":12,"output_tokens":3}}`)
+	}))
+	defer srv.Close()
+
+	client := NewAnthropicClient(WithBaseURL(srv.URL+"/v1/"), WithAPIKey("sk-test"))
+	resp, err := client.ChatCompletion(context.Background(), ChatRequest{Model: "claude", SystemMessage: "be brief", UserMessage: "hi"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Hello world", resp.Content)
+	assert.Equal(t, Usage{PromptTokens: 12, CompletionTokens: 3, TotalTokens: 15}, resp.Usage)
+	assert.Equal(t, FinishReasonStop, resp.FinishReason)
+	assert.Equal(t, "claude-sonnet-4-5-20250929", resp.Model)
+	assert.Equal(t, "be brief", got.System)
+	assert.False(t, got.Stream)
+}
+
+func TestAnthropicChatCompletionError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"type":"error","error":{"type":"invalid_request_error","message":"prompt is too long: 250000 tokens > 200000 maximum"}}`)
+	}))
+	defer srv.Close()
+
+	_, err := NewAnthropicClient(WithBaseURL(srv.URL)).ChatCompletion(context.Background(), ChatRequest{Model: "claude", UserMessage: "hi"})
+	require.Error(t, err)
+
+	var apiErr *AnthropicError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "invalid_request_error", apiErr.Type)
+	assert.Equal(t, ErrorClassContextLength, ClassifyError(err))
+}
+
+func TestAnthropicChatCompletionStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `event: message_start
+data: {"type":"message_start","message":{"model":"claude-sonnet-4-5-20250929","usage":{"input_tokens":20,"output_tokens":1}}}
+
+event: content_block_start
+data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}
+
+event: ping
+data: {"type": "ping"}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hello"}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":" world"}}
+
+event: message_delta
+data: {"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":7}}
+
+event: message_stop
+data: {"type":"message_stop"}
+
+`)
+	}))
+	defer srv.Close()
+
+	stream, err := NewAnthropicClient(WithBaseURL(srv.URL)).ChatCompletionStream(context.Background(), ChatRequest{Model: "claude", UserMessage: "hi"})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	var content string
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		content += chunk
+	}
+	assert.Equal(t, "Hello world", content)
+	assert.Equal(t, Usage{PromptTokens: 20, CompletionTokens: 7, TotalTokens: 27}, stream.Usage())
+	assert.Equal(t, FinishReasonStop, stream.FinishReason())
+	assert.Equal(t, "claude-sonnet-4-5-20250929", stream.Model())
+	assert.Len(t, stream.ChunkTimes(), 2)
+	assert.Positive(t, stream.TimeToFirstToken())
+}
+
+func TestAnthropicChatCompletionStreamError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n")
+	}))
+	defer srv.Close()
+
+	stream, err := NewAnthropicClient(WithBaseURL(srv.URL)).ChatCompletionStream(context.Background(), ChatRequest{Model: "claude", UserMessage: "hi"})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	_, err = stream.Recv()
+	require.Error(t, err)
+	assert.Equal(t, ErrorClassServer, ClassifyError(err))
+}
+
+func TestNewClient(t *testing.T) {
+	client, err := NewClient(ProviderAnthropic)
+	require.NoError(t, err)
+	assert.IsType(t, &AnthropicClient{}, client)
+
+	client, err = NewClient("")
+	require.NoError(t, err)
+	assert.IsType(t, &OpenAIClient{}, client)
+
+	client, err = NewClient(ProviderGemini)
+	require.NoError(t, err)
+	assert.IsType(t, &GeminiClient{}, client)
+
+	_, err = NewClient("bedrock")
+	assert.Error(t, err)
+}
+
+func TestBuildAnthropicRequestMessages(t *testing.T) {
+	req := buildAnthropicRequest(ChatRequest{
+		Model:         "claude",
+		SystemMessage: "system",
+		Messages: []Message{
+			{Role: RoleSystem, Content: "be brief"},
+			{Role: RoleUser, Content: "hi"},
+			{Role: RoleAssistant, Content: "hello"},
+		},
+		UserMessage: "bye",
+	}, false)
+
+	assert.Equal(t, "system\n\nbe brief", req.System)
+	assert.Equal(t, []anthropicMessage{
+		{Role: "user", Content: "hi"},
+		{Role: "assistant", Content: "hello"},
+		{Role: "user", Content: "bye"},
+	}, req.Messages)
+}
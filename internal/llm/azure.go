@@ -0,0 +1,44 @@
+package llm
+
+import (
+	"github.com/sashabaranov/go-openai"
+)
+
+// DefaultAzureAPIVersion is the Azure OpenAI API version used when none is
+// given.
+const DefaultAzureAPIVersion = "2024-10-21"
+
+// WithAzure makes an OpenAIClient call an Azure OpenAI resource: endpoint
+// is the resource URL (e.g. https://my-resource.openai.azure.com; empty
+// keeps the base URL), deployment the deployment requests are routed to
+// (empty means the requested model names the deployment), and apiVersion
+// the api-version query parameter (default: DefaultAzureAPIVersion). The API
+// key is sent in the api-key header.
+func WithAzure(endpoint, deployment, apiVersion string) Option {
+	return func(c *clientConfig) {
+		c.azure = true
+		if endpoint != "" {
+			c.baseURL = endpoint
+		}
+		c.azureDeployment = deployment
+		c.azureAPIVersion = apiVersion
+	}
+}
+
+// azureClientConfig returns the go-openai configuration of an Azure OpenAI
+// resource.
+func azureClientConfig(cfg *clientConfig) openai.ClientConfig {
+	config := openai.DefaultAzureConfig(cfg.apiKey, cfg.baseURL)
+	config.APIVersion = cfg.azureAPIVersion
+	if config.APIVersion == "" {
+		config.APIVersion = DefaultAzureAPIVersion
+	}
+	deployment := cfg.azureDeployment
+	config.AzureModelMapperFunc = func(model string) string {
+		if deployment != "" {
+			return deployment
+		}
+		return model
+	}
+	return config
+}
@@ -0,0 +1,35 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAzureChatCompletion(t *testing.T) {
+	var path, version, key string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, version, key = r.URL.Path, r.URL.Query().Get("api-version"), r.Header.Get("api-key")
+		fmt.Fprint(w, chatCompletionBody)
+	}))
+	defer srv.Close()
+
+	client := NewOpenAIClient(WithAPIKey("azure-key"), WithAzure(srv.URL, "judge-deployment", ""))
+	_, err := client.ChatCompletion(context.Background(), ChatRequest{Model: "gpt-4o", UserMessage: "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "/openai/deployments/judge-deployment/chat/completions", path)
+	assert.Equal(t, DefaultAzureAPIVersion, version)
+	assert.Equal(t, "azure-key", key)
+
+	// Without a deployment, the model names it.
+	client = NewOpenAIClient(WithAzure(srv.URL, "", "2025-01-01-preview"))
+	_, err = client.ChatCompletion(context.Background(), ChatRequest{Model: "gpt-4.1", UserMessage: "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "/openai/deployments/gpt-4.1/chat/completions", path)
+	assert.Equal(t, "2025-01-01-preview", version)
+}
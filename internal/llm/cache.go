@@ -0,0 +1,131 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+// Cache is a disk-backed, content-addressed store of chat completions.
+// A nil Cache caches nothing.
+type Cache struct {
+	dir string
+}
+
+// NewCache returns a cache storing responses in dir, which is created if
+// needed.
+func NewCache(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return &Cache{dir: dir}, nil
+}
+
+// Wrap returns a client answering identical deterministic requests (an
+// explicit temperature of 0) from the cache, and client otherwise. The
+// scope identifies what serves the requests, e.g. the provider and
+// endpoint, so that the same model name on different servers is cached
+// separately. Streaming requests are never cached.
+func (c *Cache) Wrap(client Client, scope string) Client {
+	if c == nil {
+		return client
+	}
+	return &cachingClient{Client: client, cache: c, scope: scope}
+}
+
+// cacheEntry is the stored form of a ChatResponse.
+type cacheEntry struct {
+	Content      string `json:"content"`
+	Usage        Usage  `json:"usage"`
+	FinishReason string `json:"finish_reason,omitempty"`
+	Model        string `json:"model,omitempty"`
+}
+
+// cacheKey returns the hash of everything that determines a response.
+func cacheKey(scope string, req ChatRequest) string {
+	data, _ := json.Marshal(struct {
+		Scope string
+		ChatRequest
+	}{scope, req})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+func (c *Cache) get(key string) (*ChatResponse, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var e cacheEntry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, false
+	}
+	return &ChatResponse{Content: e.Content, Usage: e.Usage, FinishReason: e.FinishReason, Model: e.Model}, true
+}
+
+// put stores a response atomically, so that concurrent runs sharing the
+// cache never read a partial entry.
+func (c *Cache) put(key string, resp *ChatResponse) error {
+	data, err := json.Marshal(cacheEntry{Content: resp.Content, Usage: resp.Usage, FinishReason: resp.FinishReason, Model: resp.Model})
+	if err != nil {
+		return err
+	}
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	err = errors.Join(err, tmp.Close())
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// cachingClient answers deterministic requests from a Cache.
+type cachingClient struct {
+	Client
+	cache *Cache
+	scope string
+}
+
+// ChatCompletion returns the cached response of a deterministic request,
+// or sends it and caches the response. Cached responses report the usage of
+// the original request.
+func (c *cachingClient) ChatCompletion(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	if req.Temperature == nil || *req.Temperature != 0 {
+		return c.Client.ChatCompletion(ctx, req)
+	}
+
+	key := cacheKey(c.scope, req)
+	if resp, ok := c.cache.get(key); ok {
+		slog.Debug("llm cache hit", "model", req.Model, "key", key)
+		return resp, nil
+	}
+
+	resp, err := c.Client.ChatCompletion(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.cache.put(key, resp); err != nil {
+		slog.Warn("failed to cache llm response", "model", req.Model, "error", err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,76 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		fmt.Fprint(w, chatCompletionBody)
+	}))
+	defer srv.Close()
+
+	cache, err := NewCache(t.TempDir())
+	require.NoError(t, err)
+	client := cache.Wrap(NewOpenAIClient(WithBaseURL(srv.URL), WithAPIKey("test")), "openai|"+srv.URL)
+	ctx := context.Background()
+	req := ChatRequest{Model: "mistral", UserMessage: "hi", Temperature: Float64Ptr(0)}
+
+	first, err := client.ChatCompletion(ctx, req)
+	require.NoError(t, err)
+	second, err := client.ChatCompletion(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), requests.Load())
+	assert.Equal(t, first, second)
+
+	// A different prompt or scope misses the cache.
+	_, err = client.ChatCompletion(ctx, ChatRequest{Model: "mistral", UserMessage: "hello", Temperature: Float64Ptr(0)})
+	require.NoError(t, err)
+	_, err = cache.Wrap(NewOpenAIClient(WithBaseURL(srv.URL), WithAPIKey("test")), "other").ChatCompletion(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), requests.Load())
+
+	// Sampled requests are never cached.
+	for range 2 {
+		_, err = client.ChatCompletion(ctx, ChatRequest{Model: "mistral", UserMessage: "hi", Temperature: Float64Ptr(0.7)})
+		require.NoError(t, err)
+		_, err = client.ChatCompletion(ctx, ChatRequest{Model: "mistral", UserMessage: "hi"})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(7), requests.Load())
+}
+
+func TestCacheSkipsErrors(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.Error(w, `{"error":{"message":"bad request"}}`, http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	cache, err := NewCache(t.TempDir())
+	require.NoError(t, err)
+	client := cache.Wrap(NewOpenAIClient(WithBaseURL(srv.URL), WithAPIKey("test")), "")
+	req := ChatRequest{Model: "mistral", UserMessage: "hi", Temperature: Float64Ptr(0)}
+	for range 2 {
+		_, err = client.ChatCompletion(context.Background(), req)
+		assert.Error(t, err)
+	}
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func TestNilCache(t *testing.T) {
+	var cache *Cache
+	client := NewOpenAIClient()
+	assert.Same(t, Client(client), cache.Wrap(client, ""))
+}
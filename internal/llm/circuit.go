@@ -0,0 +1,102 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without calling the API while a circuit
+// breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker open: endpoint is failing")
+
+// CircuitBreaker fails requests fast once an endpoint looks dead, so that a
+// test run does not wait for a timeout on every remaining question. It
+// opens after a number of consecutive endpoint failures (timeouts, 5xx
+// responses, and connection errors) and rejects requests with
+// ErrCircuitOpen for a cooldown. Afterwards requests are let through again;
+// the circuit closes on the first success and reopens on the next failure.
+// Failures caused by the request itself, e.g. 4xx responses, count as signs
+// of life. A nil CircuitBreaker never opens. It is safe for concurrent use.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time // for tests
+
+	mu        sync.Mutex
+	failures  int // consecutive endpoint failures
+	openUntil time.Time
+}
+
+// NewCircuitBreaker returns a circuit breaker opening after threshold
+// consecutive failures for cooldown, or nil if threshold is not positive.
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	if threshold <= 0 {
+		return nil
+	}
+	return &CircuitBreaker{threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+// Wrap returns a client whose requests pass through the circuit breaker.
+// Streams count as failed only if they cannot be opened.
+func (b *CircuitBreaker) Wrap(client Client) Client {
+	if b == nil {
+		return client
+	}
+	return &breakingClient{client: client, breaker: b}
+}
+
+// allow returns ErrCircuitOpen while the circuit is open.
+func (b *CircuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if wait := b.openUntil.Sub(b.now()); wait > 0 {
+		return fmt.Errorf("%w (retrying in %s)", ErrCircuitOpen, wait.Round(time.Second))
+	}
+	return nil
+}
+
+// record updates the circuit with the outcome of a request.
+func (b *CircuitBreaker) record(ctx context.Context, err error) {
+	if err != nil && ctx.Err() != nil {
+		return // cancelled by the caller, not the endpoint's fault
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch ClassifyError(err) {
+	case ErrorClassTimeout, ErrorClassServer, ErrorClassOther:
+		b.failures++
+		if b.failures >= b.threshold {
+			b.openUntil = b.now().Add(b.cooldown)
+			slog.Warn("circuit breaker opened", "consecutive_failures", b.failures, "cooldown", b.cooldown, "error", err)
+		}
+	default:
+		b.failures = 0
+	}
+}
+
+type breakingClient struct {
+	client  Client
+	breaker *CircuitBreaker
+}
+
+func (c *breakingClient) ChatCompletion(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	if err := c.breaker.allow(); err != nil {
+		return nil, err
+	}
+	resp, err := c.client.ChatCompletion(ctx, req)
+	c.breaker.record(ctx, err)
+	return resp, err
+}
+
+func (c *breakingClient) ChatCompletionStream(ctx context.Context, req ChatRequest) (*StreamReader, error) {
+	if err := c.breaker.allow(); err != nil {
+		return nil, err
+	}
+	stream, err := c.client.ChatCompletionStream(ctx, req)
+	c.breaker.record(ctx, err)
+	return stream, err
+}
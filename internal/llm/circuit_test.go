@@ -0,0 +1,108 @@
+package llm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedClient answers with the next of its errors, or successfully once
+// they are used up.
+type scriptedClient struct {
+	errs  []error
+	calls int
+}
+
+func (c *scriptedClient) next() error {
+	c.calls++
+	if len(c.errs) == 0 {
+		return nil
+	}
+	err := c.errs[0]
+	c.errs = c.errs[1:]
+	return err
+}
+
+func (c *scriptedClient) ChatCompletion(context.Context, ChatRequest) (*ChatResponse, error) {
+	if err := c.next(); err != nil {
+		return nil, err
+	}
+	return &ChatResponse{Content: "ok"}, nil
+}
+
+func (c *scriptedClient) ChatCompletionStream(context.Context, ChatRequest) (*StreamReader, error) {
+	if err := c.next(); err != nil {
+		return nil, err
+	}
+	return newReplayStreamReader(&ChatResponse{Content: "ok"}), nil
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	serverErr := &openai.APIError{HTTPStatusCode: 503, Message: "unavailable"}
+	inner := &scriptedClient{errs: []error{serverErr, serverErr, serverErr, serverErr}}
+	breaker := NewCircuitBreaker(3, time.Minute)
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+	client := breaker.Wrap(inner)
+	ctx := context.Background()
+
+	for range 3 {
+		_, err := client.ChatCompletion(ctx, ChatRequest{})
+		assert.ErrorAs(t, err, &serverErr)
+	}
+
+	// Open: requests fail fast without reaching the endpoint.
+	_, err := client.ChatCompletion(ctx, ChatRequest{})
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	_, err = client.ChatCompletionStream(ctx, ChatRequest{})
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, 3, inner.calls)
+
+	// After the cooldown a failing trial request reopens the circuit at once.
+	now = now.Add(time.Minute)
+	_, err = client.ChatCompletion(ctx, ChatRequest{})
+	assert.ErrorAs(t, err, &serverErr)
+	_, err = client.ChatCompletion(ctx, ChatRequest{})
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+
+	// A successful trial request closes it.
+	now = now.Add(time.Minute)
+	resp, err := client.ChatCompletion(ctx, ChatRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp.Content)
+	_, err = client.ChatCompletion(ctx, ChatRequest{})
+	assert.NoError(t, err)
+}
+
+func TestCircuitBreakerIgnoresRequestErrors(t *testing.T) {
+	serverErr := &openai.APIError{HTTPStatusCode: 500}
+	clientErr := &openai.APIError{HTTPStatusCode: 400}
+	inner := &scriptedClient{errs: []error{serverErr, clientErr, serverErr, context.Canceled, serverErr}}
+	client := NewCircuitBreaker(2, time.Minute).Wrap(inner)
+
+	// A 4xx response resets the count of consecutive failures.
+	for range 3 {
+		_, _ = client.ChatCompletion(context.Background(), ChatRequest{})
+	}
+
+	// Requests cancelled by the caller do not count.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := client.ChatCompletion(ctx, ChatRequest{})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, err = client.ChatCompletion(context.Background(), ChatRequest{})
+	assert.NotErrorIs(t, err, ErrCircuitOpen, "opened by the fifth request, not before")
+	_, err = client.ChatCompletion(context.Background(), ChatRequest{})
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+}
+
+func TestNewCircuitBreakerDisabled(t *testing.T) {
+	inner := &scriptedClient{}
+	assert.Nil(t, NewCircuitBreaker(0, time.Minute))
+	assert.Same(t, Client(inner), NewCircuitBreaker(0, time.Minute).Wrap(inner))
+}
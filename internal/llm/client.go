@@ -2,15 +2,16 @@ package llm
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	"io"
-	"strings"
+	"sort"
+	"time"
 
 	"github.com/sashabaranov/go-openai"
 )
 
-// Client abstracts an OpenAI-compatible LLM API.
+// Client abstracts a chat LLM API: OpenAI-compatible servers (OpenAIClient),
+// Anthropic's Messages API (AnthropicClient), or Gemini on the Gemini API or
+// Vertex AI (GeminiClient).
 type Client interface {
 	// ChatCompletion sends a chat completion request and returns the response.
 	ChatCompletion(ctx context.Context, req ChatRequest) (*ChatResponse, error)
@@ -18,44 +19,142 @@ type Client interface {
 	ChatCompletionStream(ctx context.Context, req ChatRequest) (*StreamReader, error)
 }
 
-// ChatRequest is a simplified chat request.
+// ModelLister is implemented by clients that can list the models served at
+// their endpoint.
+type ModelLister interface {
+	ListModels(ctx context.Context) ([]string, error)
+}
+
+// ListModels returns the IDs of the models served at the endpoint of
+// client, e.g. the exact model name a vLLM server expects.
+func ListModels(ctx context.Context, client Client) ([]string, error) {
+	lister, ok := client.(ModelLister)
+	if !ok {
+		return nil, fmt.Errorf("%T cannot list models", client)
+	}
+	return lister.ListModels(ctx)
+}
+
+// ChatRequest is a simplified chat request. Single-turn requests only set
+// SystemMessage and UserMessage; multi-turn conversations and few-shot
+// prompts add earlier turns in Messages.
 type ChatRequest struct {
 	Model         string
 	SystemMessage string
 	UserMessage   string
 	Temperature   *float64 // nil means "use client default"
+
+	// Messages are the turns between SystemMessage and UserMessage, e.g.
+	// few-shot examples as pairs of user and assistant messages.
+	Messages []Message
+
+	// MaxTokens caps the number of generated tokens. Zero means "use server default".
+	MaxTokens int
+
+	// ReasoningEffort controls the thinking budget of reasoning models
+	// ("low", "medium", "high"). Empty means "use server default".
+	ReasoningEffort string
+
+	// ExtraParams are added to the provider's request payload as top-level
+	// fields, replacing fields set from the settings above, e.g.
+	// {"top_k": 20} or, for DeepSeek-R1 on vLLM,
+	// {"chat_template_kwargs": {"enable_thinking": true}}. Left out of the
+	// response cache key when empty, so that existing entries stay valid.
+	ExtraParams map[string]any `json:",omitempty"`
+}
+
+// Roles of a Message.
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Message is a turn of a conversation.
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Conversation returns the messages of the request in order: SystemMessage
+// as a system message, Messages, and UserMessage as the last user message.
+// Empty SystemMessage and UserMessage are left out.
+func (r ChatRequest) Conversation() []Message {
+	messages := make([]Message, 0, len(r.Messages)+2)
+	if r.SystemMessage != "" {
+		messages = append(messages, Message{Role: RoleSystem, Content: r.SystemMessage})
+	}
+	messages = append(messages, r.Messages...)
+	if r.UserMessage != "" {
+		messages = append(messages, Message{Role: RoleUser, Content: r.UserMessage})
+	}
+	return messages
 }
 
 // ChatResponse holds the result of a chat completion.
 type ChatResponse struct {
 	Content string
-}
+	Usage   Usage
 
-// StreamReader wraps a streaming response.
-type StreamReader struct {
-	stream *openai.ChatCompletionStream
+	// FinishReason is why the model stopped generating, normalized to
+	// FinishReasonStop, FinishReasonLength, or FinishReasonContentFilter
+	// where the API's reason maps to one of them. Empty if not reported.
+	FinishReason string
+
+	// Model is the model that answered as reported by the API, e.g. a dated
+	// version of the requested alias. Empty if not reported.
+	Model string
 }
 
-// Recv reads the next chunk from the stream.
-func (s *StreamReader) Recv() (string, error) {
-	resp, err := s.stream.Recv()
-	if err != nil {
-		return "", err
-	}
-	if len(resp.Choices) > 0 {
-		return resp.Choices[0].Delta.Content, nil
-	}
-	return "", nil
+// Normalized values of ChatResponse.FinishReason.
+const (
+	FinishReasonStop          = "stop"
+	FinishReasonLength        = "length" // cut off at the token limit
+	FinishReasonContentFilter = "content_filter"
+)
+
+// Usage is the token count reported by the server for a completion.
+// All counts are zero when the server does not report usage.
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
 }
 
-// Close closes the stream.
-func (s *StreamReader) Close() {
-	_ = s.stream.Close()
+// newOpenAIStreamReader reads an OpenAI chat completion stream.
+func newOpenAIStreamReader(stream *openai.ChatCompletionStream) *StreamReader {
+	return &StreamReader{
+		next: func(meta *streamMeta) (string, error) {
+			resp, err := stream.Recv()
+			if err != nil {
+				return "", err
+			}
+			if resp.Model != "" {
+				meta.model = resp.Model
+			}
+			if resp.Usage != nil {
+				meta.usage = Usage{
+					PromptTokens:     resp.Usage.PromptTokens,
+					CompletionTokens: resp.Usage.CompletionTokens,
+					TotalTokens:      resp.Usage.TotalTokens,
+				}
+			}
+			if len(resp.Choices) > 0 {
+				if reason := resp.Choices[0].FinishReason; reason != "" {
+					meta.finishReason = string(reason)
+				}
+				return resp.Choices[0].Delta.Content, nil
+			}
+			return "", nil
+		},
+		closer: stream,
+	}
 }
 
 // OpenAIClient implements Client using the OpenAI-compatible API.
 type OpenAIClient struct {
-	client *openai.Client
+	client  *openai.Client
+	limiter *RateLimiter
 }
 
 // NewOpenAIClient creates a new OpenAI-compatible client.
@@ -70,56 +169,125 @@ func NewOpenAIClient(opts ...Option) *OpenAIClient {
 
 	config := openai.DefaultConfig(cfg.apiKey)
 	config.BaseURL = cfg.baseURL
+	header, prefix := "Authorization", "Bearer "
+	if cfg.azure {
+		config = azureClientConfig(cfg)
+		header, prefix = openai.AzureAPIKeyHeader, ""
+	}
+	httpClient := cfg.httpClient(cfg.apiKeyTransport(header, prefix))
+	httpClient.Transport = &extraParamsTransport{next: httpClient.Transport}
+	config.HTTPClient = httpClient
 
 	return &OpenAIClient{
-		client: openai.NewClientWithConfig(config),
+		client:  openai.NewClientWithConfig(config),
+		limiter: cfg.rateLimiter,
+	}
+}
+
+// NewClient creates a client for the given provider; empty means
+// ProviderOpenAI.
+func NewClient(provider string, opts ...Option) (Client, error) {
+	if err := ValidateProvider(provider); err != nil {
+		return nil, err
 	}
+	switch provider {
+	case ProviderAnthropic:
+		return NewAnthropicClient(opts...), nil
+	case ProviderGemini:
+		return NewGeminiClient(opts...)
+	case ProviderVertexAI:
+		return NewGeminiClient(append([]Option{WithVertexAI("", "")}, opts...)...)
+	case ProviderAzure:
+		return NewOpenAIClient(append([]Option{WithAzure("", "", "")}, opts...)...), nil
+	}
+	return NewOpenAIClient(opts...), nil
 }
 
 // ChatCompletion sends a non-streaming chat completion request.
 func (c *OpenAIClient) ChatCompletion(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
-	messages := []openai.ChatCompletionMessage{
-		{Role: openai.ChatMessageRoleSystem, Content: req.SystemMessage},
-		{Role: openai.ChatMessageRoleUser, Content: req.UserMessage},
-	}
+	return traceChat(ctx, ProviderOpenAI, req, c.chatCompletion)
+}
 
-	temp := float32(temperatureValue(req.Temperature))
-	resp, err := c.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
-		Model:       req.Model,
-		Messages:    messages,
-		Temperature: temp,
-	})
+func (c *OpenAIClient) chatCompletion(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("chat completion failed: %w", err)
+	}
+	resp, err := c.client.CreateChatCompletion(contextWithExtraParams(ctx, req.ExtraParams), buildChatCompletionRequest(req))
 	if err != nil {
 		return nil, fmt.Errorf("chat completion failed: %w", err)
 	}
 
 	if len(resp.Choices) == 0 {
-		return nil, fmt.Errorf("no choices returned")
+		return nil, ErrNoChoices
 	}
 
+	usage := Usage{
+		PromptTokens:     resp.Usage.PromptTokens,
+		CompletionTokens: resp.Usage.CompletionTokens,
+		TotalTokens:      resp.Usage.TotalTokens,
+	}
+	c.limiter.Charge(usage)
 	return &ChatResponse{
-		Content: resp.Choices[0].Message.Content,
+		Content:      resp.Choices[0].Message.Content,
+		Usage:        usage,
+		FinishReason: string(resp.Choices[0].FinishReason),
+		Model:        resp.Model,
 	}, nil
 }
 
 // ChatCompletionStream sends a streaming chat completion request.
 func (c *OpenAIClient) ChatCompletionStream(ctx context.Context, req ChatRequest) (*StreamReader, error) {
-	messages := []openai.ChatCompletionMessage{
-		{Role: openai.ChatMessageRoleSystem, Content: req.SystemMessage},
-		{Role: openai.ChatMessageRoleUser, Content: req.UserMessage},
-	}
+	return traceStream(ctx, ProviderOpenAI, req, c.chatCompletionStream)
+}
 
-	temp := float32(temperatureValue(req.Temperature))
-	stream, err := c.client.CreateChatCompletionStream(ctx, openai.ChatCompletionRequest{
-		Model:       req.Model,
-		Messages:    messages,
-		Temperature: temp,
-	})
+func (c *OpenAIClient) chatCompletionStream(ctx context.Context, req ChatRequest) (*StreamReader, error) {
+	r := buildChatCompletionRequest(req)
+	// Ask for a final chunk with token usage; servers that do not support it ignore the option.
+	r.StreamOptions = &openai.StreamOptions{IncludeUsage: true}
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("chat completion stream failed: %w", err)
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	started := time.Now()
+	stream, err := c.client.CreateChatCompletionStream(contextWithExtraParams(ctx, req.ExtraParams), r)
 	if err != nil {
+		cancel(nil)
 		return nil, fmt.Errorf("chat completion stream failed: %w", err)
 	}
 
-	return &StreamReader{stream: stream}, nil
+	return newOpenAIStreamReader(stream).open(ctx, cancel, started, c.limiter), nil
+}
+
+// ListModels returns the sorted IDs of the models listed by the endpoint's
+// /models API.
+func (c *OpenAIClient) ListModels(ctx context.Context) ([]string, error) {
+	list, err := c.client.ListModels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list models: %w", err)
+	}
+	ids := make([]string, 0, len(list.Models))
+	for _, m := range list.Models {
+		ids = append(ids, m.ID)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// buildChatCompletionRequest converts a ChatRequest into the OpenAI wire format.
+func buildChatCompletionRequest(req ChatRequest) openai.ChatCompletionRequest {
+	var messages []openai.ChatCompletionMessage
+	for _, m := range req.Conversation() {
+		messages = append(messages, openai.ChatCompletionMessage{Role: m.Role, Content: m.Content})
+	}
+	return openai.ChatCompletionRequest{
+		Model:       req.Model,
+		Messages:    messages,
+		Temperature: float32(temperatureValue(req.Temperature)),
+		// max_completion_tokens supersedes max_tokens and is the only limit
+		// accepted by reasoning models.
+		MaxCompletionTokens: req.MaxTokens,
+		ReasoningEffort:     req.ReasoningEffort,
+	}
 }
 
 // temperatureValue returns the float64 temperature value, defaulting to 0 if nil.
@@ -129,20 +297,3 @@ func temperatureValue(t *float64) float64 {
 	}
 	return 0
 }
-
-// CollectStream reads all chunks from a StreamReader and returns the full content.
-func CollectStream(sr *StreamReader) (string, error) {
-	defer sr.Close()
-	var b strings.Builder
-	for {
-		chunk, err := sr.Recv()
-		if err != nil {
-			if errors.Is(err, io.EOF) {
-				break
-			}
-			return b.String(), err
-		}
-		b.WriteString(chunk)
-	}
-	return b.String(), nil
-}
@@ -3,6 +3,7 @@ package llm
 import (
 	"testing"
 
+	"github.com/sashabaranov/go-openai"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -18,3 +19,56 @@ func TestNewOpenAIClientWithAllOptions(t *testing.T) {
 	)
 	assert.NotNil(t, client.client)
 }
+
+func TestBuildChatCompletionRequest(t *testing.T) {
+	req := buildChatCompletionRequest(ChatRequest{
+		Model:           "judge",
+		SystemMessage:   "system",
+		UserMessage:     "user",
+		Temperature:     Float64Ptr(0.5),
+		MaxTokens:       256,
+		ReasoningEffort: ReasoningEffortLow,
+	})
+
+	assert.Equal(t, "judge", req.Model)
+	assert.Len(t, req.Messages, 2)
+	assert.InDelta(t, 0.5, req.Temperature, 0.001)
+	assert.Equal(t, 256, req.MaxCompletionTokens)
+	assert.Equal(t, ReasoningEffortLow, req.ReasoningEffort)
+}
+
+func TestValidateReasoningEffort(t *testing.T) {
+	for _, effort := range []string{"", ReasoningEffortLow, ReasoningEffortMedium, ReasoningEffortHigh} {
+		assert.NoError(t, ValidateReasoningEffort(effort), effort)
+	}
+	assert.Error(t, ValidateReasoningEffort("extreme"))
+}
+
+func TestChatRequestConversation(t *testing.T) {
+	fewShot := []Message{
+		{Role: RoleUser, Content: "2+2?"},
+		{Role: RoleAssistant, Content: "4"},
+	}
+	assert.Equal(t, []Message{
+		{Role: RoleSystem, Content: "system"},
+		{Role: RoleUser, Content: "2+2?"},
+		{Role: RoleAssistant, Content: "4"},
+		{Role: RoleUser, Content: "3+3?"},
+	}, ChatRequest{SystemMessage: "system", Messages: fewShot, UserMessage: "3+3?"}.Conversation())
+
+	assert.Equal(t, fewShot, ChatRequest{Messages: fewShot}.Conversation())
+}
+
+func TestBuildChatCompletionRequestMessages(t *testing.T) {
+	req := buildChatCompletionRequest(ChatRequest{
+		Model:       "mistral",
+		Messages:    []Message{{Role: RoleUser, Content: "hi"}, {Role: RoleAssistant, Content: "hello"}},
+		UserMessage: "bye",
+	})
+
+	assert.Equal(t, []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleUser, Content: "hi"},
+		{Role: openai.ChatMessageRoleAssistant, Content: "hello"},
+		{Role: openai.ChatMessageRoleUser, Content: "bye"},
+	}, req.Messages)
+}
@@ -0,0 +1,95 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// EmbeddingsClient is implemented by clients that can embed texts, e.g. to
+// compare answers by their similarity.
+type EmbeddingsClient interface {
+	Embeddings(ctx context.Context, req EmbeddingsRequest) (*EmbeddingsResponse, error)
+}
+
+// EmbeddingsRequest asks for the embeddings of texts.
+type EmbeddingsRequest struct {
+	Model string
+	Input []string
+
+	// Dimensions shortens the embeddings of models that support it. Zero
+	// means the model's full size.
+	Dimensions int
+}
+
+// EmbeddingsResponse holds one embedding per input text, in input order.
+type EmbeddingsResponse struct {
+	Embeddings [][]float32
+	Usage      Usage
+	Model      string
+}
+
+// Embeddings embeds texts with client, which must implement
+// EmbeddingsClient.
+func Embeddings(ctx context.Context, client Client, req EmbeddingsRequest) (*EmbeddingsResponse, error) {
+	embedder, ok := client.(EmbeddingsClient)
+	if !ok {
+		return nil, fmt.Errorf("%T cannot compute embeddings", client)
+	}
+	return embedder.Embeddings(ctx, req)
+}
+
+// Embeddings sends an /embeddings request.
+func (c *OpenAIClient) Embeddings(ctx context.Context, req EmbeddingsRequest) (*EmbeddingsResponse, error) {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("embeddings failed: %w", err)
+	}
+	resp, err := c.client.CreateEmbeddings(ctx, openai.EmbeddingRequestStrings{
+		Input:      req.Input,
+		Model:      openai.EmbeddingModel(req.Model),
+		Dimensions: req.Dimensions,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("embeddings failed: %w", err)
+	}
+	if len(resp.Data) != len(req.Input) {
+		return nil, fmt.Errorf("embeddings failed: got %d embeddings for %d inputs", len(resp.Data), len(req.Input))
+	}
+
+	usage := Usage{PromptTokens: resp.Usage.PromptTokens, TotalTokens: resp.Usage.TotalTokens}
+	c.limiter.Charge(usage)
+	out := &EmbeddingsResponse{
+		Embeddings: make([][]float32, len(resp.Data)),
+		Usage:      usage,
+		Model:      string(resp.Model),
+	}
+	for i, d := range resp.Data {
+		index := d.Index
+		if index < 0 || index >= len(out.Embeddings) {
+			index = i
+		}
+		out.Embeddings[index] = d.Embedding
+	}
+	return out, nil
+}
+
+// CosineSimilarity returns the cosine similarity of two embeddings, from -1
+// (opposite) to 1 (same direction), or 0 if they differ in size or either
+// is zero.
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,53 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIEmbeddings(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/embeddings", r.URL.Path)
+		var body struct {
+			Input      []string `json:"input"`
+			Model      string   `json:"model"`
+			Dimensions int      `json:"dimensions"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, []string{"a", "b"}, body.Input)
+		assert.Equal(t, "bge-m3", body.Model)
+		assert.Equal(t, 2, body.Dimensions)
+		// Embeddings may come out of order; the index places them.
+		fmt.Fprint(w, `{"object":"list","model":"bge-m3","data":[
+			{"object":"embedding","index":1,"embedding":[0,1]},
+			{"object":"embedding","index":0,"embedding":[1,0]}
+		],"usage":{"prompt_tokens":2,"total_tokens":2}}`)
+	}))
+	defer srv.Close()
+
+	resp, err := Embeddings(context.Background(), NewOpenAIClient(WithBaseURL(srv.URL)), EmbeddingsRequest{Model: "bge-m3", Input: []string{"a", "b"}, Dimensions: 2})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float32{{1, 0}, {0, 1}}, resp.Embeddings)
+	assert.Equal(t, Usage{PromptTokens: 2, TotalTokens: 2}, resp.Usage)
+	assert.Equal(t, "bge-m3", resp.Model)
+}
+
+func TestEmbeddingsUnsupported(t *testing.T) {
+	_, err := Embeddings(context.Background(), NewAnthropicClient(), EmbeddingsRequest{Model: "claude", Input: []string{"a"}})
+	assert.ErrorContains(t, err, "cannot compute embeddings")
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	assert.InDelta(t, 1, CosineSimilarity([]float32{1, 2}, []float32{2, 4}), 1e-9)
+	assert.InDelta(t, 0, CosineSimilarity([]float32{1, 0}, []float32{0, 1}), 1e-9)
+	assert.InDelta(t, -1, CosineSimilarity([]float32{1, 0}, []float32{-1, 0}), 1e-9)
+	assert.Zero(t, CosineSimilarity([]float32{1}, []float32{1, 0}))
+	assert.Zero(t, CosineSimilarity([]float32{0, 0}, []float32{1, 0}))
+}
@@ -0,0 +1,127 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// ErrNoChoices is returned when the API responds successfully but without any choices.
+var ErrNoChoices = errors.New("no choices returned")
+
+// ErrorClass categorises a failed LLM request so that flaky endpoints can be
+// told apart from genuinely poor model answers.
+type ErrorClass string
+
+const (
+	ErrorClassTimeout       ErrorClass = "timeout"
+	ErrorClassClient        ErrorClass = "4xx"
+	ErrorClassServer        ErrorClass = "5xx"
+	ErrorClassParse         ErrorClass = "parse"
+	ErrorClassContextLength ErrorClass = "context_length"
+	ErrorClassCircuitOpen   ErrorClass = "circuit_open"
+	ErrorClassOther         ErrorClass = "other"
+)
+
+// Retryable reports whether a request failing with this class may succeed when retried.
+func (c ErrorClass) Retryable() bool {
+	return c == ErrorClassTimeout || c == ErrorClassServer
+}
+
+// ClassifyError maps an error returned by a Client to an ErrorClass.
+func ClassifyError(err error) ErrorClass {
+	if err == nil {
+		return ""
+	}
+
+	if errors.Is(err, ErrCircuitOpen) {
+		return ErrorClassCircuitOpen
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorClassTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ErrorClassTimeout
+	}
+
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		if isContextLengthError(apiErr.Code, apiErr.Message) {
+			return ErrorClassContextLength
+		}
+		return classifyStatus(apiErr.HTTPStatusCode)
+	}
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		if isContextLengthError(nil, string(reqErr.Body)) {
+			return ErrorClassContextLength
+		}
+		return classifyStatus(reqErr.HTTPStatusCode)
+	}
+
+	var anthropicErr *AnthropicError
+	if errors.As(err, &anthropicErr) {
+		if isContextLengthError(nil, anthropicErr.Message) {
+			return ErrorClassContextLength
+		}
+		if anthropicErr.StatusCode == 0 {
+			// Errors in a stream carry no status code, only their type.
+			switch anthropicErr.Type {
+			case "overloaded_error", "api_error":
+				return ErrorClassServer
+			}
+			return ErrorClassOther
+		}
+		return classifyStatus(anthropicErr.StatusCode)
+	}
+
+	var geminiErr *GeminiError
+	if errors.As(err, &geminiErr) {
+		if isContextLengthError(nil, geminiErr.Message) {
+			return ErrorClassContextLength
+		}
+		return classifyStatus(geminiErr.StatusCode)
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.Is(err, ErrNoChoices) || errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+		return ErrorClassParse
+	}
+
+	return ErrorClassOther
+}
+
+func classifyStatus(code int) ErrorClass {
+	switch {
+	case code == http.StatusRequestTimeout || code == http.StatusGatewayTimeout:
+		return ErrorClassTimeout
+	case code >= 400 && code < 500:
+		return ErrorClassClient
+	case code >= 500:
+		return ErrorClassServer
+	default:
+		return ErrorClassOther
+	}
+}
+
+// isContextLengthError detects prompt-too-long errors. OpenAI reports them via
+// the error code; vLLM, Anthropic, Gemini, and most proxies only via the
+// message text.
+func isContextLengthError(code any, message string) bool {
+	if c, ok := code.(string); ok && c == "context_length_exceeded" {
+		return true
+	}
+	msg := strings.ToLower(message)
+	return strings.Contains(msg, "maximum context length") ||
+		strings.Contains(msg, "context_length_exceeded") ||
+		strings.Contains(msg, "context length") ||
+		strings.Contains(msg, "prompt is too long") ||
+		strings.Contains(msg, "exceeds the maximum number of tokens")
+}
@@ -0,0 +1,51 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorClass
+	}{
+		{name: "nil", err: nil, want: ""},
+		{name: "deadline", err: fmt.Errorf("wrapped: %w", context.DeadlineExceeded), want: ErrorClassTimeout},
+		{name: "gateway timeout", err: &openai.APIError{HTTPStatusCode: 504}, want: ErrorClassTimeout},
+		{name: "bad request", err: &openai.APIError{HTTPStatusCode: 400, Message: "bad"}, want: ErrorClassClient},
+		{name: "server error", err: &openai.RequestError{HTTPStatusCode: 502}, want: ErrorClassServer},
+		{
+			name: "context length code",
+			err:  &openai.APIError{HTTPStatusCode: 400, Code: "context_length_exceeded"},
+			want: ErrorClassContextLength,
+		},
+		{
+			name: "context length message",
+			err:  &openai.APIError{HTTPStatusCode: 400, Message: "This model's maximum context length is 8192 tokens"},
+			want: ErrorClassContextLength,
+		},
+		{name: "no choices", err: fmt.Errorf("chat: %w", ErrNoChoices), want: ErrorClassParse},
+		{name: "circuit open", err: fmt.Errorf("chat: %w", ErrCircuitOpen), want: ErrorClassCircuitOpen},
+		{name: "unknown", err: fmt.Errorf("boom"), want: ErrorClassOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ClassifyError(tt.err))
+		})
+	}
+}
+
+func TestErrorClassRetryable(t *testing.T) {
+	assert.True(t, ErrorClassTimeout.Retryable())
+	assert.True(t, ErrorClassServer.Retryable())
+	assert.False(t, ErrorClassClient.Retryable())
+	assert.False(t, ErrorClassContextLength.Retryable())
+	assert.False(t, ErrorClassCircuitOpen.Retryable())
+}
@@ -0,0 +1,368 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	// DefaultGeminiBaseURL is the base URL of the Gemini API.
+	DefaultGeminiBaseURL = "https://generativelanguage.googleapis.com/v1beta"
+
+	// DefaultVertexLocation is the Vertex AI region used when none is set.
+	DefaultVertexLocation = "us-central1"
+
+	// vertexScope is the OAuth scope of Vertex AI requests.
+	vertexScope = "https://www.googleapis.com/auth/cloud-platform"
+)
+
+// GeminiClient implements Client using the Gemini generateContent API,
+// either with an API key or on Vertex AI with Google credentials.
+type GeminiClient struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+	limiter    *RateLimiter
+}
+
+// NewGeminiClient creates a client for the Gemini API. With WithVertexAI,
+// it calls Vertex AI instead and authenticates with Application Default
+// Credentials, e.g. the service account key in GOOGLE_APPLICATION_CREDENTIALS
+// or the workload identity of the pod.
+func NewGeminiClient(opts ...Option) (*GeminiClient, error) {
+	cfg := &clientConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if !cfg.vertex {
+		baseURL := cfg.baseURL
+		if baseURL == "" {
+			baseURL = DefaultGeminiBaseURL
+		}
+		return &GeminiClient{
+			baseURL:    strings.TrimSuffix(baseURL, "/"),
+			apiKey:     cfg.apiKey,
+			httpClient: cfg.httpClient(cfg.apiKeyTransport("X-Goog-Api-Key", "")),
+			limiter:    cfg.rateLimiter,
+		}, nil
+	}
+
+	if cfg.vertexProject == "" {
+		return nil, fmt.Errorf("vertex AI client requires a project")
+	}
+	baseURL := cfg.baseURL
+	if baseURL == "" {
+		baseURL = vertexBaseURL(cfg.vertexProject, cfg.vertexLocation)
+	}
+	creds, err := google.FindDefaultCredentials(context.Background(), vertexScope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find Google credentials: %w", err)
+	}
+	return &GeminiClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		httpClient: cfg.httpClient(func(base http.RoundTripper) http.RoundTripper {
+			return &oauth2.Transport{Source: creds.TokenSource, Base: base}
+		}),
+		limiter: cfg.rateLimiter,
+	}, nil
+}
+
+// vertexBaseURL returns the base URL of Google's publisher models on Vertex
+// AI in the given project and location (default: DefaultVertexLocation).
+func vertexBaseURL(project, location string) string {
+	if location == "" {
+		location = DefaultVertexLocation
+	}
+	host := location + "-aiplatform.googleapis.com"
+	if location == "global" {
+		host = "aiplatform.googleapis.com"
+	}
+	return fmt.Sprintf("https://%s/v1/projects/%s/locations/%s/publishers/google", host, url.PathEscape(project), url.PathEscape(location))
+}
+
+// GeminiError is an error response of the Gemini or Vertex AI API.
+type GeminiError struct {
+	StatusCode int
+	Status     string // e.g. "INVALID_ARGUMENT", "RESOURCE_EXHAUSTED"
+	Message    string
+}
+
+func (e *GeminiError) Error() string {
+	return fmt.Sprintf("gemini API error (status %d, %s): %s", e.StatusCode, e.Status, e.Message)
+}
+
+type geminiPart struct {
+	Text    string `json:"text"`
+	Thought bool   `json:"thought,omitempty"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiThinkingConfig struct {
+	ThinkingBudget int `json:"thinkingBudget"`
+}
+
+type geminiGenerationConfig struct {
+	Temperature     *float64              `json:"temperature,omitempty"`
+	MaxOutputTokens int                   `json:"maxOutputTokens,omitempty"`
+	ThinkingConfig  *geminiThinkingConfig `json:"thinkingConfig,omitempty"`
+}
+
+type geminiRequest struct {
+	SystemInstruction *geminiContent         `json:"systemInstruction,omitempty"`
+	Contents          []geminiContent        `json:"contents"`
+	GenerationConfig  geminiGenerationConfig `json:"generationConfig"`
+}
+
+type geminiResponse struct {
+	Candidates []struct {
+		Content      geminiContent `json:"content"`
+		FinishReason string        `json:"finishReason"`
+	} `json:"candidates"`
+	UsageMetadata struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+		ThoughtsTokenCount   int `json:"thoughtsTokenCount"`
+		TotalTokenCount      int `json:"totalTokenCount"`
+	} `json:"usageMetadata"`
+	ModelVersion string `json:"modelVersion"`
+	Error        *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+	} `json:"error"`
+}
+
+// text returns the answer text of the first candidate, without thoughts,
+// and whether the response had a candidate.
+func (r *geminiResponse) text() (string, bool) {
+	if len(r.Candidates) == 0 {
+		return "", false
+	}
+	var b strings.Builder
+	for _, part := range r.Candidates[0].Content.Parts {
+		if !part.Thought {
+			b.WriteString(part.Text)
+		}
+	}
+	return b.String(), true
+}
+
+// usage returns the reported token usage; thinking tokens are billed as
+// completion tokens.
+func (r *geminiResponse) usage() Usage {
+	u := r.UsageMetadata
+	usage := Usage{
+		PromptTokens:     u.PromptTokenCount,
+		CompletionTokens: u.CandidatesTokenCount + u.ThoughtsTokenCount,
+		TotalTokens:      u.TotalTokenCount,
+	}
+	if usage.TotalTokens == 0 {
+		usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	}
+	return usage
+}
+
+// finishReason returns the normalized finish reason of the first candidate.
+func (r *geminiResponse) finishReason() string {
+	if len(r.Candidates) == 0 {
+		return ""
+	}
+	switch reason := r.Candidates[0].FinishReason; reason {
+	case "STOP":
+		return FinishReasonStop
+	case "MAX_TOKENS":
+		return FinishReasonLength
+	case "SAFETY", "RECITATION", "BLOCKLIST", "PROHIBITED_CONTENT", "SPII":
+		return FinishReasonContentFilter
+	default:
+		return strings.ToLower(reason)
+	}
+}
+
+// buildGeminiRequest converts a ChatRequest into the generateContent format,
+// in which system messages are the system instruction and the assistant is
+// the "model". A reasoning effort sets the thinking budget of thinking models.
+func buildGeminiRequest(req ChatRequest) geminiRequest {
+	r := geminiRequest{
+		GenerationConfig: geminiGenerationConfig{
+			Temperature:     req.Temperature,
+			MaxOutputTokens: req.MaxTokens,
+		},
+	}
+	for _, m := range req.Conversation() {
+		switch m.Role {
+		case RoleSystem:
+			if r.SystemInstruction == nil {
+				r.SystemInstruction = &geminiContent{}
+			}
+			r.SystemInstruction.Parts = append(r.SystemInstruction.Parts, geminiPart{Text: m.Content})
+		case RoleAssistant:
+			r.Contents = append(r.Contents, geminiContent{Role: "model", Parts: []geminiPart{{Text: m.Content}}})
+		default:
+			r.Contents = append(r.Contents, geminiContent{Role: m.Role, Parts: []geminiPart{{Text: m.Content}}})
+		}
+	}
+	if budget, ok := thinkingBudgets[req.ReasoningEffort]; ok {
+		r.GenerationConfig.ThinkingConfig = &geminiThinkingConfig{ThinkingBudget: budget}
+	}
+	return r
+}
+
+// ChatCompletion sends a non-streaming generateContent request.
+func (c *GeminiClient) ChatCompletion(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	return traceChat(ctx, ProviderGemini, req, c.chatCompletion)
+}
+
+func (c *GeminiClient) chatCompletion(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("chat completion failed: %w", err)
+	}
+	resp, err := c.send(ctx, req.Model, "generateContent", buildGeminiRequest(req), req.ExtraParams)
+	if err != nil {
+		return nil, fmt.Errorf("chat completion failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var out geminiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("chat completion failed: %w", err)
+	}
+	content, ok := out.text()
+	if !ok {
+		return nil, ErrNoChoices
+	}
+	usage := out.usage()
+	c.limiter.Charge(usage)
+	return &ChatResponse{
+		Content:      content,
+		Usage:        usage,
+		FinishReason: out.finishReason(),
+		Model:        out.ModelVersion,
+	}, nil
+}
+
+// ChatCompletionStream sends a streamGenerateContent request.
+func (c *GeminiClient) ChatCompletionStream(ctx context.Context, req ChatRequest) (*StreamReader, error) {
+	return traceStream(ctx, ProviderGemini, req, c.chatCompletionStream)
+}
+
+func (c *GeminiClient) chatCompletionStream(ctx context.Context, req ChatRequest) (*StreamReader, error) {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("chat completion stream failed: %w", err)
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	started := time.Now()
+	resp, err := c.send(ctx, req.Model, "streamGenerateContent?alt=sse", buildGeminiRequest(req), req.ExtraParams)
+	if err != nil {
+		cancel(nil)
+		return nil, fmt.Errorf("chat completion stream failed: %w", err)
+	}
+	return newGeminiStreamReader(resp.Body).open(ctx, cancel, started, c.limiter), nil
+}
+
+// send posts a request with extra params to a method of the model and
+// returns the response of a successful request; error responses are
+// returned as *GeminiError.
+func (c *GeminiClient) send(ctx context.Context, model, method string, r geminiRequest, extra map[string]any) (*http.Response, error) {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	if body, err = withExtraParams(body, extra); err != nil {
+		return nil, err
+	}
+	endpoint := fmt.Sprintf("%s/models/%s:%s", c.baseURL, url.PathEscape(strings.TrimPrefix(model, "models/")), method)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		httpReq.Header.Set("x-goog-api-key", c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		return nil, geminiError(resp.StatusCode, data)
+	}
+	return resp, nil
+}
+
+// geminiError decodes an error body, falling back to the raw body. Vertex AI
+// wraps the error in a JSON array.
+func geminiError(status int, data []byte) *GeminiError {
+	var body geminiResponse
+	if err := json.Unmarshal(data, &body); err != nil {
+		var list []geminiResponse
+		if json.Unmarshal(data, &list) == nil && len(list) > 0 {
+			body = list[0]
+		}
+	}
+	if body.Error == nil || body.Error.Message == "" {
+		return &GeminiError{StatusCode: status, Message: strings.TrimSpace(string(data))}
+	}
+	return &GeminiError{StatusCode: status, Status: body.Error.Status, Message: body.Error.Message}
+}
+
+// newGeminiStreamReader reads the text of a streaming response. Each event
+// is a partial response; the usage of the last one covers the whole stream,
+// and the last one reports the finish reason.
+func newGeminiStreamReader(body io.ReadCloser) *StreamReader {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	return &StreamReader{
+		next: func(meta *streamMeta) (string, error) {
+			for scanner.Scan() {
+				data, ok := strings.CutPrefix(scanner.Text(), "data:")
+				if !ok {
+					continue
+				}
+				var chunk geminiResponse
+				if err := json.Unmarshal([]byte(strings.TrimSpace(data)), &chunk); err != nil {
+					return "", err
+				}
+				if chunk.Error != nil {
+					return "", &GeminiError{StatusCode: chunk.Error.Code, Status: chunk.Error.Status, Message: chunk.Error.Message}
+				}
+				if u := chunk.usage(); u != (Usage{}) {
+					meta.usage = u
+				}
+				if reason := chunk.finishReason(); reason != "" {
+					meta.finishReason = reason
+				}
+				if chunk.ModelVersion != "" {
+					meta.model = chunk.ModelVersion
+				}
+				if text, _ := chunk.text(); text != "" {
+					return text, nil
+				}
+			}
+			if err := scanner.Err(); err != nil {
+				return "", err
+			}
+			return "", io.EOF
+		},
+		closer: body,
+	}
+}
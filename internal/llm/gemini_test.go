@@ -0,0 +1,172 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildGeminiRequest(t *testing.T) {
+	req := buildGeminiRequest(ChatRequest{
+		Model:           "gemini-2.5-pro",
+		SystemMessage:   "system",
+		UserMessage:     "user",
+		Temperature:     Float64Ptr(0.5),
+		MaxTokens:       256,
+		ReasoningEffort: ReasoningEffortMedium,
+	})
+
+	require.NotNil(t, req.SystemInstruction)
+	assert.Equal(t, "system", req.SystemInstruction.Parts[0].Text)
+	assert.Equal(t, []geminiContent{{Role: "user", Parts: []geminiPart{{Text: "user"}}}}, req.Contents)
+	assert.Equal(t, 256, req.GenerationConfig.MaxOutputTokens)
+	require.NotNil(t, req.GenerationConfig.ThinkingConfig)
+	assert.Equal(t, thinkingBudgets[ReasoningEffortMedium], req.GenerationConfig.ThinkingConfig.ThinkingBudget)
+
+	req = buildGeminiRequest(ChatRequest{Model: "gemini-2.5-flash", UserMessage: "user"})
+	assert.Nil(t, req.SystemInstruction)
+	assert.Nil(t, req.GenerationConfig.ThinkingConfig)
+}
+
+func TestVertexBaseURL(t *testing.T) {
+	assert.Equal(t, "https://us-central1-aiplatform.googleapis.com/v1/projects/my-project/locations/us-central1/publishers/google", vertexBaseURL("my-project", ""))
+	assert.Equal(t, "https://aiplatform.googleapis.com/v1/projects/my-project/locations/global/publishers/google", vertexBaseURL("my-project", "global"))
+}
+
+func TestNewGeminiClientVertexRequiresProject(t *testing.T) {
+	_, err := NewClient(ProviderVertexAI)
+	assert.Error(t, err)
+}
+
+func TestGeminiChatCompletion(t *testing.T) {
+	var got geminiRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1beta/models/gemini-2.5-pro:generateContent", r.URL.Path)
+		assert.Equal(t, "key", r.Header.Get("x-goog-api-key"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		fmt.Fprint(w, `{"candidates":[{"content":{"role":"model","parts":[{"text":"thinking...","thought":true},{"text":"Hello"},{"text":" world"}]},"finishReason":"MAX_TOKENS"}],"usageMetadata":{"promptTokenCount":12,"candidatesTokenCount":3,"thoughtsTokenCount":40,"totalTokenCount":55},"modelVersion":"gemini-2.5-pro-preview-06-05"}`)
+	}))
+	defer srv.Close()
+
+	client, err := NewGeminiClient(WithBaseURL(srv.URL+"/v1beta/"), WithAPIKey("key"))
+	require.NoError(t, err)
+	resp, err := client.ChatCompletion(context.Background(), ChatRequest{Model: "models/gemini-2.5-pro", SystemMessage: "be brief", UserMessage: "hi"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Hello world", resp.Content)
+	assert.Equal(t, Usage{PromptTokens: 12, CompletionTokens: 43, TotalTokens: 55}, resp.Usage)
+	assert.Equal(t, FinishReasonLength, resp.FinishReason)
+	assert.Equal(t, "gemini-2.5-pro-preview-06-05", resp.Model)
+	require.NotNil(t, got.SystemInstruction)
+	assert.Equal(t, "be brief", got.SystemInstruction.Parts[0].Text)
+}
+
+func TestGeminiChatCompletionNoCandidates(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"promptFeedback":{"blockReason":"SAFETY"}}`)
+	}))
+	defer srv.Close()
+
+	client, err := NewGeminiClient(WithBaseURL(srv.URL))
+	require.NoError(t, err)
+	_, err = client.ChatCompletion(context.Background(), ChatRequest{Model: "gemini-2.5-pro", UserMessage: "hi"})
+	assert.ErrorIs(t, err, ErrNoChoices)
+}
+
+func TestGeminiChatCompletionError(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   ErrorClass
+	}{
+		{
+			name:   "context length",
+			status: http.StatusBadRequest,
+			body:   `{"error":{"code":400,"message":"The input token count (1200000) exceeds the maximum number of tokens allowed (1048576).","status":"INVALID_ARGUMENT"}}`,
+			want:   ErrorClassContextLength,
+		},
+		{
+			name:   "vertex array",
+			status: http.StatusServiceUnavailable,
+			body:   `[{"error":{"code":503,"message":"The service is currently unavailable.","status":"UNAVAILABLE"}}]`,
+			want:   ErrorClassServer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer srv.Close()
+
+			client, err := NewGeminiClient(WithBaseURL(srv.URL))
+			require.NoError(t, err)
+			_, err = client.ChatCompletion(context.Background(), ChatRequest{Model: "gemini-2.5-pro", UserMessage: "hi"})
+
+			var apiErr *GeminiError
+			require.ErrorAs(t, err, &apiErr)
+			assert.NotEmpty(t, apiErr.Status)
+			assert.Equal(t, tt.want, ClassifyError(err))
+		})
+	}
+}
+
+func TestGeminiChatCompletionStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/models/gemini-2.5-flash:streamGenerateContent", r.URL.Path)
+		assert.Equal(t, "sse", r.URL.Query().Get("alt"))
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `data: {"candidates":[{"content":{"role":"model","parts":[{"text":"Hello"}]}}],"usageMetadata":{"promptTokenCount":20,"candidatesTokenCount":1}}
+
+data: {"candidates":[{"content":{"role":"model","parts":[{"text":" world"}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":20,"candidatesTokenCount":7}}
+
+`)
+	}))
+	defer srv.Close()
+
+	client, err := NewGeminiClient(WithBaseURL(srv.URL))
+	require.NoError(t, err)
+	stream, err := client.ChatCompletionStream(context.Background(), ChatRequest{Model: "gemini-2.5-flash", UserMessage: "hi"})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	var content string
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		content += chunk
+	}
+	assert.Equal(t, "Hello world", content)
+	assert.Equal(t, Usage{PromptTokens: 20, CompletionTokens: 7, TotalTokens: 27}, stream.Usage())
+	assert.Equal(t, FinishReasonStop, stream.FinishReason())
+}
+
+func TestBuildGeminiRequestMessages(t *testing.T) {
+	req := buildGeminiRequest(ChatRequest{
+		Model:         "gemini-2.5-flash",
+		SystemMessage: "system",
+		Messages:      []Message{{Role: RoleUser, Content: "hi"}, {Role: RoleAssistant, Content: "hello"}},
+		UserMessage:   "bye",
+	})
+
+	require.NotNil(t, req.SystemInstruction)
+	assert.Equal(t, []geminiPart{{Text: "system"}}, req.SystemInstruction.Parts)
+	assert.Equal(t, []geminiContent{
+		{Role: "user", Parts: []geminiPart{{Text: "hi"}}},
+		{Role: "model", Parts: []geminiPart{{Text: "hello"}}},
+		{Role: "user", Parts: []geminiPart{{Text: "bye"}}},
+	}, req.Contents)
+}
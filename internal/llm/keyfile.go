@@ -0,0 +1,83 @@
+package llm
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WithAPIKeyFile reads the API key from a file, e.g. a mounted Kubernetes
+// Secret, instead of taking it from WithAPIKey. The file is read again when
+// it changes, so that a rotated key is used without restarting.
+func WithAPIKeyFile(path string) Option {
+	return func(c *clientConfig) {
+		c.apiKeyFile = path
+	}
+}
+
+// keyFile is an API key file, re-read when its size or modification time
+// changes. Kubernetes updates mounted Secrets by swapping a symlink, which
+// os.Stat follows.
+type keyFile struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	key     string
+}
+
+func (k *keyFile) get() (string, error) {
+	info, err := os.Stat(k.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read API key file: %w", err)
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.key != "" && info.ModTime().Equal(k.modTime) && info.Size() == k.size {
+		return k.key, nil
+	}
+	data, err := os.ReadFile(k.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read API key file: %w", err)
+	}
+	key := strings.TrimSpace(string(data))
+	if key == "" {
+		return "", fmt.Errorf("API key file %s is empty", k.path)
+	}
+	k.key, k.modTime, k.size = key, info.ModTime(), info.Size()
+	return key, nil
+}
+
+// keyTransport authenticates requests with the current key of a keyFile.
+type keyTransport struct {
+	next   http.RoundTripper
+	key    *keyFile
+	header string
+	prefix string
+}
+
+func (t *keyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key, err := t.key.get()
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set(t.header, t.prefix+key)
+	return t.next.RoundTrip(req)
+}
+
+// apiKeyTransport returns a wrap for httpClient that sends the key of the
+// API key file in header, after prefix; nil without an API key file.
+func (c *clientConfig) apiKeyTransport(header, prefix string) func(http.RoundTripper) http.RoundTripper {
+	if c.apiKeyFile == "" {
+		return nil
+	}
+	key := &keyFile{path: c.apiKeyFile}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return &keyTransport{next: next, key: key, header: header, prefix: prefix}
+	}
+}
@@ -0,0 +1,64 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithAPIKeyFile(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get("Authorization"))
+		fmt.Fprint(w, `{"model":"m","choices":[{"index":0,"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`)
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "api-key")
+	require.NoError(t, os.WriteFile(path, []byte("first\n"), 0o600))
+	client := NewOpenAIClient(WithBaseURL(srv.URL), WithAPIKey("ignored"), WithAPIKeyFile(path))
+
+	ctx := context.Background()
+	_, err := client.ChatCompletion(ctx, ChatRequest{Model: "m", UserMessage: "q"})
+	require.NoError(t, err)
+
+	// A rotated key is used by the next request.
+	require.NoError(t, os.WriteFile(path, []byte("second"), 0o600))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+	_, err = client.ChatCompletion(ctx, ChatRequest{Model: "m", UserMessage: "q"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Bearer first", "Bearer second"}, got)
+
+	require.NoError(t, os.Remove(path))
+	_, err = client.ChatCompletion(ctx, ChatRequest{Model: "m", UserMessage: "q"})
+	assert.ErrorContains(t, err, "failed to read API key file")
+}
+
+func TestWithAPIKeyFileAnthropic(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.Header.Get("X-Api-Key"))
+		fmt.Fprint(w, `{"model":"claude","content":[{"type":"text","text":"ok"}],"stop_reason":"end_turn","usage":{"input_tokens":1,"output_tokens":1}}`)
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "api-key")
+	require.NoError(t, os.WriteFile(path, []byte("secret"), 0o600))
+	resp, err := NewAnthropicClient(WithBaseURL(srv.URL), WithAPIKeyFile(path)).ChatCompletion(context.Background(), ChatRequest{Model: "claude", UserMessage: "q"})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp.Content)
+}
+
+func TestKeyFileEmpty(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api-key")
+	require.NoError(t, os.WriteFile(path, []byte(" \n"), 0o600))
+	_, err := (&keyFile{path: path}).get()
+	assert.ErrorContains(t, err, "is empty")
+}
@@ -0,0 +1,209 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// maxLoggedBody caps the bytes of a request or response body kept in an
+// Exchange.
+const maxLoggedBody = 4 << 20
+
+// redactedHeaders carry credentials and are never logged.
+var redactedHeaders = []string{"Authorization", "X-Api-Key", "X-Goog-Api-Key", "Api-Key", "Cookie", "Proxy-Authorization"}
+
+// Exchange is an HTTP request to an LLM API and its response, with
+// credentials redacted.
+type Exchange struct {
+	Time            time.Time       `json:"time"`
+	Duration        time.Duration   `json:"duration"`
+	Method          string          `json:"method"`
+	URL             string          `json:"url"`
+	RequestHeaders  http.Header     `json:"request_headers,omitempty"`
+	RequestBody     json.RawMessage `json:"request_body,omitempty"`
+	Status          int             `json:"status,omitempty"`
+	ResponseHeaders http.Header     `json:"response_headers,omitempty"`
+	ResponseBody    json.RawMessage `json:"response_body,omitempty"`
+	Error           string          `json:"error,omitempty"`
+}
+
+// RequestLogger receives every exchange of a client once its response has
+// been read; ctx is the context of the request.
+type RequestLogger func(ctx context.Context, ex *Exchange)
+
+// WithRequestLogger logs the full payloads of every request, including each
+// retry attempt, with logger.
+func WithRequestLogger(logger RequestLogger) Option {
+	return func(c *clientConfig) {
+		c.requestLogger = logger
+	}
+}
+
+// loggingTransport passes exchanges to a RequestLogger.
+type loggingTransport struct {
+	next   http.RoundTripper
+	logger RequestLogger
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ex := &Exchange{
+		Time:           time.Now(),
+		Method:         req.Method,
+		URL:            redactURL(req.URL),
+		RequestHeaders: redactHeaders(req.Header),
+	}
+	if req.Body != nil && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(io.LimitReader(body, maxLoggedBody))
+			body.Close()
+			ex.RequestBody = payload(data)
+		}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		ex.Duration = time.Since(ex.Time)
+		ex.Error = err.Error()
+		t.logger(req.Context(), ex)
+		return nil, err
+	}
+	ex.Status = resp.StatusCode
+	ex.ResponseHeaders = redactHeaders(resp.Header)
+	resp.Body = &loggedBody{ReadCloser: resp.Body, done: func(data []byte, err error) {
+		ex.Duration = time.Since(ex.Time)
+		ex.ResponseBody = payload(data)
+		if err != nil && !errors.Is(err, io.EOF) {
+			ex.Error = err.Error()
+		}
+		t.logger(req.Context(), ex)
+	}}
+	return resp, nil
+}
+
+// loggedBody records a response body as it is read, also for streams, and
+// reports it once when the body is closed.
+type loggedBody struct {
+	io.ReadCloser
+	buf      bytes.Buffer
+	err      error
+	done     func(data []byte, err error)
+	reported bool
+}
+
+func (b *loggedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if room := maxLoggedBody - b.buf.Len(); room > 0 {
+		b.buf.Write(p[:min(n, room)])
+	}
+	if err != nil {
+		b.err = err
+	}
+	return n, err
+}
+
+func (b *loggedBody) Close() error {
+	err := b.ReadCloser.Close()
+	if !b.reported {
+		b.reported = true
+		b.done(b.buf.Bytes(), b.err)
+	}
+	return err
+}
+
+// payload returns a JSON body as is and any other body as a JSON string.
+func payload(data []byte) json.RawMessage {
+	if len(data) == 0 {
+		return nil
+	}
+	if json.Valid(data) {
+		return append(json.RawMessage(nil), data...)
+	}
+	quoted, _ := json.Marshal(string(data))
+	return quoted
+}
+
+func redactHeaders(h http.Header) http.Header {
+	out := h.Clone()
+	for _, name := range redactedHeaders {
+		if out.Get(name) != "" {
+			out.Set(name, "REDACTED")
+		}
+	}
+	return out
+}
+
+func redactURL(u *url.URL) string {
+	q := u.Query()
+	if q.Get("key") == "" {
+		return u.String()
+	}
+	q.Set("key", "REDACTED")
+	redacted := *u
+	redacted.RawQuery = q.Encode()
+	return redacted.String()
+}
+
+type transcriptKey struct{}
+
+type transcript struct {
+	dir, name string
+}
+
+// WithTranscript returns a context whose requests TranscriptLogger records
+// in dir, as <name>.json, or <name>-<n>.json for further requests such as
+// retries.
+func WithTranscript(ctx context.Context, dir, name string) context.Context {
+	return context.WithValue(ctx, transcriptKey{}, transcript{dir: dir, name: name})
+}
+
+// TranscriptLogger is a RequestLogger that writes exchanges to the
+// transcript of their context (see WithTranscript). Exchanges outside a
+// transcript are logged at debug level.
+func TranscriptLogger(ctx context.Context, ex *Exchange) {
+	t, ok := ctx.Value(transcriptKey{}).(transcript)
+	if !ok {
+		slog.Debug("llm request", "method", ex.Method, "url", ex.URL, "status", ex.Status, "duration", ex.Duration, "error", ex.Error)
+		return
+	}
+	if err := writeTranscript(t, ex); err != nil {
+		slog.Warn("failed to write llm transcript", "dir", t.dir, "name", t.name, "error", err)
+	}
+}
+
+func writeTranscript(t transcript, ex *Exchange) error {
+	data, err := json.MarshalIndent(ex, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal exchange: %w", err)
+	}
+	if err := os.MkdirAll(t.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create transcript directory: %w", err)
+	}
+	for n := 1; ; n++ {
+		name := t.name + ".json"
+		if n > 1 {
+			name = fmt.Sprintf("%s-%d.json", t.name, n)
+		}
+		f, err := os.OpenFile(filepath.Join(t.dir, name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(data)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		return err
+	}
+}
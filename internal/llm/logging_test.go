@@ -0,0 +1,103 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exchangeRecorder collects the exchanges of a client.
+type exchangeRecorder struct {
+	mu        sync.Mutex
+	exchanges []*Exchange
+}
+
+func (r *exchangeRecorder) log(_ context.Context, ex *Exchange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.exchanges = append(r.exchanges, ex)
+}
+
+func TestRequestLogger(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, chatCompletionBody)
+	}))
+	defer srv.Close()
+
+	rec := &exchangeRecorder{}
+	client := NewOpenAIClient(WithBaseURL(srv.URL), WithAPIKey("sk-secret"), WithRequestLogger(rec.log))
+	_, err := client.ChatCompletion(context.Background(), ChatRequest{Model: "mistral", UserMessage: "hi"})
+	require.NoError(t, err)
+
+	require.Len(t, rec.exchanges, 1)
+	ex := rec.exchanges[0]
+	assert.Equal(t, http.MethodPost, ex.Method)
+	assert.Equal(t, srv.URL+"/chat/completions", ex.URL)
+	assert.Equal(t, "REDACTED", ex.RequestHeaders.Get("Authorization"))
+	assert.Equal(t, http.StatusOK, ex.Status)
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(ex.RequestBody, &body))
+	assert.Equal(t, "mistral", body["model"])
+	assert.JSONEq(t, chatCompletionBody, string(ex.ResponseBody))
+
+	data, err := json.Marshal(ex)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "sk-secret")
+}
+
+func TestRequestLoggerStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n")
+	}))
+	defer srv.Close()
+
+	rec := &exchangeRecorder{}
+	client := NewAnthropicClient(WithBaseURL(srv.URL), WithAPIKey("sk-secret"), WithRequestLogger(rec.log))
+	stream, err := client.ChatCompletionStream(context.Background(), ChatRequest{Model: "claude", UserMessage: "hi"})
+	require.NoError(t, err)
+	_, err = CollectStream(stream)
+	require.NoError(t, err)
+
+	require.Len(t, rec.exchanges, 1, "the stream is logged once it is closed")
+	ex := rec.exchanges[0]
+	assert.Equal(t, "REDACTED", ex.RequestHeaders.Get("X-Api-Key"))
+	var body string
+	require.NoError(t, json.Unmarshal(ex.ResponseBody, &body), "non-JSON bodies are logged as strings")
+	assert.Contains(t, body, "message_stop")
+}
+
+func TestRedactURL(t *testing.T) {
+	u, err := url.Parse("https://generativelanguage.googleapis.com/v1beta/models/gemini:generateContent?key=secret&alt=sse")
+	require.NoError(t, err)
+	assert.Equal(t, "https://generativelanguage.googleapis.com/v1beta/models/gemini:generateContent?alt=sse&key=REDACTED", redactURL(u))
+}
+
+func TestTranscriptLogger(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "transcripts", "mistral")
+	ctx := WithTranscript(context.Background(), dir, "q1")
+
+	TranscriptLogger(ctx, &Exchange{Method: http.MethodPost, Status: http.StatusServiceUnavailable})
+	TranscriptLogger(ctx, &Exchange{Method: http.MethodPost, Status: http.StatusOK})
+
+	data, err := os.ReadFile(filepath.Join(dir, "q1.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"status": 503`)
+
+	data, err = os.ReadFile(filepath.Join(dir, "q1-2.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"status": 200`)
+
+	// Without a transcript, exchanges are only logged.
+	TranscriptLogger(context.Background(), &Exchange{Method: http.MethodPost})
+}
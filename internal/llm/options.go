@@ -1,15 +1,85 @@
 package llm
 
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
 // Float64Ptr returns a pointer to the given float64 value.
 // Useful for constructing ChatRequest with an explicit temperature.
 func Float64Ptr(v float64) *float64 {
 	return &v
 }
 
+// Supported values for ChatRequest.ReasoningEffort.
+const (
+	ReasoningEffortLow    = "low"
+	ReasoningEffortMedium = "medium"
+	ReasoningEffortHigh   = "high"
+)
+
+// ValidateReasoningEffort returns an error if effort is not empty and not one
+// of the supported reasoning effort levels.
+func ValidateReasoningEffort(effort string) error {
+	switch effort {
+	case "", ReasoningEffortLow, ReasoningEffortMedium, ReasoningEffortHigh:
+		return nil
+	default:
+		return fmt.Errorf("invalid reasoning effort %q (supported: low, medium, high)", effort)
+	}
+}
+
+// Supported LLM API providers.
+const (
+	ProviderOpenAI    = "openai"
+	ProviderAnthropic = "anthropic"
+	ProviderGemini    = "gemini"
+	ProviderVertexAI  = "vertexai"
+	ProviderAzure     = "azure"
+)
+
+// ValidateProvider returns an error if provider is not empty and not one of
+// the supported providers.
+func ValidateProvider(provider string) error {
+	switch provider {
+	case "", ProviderOpenAI, ProviderAnthropic, ProviderGemini, ProviderVertexAI, ProviderAzure:
+		return nil
+	default:
+		return fmt.Errorf("invalid provider %q (supported: openai, anthropic, gemini, vertexai, azure)", provider)
+	}
+}
+
 // clientConfig holds configuration for an LLM client.
 type clientConfig struct {
-	baseURL string
-	apiKey  string
+	baseURL    string
+	apiKey     string
+	apiKeyFile string // see WithAPIKeyFile
+
+	// Vertex AI project and location of a GeminiClient (see WithVertexAI).
+	vertex         bool
+	vertexProject  string
+	vertexLocation string
+
+	// Azure OpenAI deployment and API version of an OpenAIClient (see
+	// WithAzure).
+	azure           bool
+	azureDeployment string
+	azureAPIVersion string
+
+	// HTTP settings (see WithHTTPTimeout, WithRetry, WithProxy, WithTransport).
+	httpTimeout  time.Duration
+	maxRetries   int
+	retryBackoff time.Duration
+	proxy        *url.URL
+	transport    http.RoundTripper
+
+	// requestLogger receives every exchange (see WithRequestLogger).
+	requestLogger RequestLogger
+
+	// rateLimiter throttles the requests of the client (see WithRateLimiter).
+	rateLimiter *RateLimiter
 }
 
 // Option is a functional option for configuring an LLM client.
@@ -28,3 +98,13 @@ func WithAPIKey(key string) Option {
 		c.apiKey = key
 	}
 }
+
+// WithVertexAI makes a GeminiClient call Vertex AI in the given project and
+// location (default: DefaultVertexLocation) instead of the Gemini API.
+func WithVertexAI(project, location string) Option {
+	return func(c *clientConfig) {
+		c.vertex = true
+		c.vertexProject = project
+		c.vertexLocation = location
+	}
+}
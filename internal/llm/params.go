@@ -0,0 +1,68 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// withExtraParams sets the top-level fields of extra in the JSON object
+// body, replacing fields of the same name.
+func withExtraParams(body []byte, extra map[string]any) ([]byte, error) {
+	if len(extra) == 0 {
+		return body, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, fmt.Errorf("failed to add extra params: %w", err)
+	}
+	for k, v := range extra {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add extra param %q: %w", k, err)
+		}
+		fields[k] = raw
+	}
+	return json.Marshal(fields)
+}
+
+type extraParamsKey struct{}
+
+// contextWithExtraParams returns a context whose requests get the extra
+// params added by extraParamsTransport, for clients that do not marshal
+// their requests themselves.
+func contextWithExtraParams(ctx context.Context, extra map[string]any) context.Context {
+	if len(extra) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, extraParamsKey{}, extra)
+}
+
+// extraParamsTransport adds the extra params of a request's context to its
+// JSON body.
+type extraParamsTransport struct {
+	next http.RoundTripper
+}
+
+func (t *extraParamsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	extra, _ := req.Context().Value(extraParamsKey{}).(map[string]any)
+	if len(extra) == 0 || req.Body == nil {
+		return t.next.RoundTrip(req)
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if body, err = withExtraParams(body, extra); err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+	req.ContentLength = int64(len(body))
+	return t.next.RoundTrip(req)
+}
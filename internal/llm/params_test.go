@@ -0,0 +1,82 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// payloadServer answers chat requests of every provider and records the
+// payload of the last one.
+func payloadServer(t *testing.T, got *map[string]any) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*got = nil
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(got))
+		switch {
+		case r.URL.Path == "/messages":
+			fmt.Fprint(w, `{"content":[{"type":"text","text":"ok"}],"stop_reason":"end_turn"}`)
+		case r.URL.Path == "/models/gemini:generateContent":
+			fmt.Fprint(w, `{"candidates":[{"content":{"parts":[{"text":"ok"}]},"finishReason":"STOP"}]}`)
+		default:
+			fmt.Fprint(w, `{"choices":[{"index":0,"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestExtraParams(t *testing.T) {
+	var got map[string]any
+	srv := payloadServer(t, &got)
+	extra := map[string]any{
+		"chat_template_kwargs": map[string]any{"enable_thinking": true},
+		"max_tokens":           float64(64),
+	}
+
+	for _, tc := range []struct {
+		provider, model string
+		built           string // a field set from the request
+	}{
+		{ProviderOpenAI, "deepseek-r1", "messages"},
+		{ProviderAnthropic, "claude", "messages"},
+		{ProviderGemini, "gemini", "contents"},
+	} {
+		client, err := NewClient(tc.provider, WithBaseURL(srv.URL), WithAPIKey("key"))
+		require.NoError(t, err)
+		_, err = client.ChatCompletion(context.Background(), ChatRequest{Model: tc.model, UserMessage: "hi", MaxTokens: 32, ExtraParams: extra})
+		require.NoError(t, err, tc.provider)
+		assert.Equal(t, extra["chat_template_kwargs"], got["chat_template_kwargs"], tc.provider)
+		assert.Equal(t, float64(64), got["max_tokens"], "%s: extra params replace fields", tc.provider)
+		assert.NotEmpty(t, got[tc.built], tc.provider)
+	}
+}
+
+func TestExtraParamsStream(t *testing.T) {
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"ok\"},\"finish_reason\":\"stop\"}]}\n\ndata: [DONE]\n\n")
+	}))
+	defer srv.Close()
+
+	stream, err := NewOpenAIClient(WithBaseURL(srv.URL), WithRetry(1, 0)).ChatCompletionStream(context.Background(), ChatRequest{Model: "m", UserMessage: "hi", ExtraParams: map[string]any{"top_k": 20}})
+	require.NoError(t, err)
+	content, err := CollectStream(stream)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", content)
+	assert.Equal(t, float64(20), got["top_k"])
+	assert.Equal(t, true, got["stream"])
+}
+
+func TestWithExtraParamsInvalid(t *testing.T) {
+	_, err := withExtraParams([]byte(`{}`), map[string]any{"f": func() {}})
+	assert.ErrorContains(t, err, `extra param "f"`)
+}
@@ -0,0 +1,230 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Priority classifies the requests sharing a RateLimiter, so that one kind
+// of traffic cannot starve another while they wait for the quota.
+type Priority string
+
+const (
+	// PriorityInteractive is the default: judge calls and other requests
+	// someone waits for.
+	PriorityInteractive Priority = "interactive"
+	// PriorityBulk is the evaluation of test suites by the runner.
+	PriorityBulk Priority = "bulk"
+)
+
+// ValidatePriority checks that p is a known priority.
+func ValidatePriority(p Priority) error {
+	switch p {
+	case PriorityInteractive, PriorityBulk:
+		return nil
+	}
+	return fmt.Errorf("invalid priority %q (want %s or %s)", p, PriorityInteractive, PriorityBulk)
+}
+
+type priorityKey struct{}
+
+// WithPriority returns a context whose requests wait for rate limiters
+// with priority p.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// priorityOf returns the priority of a context's requests.
+func priorityOf(ctx context.Context) Priority {
+	if p, ok := ctx.Value(priorityKey{}).(Priority); ok {
+		return p
+	}
+	return PriorityInteractive
+}
+
+// RateLimiter keeps the requests and tokens sent to a provider under a
+// per-minute quota with token buckets. It is safe for concurrent use, and
+// one limiter can be shared by several clients (see WithRateLimiter), e.g.
+// those of the runner and the scorer, to limit their aggregate throughput.
+//
+// The tokens of a request are only known from its response, so they are
+// charged afterwards: requests wait while the token bucket is in debt, and
+// requests started concurrently may together exceed the token quota.
+//
+// Waiting requests of different priorities (see WithPriority) are let
+// through in proportion to the weights of their priorities, equal unless
+// set with SetWeights, so that e.g. a burst of judge calls cannot starve the
+// evaluation of a test suite.
+type RateLimiter struct {
+	mu       sync.Mutex
+	requests bucket
+	tokens   bucket
+	now      func() time.Time
+
+	weights map[Priority]int
+	waiting map[Priority]int     // waiting requests per priority
+	pass    map[Priority]float64 // grants per priority, divided by its weight
+	changed chan struct{}        // closed when the next request to let through may change
+}
+
+// bucket is a token bucket refilling at rate per minute up to rate.
+type bucket struct {
+	rate   float64 // zero means unlimited
+	level  float64
+	filled time.Time
+}
+
+func (b *bucket) refill(now time.Time) {
+	b.level = math.Min(b.rate, b.level+b.rate*now.Sub(b.filled).Minutes())
+	b.filled = now
+}
+
+// until returns how long the bucket takes to reach level.
+func (b *bucket) until(level float64) time.Duration {
+	if b.rate == 0 || b.level >= level {
+		return 0
+	}
+	return time.Duration((level - b.level) / b.rate * float64(time.Minute))
+}
+
+// NewRateLimiter returns a limiter allowing requestsPerMinute requests and
+// tokensPerMinute tokens (prompt and completion); zero means unlimited. A
+// full minute's quota may be spent at once.
+func NewRateLimiter(requestsPerMinute, tokensPerMinute int) *RateLimiter {
+	now := time.Now()
+	return &RateLimiter{
+		requests: bucket{rate: float64(requestsPerMinute), level: float64(requestsPerMinute), filled: now},
+		tokens:   bucket{rate: float64(tokensPerMinute), level: float64(tokensPerMinute), filled: now},
+		now:      time.Now,
+		weights:  make(map[Priority]int),
+		waiting:  make(map[Priority]int),
+		pass:     make(map[Priority]float64),
+		changed:  make(chan struct{}),
+	}
+}
+
+// SetWeights sets the share of the quota of waiting requests by priority;
+// priorities without a positive weight have weight 1.
+func (l *RateLimiter) SetWeights(weights map[Priority]int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	clear(l.weights)
+	for p, w := range weights {
+		if w > 0 {
+			l.weights[p] = w
+		}
+	}
+}
+
+func (l *RateLimiter) weight(p Priority) float64 {
+	if w, ok := l.weights[p]; ok {
+		return float64(w)
+	}
+	return 1
+}
+
+// next returns the priority whose waiting request goes next: the one with
+// the fewest grants relative to its weight.
+func (l *RateLimiter) next() Priority {
+	var next Priority
+	for p, n := range l.waiting {
+		if n == 0 {
+			continue
+		}
+		if next == "" || l.pass[p] < l.pass[next] || (l.pass[p] == l.pass[next] && p < next) {
+			next = p
+		}
+	}
+	return next
+}
+
+// notify wakes the waiting requests to check whether they are next.
+func (l *RateLimiter) notify() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// WithRateLimiter makes a client wait for limiter before every request and
+// charge it the tokens the request used.
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(c *clientConfig) {
+		c.rateLimiter = limiter
+	}
+}
+
+// Wait blocks until a request may be sent or ctx is done. A nil limiter
+// never waits.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	p := priorityOf(ctx)
+
+	l.mu.Lock()
+	if l.waiting[p] == 0 {
+		// A priority that was idle does not get to catch up on the grants
+		// it missed.
+		if next := l.next(); next != "" {
+			l.pass[p] = max(l.pass[p], l.pass[next])
+		}
+	}
+	l.waiting[p]++
+	defer func() {
+		l.mu.Lock()
+		l.waiting[p]--
+		l.notify()
+		l.mu.Unlock()
+	}()
+
+	for {
+		now := l.now()
+		l.requests.refill(now)
+		l.tokens.refill(now)
+		wait := max(l.requests.until(1), l.tokens.until(0))
+		if wait == 0 && l.next() == p {
+			if l.requests.rate > 0 {
+				l.requests.level--
+			}
+			l.pass[p] += 1 / l.weight(p)
+			l.mu.Unlock()
+			return nil
+		}
+		changed := l.changed
+		l.mu.Unlock()
+
+		// Wait for the quota, or for requests of other priorities to go
+		// first.
+		var refilled <-chan time.Time
+		timer := time.NewTimer(wait)
+		if wait > 0 {
+			refilled = timer.C
+		}
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-refilled:
+		case <-changed:
+		}
+		timer.Stop()
+		l.mu.Lock()
+	}
+}
+
+// Charge records the tokens used by a request.
+func (l *RateLimiter) Charge(usage Usage) {
+	if l == nil || l.tokens.rate == 0 {
+		return
+	}
+	tokens := usage.TotalTokens
+	if tokens == 0 {
+		tokens = usage.PromptTokens + usage.CompletionTokens
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens.refill(l.now())
+	l.tokens.level -= float64(tokens)
+}
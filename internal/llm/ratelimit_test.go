@@ -0,0 +1,163 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// shortContext returns a context that expires before a limiter refills.
+func shortContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func TestRateLimiterRequests(t *testing.T) {
+	l := NewRateLimiter(2, 0)
+	now := time.Now()
+	l.now = func() time.Time { return now }
+
+	require.NoError(t, l.Wait(context.Background()))
+	require.NoError(t, l.Wait(context.Background()))
+	assert.ErrorIs(t, l.Wait(shortContext(t)), context.DeadlineExceeded)
+
+	now = now.Add(30 * time.Second) // refills one request
+	require.NoError(t, l.Wait(context.Background()))
+	assert.ErrorIs(t, l.Wait(shortContext(t)), context.DeadlineExceeded)
+}
+
+func TestRateLimiterTokens(t *testing.T) {
+	l := NewRateLimiter(0, 1000)
+	now := time.Now()
+	l.now = func() time.Time { return now }
+
+	require.NoError(t, l.Wait(context.Background()))
+	l.Charge(Usage{PromptTokens: 1000, CompletionTokens: 500})
+	assert.ErrorIs(t, l.Wait(shortContext(t)), context.DeadlineExceeded, "the bucket is in debt")
+
+	now = now.Add(20 * time.Second)
+	assert.ErrorIs(t, l.Wait(shortContext(t)), context.DeadlineExceeded)
+	now = now.Add(10 * time.Second) // repaid
+	require.NoError(t, l.Wait(context.Background()))
+}
+
+func TestRateLimiterPriorities(t *testing.T) {
+	l := NewRateLimiter(60000, 0) // a request per millisecond
+	l.SetWeights(map[Priority]int{PriorityInteractive: 3})
+	now := time.Now()
+	l.now = func() time.Time { return now }
+	l.requests.level = 0
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	granted := make(chan Priority, 16)
+	var wg sync.WaitGroup
+	for _, p := range []Priority{PriorityBulk, PriorityInteractive} {
+		for range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if l.Wait(WithPriority(ctx, p)) == nil {
+					granted <- p
+				}
+			}()
+		}
+	}
+	require.Eventually(t, func() bool {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return l.waiting[PriorityBulk]+l.waiting[PriorityInteractive] == 16
+	}, time.Second, time.Millisecond)
+
+	// Refill 8 requests: 3 interactive ones go for every bulk one.
+	l.mu.Lock()
+	now = now.Add(8 * time.Millisecond)
+	l.mu.Unlock()
+	counts := map[Priority]int{}
+	for range 8 {
+		counts[<-granted]++
+	}
+	assert.Equal(t, map[Priority]int{PriorityInteractive: 6, PriorityBulk: 2}, counts)
+
+	cancel()
+	wg.Wait()
+	assert.Empty(t, granted)
+}
+
+func TestValidatePriority(t *testing.T) {
+	assert.NoError(t, ValidatePriority(PriorityBulk))
+	assert.ErrorContains(t, ValidatePriority("urgent"), "invalid priority")
+}
+
+func TestNilRateLimiter(t *testing.T) {
+	var l *RateLimiter
+	require.NoError(t, l.Wait(context.Background()))
+	l.Charge(Usage{TotalTokens: 10})
+}
+
+func TestClientRateLimiter(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		fmt.Fprint(w, chatCompletionBody)
+	}))
+	defer srv.Close()
+
+	r, err := NewRegistry(map[string]ProviderConfig{
+		"local": {Type: ProviderOpenAI, Endpoint: srv.URL, RequestsPerMinute: 1},
+	})
+	require.NoError(t, err)
+	runClient, err := r.Client("local")
+	require.NoError(t, err)
+	judgeClient, err := r.Client("local")
+	require.NoError(t, err)
+
+	_, err = runClient.ChatCompletion(context.Background(), ChatRequest{Model: "mistral", UserMessage: "hi"})
+	require.NoError(t, err)
+	_, err = judgeClient.ChatCompletion(shortContext(t), ChatRequest{Model: "mistral", UserMessage: "hi"})
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "clients of a provider share its limit")
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestTimeToFirstTokenExcludesRateLimitWait(t *testing.T) {
+	bodies := map[string]string{
+		ProviderOpenAI:    "data: {\"choices\":[{\"delta\":{\"content\":\"hi\"}}]}\n\ndata: [DONE]\n\n",
+		ProviderAnthropic: "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"hi\"}}\n\nevent: message_stop\ndata: {\"type\":\"message_stop\"}\n\n",
+		ProviderGemini:    "data: {\"candidates\":[{\"content\":{\"role\":\"model\",\"parts\":[{\"text\":\"hi\"}]},\"finishReason\":\"STOP\"}]}\n\n",
+	}
+	for provider, body := range bodies {
+		t.Run(provider, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				fmt.Fprint(w, body)
+			}))
+			defer srv.Close()
+
+			// An empty bucket refilling at 10 requests a second lets the
+			// request through after 100ms.
+			limiter := NewRateLimiter(600, 0)
+			limiter.requests.level = 0
+			client, err := NewClient(provider, WithBaseURL(srv.URL), WithRateLimiter(limiter))
+			require.NoError(t, err)
+
+			start := time.Now()
+			stream, err := client.ChatCompletionStream(context.Background(), ChatRequest{Model: "m", UserMessage: "hi"})
+			require.NoError(t, err)
+			defer stream.Close()
+			_, err = stream.Recv()
+			require.NoError(t, err)
+
+			require.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond, "the request waited for the limiter")
+			assert.Less(t, stream.TimeToFirstToken(), 90*time.Millisecond)
+		})
+	}
+}
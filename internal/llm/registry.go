@@ -0,0 +1,370 @@
+package llm
+
+import (
+	"cmp"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// providerEnvPrefix prefixes the environment variables that configure
+// providers, e.g. LLM_PROVIDER_CLAUDE_TYPE=anthropic.
+const providerEnvPrefix = "LLM_PROVIDER_"
+
+// ProviderConfig configures a named LLM provider.
+type ProviderConfig struct {
+	// Type is the API of the provider: openai, anthropic, gemini, vertexai,
+	// or azure.
+	Type string `yaml:"type" json:"type"`
+
+	// Endpoint is the base URL of the API (default: the type's public API;
+	// for openai, a local vLLM server).
+	Endpoint string `yaml:"endpoint" json:"endpoint,omitempty"`
+
+	// APIKeyEnv names the environment variable holding the API key, so that
+	// keys stay out of the config file. Providers named like their type
+	// default to the type's conventional variable, e.g. ANTHROPIC_API_KEY;
+	// other providers send no key unless they name one, so that a key is
+	// never sent to an endpoint it was not meant for.
+	APIKeyEnv string `yaml:"api_key_env" json:"api_key_env,omitempty"`
+
+	// APIKeyFile names a file holding the API key instead, e.g. a mounted
+	// Kubernetes Secret; a changed key is picked up without a restart.
+	APIKeyFile string `yaml:"api_key_file" json:"api_key_file,omitempty"`
+
+	// Project and Location select the Vertex AI project and region
+	// (default: GOOGLE_CLOUD_PROJECT and GOOGLE_CLOUD_LOCATION).
+	Project  string `yaml:"project" json:"project,omitempty"`
+	Location string `yaml:"location" json:"location,omitempty"`
+
+	// Deployment and APIVersion route requests to an Azure OpenAI deployment
+	// (default: the deployment named like the model, and
+	// DefaultAzureAPIVersion or OPENAI_API_VERSION). The endpoint of azure
+	// defaults to AZURE_OPENAI_ENDPOINT.
+	Deployment string `yaml:"deployment" json:"deployment,omitempty"`
+	APIVersion string `yaml:"api_version" json:"api_version,omitempty"`
+
+	// RequestsPerMinute and TokensPerMinute limit the aggregate throughput
+	// of all clients of the provider, e.g. of a test run and its judge, to
+	// stay under the provider's quota (default: unlimited).
+	RequestsPerMinute int `yaml:"requests_per_minute" json:"requests_per_minute,omitempty"`
+	TokensPerMinute   int `yaml:"tokens_per_minute" json:"tokens_per_minute,omitempty"`
+
+	// PriorityWeights share the rate limits among waiting requests by
+	// priority, e.g. {bulk: 3, interactive: 1} to favour test runs over
+	// judges (default: equal shares).
+	PriorityWeights map[Priority]int `yaml:"priority_weights" json:"priority_weights,omitempty"`
+
+	// Timeout limits each request including reading its response, and
+	// MaxRetries retries requests failing with a network error, 429, or a
+	// 5xx status, waiting RetryBackoff (default: DefaultRetryBackoff) before
+	// the first retry (see WithHTTPTimeout and WithRetry). Proxy is the URL
+	// of an HTTP(S) proxy to use instead of HTTP_PROXY and HTTPS_PROXY.
+	Timeout      time.Duration `yaml:"timeout" json:"timeout,omitempty"`
+	MaxRetries   int           `yaml:"max_retries" json:"max_retries,omitempty"`
+	RetryBackoff time.Duration `yaml:"retry_backoff" json:"retry_backoff,omitempty"`
+	Proxy        string        `yaml:"proxy" json:"proxy,omitempty"`
+}
+
+// apiKey returns the API key of the provider named name from the
+// environment.
+func (c ProviderConfig) apiKey(name string) string {
+	if c.APIKeyEnv != "" {
+		return os.Getenv(c.APIKeyEnv)
+	}
+	if name != c.Type {
+		return ""
+	}
+	switch c.Type {
+	case ProviderAnthropic:
+		return os.Getenv("ANTHROPIC_API_KEY")
+	case ProviderGemini:
+		if key := os.Getenv("GEMINI_API_KEY"); key != "" {
+			return key
+		}
+		return os.Getenv("GOOGLE_API_KEY")
+	case ProviderVertexAI:
+		return "" // Vertex AI authenticates with Google credentials.
+	case ProviderAzure:
+		return os.Getenv("AZURE_OPENAI_API_KEY")
+	default:
+		return os.Getenv("OPENAI_API_KEY")
+	}
+}
+
+// options returns the client options of the provider named name.
+func (c ProviderConfig) options(name string) []Option {
+	var opts []Option
+	if c.Type == ProviderVertexAI {
+		project, location := c.Project, c.Location
+		if project == "" {
+			project = os.Getenv("GOOGLE_CLOUD_PROJECT")
+		}
+		if location == "" {
+			location = os.Getenv("GOOGLE_CLOUD_LOCATION")
+		}
+		opts = append(opts, WithVertexAI(project, location))
+	}
+	if c.Type == ProviderAzure {
+		endpoint, version := c.Endpoint, c.APIVersion
+		if endpoint == "" {
+			endpoint = os.Getenv("AZURE_OPENAI_ENDPOINT")
+		}
+		if version == "" {
+			version = os.Getenv("OPENAI_API_VERSION")
+		}
+		opts = append(opts, WithAzure(endpoint, c.Deployment, version))
+	}
+	if c.Endpoint != "" {
+		opts = append(opts, WithBaseURL(c.Endpoint))
+	}
+	if c.APIKeyFile != "" {
+		opts = append(opts, WithAPIKeyFile(c.APIKeyFile))
+	} else if key := c.apiKey(name); key != "" {
+		opts = append(opts, WithAPIKey(key))
+	}
+	return opts
+}
+
+// httpOptions returns the options for the HTTP settings of the provider.
+func (c ProviderConfig) httpOptions() []Option {
+	var opts []Option
+	if c.Timeout > 0 {
+		opts = append(opts, WithHTTPTimeout(c.Timeout))
+	}
+	if c.MaxRetries > 0 {
+		opts = append(opts, WithRetry(c.MaxRetries, cmp.Or(c.RetryBackoff, DefaultRetryBackoff)))
+	}
+	if c.Proxy != "" {
+		if proxy, err := url.Parse(c.Proxy); err == nil { // validated by NewRegistry
+			opts = append(opts, WithProxy(proxy))
+		}
+	}
+	return opts
+}
+
+// validateHTTP checks the HTTP settings of the provider.
+func (c ProviderConfig) validateHTTP() error {
+	if c.Timeout < 0 || c.MaxRetries < 0 || c.RetryBackoff < 0 {
+		return fmt.Errorf("timeout, max_retries, and retry_backoff must not be negative")
+	}
+	if c.Proxy != "" {
+		proxy, err := url.Parse(c.Proxy)
+		if err != nil || proxy.Host == "" || (proxy.Scheme != "http" && proxy.Scheme != "https") {
+			return fmt.Errorf("invalid proxy %q: expected an http:// or https:// URL", c.Proxy)
+		}
+	}
+	return nil
+}
+
+// Registry maps provider names to their configuration, so that a run can
+// mix models across providers with separate endpoints and credentials.
+// The provider types (openai, anthropic, gemini, vertexai, azure) are always
+// available under their own names with default settings, unless configured
+// otherwise. A nil Registry only knows these.
+type Registry struct {
+	providers map[string]ProviderConfig
+	limiters  map[string]*RateLimiter
+}
+
+type registryFile struct {
+	Providers map[string]ProviderConfig `yaml:"providers"`
+}
+
+// NewRegistry builds a registry from named provider configs.
+func NewRegistry(providers map[string]ProviderConfig) (*Registry, error) {
+	r := &Registry{
+		providers: make(map[string]ProviderConfig, len(providers)),
+		limiters:  make(map[string]*RateLimiter),
+	}
+	for name, cfg := range providers {
+		if strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("provider without name")
+		}
+		if cfg.Type == "" && ValidateProvider(name) == nil {
+			cfg.Type = name // e.g. only the endpoint of "anthropic" is configured
+		}
+		if cfg.Type == "" {
+			return nil, fmt.Errorf("provider %q: type is required", name)
+		}
+		if err := ValidateProvider(cfg.Type); err != nil {
+			return nil, fmt.Errorf("provider %q: %w", name, err)
+		}
+		if cfg.RequestsPerMinute < 0 || cfg.TokensPerMinute < 0 {
+			return nil, fmt.Errorf("provider %q: rate limits must not be negative", name)
+		}
+		if err := cfg.validateHTTP(); err != nil {
+			return nil, fmt.Errorf("provider %q: %w", name, err)
+		}
+		for p, w := range cfg.PriorityWeights {
+			if err := ValidatePriority(p); err != nil {
+				return nil, fmt.Errorf("provider %q: %w", name, err)
+			}
+			if w <= 0 {
+				return nil, fmt.Errorf("provider %q: weight of priority %s must be positive", name, p)
+			}
+		}
+		r.providers[name] = cfg
+		if cfg.RequestsPerMinute > 0 || cfg.TokensPerMinute > 0 {
+			limiter := NewRateLimiter(cfg.RequestsPerMinute, cfg.TokensPerMinute)
+			limiter.SetWeights(cfg.PriorityWeights)
+			r.limiters[name] = limiter
+		}
+	}
+	return r, nil
+}
+
+// LoadRegistry reads providers from an optional YAML file of the form:
+//
+//	providers:
+//	  claude:
+//	    type: anthropic
+//	    api_key_env: TEAM_ANTHROPIC_KEY
+//	  local-vllm:
+//	    type: openai
+//	    endpoint: http://vllm.llm-testing.svc/v1
+//	    requests_per_minute: 600
+//	    priority_weights: {bulk: 3, interactive: 1}
+//	    timeout: 5m
+//	    max_retries: 3
+//
+// and from environ, in which LLM_PROVIDER_<NAME>_TYPE, _ENDPOINT,
+// _API_KEY_ENV, _API_KEY_FILE, _PROJECT, _LOCATION, _DEPLOYMENT, _API_VERSION,
+// _REQUESTS_PER_MINUTE, _TOKENS_PER_MINUTE, _PRIORITY_WEIGHTS (e.g.
+// bulk=3,interactive=1), _TIMEOUT, _MAX_RETRIES, _RETRY_BACKOFF, and _PROXY
+// configure the provider <name>
+// (lowercase, with underscores as dashes). Settings from the environment
+// take precedence over the file.
+func LoadRegistry(path string, environ []string) (*Registry, error) {
+	var f registryFile
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read provider registry: %w", err)
+		}
+		if err := yaml.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("failed to parse provider registry: %w", err)
+		}
+	}
+	if f.Providers == nil {
+		f.Providers = make(map[string]ProviderConfig)
+	}
+	if err := applyProviderEnv(f.Providers, environ); err != nil {
+		return nil, err
+	}
+	return NewRegistry(f.Providers)
+}
+
+// applyProviderEnv sets the provider settings of LLM_PROVIDER_* variables.
+func applyProviderEnv(providers map[string]ProviderConfig, environ []string) error {
+	fields := []struct {
+		suffix string
+		set    func(*ProviderConfig, string) error
+	}{
+		{"_API_KEY_ENV", func(c *ProviderConfig, v string) error { c.APIKeyEnv = v; return nil }},
+		{"_API_KEY_FILE", func(c *ProviderConfig, v string) error { c.APIKeyFile = v; return nil }},
+		{"_TYPE", func(c *ProviderConfig, v string) error { c.Type = v; return nil }},
+		{"_ENDPOINT", func(c *ProviderConfig, v string) error { c.Endpoint = v; return nil }},
+		{"_PROJECT", func(c *ProviderConfig, v string) error { c.Project = v; return nil }},
+		{"_LOCATION", func(c *ProviderConfig, v string) error { c.Location = v; return nil }},
+		{"_DEPLOYMENT", func(c *ProviderConfig, v string) error { c.Deployment = v; return nil }},
+		{"_API_VERSION", func(c *ProviderConfig, v string) error { c.APIVersion = v; return nil }},
+		{"_REQUESTS_PER_MINUTE", func(c *ProviderConfig, v string) (err error) {
+			c.RequestsPerMinute, err = strconv.Atoi(v)
+			return err
+		}},
+		{"_TOKENS_PER_MINUTE", func(c *ProviderConfig, v string) (err error) {
+			c.TokensPerMinute, err = strconv.Atoi(v)
+			return err
+		}},
+		{"_TIMEOUT", func(c *ProviderConfig, v string) (err error) {
+			c.Timeout, err = time.ParseDuration(v)
+			return err
+		}},
+		{"_MAX_RETRIES", func(c *ProviderConfig, v string) (err error) {
+			c.MaxRetries, err = strconv.Atoi(v)
+			return err
+		}},
+		{"_RETRY_BACKOFF", func(c *ProviderConfig, v string) (err error) {
+			c.RetryBackoff, err = time.ParseDuration(v)
+			return err
+		}},
+		{"_PROXY", func(c *ProviderConfig, v string) error { c.Proxy = v; return nil }},
+		{"_PRIORITY_WEIGHTS", func(c *ProviderConfig, v string) error {
+			c.PriorityWeights = make(map[Priority]int)
+			for _, pair := range strings.Split(v, ",") {
+				p, w, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if !ok {
+					return fmt.Errorf("want priority=weight, got %q", pair)
+				}
+				weight, err := strconv.Atoi(w)
+				if err != nil {
+					return err
+				}
+				c.PriorityWeights[Priority(p)] = weight
+			}
+			return nil
+		}},
+	}
+	for _, kv := range environ {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(key, providerEnvPrefix) {
+			continue
+		}
+		key = strings.TrimPrefix(key, providerEnvPrefix)
+		for _, f := range fields {
+			raw, ok := strings.CutSuffix(key, f.suffix)
+			if !ok || raw == "" {
+				continue
+			}
+			name := strings.ReplaceAll(strings.ToLower(raw), "_", "-")
+			cfg := providers[name]
+			if err := f.set(&cfg, value); err != nil {
+				return fmt.Errorf("invalid %s: %w", kv, err)
+			}
+			providers[name] = cfg
+			break
+		}
+	}
+	return nil
+}
+
+// Lookup returns the configuration of a provider: the configured one, or
+// the defaults of a provider type named name.
+func (r *Registry) Lookup(name string) (ProviderConfig, bool) {
+	if r != nil {
+		if cfg, ok := r.providers[name]; ok {
+			return cfg, true
+		}
+	}
+	if name != "" && ValidateProvider(name) == nil {
+		return ProviderConfig{Type: name}, true
+	}
+	return ProviderConfig{}, false
+}
+
+// Client creates a client for the named provider (default: ProviderOpenAI).
+// Options in opts, e.g. an endpoint or API key given on the command line,
+// override the provider's configuration, except for its HTTP settings,
+// which override the defaults of the command line. All clients of a provider with
+// rate limits share one RateLimiter.
+func (r *Registry) Client(name string, opts ...Option) (Client, error) {
+	if name == "" {
+		name = ProviderOpenAI
+	}
+	cfg, ok := r.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %q", name)
+	}
+	base := cfg.options(name)
+	if r != nil && r.limiters[name] != nil {
+		base = append(base, WithRateLimiter(r.limiters[name]))
+	}
+	base = append(base, opts...)
+	return NewClient(cfg.Type, append(base, cfg.httpOptions()...)...)
+}
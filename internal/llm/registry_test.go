@@ -0,0 +1,227 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "providers.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`providers:
+  claude:
+    type: anthropic
+    api_key_env: TEAM_ANTHROPIC_KEY
+  local-vllm:
+    type: openai
+    endpoint: http://vllm.llm-testing.svc/v1
+`), 0o600))
+
+	r, err := LoadRegistry(path, []string{
+		"LLM_PROVIDER_LOCAL_VLLM_ENDPOINT=http://localhost:8000/v1",
+		"LLM_PROVIDER_VERTEX_EU_TYPE=vertexai",
+		"LLM_PROVIDER_VERTEX_EU_LOCATION=europe-west4",
+		"LLM_PROVIDER_LOCAL_VLLM_PRIORITY_WEIGHTS=bulk=3, interactive=1",
+		"LLM_PROVIDER_ANTHROPIC_API_KEY_ENV=OTHER_ANTHROPIC_KEY",
+		"LLM_PROVIDER_ANTHROPIC_API_KEY_FILE=/var/run/secrets/anthropic/api-key",
+		"OPENAI_API_KEY=ignored",
+	})
+	require.NoError(t, err)
+
+	cfg, ok := r.Lookup("claude")
+	require.True(t, ok)
+	assert.Equal(t, ProviderConfig{Type: ProviderAnthropic, APIKeyEnv: "TEAM_ANTHROPIC_KEY"}, cfg)
+
+	cfg, ok = r.Lookup("local-vllm")
+	require.True(t, ok)
+	assert.Equal(t, "http://localhost:8000/v1", cfg.Endpoint, "environment overrides the file")
+	assert.Equal(t, map[Priority]int{PriorityBulk: 3, PriorityInteractive: 1}, cfg.PriorityWeights)
+
+	cfg, ok = r.Lookup("vertex-eu")
+	require.True(t, ok)
+	assert.Equal(t, ProviderConfig{Type: ProviderVertexAI, Location: "europe-west4"}, cfg)
+
+	cfg, ok = r.Lookup(ProviderAnthropic)
+	require.True(t, ok)
+	assert.Equal(t, ProviderConfig{Type: ProviderAnthropic, APIKeyEnv: "OTHER_ANTHROPIC_KEY", APIKeyFile: "/var/run/secrets/anthropic/api-key"}, cfg)
+}
+
+func TestLoadRegistryInvalid(t *testing.T) {
+	_, err := LoadRegistry("", []string{"LLM_PROVIDER_FOO_ENDPOINT=http://localhost"})
+	assert.ErrorContains(t, err, "type is required")
+
+	_, err = LoadRegistry("", []string{"LLM_PROVIDER_FOO_TYPE=bedrock"})
+	assert.ErrorContains(t, err, "invalid provider")
+
+	_, err = LoadRegistry("", []string{"LLM_PROVIDER_FOO_TYPE=openai", "LLM_PROVIDER_FOO_PRIORITY_WEIGHTS=urgent=2"})
+	assert.ErrorContains(t, err, "invalid priority")
+
+	_, err = LoadRegistry("", []string{"LLM_PROVIDER_FOO_TYPE=openai", "LLM_PROVIDER_FOO_PRIORITY_WEIGHTS=bulk"})
+	assert.ErrorContains(t, err, "want priority=weight")
+
+	_, err = LoadRegistry(filepath.Join(t.TempDir(), "missing.yaml"), nil)
+	assert.Error(t, err)
+}
+
+func TestRegistryLookupDefaults(t *testing.T) {
+	var r *Registry
+	cfg, ok := r.Lookup(ProviderGemini)
+	require.True(t, ok)
+	assert.Equal(t, ProviderConfig{Type: ProviderGemini}, cfg)
+
+	_, ok = r.Lookup("claude")
+	assert.False(t, ok)
+}
+
+func TestRegistryClient(t *testing.T) {
+	t.Setenv("TEAM_ANTHROPIC_KEY", "sk-team")
+	r, err := NewRegistry(map[string]ProviderConfig{
+		"claude": {Type: ProviderAnthropic, APIKeyEnv: "TEAM_ANTHROPIC_KEY", Endpoint: "https://proxy.example.com/v1/"},
+	})
+	require.NoError(t, err)
+
+	client, err := r.Client("claude")
+	require.NoError(t, err)
+	require.IsType(t, &AnthropicClient{}, client)
+	assert.Equal(t, "sk-team", client.(*AnthropicClient).apiKey)
+	assert.Equal(t, "https://proxy.example.com/v1", client.(*AnthropicClient).baseURL)
+
+	// Explicit options override the configuration.
+	client, err = r.Client("claude", WithAPIKey("sk-flag"))
+	require.NoError(t, err)
+	assert.Equal(t, "sk-flag", client.(*AnthropicClient).apiKey)
+
+	client, err = r.Client("")
+	require.NoError(t, err)
+	assert.IsType(t, &OpenAIClient{}, client)
+
+	_, err = r.Client("unknown")
+	assert.ErrorContains(t, err, `unknown provider "unknown"`)
+}
+
+func TestLoadRegistryRateLimits(t *testing.T) {
+	r, err := LoadRegistry("", []string{
+		"LLM_PROVIDER_OPENAI_REQUESTS_PER_MINUTE=60",
+		"LLM_PROVIDER_OPENAI_TOKENS_PER_MINUTE=90000",
+	})
+	require.NoError(t, err)
+	cfg, ok := r.Lookup(ProviderOpenAI)
+	require.True(t, ok)
+	assert.Equal(t, ProviderConfig{Type: ProviderOpenAI, RequestsPerMinute: 60, TokensPerMinute: 90000}, cfg)
+
+	_, err = LoadRegistry("", []string{"LLM_PROVIDER_OPENAI_REQUESTS_PER_MINUTE=many"})
+	assert.ErrorContains(t, err, "invalid LLM_PROVIDER_OPENAI_REQUESTS_PER_MINUTE")
+
+	_, err = NewRegistry(map[string]ProviderConfig{"openai": {TokensPerMinute: -1}})
+	assert.ErrorContains(t, err, "must not be negative")
+}
+
+func TestRegistryAzure(t *testing.T) {
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		fmt.Fprint(w, chatCompletionBody)
+	}))
+	defer srv.Close()
+
+	t.Setenv("AZURE_OPENAI_ENDPOINT", srv.URL)
+	r, err := LoadRegistry("", []string{
+		"LLM_PROVIDER_AZURE_JUDGE_TYPE=azure",
+		"LLM_PROVIDER_AZURE_JUDGE_DEPLOYMENT=judge",
+		"LLM_PROVIDER_AZURE_JUDGE_API_VERSION=2024-06-01",
+	})
+	require.NoError(t, err)
+	cfg, ok := r.Lookup("azure-judge")
+	require.True(t, ok)
+	assert.Equal(t, ProviderConfig{Type: ProviderAzure, Deployment: "judge", APIVersion: "2024-06-01"}, cfg)
+
+	client, err := r.Client("azure-judge")
+	require.NoError(t, err)
+	_, err = client.ChatCompletion(context.Background(), ChatRequest{Model: "gpt-4o", UserMessage: "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "/openai/deployments/judge/chat/completions", path)
+}
+
+func TestLoadRegistryHTTPSettings(t *testing.T) {
+	var attempts int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts++; attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, chatCompletionBody)
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "providers.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`providers:
+  flaky:
+    type: openai
+    endpoint: `+srv.URL+`
+    timeout: 5m
+    max_retries: 2
+`), 0o600))
+	r, err := LoadRegistry(path, []string{
+		"LLM_PROVIDER_FLAKY_RETRY_BACKOFF=1ms",
+		"LLM_PROVIDER_FLAKY_PROXY=http://proxy.example.com:3128",
+	})
+	require.NoError(t, err)
+	cfg, ok := r.Lookup("flaky")
+	require.True(t, ok)
+	assert.Equal(t, 5*time.Minute, cfg.Timeout)
+	assert.Equal(t, 2, cfg.MaxRetries)
+	assert.Equal(t, time.Millisecond, cfg.RetryBackoff)
+	assert.Equal(t, "http://proxy.example.com:3128", cfg.Proxy)
+
+	// The proxy is not reachable from the test, so retry without it.
+	cfg.Proxy = ""
+	r, err = NewRegistry(map[string]ProviderConfig{"flaky": cfg})
+	require.NoError(t, err)
+	client, err := r.Client("flaky")
+	require.NoError(t, err)
+	_, err = client.ChatCompletion(context.Background(), ChatRequest{Model: "m", UserMessage: "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempts, "the 503 is retried")
+
+	_, err = NewRegistry(map[string]ProviderConfig{"openai": {MaxRetries: -1}})
+	assert.ErrorContains(t, err, "must not be negative")
+	_, err = NewRegistry(map[string]ProviderConfig{"openai": {Proxy: "proxy.example.com:3128"}})
+	assert.ErrorContains(t, err, `invalid proxy "proxy.example.com:3128"`)
+	_, err = LoadRegistry("", []string{"LLM_PROVIDER_OPENAI_TIMEOUT=soon"})
+	assert.ErrorContains(t, err, "invalid LLM_PROVIDER_OPENAI_TIMEOUT")
+}
+
+func TestRegistryCustomProviderAPIKey(t *testing.T) {
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		fmt.Fprint(w, chatCompletionBody)
+	}))
+	defer srv.Close()
+
+	t.Setenv("OPENAI_API_KEY", "sk-openai")
+	r, err := NewRegistry(map[string]ProviderConfig{
+		"openai":     {Endpoint: srv.URL},
+		"openrouter": {Type: ProviderOpenAI, Endpoint: srv.URL},
+	})
+	require.NoError(t, err)
+
+	for name, want := range map[string]string{
+		"openai":     "Bearer sk-openai",
+		"openrouter": "Bearer not-needed", // the OpenAI key is not sent elsewhere
+	} {
+		client, err := r.Client(name)
+		require.NoError(t, err)
+		_, err = client.ChatCompletion(context.Background(), ChatRequest{Model: "m", UserMessage: "hi"})
+		require.NoError(t, err)
+		assert.Equal(t, want, auth, name)
+	}
+}
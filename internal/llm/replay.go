@@ -0,0 +1,218 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// ErrNoFixture is returned by a replaying client for a request that was
+// not recorded.
+var ErrNoFixture = errors.New("no recorded response for request")
+
+// fixture is a recorded request and its response.
+type fixture struct {
+	Request  fixtureRequest `json:"request"`
+	Response cacheEntry     `json:"response"`
+}
+
+// fixtureRequest is the stored form of a ChatRequest.
+type fixtureRequest struct {
+	Model           string         `json:"model"`
+	SystemMessage   string         `json:"system_message,omitempty"`
+	Messages        []Message      `json:"messages,omitempty"`
+	UserMessage     string         `json:"user_message,omitempty"`
+	Temperature     *float64       `json:"temperature,omitempty"`
+	MaxTokens       int            `json:"max_tokens,omitempty"`
+	ReasoningEffort string         `json:"reasoning_effort,omitempty"`
+	ExtraParams     map[string]any `json:"extra_params,omitempty"`
+}
+
+func (r fixtureRequest) chatRequest() ChatRequest {
+	return ChatRequest{
+		Model:           r.Model,
+		SystemMessage:   r.SystemMessage,
+		Messages:        r.Messages,
+		UserMessage:     r.UserMessage,
+		Temperature:     r.Temperature,
+		MaxTokens:       r.MaxTokens,
+		ReasoningEffort: r.ReasoningEffort,
+		ExtraParams:     r.ExtraParams,
+	}
+}
+
+// Fixtures records the responses of clients to a fixture file and serves
+// them again without calling an API, e.g. to reproduce a run offline, in
+// regression tests of the runner and scorer, or in demos without
+// credentials. It is safe for concurrent use.
+type Fixtures struct {
+	mu       sync.Mutex
+	fixtures []fixture
+	byKey    map[string][]int // indices into fixtures, in recording order
+	served   map[string]int   // responses of a key replayed so far
+}
+
+type fixtureFile struct {
+	Fixtures []fixture `json:"fixtures"`
+}
+
+// NewFixtures returns an empty set of fixtures to record into.
+func NewFixtures() *Fixtures {
+	return &Fixtures{byKey: make(map[string][]int), served: make(map[string]int)}
+}
+
+// LoadFixtures reads fixtures written by Save.
+func LoadFixtures(path string) (*Fixtures, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixtures: %w", err)
+	}
+	var file fixtureFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse fixtures: %w", err)
+	}
+	f := NewFixtures()
+	for _, fx := range file.Fixtures {
+		f.add(fx)
+	}
+	return f, nil
+}
+
+// Save writes the fixtures to path.
+func (f *Fixtures) Save(path string) error {
+	f.mu.Lock()
+	data, err := json.MarshalIndent(fixtureFile{Fixtures: f.fixtures}, "", "  ")
+	f.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal fixtures: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write fixtures: %w", err)
+	}
+	return nil
+}
+
+// Len returns the number of fixtures.
+func (f *Fixtures) Len() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.fixtures)
+}
+
+func (f *Fixtures) add(fx fixture) {
+	key := cacheKey("", fx.Request.chatRequest())
+	f.byKey[key] = append(f.byKey[key], len(f.fixtures))
+	f.fixtures = append(f.fixtures, fx)
+}
+
+func (f *Fixtures) record(req ChatRequest, resp *ChatResponse) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.add(fixture{
+		Request: fixtureRequest{
+			Model:           req.Model,
+			SystemMessage:   req.SystemMessage,
+			Messages:        req.Messages,
+			UserMessage:     req.UserMessage,
+			Temperature:     req.Temperature,
+			MaxTokens:       req.MaxTokens,
+			ReasoningEffort: req.ReasoningEffort,
+			ExtraParams:     req.ExtraParams,
+		},
+		Response: cacheEntry{Content: resp.Content, Usage: resp.Usage, FinishReason: resp.FinishReason, Model: resp.Model},
+	})
+}
+
+// next returns the response to replay for a request: identical requests
+// get the recorded responses in order, and the last one once all have been
+// served.
+func (f *Fixtures) next(req ChatRequest) (*ChatResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := cacheKey("", req)
+	indices := f.byKey[key]
+	if len(indices) == 0 {
+		return nil, fmt.Errorf("%w (model %q)", ErrNoFixture, req.Model)
+	}
+	n := min(f.served[key], len(indices)-1)
+	f.served[key]++
+	e := f.fixtures[indices[n]].Response
+	return &ChatResponse{Content: e.Content, Usage: e.Usage, FinishReason: e.FinishReason, Model: e.Model}, nil
+}
+
+// Record returns a client recording the responses of client. Streamed
+// responses are read to the end before they are returned, so that they can
+// be recorded.
+func (f *Fixtures) Record(client Client) Client {
+	return &recordingClient{client: client, fixtures: f}
+}
+
+// Replay returns a client answering requests with the recorded responses;
+// requests that were not recorded fail with ErrNoFixture.
+func (f *Fixtures) Replay() Client {
+	return &replayClient{fixtures: f}
+}
+
+type recordingClient struct {
+	client   Client
+	fixtures *Fixtures
+}
+
+func (c *recordingClient) ChatCompletion(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	resp, err := c.client.ChatCompletion(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	c.fixtures.record(req, resp)
+	return resp, nil
+}
+
+func (c *recordingClient) ChatCompletionStream(ctx context.Context, req ChatRequest) (*StreamReader, error) {
+	stream, err := c.client.ChatCompletionStream(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	content, err := stream.Collect(0)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ChatResponse{Content: content, Usage: stream.Usage(), FinishReason: stream.FinishReason(), Model: stream.Model()}
+	c.fixtures.record(req, resp)
+	return newReplayStreamReader(resp), nil
+}
+
+type replayClient struct {
+	fixtures *Fixtures
+}
+
+func (c *replayClient) ChatCompletion(_ context.Context, req ChatRequest) (*ChatResponse, error) {
+	return c.fixtures.next(req)
+}
+
+func (c *replayClient) ChatCompletionStream(_ context.Context, req ChatRequest) (*StreamReader, error) {
+	resp, err := c.fixtures.next(req)
+	if err != nil {
+		return nil, err
+	}
+	return newReplayStreamReader(resp), nil
+}
+
+// newReplayStreamReader streams a response as a single chunk.
+func newReplayStreamReader(resp *ChatResponse) *StreamReader {
+	sent := false
+	return &StreamReader{
+		next: func(meta *streamMeta) (string, error) {
+			if sent {
+				return "", io.EOF
+			}
+			sent = true
+			*meta = streamMeta{usage: resp.Usage, finishReason: resp.FinishReason, model: resp.Model}
+			return resp.Content, nil
+		},
+		closer: io.NopCloser(nil),
+	}
+}
@@ -0,0 +1,94 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixturesRecordReplay(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		fmt.Fprintf(w, `{"model":"mistral","choices":[{"index":0,"message":{"role":"assistant","content":"answer %d"},"finish_reason":"stop"}],"usage":{"prompt_tokens":3,"completion_tokens":2,"total_tokens":5}}`, n)
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	question := ChatRequest{Model: "mistral", SystemMessage: "system", UserMessage: "q1", Temperature: Float64Ptr(0.7)}
+
+	recording := NewFixtures()
+	client := recording.Record(NewOpenAIClient(WithBaseURL(srv.URL)))
+	for range 2 {
+		_, err := client.ChatCompletion(ctx, question)
+		require.NoError(t, err)
+	}
+	_, err := client.ChatCompletion(ctx, ChatRequest{Model: "judge", UserMessage: "q1"})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "fixtures.json")
+	require.NoError(t, recording.Save(path))
+
+	fixtures, err := LoadFixtures(path)
+	require.NoError(t, err)
+	assert.Equal(t, 3, fixtures.Len())
+	replay := fixtures.Replay()
+
+	// Identical requests get the recorded responses in order, then the last.
+	for _, want := range []string{"answer 1", "answer 2", "answer 2"} {
+		resp, err := replay.ChatCompletion(ctx, question)
+		require.NoError(t, err)
+		assert.Equal(t, want, resp.Content)
+		assert.Equal(t, Usage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5}, resp.Usage)
+		assert.Equal(t, FinishReasonStop, resp.FinishReason)
+	}
+
+	stream, err := replay.ChatCompletionStream(ctx, ChatRequest{Model: "judge", UserMessage: "q1"})
+	require.NoError(t, err)
+	content, err := CollectStream(stream)
+	require.NoError(t, err)
+	assert.Equal(t, "answer 3", content)
+	assert.Equal(t, 5, stream.Usage().TotalTokens)
+
+	_, err = replay.ChatCompletion(ctx, ChatRequest{Model: "mistral", UserMessage: "q2"})
+	assert.ErrorIs(t, err, ErrNoFixture)
+	assert.Equal(t, int32(3), requests.Load(), "replaying never calls the API")
+}
+
+func TestFixturesRecordStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `data: {"model":"mistral","choices":[{"index":0,"delta":{"content":"Hello"}}]}
+
+data: {"model":"mistral","choices":[{"index":0,"delta":{"content":" world"},"finish_reason":"stop"}]}
+
+data: [DONE]
+
+`)
+	}))
+	defer srv.Close()
+
+	fixtures := NewFixtures()
+	req := ChatRequest{Model: "mistral", UserMessage: "hi"}
+	stream, err := fixtures.Record(NewOpenAIClient(WithBaseURL(srv.URL))).ChatCompletionStream(context.Background(), req)
+	require.NoError(t, err)
+	content, err := CollectStream(stream)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello world", content)
+
+	resp, err := fixtures.Replay().ChatCompletion(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello world", resp.Content)
+	assert.Equal(t, FinishReasonStop, resp.FinishReason)
+}
+
+func TestLoadFixturesInvalid(t *testing.T) {
+	_, err := LoadFixtures(filepath.Join(t.TempDir(), "missing.json"))
+	assert.ErrorContains(t, err, "failed to read fixtures")
+}
@@ -0,0 +1,167 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// streamMeta is what the chunks of a stream report besides content; the
+// terminal chunks carry the usage and finish reason.
+type streamMeta struct {
+	usage        Usage
+	finishReason string
+	model        string
+}
+
+// StreamReader wraps a streaming response. It stops reading when the
+// context of the request is done, and records when each chunk of content
+// arrived, e.g. to measure the time to the first token.
+type StreamReader struct {
+	// next reads the next chunk and records the metadata the chunk reports;
+	// it returns io.EOF at the end of the stream.
+	next   func(meta *streamMeta) (string, error)
+	closer io.Closer
+	meta   streamMeta
+
+	// ctx is the context of the request, which cancel cancels (see open).
+	ctx     context.Context
+	cancel  context.CancelCauseFunc
+	started time.Time
+	chunks  []time.Time
+
+	// limiter is charged the usage of the stream when it is closed.
+	limiter *RateLimiter
+	closed  bool
+
+	// span is the trace span of the request, ended when the stream is closed.
+	span trace.Span
+}
+
+// open attaches the reader to the request it reads the response of: ctx
+// and its cancel function, when the request was sent, and the rate limiter
+// of the client.
+func (s *StreamReader) open(ctx context.Context, cancel context.CancelCauseFunc, started time.Time, limiter *RateLimiter) *StreamReader {
+	s.ctx = ctx
+	s.cancel = cancel
+	s.started = started
+	s.limiter = limiter
+	return s
+}
+
+// Recv reads the next chunk from the stream. Once the context of the
+// request is done, it returns the context's error, or the cause given to
+// Collect's timeout.
+func (s *StreamReader) Recv() (string, error) {
+	if err := s.ctxErr(); err != nil {
+		return "", err
+	}
+	chunk, err := s.next(&s.meta)
+	if err != nil {
+		if ctxErr := s.ctxErr(); ctxErr != nil && !errors.Is(err, io.EOF) {
+			return "", ctxErr
+		}
+		return "", err
+	}
+	if chunk != "" {
+		s.chunks = append(s.chunks, time.Now())
+	}
+	return chunk, nil
+}
+
+func (s *StreamReader) ctxErr() error {
+	if s.ctx == nil || s.ctx.Err() == nil {
+		return nil
+	}
+	return context.Cause(s.ctx)
+}
+
+// Usage returns the token usage sent with the final chunk of the stream.
+// It is only complete once the stream has been read to the end.
+func (s *StreamReader) Usage() Usage {
+	return s.meta.usage
+}
+
+// FinishReason returns the finish reason sent with the final chunk of the
+// stream, normalized like ChatResponse.FinishReason. It is empty until the
+// stream has been read to the end or if the API does not report it.
+func (s *StreamReader) FinishReason() string {
+	return s.meta.finishReason
+}
+
+// Model returns the model that answered as reported by the stream, or an
+// empty string.
+func (s *StreamReader) Model() string {
+	return s.meta.model
+}
+
+// TimeToFirstToken returns the time from sending the request to receiving
+// the first chunk of content, or zero if none has been received.
+func (s *StreamReader) TimeToFirstToken() time.Duration {
+	if len(s.chunks) == 0 || s.started.IsZero() {
+		return 0
+	}
+	return s.chunks[0].Sub(s.started)
+}
+
+// ChunkTimes returns when each chunk of content was received.
+func (s *StreamReader) ChunkTimes() []time.Time {
+	return s.chunks
+}
+
+// Close stops reading and closes the stream.
+func (s *StreamReader) Close() {
+	if s.cancel != nil {
+		s.cancel(context.Canceled)
+	}
+	_ = s.closer.Close()
+	if !s.closed {
+		s.closed = true
+		s.limiter.Charge(s.meta.usage)
+		s.endSpan()
+	}
+}
+
+// endSpan records the usage and timing of the stream on its span and ends it.
+func (s *StreamReader) endSpan() {
+	if s.span == nil {
+		return
+	}
+	setResponseAttributes(s.span, s.meta.usage, s.meta.finishReason, s.meta.model)
+	if ttft := s.TimeToFirstToken(); ttft > 0 {
+		s.span.SetAttributes(attrTTFT.Int64(ttft.Milliseconds()))
+	}
+	s.span.End()
+}
+
+// Collect reads the stream to its end, closes it, and returns the content.
+// With a positive timeout, the stream is cancelled when the timeout elapses,
+// and the content received until then is returned with
+// context.DeadlineExceeded.
+func (s *StreamReader) Collect(timeout time.Duration) (string, error) {
+	defer s.Close()
+	if timeout > 0 && s.cancel != nil {
+		timer := time.AfterFunc(timeout, func() { s.cancel(context.DeadlineExceeded) })
+		defer timer.Stop()
+	}
+	var b strings.Builder
+	for {
+		chunk, err := s.Recv()
+		if errors.Is(err, io.EOF) {
+			return b.String(), nil
+		}
+		if err != nil {
+			return b.String(), err
+		}
+		b.WriteString(chunk)
+	}
+}
+
+// CollectStream reads all chunks from a StreamReader and returns the full content.
+func CollectStream(sr *StreamReader) (string, error) {
+	return sr.Collect(0)
+}
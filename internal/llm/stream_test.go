@@ -0,0 +1,80 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stalledStream serves an OpenAI stream that sends one chunk and then
+// stalls until the client goes away.
+func stalledStream(t *testing.T) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `data: {"model":"mistral","choices":[{"index":0,"delta":{"content":"Hello"}}]}`+"\n\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestStreamReaderCollect(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `data: {"model":"mistral","choices":[{"index":0,"delta":{"content":"Hello"}}]}
+
+data: {"model":"mistral","choices":[{"index":0,"delta":{"content":" world"},"finish_reason":"length"}]}
+
+data: {"model":"mistral","choices":[],"usage":{"prompt_tokens":3,"completion_tokens":2,"total_tokens":5}}
+
+data: [DONE]
+
+`)
+	}))
+	defer srv.Close()
+
+	stream, err := NewOpenAIClient(WithBaseURL(srv.URL)).ChatCompletionStream(context.Background(), ChatRequest{Model: "mistral", UserMessage: "hi"})
+	require.NoError(t, err)
+	content, err := stream.Collect(time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello world", content)
+	assert.Equal(t, Usage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5}, stream.Usage())
+	assert.Equal(t, FinishReasonLength, stream.FinishReason())
+	assert.Equal(t, "mistral", stream.Model())
+	assert.Len(t, stream.ChunkTimes(), 2)
+}
+
+func TestStreamReaderCollectTimeout(t *testing.T) {
+	srv := stalledStream(t)
+
+	stream, err := NewOpenAIClient(WithBaseURL(srv.URL)).ChatCompletionStream(context.Background(), ChatRequest{Model: "mistral", UserMessage: "hi"})
+	require.NoError(t, err)
+	content, err := stream.Collect(100 * time.Millisecond)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, "Hello", content, "the content received before the timeout is kept")
+	assert.Positive(t, stream.TimeToFirstToken())
+}
+
+func TestStreamReaderContextCanceled(t *testing.T) {
+	srv := stalledStream(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := NewOpenAIClient(WithBaseURL(srv.URL)).ChatCompletionStream(ctx, ChatRequest{Model: "mistral", UserMessage: "hi"})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	chunk, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", chunk)
+
+	time.AfterFunc(50*time.Millisecond, cancel)
+	_, err = stream.Recv()
+	assert.ErrorIs(t, err, context.Canceled)
+}
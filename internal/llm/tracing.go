@@ -0,0 +1,73 @@
+package llm
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/giantswarm/llm-testing/internal/tracing"
+)
+
+// Attributes of chat spans, following the OpenTelemetry semantic
+// conventions for generative AI.
+const (
+	attrSystem        = attribute.Key("gen_ai.system")
+	attrRequestModel  = attribute.Key("gen_ai.request.model")
+	attrResponseModel = attribute.Key("gen_ai.response.model")
+	attrInputTokens   = attribute.Key("gen_ai.usage.input_tokens")
+	attrOutputTokens  = attribute.Key("gen_ai.usage.output_tokens")
+	attrFinishReasons = attribute.Key("gen_ai.response.finish_reasons")
+	attrStreaming     = attribute.Key("llm.streaming")
+	attrPriority      = attribute.Key("llm.priority")
+	attrTTFT          = attribute.Key("llm.time_to_first_token_ms")
+)
+
+// traceChat sends a chat request with send in a span of the request.
+func traceChat(ctx context.Context, system string, req ChatRequest, send func(context.Context, ChatRequest) (*ChatResponse, error)) (*ChatResponse, error) {
+	ctx, span := startChatSpan(ctx, system, req, false)
+	resp, err := send(ctx, req)
+	if resp != nil {
+		setResponseAttributes(span, resp.Usage, resp.FinishReason, resp.Model)
+	}
+	tracing.End(span, err)
+	return resp, err
+}
+
+// traceStream opens a chat stream with open in a span of the request,
+// which ends when the stream is closed.
+func traceStream(ctx context.Context, system string, req ChatRequest, open func(context.Context, ChatRequest) (*StreamReader, error)) (*StreamReader, error) {
+	ctx, span := startChatSpan(ctx, system, req, true)
+	stream, err := open(ctx, req)
+	if err != nil {
+		tracing.End(span, err)
+		return nil, err
+	}
+	stream.span = span
+	return stream, nil
+}
+
+// startChatSpan starts the span of a chat request.
+func startChatSpan(ctx context.Context, system string, req ChatRequest, streaming bool) (context.Context, trace.Span) {
+	return tracing.Start(ctx, "chat "+req.Model,
+		attrSystem.String(system),
+		attrRequestModel.String(req.Model),
+		attrStreaming.Bool(streaming),
+		attrPriority.String(string(priorityOf(ctx))),
+	)
+}
+
+// setResponseAttributes records the usage, finish reason, and model of a
+// response on its span.
+func setResponseAttributes(span trace.Span, usage Usage, finishReason, model string) {
+	span.SetAttributes(
+		attrInputTokens.Int(usage.PromptTokens),
+		attrOutputTokens.Int(usage.CompletionTokens),
+	)
+	if finishReason != "" {
+		span.SetAttributes(attrFinishReasons.StringSlice([]string{finishReason}))
+	}
+	if model != "" {
+		span.SetAttributes(attrResponseModel.String(model))
+	}
+}
@@ -0,0 +1,53 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestChatSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-key") == "bad" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"model":"claude-sonnet-4-5-20250929","content":[{"type":"text","text":"Hello"}],"stop_reason":"end_turn","usage":{"input_tokens":12,"output_tokens":3}}`)
+	}))
+	defer srv.Close()
+
+	ctx := WithPriority(context.Background(), PriorityBulk)
+	_, err := NewAnthropicClient(WithBaseURL(srv.URL)).ChatCompletion(ctx, ChatRequest{Model: "claude", UserMessage: "hi"})
+	require.NoError(t, err)
+	_, err = NewAnthropicClient(WithBaseURL(srv.URL), WithAPIKey("bad")).ChatCompletion(ctx, ChatRequest{Model: "claude", UserMessage: "hi"})
+	require.Error(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "chat claude", spans[0].Name())
+	assert.Subset(t, spans[0].Attributes(), []attribute.KeyValue{
+		attrSystem.String(ProviderAnthropic),
+		attrRequestModel.String("claude"),
+		attrResponseModel.String("claude-sonnet-4-5-20250929"),
+		attrInputTokens.Int(12),
+		attrOutputTokens.Int(3),
+		attrFinishReasons.StringSlice([]string{FinishReasonStop}),
+		attrPriority.String(string(PriorityBulk)),
+	})
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+}
@@ -0,0 +1,143 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultRetryBackoff is the wait before the first retry of a request
+	// of providers configured with retries but no backoff.
+	DefaultRetryBackoff = time.Second
+
+	// maxRetryBackoff caps the wait between two attempts of a request.
+	maxRetryBackoff = time.Minute
+)
+
+// WithHTTPTimeout limits the time of a request including reading its
+// response, which for streaming requests is the whole stream. Zero (the
+// default) means no limit besides the request's context.
+func WithHTTPTimeout(timeout time.Duration) Option {
+	return func(c *clientConfig) {
+		c.httpTimeout = timeout
+	}
+}
+
+// WithRetry retries a request up to maxRetries times when it fails with a
+// network error, 429, or a 5xx status, waiting backoff before the first
+// retry and doubling the wait after each (up to a minute). A Retry-After
+// header of the response takes precedence over the backoff.
+func WithRetry(maxRetries int, backoff time.Duration) Option {
+	return func(c *clientConfig) {
+		c.maxRetries = maxRetries
+		c.retryBackoff = backoff
+	}
+}
+
+// WithProxy sends requests through an HTTP(S) proxy instead of the one
+// configured by the HTTP_PROXY/HTTPS_PROXY environment variables.
+func WithProxy(proxy *url.URL) Option {
+	return func(c *clientConfig) {
+		c.proxy = proxy
+	}
+}
+
+// WithTransport sets the HTTP transport requests are sent with, e.g. to
+// add TLS settings or instrumentation. WithProxy does not apply to it.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *clientConfig) {
+		c.transport = transport
+	}
+}
+
+// httpClient returns the HTTP client of the configured transport, proxy,
+// request logger, retries, and timeout. If wrap is given, it wraps the transport below the
+// retries, e.g. to authenticate every attempt.
+func (c *clientConfig) httpClient(wrap func(http.RoundTripper) http.RoundTripper) *http.Client {
+	var transport http.RoundTripper = http.DefaultTransport
+	switch {
+	case c.transport != nil:
+		transport = c.transport
+	case c.proxy != nil:
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.Proxy = http.ProxyURL(c.proxy)
+		transport = t
+	}
+	if wrap != nil {
+		transport = wrap(transport)
+	}
+	if c.requestLogger != nil {
+		transport = &loggingTransport{next: transport, logger: c.requestLogger}
+	}
+	if c.maxRetries > 0 {
+		transport = &retryTransport{next: transport, maxRetries: c.maxRetries, backoff: c.retryBackoff}
+	}
+	return &http.Client{Transport: transport, Timeout: c.httpTimeout}
+}
+
+// retryTransport retries requests that failed transiently.
+type retryTransport struct {
+	next       http.RoundTripper
+	maxRetries int
+	backoff    time.Duration
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	backoff := t.backoff
+	for attempt := 0; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		if attempt >= t.maxRetries || !retryable(req.Context(), resp, err) {
+			return resp, err
+		}
+		if req.Body != nil && req.GetBody == nil {
+			return resp, err // the body cannot be sent again
+		}
+
+		wait := backoff
+		if resp != nil {
+			if after := retryAfter(resp); after > 0 {
+				wait = after
+			}
+			resp.Body.Close()
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		backoff = min(2*backoff, maxRetryBackoff)
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// retryable reports whether a request may succeed when sent again.
+func retryable(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		return ctx.Err() == nil && !errors.Is(err, context.Canceled)
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// retryAfter returns the wait requested by a Retry-After header in seconds,
+// or zero.
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return min(time.Duration(seconds)*time.Second, maxRetryBackoff)
+}
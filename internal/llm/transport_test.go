@@ -0,0 +1,142 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const chatCompletionBody = `{"model":"mistral-7b","choices":[{"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}],"usage":{"prompt_tokens":1,"completion_tokens":1,"total_tokens":2}}`
+
+func TestOpenAIClientRetry(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Contains(t, string(body), `"model":"mistral"`, "the body is sent again")
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, chatCompletionBody)
+	}))
+	defer srv.Close()
+
+	client := NewOpenAIClient(WithBaseURL(srv.URL), WithRetry(3, time.Millisecond))
+	resp, err := client.ChatCompletion(context.Background(), ChatRequest{Model: "mistral", UserMessage: "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp.Content)
+	assert.Equal(t, Usage{PromptTokens: 1, CompletionTokens: 1, TotalTokens: 2}, resp.Usage)
+	assert.Equal(t, FinishReasonStop, resp.FinishReason)
+	assert.Equal(t, "mistral-7b", resp.Model)
+	assert.Equal(t, int32(3), attempts.Load())
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		maxRetries   int
+		wantStatus   int
+		wantAttempts int32
+	}{
+		{name: "retries 5xx", statuses: []int{502, 500, 200}, maxRetries: 3, wantStatus: 200, wantAttempts: 3},
+		{name: "retries 429", statuses: []int{429, 200}, maxRetries: 3, wantStatus: 200, wantAttempts: 2},
+		{name: "gives up", statuses: []int{503, 503, 503}, maxRetries: 2, wantStatus: 503, wantAttempts: 3},
+		{name: "no retry on 4xx", statuses: []int{400, 200}, maxRetries: 3, wantStatus: 400, wantAttempts: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statuses[attempts.Add(1)-1])
+			}))
+			defer srv.Close()
+
+			cfg := &clientConfig{maxRetries: tt.maxRetries, retryBackoff: time.Millisecond}
+			resp, err := cfg.httpClient(nil).Post(srv.URL, "application/json", strings.NewReader("{}"))
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			assert.Equal(t, tt.wantAttempts, attempts.Load())
+		})
+	}
+}
+
+func TestRetryTransportContextCanceled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+
+	cfg := &clientConfig{maxRetries: 3, retryBackoff: time.Millisecond}
+	start := time.Now()
+	_, err = cfg.httpClient(nil).Do(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second, "the Retry-After wait ends with the context")
+}
+
+func TestHTTPTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(500 * time.Millisecond):
+		}
+	}))
+	defer srv.Close()
+
+	client := NewOpenAIClient(WithBaseURL(srv.URL), WithHTTPTimeout(50*time.Millisecond))
+	_, err := client.ChatCompletion(context.Background(), ChatRequest{Model: "mistral", UserMessage: "hi"})
+	require.Error(t, err)
+	assert.Equal(t, ErrorClassTimeout, ClassifyError(err))
+}
+
+func TestProxyAndTransport(t *testing.T) {
+	var proxied atomic.Bool
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied.Store(true)
+		assert.Equal(t, "llm.invalid", r.URL.Host, "the proxy receives the target URL")
+		fmt.Fprint(w, chatCompletionBody)
+	}))
+	defer proxy.Close()
+	proxyURL, err := url.Parse(proxy.URL)
+	require.NoError(t, err)
+
+	client := NewOpenAIClient(WithBaseURL("http://llm.invalid/v1"), WithProxy(proxyURL))
+	_, err = client.ChatCompletion(context.Background(), ChatRequest{Model: "mistral", UserMessage: "hi"})
+	require.NoError(t, err)
+	assert.True(t, proxied.Load())
+
+	// The transport is below the retries.
+	var attempts atomic.Int32
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if attempts.Add(1) == 1 {
+			return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(strings.NewReader("")), Header: http.Header{}}, nil
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(chatCompletionBody)), Header: http.Header{}}, nil
+	})
+	client = NewOpenAIClient(WithBaseURL("http://llm.invalid/v1"), WithTransport(transport), WithRetry(1, time.Millisecond))
+	_, err = client.ChatCompletion(context.Background(), ChatRequest{Model: "mistral", UserMessage: "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), attempts.Load())
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
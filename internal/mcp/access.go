@@ -0,0 +1,67 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"github.com/giantswarm/llm-testing/internal/server"
+)
+
+// writeTools are the tools that change state: they deploy or tear down
+// models, run or score tests, or write or delete files. list_endpoint_models
+// is one too, since it sends the server's API keys to the endpoint it is
+// given. sc.Access decides which clients may call them.
+var writeTools = map[string]bool{
+	"annotate_run":         true,
+	"cancel_run":           true,
+	"cleanup_models":       true,
+	"compare_revisions":    true,
+	"create_runtime":       true,
+	"delete_results":       true,
+	"deploy_model":         true,
+	"evaluate_model":       true,
+	"generate_suite":       true,
+	"list_endpoint_models": true,
+	"run_test_suite":       true,
+	"score_results":        true,
+	"teardown_model":       true,
+}
+
+// AccessOptions return the server options that enforce sc.Access: the tools
+// that change state are hidden from the tool list of clients that may not
+// call them, and their calls fail.
+func AccessOptions(sc *server.ServerContext) []mcpserver.ServerOption {
+	return []mcpserver.ServerOption{
+		mcpserver.WithToolFilter(func(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+			if sc.Access.CanWrite(ctx) {
+				return tools
+			}
+			allowed := make([]mcp.Tool, 0, len(tools))
+			for _, tool := range tools {
+				if !writeTools[tool.Name] {
+					allowed = append(allowed, tool)
+				}
+			}
+			return allowed
+		}),
+		mcpserver.WithToolHandlerMiddleware(func(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+			return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				if writeTools[request.Params.Name] && !sc.Access.CanWrite(ctx) {
+					return toolError(codePermissionDenied, accessDenied(request.Params.Name, sc.Access)), nil
+				}
+				return next(ctx, request)
+			}
+		}),
+	}
+}
+
+func accessDenied(tool string, access server.AccessPolicy) string {
+	if access.ReadOnly {
+		return fmt.Sprintf("%s is not available: the server is read-only", tool)
+	}
+	return fmt.Sprintf("%s is not available: it requires membership in one of the groups %s", tool, strings.Join(access.WriterGroups, ", "))
+}
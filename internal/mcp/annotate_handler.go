@@ -0,0 +1,60 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/llm-testing/internal/runner"
+	"github.com/giantswarm/llm-testing/internal/server"
+)
+
+// annotateRunArgs are the arguments of annotate_run.
+type annotateRunArgs struct {
+	RunID  string `json:"run_id" jsonschema_description:"Run ID to annotate, or 'latest' for the last run of this session"`
+	Text   string `json:"text" jsonschema_description:"The finding or conclusion (free-form text, Markdown allowed)"`
+	Model  string `json:"model,omitempty" jsonschema_description:"Model in the run the finding is about (optional, whole run if omitted)"`
+	Author string `json:"author,omitempty" jsonschema_description:"Who wrote the annotation (default: 'agent')"`
+}
+
+func handleAnnotateRun(_ context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	args, err := bindArgs[annotateRunArgs](request)
+	if err != nil {
+		return toolErrorFrom(err, codeInvalidArgument), nil
+	}
+
+	runID := args.RunID
+	runPath, err := resolveRunPath(sc.OutputDir, runID)
+	if err != nil {
+		return toolErrorf(codeInvalidArgument, "invalid run_id: %v", err), nil
+	}
+
+	annotation := runner.Annotation{
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Author:    "agent",
+		Text:      strings.TrimSpace(args.Text),
+		Model:     strings.TrimSpace(args.Model),
+	}
+	if author := strings.TrimSpace(args.Author); author != "" {
+		annotation.Author = author
+	}
+
+	annotations, err := runner.AddAnnotation(runPath, annotation)
+	if err != nil {
+		return toolErrorFromf(runNotFound(err), codeInvalidArgument, "failed to annotate run %q: %v", runID, err), nil
+	}
+
+	result := map[string]interface{}{
+		"run_id":      runID,
+		"annotations": annotations,
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return toolErrorf(codeInternal, "failed to marshal result: %v", err), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
@@ -0,0 +1,200 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Tool arguments can be bound to a struct with bindArgs. The struct's tags
+// define both the tool's input schema, generated with mcp.WithInputSchema,
+// and the validation of its arguments, so that the two cannot drift apart:
+//
+//   - json: the argument name. Arguments without "omitempty" are required;
+//     empty and whitespace-only strings count as missing.
+//   - jsonschema_description: the description of the argument.
+//   - jsonschema: "enum=a,enum=b" restricts a string to the listed values,
+//     "minimum=n" a number to n or more, and "maximum=n" to n or less.
+//
+// Arguments shared by several tools are embedded structs, whose fields are
+// arguments of the embedding struct. Descriptions too long for a tag, or
+// built from constants of other packages, are set by a JSONSchemaExtend
+// method of the struct.
+//
+// Validation errors name the argument, e.g. "run_id is required" or
+// "invalid limit 0: must be at least 1".
+
+// bindArgs binds the arguments of a tool call to a T and validates them.
+func bindArgs[T any](request mcp.CallToolRequest) (T, error) {
+	return bindArgMap[T](request.GetArguments())
+}
+
+// bindArgMap binds tool arguments to a T and validates them, like
+// bindArgs, e.g. after adding the settings of a model alias to them.
+func bindArgMap[T any](args map[string]interface{}) (T, error) {
+	var target T
+	data, err := json.Marshal(args)
+	if err == nil {
+		err = json.Unmarshal(data, &target)
+	}
+	if err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			// The field path of an entry of an array argument, e.g.
+			// "args.0", is reported as the argument.
+			name, _, _ := strings.Cut(typeErr.Field, ".")
+			t, ok := argType(reflect.TypeOf(target), name)
+			if !ok {
+				t = typeErr.Type
+			}
+			return target, fmt.Errorf("invalid %s: must be %s", name, jsonTypeName(t))
+		}
+		return target, fmt.Errorf("invalid arguments: %w", err)
+	}
+	return target, errors.Join(checkFields(reflect.ValueOf(target), args)...)
+}
+
+// checkFields checks the fields of a bound struct v, including those of its
+// embedded structs, against the arguments present.
+func checkFields(v reflect.Value, present map[string]interface{}) []error {
+	var errs []error
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			errs = append(errs, checkFields(v.Field(i), present)...)
+			continue
+		}
+		if name == "" || name == "-" {
+			continue
+		}
+		value := reflect.Indirect(v.Field(i))
+		if _, ok := present[name]; !ok || isBlank(value) {
+			if !strings.Contains(opts, "omitempty") {
+				errs = append(errs, fmt.Errorf("%s is required", name))
+			}
+			continue
+		}
+		if err := checkConstraints(name, value, field.Tag.Get("jsonschema")); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// argType returns the type of the field of struct t, or of its embedded
+// structs, bound to the argument name.
+func argType(t reflect.Type, name string) (reflect.Type, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if field.Anonymous && tag == "" && field.Type.Kind() == reflect.Struct {
+			if ft, ok := argType(field.Type, name); ok {
+				return ft, true
+			}
+			continue
+		}
+		if tag == name {
+			return field.Type, true
+		}
+	}
+	return nil, false
+}
+
+// checkConstraints checks a present argument against the enum, minimum,
+// and maximum constraints of its jsonschema tag.
+func checkConstraints(name string, value reflect.Value, tag string) error {
+	var enum []string
+	for _, constraint := range strings.Split(tag, ",") {
+		key, arg, _ := strings.Cut(constraint, "=")
+		switch key {
+		case "enum":
+			enum = append(enum, arg)
+		case "minimum", "maximum":
+			bound, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				continue
+			}
+			var n float64
+			switch value.Kind() {
+			case reflect.Int, reflect.Int64:
+				n = float64(value.Int())
+			case reflect.Float64:
+				n = value.Float()
+			default:
+				continue
+			}
+			if key == "minimum" && n < bound {
+				return fmt.Errorf("invalid %s %v: must be at least %v", name, n, bound)
+			}
+			if key == "maximum" && n > bound {
+				return fmt.Errorf("invalid %s %v: must be at most %v", name, n, bound)
+			}
+		}
+	}
+	if len(enum) > 0 && value.Kind() == reflect.String && !slices.Contains(enum, value.String()) {
+		return fmt.Errorf("invalid %s %q: must be one of %s", name, value.String(), strings.Join(enum, ", "))
+	}
+	return nil
+}
+
+// isBlank reports whether an argument value counts as missing.
+func isBlank(value reflect.Value) bool {
+	if !value.IsValid() {
+		return true
+	}
+	if value.Kind() == reflect.String {
+		return strings.TrimSpace(value.String()) == ""
+	}
+	return false
+}
+
+// describeArg sets the description of argument name in the schema s of a
+// tool's arguments.
+func describeArg(s *jsonschema.Schema, name, description string) {
+	if prop, ok := s.Properties.Get(name); ok {
+		prop.Description = description
+	}
+}
+
+// enumArg restricts the string argument name in the schema s of a tool's
+// arguments to values. Unlike enums of tags, bindArgs does not check them.
+func enumArg(s *jsonschema.Schema, name string, values []string) {
+	if prop, ok := s.Properties.Get(name); ok {
+		prop.Enum = make([]any, 0, len(values))
+		for _, v := range values {
+			prop.Enum = append(prop.Enum, v)
+		}
+	}
+}
+
+// jsonTypeName names the JSON type of arguments bound to t.
+func jsonTypeName(t reflect.Type) string {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int64:
+		return "an integer"
+	case reflect.Float64:
+		return "a number"
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.String {
+			return "an array of strings"
+		}
+		return "an array"
+	default:
+		return "an object"
+	}
+}
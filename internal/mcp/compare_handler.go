@@ -0,0 +1,182 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/invopop/jsonschema"
+	"github.com/mark3labs/mcp-go/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/giantswarm/llm-testing/internal/kserve"
+	"github.com/giantswarm/llm-testing/internal/server"
+	"github.com/giantswarm/llm-testing/internal/testsuite"
+)
+
+// compareRevisionsArgs are the arguments of compare_revisions.
+type compareRevisionsArgs struct {
+	suiteArgs
+	BaseName     string `json:"base_name" jsonschema_description:"Base model name; revisions are deployed as '<base_name>-<revision name>'"`
+	ModelURI     string `json:"model_uri,omitempty" jsonschema_description:"Storage URI shared by the revisions unless they set their own 'model_uri'"`
+	Revisions    string `json:"revisions"`
+	Judge        bool   `json:"judge,omitempty" jsonschema_description:"Judge each answer immediately after it is produced and report running accuracy (default: false)"`
+	ScoringModel string `json:"scoring_model,omitempty" jsonschema_description:"Model to use for judging when 'judge' is enabled (default: server scoring model)"`
+}
+
+// JSONSchemaExtend completes the schema with the description of revisions,
+// which spans several lines.
+func (compareRevisionsArgs) JSONSchemaExtend(s *jsonschema.Schema) {
+	describeArg(s, "revisions", `JSON array of at least two revisions. Each revision has a "name" (e.g. "baseline", "awq") and accepts the same fields as the models of run_test_suite.
+
+Example: [{"name":"baseline"},{"name":"fp8","runtime_args":["--quantization=fp8"]}]`)
+}
+
+// handleCompareRevisions deploys revisions of a model side by side, runs a
+// test suite against each, and tears them all down. The deployments are part
+// of the run's job, so that they wait in the run queue with the run.
+func handleCompareRevisions(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	if sc.KServeManager == nil {
+		return toolError(codeNotConfigured, "KServe manager is not configured"), nil
+	}
+
+	args, err := bindArgs[compareRevisionsArgs](request)
+	if err != nil {
+		return toolErrorFrom(err, codeInvalidArgument), nil
+	}
+	var revisions []testsuite.Model
+	if err := json.Unmarshal([]byte(args.Revisions), &revisions); err != nil {
+		return toolErrorf(codeInvalidArgument, "invalid revisions JSON: %v", err), nil
+	}
+	models, err := revisionModels(args.BaseName, args.ModelURI, revisions)
+	if err != nil {
+		return toolErrorFrom(err, codeInvalidArgument), nil
+	}
+	if err := checkRevisionsFree(ctx, sc, models); err != nil {
+		return toolErrorFrom(err, codeKubernetesError), nil
+	}
+
+	stages := runStages{
+		before: func(ctx context.Context, notify progressNotifier) error {
+			if err := deployRevisions(ctx, sc, models, notify); err != nil {
+				return withCode(classifyError(err, codeDeploymentFailed), err)
+			}
+			return nil
+		},
+		// Tear down all revisions afterwards, including those deployed
+		// before another one failed. Revisions that exist by now without
+		// this run having created them are left in place.
+		after: func(ctx context.Context) {
+			for _, model := range models {
+				if err := teardownModel(ctx, sc, model, true); err != nil {
+					slog.Error("failed to tear down revision", "model", model.Name, "error", err)
+				}
+			}
+		},
+	}
+
+	// Run the suite against the deployed revisions, which the run finds by
+	// name, so that it neither redeploys nor tears them down.
+	runModels := make([]testsuite.Model, 0, len(models))
+	for _, model := range models {
+		runModels = append(runModels, testsuite.Model{
+			Name:        model.Name,
+			Temperature: model.Temperature,
+			MaxRetries:  model.MaxRetries,
+			Namespace:   model.Namespace,
+		})
+	}
+	data, err := json.Marshal(runModels)
+	if err != nil {
+		return toolErrorf(codeInternal, "failed to marshal models: %v", err), nil
+	}
+	deploy := false
+	runArgs := runTestSuiteArgs{
+		suiteArgs:    args.suiteArgs,
+		Models:       string(data),
+		Deploy:       &deploy,
+		Judge:        args.Judge,
+		ScoringModel: args.ScoringModel,
+	}
+	return runTestSuite(ctx, request, sc, runArgs, stages)
+}
+
+// revisionModels names the revisions after the base model and defaults
+// their model URI to the base model's.
+func revisionModels(baseName, modelURI string, revisions []testsuite.Model) ([]testsuite.Model, error) {
+	if len(revisions) < 2 {
+		return nil, fmt.Errorf("at least two revisions are required")
+	}
+
+	models := make([]testsuite.Model, 0, len(revisions))
+	seen := map[string]bool{}
+	for _, rev := range revisions {
+		if strings.TrimSpace(rev.Name) == "" {
+			return nil, fmt.Errorf("revision name cannot be empty")
+		}
+		model := rev
+		model.Name = kserve.RevisionName(baseName, rev.Name)
+		if seen[model.Name] {
+			return nil, fmt.Errorf("revisions must have distinct names, %q is used twice", model.Name)
+		}
+		seen[model.Name] = true
+		if model.ModelURI == "" {
+			model.ModelURI = modelURI
+		}
+		if model.ModelURI == "" {
+			return nil, fmt.Errorf("revision %q has no model_uri and no base model_uri is set", rev.Name)
+		}
+		models = append(models, model)
+	}
+	return models, validateModels(models)
+}
+
+// checkRevisionsFree returns a CONFLICT error if an InferenceService of the
+// name of a revision exists, so that the comparison neither reuses nor tears
+// down a model it did not deploy.
+func checkRevisionsFree(ctx context.Context, sc *server.ServerContext, models []testsuite.Model) error {
+	for _, model := range models {
+		_, err := sc.KServeManager.WithNamespace(model.Namespace).Get(ctx, model.Name)
+		switch {
+		case err == nil:
+			return withCode(codeConflict, fmt.Errorf("InferenceService %s already exists; delete it or choose another base_name", model.Name))
+		case !apierrors.IsNotFound(err):
+			return err
+		}
+	}
+	return nil
+}
+
+// deployRevisions deploys the revisions concurrently and waits for all of them.
+func deployRevisions(ctx context.Context, sc *server.ServerContext, models []testsuite.Model, notify progressNotifier) error {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for _, model := range models {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cfg := deployConfig(ctx, sc, model)
+			cfg.Progress = func(p kserve.DeployProgress) {
+				mu.Lock()
+				defer mu.Unlock()
+				notify(p.Elapsed.Seconds(), cfg.ReadyTimeout.Seconds(), p.String())
+			}
+
+			slog.Info("deploying revision", "model", model.Name, "uri", model.ModelURI)
+			if _, err := sc.KServeManager.WithNamespace(model.Namespace).Deploy(ctx, cfg); err != nil {
+				mu.Lock()
+				defer mu.Unlock()
+				errs = append(errs, fmt.Errorf("failed to deploy revision %q: %w", model.Name, err))
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
@@ -0,0 +1,33 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"github.com/giantswarm/llm-testing/internal/server"
+)
+
+// DrainOptions return the server options that let tool calls in flight
+// finish while sc.Drain drains the server. Calls of the tools that change
+// state are then refused, except for cancel_run; other tools keep working,
+// so that clients can follow the runs being drained.
+func DrainOptions(sc *server.ServerContext) []mcpserver.ServerOption {
+	return []mcpserver.ServerOption{
+		mcpserver.WithToolHandlerMiddleware(func(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+			return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				callCtx, done, err := sc.Drain.Begin(ctx)
+				if err != nil {
+					name := request.Params.Name
+					if writeTools[name] && name != "cancel_run" {
+						return toolErrorFromf(err, codeShuttingDown, "%s is not available: %v", name, err), nil
+					}
+					return next(ctx, request)
+				}
+				defer done()
+				return next(callCtx, request)
+			}
+		}),
+	}
+}
@@ -0,0 +1,210 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/giantswarm/llm-testing/internal/jobs"
+	"github.com/giantswarm/llm-testing/internal/kserve"
+	"github.com/giantswarm/llm-testing/internal/llm"
+	"github.com/giantswarm/llm-testing/internal/server"
+	"github.com/giantswarm/llm-testing/internal/testsuite"
+)
+
+// errorCode identifies the kind of failure of a tool call, so that clients
+// can branch on it rather than on the message.
+type errorCode string
+
+const (
+	codeInvalidArgument  errorCode = "INVALID_ARGUMENT"
+	codeNotConfigured    errorCode = "NOT_CONFIGURED"
+	codePermissionDenied errorCode = "PERMISSION_DENIED"
+	codeNotFound         errorCode = "NOT_FOUND"
+	codeSuiteNotFound    errorCode = "SUITE_NOT_FOUND"
+	codeRunNotFound      errorCode = "RUN_NOT_FOUND"
+	codeModelNotFound    errorCode = "MODEL_NOT_FOUND"
+	codeConflict         errorCode = "CONFLICT"
+	codeGPUUnavailable   errorCode = "GPU_UNAVAILABLE"
+	codeDeploymentFailed errorCode = "DEPLOYMENT_FAILED"
+	codeKubernetesError  errorCode = "KUBERNETES_ERROR"
+	codeLLMUnavailable   errorCode = "LLM_UNAVAILABLE"
+	codeLLMError         errorCode = "LLM_ERROR"
+	codeRunFailed        errorCode = "RUN_FAILED"
+	codeTimeout          errorCode = "TIMEOUT"
+	codeCancelled        errorCode = "CANCELLED"
+	codeShuttingDown     errorCode = "SHUTTING_DOWN"
+	codeInternal         errorCode = "INTERNAL"
+)
+
+// retryable reports whether a call failing with the code may succeed when
+// repeated unchanged, e.g. once GPUs are freed or an endpoint recovered.
+func (c errorCode) retryable() bool {
+	switch c {
+	case codeGPUUnavailable, codeKubernetesError, codeLLMUnavailable, codeTimeout, codeShuttingDown:
+		return true
+	default:
+		return false
+	}
+}
+
+// toolErrorInfo is the structured error of a failed tool call, returned as
+// {"error": {...}} in the structured content of its result. The text
+// content is the message.
+type toolErrorInfo struct {
+	Code      errorCode              `json:"code"`
+	Message   string                 `json:"message"`
+	Retryable bool                   `json:"retryable"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+// toolError returns an error result with message and code.
+func toolError(code errorCode, message string) *mcp.CallToolResult {
+	return newToolError(code, message, nil)
+}
+
+// toolErrorf returns an error result with a formatted message and code.
+func toolErrorf(code errorCode, format string, args ...interface{}) *mcp.CallToolResult {
+	return newToolError(code, fmt.Sprintf(format, args...), nil)
+}
+
+// toolErrorFrom returns an error result for err, with the code of err, or
+// fallback if err is not of a known kind.
+func toolErrorFrom(err error, fallback errorCode) *mcp.CallToolResult {
+	code := classifyError(err, fallback)
+	return newToolError(code, err.Error(), errorDetails(err, code))
+}
+
+// toolErrorFromf is toolErrorFrom with a formatted message, which usually
+// includes err.
+func toolErrorFromf(err error, fallback errorCode, format string, args ...interface{}) *mcp.CallToolResult {
+	code := classifyError(err, fallback)
+	return newToolError(code, fmt.Sprintf(format, args...), errorDetails(err, code))
+}
+
+func newToolError(code errorCode, message string, details map[string]interface{}) *mcp.CallToolResult {
+	result := mcp.NewToolResultError(message)
+	result.StructuredContent = map[string]interface{}{
+		"error": toolErrorInfo{
+			Code:      code,
+			Message:   message,
+			Retryable: code.retryable(),
+			Details:   details,
+		},
+	}
+	return result
+}
+
+// toolResultError returns the error of an error result, with its code.
+func toolResultError(result *mcp.CallToolResult) error {
+	err := errors.New(toolResultText(result))
+	if content, ok := result.StructuredContent.(map[string]interface{}); ok {
+		if info, ok := content["error"].(toolErrorInfo); ok {
+			return withCode(info.Code, err)
+		}
+	}
+	return err
+}
+
+// runNotFound gives the errors for missing files of a run the code
+// RUN_NOT_FOUND.
+func runNotFound(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return withCode(codeRunNotFound, err)
+	}
+	return err
+}
+
+// codedError is an error of a known code, returned by helpers whose errors
+// are not all of the same kind.
+type codedError struct {
+	code errorCode
+	err  error
+}
+
+func (e *codedError) Error() string { return e.err.Error() }
+func (e *codedError) Unwrap() error { return e.err }
+
+// withCode gives err the code.
+func withCode(code errorCode, err error) error {
+	return &codedError{code: code, err: err}
+}
+
+// classifyError returns the code of err, or fallback if err is not of a
+// known kind. LLM errors are told apart by their class if fallback is
+// codeLLMError: those worth retrying are codeLLMUnavailable.
+func classifyError(err error, fallback errorCode) errorCode {
+	var coded *codedError
+	var capacityErr *kserve.InsufficientCapacityError
+	var deployErr *kserve.DeployError
+	var statusErr apierrors.APIStatus
+	switch {
+	case err == nil:
+		return fallback
+	case errors.As(err, &coded):
+		return coded.code
+	case errors.Is(err, server.ErrDraining), errors.Is(err, jobs.ErrDraining):
+		return codeShuttingDown
+	case errors.Is(err, jobs.ErrNotFound):
+		return codeRunNotFound
+	case errors.Is(err, jobs.ErrAlreadyRunning):
+		return codeConflict
+	case errors.Is(err, testsuite.ErrNotFound):
+		return codeSuiteNotFound
+	case errors.As(err, &capacityErr):
+		return codeGPUUnavailable
+	case errors.As(err, &deployErr):
+		return codeDeploymentFailed
+	case errors.Is(err, fs.ErrNotExist):
+		return codeNotFound
+	case errors.Is(err, fs.ErrExist):
+		return codeConflict
+	case errors.Is(err, context.Canceled):
+		return codeCancelled
+	case fallback == codeLLMError:
+		switch class := llm.ClassifyError(err); {
+		case class.Retryable(), class == llm.ErrorClassCircuitOpen:
+			return codeLLMUnavailable
+		default:
+			return codeLLMError
+		}
+	case errors.Is(err, context.DeadlineExceeded):
+		return codeTimeout
+	// The only resources the tools look up by name are InferenceServices.
+	case apierrors.IsNotFound(err):
+		return codeModelNotFound
+	case apierrors.IsForbidden(err):
+		return codePermissionDenied
+	case errors.As(err, &statusErr):
+		return codeKubernetesError
+	default:
+		return fallback
+	}
+}
+
+// errorDetails returns the details of errors of code that carry more than
+// their message: the GPU capacity for GPU_UNAVAILABLE, and the error class
+// of LLM errors.
+func errorDetails(err error, code errorCode) map[string]interface{} {
+	var capacityErr *kserve.InsufficientCapacityError
+	switch {
+	case code == codeGPUUnavailable && errors.As(err, &capacityErr):
+		details := map[string]interface{}{
+			"requested_gpus": capacityErr.Requested,
+			"nodes":          capacityErr.Nodes,
+		}
+		if capacityErr.Capacity != nil {
+			details["largest_free"] = capacityErr.Capacity.Largest
+			details["free"] = capacityErr.Capacity.Free
+		}
+		return details
+	case code == codeLLMUnavailable || code == codeLLMError:
+		return map[string]interface{}{"llm_error_class": llm.ClassifyError(err)}
+	default:
+		return nil
+	}
+}
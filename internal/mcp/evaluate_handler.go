@@ -0,0 +1,106 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/llm-testing/internal/scorer"
+	"github.com/giantswarm/llm-testing/internal/server"
+	"github.com/giantswarm/llm-testing/internal/testsuite"
+)
+
+// evaluateModelArgs are the arguments of evaluate_model.
+type evaluateModelArgs struct {
+	suiteArgs
+	Model      string `json:"model" jsonschema_description:"Name of the model, used as the InferenceService name, or a model alias of the server (see list_model_aliases)"`
+	ModelURI   string `json:"model_uri,omitempty" jsonschema_description:"Storage URI of the model weights (hf://, pvc://, or s3://); required unless 'model' is an alias with a model_uri"`
+	Deployment string `json:"deployment,omitempty" jsonschema_description:"JSON object of further model settings, with the same fields as the models of run_test_suite (e.g. {\"gpu_count\":2,\"runtime_args\":[\"--max-model-len=8192\"]})"`
+	judgeArgs
+	Async bool `json:"async,omitempty" jsonschema_description:"Return the run_id immediately and evaluate in the background; follow it with get_run_status, whose result includes the scores once done (default: false)"`
+}
+
+// handleEvaluateModel deploys a model, runs a test suite against it, tears
+// it down, and scores its results, in one call.
+func handleEvaluateModel(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	if sc.KServeManager == nil {
+		return toolError(codeNotConfigured, "KServe manager is not configured"), nil
+	}
+
+	args, err := bindArgs[evaluateModelArgs](request)
+	if err != nil {
+		return toolErrorFrom(err, codeInvalidArgument), nil
+	}
+	modelName := args.Model
+
+	// A model alias provides the deployment settings, which the arguments
+	// override.
+	model, aliased := sc.ModelAliases.Lookup(modelName)
+	if !aliased {
+		model.Name = modelName
+	}
+	if deployment := args.Deployment; deployment != "" {
+		if err := json.Unmarshal([]byte(deployment), &model); err != nil {
+			return toolErrorf(codeInvalidArgument, "invalid deployment JSON: %v", err), nil
+		}
+	}
+	if model.Provider != "" {
+		return toolError(codeInvalidArgument, "evaluate_model deploys the model via KServe; use run_test_suite for models of a provider"), nil
+	}
+	model.Name = sc.ModelAliases.ModelName(modelName)
+	if args.ModelURI != "" {
+		model.ModelURI = args.ModelURI
+	}
+	if model.ModelURI == "" {
+		return toolError(codeInvalidArgument, "model_uri is required"), nil
+	}
+	if err := validateModels([]testsuite.Model{model}); err != nil {
+		return toolErrorFrom(err, codeInvalidArgument), nil
+	}
+
+	// Check the scoring settings before deploying, so that a typo does not
+	// cost a deployment.
+	cfg, opts, err := parseScoreConfig(scoreResultsArgs{judgeArgs: args.judgeArgs}, sc)
+	if err != nil {
+		return toolErrorFrom(err, codeInvalidArgument), nil
+	}
+
+	data, err := json.Marshal([]testsuite.Model{model})
+	if err != nil {
+		return toolErrorf(codeInternal, "failed to marshal models: %v", err), nil
+	}
+	// The run deploys the model, as runs do by default.
+	runArgs := runTestSuiteArgs{suiteArgs: args.suiteArgs, Models: string(data), Async: args.Async}
+	return runTestSuite(ctx, request, sc, runArgs, runStages{then: scoreRun(sc, cfg, opts)})
+}
+
+// scoreRun returns the follow-up of a run that scores all its results files
+// and adds the scores to the summary of the run as "scoring".
+func scoreRun(sc *server.ServerContext, cfg scorer.Config, opts scoreOptions) runFollowUp {
+	return func(ctx context.Context, summary map[string]interface{}, notify progressNotifier) error {
+		runID, _ := summary["run_id"].(string)
+		runPath, err := resolveRunPath(sc.OutputDir, runID)
+		if err != nil {
+			return fmt.Errorf("invalid run_id: %w", err)
+		}
+
+		result, err := scoreByRunID(ctx, scorer.NewScorer(sc.LLMClient, cfg), runID, runPath, opts, notify)
+		if err == nil && result.IsError {
+			err = toolResultError(result)
+		}
+		if err != nil {
+			summary["scoring_error"] = err.Error()
+			return err
+		}
+
+		var scoring map[string]interface{}
+		if err := json.Unmarshal([]byte(toolResultText(result)), &scoring); err != nil {
+			return fmt.Errorf("failed to parse scores: %w", err)
+		}
+		delete(scoring, "run_id")
+		summary["scoring"] = scoring
+		return nil
+	}
+}
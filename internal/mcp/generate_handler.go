@@ -0,0 +1,119 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/llm-testing/internal/generator"
+	"github.com/giantswarm/llm-testing/internal/server"
+)
+
+// GeneratedSuitesDir is the directory inside the output directory that
+// generated suites are written to for review.
+const GeneratedSuitesDir = "generated-suites"
+
+// generateSuiteArgs are the arguments of generate_suite.
+type generateSuiteArgs struct {
+	Name                 string `json:"name" jsonschema_description:"Name of the suite, also used as its directory name"`
+	DocsDir              string `json:"docs_dir,omitempty" jsonschema_description:"Directory of reference documents (markdown, text, and a urls.txt listing URLs), relative to the output directory"`
+	URLs                 string `json:"urls,omitempty" jsonschema_description:"JSON array of http(s) URLs of reference documents, e.g. [\"https://kubernetes.io/docs/concepts/services-networking/service/\"]"`
+	Model                string `json:"model,omitempty" jsonschema_description:"Model drafting the questions (default: server scoring model)"`
+	QuestionsPerDocument int    `json:"questions_per_document,omitempty" jsonschema:"minimum=1" jsonschema_description:"Questions to draft per document; long documents are split into parts (default: 5)"`
+	Description          string `json:"description,omitempty" jsonschema_description:"Description of the suite"`
+	Force                bool   `json:"force,omitempty" jsonschema_description:"Overwrite a previously generated suite of the same name (default: false)"`
+}
+
+func handleGenerateSuite(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	if sc.LLMClient == nil {
+		return toolError(codeNotConfigured, "LLM client is not configured"), nil
+	}
+
+	args, err := bindArgs[generateSuiteArgs](request)
+	if err != nil {
+		return toolErrorFrom(err, codeInvalidArgument), nil
+	}
+
+	name := args.Name
+	if strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return toolError(codeInvalidArgument, "name must not contain path separators"), nil
+	}
+
+	var sources []string
+	if docsDir := args.DocsDir; docsDir != "" {
+		safeDocsDir, err := resolvePathWithinBase(sc.OutputDir, docsDir)
+		if err != nil {
+			return toolErrorf(codeInvalidArgument, "invalid docs_dir: %v", err), nil
+		}
+		sources = append(sources, safeDocsDir)
+	}
+	if urlsJSON := args.URLs; urlsJSON != "" {
+		var urls []string
+		if err := json.Unmarshal([]byte(urlsJSON), &urls); err != nil {
+			return toolErrorf(codeInvalidArgument, "invalid urls JSON: %v", err), nil
+		}
+		for _, u := range urls {
+			if !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+				return toolErrorf(codeInvalidArgument, "invalid URL %q: only http(s) URLs are supported", u), nil
+			}
+		}
+		sources = append(sources, urls...)
+	}
+	if len(sources) == 0 {
+		return toolError(codeInvalidArgument, "at least one of 'docs_dir' or 'urls' is required"), nil
+	}
+
+	cfg := generator.Config{Model: sc.ScoringModel}
+	client := sc.LLMClient
+	if model := args.Model; model != "" {
+		name, aliasClient, err := aliasedModel(sc, model)
+		if err != nil {
+			return toolErrorFrom(err, codeInvalidArgument), nil
+		}
+		cfg.Model = name
+		if aliasClient != nil {
+			client = aliasClient
+		}
+	}
+	if cfg.Model == "" {
+		return toolError(codeInvalidArgument, "model is required: the server has no default scoring model"), nil
+	}
+	cfg.QuestionsPerDocument = args.QuestionsPerDocument
+
+	docs, err := generator.LoadDocuments(ctx, sources, generator.FetchOptions{AllowPrivateNetworks: sc.AllowPrivateURLs})
+	if err != nil {
+		return toolErrorFrom(err, codeInvalidArgument), nil
+	}
+
+	g := generator.New(client, cfg)
+	notify := newProgressNotifier(ctx, request)
+	g.SetProgressFunc(func(completed, total int) {
+		notify(float64(completed), float64(total), fmt.Sprintf("drafted questions from document part %d/%d", completed, total))
+	})
+	result, err := g.Generate(ctx, docs)
+	if err != nil {
+		return toolErrorFromf(err, codeLLMError, "failed to generate questions: %v", err), nil
+	}
+
+	suiteDir := filepath.Join(sc.OutputDir, GeneratedSuitesDir, name)
+	info := generator.SuiteInfo{Name: name, Description: args.Description, Model: cfg.Model, Sources: sources}
+	if err := generator.WriteSuite(suiteDir, info, result.Questions, args.Force); err != nil {
+		return toolErrorFrom(err, codeInternal), nil
+	}
+
+	data, err := json.MarshalIndent(map[string]interface{}{
+		"suite_dir": suiteDir,
+		"documents": len(docs),
+		"questions": len(result.Questions),
+		"failed":    result.Failed,
+		"next_step": "Review config.yaml and questions.csv, then move the suite to the suites directory.",
+	}, "", "  ")
+	if err != nil {
+		return toolErrorf(codeInternal, "failed to marshal result: %v", err), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
@@ -3,16 +3,35 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/giantswarm/llm-testing/internal/history"
+	"github.com/giantswarm/llm-testing/internal/jobs"
+	"github.com/giantswarm/llm-testing/internal/kserve"
+	"github.com/giantswarm/llm-testing/internal/llm"
+	"github.com/giantswarm/llm-testing/internal/scorer"
 	"github.com/giantswarm/llm-testing/internal/server"
+	"github.com/giantswarm/llm-testing/internal/testsuite"
 	"github.com/giantswarm/llm-testing/internal/testutil"
+	"github.com/giantswarm/llm-testing/internal/webhook"
 )
 
 func TestHandleListTestSuites(t *testing.T) {
@@ -40,6 +59,90 @@ func TestHandleListTestSuites(t *testing.T) {
 	assert.Contains(t, s, "version")
 	assert.Contains(t, s, "strategy")
 	assert.Contains(t, s, "question_count")
+	assert.Contains(t, s, "sections")
+	assert.Contains(t, s, "mean_question_chars")
+	assert.Contains(t, s, "estimated_prompt_tokens")
+}
+
+func TestHandleValidateTestSuite(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "broken"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken", "config.yaml"), []byte("name: Broken\nstrategy: tool-use\nprompt:\n  system_message: hi\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken", "questions.csv"), []byte("ID,Section,Question,ExpectedAnswer\n1,a,q,\n"), 0o644))
+	sc := &server.ServerContext{SuitesDir: dir}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"test_suite": "broken"}
+	result, err := handleValidateTestSuite(context.Background(), request, sc)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var report testsuite.ValidationReport
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &report))
+	assert.False(t, report.Valid)
+	assert.Equal(t, []testsuite.ValidationIssue{
+		{File: "questions.csv", Line: 2, Severity: testsuite.SeverityError, Message: "empty expected answer"},
+		{File: "config.yaml", Severity: testsuite.SeverityError, Message: "unsupported evaluation strategy: tool-use"},
+	}, report.Issues)
+
+	request.Params.Arguments = map[string]interface{}{"test_suite": "../broken"}
+	result, err = handleValidateTestSuite(context.Background(), request, sc)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestHandleValidateTestSuiteLint(t *testing.T) {
+	client := &testutil.MockLLMClient{DefaultResponse: "AMBIGUOUS\nToo short."}
+	sc := &server.ServerContext{LLMClient: client, ScoringModel: "judge"}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"test_suite": "kubernetes-cka-v2", "lint": true, "scoring_model": "linter"}
+	result, err := handleValidateTestSuite(context.Background(), request, sc)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var report testsuite.ValidationReport
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &report))
+	require.NotNil(t, report.Lint)
+	assert.Equal(t, "linter", report.Lint.Model)
+	assert.Equal(t, report.Questions, report.Lint.Flagged)
+	assert.Equal(t, "linter", client.LastRequest.Model)
+}
+
+func TestHandleGenerateSuite(t *testing.T) {
+	outputDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(outputDir, "docs"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(outputDir, "docs", "pods.md"), []byte("# Pods"), 0o644))
+	client := &testutil.MockLLMClient{DefaultResponse: `[{"question": "What is a Pod?", "answer": "A group of containers", "section": "Pods"}]`}
+	sc := &server.ServerContext{LLMClient: client, OutputDir: outputDir, ScoringModel: "judge"}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"name": "pods", "docs_dir": "docs"}
+	result, err := handleGenerateSuite(context.Background(), request, sc)
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content[0].(mcp.TextContent).Text)
+	assert.Equal(t, "judge", client.LastRequest.Model)
+
+	suite, err := testsuite.Load("pods", filepath.Join(outputDir, GeneratedSuitesDir))
+	require.NoError(t, err)
+	require.Len(t, suite.Questions, 1)
+	assert.Equal(t, "What is a Pod?", suite.Questions[0].QuestionText)
+
+	result, err = handleGenerateSuite(context.Background(), request, sc)
+	require.NoError(t, err)
+	assert.True(t, result.IsError, "existing suites are not overwritten without force")
+
+	for _, args := range []map[string]interface{}{
+		{"name": "pods"},
+		{"name": "../pods", "docs_dir": "docs"},
+		{"name": "pods", "docs_dir": "../"},
+		{"name": "pods", "urls": `["file:///etc/passwd"]`},
+	} {
+		request.Params.Arguments = args
+		result, err = handleGenerateSuite(context.Background(), request, sc)
+		require.NoError(t, err)
+		assert.True(t, result.IsError, "arguments %v", args)
+	}
 }
 
 func TestHandleRunTestSuiteMissingRequired(t *testing.T) {
@@ -122,6 +225,150 @@ func TestHandleRunTestSuiteInvalidModelsJSON(t *testing.T) {
 	assert.Contains(t, content.Text, "invalid models JSON")
 }
 
+func TestHandleRunTestSuiteAsync(t *testing.T) {
+	sc := &server.ServerContext{
+		LLMClient: &testutil.MockLLMClient{DefaultResponse: "An answer."},
+		OutputDir: t.TempDir(),
+		Jobs:      jobs.NewManager(context.Background()),
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{
+		"test_suite": "kubernetes-cka-v2",
+		"model":      "test-model",
+		"async":      true,
+	}
+	result, err := handleRunTestSuite(context.Background(), request, sc)
+	require.NoError(t, err)
+	require.False(t, result.IsError, toolResultText(result))
+
+	var started map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(toolResultText(result)), &started))
+	runID, _ := started["run_id"].(string)
+	require.NotEmpty(t, runID)
+	assert.Equal(t, "running", started["state"])
+
+	job, err := sc.Jobs.Get(runID)
+	require.NoError(t, err)
+	<-job.Done()
+
+	statusRequest := mcp.CallToolRequest{}
+	statusRequest.Params.Arguments = map[string]interface{}{"run_id": runID}
+	result, err = handleGetRunStatus(context.Background(), statusRequest, sc)
+	require.NoError(t, err)
+	require.False(t, result.IsError, toolResultText(result))
+
+	var status jobs.Status
+	require.NoError(t, json.Unmarshal([]byte(toolResultText(result)), &status))
+	assert.Equal(t, jobs.StateSucceeded, status.State)
+	require.Len(t, status.Progress, 1)
+	assert.Equal(t, "test-model", status.Progress[0].Model)
+	assert.Equal(t, status.Progress[0].TotalQuestions, status.Progress[0].CompletedQuestions)
+	assert.Contains(t, status.Result, "models")
+	assert.DirExists(t, filepath.Join(sc.OutputDir, runID))
+
+	result, err = handleCancelRun(context.Background(), statusRequest, sc)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, toolResultText(result), "is not running (state: succeeded)")
+
+	statusRequest.Params.Arguments = map[string]interface{}{"run_id": "unknown"}
+	result, err = handleCancelRun(context.Background(), statusRequest, sc)
+	require.NoError(t, err)
+	assert.Contains(t, toolResultText(result), "run not found: unknown")
+}
+
+func TestResumeRun(t *testing.T) {
+	sc := &server.ServerContext{
+		LLMClient: &testutil.MockLLMClient{DefaultResponse: "An answer."},
+		OutputDir: t.TempDir(),
+	}
+	resume := ResumeRun(sc)
+
+	work, err := resume("kubernetes-cka-v2-resumed", json.RawMessage(`{"test_suite":"kubernetes-cka-v2","model":"test-model","async":true}`))
+	require.NoError(t, err)
+	job, err := jobs.NewManager(context.Background()).Start("kubernetes-cka-v2-resumed", work)
+	require.NoError(t, err)
+	<-job.Done()
+	assert.Equal(t, jobs.StateSucceeded, job.Status().State, job.Status().Error)
+	assert.DirExists(t, filepath.Join(sc.OutputDir, "kubernetes-cka-v2-resumed"))
+
+	_, err = resume("run-2", json.RawMessage(`{"test_suite":"no-such-suite","model":"test-model"}`))
+	assert.ErrorContains(t, err, "failed to load test suite")
+}
+
+// blockingClient answers no question until its request is cancelled.
+type blockingClient struct {
+	testutil.MockLLMClient
+	started chan struct{}
+}
+
+func (c *blockingClient) ChatCompletion(ctx context.Context, _ llm.ChatRequest) (*llm.ChatResponse, error) {
+	select {
+	case c.started <- struct{}{}:
+	default:
+	}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestHandleCancelRun(t *testing.T) {
+	client := &blockingClient{started: make(chan struct{}, 1)}
+	sc := &server.ServerContext{
+		LLMClient: client,
+		OutputDir: t.TempDir(),
+		Jobs:      jobs.NewManager(context.Background()),
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{
+		"test_suite": "kubernetes-cka-v2",
+		"model":      "test-model",
+	}
+	done := make(chan *mcp.CallToolResult)
+	go func() {
+		result, _ := handleRunTestSuite(context.Background(), request, sc)
+		done <- result
+	}()
+	<-client.started
+
+	runs := sc.Jobs.List()
+	require.Len(t, runs, 1)
+	cancelRequest := mcp.CallToolRequest{}
+	cancelRequest.Params.Arguments = map[string]interface{}{"run_id": runs[0].ID}
+	result, err := handleCancelRun(context.Background(), cancelRequest, sc)
+	require.NoError(t, err)
+	require.False(t, result.IsError, toolResultText(result))
+
+	result = <-done
+	require.False(t, result.IsError, toolResultText(result))
+	var summary map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(toolResultText(result)), &summary))
+	assert.Equal(t, true, summary["cancelled"], toolResultText(result))
+
+	job, err := sc.Jobs.Get(runs[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, jobs.StateCancelled, job.Status().State)
+
+	data, err := os.ReadFile(filepath.Join(sc.OutputDir, runs[0].ID, "resultset.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"cancelled": true`)
+}
+
+func TestHandleRunTestSuiteAsyncDisabled(t *testing.T) {
+	sc := &server.ServerContext{LLMClient: &testutil.MockLLMClient{}}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{
+		"test_suite": "kubernetes-cka-v2",
+		"model":      "test-model",
+		"async":      true,
+	}
+	result, err := handleRunTestSuite(context.Background(), request, sc)
+	require.NoError(t, err)
+	assert.Contains(t, toolResultText(result), "async runs are not enabled")
+}
+
 func TestHandleRunTestSuiteEmptyModelName(t *testing.T) {
 	sc := &server.ServerContext{
 		LLMClient: &testutil.MockLLMClient{},
@@ -140,6 +387,161 @@ func TestHandleRunTestSuiteEmptyModelName(t *testing.T) {
 	assert.Contains(t, content.Text, "model name cannot be empty")
 }
 
+func TestHandleRunTestSuiteNegativeTemperature(t *testing.T) {
+	sc := &server.ServerContext{
+		LLMClient: &testutil.MockLLMClient{},
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{
+		"test_suite": "kubernetes-cka-v2",
+		"models":     `[{"name":"mistral","temperature":-0.5}]`,
+	}
+
+	result, err := handleRunTestSuite(context.Background(), request, sc)
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, `temperature for model "mistral" cannot be negative`)
+}
+
+func TestHandleRunTestSuiteInvalidReasoningEffort(t *testing.T) {
+	sc := &server.ServerContext{
+		LLMClient: &testutil.MockLLMClient{},
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{
+		"test_suite":       "kubernetes-cka-v2",
+		"model":            "deepseek-r1",
+		"reasoning_effort": "extreme",
+	}
+
+	result, err := handleRunTestSuite(context.Background(), request, sc)
+	require.NoError(t, err)
+
+	content := result.Content[0].(mcp.TextContent)
+	assert.Contains(t, content.Text, `invalid reasoning_effort "extreme": must be one of low, medium, high`)
+}
+
+func TestParseModelsReasoningParams(t *testing.T) {
+	models, err := parseModels(runTestSuiteArgs{
+		Model:           "deepseek-r1",
+		ReasoningEffort: "high",
+		ExtraParams:     `{"chat_template_kwargs":{"enable_thinking":true}}`,
+	}, nil)
+	require.NoError(t, err)
+	require.Len(t, models, 1)
+	assert.Equal(t, "high", models[0].ReasoningEffort)
+	assert.Equal(t, map[string]any{"chat_template_kwargs": map[string]any{"enable_thinking": true}}, models[0].ExtraParams)
+
+	_, err = parseModels(runTestSuiteArgs{Model: "m", ExtraParams: `[1]`}, nil)
+	assert.ErrorContains(t, err, "invalid extra_params JSON")
+}
+
+func TestHandleRunTestSuiteUnknownProvider(t *testing.T) {
+	sc := &server.ServerContext{
+		LLMClient: &testutil.MockLLMClient{},
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{
+		"test_suite": "kubernetes-cka-v2",
+		"models":     `[{"name":"claude-sonnet-4-5","provider":"team-claude"}]`,
+	}
+
+	result, err := handleRunTestSuite(context.Background(), request, sc)
+	require.NoError(t, err)
+
+	content := result.Content[0].(mcp.TextContent)
+	assert.Contains(t, content.Text, `unknown provider "team-claude"`)
+}
+
+func TestClientForModelProvider(t *testing.T) {
+	registry, err := llm.NewRegistry(map[string]llm.ProviderConfig{
+		"team-claude": {Type: llm.ProviderAnthropic, APIKeyEnv: "TEAM_ANTHROPIC_KEY"},
+	})
+	require.NoError(t, err)
+	sc := &server.ServerContext{
+		LLMClient: &testutil.MockLLMClient{},
+		Providers: registry,
+	}
+	endpoint := "http://localhost:8000/v1"
+
+	client, scope, err := clientForModel(context.Background(), sc, testsuite.Model{Name: "claude", Provider: "team-claude"}, endpoint, true, nil, nil)
+	require.NoError(t, err)
+	assert.IsType(t, &llm.AnthropicClient{}, client)
+	assert.Equal(t, "provider|team-claude|anthropic|", scope)
+
+	// Models without a provider keep using the explicit endpoint.
+	client, scope, err = clientForModel(context.Background(), sc, testsuite.Model{Name: "mistral"}, endpoint, true, nil, nil)
+	require.NoError(t, err)
+	assert.IsType(t, &llm.OpenAIClient{}, client)
+	assert.Equal(t, "endpoint|http://localhost:8000/v1", scope)
+}
+
+func TestClientForModelCacheScopeOfDiscoveredModel(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "serving.kserve.io", Version: "v1beta1", Resource: "inferenceservices"}
+	isvc := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{
+			"url":        "http://mistral.llm-testing.example.com",
+			"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": "True"}},
+		},
+	}}
+	isvc.SetAPIVersion("serving.kserve.io/v1beta1")
+	isvc.SetKind("InferenceService")
+	isvc.SetName("mistral")
+	isvc.SetNamespace("llm-testing")
+	isvc.SetAnnotations(map[string]string{"llm-testing.giantswarm.io/spec-hash": "aaaa"})
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "InferenceServiceList"}, isvc)
+	sc := &server.ServerContext{
+		LLMClient:     &testutil.MockLLMClient{},
+		KServeManager: kserve.NewManagerWithClient(client, "llm-testing"),
+	}
+	model := testsuite.Model{Name: "mistral"}
+
+	_, scope, err := clientForModel(context.Background(), sc, model, "", false, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "kserve|llm-testing/mistral|aaaa", scope)
+
+	// Redeploying the model with other settings changes its scope.
+	isvc.SetAnnotations(map[string]string{"llm-testing.giantswarm.io/spec-hash": "bbbb"})
+	_, err = client.Resource(gvr).Namespace("llm-testing").Update(context.Background(), isvc, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	_, scope, err = clientForModel(context.Background(), sc, model, "", false, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "kserve|llm-testing/mistral|bbbb", scope)
+
+	// Models found nowhere use the default client.
+	_, scope, err = clientForModel(context.Background(), sc, testsuite.Model{Name: "other"}, "", false, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "default", scope)
+}
+
+func TestClientForModelOpenAIProvider(t *testing.T) {
+	var requested bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"ok"}}]}`)
+	}))
+	defer srv.Close()
+
+	registry, err := llm.NewRegistry(map[string]llm.ProviderConfig{
+		llm.ProviderOpenAI: {Endpoint: srv.URL},
+	})
+	require.NoError(t, err)
+	sc := &server.ServerContext{
+		LLMClient: &testutil.MockLLMClient{},
+		Providers: registry,
+	}
+	// The configured openai provider is used instead of the explicit endpoint.
+	client, _, err := clientForModel(context.Background(), sc, testsuite.Model{Name: "gpt-4o", Provider: llm.ProviderOpenAI}, "http://localhost:8000/v1", true, nil, nil)
+	require.NoError(t, err)
+	_, err = client.ChatCompletion(context.Background(), llm.ChatRequest{Model: "gpt-4o", UserMessage: "hi"})
+	require.NoError(t, err)
+	assert.True(t, requested)
+}
+
 func TestHandleScoreResultsMissingRequired(t *testing.T) {
 	sc := &server.ServerContext{
 		LLMClient: nil, // No client configured.
@@ -188,6 +590,24 @@ func TestHandleScoreResultsNeitherRunIDNorFile(t *testing.T) {
 	assert.Contains(t, content.Text, "either 'run_id' or 'results_file' is required")
 }
 
+func TestHandleScoreResultsJUnitRequiresPerQuestion(t *testing.T) {
+	sc := &server.ServerContext{
+		LLMClient: &testutil.MockLLMClient{},
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{
+		"results_file": "some-file.txt",
+		"format":       "junit",
+	}
+
+	result, err := handleScoreResults(context.Background(), request, sc)
+	require.NoError(t, err)
+
+	content := result.Content[0].(mcp.TextContent)
+	assert.Contains(t, content.Text, "format 'junit' requires mode 'per_question'")
+}
+
 func TestHandleScoreResultsBothRunIDAndFile(t *testing.T) {
 	sc := &server.ServerContext{
 		LLMClient: &testutil.MockLLMClient{},
@@ -297,8 +717,10 @@ func TestHandleGetResultsEmptyDir(t *testing.T) {
 	require.NoError(t, err)
 
 	content := result.Content[0].(mcp.TextContent)
-	// Should return empty list, not an error.
-	assert.Equal(t, "[]", content.Text)
+	// Should return an empty list, not an error.
+	assert.False(t, result.IsError)
+	assert.Contains(t, content.Text, `"total": 0`)
+	assert.Contains(t, content.Text, `"runs": []`)
 }
 
 func TestHandleGetResultsNonexistentDir(t *testing.T) {
@@ -313,7 +735,8 @@ func TestHandleGetResultsNonexistentDir(t *testing.T) {
 	require.NoError(t, err)
 
 	content := result.Content[0].(mcp.TextContent)
-	assert.Equal(t, "[]", content.Text)
+	assert.False(t, result.IsError)
+	assert.Contains(t, content.Text, `"runs": []`)
 }
 
 func TestHandleGetResultsSpecificRun(t *testing.T) {
@@ -340,6 +763,181 @@ func TestHandleGetResultsSpecificRun(t *testing.T) {
 	assert.Contains(t, content.Text, "test-run")
 }
 
+func TestHandleGetResultsFilters(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeRun := func(id, suite, model, timestamp string, scored bool) {
+		runDir := filepath.Join(tmpDir, id)
+		require.NoError(t, os.MkdirAll(runDir, 0o755))
+		metadata := fmt.Sprintf(`{"id": %q, "suite": %q, "timestamp": %q, "models": [{"model_name": %q, "results_file": "x.txt"}]}`, id, suite, timestamp, model)
+		require.NoError(t, os.WriteFile(filepath.Join(runDir, "resultset.json"), []byte(metadata), 0o644))
+		if scored {
+			require.NoError(t, os.WriteFile(filepath.Join(runDir, model+"_scores.json"), []byte(`{}`), 0o644))
+		}
+	}
+	writeRun("cka-1", "Kubernetes CKA", "qwen-7b", "2026-01-10T10:00:00Z", true)
+	writeRun("cka-2", "Kubernetes CKA", "llama-8b", "2026-02-10T10:00:00Z", false)
+	writeRun("ckad-1", "Kubernetes CKAD", "qwen-7b", "2026-03-10T10:00:00Z", true)
+
+	sc := &server.ServerContext{OutputDir: tmpDir}
+	list := func(args map[string]interface{}) (listing struct {
+		Total   int                      `json:"total"`
+		HasMore bool                     `json:"has_more"`
+		Runs    []map[string]interface{} `json:"runs"`
+	}) {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = args
+		result, err := handleGetResults(context.Background(), request, sc)
+		require.NoError(t, err)
+		require.False(t, result.IsError, toolResultText(result))
+		require.NoError(t, json.Unmarshal([]byte(toolResultText(result)), &listing))
+		return listing
+	}
+	ids := func(runs []map[string]interface{}) []string {
+		var ids []string
+		for _, run := range runs {
+			ids = append(ids, run["id"].(string))
+		}
+		return ids
+	}
+
+	listing := list(map[string]interface{}{})
+	assert.Equal(t, 3, listing.Total)
+	assert.Equal(t, []string{"ckad-1", "cka-2", "cka-1"}, ids(listing.Runs))
+
+	listing = list(map[string]interface{}{"suite": "kubernetes cka", "sort": "oldest"})
+	assert.Equal(t, []string{"cka-1", "cka-2"}, ids(listing.Runs))
+
+	listing = list(map[string]interface{}{"model": "qwen", "has_scores": true})
+	assert.Equal(t, []string{"ckad-1", "cka-1"}, ids(listing.Runs))
+
+	listing = list(map[string]interface{}{"since": "2026-02-01", "until": "2026-02-10"})
+	assert.Equal(t, []string{"cka-2"}, ids(listing.Runs))
+
+	listing = list(map[string]interface{}{"limit": float64(1), "offset": float64(1)})
+	assert.Equal(t, 3, listing.Total)
+	assert.True(t, listing.HasMore)
+	assert.Equal(t, []string{"cka-2"}, ids(listing.Runs))
+
+	// Offsets beyond the runs, however large, list none.
+	listing = list(map[string]interface{}{"limit": float64(100), "offset": float64(1 << 62)})
+	assert.Equal(t, 3, listing.Total)
+	assert.False(t, listing.HasMore)
+	assert.Empty(t, listing.Runs)
+
+	listing = list(map[string]interface{}{"summary": true, "limit": float64(1)})
+	require.Len(t, listing.Runs, 1)
+	assert.Equal(t, []interface{}{"qwen-7b"}, listing.Runs[0]["models"])
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"since": "last week"}
+	result, err := handleGetResults(context.Background(), request, sc)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, toolResultText(result), "invalid since")
+}
+
+func TestHandleGetResultsWarnsOfSuiteChanges(t *testing.T) {
+	tmpDir := t.TempDir()
+	for id, hash := range map[string]string{"cka-1": "sha256:a", "cka-2": "sha256:b", "ckad-1": "sha256:c", "ckad-2": "sha256:c"} {
+		runDir := filepath.Join(tmpDir, id)
+		require.NoError(t, os.MkdirAll(runDir, 0o755))
+		suite := "Kubernetes CKA"
+		if id[:4] == "ckad" {
+			suite = "Kubernetes CKAD"
+		}
+		metadata := fmt.Sprintf(`{"id": %q, "suite": %q, "suite_version": "1", "suite_hash": %q, "timestamp": "2026-01-10T10:00:00Z"}`, id, suite, hash)
+		require.NoError(t, os.WriteFile(filepath.Join(runDir, "resultset.json"), []byte(metadata), 0o644))
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"summary": true}
+	result, err := handleGetResults(context.Background(), request, &server.ServerContext{OutputDir: tmpDir})
+	require.NoError(t, err)
+	var listing struct {
+		Runs     []map[string]interface{} `json:"runs"`
+		Warnings []string                 `json:"warnings"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(toolResultText(result)), &listing))
+	assert.Equal(t, []string{`runs of suite "Kubernetes CKA" were executed against 2 different versions of its content (see suite_hash); their scores are not directly comparable`}, listing.Warnings)
+	require.Len(t, listing.Runs, 4)
+	assert.NotEmpty(t, listing.Runs[0]["suite_hash"])
+	assert.Equal(t, "1", listing.Runs[0]["suite_version"])
+}
+
+func TestHandleGetQuestionResults(t *testing.T) {
+	tmpDir := t.TempDir()
+	runDir := filepath.Join(tmpDir, "run-1")
+	require.NoError(t, os.MkdirAll(runDir, 0o755))
+	resultsFile := filepath.Join(runDir, "qwen-7b.txt")
+	metadata := fmt.Sprintf(`{"id": "run-1", "models": [{"model_name": "qwen-7b", "results_file": %q}]}`, resultsFile)
+	require.NoError(t, os.WriteFile(filepath.Join(runDir, "resultset.json"), []byte(metadata), 0o644))
+
+	// Question 2 is from a blind run; its expected answer is in the answer key.
+	results := "---\nNO. 1 - Pods\nQUESTION: What is a pod?\nEXPECTED ANSWER: A group of containers\nACTUAL ANSWER: A container group\n" +
+		"---\nNO. 2 - Pods\nQUESTION: What restarts pods?\nACTUAL ANSWER: The scheduler\n" +
+		"---\nNO. 3 - Networking\nQUESTION: What is a service?\nEXPECTED ANSWER: A stable endpoint\nACTUAL ANSWER: A load balancer\n"
+	require.NoError(t, os.WriteFile(resultsFile, []byte(results), 0o644))
+	require.NoError(t, testsuite.WriteAnswerKey(runDir, []testsuite.Question{{ID: "2", ExpectedAnswer: "The kubelet"}}))
+
+	// Question 3 was judged during the run, questions 1 and 2 by scoring.
+	correct := false
+	require.NoError(t, testsuite.WriteQuestionRecords(resultsFile, []*testsuite.Result{
+		{Question: testsuite.Question{ID: "1"}, Duration: 1500 * time.Millisecond, CompletionTokens: 12},
+		{Question: testsuite.Question{ID: "3"}, Duration: time.Second, Verdict: &correct},
+	}))
+	scores := `{"runs": [
+		{"questions": [{"id": "1", "correct": true}, {"id": "2", "correct": false}]},
+		{"questions": [{"id": "1", "correct": false}, {"id": "2", "correct": false}]}
+	]}`
+	require.NoError(t, os.WriteFile(scorer.ScoreFilePath(resultsFile), []byte(scores), 0o644))
+
+	sc := &server.ServerContext{OutputDir: tmpDir}
+	get := func(args map[string]interface{}) (listing struct {
+		Total     int              `json:"total"`
+		HasMore   bool             `json:"has_more"`
+		Questions []questionResult `json:"questions"`
+	}) {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = args
+		result, err := handleGetQuestionResults(context.Background(), request, sc)
+		require.NoError(t, err)
+		require.False(t, result.IsError, toolResultText(result))
+		require.NoError(t, json.Unmarshal([]byte(toolResultText(result)), &listing))
+		return listing
+	}
+
+	listing := get(map[string]interface{}{"run_id": "run-1", "model": "qwen-7b"})
+	require.Equal(t, 3, listing.Total)
+	q := listing.Questions[0]
+	assert.Equal(t, "What is a pod?", q.Question)
+	assert.Equal(t, "A container group", q.ActualAnswer)
+	require.NotNil(t, q.Duration)
+	assert.Equal(t, 1.5, *q.Duration)
+	assert.Equal(t, 12, q.CompletionTokens)
+	assert.Equal(t, verdictUnstable, q.Verdict)
+	assert.Equal(t, 1, q.CorrectRuns)
+	assert.Equal(t, 2, q.JudgedRuns)
+	assert.Equal(t, "The kubelet", listing.Questions[1].ExpectedAnswer)
+	assert.Nil(t, listing.Questions[1].Duration)
+	assert.Equal(t, verdictIncorrect, listing.Questions[2].Verdict)
+
+	listing = get(map[string]interface{}{"run_id": "run-1", "model": "qwen-7b", "verdict": "incorrect"})
+	assert.Equal(t, 2, listing.Total)
+
+	listing = get(map[string]interface{}{"run_id": "run-1", "model": "qwen-7b", "section": "pods", "limit": float64(1)})
+	assert.Equal(t, 2, listing.Total)
+	assert.True(t, listing.HasMore)
+	require.Len(t, listing.Questions, 1)
+	assert.Equal(t, "1", listing.Questions[0].ID)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"run_id": "run-1", "model": "llama-8b"}
+	result, err := handleGetQuestionResults(context.Background(), request, sc)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, toolResultText(result), `model "llama-8b" not found in run "run-1" (models: qwen-7b)`)
+}
+
 func TestHandleGetResultsRunIDPathTraversal(t *testing.T) {
 	sc := &server.ServerContext{
 		OutputDir: t.TempDir(),
@@ -375,6 +973,98 @@ func TestHandleDeployModelNoManager(t *testing.T) {
 	assert.Contains(t, content.Text, "KServe manager is not configured")
 }
 
+func TestHandleRenderModelManifest(t *testing.T) {
+	sc := &server.ServerContext{Namespace: "llm-testing"}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{
+		"model_name":   "qwen",
+		"model_uri":    "hf://Qwen/Qwen2.5-7B-Instruct-AWQ",
+		"quantization": "awq",
+	}
+
+	result, err := handleRenderModelManifest(context.Background(), request, sc)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "kind: InferenceService")
+	assert.Contains(t, text, "namespace: llm-testing")
+	assert.Contains(t, text, "--quantization=awq")
+
+	request.Params.Arguments.(map[string]interface{})["quantization"] = "int4"
+	result, err = handleRenderModelManifest(context.Background(), request, sc)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "unsupported quantization")
+}
+
+func TestModelAliases(t *testing.T) {
+	sc := &server.ServerContext{
+		Namespace: "llm-testing",
+		ModelAliases: testsuite.ModelAliases{
+			"judge-default": {Name: "claude-sonnet-4-5", Provider: "anthropic"},
+			"small-gpu": {
+				Name:        "qwen2.5-7b",
+				ModelURI:    "hf://Qwen/Qwen2.5-7B-Instruct",
+				GPUCount:    1,
+				Temperature: 0.2,
+				Tolerations: []corev1.Toleration{{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists}},
+			},
+		},
+	}
+
+	// Deploy tools take the settings of the alias, unless given.
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"model_name": "small-gpu", "runtime_args": []interface{}{"--max-model-len=8192"}}
+	result, err := handleRenderModelManifest(context.Background(), request, sc)
+	require.NoError(t, err)
+	require.False(t, result.IsError, toolResultText(result))
+	text := toolResultText(result)
+	assert.Contains(t, text, "name: qwen2.5-7b")
+	assert.Contains(t, text, "hf://Qwen/Qwen2.5-7B-Instruct")
+	assert.Contains(t, text, "--max-model-len=8192")
+	assert.Contains(t, text, "key: nvidia.com/gpu")
+
+	request.Params.Arguments = map[string]interface{}{"model_name": "judge-default"}
+	result, err = handleRenderModelManifest(context.Background(), request, sc)
+	require.NoError(t, err)
+	assert.Contains(t, toolResultText(result), `model alias "judge-default" names a model of provider "anthropic"`)
+
+	// Test runs take the settings of the alias, unless given.
+	temperature := 0.7
+	models, err := parseModels(runTestSuiteArgs{Model: "small-gpu", Temperature: &temperature}, sc.ModelAliases)
+	require.NoError(t, err)
+	require.Len(t, models, 1)
+	assert.Equal(t, "qwen2.5-7b", models[0].Name)
+	assert.Equal(t, "hf://Qwen/Qwen2.5-7B-Instruct", models[0].ModelURI)
+	assert.Equal(t, 0.7, models[0].Temperature)
+	models, err = parseModels(runTestSuiteArgs{Models: `[{"name": "judge-default"}]`}, sc.ModelAliases)
+	require.NoError(t, err)
+	assert.Equal(t, []testsuite.Model{{Name: "claude-sonnet-4-5", Provider: "anthropic"}}, models)
+
+	// Judges are named by their model.
+	cfg, _, err := parseScoreConfig(scoreResultsArgs{
+		judgeArgs: judgeArgs{ScoringModel: "judge-default", Mode: "per_question"},
+		Judges:    `[{"model": "judge-default"}, {"model": "gpt-4o"}]`,
+	}, sc)
+	require.NoError(t, err)
+	assert.Equal(t, "claude-sonnet-4-5", cfg.Model)
+	assert.Equal(t, []scorer.Judge{{Model: "claude-sonnet-4-5"}, {Model: "gpt-4o"}}, cfg.Judges)
+	// Judges of another provider are reached through it, the others
+	// through the server's default client.
+	require.Contains(t, cfg.Clients, "claude-sonnet-4-5")
+	assert.IsType(t, &llm.AnthropicClient{}, cfg.Clients["claude-sonnet-4-5"])
+	assert.NotContains(t, cfg.Clients, "gpt-4o")
+
+	result, err = handleListModelAliases(context.Background(), mcp.CallToolRequest{}, sc)
+	require.NoError(t, err)
+	var listed struct {
+		Aliases map[string]testsuite.Model `json:"aliases"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(toolResultText(result)), &listed))
+	assert.Equal(t, sc.ModelAliases["small-gpu"], listed.Aliases["small-gpu"])
+}
+
 func TestHandleTeardownModelNoManager(t *testing.T) {
 	sc := &server.ServerContext{
 		KServeManager: nil,
@@ -407,6 +1097,188 @@ func TestHandleListModelsNoManager(t *testing.T) {
 	assert.Contains(t, content.Text, "KServe manager is not configured")
 }
 
+func TestHandleListEndpointModels(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/models", r.URL.Path)
+		fmt.Fprint(w, `{"object":"list","data":[{"id":"qwen","object":"model"},{"id":"mistral","object":"model"}]}`)
+	}))
+	defer srv.Close()
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"endpoint": srv.URL + "/v1"}
+
+	result, err := handleListEndpointModels(context.Background(), request, &server.ServerContext{})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var out struct {
+		Models []string `json:"models"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &out))
+	assert.Equal(t, []string{"mistral", "qwen"}, out.Models)
+
+	// Without an endpoint, the server's default client is used.
+	request.Params.Arguments = map[string]interface{}{}
+	result, err = handleListEndpointModels(context.Background(), request, &server.ServerContext{})
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "endpoint is required")
+
+	result, err = handleListEndpointModels(context.Background(), request, &server.ServerContext{LLMClient: &testutil.MockLLMClient{}})
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "cannot list models")
+}
+
+func TestHandleGetModelNoManager(t *testing.T) {
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"model_name": "test"}
+
+	result, err := handleGetModel(context.Background(), request, &server.ServerContext{})
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "KServe manager is not configured")
+}
+
+func TestHandleWatchModelNoManager(t *testing.T) {
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"model_name": "test"}
+
+	result, err := handleWatchModel(context.Background(), request, &server.ServerContext{})
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "KServe manager is not configured")
+}
+
+func TestHandleCleanupModels(t *testing.T) {
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"ttl": "soon"}
+
+	result, err := handleCleanupModels(context.Background(), request, &server.ServerContext{})
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "KServe manager is not configured")
+
+	sc := &server.ServerContext{KServeManager: kserve.NewManagerWithClient(nil, "llm-testing")}
+	result, err = handleCleanupModels(context.Background(), request, sc)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, `invalid ttl "soon"`)
+}
+
+func TestTeardownModelLeavesExistingModels(t *testing.T) {
+	// The manager has no client: a teardown would fail.
+	sc := &server.ServerContext{KServeManager: kserve.NewManagerWithClient(nil, "llm-testing")}
+	model := testsuite.Model{Name: "user-model", ModelURI: "hf://org/model"}
+	assert.NoError(t, teardownModel(context.Background(), sc, model, true))
+}
+
+func TestHandleRuntimeToolsNoManager(t *testing.T) {
+	sc := &server.ServerContext{}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{
+		"name":           "vllm-next",
+		"vllm_image_tag": "nightly",
+	}
+
+	result, err := handleListRuntimes(context.Background(), request, sc)
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "KServe manager is not configured")
+
+	result, err = handleCreateRuntime(context.Background(), request, sc)
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "KServe manager is not configured")
+}
+
+func TestParsePlacement(t *testing.T) {
+	cfg := kserve.DefaultModelConfig("m", "hf://org/model")
+	err := parsePlacement(modelSpecArgs{
+		NodeSelector:     `{"nvidia.com/gpu.product":"NVIDIA-H100-80GB-HBM3"}`,
+		Tolerations:      `[{"key":"nvidia.com/gpu","operator":"Exists","effect":"NoSchedule"}]`,
+		Affinity:         `{"nodeAffinity":{}}`,
+		RuntimeClassName: "nvidia",
+	}, &cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "NVIDIA-H100-80GB-HBM3", cfg.NodeSelector["nvidia.com/gpu.product"])
+	require.Len(t, cfg.Tolerations, 1)
+	assert.Equal(t, "nvidia.com/gpu", cfg.Tolerations[0].Key)
+	require.NotNil(t, cfg.Affinity)
+	assert.Equal(t, "nvidia", cfg.RuntimeClassName)
+
+	err = parsePlacement(modelSpecArgs{Tolerations: `{"key":"x"}`}, &cfg)
+	assert.ErrorContains(t, err, "invalid tolerations JSON")
+}
+
+func TestParseEnv(t *testing.T) {
+	cfg := kserve.DefaultModelConfig("m", "hf://org/model")
+	require.NoError(t, parseEnv(modelSpecArgs{Env: `{"VLLM_ATTENTION_BACKEND":"FLASHINFER"}`}, &cfg))
+	assert.Equal(t, map[string]string{"VLLM_ATTENTION_BACKEND": "FLASHINFER"}, cfg.Env)
+
+	err := parseEnv(modelSpecArgs{Env: `["VLLM_ATTENTION_BACKEND"]`}, &cfg)
+	assert.ErrorContains(t, err, "invalid env JSON")
+}
+
+func TestParseScaling(t *testing.T) {
+	cfg := kserve.DefaultModelConfig("m", "hf://org/model")
+	parseScaling(modelSpecArgs{}, &cfg)
+	assert.Nil(t, cfg.MinReplicas)
+
+	args, err := bindArgMap[modelSpecArgs](map[string]interface{}{
+		"model_name":   "m",
+		"min_replicas": float64(0),
+		"max_replicas": float64(2),
+		"scale_target": float64(8),
+		"scale_metric": "rps",
+	})
+	require.NoError(t, err)
+	parseScaling(args, &cfg)
+	require.NotNil(t, cfg.MinReplicas)
+	assert.Equal(t, 0, *cfg.MinReplicas)
+	assert.Equal(t, 2, cfg.MaxReplicas)
+	assert.Equal(t, 8, cfg.ScaleTarget)
+	assert.Equal(t, "rps", cfg.ScaleMetric)
+}
+
+func TestParseParallelism(t *testing.T) {
+	cfg := kserve.DefaultModelConfig("m", "hf://org/model")
+	parseParallelism(modelSpecArgs{TensorParallelSize: 8, WorkerCount: 1}, &cfg)
+	assert.Equal(t, 8, cfg.TensorParallelSize)
+	assert.Equal(t, 2, cfg.Nodes())
+	assert.Equal(t, 16, cfg.TotalGPUs())
+}
+
+func TestParseCredentials(t *testing.T) {
+	sc := &server.ServerContext{HFTokenSecret: "default-hf"}
+
+	cfg := kserve.DefaultModelConfig("m", "hf://org/model")
+	require.NoError(t, parseCredentials(modelSpecArgs{}, sc, &cfg))
+	assert.Equal(t, "default-hf", cfg.HFTokenSecret)
+
+	err := parseCredentials(modelSpecArgs{
+		HFTokenSecret:    "team-hf",
+		ServiceAccount:   "models",
+		ImagePullSecrets: []string{"registry"},
+		StorageKey:       "minio",
+	}, sc, &cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "minio", cfg.StorageKey)
+	assert.Equal(t, "team-hf", cfg.HFTokenSecret)
+	assert.Equal(t, "models", cfg.ServiceAccountName)
+	assert.Equal(t, []string{"registry"}, cfg.ImagePullSecrets)
+}
+
+func TestTrimStrings(t *testing.T) {
+	values, err := trimStrings("args", []string{" --a ", "--b"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"--a", "--b"}, values)
+
+	values, err = trimStrings("args", nil)
+	require.NoError(t, err)
+	assert.Nil(t, values)
+
+	_, err = trimStrings("args", []string{" "})
+	assert.ErrorContains(t, err, "args entries must be non-empty")
+
+	_, err = bindArgMap[createRuntimeArgs](map[string]interface{}{"name": "r", "args": []interface{}{1}})
+	assert.EqualError(t, err, "invalid args: must be an array of strings")
+}
+
 func TestHandleDeployModelNoManagerTakesPrecedence(t *testing.T) {
 	sc := &server.ServerContext{
 		// A nil KServeManager should be caught before parameter validation.
@@ -456,12 +1328,139 @@ func TestHandleRunTestSuiteSuccess(t *testing.T) {
 	assert.Contains(t, summary, "duration")
 	assert.Contains(t, summary, "models")
 	assert.Contains(t, summary, "deploy_enabled")
-	assert.Contains(t, summary, "progress_updates")
+	assert.NotContains(t, summary, "progress_updates")
 
 	// The LLM client should have been called (100 questions for CKA).
 	assert.Equal(t, 100, client.Calls)
 }
 
+func TestHandleEvaluateModelValidation(t *testing.T) {
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"test_suite": "kubernetes-cka-v2", "model": "m", "model_uri": "hf://org/m"}
+	result, err := handleEvaluateModel(context.Background(), request, &server.ServerContext{})
+	require.NoError(t, err)
+	assert.Contains(t, toolResultText(result), "KServe manager is not configured")
+
+	sc := &server.ServerContext{
+		LLMClient:     &testutil.MockLLMClient{},
+		KServeManager: kserve.NewManagerWithClient(nil, "llm-testing"),
+	}
+	for _, tc := range []struct {
+		args map[string]interface{}
+		want string
+	}{
+		{map[string]interface{}{"model": "m", "model_uri": "hf://org/m"}, "test_suite is required"},
+		{map[string]interface{}{"test_suite": "kubernetes-cka-v2", "model_uri": "hf://org/m"}, "model is required"},
+		{map[string]interface{}{"test_suite": "kubernetes-cka-v2", "model": "m"}, "model_uri is required"},
+		{map[string]interface{}{"test_suite": "kubernetes-cka-v2", "model": "m", "model_uri": "hf://org/m", "deployment": "{"}, "invalid deployment JSON"},
+		{map[string]interface{}{"test_suite": "kubernetes-cka-v2", "model": "m", "model_uri": "hf://org/m", "deployment": `{"provider":"anthropic"}`}, "use run_test_suite"},
+		{map[string]interface{}{"test_suite": "kubernetes-cka-v2", "model": "m", "model_uri": "hf://org/m", "mode": "vibes"}, "vibes"},
+	} {
+		request.Params.Arguments = tc.args
+		result, err := handleEvaluateModel(context.Background(), request, sc)
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, toolResultText(result), tc.want)
+	}
+}
+
+func TestRunTestSuiteScoreRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	client := &testutil.MockLLMClient{DefaultResponse: "72 out of 100 answers are correct."}
+	sc := &server.ServerContext{LLMClient: client, OutputDir: tmpDir}
+
+	request := mcp.CallToolRequest{}
+	args := runTestSuiteArgs{suiteArgs: suiteArgs{TestSuite: "kubernetes-cka-v2"}, Model: "test-model"}
+	cfg, opts, err := parseScoreConfig(scoreResultsArgs{judgeArgs: judgeArgs{Repetitions: 1}}, sc)
+	require.NoError(t, err)
+
+	result, err := runTestSuite(context.Background(), request, sc, args, runStages{then: scoreRun(sc, cfg, opts)})
+	require.NoError(t, err)
+	require.False(t, result.IsError, toolResultText(result))
+
+	var summary struct {
+		RunID   string `json:"run_id"`
+		Scoring struct {
+			Scored []struct {
+				ScoresFile string `json:"scores_file"`
+			} `json:"scored"`
+		} `json:"scoring"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(toolResultText(result)), &summary))
+	require.Len(t, summary.Scoring.Scored, 1)
+	assert.FileExists(t, summary.Scoring.Scored[0].ScoresFile)
+	// 100 questions answered and one scoring repetition.
+	assert.Equal(t, 101, client.Calls)
+}
+
+func TestRunTestSuiteStages(t *testing.T) {
+	client := &testutil.MockLLMClient{DefaultResponse: "answer"}
+	sc := &server.ServerContext{LLMClient: client, OutputDir: t.TempDir()}
+
+	request := mcp.CallToolRequest{}
+	args := runTestSuiteArgs{suiteArgs: suiteArgs{TestSuite: "kubernetes-cka-v2"}, Model: "test-model"}
+
+	var stages []string
+	result, err := runTestSuite(context.Background(), request, sc, args, runStages{
+		before: func(context.Context, progressNotifier) error {
+			stages = append(stages, "before")
+			assert.Zero(t, client.Calls)
+			return nil
+		},
+		then: func(context.Context, map[string]interface{}, progressNotifier) error {
+			stages = append(stages, "then")
+			return nil
+		},
+		after: func(context.Context) { stages = append(stages, "after") },
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError, toolResultText(result))
+	assert.Equal(t, []string{"before", "then", "after"}, stages)
+	assert.Equal(t, 100, client.Calls)
+
+	// A failing before stage fails the run without starting it, but is
+	// still followed by after.
+	client.Calls, stages = 0, nil
+	result, err = runTestSuite(context.Background(), request, sc, args, runStages{
+		before: func(context.Context, progressNotifier) error {
+			return withCode(codeDeploymentFailed, errors.New("revision failed"))
+		},
+		after: func(context.Context) { stages = append(stages, "after") },
+	})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, toolResultText(result), "revision failed")
+	assert.Equal(t, []string{"after"}, stages)
+	assert.Zero(t, client.Calls)
+}
+
+func TestRunProgress(t *testing.T) {
+	type notification struct {
+		progress, total float64
+		message         string
+	}
+	var sent []notification
+	progress := newRunProgress(func(progress, total float64, message string) {
+		sent = append(sent, notification{progress, total, message})
+	}, 2, []testsuite.Model{{Name: "a"}, {Name: "b"}})
+
+	progress.question("a", 1, 2)
+	progress.score("a", testsuite.LiveScore{Judged: 1, Correct: 1, Percent: 100})
+	progress.question("a", 2, 2)
+	progress.deploy(kserve.DeployProgress{Name: "b", Status: "loading model"})
+	progress.deploy(kserve.DeployProgress{Name: "b", Status: "Ready"})
+	progress.question("b", 1, 2)
+
+	assert.Equal(t, []notification{
+		{0, 4, "a: question 1/2"},
+		{0.5, 4, "a: 1 of 1 judged answers correct (100.0%)"},
+		{1, 4, "a: question 2/2"},
+		{2, 4, "deploying b: loading model (0s)"},
+		{2.5, 4, "deploying b: Ready (0s)"},
+		{2.75, 4, "b: question 1/2"},
+	}, sent)
+}
+
 func TestHandleScoreResultsFileSuccess(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -522,8 +1521,775 @@ func TestHandleGetResultsWithRun(t *testing.T) {
 	require.NoError(t, err)
 
 	content := result.Content[0].(mcp.TextContent)
-	var runs []map[string]interface{}
-	require.NoError(t, json.Unmarshal([]byte(content.Text), &runs))
-	assert.Len(t, runs, 1)
-	assert.Equal(t, "test-run", runs[0]["id"])
+	var listing struct {
+		Runs []map[string]interface{} `json:"runs"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(content.Text), &listing))
+	assert.Len(t, listing.Runs, 1)
+	assert.Equal(t, "test-run", listing.Runs[0]["id"])
+}
+
+func TestHandleScoreResultsVerbosity(t *testing.T) {
+	tmpDir := t.TempDir()
+	runDir := filepath.Join(tmpDir, "test-run")
+	require.NoError(t, os.MkdirAll(runDir, 0o755))
+	resultsContent := `---
+NO. 1 - Setup
+QUESTION: What is kubectl?
+EXPECTED ANSWER: CLI tool
+ACTUAL ANSWER: kubectl is the Kubernetes CLI
+`
+	require.NoError(t, os.WriteFile(filepath.Join(runDir, "test-model.txt"), []byte(resultsContent), 0o644))
+
+	sc := &server.ServerContext{
+		LLMClient: &testutil.MockLLMClient{DefaultResponse: "72 out of 100 answers are correct."},
+		OutputDir: tmpDir,
+	}
+	score := func(verbosity string) *mcp.CallToolResult {
+		t.Helper()
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]interface{}{
+			"run_id":      "test-run",
+			"repetitions": float64(1),
+			"verbosity":   verbosity,
+		}
+		result, err := handleScoreResults(context.Background(), request, sc)
+		require.NoError(t, err)
+		require.False(t, result.IsError, toolResultText(result))
+		return result
+	}
+
+	// The summary is compact, and the scores are linked.
+	result := score("summary")
+	require.Len(t, result.Content, 2)
+	var scoreResult struct {
+		Scored []struct {
+			Summary map[string]interface{} `json:"summary"`
+		} `json:"scored"`
+		Usage interface{} `json:"usage"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &scoreResult))
+	require.Len(t, scoreResult.Scored, 1)
+	assert.Equal(t, 72.0, scoreResult.Scored[0].Summary["mean_percentage"])
+	assert.NotContains(t, scoreResult.Scored[0].Summary, "variance")
+	link, ok := result.Content[1].(mcp.ResourceLink)
+	require.True(t, ok)
+	assert.Equal(t, "results://test-run/test-model_scores.json", link.URI)
+	assert.Equal(t, "application/json", link.MIMEType)
+
+	// Full results embed the scores file.
+	result = score("full")
+	require.Len(t, result.Content, 2)
+	embedded, ok := result.Content[1].(mcp.EmbeddedResource)
+	require.True(t, ok)
+	contents := embedded.Resource.(mcp.TextResourceContents)
+	assert.Equal(t, "results://test-run/test-model_scores.json", contents.URI)
+	assert.Contains(t, contents.Text, `"mean_percentage"`)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"run_id": "test-run", "verbosity": "huge"}
+	result, err := handleScoreResults(context.Background(), request, sc)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestHandleGetResultsVerbosity(t *testing.T) {
+	tmpDir := t.TempDir()
+	runDir := filepath.Join(tmpDir, "test-run")
+	require.NoError(t, os.MkdirAll(runDir, 0o755))
+	metadata := `{"id": "test-run", "suite": "kubernetes-cka-v2", "timestamp": "2024-01-01T00:00:00Z", "models": [{"model_name": "test-model", "config": {}}]}`
+	require.NoError(t, os.WriteFile(filepath.Join(runDir, "resultset.json"), []byte(metadata), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(runDir, "test-model.txt"), []byte("---\n"), 0o644))
+	scores := `{"runs": [], "summary": {"mean_percentage": 80, "mean_correct": 8, "variance": 0, "all_runs_parsed": true}}`
+	require.NoError(t, os.WriteFile(filepath.Join(runDir, "test-model_scores.json"), []byte(scores), 0o644))
+
+	sc := &server.ServerContext{OutputDir: tmpDir}
+	get := func(verbosity string) *mcp.CallToolResult {
+		t.Helper()
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]interface{}{"run_id": "test-run", "verbosity": verbosity}
+		result, err := handleGetResults(context.Background(), request, sc)
+		require.NoError(t, err)
+		require.False(t, result.IsError, toolResultText(result))
+		return result
+	}
+
+	result := get("summary")
+	var run map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &run))
+	assert.Equal(t, []interface{}{"test-model"}, run["models"])
+	assert.Equal(t, map[string]interface{}{
+		"test-model_scores.json": map[string]interface{}{"mean_percentage": 80.0, "mean_correct": 8.0, "all_runs_parsed": true},
+	}, run["scores"])
+	var uris []string
+	for _, c := range result.Content[1:] {
+		uris = append(uris, c.(mcp.ResourceLink).URI)
+	}
+	assert.Equal(t, []string{
+		"results://test-run/resultset.json",
+		"results://test-run/test-model_scores.json",
+		"results://test-run/test-model.txt",
+	}, uris)
+
+	// Normal results keep the complete scores.
+	result = get("")
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, `"variance"`)
+	assert.Len(t, result.Content, 4)
+
+	result = get("full")
+	require.Len(t, result.Content, 4)
+	for _, c := range result.Content[1:] {
+		_, ok := c.(mcp.EmbeddedResource)
+		assert.True(t, ok)
+	}
+}
+
+func TestHandleGetScoreHistory(t *testing.T) {
+	tmpDir := t.TempDir()
+	dir := filepath.Join(tmpDir, history.DirName)
+	for _, ts := range []string{"2026-01-02T00:00:00Z", "2026-01-01T00:00:00Z", "2026-01-03T00:00:00Z"} {
+		require.NoError(t, history.Append(dir, history.Entry{Timestamp: ts, Suite: "suite", Model: "m", RunID: ts}))
+	}
+
+	sc := &server.ServerContext{OutputDir: tmpDir}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{
+		"test_suite": "suite",
+		"model":      "m",
+		"limit":      float64(2),
+	}
+
+	result, err := handleGetScoreHistory(context.Background(), request, sc)
+	require.NoError(t, err)
+
+	content := result.Content[0].(mcp.TextContent)
+	var entries []history.Entry
+	require.NoError(t, json.Unmarshal([]byte(content.Text), &entries))
+	require.Len(t, entries, 2)
+	assert.Equal(t, "2026-01-02T00:00:00Z", entries[0].Timestamp)
+	assert.Equal(t, "2026-01-03T00:00:00Z", entries[1].Timestamp)
+}
+
+func TestHandleAnnotateRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	runDir := filepath.Join(tmpDir, "test-run")
+	require.NoError(t, os.MkdirAll(runDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(runDir, "resultset.json"), []byte(`{"id": "test-run"}`), 0o644))
+
+	sc := &server.ServerContext{OutputDir: tmpDir}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{
+		"run_id": "test-run",
+		"text":   "Scores regressed on storage questions.",
+	}
+	result, err := handleAnnotateRun(context.Background(), request, sc)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	// Annotations are returned with the run metadata.
+	request.Params.Arguments = map[string]interface{}{"run_id": "test-run"}
+	result, err = handleGetResults(context.Background(), request, sc)
+	require.NoError(t, err)
+
+	content := result.Content[0].(mcp.TextContent)
+	assert.Contains(t, content.Text, "Scores regressed on storage questions.")
+	assert.Contains(t, content.Text, `"author": "agent"`)
+}
+
+func TestHandleAnnotateRunValidation(t *testing.T) {
+	sc := &server.ServerContext{OutputDir: t.TempDir()}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"run_id": "../escape", "text": "x"}
+	result, err := handleAnnotateRun(context.Background(), request, sc)
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "invalid run_id")
+
+	request.Params.Arguments = map[string]interface{}{"run_id": "run", "text": "  "}
+	result, err = handleAnnotateRun(context.Background(), request, sc)
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "text is required")
+}
+
+func TestHandleCompareRevisionsNoManager(t *testing.T) {
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"test_suite": "s", "base_name": "m"}
+
+	result, err := handleCompareRevisions(context.Background(), request, &server.ServerContext{})
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "KServe manager is not configured")
+}
+
+func TestHandleCompareRevisionsExistingRevision(t *testing.T) {
+	isvc := &unstructured.Unstructured{}
+	isvc.SetAPIVersion("serving.kserve.io/v1beta1")
+	isvc.SetKind("InferenceService")
+	isvc.SetName("llama-fp8")
+	isvc.SetNamespace("llm-testing")
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			{Group: "serving.kserve.io", Version: "v1beta1", Resource: "inferenceservices"}: "InferenceServiceList",
+		},
+		isvc,
+	)
+	sc := &server.ServerContext{KServeManager: kserve.NewManagerWithClient(client, "llm-testing")}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{
+		"test_suite": "s",
+		"base_name":  "llama",
+		"model_uri":  "hf://org/llama",
+		"revisions":  `[{"name":"baseline"},{"name":"fp8"}]`,
+	}
+	result, err := handleCompareRevisions(context.Background(), request, sc)
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "InferenceService llama-fp8 already exists")
+	assert.Equal(t, codeConflict, classifyError(toolResultError(result), codeInternal))
+}
+
+func TestRevisionModels(t *testing.T) {
+	models, err := revisionModels("llama-3-8b", "hf://meta-llama/Llama-3.1-8B-Instruct", []testsuite.Model{
+		{Name: "baseline"},
+		{Name: "fp8", RuntimeArgs: []string{"--quantization=fp8"}},
+		{Name: "awq", ModelURI: "hf://org/llama-3-8b-awq"},
+	})
+	require.NoError(t, err)
+	require.Len(t, models, 3)
+	assert.Equal(t, "llama-3-8b-baseline", models[0].Name)
+	assert.Equal(t, "hf://meta-llama/Llama-3.1-8B-Instruct", models[0].ModelURI)
+	assert.Equal(t, []string{"--quantization=fp8"}, models[1].RuntimeArgs)
+	assert.Equal(t, "hf://org/llama-3-8b-awq", models[2].ModelURI)
+
+	_, err = revisionModels("m", "hf://org/m", []testsuite.Model{{Name: "only"}})
+	assert.ErrorContains(t, err, "at least two revisions")
+	_, err = revisionModels("m", "hf://org/m", []testsuite.Model{{Name: "a"}, {Name: "A"}})
+	assert.ErrorContains(t, err, "distinct names")
+	_, err = revisionModels("m", "", []testsuite.Model{{Name: "a"}, {Name: "b"}})
+	assert.ErrorContains(t, err, "no model_uri")
+}
+
+func TestDescribeSuitesChange(t *testing.T) {
+	change := testsuite.SuitesChange{Added: []string{"a", "b"}, Removed: []string{"c"}}
+	assert.Equal(t, "Test suites changed (added: a, b; removed: c)", describeSuitesChange(change, nil))
+	assert.Equal(t, "Test suites changed (added: a, b; removed: c; failing to load: b)", describeSuitesChange(change, []string{"b"}))
+}
+
+// loggingSession is a client session with a logging level.
+type loggingSession struct {
+	id            string
+	level         mcp.LoggingLevel
+	notifications chan mcp.JSONRPCNotification
+}
+
+func (s *loggingSession) SessionID() string { return s.id }
+func (s *loggingSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+func (s *loggingSession) Initialize()                        {}
+func (s *loggingSession) Initialized() bool                  { return true }
+func (s *loggingSession) SetLogLevel(level mcp.LoggingLevel) { s.level = level }
+func (s *loggingSession) GetLogLevel() mcp.LoggingLevel      { return s.level }
+
+func TestNotifySuitesChangeRespectsLoggingLevels(t *testing.T) {
+	sc := &server.ServerContext{Sessions: server.NewSessions()}
+	srv := mcpserver.NewMCPServer("test", "0.0.0", append([]mcpserver.ServerOption{mcpserver.WithLogging()}, SessionOptions(sc)...)...)
+	sessions := map[mcp.LoggingLevel]*loggingSession{}
+	for _, level := range []mcp.LoggingLevel{mcp.LoggingLevelDebug, mcp.LoggingLevelWarning, mcp.LoggingLevelError} {
+		sessions[level] = &loggingSession{id: string(level), level: level, notifications: make(chan mcp.JSONRPCNotification, 1)}
+		require.NoError(t, srv.RegisterSession(context.Background(), sessions[level]))
+	}
+	received := func(level mcp.LoggingLevel) []mcp.LoggingLevel {
+		var levels []mcp.LoggingLevel
+		for {
+			select {
+			case n := <-sessions[level].notifications:
+				assert.Equal(t, "notifications/message", n.Method)
+				levels = append(levels, n.Params.AdditionalFields["level"].(mcp.LoggingLevel))
+			default:
+				return levels
+			}
+		}
+	}
+
+	change := testsuite.SuitesChange{Added: []string{"a"}}
+	notifySuitesChange(srv, sc.Sessions, change, nil)
+	assert.Equal(t, []mcp.LoggingLevel{mcp.LoggingLevelInfo}, received(mcp.LoggingLevelDebug))
+	assert.Empty(t, received(mcp.LoggingLevelWarning))
+	assert.Empty(t, received(mcp.LoggingLevelError))
+
+	notifySuitesChange(srv, sc.Sessions, change, []string{"a"})
+	assert.Equal(t, []mcp.LoggingLevel{mcp.LoggingLevelWarning}, received(mcp.LoggingLevelDebug))
+	assert.Equal(t, []mcp.LoggingLevel{mcp.LoggingLevelWarning}, received(mcp.LoggingLevelWarning))
+	assert.Empty(t, received(mcp.LoggingLevelError))
+
+	// Sessions that ended are not notified.
+	srv.UnregisterSession(context.Background(), string(mcp.LoggingLevelDebug))
+	assert.Equal(t, []string{"error", "warning"}, sc.Sessions.Connected())
+}
+
+func TestReadResources(t *testing.T) {
+	outputDir := t.TempDir()
+	runDir := filepath.Join(outputDir, "cka_20260101-120000")
+	require.NoError(t, os.MkdirAll(runDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(runDir, "resultset.json"), []byte(`{"id": "cka_20260101-120000"}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(runDir, "org_model.txt"), []byte("NO. 1"), 0o644))
+
+	srv := mcpserver.NewMCPServer("test", "0.0.0")
+	require.NoError(t, RegisterTools(srv, &server.ServerContext{OutputDir: outputDir}))
+
+	read := func(uri string) (mcp.TextResourceContents, string) {
+		message, err := json.Marshal(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      1,
+			"method":  "resources/read",
+			"params":  map[string]any{"uri": uri},
+		})
+		require.NoError(t, err)
+		response, err := json.Marshal(srv.HandleMessage(context.Background(), message))
+		require.NoError(t, err)
+
+		var result struct {
+			Result struct {
+				Contents []mcp.TextResourceContents `json:"contents"`
+			} `json:"result"`
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		require.NoError(t, json.Unmarshal(response, &result))
+		if result.Error.Message != "" {
+			return mcp.TextResourceContents{}, result.Error.Message
+		}
+		require.Len(t, result.Result.Contents, 1)
+		return result.Result.Contents[0], ""
+	}
+
+	contents, errMsg := read("results://cka_20260101-120000/resultset.json")
+	require.Empty(t, errMsg)
+	assert.Equal(t, "application/json", contents.MIMEType)
+	assert.Contains(t, contents.Text, `"id"`)
+
+	contents, errMsg = read("results://cka_20260101-120000/org_model.txt")
+	require.Empty(t, errMsg)
+	assert.Equal(t, "text/plain", contents.MIMEType)
+	assert.Equal(t, "NO. 1", contents.Text)
+
+	contents, errMsg = read("suites://kubernetes-cka-v2/config.yaml")
+	require.Empty(t, errMsg)
+	assert.Equal(t, "application/yaml", contents.MIMEType)
+	assert.Contains(t, contents.Text, "name:")
+
+	_, errMsg = read("results://cka_20260101-120000/missing.txt")
+	assert.NotEmpty(t, errMsg)
+	_, errMsg = read("results://../resultset.json")
+	assert.NotEmpty(t, errMsg)
+	_, errMsg = read("suites://unknown-suite/config.yaml")
+	assert.NotEmpty(t, errMsg)
+}
+
+func TestHandleDeleteResults(t *testing.T) {
+	outputDir := t.TempDir()
+	for id, started := range map[string]string{"old-run": "2020-01-01T00:00:00Z", "new-run": time.Now().Format(time.RFC3339)} {
+		require.NoError(t, os.MkdirAll(filepath.Join(outputDir, id), 0o755))
+		metadata := fmt.Sprintf(`{"id": %q, "timestamp": %q}`, id, started)
+		require.NoError(t, os.WriteFile(filepath.Join(outputDir, id, "resultset.json"), []byte(metadata), 0o644))
+	}
+	sc := &server.ServerContext{OutputDir: outputDir}
+
+	call := func(args map[string]interface{}) *mcp.CallToolResult {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = args
+		result, err := handleDeleteResults(context.Background(), request, sc)
+		require.NoError(t, err)
+		return result
+	}
+
+	result := call(map[string]interface{}{})
+	assert.Contains(t, toolResultText(result), "provide exactly one of run_id or older_than")
+	result = call(map[string]interface{}{"older_than": "soon"})
+	assert.Contains(t, toolResultText(result), "invalid age")
+	result = call(map[string]interface{}{"run_id": "../etc"})
+	assert.Contains(t, toolResultText(result), "invalid run_id")
+
+	result = call(map[string]interface{}{"older_than": "30d"})
+	require.False(t, result.IsError, toolResultText(result))
+	assert.Contains(t, toolResultText(result), `"run_id": "old-run"`)
+	assert.NoDirExists(t, filepath.Join(outputDir, "old-run"))
+
+	result = call(map[string]interface{}{"run_id": "new-run", "dry_run": true})
+	require.False(t, result.IsError, toolResultText(result))
+	assert.DirExists(t, filepath.Join(outputDir, "new-run"))
+
+	result = call(map[string]interface{}{"run_id": "new-run"})
+	require.False(t, result.IsError, toolResultText(result))
+	assert.NoDirExists(t, filepath.Join(outputDir, "new-run"))
+}
+
+func TestBindArgs(t *testing.T) {
+	type testArgs struct {
+		Name  string `json:"name"`
+		Sort  string `json:"sort,omitempty" jsonschema:"enum=newest,enum=oldest"`
+		Limit int    `json:"limit,omitempty" jsonschema:"minimum=1,maximum=100"`
+		Flag  *bool  `json:"flag,omitempty"`
+	}
+	bind := func(args map[string]interface{}) (testArgs, error) {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = args
+		return bindArgs[testArgs](request)
+	}
+
+	args, err := bind(map[string]interface{}{"name": "x", "sort": "oldest", "limit": float64(5), "flag": false})
+	require.NoError(t, err)
+	assert.Equal(t, "x", args.Name)
+	assert.Equal(t, 5, args.Limit)
+	require.NotNil(t, args.Flag)
+	assert.False(t, *args.Flag)
+
+	for _, tc := range []struct {
+		args map[string]interface{}
+		want string
+	}{
+		{map[string]interface{}{}, "name is required"},
+		{map[string]interface{}{"name": "  "}, "name is required"},
+		{map[string]interface{}{"name": 42}, "invalid name: must be a string"},
+		{map[string]interface{}{"name": "x", "limit": 2.5}, "invalid limit: must be an integer"},
+		{map[string]interface{}{"name": "x", "limit": float64(0)}, "invalid limit 0: must be at least 1"},
+		{map[string]interface{}{"name": "x", "limit": float64(101)}, "invalid limit 101: must be at most 100"},
+		{map[string]interface{}{"name": "x", "sort": "best"}, `invalid sort "best": must be one of newest, oldest`},
+	} {
+		_, err := bind(tc.args)
+		assert.EqualError(t, err, tc.want)
+	}
+
+	// All violations are reported.
+	_, err = bind(map[string]interface{}{"sort": "best"})
+	assert.ErrorContains(t, err, "name is required")
+	assert.ErrorContains(t, err, "invalid sort")
+
+	// The fields of embedded structs are arguments too.
+	type embeddingArgs struct {
+		testArgs
+		Extra string `json:"extra,omitempty"`
+	}
+	embedded, err := bindArgMap[embeddingArgs](map[string]interface{}{"name": "x", "extra": "y"})
+	require.NoError(t, err)
+	assert.Equal(t, "x", embedded.Name)
+	assert.Equal(t, "y", embedded.Extra)
+	_, err = bindArgMap[embeddingArgs](map[string]interface{}{"extra": "y"})
+	assert.EqualError(t, err, "name is required")
+	_, err = bindArgMap[embeddingArgs](map[string]interface{}{"name": "x", "limit": "5"})
+	assert.EqualError(t, err, "invalid limit: must be an integer")
+}
+
+func TestToolInputSchemas(t *testing.T) {
+	s := mcpserver.NewMCPServer("test", "0.0.0")
+	require.NoError(t, RegisterTools(s, &server.ServerContext{}))
+
+	tools := s.ListTools()
+	schema := func(name string) map[string]interface{} {
+		tool, ok := tools[name]
+		require.True(t, ok, name)
+		var schema map[string]interface{}
+		require.NoError(t, json.Unmarshal(tool.Tool.RawInputSchema, &schema))
+		return schema
+	}
+
+	annotate := schema("annotate_run")
+	assert.ElementsMatch(t, []interface{}{"run_id", "text"}, annotate["required"])
+
+	results := schema("get_results")
+	assert.NotContains(t, results, "required")
+	properties := results["properties"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"newest", "oldest", "suite"}, properties["sort"].(map[string]interface{})["enum"])
+	assert.Equal(t, "integer", properties["limit"].(map[string]interface{})["type"])
+	assert.NotEmpty(t, properties["since"].(map[string]interface{})["description"])
+
+	// Arguments of embedded structs are arguments of the tool, and those
+	// described by JSONSchemaExtend are described too.
+	deploy := schema("deploy_model")
+	assert.ElementsMatch(t, []interface{}{"model_name"}, deploy["required"])
+	properties = deploy["properties"].(map[string]interface{})
+	assert.Equal(t, "integer", properties["gpu_count"].(map[string]interface{})["type"])
+	assert.Equal(t, []interface{}{"off", "warn", "enforce"}, properties["capacity_check"].(map[string]interface{})["enum"])
+	var methods []interface{}
+	for _, m := range kserve.QuantizationMethods() {
+		methods = append(methods, m)
+	}
+	assert.Equal(t, methods, properties["quantization"].(map[string]interface{})["enum"])
+	assert.Contains(t, properties["backend"].(map[string]interface{})["description"], kserve.DefaultOllamaRuntime)
+
+	run := schema("run_test_suite")
+	assert.ElementsMatch(t, []interface{}{"test_suite"}, run["required"])
+	properties = run["properties"].(map[string]interface{})
+	assert.Equal(t, "boolean", properties["examples"].(map[string]interface{})["type"])
+	assert.Contains(t, properties["models"].(map[string]interface{})["description"], "Example:")
+
+	assert.ElementsMatch(t, []interface{}{"test_suite", "model"}, schema("evaluate_model")["required"])
+	assert.ElementsMatch(t, []interface{}{"test_suite", "base_name", "revisions"}, schema("compare_revisions")["required"])
+
+	for name, tool := range tools {
+		if tool.Tool.RawInputSchema == nil {
+			continue // a tool without arguments
+		}
+		for arg, property := range schema(name)["properties"].(map[string]interface{}) {
+			assert.NotEmpty(t, property.(map[string]interface{})["description"], "%s of %s", arg, name)
+		}
+	}
+}
+
+func TestAccessOptions(t *testing.T) {
+	sc := &server.ServerContext{OutputDir: t.TempDir(), Access: server.AccessPolicy{ReadOnly: true}}
+	srv := mcpserver.NewMCPServer("test", "0.0.0", AccessOptions(sc)...)
+	require.NoError(t, RegisterTools(srv, sc))
+
+	call := func(method string, params map[string]any) string {
+		message, err := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+		require.NoError(t, err)
+		response, err := json.Marshal(srv.HandleMessage(context.Background(), message))
+		require.NoError(t, err)
+		return string(response)
+	}
+
+	tools := call("tools/list", map[string]any{})
+	assert.Contains(t, tools, `"get_results"`)
+	for name := range writeTools {
+		assert.NotContains(t, tools, `"name":"`+name+`"`)
+	}
+
+	// Calls are refused even by clients that know the tool.
+	response := call("tools/call", map[string]any{"name": "delete_results", "arguments": map[string]any{"older_than": "1d"}})
+	assert.Contains(t, response, "delete_results is not available: the server is read-only")
+	assert.Contains(t, response, `"code":"PERMISSION_DENIED"`)
+	response = call("tools/call", map[string]any{"name": "list_endpoint_models", "arguments": map[string]any{"endpoint": "https://attacker.example.com/v1"}})
+	assert.Contains(t, response, `"code":"PERMISSION_DENIED"`, "the server's API keys are not sent to endpoints of read-only clients")
+	response = call("tools/call", map[string]any{"name": "get_results", "arguments": map[string]any{}})
+	assert.Contains(t, response, `\"total\": 0`)
+
+	// Every tool that changes state is registered.
+	registered := srv.ListTools()
+	for name := range writeTools {
+		assert.Contains(t, registered, name)
+	}
+}
+
+func TestDrainOptions(t *testing.T) {
+	sc := &server.ServerContext{OutputDir: t.TempDir(), Drain: server.NewDrain()}
+	srv := mcpserver.NewMCPServer("test", "0.0.0", DrainOptions(sc)...)
+	require.NoError(t, RegisterTools(srv, sc))
+
+	call := func(name string, args map[string]any) string {
+		message, err := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": map[string]any{"name": name, "arguments": args}})
+		require.NoError(t, err)
+		response, err := json.Marshal(srv.HandleMessage(context.Background(), message))
+		require.NoError(t, err)
+		return string(response)
+	}
+
+	sc.Drain.Start()
+	response := call("delete_results", map[string]any{"older_than": "1d"})
+	assert.Contains(t, response, "delete_results is not available: the server is shutting down")
+	assert.Contains(t, response, `"code":"SHUTTING_DOWN","message":"delete_results is not available: the server is shutting down","retryable":true`)
+	assert.NotContains(t, call("cancel_run", map[string]any{"run_id": "run-1"}), "shutting down")
+	assert.Contains(t, call("get_results", map[string]any{}), `\"total\": 0`)
+}
+
+func TestRunTemplate(t *testing.T) {
+	tmpl := webhook.Template{Name: "nightly", Suite: "kubernetes-cka-v2", Models: []testsuite.Model{{Name: "mistral-7b"}}}
+
+	// The job manager is shut down, so that the run stays queued.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	sc := &server.ServerContext{
+		OutputDir: t.TempDir(),
+		LLMClient: &testutil.MockLLMClient{},
+		Jobs:      jobs.NewManager(ctx),
+		Drain:     server.NewDrain(),
+	}
+	runID, err := RunTemplate(context.Background(), sc, tmpl)
+	require.NoError(t, err)
+	assert.Contains(t, runID, "Kubernetes_CKA")
+
+	tmpl.Suite = "missing"
+	_, err = RunTemplate(context.Background(), sc, tmpl)
+	assert.ErrorContains(t, err, `test suite "missing" not found`)
+	assert.NotErrorIs(t, err, webhook.ErrUnavailable)
+
+	sc.Drain.Start()
+	_, err = RunTemplate(context.Background(), sc, tmpl)
+	assert.ErrorIs(t, err, webhook.ErrUnavailable)
+}
+
+func TestHandleListJobs(t *testing.T) {
+	sc := &server.ServerContext{Jobs: jobs.NewManager(context.Background())}
+	release := make(chan struct{})
+	defer close(release)
+	for _, id := range []string{"run-1", "run-2"} {
+		_, err := sc.Jobs.Start(id, func(context.Context, *jobs.Job) (any, error) {
+			<-release
+			return "summary", nil
+		})
+		require.NoError(t, err)
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"state": "queued"}
+	result, err := handleListJobs(context.Background(), request, sc)
+	require.NoError(t, err)
+	require.False(t, result.IsError, toolResultText(result))
+
+	var listing struct {
+		MaxConcurrentRuns int           `json:"max_concurrent_runs"`
+		Running           int           `json:"running"`
+		Queued            int           `json:"queued"`
+		Jobs              []jobs.Status `json:"jobs"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(toolResultText(result)), &listing))
+	assert.Equal(t, 1, listing.MaxConcurrentRuns)
+	assert.Equal(t, 1, listing.Running)
+	assert.Equal(t, 1, listing.Queued)
+	require.Len(t, listing.Jobs, 1)
+	assert.Equal(t, "run-2", listing.Jobs[0].ID)
+	assert.Equal(t, 1, listing.Jobs[0].QueuePosition)
+
+	request.Params.Arguments = map[string]interface{}{"state": "paused"}
+	result, err = handleListJobs(context.Background(), request, sc)
+	require.NoError(t, err)
+	assert.Contains(t, toolResultText(result), `invalid state "paused"`)
+}
+
+// toolErrorOf returns the structured error of a tool result.
+func toolErrorOf(t *testing.T, result *mcp.CallToolResult) toolErrorInfo {
+	t.Helper()
+	require.True(t, result.IsError, toolResultText(result))
+	content, ok := result.StructuredContent.(map[string]interface{})
+	require.True(t, ok, "structured content")
+	info, ok := content["error"].(toolErrorInfo)
+	require.True(t, ok, "structured error")
+	assert.Equal(t, toolResultText(result), info.Message)
+	return info
+}
+
+func TestClassifyError(t *testing.T) {
+	capacityErr := &kserve.InsufficientCapacityError{Requested: 4, Nodes: 1, Capacity: &kserve.GPUCapacity{Free: 3, Largest: 2}}
+	tests := []struct {
+		name     string
+		err      error
+		fallback errorCode
+		want     errorCode
+	}{
+		{"plain error", fmt.Errorf("boom"), codeInternal, codeInternal},
+		{"coded error", withCode(codeNotFound, fmt.Errorf("missing")), codeInternal, codeNotFound},
+		{"run not found", fmt.Errorf("get: %w", jobs.ErrNotFound), codeInvalidArgument, codeRunNotFound},
+		{"run already running", fmt.Errorf("run r is %w", jobs.ErrAlreadyRunning), codeInternal, codeConflict},
+		{"suite not found", fmt.Errorf("load: %w", testsuite.ErrNotFound), codeInvalidArgument, codeSuiteNotFound},
+		{"insufficient GPUs", fmt.Errorf("failed to deploy model: %w", capacityErr), codeDeploymentFailed, codeGPUUnavailable},
+		{"deployment not ready", &kserve.DeployError{Name: "m", Err: fmt.Errorf("crash loop")}, codeKubernetesError, codeDeploymentFailed},
+		{"shutting down", server.ErrDraining, codeInternal, codeShuttingDown},
+		{"missing file", os.ErrNotExist, codeInternal, codeNotFound},
+		{"missing run file", runNotFound(os.ErrNotExist), codeInternal, codeRunNotFound},
+		{"cancelled", context.Canceled, codeInternal, codeCancelled},
+		{"timeout", context.DeadlineExceeded, codeKubernetesError, codeTimeout},
+		{"failing endpoint", llm.ErrCircuitOpen, codeLLMError, codeLLMUnavailable},
+		{"unparsable answer", llm.ErrNoChoices, codeLLMError, codeLLMError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, classifyError(tt.err, tt.fallback))
+		})
+	}
+
+	info := toolErrorOf(t, toolErrorFromf(capacityErr, codeDeploymentFailed, "failed to deploy model: %v", capacityErr))
+	assert.Equal(t, codeGPUUnavailable, info.Code)
+	assert.True(t, info.Retryable)
+	assert.Equal(t, map[string]interface{}{"requested_gpus": 4, "nodes": 1, "largest_free": 2, "free": 3}, info.Details)
+
+	info = toolErrorOf(t, toolErrorFrom(llm.ErrNoChoices, codeLLMError))
+	assert.False(t, info.Retryable)
+	assert.Equal(t, map[string]interface{}{"llm_error_class": llm.ErrorClassParse}, info.Details)
+
+	// The code of an error result survives its conversion to an error.
+	err := toolResultError(toolError(codeSuiteNotFound, "missing suite"))
+	assert.EqualError(t, err, "missing suite")
+	assert.Equal(t, codeSuiteNotFound, classifyError(err, codeInternal))
+}
+
+func TestToolErrorCodes(t *testing.T) {
+	sc := &server.ServerContext{
+		OutputDir:    t.TempDir(),
+		LLMClient:    &testutil.MockLLMClient{},
+		ScoringModel: "judge",
+		Jobs:         jobs.NewManager(context.Background()),
+	}
+	call := func(handler func(context.Context, mcp.CallToolRequest, *server.ServerContext) (*mcp.CallToolResult, error), args map[string]interface{}) toolErrorInfo {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = args
+		result, err := handler(context.Background(), request, sc)
+		require.NoError(t, err)
+		return toolErrorOf(t, result)
+	}
+
+	info := call(handleRunTestSuite, map[string]interface{}{"test_suite": "missing", "model": "m"})
+	assert.Equal(t, codeSuiteNotFound, info.Code)
+	assert.False(t, info.Retryable)
+	assert.Contains(t, info.Message, `test suite "missing" not found`)
+
+	assert.Equal(t, codeInvalidArgument, call(handleRunTestSuite, map[string]interface{}{}).Code)
+	assert.Equal(t, codeRunNotFound, call(handleGetRunStatus, map[string]interface{}{"run_id": "run-1"}).Code)
+	assert.Equal(t, codeRunNotFound, call(handleGetResults, map[string]interface{}{"run_id": "run-1"}).Code)
+	assert.Equal(t, codeNotConfigured, call(handleTeardownModel, map[string]interface{}{"model_name": "m"}).Code)
+
+	// The structured error is part of the result sent to clients.
+	data, err := json.Marshal(toolError(codeShuttingDown, "the server is shutting down"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"structuredContent":{"error":{"code":"SHUTTING_DOWN","message":"the server is shutting down","retryable":true}}`)
+}
+
+func TestSessionOptions(t *testing.T) {
+	sc := &server.ServerContext{OutputDir: t.TempDir(), Sessions: server.NewSessions()}
+	srv := mcpserver.NewMCPServer("test", "0.0.0", SessionOptions(sc)...)
+	require.NoError(t, RegisterTools(srv, sc))
+	runDir := filepath.Join(sc.OutputDir, "run-1")
+	require.NoError(t, os.MkdirAll(runDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(runDir, "resultset.json"), []byte(`{"id": "run-1"}`), 0o644))
+
+	call := func(name string, args map[string]any) string {
+		message, err := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": map[string]any{"name": name, "arguments": args}})
+		require.NoError(t, err)
+		response, err := json.Marshal(srv.HandleMessage(context.Background(), message))
+		require.NoError(t, err)
+		return string(response)
+	}
+
+	// Nothing to refer to yet.
+	response := call("annotate_run", map[string]any{"run_id": "latest", "text": "note"})
+	assert.Contains(t, response, `run_id: no run in this session yet to refer to as \"latest\"`)
+	assert.Contains(t, response, `"code":"INVALID_ARGUMENT"`)
+
+	// The run looked at last is the latest.
+	assert.Contains(t, call("get_results", map[string]any{"run_id": "run-1"}), `\"id\": \"run-1\"`)
+	assert.Contains(t, call("annotate_run", map[string]any{"run_id": "latest", "text": "note"}), `\"run_id\": \"run-1\"`)
+	assert.Contains(t, call("get_session_context", map[string]any{}), `\"run_id\": \"run-1\"`)
+	assert.Equal(t, server.WorkingContext{RunID: "run-1"}, sc.Sessions.Get(""))
+
+	// Runs are only deleted by their explicit ID.
+	assert.Contains(t, call("delete_results", map[string]any{"run_id": "latest"}), "delete_results requires an explicit run_id")
+	call("delete_results", map[string]any{"run_id": "run-1"})
+	assert.Equal(t, server.WorkingContext{}, sc.Sessions.Get(""))
+}
+
+func TestRecordWorkingContext(t *testing.T) {
+	var wc server.WorkingContext
+	recordWorkingContext(&wc, "deploy_model", map[string]interface{}{"model_name": "small-gpu"}, mcp.NewToolResultText(`{"name": "qwen"}`))
+	recordWorkingContext(&wc, "run_test_suite", map[string]interface{}{"test_suite": "kubernetes-cka-v2", "model": "small-gpu"}, mcp.NewToolResultText(`{"run_id": "run-2", "suite": "kubernetes-cka-v2"}`))
+	assert.Equal(t, server.WorkingContext{RunID: "run-2", Model: "small-gpu", TestSuite: "kubernetes-cka-v2"}, wc)
+
+	args, err := resolveLatest("run_test_suite", map[string]interface{}{"test_suite": "latest", "model": "latest", "async": true}, wc)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"test_suite": "kubernetes-cka-v2", "model": "small-gpu", "async": true}, args)
+
+	_, err = resolveLatest("get_model", map[string]interface{}{"model_name": "latest"}, server.WorkingContext{})
+	assert.EqualError(t, err, `model_name: no deployed model in this session yet to refer to as "latest"`)
 }
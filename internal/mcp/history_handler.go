@@ -0,0 +1,51 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/llm-testing/internal/history"
+	"github.com/giantswarm/llm-testing/internal/server"
+)
+
+// getScoreHistoryArgs are the arguments of get_score_history.
+type getScoreHistoryArgs struct {
+	TestSuite string `json:"test_suite,omitempty" jsonschema_description:"Test suite name as recorded in run metadata (e.g. 'Kubernetes CKA'). Lists all suites if omitted."`
+	Model     string `json:"model,omitempty" jsonschema_description:"Model name or alias to filter by; aliases are resolved via the model registry (optional, all models if omitted)"`
+	Limit     int    `json:"limit,omitempty" jsonschema:"minimum=1" jsonschema_description:"Return only the most recent N entries (optional)"`
+}
+
+func handleGetScoreHistory(_ context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	args, err := bindArgs[getScoreHistoryArgs](request)
+	if err != nil {
+		return toolErrorFrom(err, codeInvalidArgument), nil
+	}
+	suite, model := args.TestSuite, args.Model
+
+	// Resolve aliases so that all naming variants of a model share one history.
+	modelID := ""
+	if model != "" {
+		modelID = sc.ModelRegistry.Resolve(model, "")
+	}
+
+	entries, err := history.Load(filepath.Join(sc.OutputDir, history.DirName), suite, modelID)
+	if err != nil {
+		return toolErrorFromf(err, codeInternal, "failed to load score history: %v", err), nil
+	}
+
+	if args.Limit > 0 && args.Limit < len(entries) {
+		entries = entries[len(entries)-args.Limit:]
+	}
+	if entries == nil {
+		entries = []history.Entry{}
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return toolErrorf(codeInternal, "failed to marshal score history: %v", err), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
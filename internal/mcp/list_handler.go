@@ -3,11 +3,13 @@ package mcp
 import (
 	"context"
 	"encoding/json"
-	"fmt"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	mcpserver "github.com/mark3labs/mcp-go/server"
 
+	"github.com/giantswarm/llm-testing/internal/runner"
+	"github.com/giantswarm/llm-testing/internal/scorer"
 	"github.com/giantswarm/llm-testing/internal/server"
 	"github.com/giantswarm/llm-testing/internal/testsuite"
 )
@@ -15,64 +17,104 @@ import (
 func registerTestSuiteTools(s *mcpserver.MCPServer, sc *server.ServerContext) error {
 	// list_test_suites
 	listTool := mcp.NewTool("list_test_suites",
-		mcp.WithDescription("List available LLM evaluation test suites with metadata"),
+		mcp.WithDescription("List available LLM evaluation test suites with metadata and statistics: questions per section, mean question and answer lengths, estimated prompt tokens per model, and when external suites were last modified"),
 	)
 	s.AddTool(listTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		return handleListTestSuites(ctx, request, sc)
 	})
 
+	// validate_test_suite
+	validateTool := mcp.NewTool("validate_test_suite",
+		mcp.WithDescription("Check a test suite for problems before running it: the config.yaml schema, the questions file (duplicate IDs, empty questions or answers, encoding issues), weights of unknown questions, and estimated prompt lengths. Returns all issues with their file and line."),
+		mcp.WithInputSchema[validateTestSuiteArgs](),
+	)
+	s.AddTool(validateTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleValidateTestSuite(ctx, request, sc)
+	})
+
+	// generate_suite
+	generateTool := mcp.NewTool("generate_suite",
+		mcp.WithDescription(`Draft a new test suite from reference documents: an LLM writes questions with expected answers, sections, difficulties, and tags, which are written as config.yaml and questions.csv to generated-suites/<name> in the output directory.
+
+The suite is a draft for human review; it is not available to run_test_suite until it has been reviewed and moved to the suites directory.`),
+		mcp.WithInputSchema[generateSuiteArgs](),
+	)
+	s.AddTool(generateTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleGenerateSuite(ctx, request, sc)
+	})
+
 	// run_test_suite
 	runTool := mcp.NewTool("run_test_suite",
 		mcp.WithDescription(`Execute a test suite against one or more models. Models are specified at runtime -- they are NOT part of the test suite configuration.
 
 When models have a 'model_uri', they can be automatically deployed via KServe InferenceService before testing and torn down afterwards. Models are tested sequentially to respect GPU memory constraints.
 
-Use 'models' for multi-model configs (JSON array) or 'model' for a single model.`),
-		mcp.WithString("test_suite",
-			mcp.Required(),
-			mcp.Description("Name of the test suite to run (e.g. 'kubernetes-cka-v2')"),
-		),
-		mcp.WithString("model",
-			mcp.Description("Single model name to test. For multiple models, use the 'models' parameter instead."),
-		),
-		mcp.WithString("models",
-			mcp.Description(`JSON array of model configs. Each model can include:
-- "name" (required): model identifier
-- "temperature": generation temperature (default: 0.0)
-- "model_uri": KServe storage URI for auto-deploy (e.g. "hf://org/model")
-- "gpu_count": GPUs to request when deploying (default: 1)
-
-Example: [{"name":"mistral-7b","model_uri":"hf://mistralai/Mistral-7B-Instruct-v0.3","gpu_count":1}]`),
-		),
-		mcp.WithString("endpoint",
-			mcp.Description("LLM endpoint URL (overrides KServe auto-discovery). Use when models are served externally."),
-		),
-		mcp.WithNumber("temperature",
-			mcp.Description("Temperature for generation when using single 'model' param (default: 0.0)"),
-		),
-		mcp.WithBoolean("deploy",
-			mcp.Description("Whether to auto-deploy models with model_uri via KServe (default: true)"),
-		),
+Use 'models' for multi-model configs (JSON array) or 'model' for a single model.
+
+Set 'async' for long runs: the call then returns the run_id immediately, and the run continues in the background (see get_run_status and cancel_run).`),
+		mcp.WithInputSchema[runTestSuiteArgs](),
 	)
 	s.AddTool(runTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		return handleRunTestSuite(ctx, request, sc)
 	})
 
+	// get_run_status
+	runStatusTool := mcp.NewTool("get_run_status",
+		mcp.WithDescription("Report the state, queue position, and per-model progress of test runs started with run_test_suite, e.g. with 'async', and the summary of finished ones. Finished runs are kept for 24 hours."),
+		mcp.WithInputSchema[runStatusArgs](),
+	)
+	s.AddTool(runStatusTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleGetRunStatus(ctx, request, sc)
+	})
+
+	// list_jobs
+	listJobsTool := mcp.NewTool("list_jobs",
+		mcp.WithDescription("List the queued, running, and recently finished test runs of the server, with the queue position of queued runs. Runs beyond the server's limit of concurrent runs wait in a queue and start in order."),
+		mcp.WithInputSchema[listJobsArgs](),
+	)
+	s.AddTool(listJobsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleListJobs(ctx, request, sc)
+	})
+
+	// get_session_context
+	sessionContextTool := mcp.NewTool("get_session_context",
+		mcp.WithDescription("Report what this session last worked on: the run it last started or looked at, the model it last deployed, and the test suite it last chose. Tools accept 'latest' for these as run_id, model or model_name, and test_suite."),
+	)
+	s.AddTool(sessionContextTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleGetSessionContext(ctx, request, sc)
+	})
+
+	// cancel_run
+	cancelRunTool := mcp.NewTool("cancel_run",
+		mcp.WithDescription("Cancel a queued or in-flight test run started with run_test_suite, compare_revisions, or evaluate_model. The answers so far are written to the results files, the models deployed for the run are torn down, and the run is marked 'cancelled' in its resultset.json."),
+		mcp.WithInputSchema[cancelRunArgs](),
+	)
+	s.AddTool(cancelRunTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleCancelRun(ctx, request, sc)
+	})
+
+	// compare_revisions
+	compareTool := mcp.NewTool("compare_revisions",
+		mcp.WithDescription(`Benchmark revisions of the same model against each other, e.g. with different vLLM arguments or quantization. The revisions are deployed side by side via KServe as '<base_name>-<revision name>', the test suite is run against each, and all of them are torn down afterwards. Fails with CONFLICT if a model of one of these names already exists.`),
+		mcp.WithInputSchema[compareRevisionsArgs](),
+	)
+	s.AddTool(compareTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleCompareRevisions(ctx, request, sc)
+	})
+
+	// evaluate_model
+	evaluateTool := mcp.NewTool("evaluate_model",
+		mcp.WithDescription(`Evaluate a model in one call: deploy it via KServe, run a test suite against it, tear it down, and score its results with an LLM as judge. Returns the run summary with the scores under "scoring". The model is torn down also when the run fails or is cancelled; cancelled runs are not scored.`),
+		mcp.WithInputSchema[evaluateModelArgs](),
+	)
+	s.AddTool(evaluateTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleEvaluateModel(ctx, request, sc)
+	})
+
 	// score_results
 	scoreTool := mcp.NewTool("score_results",
 		mcp.WithDescription("Score a completed test run using an LLM as judge. Provide exactly one of 'run_id' (all result files in a run) or 'results_file' (one specific file)."),
-		mcp.WithString("run_id",
-			mcp.Description("Run ID to score (scores all result files in the run directory)"),
-		),
-		mcp.WithString("results_file",
-			mcp.Description("Path to a specific results file to score"),
-		),
-		mcp.WithString("scoring_model",
-			mcp.Description("Model to use for scoring (default: claude-sonnet-4-5-20250514)"),
-		),
-		mcp.WithNumber("repetitions",
-			mcp.Description("Number of scoring repetitions for confidence (default: 3)"),
-		),
+		mcp.WithInputSchema[scoreResultsArgs](),
 	)
 	s.AddTool(scoreTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		return handleScoreResults(ctx, request, sc)
@@ -80,30 +122,67 @@ Example: [{"name":"mistral-7b","model_uri":"hf://mistralai/Mistral-7B-Instruct-v
 
 	// get_results
 	getResultsTool := mcp.NewTool("get_results",
-		mcp.WithDescription("Retrieve results and scores for past test runs"),
-		mcp.WithString("run_id",
-			mcp.Description("Specific run ID to retrieve (optional, lists all if omitted)"),
-		),
+		mcp.WithDescription("Retrieve results and scores for past test runs. Without 'run_id', lists runs matching the filters, newest first and paginated with 'limit' and 'offset'; the response includes the total number of matching runs."),
+		mcp.WithInputSchema[getResultsArgs](),
 	)
 	s.AddTool(getResultsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		return handleGetResults(ctx, request, sc)
 	})
 
+	// get_question_results
+	getQuestionResultsTool := mcp.NewTool("get_question_results",
+		mcp.WithDescription("Return a model's answers in a run question by question: the question, expected and actual answer, duration, token usage, and verdict if scored. Filter by section, question, or verdict to investigate specific failures without reading the whole results file."),
+		mcp.WithInputSchema[getQuestionResultsArgs](),
+	)
+	s.AddTool(getQuestionResultsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleGetQuestionResults(ctx, request, sc)
+	})
+
+	// delete_results
+	deleteResultsTool := mcp.NewTool("delete_results",
+		mcp.WithDescription("Delete completed test runs from the output directory: one run by 'run_id', or all runs started before 'older_than'. Score history entries of the runs are kept."),
+		mcp.WithInputSchema[deleteResultsArgs](),
+	)
+	s.AddTool(deleteResultsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleDeleteResults(ctx, request, sc)
+	})
+
+	// get_score_history
+	historyTool := mcp.NewTool("get_score_history",
+		mcp.WithDescription("Retrieve time-ordered score summaries for a test suite and model across runs, to spot regressions"),
+		mcp.WithInputSchema[getScoreHistoryArgs](),
+	)
+	s.AddTool(historyTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleGetScoreHistory(ctx, request, sc)
+	})
+
+	// annotate_run
+	annotateTool := mcp.NewTool("annotate_run",
+		mcp.WithDescription("Attach a free-form finding or conclusion to a run, e.g. the analysis produced after scoring. Annotations are stored in the run metadata and returned by get_results."),
+		mcp.WithInputSchema[annotateRunArgs](),
+	)
+	s.AddTool(annotateTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleAnnotateRun(ctx, request, sc)
+	})
+
 	return nil
 }
 
 func handleListTestSuites(_ context.Context, _ mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
 	names, err := testsuite.List(sc.SuitesDir)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to list test suites: %v", err)), nil
+		return toolErrorFromf(err, codeInternal, "failed to list test suites: %v", err), nil
 	}
 
 	type suiteInfo struct {
-		Name          string `json:"name"`
-		Description   string `json:"description"`
-		Version       string `json:"version"`
-		Strategy      string `json:"strategy"`
-		QuestionCount int    `json:"question_count"`
+		Name          string   `json:"name"`
+		Description   string   `json:"description"`
+		Version       string   `json:"version"`
+		Strategy      string   `json:"strategy"`
+		QuestionCount int      `json:"question_count"`
+		Profiles      []string `json:"profiles,omitempty"`
+		testsuite.Stats
+		LastModified string `json:"last_modified,omitempty"`
 	}
 
 	var suites []suiteInfo
@@ -112,18 +191,66 @@ func handleListTestSuites(_ context.Context, _ mcp.CallToolRequest, sc *server.S
 		if err != nil {
 			continue
 		}
-		suites = append(suites, suiteInfo{
+		info := suiteInfo{
 			Name:          suite.Name,
 			Description:   suite.Description,
 			Version:       suite.Version,
 			Strategy:      suite.Strategy,
 			QuestionCount: len(suite.Questions),
-		})
+			Profiles:      suite.ProfileNames(),
+			Stats:         suite.Stats(),
+		}
+		if modified, err := testsuite.LastModified(name, sc.SuitesDir); err == nil && !modified.IsZero() {
+			info.LastModified = modified.UTC().Format(time.RFC3339)
+		}
+		suites = append(suites, info)
 	}
 
 	data, err := json.MarshalIndent(suites, "", "  ")
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal test suites: %v", err)), nil
+		return toolErrorf(codeInternal, "failed to marshal test suites: %v", err), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// validateTestSuiteArgs are the arguments of validate_test_suite.
+type validateTestSuiteArgs struct {
+	TestSuite    string `json:"test_suite" jsonschema_description:"Name of the test suite to validate"`
+	Lint         bool   `json:"lint,omitempty" jsonschema_description:"Also have the scoring model flag expected answers likely to produce unstable verdicts: too short, one of several valid phrasings, or environment-specific (default: false)"`
+	ScoringModel string `json:"scoring_model,omitempty" jsonschema_description:"Model linting the expected answers when 'lint' is enabled (default: server scoring model)"`
+}
+
+func handleValidateTestSuite(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	args, err := bindArgs[validateTestSuiteArgs](request)
+	if err != nil {
+		return toolErrorFrom(err, codeInvalidArgument), nil
+	}
+
+	fsys, err := testsuite.Open(args.TestSuite, sc.SuitesDir)
+	if err != nil {
+		return toolErrorFrom(err, codeInvalidArgument), nil
+	}
+	report := runner.ValidateSuite(fsys)
+
+	if args.Lint && report.Questions > 0 {
+		if sc.LLMClient == nil {
+			return toolError(codeNotConfigured, "LLM client is not configured"), nil
+		}
+		cfg := scorer.Config{Model: sc.ScoringModel}
+		if model := args.ScoringModel; model != "" {
+			name, client, err := aliasedModel(sc, model)
+			if err != nil {
+				return toolErrorFrom(err, codeInvalidArgument), nil
+			}
+			cfg.Model = name
+			cfg.Clients = addClient(cfg.Clients, name, client)
+		}
+		scorer.NewScorer(sc.LLMClient, cfg).LintSuite(ctx, report)
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return toolErrorf(codeInternal, "failed to marshal validation report: %v", err), nil
 	}
 	return mcp.NewToolResultText(string(data)), nil
 }
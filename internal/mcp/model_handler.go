@@ -4,46 +4,68 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/invopop/jsonschema"
 	"github.com/mark3labs/mcp-go/mcp"
 	mcpserver "github.com/mark3labs/mcp-go/server"
 
 	"github.com/giantswarm/llm-testing/internal/kserve"
+	"github.com/giantswarm/llm-testing/internal/llm"
 	"github.com/giantswarm/llm-testing/internal/server"
+	"github.com/giantswarm/llm-testing/internal/testsuite"
 )
 
+// defaultWatchTimeout is how long watch_model follows a model by default.
+const defaultWatchTimeout = 10 * time.Minute
+
+// defaultCleanupTTL is the age after which cleanup_models tears down deployments.
+const defaultCleanupTTL = 24 * time.Hour
+
 func registerModelTools(s *mcpserver.MCPServer, sc *server.ServerContext) error {
 	// deploy_model
 	deployTool := mcp.NewTool("deploy_model",
-		mcp.WithDescription("Deploy a model via KServe InferenceService (vLLM runtime). Creates a new InferenceService CRD and waits for it to become ready."),
-		mcp.WithString("model_name",
-			mcp.Required(),
-			mcp.Description("Name for the InferenceService resource"),
-		),
-		mcp.WithString("model_uri",
-			mcp.Required(),
-			mcp.Description("Model storage URI (e.g. 'hf://mistralai/Mistral-7B-Instruct-v0.3')"),
-		),
-		mcp.WithNumber("gpu_count",
-			mcp.Description("Number of GPUs to request (default: 1)"),
-		),
-		mcp.WithArray("runtime_args",
-			mcp.Description("Optional runtime arguments for the serving runtime (e.g. ['--max-model-len=4096'])"),
-			mcp.WithStringItems(),
-		),
+		mcp.WithDescription("Deploy a model via KServe InferenceService (vLLM runtime) and wait for it to become ready. An existing InferenceService with the same name is reused if its spec is unchanged and updated otherwise; the result's 'action' reports which happened."),
+		mcp.WithInputSchema[deployModelArgs](),
 	)
 	s.AddTool(deployTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		return handleDeployModel(ctx, request, sc)
 	})
 
+	// render_model_manifest
+	renderTool := mcp.NewTool("render_model_manifest",
+		mcp.WithDescription("Render the InferenceService manifest deploy_model would create, for review or check-in to a GitOps repository, without touching the cluster"),
+		mcp.WithInputSchema[renderManifestArgs](),
+	)
+	s.AddTool(renderTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleRenderModelManifest(ctx, request, sc)
+	})
+
+	// list_runtimes
+	listRuntimesTool := mcp.NewTool("list_runtimes",
+		mcp.WithDescription("Report what the cluster can serve, to choose a runtime and GPU count before deploy_model: the KServe ServingRuntimes in the namespace and the ClusterServingRuntimes, the GPU nodes with their GPU type, free GPUs, and taints (to tolerate), free GPUs per GPU type, the KServe version and default deployment mode, and whether Knative Serving (needed for scale-to-zero with min_replicas 0) and Istio are installed"),
+	)
+	s.AddTool(listRuntimesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleListRuntimes(ctx, request, sc)
+	})
+
+	// create_runtime
+	createRuntimeTool := mcp.NewTool("create_runtime",
+		mcp.WithDescription("Create a custom KServe ServingRuntime running a specific vLLM image, e.g. to test a new vLLM release, or an Ollama or llama.cpp server for CPU-only clusters. Use its name as 'runtime' in deploy_model."),
+		mcp.WithInputSchema[createRuntimeArgs](),
+	)
+	s.AddTool(createRuntimeTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleCreateRuntime(ctx, request, sc)
+	})
+
 	// teardown_model
 	teardownTool := mcp.NewTool("teardown_model",
 		mcp.WithDescription("Delete a KServe InferenceService to stop serving a model"),
-		mcp.WithString("model_name",
-			mcp.Required(),
-			mcp.Description("Name of the InferenceService to delete"),
-		),
+		mcp.WithInputSchema[teardownModelArgs](),
 	)
 	s.AddTool(teardownTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		return handleTeardownModel(ctx, request, sc)
@@ -52,96 +74,728 @@ func registerModelTools(s *mcpserver.MCPServer, sc *server.ServerContext) error
 	// list_models
 	listTool := mcp.NewTool("list_models",
 		mcp.WithDescription("List InferenceService resources managed by llm-testing"),
+		mcp.WithInputSchema[listModelsArgs](),
 	)
 	s.AddTool(listTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		return handleListModels(ctx, request, sc)
 	})
 
+	// list_endpoint_models
+	listEndpointTool := mcp.NewTool("list_endpoint_models",
+		mcp.WithDescription("List the models an OpenAI-compatible endpoint serves (its /models API), e.g. to find the exact model name a vLLM server expects"),
+		mcp.WithInputSchema[listEndpointModelsArgs](),
+	)
+	s.AddTool(listEndpointTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleListEndpointModels(ctx, request, sc)
+	})
+
+	// list_model_aliases
+	listAliasesTool := mcp.NewTool("list_model_aliases",
+		mcp.WithDescription("List the model aliases of the server (e.g. 'judge-default' or 'small-gpu') with the model, provider, and deployment settings each stands for. An alias can be given wherever a tool takes a model name: models of run_test_suite, evaluate_model, deploy_model, scoring_model, and judges."),
+	)
+	s.AddTool(listAliasesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleListModelAliases(ctx, request, sc)
+	})
+
+	// get_model
+	getTool := mcp.NewTool("get_model",
+		mcp.WithDescription("Get the detailed state of a KServe InferenceService: conditions, revisions, model URI, runtime, GPU allocation, endpoint, and predictor pods"),
+		mcp.WithInputSchema[getModelArgs](),
+	)
+	s.AddTool(getTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleGetModel(ctx, request, sc)
+	})
+
+	// watch_model
+	watchTool := mcp.NewTool("watch_model",
+		mcp.WithDescription("Follow a KServe InferenceService until it is ready, its predictor pods fail (e.g. CrashLoopBackOff), or the timeout expires. Status transitions and pod events are sent as progress notifications and returned as 'transitions'."),
+		mcp.WithInputSchema[watchModelArgs](),
+	)
+	s.AddTool(watchTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleWatchModel(ctx, request, sc)
+	})
+
+	// cleanup_models
+	cleanupTool := mcp.NewTool("cleanup_models",
+		mcp.WithDescription("Tear down the InferenceServices managed by llm-testing that are older than a TTL (or all of them), so that forgotten deployments stop holding GPUs"),
+		mcp.WithInputSchema[cleanupModelsArgs](),
+	)
+	s.AddTool(cleanupTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleCleanupModels(ctx, request, sc)
+	})
+
 	return nil
 }
 
+// modelSpecArgs are the arguments of the InferenceService spec, shared by
+// deploy_model and render_model_manifest.
+type modelSpecArgs struct {
+	ModelName            string   `json:"model_name" jsonschema_description:"Name for the InferenceService resource, or a model alias of the server (see list_model_aliases) whose deployment settings the other parameters override"`
+	ModelURI             string   `json:"model_uri,omitempty" jsonschema_description:"Model storage URI: 'hf://<org>/<model>' (e.g. 'hf://mistralai/Mistral-7B-Instruct-v0.3'), 'pvc://<claim>/<path>' for a model pre-staged on a PersistentVolumeClaim, or 's3://<bucket>/<path>'; required unless model_name is an alias with a model_uri"`
+	GPUCount             int      `json:"gpu_count,omitempty" jsonschema:"minimum=0" jsonschema_description:"Number of GPUs to request (default: estimated from the model size for hf:// URIs, otherwise 1; none for the CPU backends)"`
+	Backend              string   `json:"backend,omitempty" jsonschema:"enum=vllm,enum=ollama,enum=llamacpp"`
+	GGUFFile             string   `json:"gguf_file,omitempty" jsonschema_description:"GGUF file of the hf:// repository to serve with the ollama or llamacpp backend, e.g. 'model-Q4_K_M.gguf'; only it is downloaded (default: the Q4_K_M file or the next best quantization of a public repository)"`
+	Runtime              string   `json:"runtime,omitempty" jsonschema_description:"KServe ServingRuntime or ClusterServingRuntime to serve the model with (default: kserve-vllm). See list_runtimes."`
+	RuntimeArgs          []string `json:"runtime_args,omitempty" jsonschema_description:"Optional runtime arguments for the serving runtime (e.g. ['--max-model-len=4096'])"`
+	Quantization         string   `json:"quantization,omitempty" jsonschema_description:"Quantization preset that sets the matching vLLM runtime args: 'awq' or 'gptq' for quantized checkpoints, 'fp8' or 'bitsandbytes' to quantize on load"`
+	ArgsPreset           string   `json:"args_preset,omitempty" jsonschema_description:"Curated vLLM runtime args: 'long-context' (chunked prefill, FP8 KV cache), 'throughput' (large batches, prefix caching), or 'low-latency' (small batches). runtime_args take precedence over it."`
+	Env                  string   `json:"env,omitempty" jsonschema_description:"JSON object of environment variables for the model container (e.g. '{\"VLLM_ATTENTION_BACKEND\":\"FLASHINFER\"}')"`
+	RawDeployment        bool     `json:"raw_deployment,omitempty" jsonschema_description:"Deploy in KServe's RawDeployment mode (a plain Kubernetes Deployment) instead of as a Knative service; multi-node deployments always use it (default: false)"`
+	CPU                  string   `json:"cpu,omitempty" jsonschema_description:"CPU request (e.g. '8')"`
+	CPULimit             string   `json:"cpu_limit,omitempty" jsonschema_description:"CPU limit (e.g. '16')"`
+	Memory               string   `json:"memory,omitempty" jsonschema_description:"Memory request (e.g. '64Gi')"`
+	MemoryLimit          string   `json:"memory_limit,omitempty" jsonschema_description:"Memory limit (e.g. '96Gi')"`
+	ShmSize              string   `json:"shm_size,omitempty"`
+	HFTokenSecret        string   `json:"hf_token_secret,omitempty" jsonschema_description:"Kubernetes Secret holding a HuggingFace token, injected as HF_TOKEN for downloading gated models (default: server's --hf-token-secret)"`
+	HFTokenSecretKey     string   `json:"hf_token_secret_key,omitempty"`
+	ServiceAccount       string   `json:"service_account,omitempty" jsonschema_description:"Service account for the predictor pod. For s3:// URIs, its Secrets provide the S3 credentials unless storage_key is set."`
+	StorageKey           string   `json:"storage_key,omitempty" jsonschema_description:"Entry of KServe's storage-config Secret with the S3 endpoint and credentials (s3:// URIs only)"`
+	ImagePullSecrets     []string `json:"image_pull_secrets,omitempty" jsonschema_description:"Secrets for pulling private runtime images"`
+	NodeSelector         string   `json:"node_selector,omitempty" jsonschema_description:"JSON object of node labels the predictor must run on (e.g. '{\"nvidia.com/gpu.product\":\"NVIDIA-H100-80GB-HBM3\"}')"`
+	Tolerations          string   `json:"tolerations,omitempty" jsonschema_description:"JSON array of Kubernetes tolerations (e.g. '[{\"key\":\"nvidia.com/gpu\",\"operator\":\"Exists\",\"effect\":\"NoSchedule\"}]')"`
+	Affinity             string   `json:"affinity,omitempty" jsonschema_description:"JSON object in Kubernetes pod affinity format"`
+	RuntimeClassName     string   `json:"runtime_class_name,omitempty" jsonschema_description:"RuntimeClass for the predictor pod (e.g. 'nvidia')"`
+	TensorParallelSize   int      `json:"tensor_parallel_size,omitempty" jsonschema:"minimum=0" jsonschema_description:"GPUs to shard the model across on each node; sets the GPUs per node"`
+	PipelineParallelSize int      `json:"pipeline_parallel_size,omitempty" jsonschema:"minimum=0"`
+	WorkerCount          int      `json:"worker_count,omitempty" jsonschema:"minimum=0" jsonschema_description:"Worker nodes besides the head node; an alternative to pipeline_parallel_size (= worker_count + 1)"`
+	MinReplicas          *int     `json:"min_replicas,omitempty" jsonschema:"minimum=0" jsonschema_description:"Minimum predictor replicas. 0 enables scale-to-zero so an idle deployment releases its GPUs (default: KServe's, 1)"`
+	MaxReplicas          int      `json:"max_replicas,omitempty" jsonschema:"minimum=0" jsonschema_description:"Maximum predictor replicas"`
+	ScaleTarget          int      `json:"scale_target,omitempty" jsonschema:"minimum=0" jsonschema_description:"Per-replica target of scale_metric for the autoscaler"`
+	ScaleMetric          string   `json:"scale_metric,omitempty" jsonschema:"enum=concurrency,enum=rps,enum=cpu,enum=memory" jsonschema_description:"Autoscaling metric: concurrency, rps, cpu, or memory"`
+}
+
+// JSONSchemaExtend completes the schema with the descriptions and enums
+// that name the defaults of the kserve package.
+func (modelSpecArgs) JSONSchemaExtend(s *jsonschema.Schema) {
+	describeArg(s, "backend", "Model server: 'vllm' on GPUs, or 'ollama' or 'llamacpp' to serve a GGUF model on CPU-only clusters (default: vllm). The CPU backends use the "+kserve.DefaultOllamaRuntime+" and "+kserve.DefaultLlamaCPPRuntime+" runtimes, which are created if missing, and default to 2 CPUs and 8Gi memory.")
+	enumArg(s, "quantization", kserve.QuantizationMethods())
+	enumArg(s, "args_preset", kserve.ArgsPresets())
+	describeArg(s, "shm_size", "Size of the in-memory /dev/shm volume vLLM uses for tensor parallelism (default: "+kserve.DefaultShmSize+")")
+	describeArg(s, "hf_token_secret_key", "Key of the token in hf_token_secret (default: "+kserve.DefaultHFTokenSecretKey+")")
+	describeArg(s, "pipeline_parallel_size", "Nodes to split the model across for models that don't fit a single node; more than 1 deploys a multi-node KServe workerSpec with the "+kserve.DefaultMultiNodeRuntime+" runtime")
+}
+
+// deployModelArgs are the arguments of deploy_model.
+type deployModelArgs struct {
+	modelSpecArgs
+	CapacityCheck   string `json:"capacity_check,omitempty" jsonschema:"enum=off,enum=warn,enum=enforce" jsonschema_description:"Check that a node has enough free GPUs before deploying: off, warn (deploy anyway and report 'capacity_warning'), or enforce (refuse) (default: server's --gpu-capacity-check)"`
+	Recreate        bool   `json:"recreate,omitempty" jsonschema_description:"Delete an existing InferenceService with the same name and create it anew instead of updating it in place (default: false)"`
+	ProbeCompletion bool   `json:"probe_completion,omitempty" jsonschema_description:"Once the model is ready, also request a one-token completion before reporting it ready, not only GET /v1/models (default: false)"`
+	Namespace       string `json:"namespace,omitempty" jsonschema_description:"Namespace of the InferenceService (default: the server's namespace)"`
+}
+
+// renderManifestArgs are the arguments of render_model_manifest.
+type renderManifestArgs struct {
+	modelSpecArgs
+	Format    string `json:"format,omitempty" jsonschema:"enum=yaml,enum=json" jsonschema_description:"Output format (default: yaml)"`
+	Namespace string `json:"namespace,omitempty" jsonschema_description:"Namespace of the InferenceService (default: the server's namespace)"`
+}
+
 func handleDeployModel(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
 	if sc.KServeManager == nil {
-		return mcp.NewToolResultError("KServe manager is not configured (not running in-cluster or KServe not available)"), nil
+		return toolError(codeNotConfigured, "KServe manager is not configured (not running in-cluster or KServe not available)"), nil
 	}
 
-	args := request.GetArguments()
+	args, err := bindModelArgs[deployModelArgs](request, sc.ModelAliases)
+	if err != nil {
+		return toolErrorFrom(err, codeInvalidArgument), nil
+	}
+	manager := managerFor(sc, args.Namespace)
 
-	modelName, ok := args["model_name"].(string)
-	if !ok || modelName == "" {
-		return mcp.NewToolResultError("model_name is required"), nil
+	cfg, explicitGPUs, err := modelConfigFromArgs(args.modelSpecArgs, sc)
+	if err != nil {
+		return toolErrorFrom(err, codeInvalidArgument), nil
+	}
+	if strings.TrimSpace(args.Runtime) != "" {
+		if err := manager.CheckRuntime(ctx, cfg.Runtime); err != nil {
+			return toolErrorFrom(err, codeInvalidArgument), nil
+		}
 	}
 
-	modelURI, ok := args["model_uri"].(string)
-	if !ok || modelURI == "" {
-		return mcp.NewToolResultError("model_uri is required"), nil
+	cfg.Recreate = args.Recreate
+	cfg.ProbeCompletion = args.ProbeCompletion
+	cfg.CapacityCheck = sc.GPUCapacityCheck
+	cfg.URLMode = sc.EndpointURLMode
+	if v := args.CapacityCheck; v != "" {
+		check, err := kserve.ParseCapacityCheck(v)
+		if err != nil {
+			return toolErrorFrom(err, codeInvalidArgument), nil
+		}
+		cfg.CapacityCheck = check
 	}
 
-	cfg := kserve.DefaultModelConfig(modelName, modelURI)
+	rec := recommendGPUs(ctx, sc, &cfg, explicitGPUs)
+	resolveGGUFFile(ctx, sc, &cfg)
 
-	if gpuCount, ok := args["gpu_count"].(float64); ok && gpuCount > 0 {
-		cfg.GPUCount = int(gpuCount)
+	// Report progress in elapsed seconds out of the ready timeout.
+	notify := newProgressNotifier(ctx, request)
+	cfg.Progress = func(p kserve.DeployProgress) {
+		notify(p.Elapsed.Seconds(), cfg.ReadyTimeout.Seconds(), p.String())
 	}
-	if rawArgs, ok := args["runtime_args"].([]interface{}); ok && len(rawArgs) > 0 {
-		runtimeArgs := make([]string, 0, len(rawArgs))
-		for _, arg := range rawArgs {
-			argStr, ok := arg.(string)
-			if !ok {
-				return mcp.NewToolResultError("runtime_args must be an array of strings"), nil
-			}
-			argStr = strings.TrimSpace(argStr)
-			if argStr == "" {
-				return mcp.NewToolResultError("runtime_args entries must be non-empty strings"), nil
-			}
-			runtimeArgs = append(runtimeArgs, argStr)
+
+	status, err := manager.Deploy(ctx, cfg)
+	if err != nil {
+		return toolErrorFromf(err, codeDeploymentFailed, "failed to deploy model: %v", err), nil
+	}
+	status.GPURecommendation = rec
+
+	data, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		return toolErrorf(codeInternal, "failed to marshal status: %v", err), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+func handleRenderModelManifest(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	args, err := bindModelArgs[renderManifestArgs](request, sc.ModelAliases)
+	if err != nil {
+		return toolErrorFrom(err, codeInvalidArgument), nil
+	}
+
+	cfg, explicitGPUs, err := modelConfigFromArgs(args.modelSpecArgs, sc)
+	if err != nil {
+		return toolErrorFrom(err, codeInvalidArgument), nil
+	}
+	recommendGPUs(ctx, sc, &cfg, explicitGPUs)
+	resolveGGUFFile(ctx, sc, &cfg)
+
+	namespace := strings.TrimSpace(args.Namespace)
+	if namespace == "" {
+		namespace = sc.Namespace
+		if sc.KServeManager != nil {
+			namespace = sc.KServeManager.Namespace()
 		}
-		cfg.RuntimeArgs = runtimeArgs
+	}
+	format := args.Format
+	if format == "" {
+		format = "yaml"
 	}
 
-	status, err := sc.KServeManager.Deploy(ctx, cfg)
+	data, err := kserve.RenderManifest(cfg, namespace, format)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to deploy model: %v", err)), nil
+		return toolErrorFromf(err, codeInvalidArgument, "failed to render manifest: %v", err), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+func handleListRuntimes(ctx context.Context, _ mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	if sc.KServeManager == nil {
+		return toolError(codeNotConfigured, "KServe manager is not configured"), nil
 	}
 
-	data, err := json.MarshalIndent(status, "", "  ")
+	info, err := sc.KServeManager.ClusterInfo(ctx)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal status: %v", err)), nil
+		return toolErrorFromf(err, codeKubernetesError, "failed to list runtimes: %v", err), nil
+	}
+
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return toolErrorf(codeInternal, "failed to marshal runtimes: %v", err), nil
 	}
 	return mcp.NewToolResultText(string(data)), nil
 }
 
-func handleTeardownModel(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+// createRuntimeArgs are the arguments of create_runtime.
+type createRuntimeArgs struct {
+	Name         string   `json:"name"`
+	Backend      string   `json:"backend,omitempty" jsonschema:"enum=vllm,enum=ollama,enum=llamacpp" jsonschema_description:"Model server: 'vllm', 'ollama', or 'llamacpp' (default: vllm)"`
+	VLLMImageTag string   `json:"vllm_image_tag,omitempty" jsonschema_description:"Image tag (e.g. 'v0.6.3'); required for the vllm backend, while ollama defaults to 'latest' and llamacpp to 'server'"`
+	Image        string   `json:"image,omitempty"`
+	Args         []string `json:"args,omitempty" jsonschema_description:"Additional server arguments for every model served by the runtime (not supported by ollama)"`
+}
+
+// JSONSchemaExtend completes the schema with the descriptions that name the
+// default runtimes and images of the kserve package.
+func (createRuntimeArgs) JSONSchemaExtend(s *jsonschema.Schema) {
+	describeArg(s, "name", "Name for the ServingRuntime resource (deploy_model uses "+kserve.DefaultOllamaRuntime+" and "+kserve.DefaultLlamaCPPRuntime+" for the CPU backends)")
+	describeArg(s, "image", "Image repository (default: "+kserve.DefaultVLLMImage+", "+kserve.DefaultOllamaImage+", or "+kserve.DefaultLlamaCPPImage+")")
+}
+
+func handleCreateRuntime(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
 	if sc.KServeManager == nil {
-		return mcp.NewToolResultError("KServe manager is not configured"), nil
+		return toolError(codeNotConfigured, "KServe manager is not configured"), nil
 	}
 
-	args := request.GetArguments()
+	args, err := bindArgs[createRuntimeArgs](request)
+	if err != nil {
+		return toolErrorFrom(err, codeInvalidArgument), nil
+	}
+
+	t := kserve.RuntimeTemplate{Name: args.Name, ImageTag: args.VLLMImageTag, Image: args.Image}
+	backend, err := kserve.ParseBackend(args.Backend)
+	if err != nil {
+		return toolErrorFrom(err, codeInvalidArgument), nil
+	}
+	t.Backend = backend
+	if t.ImageTag == "" && backend == kserve.BackendVLLM {
+		return toolError(codeInvalidArgument, "vllm_image_tag is required"), nil
+	}
 
-	modelName, ok := args["model_name"].(string)
-	if !ok || modelName == "" {
-		return mcp.NewToolResultError("model_name is required"), nil
+	extraArgs, err := trimStrings("args", args.Args)
+	if err != nil {
+		return toolErrorFrom(err, codeInvalidArgument), nil
 	}
+	t.Args = extraArgs
 
-	if err := sc.KServeManager.Teardown(ctx, modelName); err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to teardown model: %v", err)), nil
+	info, err := sc.KServeManager.CreateRuntime(ctx, t)
+	if err != nil {
+		return toolErrorFromf(err, codeInvalidArgument, "failed to create runtime: %v", err), nil
+	}
+
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return toolErrorf(codeInternal, "failed to marshal runtime: %v", err), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// modelConfigFromArgs reads the modelSpecArgs into a validated config.
+// explicitGPUs reports whether gpu_count was given.
+func modelConfigFromArgs(args modelSpecArgs, sc *server.ServerContext) (kserve.ModelConfig, bool, error) {
+	if args.ModelURI == "" {
+		return kserve.ModelConfig{}, false, fmt.Errorf("model_uri is required")
+	}
+
+	cfg := kserve.DefaultModelConfig(args.ModelName, args.ModelURI)
+	backend, err := kserve.ParseBackend(args.Backend)
+	if err != nil {
+		return cfg, false, err
+	}
+	cfg.UseBackend(backend)
+	cfg.GGUFFile = args.GGUFFile
+	if runtime := strings.TrimSpace(args.Runtime); runtime != "" {
+		cfg.Runtime = runtime
+	}
+
+	explicitGPUs := args.GPUCount > 0
+	if explicitGPUs {
+		cfg.GPUCount = args.GPUCount
+	}
+	runtimeArgs, err := trimStrings("runtime_args", args.RuntimeArgs)
+	if err != nil {
+		return cfg, false, err
+	}
+	cfg.RuntimeArgs = runtimeArgs
+	cfg.Quantization = args.Quantization
+	cfg.ArgsPreset = args.ArgsPreset
+	if err := parseEnv(args, &cfg); err != nil {
+		return cfg, false, err
+	}
+	cfg.RawDeployment = args.RawDeployment
+
+	if err := parsePlacement(args, &cfg); err != nil {
+		return cfg, false, err
+	}
+	parseResources(args, &cfg)
+	parseScaling(args, &cfg)
+	parseParallelism(args, &cfg)
+	if err := parseCredentials(args, sc, &cfg); err != nil {
+		return cfg, false, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, false, err
+	}
+	return cfg, explicitGPUs, nil
+}
+
+// parsePlacement reads the scheduling constraint arguments of deploy_model into cfg.
+func parsePlacement(args modelSpecArgs, cfg *kserve.ModelConfig) error {
+	if v := args.NodeSelector; v != "" {
+		if err := json.Unmarshal([]byte(v), &cfg.NodeSelector); err != nil {
+			return fmt.Errorf("invalid node_selector JSON: %v", err)
+		}
+	}
+	if v := args.Tolerations; v != "" {
+		if err := json.Unmarshal([]byte(v), &cfg.Tolerations); err != nil {
+			return fmt.Errorf("invalid tolerations JSON: %v", err)
+		}
+	}
+	if v := args.Affinity; v != "" {
+		if err := json.Unmarshal([]byte(v), &cfg.Affinity); err != nil {
+			return fmt.Errorf("invalid affinity JSON: %v", err)
+		}
+	}
+	cfg.RuntimeClassName = args.RuntimeClassName
+	return nil
+}
+
+// parseEnv reads the environment variables of deploy_model into cfg.
+func parseEnv(args modelSpecArgs, cfg *kserve.ModelConfig) error {
+	if v := args.Env; v != "" {
+		if err := json.Unmarshal([]byte(v), &cfg.Env); err != nil {
+			return fmt.Errorf("invalid env JSON: %v", err)
+		}
+	}
+	return nil
+}
+
+// parseResources reads the resource arguments of deploy_model into cfg.
+func parseResources(args modelSpecArgs, cfg *kserve.ModelConfig) {
+	if args.CPU != "" {
+		cfg.CPURequest = args.CPU
+	}
+	cfg.CPULimit = args.CPULimit
+	if args.Memory != "" {
+		cfg.MemoryRequest = args.Memory
+	}
+	cfg.MemoryLimit = args.MemoryLimit
+	if args.ShmSize != "" {
+		cfg.ShmSize = args.ShmSize
+	}
+}
+
+// parseScaling reads the autoscaling arguments of deploy_model into cfg.
+func parseScaling(args modelSpecArgs, cfg *kserve.ModelConfig) {
+	cfg.MinReplicas = args.MinReplicas
+	cfg.MaxReplicas = args.MaxReplicas
+	cfg.ScaleTarget = args.ScaleTarget
+	cfg.ScaleMetric = args.ScaleMetric
+}
+
+// parseParallelism reads the multi-GPU and multi-node arguments of deploy_model into cfg.
+func parseParallelism(args modelSpecArgs, cfg *kserve.ModelConfig) {
+	cfg.TensorParallelSize = args.TensorParallelSize
+	cfg.PipelineParallelSize = args.PipelineParallelSize
+	cfg.WorkerCount = args.WorkerCount
+}
+
+// parseCredentials reads the credential arguments of deploy_model into cfg,
+// defaulting the HuggingFace token Secret to the server's.
+func parseCredentials(args modelSpecArgs, sc *server.ServerContext, cfg *kserve.ModelConfig) error {
+	cfg.HFTokenSecret = args.HFTokenSecret
+	if cfg.HFTokenSecret == "" {
+		cfg.HFTokenSecret = sc.HFTokenSecret
+	}
+	cfg.HFTokenSecretKey = args.HFTokenSecretKey
+	cfg.ServiceAccountName = args.ServiceAccount
+	cfg.StorageKey = args.StorageKey
+
+	pullSecrets, err := trimStrings("image_pull_secrets", args.ImagePullSecrets)
+	if err != nil {
+		return err
+	}
+	cfg.ImagePullSecrets = pullSecrets
+	return nil
+}
+
+// trimStrings trims the entries of the array argument name, which must not
+// be empty.
+func trimStrings(name string, values []string) ([]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	trimmed := make([]string, 0, len(values))
+	for _, s := range values {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			return nil, fmt.Errorf("%s entries must be non-empty strings", name)
+		}
+		trimmed = append(trimmed, s)
+	}
+	return trimmed, nil
+}
+
+// teardownModelArgs are the arguments of teardown_model.
+type teardownModelArgs struct {
+	ModelName string `json:"model_name" jsonschema_description:"Name of the InferenceService to delete, or 'latest' for the model last deployed in this session"`
+	Namespace string `json:"namespace,omitempty" jsonschema_description:"Namespace of the InferenceService (default: the server's namespace)"`
+}
+
+func handleTeardownModel(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	if sc.KServeManager == nil {
+		return toolError(codeNotConfigured, "KServe manager is not configured"), nil
+	}
+
+	args, err := bindModelArgs[teardownModelArgs](request, sc.ModelAliases)
+	if err != nil {
+		return toolErrorFrom(err, codeInvalidArgument), nil
+	}
+	modelName := args.ModelName
+
+	if err := managerFor(sc, args.Namespace).Teardown(ctx, modelName); err != nil {
+		return toolErrorFromf(err, codeKubernetesError, "failed to teardown model: %v", err), nil
 	}
 
 	return mcp.NewToolResultText(fmt.Sprintf("InferenceService %q deleted", modelName)), nil
 }
 
-func handleListModels(ctx context.Context, _ mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+// listModelsArgs are the arguments of list_models.
+type listModelsArgs struct {
+	Namespace string `json:"namespace,omitempty" jsonschema_description:"Namespace to list (default: the server's namespace)"`
+}
+
+func handleListModels(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
 	if sc.KServeManager == nil {
-		return mcp.NewToolResultError("KServe manager is not configured"), nil
+		return toolError(codeNotConfigured, "KServe manager is not configured"), nil
+	}
+
+	args, err := bindArgs[listModelsArgs](request)
+	if err != nil {
+		return toolErrorFrom(err, codeInvalidArgument), nil
 	}
 
-	statuses, err := sc.KServeManager.List(ctx)
+	statuses, err := managerFor(sc, args.Namespace).List(ctx)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to list models: %v", err)), nil
+		return toolErrorFromf(err, codeKubernetesError, "failed to list models: %v", err), nil
 	}
 
 	data, err := json.MarshalIndent(statuses, "", "  ")
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal statuses: %v", err)), nil
+		return toolErrorf(codeInternal, "failed to marshal statuses: %v", err), nil
 	}
 	return mcp.NewToolResultText(string(data)), nil
 }
+
+// listEndpointModelsArgs are the arguments of list_endpoint_models.
+type listEndpointModelsArgs struct {
+	Endpoint string `json:"endpoint,omitempty" jsonschema_description:"Base URL of the API, e.g. http://vllm:8000/v1 (default: the provider's endpoint, or the server's default endpoint)"`
+	Provider string `json:"provider,omitempty" jsonschema_description:"Provider from the server's provider registry whose endpoint and credentials to use (optional)"`
+}
+
+func handleListEndpointModels(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	args, err := bindArgs[listEndpointModelsArgs](request)
+	if err != nil {
+		return toolErrorFrom(err, codeInvalidArgument), nil
+	}
+	endpoint, provider := args.Endpoint, args.Provider
+
+	client := sc.LLMClient
+	switch {
+	case provider != "":
+		opts := sc.LLMOptions
+		if endpoint != "" {
+			opts = append(slices.Clone(opts), llm.WithBaseURL(endpoint))
+		}
+		var err error
+		if client, err = sc.Providers.Client(provider, opts...); err != nil {
+			return toolErrorFromf(err, codeInvalidArgument, "failed to create client: %v", err), nil
+		}
+	case endpoint != "":
+		client = newEndpointClient(endpoint, sc.LLMAPIKey, sc.LLMAPIKeyFile, sc.LLMOptions...)
+	}
+	if client == nil {
+		return toolError(codeInvalidArgument, "endpoint is required: the server has no default LLM client"), nil
+	}
+
+	models, err := llm.ListModels(ctx, client)
+	if err != nil {
+		return toolErrorFromf(err, codeLLMError, "failed to list endpoint models: %v", err), nil
+	}
+
+	data, err := json.MarshalIndent(map[string]interface{}{"models": models}, "", "  ")
+	if err != nil {
+		return toolErrorf(codeInternal, "failed to marshal models: %v", err), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// getModelArgs are the arguments of get_model.
+type getModelArgs struct {
+	ModelName string `json:"model_name" jsonschema_description:"Name of the InferenceService, or 'latest' for the model last deployed in this session"`
+	Namespace string `json:"namespace,omitempty" jsonschema_description:"Namespace of the InferenceService (default: the server's namespace)"`
+}
+
+func handleGetModel(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	if sc.KServeManager == nil {
+		return toolError(codeNotConfigured, "KServe manager is not configured"), nil
+	}
+
+	args, err := bindModelArgs[getModelArgs](request, sc.ModelAliases)
+	if err != nil {
+		return toolErrorFrom(err, codeInvalidArgument), nil
+	}
+
+	details, err := managerFor(sc, args.Namespace).Describe(ctx, args.ModelName)
+	if err != nil {
+		return toolErrorFromf(err, codeKubernetesError, "failed to get model: %v", err), nil
+	}
+
+	data, err := json.MarshalIndent(details, "", "  ")
+	if err != nil {
+		return toolErrorf(codeInternal, "failed to marshal model: %v", err), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// watchModelArgs are the arguments of watch_model.
+type watchModelArgs struct {
+	ModelName      string  `json:"model_name" jsonschema_description:"Name of the InferenceService, or 'latest' for the model last deployed in this session"`
+	TimeoutSeconds float64 `json:"timeout_seconds,omitempty" jsonschema:"minimum=1" jsonschema_description:"How long to watch (default: 600)"`
+	Namespace      string  `json:"namespace,omitempty" jsonschema_description:"Namespace of the InferenceService (default: the server's namespace)"`
+}
+
+func handleWatchModel(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	if sc.KServeManager == nil {
+		return toolError(codeNotConfigured, "KServe manager is not configured"), nil
+	}
+
+	args, err := bindModelArgs[watchModelArgs](request, sc.ModelAliases)
+	if err != nil {
+		return toolErrorFrom(err, codeInvalidArgument), nil
+	}
+	modelName := args.ModelName
+	timeout := defaultWatchTimeout
+	if args.TimeoutSeconds > 0 {
+		timeout = time.Duration(args.TimeoutSeconds * float64(time.Second))
+	}
+
+	// Report progress in elapsed seconds out of the timeout.
+	notify := newProgressNotifier(ctx, request)
+	result, err := managerFor(sc, args.Namespace).Watch(ctx, modelName, timeout, func(p kserve.DeployProgress) {
+		notify(p.Elapsed.Seconds(), timeout.Seconds(), p.String())
+	})
+	if err != nil {
+		return toolErrorFromf(err, codeKubernetesError, "failed to watch model: %v", err), nil
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return toolErrorf(codeInternal, "failed to marshal watch result: %v", err), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// cleanupModelsArgs are the arguments of cleanup_models.
+type cleanupModelsArgs struct {
+	TTL       string `json:"ttl,omitempty" jsonschema_description:"Minimum age of the InferenceServices to tear down, as a Go duration (default: 24h)"`
+	All       bool   `json:"all,omitempty" jsonschema_description:"Tear down all managed InferenceServices regardless of age (default: false)"`
+	DryRun    bool   `json:"dry_run,omitempty" jsonschema_description:"Only list the InferenceServices that would be torn down (default: false)"`
+	Namespace string `json:"namespace,omitempty" jsonschema_description:"Namespace to clean up (default: the server's namespace)"`
+}
+
+func handleCleanupModels(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	if sc.KServeManager == nil {
+		return toolError(codeNotConfigured, "KServe manager is not configured"), nil
+	}
+
+	args, err := bindArgs[cleanupModelsArgs](request)
+	if err != nil {
+		return toolErrorFrom(err, codeInvalidArgument), nil
+	}
+
+	opts := kserve.CleanupOptions{TTL: defaultCleanupTTL}
+	if v := args.TTL; v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil || ttl <= 0 {
+			return toolErrorf(codeInvalidArgument, "invalid ttl %q: must be a positive duration such as '12h'", v), nil
+		}
+		opts.TTL = ttl
+	}
+	opts.All, opts.DryRun = args.All, args.DryRun
+
+	results, err := managerFor(sc, args.Namespace).Cleanup(ctx, opts)
+	if err != nil {
+		return toolErrorFromf(err, codeKubernetesError, "failed to clean up models: %v", err), nil
+	}
+
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return toolErrorf(codeInternal, "failed to marshal results: %v", err), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+func handleListModelAliases(_ context.Context, _ mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	aliases := sc.ModelAliases
+	if aliases == nil {
+		aliases = testsuite.ModelAliases{}
+	}
+	data, err := json.MarshalIndent(map[string]interface{}{"aliases": aliases}, "", "  ")
+	if err != nil {
+		return toolErrorf(codeInternal, "failed to marshal aliases: %v", err), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// jsonObjectArgs are the arguments of the model tools that take JSON
+// strings rather than objects or arrays.
+var jsonObjectArgs = map[string]bool{"env": true, "node_selector": true, "tolerations": true, "affinity": true}
+
+// aliasArgs returns the arguments of a model tool whose model_name is a
+// model alias with the settings of the alias, unless the arguments set them,
+// and model_name set to the name of the aliased model. Other arguments are
+// returned unchanged.
+func aliasArgs(args map[string]interface{}, aliases testsuite.ModelAliases) (map[string]interface{}, error) {
+	alias, _ := args["model_name"].(string)
+	model, ok := aliases.Lookup(alias)
+	if !ok {
+		return args, nil
+	}
+	if model.Provider != "" {
+		return nil, fmt.Errorf("model alias %q names a model of provider %q, which is not deployed via KServe", alias, model.Provider)
+	}
+
+	data, err := json.Marshal(model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert model alias %q: %w", alias, err)
+	}
+	var settings map[string]interface{}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("failed to convert model alias %q: %w", alias, err)
+	}
+	delete(settings, "name")
+
+	resolved := maps.Clone(args)
+	for key, value := range settings {
+		if _, ok := resolved[key]; ok {
+			continue
+		}
+		if jsonObjectArgs[key] {
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert %s of model alias %q: %w", key, alias, err)
+			}
+			value = string(encoded)
+		}
+		resolved[key] = value
+	}
+	resolved["model_name"] = model.Name
+	return resolved, nil
+}
+
+// bindModelArgs binds the arguments of a model tool to a T like bindArgs,
+// after resolving a model alias in model_name with aliasArgs.
+func bindModelArgs[T any](request mcp.CallToolRequest, aliases testsuite.ModelAliases) (T, error) {
+	args, err := aliasArgs(request.GetArguments(), aliases)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return bindArgMap[T](args)
+}
+
+// managerFor returns the KServe manager for the optional namespace argument.
+func managerFor(sc *server.ServerContext, namespace string) *kserve.Manager {
+	return sc.KServeManager.WithNamespace(strings.TrimSpace(namespace))
+}
+
+// resolveGGUFFile picks the GGUF file a CPU backend serves of a repository,
+// so that only it is downloaded. Failures to list the files never block a
+// deployment: the whole repository is downloaded then.
+func resolveGGUFFile(ctx context.Context, sc *server.ServerContext, cfg *kserve.ModelConfig) {
+	if err := kserve.ResolveGGUFFile(ctx, sc.ModelMetadata, cfg); err != nil {
+		slog.Warn("failed to pick a GGUF file", "model", cfg.Name, "uri", cfg.ModelURI, "error", err)
+	} else if cfg.GGUFFile != "" {
+		slog.Info("using GGUF file", "model", cfg.Name, "file", cfg.GGUFFile)
+	}
+}
+
+// recommendGPUs sizes the model before deployment. Without an explicit GPU
+// count the recommendation is applied to cfg; otherwise an insufficient count
+// is only warned about. Sizing failures never block a deployment.
+func recommendGPUs(ctx context.Context, sc *server.ServerContext, cfg *kserve.ModelConfig, explicit bool) *kserve.GPURecommendation {
+	rec, err := kserve.RecommendGPUs(ctx, sc.ModelMetadata, cfg, sc.GPUMemoryGiB, explicit)
+	if err != nil {
+		slog.Warn("failed to estimate GPU requirements", "model", cfg.Name, "uri", cfg.ModelURI, "error", err)
+		return nil
+	}
+	if rec == nil {
+		return nil
+	}
+	if rec.Warning != "" {
+		slog.Warn("requested GPU count may be insufficient", "model", cfg.Name, "warning", rec.Warning)
+	} else if rec.Applied {
+		slog.Info("using recommended GPU count", "model", cfg.Name, "gpus", rec.GPUCount, "required_gib", rec.RequiredGiB)
+	}
+	return rec
+}
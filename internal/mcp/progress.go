@@ -0,0 +1,101 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"github.com/giantswarm/llm-testing/internal/kserve"
+	"github.com/giantswarm/llm-testing/internal/testsuite"
+)
+
+// progressNotifier sends MCP progress notifications for a tool call.
+type progressNotifier func(progress, total float64, message string)
+
+// newProgressNotifier returns a notifier for the request's progress token.
+// Without a token or a client session, notifications are dropped.
+func newProgressNotifier(ctx context.Context, request mcp.CallToolRequest) progressNotifier {
+	if request.Params.Meta == nil || request.Params.Meta.ProgressToken == nil {
+		return func(float64, float64, string) {}
+	}
+	srv := mcpserver.ServerFromContext(ctx)
+	if srv == nil {
+		return func(float64, float64, string) {}
+	}
+
+	token := request.Params.Meta.ProgressToken
+	return func(progress, total float64, message string) {
+		params := map[string]any{
+			"progressToken": token,
+			"progress":      progress,
+			"total":         total,
+			"message":       message,
+		}
+		if err := srv.SendNotificationToClient(ctx, "notifications/progress", params); err != nil {
+			slog.Debug("failed to send progress notification", "error", err)
+		}
+	}
+}
+
+// runProgress reports the progress of a test run in questions asked out of
+// the questions of all its models. MCP requires the progress to increase with
+// each notification, so updates between two questions, such as deployment
+// phases and judged answers, advance it halfway to the next question.
+type runProgress struct {
+	notify    progressNotifier
+	questions int // per model
+	models    []string
+
+	mu      sync.Mutex
+	last    float64
+	started bool
+}
+
+func newRunProgress(notify progressNotifier, questions int, models []testsuite.Model) *runProgress {
+	p := &runProgress{notify: notify, questions: questions}
+	for _, model := range models {
+		p.models = append(p.models, model.Name)
+	}
+	return p
+}
+
+// queued reports that the run waits at a position in the run queue.
+func (p *runProgress) queued(position int) {
+	p.report(0, fmt.Sprintf("queued at position %d, waiting for a free run slot", position))
+}
+
+// question reports that a model is asked its index-th (1-based) question.
+func (p *runProgress) question(model string, index, total int) {
+	p.report(p.base(model)+float64(index-1), fmt.Sprintf("%s: question %d/%d", model, index, total))
+}
+
+// deploy reports a deployment phase of a model.
+func (p *runProgress) deploy(progress kserve.DeployProgress) {
+	p.report(p.base(progress.Name), "deploying "+progress.String())
+}
+
+// score reports the running accuracy of a model's judged answers.
+func (p *runProgress) score(model string, score testsuite.LiveScore) {
+	p.report(p.base(model), fmt.Sprintf("%s: %d of %d judged answers correct (%.1f%%)", model, score.Correct, score.Judged, score.Percent))
+}
+
+// base returns the progress at which the questions of a model start.
+func (p *runProgress) base(model string) float64 {
+	return float64(max(slices.Index(p.models, model), 0) * p.questions)
+}
+
+func (p *runProgress) report(progress float64, message string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.started && progress <= p.last {
+		progress = p.last + (math.Floor(p.last)+1-p.last)/2
+	}
+	p.started, p.last = true, progress
+	p.notify(progress, float64(p.questions*len(p.models)), message)
+}
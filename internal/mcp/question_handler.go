@@ -0,0 +1,245 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/llm-testing/internal/scorer"
+	"github.com/giantswarm/llm-testing/internal/server"
+	"github.com/giantswarm/llm-testing/internal/testsuite"
+)
+
+// defaultQuestionsLimit is the number of questions get_question_results
+// returns if no limit is given.
+const defaultQuestionsLimit = 20
+
+// Verdicts of a question as reported by get_question_results.
+const (
+	verdictCorrect   = "correct"
+	verdictIncorrect = "incorrect"
+	verdictUnstable  = "unstable"
+	verdictUnjudged  = "unjudged"
+)
+
+// getQuestionResultsArgs are the arguments of get_question_results.
+type getQuestionResultsArgs struct {
+	RunID      string `json:"run_id" jsonschema_description:"Run ID whose answers to return, or 'latest' for the last run of this session"`
+	Model      string `json:"model" jsonschema_description:"Name of the model whose answers to return, as listed by get_results"`
+	Section    string `json:"section,omitempty" jsonschema_description:"Only return questions of this section (case-insensitive)"`
+	QuestionID string `json:"question_id,omitempty" jsonschema_description:"Only return the question with this ID"`
+	Verdict    string `json:"verdict,omitempty" jsonschema:"enum=correct,enum=incorrect,enum=unstable,enum=unjudged" jsonschema_description:"Only return questions with this verdict; 'unstable' questions were judged differently between scoring repetitions"`
+	Limit      int    `json:"limit,omitempty" jsonschema:"minimum=1" jsonschema_description:"Maximum number of questions to return (default: 20)"`
+	Offset     int    `json:"offset,omitempty" jsonschema:"minimum=0" jsonschema_description:"Number of matching questions to skip (default: 0)"`
+}
+
+// questionResult is a model's answer to one question of a run.
+type questionResult struct {
+	ID               string   `json:"id"`
+	Section          string   `json:"section"`
+	Difficulty       string   `json:"difficulty,omitempty"`
+	Tags             []string `json:"tags,omitempty"`
+	Question         string   `json:"question"`
+	ExpectedAnswer   string   `json:"expected_answer"`
+	ActualAnswer     string   `json:"actual_answer"`
+	Duration         *float64 `json:"duration,omitempty"`
+	PromptTokens     int      `json:"prompt_tokens,omitempty"`
+	CompletionTokens int      `json:"completion_tokens,omitempty"`
+	FinishReason     string   `json:"finish_reason,omitempty"`
+	Verdict          string   `json:"verdict"`
+	CorrectRuns      int      `json:"correct_runs,omitempty"`
+	JudgedRuns       int      `json:"judged_runs,omitempty"`
+}
+
+// questionVerdict is the verdict on a question across scoring repetitions.
+type questionVerdict struct {
+	correctRuns int
+	judgedRuns  int
+}
+
+// verdict returns the verdict on the question.
+func (v questionVerdict) verdict() string {
+	switch {
+	case v.judgedRuns == 0:
+		return verdictUnjudged
+	case v.correctRuns == v.judgedRuns:
+		return verdictCorrect
+	case v.correctRuns == 0:
+		return verdictIncorrect
+	default:
+		return verdictUnstable
+	}
+}
+
+func handleGetQuestionResults(_ context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	args, err := bindArgs[getQuestionResultsArgs](request)
+	if err != nil {
+		return toolErrorFrom(err, codeInvalidArgument), nil
+	}
+	limit := args.Limit
+	if limit == 0 {
+		limit = defaultQuestionsLimit
+	}
+
+	runPath, err := resolveRunPath(sc.OutputDir, args.RunID)
+	if err != nil {
+		return toolErrorf(codeInvalidArgument, "invalid run_id: %v", err), nil
+	}
+	resultsFile, err := modelResultsFile(args.RunID, runPath, args.Model)
+	if err != nil {
+		return toolErrorFrom(err, codeInvalidArgument), nil
+	}
+
+	questions, err := readQuestionResults(runPath, resultsFile)
+	if err != nil {
+		return toolErrorFrom(err, codeInternal), nil
+	}
+
+	matching := make([]questionResult, 0, len(questions))
+	for _, q := range questions {
+		if args.Section != "" && !strings.EqualFold(q.Section, args.Section) {
+			continue
+		}
+		if args.QuestionID != "" && q.ID != args.QuestionID {
+			continue
+		}
+		if args.Verdict != "" && q.Verdict != args.Verdict {
+			continue
+		}
+		matching = append(matching, q)
+	}
+
+	total := len(matching)
+	page := matching[min(args.Offset, total):min(args.Offset+limit, total)]
+
+	data, err := json.MarshalIndent(map[string]interface{}{
+		"run_id":    args.RunID,
+		"model":     args.Model,
+		"total":     total,
+		"offset":    args.Offset,
+		"limit":     limit,
+		"has_more":  args.Offset+len(page) < total,
+		"questions": page,
+	}, "", "  ")
+	if err != nil {
+		return toolErrorf(codeInternal, "failed to marshal result: %v", err), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// modelResultsFile returns the results file of the named model in a run.
+func modelResultsFile(runID, runPath, model string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(runPath, "resultset.json"))
+	if err != nil {
+		return "", withCode(codeRunNotFound, fmt.Errorf("run %q not found: %v", runID, err))
+	}
+	var run testsuite.TestRun
+	if err := json.Unmarshal(data, &run); err != nil {
+		return "", withCode(codeInternal, fmt.Errorf("failed to parse run metadata: %v", err))
+	}
+
+	models := make([]string, 0, len(run.Models))
+	for _, m := range run.Models {
+		if m.ModelName == model {
+			return joinRunFile(runPath, filepath.Base(m.ResultsFile)), nil
+		}
+		models = append(models, m.ModelName)
+	}
+	return "", withCode(codeNotFound, fmt.Errorf("model %q not found in run %q (models: %s)", model, runID, strings.Join(models, ", ")))
+}
+
+// readQuestionResults joins the answers in a results file with their
+// question records, the answer key of blind runs, and the per-question
+// verdicts of its scores. Scored verdicts take precedence over verdicts
+// given during the run.
+func readQuestionResults(runPath, resultsFile string) ([]questionResult, error) {
+	results, err := scorer.ReadResults(resultsFile)
+	if err != nil {
+		return nil, err
+	}
+	records, err := testsuite.ReadQuestionRecords(resultsFile)
+	if err != nil {
+		return nil, err
+	}
+	answerKey, err := testsuite.ReadAnswerKey(runPath)
+	if err != nil {
+		return nil, err
+	}
+	verdicts, err := readQuestionVerdicts(resultsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	questions := make([]questionResult, 0, len(results))
+	for _, r := range results {
+		q := questionResult{
+			ID:             r.Question.ID,
+			Section:        r.Question.Section,
+			Difficulty:     r.Question.Difficulty,
+			Tags:           r.Question.Tags,
+			Question:       r.Question.QuestionText,
+			ExpectedAnswer: r.Question.ExpectedAnswer,
+			ActualAnswer:   r.Answer,
+		}
+		if q.ExpectedAnswer == "" {
+			q.ExpectedAnswer = answerKey[q.ID]
+		}
+
+		v, scored := verdicts[q.ID]
+		if record, ok := records[q.ID]; ok {
+			q.Duration = &record.Duration
+			q.PromptTokens, q.CompletionTokens = record.PromptTokens, record.CompletionTokens
+			q.FinishReason = record.FinishReason
+			if !scored && record.Verdict != nil {
+				v = questionVerdict{judgedRuns: 1}
+				if *record.Verdict {
+					v.correctRuns = 1
+				}
+			}
+		}
+		q.Verdict = v.verdict()
+		if scored {
+			q.CorrectRuns, q.JudgedRuns = v.correctRuns, v.judgedRuns
+		}
+		questions = append(questions, q)
+	}
+	return questions, nil
+}
+
+// readQuestionVerdicts returns the per-question verdicts of the scores of a
+// results file, keyed by question ID. It returns nil if the file has not
+// been scored in per_question mode.
+func readQuestionVerdicts(resultsFile string) (map[string]questionVerdict, error) {
+	output, err := scorer.ReadScoreFile(resultsFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var verdicts map[string]questionVerdict
+	for _, run := range output.Runs {
+		for _, q := range run.Questions {
+			if q.Correct == nil {
+				continue
+			}
+			if verdicts == nil {
+				verdicts = make(map[string]questionVerdict)
+			}
+			v := verdicts[q.ID]
+			v.judgedRuns++
+			if *q.Correct {
+				v.correctRuns++
+			}
+			verdicts[q.ID] = v
+		}
+	}
+	return verdicts, nil
+}
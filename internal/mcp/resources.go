@@ -0,0 +1,121 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"github.com/giantswarm/llm-testing/internal/server"
+	"github.com/giantswarm/llm-testing/internal/testsuite"
+)
+
+// Resource URI templates of the artifacts clients can read directly.
+const (
+	runFileTemplate   = "results://{run_id}/{file}"
+	suiteFileTemplate = "suites://{name}/{file}"
+)
+
+func registerResources(s *mcpserver.MCPServer, sc *server.ServerContext) {
+	s.AddResourceTemplate(
+		mcp.NewResourceTemplate(runFileTemplate, "Test run file",
+			mcp.WithTemplateDescription(`A file of a test run in the output directory: resultset.json (run metadata), <model>.txt (results), <model>_scores.json (scores), or another artifact such as <model>_deployment.json. Run IDs are listed by get_results.`),
+		),
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return readRunFile(ctx, request, sc)
+		},
+	)
+	s.AddResourceTemplate(
+		mcp.NewResourceTemplate(suiteFileTemplate, "Test suite file",
+			mcp.WithTemplateDescription(`A file of a test suite definition: config.yaml or its questions file (e.g. questions.csv). Suite names are listed by list_test_suites.`),
+		),
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return readSuiteFile(ctx, request, sc)
+		},
+	)
+}
+
+func readRunFile(_ context.Context, request mcp.ReadResourceRequest, sc *server.ServerContext) ([]mcp.ResourceContents, error) {
+	runPath, err := resolveRunPath(sc.OutputDir, resourceArgument(request, "run_id"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid run_id: %w", err)
+	}
+	name, err := resourceFileName(resourceArgument(request, "file"))
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(joinRunFile(runPath, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", request.Params.URI, err)
+	}
+	return []mcp.ResourceContents{mcp.TextResourceContents{
+		URI:      request.Params.URI,
+		MIMEType: mimeType(name),
+		Text:     string(data),
+	}}, nil
+}
+
+func readSuiteFile(_ context.Context, request mcp.ReadResourceRequest, sc *server.ServerContext) ([]mcp.ResourceContents, error) {
+	fsys, err := testsuite.Open(resourceArgument(request, "name"), sc.SuitesDir)
+	if err != nil {
+		return nil, err
+	}
+	name, err := resourceFileName(resourceArgument(request, "file"))
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", request.Params.URI, err)
+	}
+	return []mcp.ResourceContents{mcp.TextResourceContents{
+		URI:      request.Params.URI,
+		MIMEType: mimeType(name),
+		Text:     string(data),
+	}}, nil
+}
+
+// resourceArgument returns a variable of the resource URI template. The
+// server passes the matched values as lists.
+func resourceArgument(request mcp.ReadResourceRequest, name string) string {
+	switch v := request.Params.Arguments[name].(type) {
+	case string:
+		return v
+	case []string:
+		return strings.Join(v, "/")
+	default:
+		return ""
+	}
+}
+
+// resourceFileName validates the name of a file directly in a run or suite
+// directory.
+func resourceFileName(name string) (string, error) {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("invalid file name %q", name)
+	}
+	return name, nil
+}
+
+// mimeType returns the MIME type of a run or suite file from its extension.
+func mimeType(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return "application/json"
+	case ".yaml", ".yml":
+		return "application/yaml"
+	case ".csv":
+		return "text/csv"
+	case ".xml":
+		return "application/xml"
+	default:
+		return "text/plain"
+	}
+}
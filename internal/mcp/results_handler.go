@@ -4,108 +4,425 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 
+	"github.com/giantswarm/llm-testing/internal/runner"
+	"github.com/giantswarm/llm-testing/internal/scorer"
 	"github.com/giantswarm/llm-testing/internal/server"
+	"github.com/giantswarm/llm-testing/internal/testsuite"
 )
 
+// defaultResultsLimit is the number of runs get_results lists if no limit is given.
+const defaultResultsLimit = 20
+
+// runFilter selects and orders the runs listed by get_results.
+type runFilter struct {
+	suite     string
+	model     string
+	since     time.Time
+	until     time.Time
+	hasScores *bool
+	sort      string
+	limit     int
+	offset    int
+	summary   bool
+}
+
+// listedRun is a run found in the output directory.
+type listedRun struct {
+	metadata   map[string]interface{}
+	id         string
+	suite      string
+	suiteHash  string
+	timestamp  time.Time
+	models     []string
+	scoreFiles []string
+}
+
+// getResultsArgs are the arguments of get_results.
+type getResultsArgs struct {
+	RunID     string `json:"run_id,omitempty" jsonschema_description:"Specific run ID to retrieve, or 'latest' for the last run of this session (optional, lists runs if omitted; the filters below are ignored)"`
+	Suite     string `json:"suite,omitempty" jsonschema_description:"Only list runs of this test suite, as recorded in run metadata (e.g. 'Kubernetes CKA')"`
+	Model     string `json:"model,omitempty" jsonschema_description:"Only list runs that tested a model whose name contains this text"`
+	Since     string `json:"since,omitempty" jsonschema_description:"Only list runs started at or after this date (e.g. '2026-02-01') or RFC 3339 timestamp"`
+	Until     string `json:"until,omitempty" jsonschema_description:"Only list runs started at or before this date (inclusive) or RFC 3339 timestamp"`
+	HasScores *bool  `json:"has_scores,omitempty" jsonschema_description:"Only list runs that have (true) or have not (false) been scored"`
+	Sort      string `json:"sort,omitempty" jsonschema:"enum=newest,enum=oldest,enum=suite" jsonschema_description:"Order of the listed runs (default: 'newest')"`
+	Limit     int    `json:"limit,omitempty" jsonschema:"minimum=1,maximum=100" jsonschema_description:"Maximum number of runs to list, at most 100 (default: 20)"`
+	Offset    int    `json:"offset,omitempty" jsonschema:"minimum=0" jsonschema_description:"Number of matching runs to skip (default: 0)"`
+	Summary   bool   `json:"summary,omitempty" jsonschema_description:"List only the ID, suite, timestamp, model names, and score files of each run, without per-model detail (default: false)"`
+	Verbosity string `json:"verbosity,omitempty" jsonschema:"enum=summary,enum=normal,enum=full" jsonschema_description:"Detail of a run: 'summary' returns its models and headline scores, 'normal' its metadata and scores, and 'full' also embeds its files; the files are linked as results:// resources otherwise. Listing runs, 'summary' implies summary: true (default: normal)"`
+}
+
 func handleGetResults(_ context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
-	args := request.GetArguments()
-	runID, _ := args["run_id"].(string)
+	args, err := bindArgs[getResultsArgs](request)
+	if err != nil {
+		return toolErrorFrom(err, codeInvalidArgument), nil
+	}
 
-	if runID != "" {
-		runPath, err := resolveRunPath(sc.OutputDir, runID)
+	if args.RunID != "" {
+		runPath, err := resolveRunPath(sc.OutputDir, args.RunID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("invalid run_id: %v", err)), nil
+			return toolErrorf(codeInvalidArgument, "invalid run_id: %v", err), nil
 		}
-		return getSpecificRun(runID, runPath)
+		return getSpecificRun(args.RunID, runPath, args.Verbosity, sc.OutputDir)
 	}
-	return listRuns(sc.OutputDir)
-}
 
-func listRuns(outputDir string) (*mcp.CallToolResult, error) {
-	entries, err := os.ReadDir(outputDir)
+	filter, err := newRunFilter(args)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return mcp.NewToolResultText("[]"), nil
+		return toolErrorFrom(err, codeInvalidArgument), nil
+	}
+	return listRuns(sc.OutputDir, filter)
+}
+
+func newRunFilter(args getResultsArgs) (runFilter, error) {
+	filter := runFilter{
+		suite:     args.Suite,
+		model:     args.Model,
+		hasScores: args.HasScores,
+		sort:      args.Sort,
+		limit:     args.Limit,
+		offset:    args.Offset,
+		summary:   args.Summary || args.Verbosity == verbositySummary,
+	}
+	if filter.sort == "" {
+		filter.sort = "newest"
+	}
+	if filter.limit == 0 {
+		filter.limit = defaultResultsLimit
+	}
+
+	var err error
+	if args.Since != "" {
+		if filter.since, _, err = parseDate(args.Since); err != nil {
+			return filter, fmt.Errorf("invalid since: %w", err)
+		}
+	}
+	if args.Until != "" {
+		var dateOnly bool
+		if filter.until, dateOnly, err = parseDate(args.Until); err != nil {
+			return filter, fmt.Errorf("invalid until: %w", err)
 		}
-		return mcp.NewToolResultError(fmt.Sprintf("failed to read results directory: %v", err)), nil
+		// A date includes the whole day.
+		if dateOnly {
+			filter.until = filter.until.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+	}
+	return filter, nil
+}
+
+// parseDate parses a date (2006-01-02) or an RFC 3339 timestamp, and reports
+// whether it was a date.
+func parseDate(s string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, true, nil
 	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("%q is neither a date (e.g. '2026-02-10') nor an RFC 3339 timestamp", s)
+	}
+	return t, false, nil
+}
 
-	runs := make([]map[string]interface{}, 0)
+func (f runFilter) matches(run listedRun) bool {
+	if f.suite != "" && !strings.EqualFold(run.suite, f.suite) {
+		return false
+	}
+	if f.model != "" && !slices.ContainsFunc(run.models, func(m string) bool {
+		return strings.Contains(strings.ToLower(m), strings.ToLower(f.model))
+	}) {
+		return false
+	}
+	if !f.since.IsZero() && run.timestamp.Before(f.since) {
+		return false
+	}
+	if !f.until.IsZero() && run.timestamp.After(f.until) {
+		return false
+	}
+	if f.hasScores != nil && (len(run.scoreFiles) > 0) != *f.hasScores {
+		return false
+	}
+	return true
+}
+
+func listRuns(outputDir string, filter runFilter) (*mcp.CallToolResult, error) {
+	entries, err := os.ReadDir(outputDir)
+	if err != nil && !os.IsNotExist(err) {
+		return toolErrorFromf(err, codeInternal, "failed to read results directory: %v", err), nil
+	}
+
+	var runs []listedRun
 	for _, e := range entries {
 		if !e.IsDir() {
 			continue
 		}
-
-		metadataPath := filepath.Join(outputDir, e.Name(), "resultset.json")
-		data, err := os.ReadFile(metadataPath)
-		if err != nil {
-			continue
+		run, ok := readListedRun(filepath.Join(outputDir, e.Name()))
+		if ok && filter.matches(run) {
+			runs = append(runs, run)
 		}
+	}
 
-		var metadata map[string]interface{}
-		if err := json.Unmarshal(data, &metadata); err != nil {
-			continue
+	slices.SortStableFunc(runs, func(a, b listedRun) int {
+		switch filter.sort {
+		case "oldest":
+			return a.timestamp.Compare(b.timestamp)
+		case "suite":
+			if c := strings.Compare(a.suite, b.suite); c != 0 {
+				return c
+			}
 		}
+		return b.timestamp.Compare(a.timestamp)
+	})
 
-		// Check for score files.
-		files, _ := os.ReadDir(filepath.Join(outputDir, e.Name()))
-		var scoreFiles []string
-		for _, f := range files {
-			if strings.HasSuffix(f.Name(), "_scores.json") {
-				scoreFiles = append(scoreFiles, f.Name())
-			}
+	total := len(runs)
+	start := min(filter.offset, total)
+	page := runs[start : start+min(filter.limit, total-start)]
+	listed := make([]map[string]interface{}, 0, len(page))
+	for _, run := range page {
+		if filter.summary {
+			listed = append(listed, run.summary())
+		} else {
+			listed = append(listed, run.metadata)
 		}
-		metadata["score_files"] = scoreFiles
-		runs = append(runs, metadata)
 	}
 
-	data, err := json.MarshalIndent(runs, "", "  ")
+	listing := map[string]interface{}{
+		"total":    total,
+		"offset":   filter.offset,
+		"limit":    filter.limit,
+		"has_more": start+len(page) < total,
+		"runs":     listed,
+	}
+	if warnings := suiteChangeWarnings(runs); len(warnings) > 0 {
+		listing["warnings"] = warnings
+	}
+	data, err := json.MarshalIndent(listing, "", "  ")
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal runs: %v", err)), nil
+		return toolErrorf(codeInternal, "failed to marshal runs: %v", err), nil
 	}
 	return mcp.NewToolResultText(string(data)), nil
 }
 
-func getSpecificRun(runID, runPath string) (*mcp.CallToolResult, error) {
+// suiteChangeWarnings warns about the suites whose listed runs were executed
+// against different suite content, so that their scores are not compared as
+// if only the models differed.
+func suiteChangeWarnings(runs []listedRun) []string {
+	hashes := map[string]map[string]bool{}
+	for _, run := range runs {
+		if run.suiteHash == "" {
+			continue
+		}
+		if hashes[run.suite] == nil {
+			hashes[run.suite] = map[string]bool{}
+		}
+		hashes[run.suite][run.suiteHash] = true
+	}
+	var warnings []string
+	for _, suite := range slices.Sorted(maps.Keys(hashes)) {
+		if n := len(hashes[suite]); n > 1 {
+			warnings = append(warnings, fmt.Sprintf("runs of suite %q were executed against %d different versions of its content (see suite_hash); their scores are not directly comparable", suite, n))
+		}
+	}
+	return warnings
+}
+
+// readListedRun reads the metadata of the run in runDir. It reports false if
+// runDir is not a completed run.
+func readListedRun(runDir string) (listedRun, bool) {
+	data, err := os.ReadFile(filepath.Join(runDir, "resultset.json"))
+	if err != nil {
+		return listedRun{}, false
+	}
+
+	var run listedRun
+	if err := json.Unmarshal(data, &run.metadata); err != nil {
+		return listedRun{}, false
+	}
+	var testRun testsuite.TestRun
+	if err := json.Unmarshal(data, &testRun); err != nil {
+		return listedRun{}, false
+	}
+	run.id, run.suite, run.suiteHash, run.timestamp = testRun.ID, testRun.Suite, testRun.SuiteHash, testRun.Timestamp
+	if run.id == "" {
+		run.id = filepath.Base(runDir)
+	}
+	for _, m := range testRun.Models {
+		run.models = append(run.models, m.ModelName)
+	}
+
+	// Check for score files.
+	files, _ := os.ReadDir(runDir)
+	for _, f := range files {
+		if strings.HasSuffix(f.Name(), "_scores.json") {
+			run.scoreFiles = append(run.scoreFiles, f.Name())
+		}
+	}
+	run.metadata["score_files"] = run.scoreFiles
+	return run, true
+}
+
+// summary returns the run without per-model detail.
+func (r listedRun) summary() map[string]interface{} {
+	summary := map[string]interface{}{
+		"id":          r.id,
+		"suite":       r.suite,
+		"timestamp":   r.timestamp,
+		"models":      r.models,
+		"score_files": r.scoreFiles,
+	}
+	for _, key := range []string{"suite_version", "suite_hash", "cancelled"} {
+		if v, ok := r.metadata[key]; ok {
+			summary[key] = v
+		}
+	}
+	return summary
+}
+
+// getSpecificRun returns the metadata and scores of a run, and links to or
+// embeds its files depending on verbosity.
+func getSpecificRun(runID, runPath, verbosity, outputDir string) (*mcp.CallToolResult, error) {
+	verbosity, err := validateVerbosity(verbosity)
+	if err != nil {
+		return toolErrorFrom(err, codeInvalidArgument), nil
+	}
 	metadataPath := filepath.Join(runPath, "resultset.json")
 
 	data, err := os.ReadFile(metadataPath)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("run %q not found: %v", runID, err)), nil
+		return toolErrorFromf(runNotFound(err), codeInternal, "run %q not found: %v", runID, err), nil
 	}
 
 	var metadata map[string]interface{}
 	if err := json.Unmarshal(data, &metadata); err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to parse run metadata: %v", err)), nil
+		return toolErrorFromf(err, codeInternal, "failed to parse run metadata: %v", err), nil
 	}
 
 	// Include score data if available.
-	files, _ := os.ReadDir(runPath)
+	entries, _ := os.ReadDir(runPath)
 	scores := make(map[string]interface{})
-	for _, f := range files {
-		if strings.HasSuffix(f.Name(), "_scores.json") {
-			scoreData, err := os.ReadFile(filepath.Join(runPath, f.Name()))
-			if err == nil {
-				var scoreObj interface{}
-				if json.Unmarshal(scoreData, &scoreObj) == nil {
-					scores[f.Name()] = scoreObj
+	runFiles := []string{metadataPath}
+	var resultFiles []string
+	for _, f := range entries {
+		name := f.Name()
+		switch {
+		case f.IsDir():
+		case strings.HasSuffix(name, "_scores.json"):
+			runFiles = append(runFiles, filepath.Join(runPath, name))
+			scoreData, err := os.ReadFile(filepath.Join(runPath, name))
+			if err != nil {
+				continue
+			}
+			if verbosity == verbositySummary {
+				var output scorer.ScoreOutput
+				if json.Unmarshal(scoreData, &output) == nil {
+					scores[name] = compactSummary(output.Summary)
 				}
+				continue
+			}
+			var scoreObj interface{}
+			if json.Unmarshal(scoreData, &scoreObj) == nil {
+				scores[name] = scoreObj
 			}
+		case strings.HasSuffix(name, ".txt"):
+			resultFiles = append(resultFiles, filepath.Join(runPath, name))
 		}
 	}
+	if verbosity == verbositySummary {
+		metadata = runSummary(runID, data)
+	}
 	if len(scores) > 0 {
 		metadata["scores"] = scores
 	}
 
 	result, err := json.MarshalIndent(metadata, "", "  ")
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err)), nil
+		return toolErrorf(codeInternal, "failed to marshal result: %v", err), nil
+	}
+	return runFilesResult(string(result), verbosity, outputDir, append(runFiles, resultFiles...)...), nil
+}
+
+// runSummary returns the ID, suite, timestamp, and models of the run with
+// the given resultset.json data.
+func runSummary(runID string, data []byte) map[string]interface{} {
+	var testRun testsuite.TestRun
+	_ = json.Unmarshal(data, &testRun)
+	models := make([]string, 0, len(testRun.Models))
+	for _, m := range testRun.Models {
+		models = append(models, m.ModelName)
+	}
+	summary := map[string]interface{}{
+		"id":        runID,
+		"suite":     testRun.Suite,
+		"timestamp": testRun.Timestamp,
+		"models":    models,
 	}
-	return mcp.NewToolResultText(string(result)), nil
+	if testRun.Cancelled {
+		summary["cancelled"] = true
+	}
+	return summary
+}
+
+// deleteResultsArgs are the arguments of delete_results.
+type deleteResultsArgs struct {
+	RunID     string `json:"run_id,omitempty" jsonschema_description:"Run ID to delete"`
+	OlderThan string `json:"older_than,omitempty" jsonschema_description:"Delete runs older than this age, in days (e.g. '30d') or as a duration (e.g. '12h')"`
+	DryRun    bool   `json:"dry_run,omitempty" jsonschema_description:"Only list the runs that would be deleted (default: false)"`
+}
+
+func handleDeleteResults(_ context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	args, err := bindArgs[deleteResultsArgs](request)
+	if err != nil {
+		return toolErrorFrom(err, codeInvalidArgument), nil
+	}
+	runID, olderThan, dryRun := args.RunID, args.OlderThan, args.DryRun
+
+	if (runID == "") == (olderThan == "") {
+		return toolError(codeInvalidArgument, "provide exactly one of run_id or older_than"), nil
+	}
+
+	var deleted []runner.DeletedRun
+	if runID != "" {
+		runPath, err := resolveRunPath(sc.OutputDir, runID)
+		if err != nil {
+			return toolErrorf(codeInvalidArgument, "invalid run_id: %v", err), nil
+		}
+		if sc.Jobs != nil {
+			if job, err := sc.Jobs.Get(runID); err == nil && job.Status().FinishedAt == nil {
+				return toolErrorf(codeConflict, "run %s is still running; cancel it with cancel_run first", runID), nil
+			}
+		}
+		run, err := runner.DeleteRun(runPath, dryRun)
+		if err != nil {
+			return toolErrorFrom(runNotFound(err), codeInternal), nil
+		}
+		deleted = append(deleted, run)
+	} else {
+		age, err := runner.ParseAge(olderThan)
+		if err != nil {
+			return toolErrorFrom(err, codeInvalidArgument), nil
+		}
+		deleted, err = runner.DeleteRunsOlderThan(sc.OutputDir, age, dryRun)
+		if err != nil {
+			return toolErrorFromf(err, codeInternal, "failed to delete runs: %v", err), nil
+		}
+	}
+	if deleted == nil {
+		deleted = []runner.DeletedRun{}
+	}
+
+	data, err := json.MarshalIndent(map[string]interface{}{
+		"deleted": deleted,
+		"dry_run": dryRun,
+	}, "", "  ")
+	if err != nil {
+		return toolErrorf(codeInternal, "failed to marshal result: %v", err), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
 }
@@ -6,85 +6,375 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+	"time"
 
+	"github.com/invopop/jsonschema"
 	"github.com/mark3labs/mcp-go/mcp"
 
+	"github.com/giantswarm/llm-testing/internal/jobs"
 	"github.com/giantswarm/llm-testing/internal/kserve"
 	"github.com/giantswarm/llm-testing/internal/llm"
 	"github.com/giantswarm/llm-testing/internal/runner"
+	"github.com/giantswarm/llm-testing/internal/scorer"
 	"github.com/giantswarm/llm-testing/internal/server"
 	"github.com/giantswarm/llm-testing/internal/testsuite"
+	"github.com/giantswarm/llm-testing/internal/webhook"
 )
 
+// teardownTimeout bounds the teardown of a model after its test run.
+const teardownTimeout = 2 * time.Minute
+
+// suiteArgs are the arguments choosing the test suite of a run and how its
+// questions are asked, shared by run_test_suite, evaluate_model, and
+// compare_revisions.
+type suiteArgs struct {
+	TestSuite string `json:"test_suite" jsonschema_description:"Name of the test suite to run (e.g. 'kubernetes-cka-v2')"`
+	Tags      string `json:"tags,omitempty" jsonschema_description:"Comma-separated tags: only run questions having any of them (e.g. 'networking,storage')"`
+	Profile   string `json:"profile,omitempty" jsonschema_description:"Prompt profile of the suite to run with (e.g. 'concise'; see list_test_suites). Default: the suite's prompt"`
+	Examples  *bool  `json:"examples,omitempty" jsonschema_description:"Send the suite's few-shot examples before each question (default: true)"`
+	Blind     bool   `json:"blind,omitempty" jsonschema_description:"Keep expected answers out of the results files, in a separate answer key joined locally when scoring with mode 'per_question' (default: false, or the suite's 'blind' setting)"`
+}
+
+// runTestSuiteArgs are the arguments of run_test_suite.
+type runTestSuiteArgs struct {
+	suiteArgs
+	Model           string   `json:"model,omitempty" jsonschema_description:"Single model name, or model alias of the server (see list_model_aliases), to test. For multiple models, use the 'models' parameter instead."`
+	Models          string   `json:"models,omitempty"`
+	Endpoint        string   `json:"endpoint,omitempty" jsonschema_description:"LLM endpoint URL (overrides KServe auto-discovery). Use when models are served externally."`
+	Temperature     *float64 `json:"temperature,omitempty" jsonschema:"minimum=0" jsonschema_description:"Temperature for generation when using single 'model' param (default: 0.0)"`
+	MaxRetries      int      `json:"max_retries,omitempty" jsonschema:"minimum=0" jsonschema_description:"Retry budget for timeouts and 5xx errors when using single 'model' param (default: 0)"`
+	ReasoningEffort string   `json:"reasoning_effort,omitempty" jsonschema:"enum=low,enum=medium,enum=high" jsonschema_description:"Reasoning effort for reasoning models when using single 'model' param: low, medium, or high (default: server default)"`
+	ExtraParams     string   `json:"extra_params,omitempty" jsonschema_description:"JSON object of fields added to every request payload when using single 'model' param, e.g. {\"top_k\":20}"`
+	Deploy          *bool    `json:"deploy,omitempty" jsonschema_description:"Whether to auto-deploy models with model_uri via KServe (default: true)"`
+	Judge           bool     `json:"judge,omitempty" jsonschema_description:"Judge each answer immediately after it is produced and report running accuracy (default: false)"`
+	ScoringModel    string   `json:"scoring_model,omitempty" jsonschema_description:"Model to use for judging when 'judge' is enabled (default: server scoring model)"`
+	Async           bool     `json:"async,omitempty" jsonschema_description:"Return the run_id immediately and run in the background instead of waiting for the run (default: false)"`
+}
+
+// JSONSchemaExtend completes the schema with the description of models,
+// which lists the fields of a model config.
+func (runTestSuiteArgs) JSONSchemaExtend(s *jsonschema.Schema) {
+	describeArg(s, "models", `JSON array of model configs. Each model can include:
+- "name" (required): model identifier, or a model alias of the server (see list_model_aliases), whose settings the other fields override
+- "temperature": generation temperature (default: 0.0)
+- "provider": provider the model is reached through, "openai" (default, OpenAI-compatible), "anthropic", "gemini", "vertexai", "azure", or a name from the server's provider registry (never deployed)
+- "model_uri": KServe storage URI for auto-deploy (e.g. "hf://org/model", "pvc://models/org/model", "s3://bucket/org/model")
+- "gpu_count": GPUs to request when deploying (default: estimated from the model size for hf:// URIs, otherwise 1)
+- "backend": model server, "vllm" (default), or "ollama" or "llamacpp" to serve a GGUF model on CPUs
+- "gguf_file": GGUF file of the hf:// repository that ollama or llamacpp serves (default: the best quantization, e.g. Q4_K_M)
+- "runtime": KServe serving runtime to deploy with (default: kserve-vllm; see list_runtimes)
+- "cpu", "cpu_limit", "memory", "memory_limit": predictor resources as Kubernetes quantities (e.g. "8", "64Gi")
+- "shm_size": size of the /dev/shm volume (default: 2Gi)
+- "hf_token_secret", "hf_token_secret_key": Secret (and key, default HF_TOKEN) with a HuggingFace token for gated models
+- "service_account", "image_pull_secrets": predictor service account and image pull Secret names
+- "storage_key": entry of KServe's storage-config Secret with S3 credentials, for s3:// URIs
+- "runtime_args": additional arguments for the serving runtime (e.g. ["--max-model-len=4096"])
+- "quantization": vLLM quantization preset ("awq", "gptq", "fp8", or "bitsandbytes") instead of hand-written runtime_args
+- "args_preset": curated vLLM runtime args ("long-context", "throughput", or "low-latency"); runtime_args take precedence
+- "env": environment variables for the model container (e.g. {"VLLM_ATTENTION_BACKEND":"FLASHINFER"})
+- "raw_deployment": deploy in KServe's RawDeployment mode instead of as a Knative service (default: false)
+- "node_selector", "tolerations", "affinity", "runtime_class_name": scheduling constraints for the deployed predictor pod (Kubernetes pod spec format)
+- "tensor_parallel_size", "pipeline_parallel_size", "worker_count": GPUs per node and nodes for models that don't fit a single node (multi-node KServe workerSpec)
+- "min_replicas", "max_replicas", "scale_target", "scale_metric": predictor autoscaling; min_replicas 0 scales an idle deployment to zero
+- "namespace": namespace to deploy the InferenceService in (default: the server's namespace)
+- "recreate": delete and recreate an existing InferenceService of the same name instead of updating it (default: false)
+- "probe_completion": request a one-token completion before testing a deployed model, not only GET /v1/models (default: false)
+- "max_retries": retry budget for timeouts and 5xx errors across the run (default: 0)
+- "reasoning_effort": thinking budget of reasoning models, "low", "medium", or "high" (default: server default)
+- "extra_params": fields added to every request payload, e.g. {"chat_template_kwargs":{"enable_thinking":true}} for DeepSeek-R1 on vLLM
+
+Example: [{"name":"mistral-7b","model_uri":"hf://mistralai/Mistral-7B-Instruct-v0.3","gpu_count":1}]`)
+}
+
 func handleRunTestSuite(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
-	args := request.GetArguments()
+	args, err := bindArgs[runTestSuiteArgs](request)
+	if err != nil {
+		return toolErrorFrom(err, codeInvalidArgument), nil
+	}
+	return runTestSuite(ctx, request, sc, args, runStages{})
+}
+
+// runFollowUp is work done in the job of a run after the run completed,
+// e.g. scoring its results. It may add to the summary of the run.
+type runFollowUp func(ctx context.Context, summary map[string]interface{}, notify progressNotifier) error
+
+// runStages is work done in the job of a run around the run itself, so that
+// it waits in the run queue with the run. All stages are optional.
+type runStages struct {
+	// before precedes the run, e.g. deploying the models; if it fails, the
+	// run fails without being started.
+	before func(ctx context.Context, notify progressNotifier) error
+
+	// then follows the run unless it was cancelled.
+	then runFollowUp
+
+	// after runs last, also when the other stages failed, e.g. to tear
+	// down what before deployed.
+	after func(ctx context.Context)
+}
+
+// resumable reports whether a queued run with the stages can be resumed
+// from its arguments, by another replica: only runs without stages can.
+func (s runStages) resumable() bool {
+	return s.before == nil && s.then == nil && s.after == nil
+}
+
+// runTestSuite runs a test suite as configured by the arguments of
+// run_test_suite, in the job of the run with stages around it. Progress is
+// reported for request.
+func runTestSuite(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext, args runTestSuiteArgs, stages runStages) (*mcp.CallToolResult, error) {
+	// Progress is sent as MCP notifications to the caller, who does not
+	// wait for async runs and follows them with get_run_status instead.
+	async := args.Async
+	notify := newProgressNotifier(ctx, request)
+	if async {
+		notify = func(float64, float64, string) {}
+	}
+	run, err := prepareRun(sc, args, notify, stages)
+	if err != nil {
+		return toolErrorFrom(err, codeInvalidArgument), nil
+	}
+	suite, progress := run.suite, run.progress
+
+	// Runs are jobs of the server's manager, so that cancel_run can stop
+	// them. Without one, e.g. in tests, a run is only cancelled with the call.
+	manager := sc.Jobs
+	if manager == nil {
+		if async {
+			return toolError(codeNotConfigured, "async runs are not enabled on this server"), nil
+		}
+		manager = jobs.NewManager(ctx)
+	}
+	// Queued async runs keep their arguments in the job store, so that
+	// another replica can resume them; stages cannot be resumed.
+	var spec json.RawMessage
+	if async && stages.resumable() {
+		if spec, err = json.Marshal(args); err != nil {
+			return toolErrorf(codeInternal, "failed to marshal arguments: %v", err), nil
+		}
+	}
+	runID := runner.NewRunID(suite.Name, time.Now())
+	job, err := manager.StartResumable(runID, spec, run.work(runID))
+	if err != nil {
+		return toolErrorFrom(err, codeInternal), nil
+	}
+
+	var response any
+	if status := job.Status(); async {
+		message := "The run continues in the background. Use get_run_status to follow it " +
+			"and cancel_run to stop it."
+		if status.State == jobs.StateQueued {
+			message = fmt.Sprintf("The run is queued at position %d and starts when a run slot is free. "+
+				"Use get_run_status to follow it and cancel_run to stop it.", status.QueuePosition)
+		}
+		response = map[string]interface{}{
+			"run_id":         runID,
+			"suite":          suite.Name,
+			"state":          status.State,
+			"queue_position": status.QueuePosition,
+			"message":        message,
+		}
+	} else {
+		if status.State == jobs.StateQueued {
+			progress.queued(status.QueuePosition)
+		}
+		select {
+		case <-job.Done():
+		case <-ctx.Done():
+			_, _ = manager.Cancel(runID)
+			<-job.Done()
+		}
+		status := job.Status()
+		if status.Result == nil {
+			if status.State == jobs.StateCancelled {
+				return toolError(codeCancelled, status.Error), nil
+			}
+			return toolErrorFrom(job.Err(), codeRunFailed), nil
+		}
+		response = status.Result
+	}
 
-	suiteName, ok := args["test_suite"].(string)
-	if !ok || suiteName == "" {
-		return mcp.NewToolResultError("test_suite is required"), nil
+	data, err := json.MarshalIndent(response, "", "  ")
+	if err != nil {
+		return toolErrorf(codeInternal, "failed to marshal summary: %v", err), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// ResumeRun resumes the queued async runs of run_test_suite that the
+// server's job manager takes over from a stopped replica.
+func ResumeRun(sc *server.ServerContext) jobs.ResumeFunc {
+	return func(id string, spec json.RawMessage) (jobs.Func, error) {
+		var raw map[string]interface{}
+		if err := json.Unmarshal(spec, &raw); err != nil {
+			return nil, fmt.Errorf("invalid run arguments: %w", err)
+		}
+		args, err := bindArgMap[runTestSuiteArgs](raw)
+		if err != nil {
+			return nil, err
+		}
+		run, err := prepareRun(sc, args, func(float64, float64, string) {}, runStages{})
+		if err != nil {
+			return nil, err
+		}
+		return run.work(id), nil
 	}
+}
 
-	suite, err := testsuite.Load(suiteName, sc.SuitesDir)
+// preparedRun is a test run configured by the arguments of run_test_suite.
+type preparedRun struct {
+	suite    *testsuite.TestSuite
+	progress *runProgress
+
+	// work returns the work of the run's job.
+	work func(runID string) jobs.Func
+}
+
+// prepareRun configures a run from the arguments of run_test_suite. The
+// progress of the run is sent to notify, and the stages are done around it.
+// The errors are meant for the caller.
+func prepareRun(sc *server.ServerContext, args runTestSuiteArgs, notify progressNotifier, stages runStages) (*preparedRun, error) {
+	suite, err := testsuite.Load(args.TestSuite, sc.SuitesDir)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to load test suite: %v", err)), nil
+		return nil, fmt.Errorf("failed to load test suite: %w", err)
+	}
+	if err := suite.SelectTags(testsuite.ParseTags(args.Tags)); err != nil {
+		return nil, err
+	}
+	if err := suite.SelectProfile(args.Profile); err != nil {
+		return nil, err
+	}
+	if args.Examples != nil && !*args.Examples {
+		suite.Examples = nil
+	}
+	if args.Blind {
+		suite.Blind = true
 	}
 
 	// Parse models from parameters (required).
-	models, err := parseModels(args)
+	models, err := parseModels(args, sc.ModelAliases)
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return nil, err
 	}
 
 	if len(models) == 0 {
-		return mcp.NewToolResultError("at least one model is required: use 'models' (JSON array) or 'model' (single name)"), nil
+		return nil, fmt.Errorf("at least one model is required: use 'models' (JSON array) or 'model' (single name)")
+	}
+	for _, model := range models {
+		if _, ok := sc.Providers.Lookup(model.Provider); model.Provider != "" && !ok {
+			return nil, fmt.Errorf("model %q: unknown provider %q", model.Name, model.Provider)
+		}
 	}
 	if sc.LLMClient == nil {
-		return mcp.NewToolResultError("LLM client is not configured"), nil
+		return nil, withCode(codeNotConfigured, fmt.Errorf("LLM client is not configured"))
 	}
 
-	deployEnabled := true
-	if deploy, ok := args["deploy"].(bool); ok {
-		deployEnabled = deploy
-	}
+	deployEnabled := args.Deploy == nil || *args.Deploy
 
 	strategy, err := runner.GetStrategy(suite.Strategy)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("unsupported strategy: %v", err)), nil
+		return nil, fmt.Errorf("unsupported strategy: %v", err)
 	}
 
+	progress := newRunProgress(notify, len(suite.Questions), models)
+
 	r := runner.NewRunner(sc.LLMClient, strategy, sc.OutputDir)
+	r.SetTranscripts(sc.DebugLLM)
+	records := map[string]*kserve.DeploymentRecord{}
+	r.SetClientForModelFunc(func(ctx context.Context, model testsuite.Model) (llm.Client, error) {
+		client, scope, err := clientForModel(ctx, sc, model, args.Endpoint, deployEnabled, records, progress.deploy)
+		if err != nil {
+			return nil, err
+		}
+		client = llm.NewCircuitBreaker(sc.CircuitBreakerFailures, sc.CircuitBreakerCooldown).Wrap(client)
+		return sc.LLMCache.Wrap(client, scope), nil
+	})
 
 	// When KServe is available and models have model_uri, set up the
 	// deploy -> test -> teardown lifecycle. Models are processed sequentially
 	// to respect GPU memory constraints.
 	if sc.KServeManager != nil {
-		r.SetClientForModelFunc(func(ctx context.Context, model testsuite.Model) (llm.Client, error) {
-			return clientForModel(ctx, sc, model, args, deployEnabled)
+		r.SetDeploymentFunc(func(_ context.Context, model testsuite.Model) interface{} {
+			if record, ok := records[model.Name]; ok {
+				return record
+			}
+			return nil
 		})
 		r.SetAfterModelFunc(func(ctx context.Context, model testsuite.Model) error {
 			return teardownModel(ctx, sc, model, deployEnabled)
 		})
-	} else {
-		// No KServe: use explicit endpoint if provided, otherwise default client.
-		if endpoint, ok := args["endpoint"].(string); ok && endpoint != "" {
-			r = runner.NewRunner(newEndpointClient(endpoint, sc.LLMAPIKey), strategy, sc.OutputDir)
+	}
+
+	judgeEnabled := args.Judge
+	if judgeEnabled {
+		// Answers are judged by the default (scoring) client, not the model under test.
+		cfg := scorer.Config{Model: sc.ScoringModel, Repetitions: 1}
+		if model := args.ScoringModel; model != "" {
+			name, client, err := aliasedModel(sc, model)
+			if err != nil {
+				return nil, err
+			}
+			cfg.Model = name
+			if client != nil {
+				cfg.Clients = map[string]llm.Client{name: client}
+			}
 		}
+		judge := scorer.NewScorer(sc.LLMClient, cfg)
+		r.SetJudgeFunc(judge.JudgeResult)
+	}
+
+	return &preparedRun{
+		suite:    suite,
+		progress: progress,
+		work: func(runID string) jobs.Func {
+			r.SetRunID(runID)
+			return func(ctx context.Context, job *jobs.Job) (any, error) {
+				if stages.after != nil {
+					defer stages.after(ctx)
+				}
+				if stages.before != nil {
+					if err := stages.before(ctx, notify); err != nil {
+						return nil, err
+					}
+				}
+				summary, err := executeRun(ctx, sc, r, suite, models, deployEnabled, judgeEnabled, progress, job)
+				if err != nil {
+					return nil, err
+				}
+				if cancelled, _ := summary["cancelled"].(bool); stages.then != nil && !cancelled {
+					if err := stages.then(ctx, summary, notify); err != nil {
+						return summary, err
+					}
+				}
+				return summary, nil
+			}
+		},
+	}, nil
+}
+
+// executeRun runs the suite and returns the summary of the run. The progress
+// is reported to progress and, if the run is a background job, on job.
+func executeRun(ctx context.Context, sc *server.ServerContext, r *runner.Runner, suite *testsuite.TestSuite, models []testsuite.Model, deployEnabled, judgeEnabled bool, progress *runProgress, job *jobs.Job) (map[string]interface{}, error) {
+	if sc.KServeManager != nil {
+		defer teardownLeftovers(ctx, sc, models, deployEnabled)
 	}
 
-	progressEvents := make([]map[string]interface{}, 0)
 	r.SetProgressFunc(func(model string, questionIndex, totalQuestions int) {
-		if questionIndex == 1 || questionIndex == totalQuestions || questionIndex%10 == 0 {
-			progressEvents = append(progressEvents, map[string]interface{}{
-				"model":               model,
-				"completed_questions": questionIndex,
-				"total_questions":     totalQuestions,
-			})
+		progress.question(model, questionIndex, totalQuestions)
+		if job != nil {
+			job.ReportProgress(model, questionIndex, totalQuestions)
+		}
+	})
+	r.SetScoreProgressFunc(func(model string, score testsuite.LiveScore) {
+		progress.score(model, score)
+		if job != nil {
+			job.ReportScore(model, score)
 		}
 	})
 
 	run, err := r.Run(ctx, suite, models)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("test run failed: %v", err)), nil
+		return nil, fmt.Errorf("test run failed: %w", err)
 	}
 
 	// Return summary.
@@ -94,31 +384,32 @@ func handleRunTestSuite(ctx context.Context, request mcp.CallToolRequest, sc *se
 			"model":        m.ModelName,
 			"results_file": m.ResultsFile,
 			"duration":     m.Duration.String(),
+			"errors":       m.Errors,
+			"retries":      m.Retries,
+			"live_score":   m.LiveScore,
+			"usage":        m.Usage,
 		})
 	}
 
 	summary := map[string]interface{}{
-		"run_id":           run.ID,
-		"suite":            run.Suite,
-		"duration":         run.Duration.String(),
-		"models":           modelResults,
-		"deploy_enabled":   deployEnabled,
-		"progress_updates": progressEvents,
+		"run_id":         run.ID,
+		"suite":          run.Suite,
+		"duration":       run.Duration.String(),
+		"models":         modelResults,
+		"deploy_enabled": deployEnabled,
+		"judge_enabled":  judgeEnabled,
+		"cancelled":      run.Cancelled,
 	}
-
-	data, err := json.MarshalIndent(summary, "", "  ")
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal summary: %v", err)), nil
-	}
-	return mcp.NewToolResultText(string(data)), nil
+	return summary, nil
 }
 
-// parseModels extracts the model list from MCP tool arguments.
-func parseModels(args map[string]interface{}) ([]testsuite.Model, error) {
+// parseModels extracts the model list from the arguments of run_test_suite.
+// Models named by an alias get its settings, unless the arguments set them.
+func parseModels(args runTestSuiteArgs, aliases testsuite.ModelAliases) ([]testsuite.Model, error) {
 	// Multi-model JSON array.
-	if modelsJSON, ok := args["models"].(string); ok && modelsJSON != "" {
-		var models []testsuite.Model
-		if err := json.Unmarshal([]byte(modelsJSON), &models); err != nil {
+	if modelsJSON := args.Models; modelsJSON != "" {
+		models, err := aliases.DecodeModels([]byte(modelsJSON))
+		if err != nil {
 			return nil, fmt.Errorf("invalid models JSON: %v", err)
 		}
 		if err := validateModels(models); err != nil {
@@ -128,12 +419,26 @@ func parseModels(args map[string]interface{}) ([]testsuite.Model, error) {
 	}
 
 	// Single model shorthand.
-	if modelName, ok := args["model"].(string); ok && modelName != "" {
-		temp := 0.0
-		if t, ok := args["temperature"].(float64); ok {
-			temp = t
+	if modelName := args.Model; modelName != "" {
+		model, aliased := aliases.Lookup(modelName)
+		if !aliased {
+			model.Name = modelName
 		}
-		models := []testsuite.Model{{Name: modelName, Temperature: temp}}
+		if args.Temperature != nil {
+			model.Temperature = *args.Temperature
+		}
+		if args.MaxRetries > 0 {
+			model.MaxRetries = args.MaxRetries
+		}
+		if args.ReasoningEffort != "" {
+			model.ReasoningEffort = args.ReasoningEffort
+		}
+		if raw := args.ExtraParams; raw != "" {
+			if err := json.Unmarshal([]byte(raw), &model.ExtraParams); err != nil {
+				return nil, fmt.Errorf("invalid extra_params JSON: %v", err)
+			}
+		}
+		models := []testsuite.Model{model}
 		if err := validateModels(models); err != nil {
 			return nil, err
 		}
@@ -148,66 +453,280 @@ func validateModels(models []testsuite.Model) error {
 		if strings.TrimSpace(model.Name) == "" {
 			return fmt.Errorf("model name cannot be empty")
 		}
+		if model.MaxRetries < 0 {
+			return fmt.Errorf("max_retries for model %q cannot be negative", model.Name)
+		}
+		if model.Temperature < 0 {
+			return fmt.Errorf("temperature for model %q cannot be negative", model.Name)
+		}
+		if err := llm.ValidateReasoningEffort(model.ReasoningEffort); err != nil {
+			return fmt.Errorf("model %q: %w", model.Name, err)
+		}
 	}
 	return nil
 }
 
 // clientForModel handles the per-model lifecycle: deploy via KServe if needed,
-// then return a client pointing to the model's endpoint.
-func clientForModel(ctx context.Context, sc *server.ServerContext, model testsuite.Model, args map[string]interface{}, deployEnabled bool) (llm.Client, error) {
-	// Explicit endpoint overrides everything.
-	if endpoint, ok := args["endpoint"].(string); ok && endpoint != "" {
-		return newEndpointClient(endpoint, sc.LLMAPIKey), nil
+// then return a client pointing to the model's endpoint, and the scope of
+// the model's responses in the response cache (see cacheScope). An endpoint
+// overrides both. The records
+// of the models it deploys are added to records, and their deployment
+// phases are reported to onDeploy (optional).
+func clientForModel(ctx context.Context, sc *server.ServerContext, model testsuite.Model, endpoint string, deployEnabled bool, records map[string]*kserve.DeploymentRecord, onDeploy kserve.ProgressFunc) (llm.Client, string, error) {
+	// Models of a provider, including openai, are reached through its
+	// endpoint with its credentials, never through KServe.
+	if model.Provider != "" {
+		client, err := sc.Providers.Client(model.Provider, sc.LLMOptions...)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to create %s client for model %q: %w", model.Provider, model.Name, err)
+		}
+		cfg, _ := sc.Providers.Lookup(model.Provider)
+		return client, cacheScope("provider", model.Provider, cfg.Type, cfg.Endpoint), nil
+	}
+
+	// Explicit endpoint overrides everything else.
+	if endpoint != "" {
+		return newEndpointClient(endpoint, sc.LLMAPIKey, sc.LLMAPIKeyFile, sc.LLMOptions...), cacheScope("endpoint", endpoint), nil
 	}
 
 	// Deploy via KServe if model_uri is provided.
 	if deployEnabled && model.ModelURI != "" && sc.KServeManager != nil {
-		cfg := kserve.DefaultModelConfig(model.Name, model.ModelURI)
-		if model.GPUCount > 0 {
-			cfg.GPUCount = model.GPUCount
-		}
+		cfg := deployConfig(ctx, sc, model)
+		cfg.Progress = onDeploy
 
 		slog.Info("deploying model for test run", "model", model.Name, "uri", model.ModelURI)
-		status, err := sc.KServeManager.Deploy(ctx, cfg)
+		manager := sc.KServeManager.WithNamespace(model.Namespace)
+		started := time.Now()
+		status, err := manager.Deploy(ctx, cfg)
 		if err != nil {
-			return nil, fmt.Errorf("failed to deploy model %q: %w", model.Name, err)
+			return nil, "", fmt.Errorf("failed to deploy model %q: %w", model.Name, err)
+		}
+		if record := recordDeployment(ctx, manager, model.Name, status, started); record != nil {
+			records[model.Name] = record
 		}
 
 		slog.Info("model deployed, using endpoint", "model", model.Name, "endpoint", status.EndpointURL)
-		return llm.NewOpenAIClient(llm.WithBaseURL(status.EndpointURL)), nil
+		return newEndpointClient(status.EndpointURL, "", "", sc.LLMOptions...), kserveScope(manager, status), nil
 	}
 
 	// Try auto-discovery from existing KServe InferenceService.
 	if sc.KServeManager != nil {
-		status, err := sc.KServeManager.Get(ctx, model.Name)
-		if err == nil && status.Ready && status.EndpointURL != "" {
-			slog.Info("auto-discovered KServe endpoint", "model", model.Name, "endpoint", status.EndpointURL)
-			return llm.NewOpenAIClient(llm.WithBaseURL(status.EndpointURL)), nil
+		manager := sc.KServeManager.WithNamespace(model.Namespace)
+		if status, err := manager.Get(ctx, model.Name); err == nil && status.Ready {
+			endpoint, err := manager.ModelEndpoint(ctx, model.Name, sc.EndpointURLMode)
+			if err == nil {
+				slog.Info("auto-discovered KServe endpoint", "model", model.Name, "endpoint", endpoint)
+				return newEndpointClient(endpoint, "", "", sc.LLMOptions...), kserveScope(manager, status), nil
+			}
+			slog.Warn("failed to resolve KServe endpoint", "model", model.Name, "error", err)
 		}
 	}
 
 	// Fall back to default client.
-	return sc.LLMClient, nil
+	return sc.LLMClient, cacheScope("default"), nil
+}
+
+// aliasedModel resolves a model alias naming a judge or generating model,
+// e.g. a scoring_model, to the name of the model it stands for and, if it
+// has a provider, a client reaching the model through the provider, as
+// clientForModel does for tested models. The client is nil for models of
+// the server's default client, including names that are not aliases.
+func aliasedModel(sc *server.ServerContext, name string) (string, llm.Client, error) {
+	model, ok := sc.ModelAliases.Lookup(name)
+	if !ok || model.Provider == "" {
+		return sc.ModelAliases.ModelName(name), nil, nil
+	}
+	client, err := sc.Providers.Client(model.Provider, sc.LLMOptions...)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create %s client for model %q: %w", model.Provider, model.Name, err)
+	}
+	return model.Name, client, nil
+}
+
+// cacheScope identifies what serves a model's requests in the response
+// cache, so that a model name served by different endpoints or deployments
+// is cached separately.
+func cacheScope(parts ...string) string {
+	return strings.Join(parts, "|")
+}
+
+// kserveScope is the cache scope of a model served by KServe: its
+// InferenceService and the hash of its spec, which covers the weights and
+// deployment settings. The endpoint URL is left out, as port-forwarded
+// endpoints change with every run.
+func kserveScope(manager *kserve.Manager, status *kserve.ModelStatus) string {
+	return cacheScope("kserve", manager.Namespace()+"/"+status.Name, status.SpecHash)
+}
+
+// recordDeployment returns the record of a model deployed for a test run, or
+// nil if it cannot be read; a missing record never fails the run.
+func recordDeployment(ctx context.Context, manager *kserve.Manager, name string, status *kserve.ModelStatus, started time.Time) *kserve.DeploymentRecord {
+	record, err := manager.RecordDeployment(ctx, name)
+	if err != nil {
+		slog.Warn("failed to record deployment", "model", name, "error", err)
+		return nil
+	}
+	record.Action = status.Action
+	record.StartedAt, record.ReadyAt = started, time.Now()
+	record.ReadySeconds = record.ReadyAt.Sub(started).Seconds()
+	return record
+}
+
+// deployConfig returns the KServe configuration for deploying a model of a
+// test run. The deployment is ephemeral: it belongs to this run.
+func deployConfig(ctx context.Context, sc *server.ServerContext, model testsuite.Model) kserve.ModelConfig {
+	cfg := kserve.DefaultModelConfig(model.Name, model.ModelURI)
+	cfg.UseBackend(kserve.Backend(model.Backend))
+	cfg.GGUFFile = model.GGUFFile
+	if model.GPUCount > 0 {
+		cfg.GPUCount = model.GPUCount
+	}
+	if model.Runtime != "" {
+		cfg.Runtime = model.Runtime
+	}
+	if model.CPURequest != "" {
+		cfg.CPURequest = model.CPURequest
+	}
+	if model.MemoryRequest != "" {
+		cfg.MemoryRequest = model.MemoryRequest
+	}
+	cfg.CPULimit, cfg.MemoryLimit = model.CPULimit, model.MemoryLimit
+	if model.ShmSize != "" {
+		cfg.ShmSize = model.ShmSize
+	}
+	cfg.HFTokenSecret, cfg.HFTokenSecretKey = model.HFTokenSecret, model.HFTokenSecretKey
+	if cfg.HFTokenSecret == "" {
+		cfg.HFTokenSecret = sc.HFTokenSecret
+	}
+	cfg.ServiceAccountName = model.ServiceAccountName
+	cfg.StorageKey = model.StorageKey
+	cfg.ImagePullSecrets = model.ImagePullSecrets
+	cfg.NodeSelector = model.NodeSelector
+	cfg.Tolerations = model.Tolerations
+	cfg.Affinity = model.Affinity
+	cfg.RuntimeClassName = model.RuntimeClassName
+	cfg.RuntimeArgs, cfg.Quantization = model.RuntimeArgs, model.Quantization
+	cfg.ArgsPreset = model.ArgsPreset
+	cfg.Env, cfg.RawDeployment = model.Env, model.RawDeployment
+	cfg.TensorParallelSize = model.TensorParallelSize
+	cfg.PipelineParallelSize, cfg.WorkerCount = model.PipelineParallelSize, model.WorkerCount
+	cfg.MinReplicas, cfg.MaxReplicas = model.MinReplicas, model.MaxReplicas
+	cfg.ScaleTarget, cfg.ScaleMetric = model.ScaleTarget, model.ScaleMetric
+	cfg.Recreate = model.Recreate
+	cfg.ProbeCompletion = model.ProbeCompletion
+	cfg.Ephemeral = true
+	cfg.CapacityCheck = sc.GPUCapacityCheck
+	cfg.URLMode = sc.EndpointURLMode
+	recommendGPUs(ctx, sc, &cfg, model.GPUCount > 0)
+	resolveGGUFFile(ctx, sc, &cfg)
+	return cfg
 }
 
 // teardownModel cleans up a model's KServe InferenceService after testing.
-// Only tears down models that were deployed by us (i.e. have a model_uri).
+// Only tears down models that the run created itself: an InferenceService
+// that existed before the run, e.g. one of deploy_model, is left in place.
+// The teardown also runs when ctx is already cancelled, e.g. by an
+// interrupted run, so that the model does not keep holding GPUs.
 func teardownModel(ctx context.Context, sc *server.ServerContext, model testsuite.Model, deployEnabled bool) error {
 	if !deployEnabled || model.ModelURI == "" || sc.KServeManager == nil {
 		return nil // Not deployed by us, nothing to teardown.
 	}
+	manager := sc.KServeManager.WithNamespace(model.Namespace)
+	if !manager.IsTracked(model.Name) {
+		slog.Info("leaving model in place that existed before the test", "model", model.Name)
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), teardownTimeout)
+	defer cancel()
 
 	slog.Info("tearing down model after test", "model", model.Name)
-	if err := sc.KServeManager.Teardown(ctx, model.Name); err != nil {
+	if err := manager.Teardown(ctx, model.Name); err != nil {
 		return fmt.Errorf("failed to teardown model %q: %w", model.Name, err)
 	}
 	return nil
 }
 
-func newEndpointClient(endpoint, apiKey string) llm.Client {
+// teardownLeftovers tears down models of a run that are still deployed after
+// the run returned, e.g. because it failed or was cancelled before the
+// runner's per-model teardown.
+func teardownLeftovers(ctx context.Context, sc *server.ServerContext, models []testsuite.Model, deployEnabled bool) {
+	for _, model := range models {
+		if model.ModelURI == "" || !sc.KServeManager.WithNamespace(model.Namespace).IsTracked(model.Name) {
+			continue
+		}
+		if err := teardownModel(ctx, sc, model, deployEnabled); err != nil {
+			slog.Error("failed to tear down model of interrupted run", "model", model.Name, "error", err)
+		}
+	}
+}
+
+func newEndpointClient(endpoint, apiKey, apiKeyFile string, extra ...llm.Option) llm.Client {
 	opts := []llm.Option{llm.WithBaseURL(endpoint)}
 	if apiKey != "" {
 		opts = append(opts, llm.WithAPIKey(apiKey))
 	}
-	return llm.NewOpenAIClient(opts...)
+	if apiKeyFile != "" {
+		opts = append(opts, llm.WithAPIKeyFile(apiKeyFile))
+	}
+	return llm.NewOpenAIClient(append(opts, extra...)...)
+}
+
+// RunTemplate submits a test suite run as configured by a run template,
+// e.g. when triggered by a webhook, to the server's job queue and returns
+// its ID. It behaves like an async call of the run_test_suite tool. Errors
+// worth retrying, e.g. while the server shuts down, are
+// webhook.ErrUnavailable.
+func RunTemplate(ctx context.Context, sc *server.ServerContext, t webhook.Template) (string, error) {
+	models, err := json.Marshal(t.Models)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal models: %w", err)
+	}
+
+	args := runTestSuiteArgs{
+		suiteArgs:    suiteArgs{TestSuite: t.Suite, Profile: t.Profile, Examples: t.Examples, Blind: t.Blind},
+		Models:       string(models),
+		Endpoint:     t.Endpoint,
+		Deploy:       t.Deploy,
+		Judge:        t.Judge,
+		ScoringModel: t.ScoringModel,
+		Async:        true,
+	}
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "run_test_suite"
+
+	ctx, done, err := sc.Drain.Begin(ctx)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", webhook.ErrUnavailable, err)
+	}
+	defer done()
+	result, err := runTestSuite(ctx, request, sc, args, runStages{})
+	if err != nil {
+		return "", err
+	}
+	if result.IsError {
+		err := toolResultError(result)
+		if classifyError(err, codeInternal).retryable() {
+			return "", fmt.Errorf("%w: %w", webhook.ErrUnavailable, err)
+		}
+		return "", err
+	}
+	var submitted struct {
+		RunID string `json:"run_id"`
+	}
+	if err := json.Unmarshal([]byte(toolResultText(result)), &submitted); err != nil {
+		return "", fmt.Errorf("failed to read run ID: %w", err)
+	}
+	return submitted.RunID, nil
+}
+
+// toolResultText returns the text content of a tool result.
+func toolResultText(result *mcp.CallToolResult) string {
+	var parts []string
+	for _, c := range result.Content {
+		if text, ok := c.(mcp.TextContent); ok {
+			parts = append(parts, text.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
 }
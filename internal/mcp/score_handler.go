@@ -4,93 +4,267 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/invopop/jsonschema"
 	"github.com/mark3labs/mcp-go/mcp"
 
+	"github.com/giantswarm/llm-testing/internal/history"
+	"github.com/giantswarm/llm-testing/internal/identity"
+	"github.com/giantswarm/llm-testing/internal/llm"
 	"github.com/giantswarm/llm-testing/internal/scorer"
 	"github.com/giantswarm/llm-testing/internal/server"
 )
 
+// judgeArgs are the arguments of the judge, shared by score_results and
+// evaluate_model.
+type judgeArgs struct {
+	ScoringModel       string `json:"scoring_model,omitempty" jsonschema_description:"Model to use as judge (default: server scoring model)"`
+	Repetitions        int    `json:"repetitions,omitempty" jsonschema:"minimum=1" jsonschema_description:"Number of scoring repetitions for confidence (default: 3)"`
+	Mode               string `json:"mode,omitempty" jsonschema:"enum=aggregate,enum=per_question" jsonschema_description:"Scoring mode: 'aggregate' counts correct answers in one judge call, 'per_question' judges each answer separately (default: aggregate)"`
+	HallucinationCheck bool   `json:"hallucination_check,omitempty" jsonschema_description:"Run a second judging pass that flags answers with fabricated resource names, flags, or API versions, and report a hallucination rate (default: false)"`
+}
+
+// scoreResultsArgs are the arguments of score_results.
+type scoreResultsArgs struct {
+	RunID       string `json:"run_id,omitempty" jsonschema_description:"Run ID to score, or 'latest' for the last run of this session (scores all result files in the run directory)"`
+	ResultsFile string `json:"results_file,omitempty" jsonschema_description:"Path to a specific results file to score"`
+	judgeArgs
+	Temperature     *float64 `json:"temperature,omitempty" jsonschema:"minimum=0" jsonschema_description:"Judge temperature (default: 0.0)"`
+	MaxTokens       int      `json:"max_tokens,omitempty" jsonschema:"minimum=1" jsonschema_description:"Maximum number of tokens the judge may generate (default: server default)"`
+	ReasoningEffort string   `json:"reasoning_effort,omitempty" jsonschema:"enum=low,enum=medium,enum=high" jsonschema_description:"Reasoning effort for judges that support it: low, medium, or high (default: server default)"`
+	Judges          string   `json:"judges,omitempty"`
+	Consensus       string   `json:"consensus,omitempty" jsonschema:"enum=majority,enum=unanimous,enum=weighted" jsonschema_description:"Rule combining judge votes in per_question mode: majority, unanimous, or weighted (default: majority)"`
+	Rescore         bool     `json:"rescore,omitempty" jsonschema_description:"Only re-run repetitions that failed or could not be parsed in the existing scores file, merging the results (default: false)"`
+	Format          string   `json:"format,omitempty" jsonschema:"enum=json,enum=junit" jsonschema_description:"Additional output format: 'junit' also writes a JUnit XML report (one testsuite per model, one testcase per question; requires mode 'per_question'). Default: json only"`
+	Verbosity       string   `json:"verbosity,omitempty" jsonschema:"enum=summary,enum=normal,enum=full" jsonschema_description:"Result detail: 'summary' returns a compact summary, 'normal' the complete result, and 'full' also embeds the run files; the run files are linked as results:// resources otherwise (default: normal)"`
+}
+
+// JSONSchemaExtend completes the schema with the description of judges,
+// which spans several lines.
+func (scoreResultsArgs) JSONSchemaExtend(s *jsonschema.Schema) {
+	describeArg(s, "judges", `JSON array of judge models for per_question mode. Each judge can include:
+- "model" (required): judge model name
+- "weight": calibration accuracy (0-1) used by the 'weighted' consensus rule (default: 1)
+
+Example: [{"model":"gpt-4o","weight":0.92},{"model":"claude-sonnet-4-5","weight":0.95}]`)
+}
+
 func handleScoreResults(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
 	if sc.LLMClient == nil {
-		return mcp.NewToolResultError("LLM client is not configured"), nil
+		return toolError(codeNotConfigured, "LLM client is not configured"), nil
 	}
 
-	args := request.GetArguments()
-
-	resultsFile, _ := args["results_file"].(string)
-	runID, _ := args["run_id"].(string)
+	args, err := bindArgs[scoreResultsArgs](request)
+	if err != nil {
+		return toolErrorFrom(err, codeInvalidArgument), nil
+	}
+	resultsFile, runID := args.ResultsFile, args.RunID
 
 	if resultsFile == "" && runID == "" {
-		return mcp.NewToolResultError("either 'run_id' or 'results_file' is required"), nil
+		return toolError(codeInvalidArgument, "either 'run_id' or 'results_file' is required"), nil
 	}
 	if resultsFile != "" && runID != "" {
-		return mcp.NewToolResultError("provide only one of 'run_id' or 'results_file'"), nil
+		return toolError(codeInvalidArgument, "provide only one of 'run_id' or 'results_file'"), nil
 	}
 
-	cfg := scorer.Config{
-		Model:       sc.ScoringModel, // from server config; falls back to DefaultScoringModel in NewScorer
-		Repetitions: 3,
-	}
-
-	if model, ok := args["scoring_model"].(string); ok && model != "" {
-		cfg.Model = model // explicit parameter overrides server default
-	}
-	if reps, ok := args["repetitions"].(float64); ok && reps > 0 {
-		cfg.Repetitions = int(reps)
+	cfg, opts, err := parseScoreConfig(args, sc)
+	if err != nil {
+		return toolErrorFrom(err, codeInvalidArgument), nil
 	}
 
 	s := scorer.NewScorer(sc.LLMClient, cfg)
+	notify := newProgressNotifier(ctx, request)
 
 	// If run_id is specified, resolve to the results files in the run directory.
 	if runID != "" {
 		safeRunPath, err := resolveRunPath(sc.OutputDir, runID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("invalid run_id: %v", err)), nil
+			return toolErrorf(codeInvalidArgument, "invalid run_id: %v", err), nil
 		}
-		return scoreByRunID(ctx, s, runID, safeRunPath)
+		return scoreByRunID(ctx, s, runID, safeRunPath, opts, notify)
 	}
 
 	safeResultsFile, err := resolveResultFilePath(sc.OutputDir, resultsFile)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("invalid results_file: %v", err)), nil
+		return toolErrorf(codeInvalidArgument, "invalid results_file: %v", err), nil
+	}
+
+	s.SetProgressFunc(func(file string, completed, total int) {
+		notify(float64(completed), float64(total),
+			fmt.Sprintf("scored repetition %d/%d of %s", completed, total, filepath.Base(file)))
+	})
+	return scoreSingleFile(ctx, s, safeResultsFile, opts)
+}
+
+// parseScoreConfig returns the scorer configuration and post-processing
+// options set by the arguments of score_results.
+func parseScoreConfig(args scoreResultsArgs, sc *server.ServerContext) (scorer.Config, scoreOptions, error) {
+	opts := scoreOptions{registry: sc.ModelRegistry, outputDir: sc.OutputDir}
+	cfg := scorer.Config{
+		Model:       sc.ScoringModel, // from server config; falls back to DefaultScoringModel in NewScorer
+		Repetitions: 3,
+	}
+
+	if model := args.ScoringModel; model != "" {
+		// explicit parameter overrides server default
+		name, client, err := aliasedModel(sc, model)
+		if err != nil {
+			return cfg, opts, err
+		}
+		cfg.Model = name
+		cfg.Clients = addClient(cfg.Clients, name, client)
+	}
+	if args.Repetitions > 0 {
+		cfg.Repetitions = args.Repetitions
+	}
+	cfg.Temperature = args.Temperature
+	cfg.MaxTokens = args.MaxTokens
+	cfg.ReasoningEffort = args.ReasoningEffort
+	cfg.Mode = args.Mode
+
+	if judgesJSON := args.Judges; judgesJSON != "" {
+		judges, clients, err := parseJudges(judgesJSON, sc)
+		if err != nil {
+			return cfg, opts, err
+		}
+		cfg.Judges = judges
+		for name, client := range clients {
+			cfg.Clients = addClient(cfg.Clients, name, client)
+		}
+	}
+	cfg.Consensus = args.Consensus
+	if (len(cfg.Judges) > 0 || cfg.Consensus != "") && cfg.Mode != scorer.ModePerQuestion {
+		return cfg, opts, fmt.Errorf("'judges' and 'consensus' require mode 'per_question'")
+	}
+
+	cfg.HallucinationCheck = args.HallucinationCheck
+	cfg.Prices = sc.JudgePrices
+
+	opts.rescore = args.Rescore
+	if args.Format == scorer.FormatJUnit && cfg.Mode != scorer.ModePerQuestion {
+		return cfg, opts, fmt.Errorf("format 'junit' requires mode 'per_question'")
+	}
+	opts.format = args.Format
+	var err error
+	if opts.verbosity, err = validateVerbosity(args.Verbosity); err != nil {
+		return cfg, opts, err
 	}
+	return cfg, opts, nil
+}
 
-	return scoreSingleFile(ctx, s, safeResultsFile)
+// parseJudges parses the judges JSON array parameter, resolving model
+// aliases to the models they name. It returns the clients of the judges
+// that the server's default client does not reach.
+func parseJudges(judgesJSON string, sc *server.ServerContext) ([]scorer.Judge, map[string]llm.Client, error) {
+	var judges []scorer.Judge
+	if err := json.Unmarshal([]byte(judgesJSON), &judges); err != nil {
+		return nil, nil, fmt.Errorf("invalid judges JSON: %v", err)
+	}
+	var clients map[string]llm.Client
+	for i, j := range judges {
+		if strings.TrimSpace(j.Model) == "" {
+			return nil, nil, fmt.Errorf("judge model cannot be empty")
+		}
+		if j.Weight < 0 || j.Weight > 1 {
+			return nil, nil, fmt.Errorf("weight for judge %q must be between 0 and 1", j.Model)
+		}
+		name, client, err := aliasedModel(sc, j.Model)
+		if err != nil {
+			return nil, nil, err
+		}
+		judges[i].Model = name
+		clients = addClient(clients, name, client)
+	}
+	return judges, clients, nil
+}
+
+// addClient adds the client of a model to clients, unless it is nil, i.e.
+// the server's default client.
+func addClient(clients map[string]llm.Client, model string, client llm.Client) map[string]llm.Client {
+	if client == nil {
+		return clients
+	}
+	if clients == nil {
+		clients = map[string]llm.Client{}
+	}
+	clients[model] = client
+	return clients
+}
+
+// scoreOptions control how scored files are post-processed and reported.
+type scoreOptions struct {
+	rescore   bool
+	format    string
+	verbosity string
+	outputDir string
+	registry  *identity.Registry
+}
+
+// scoreFile scores a results file, or only re-runs its failed repetitions when rescore is set.
+func scoreFile(ctx context.Context, s *scorer.Scorer, resultsFile string, rescore bool) (*scorer.ScoreOutput, error) {
+	if rescore {
+		return s.RescoreFile(ctx, resultsFile)
+	}
+	return s.ScoreFile(ctx, resultsFile)
 }
 
 // scoreSingleFile scores a single results file.
-func scoreSingleFile(ctx context.Context, s *scorer.Scorer, resultsFile string) (*mcp.CallToolResult, error) {
-	output, err := s.ScoreFile(ctx, resultsFile)
+func scoreSingleFile(ctx context.Context, s *scorer.Scorer, resultsFile string, opts scoreOptions) (*mcp.CallToolResult, error) {
+	output, err := scoreFile(ctx, s, resultsFile, opts.rescore)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("scoring failed: %v", err)), nil
+		return toolErrorFromf(err, codeLLMError, "scoring failed: %v", err), nil
 	}
 
 	scoresFile, err := scorer.WriteScoreFile(output, resultsFile)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to write scores: %v", err)), nil
+		return toolErrorFromf(err, codeInternal, "failed to write scores: %v", err), nil
 	}
+	recordHistory(resultsFile, scoresFile, output, opts.registry)
 
 	result := map[string]interface{}{
 		"scores_file": scoresFile,
-		"summary":     output.Summary,
 		"runs":        len(output.Runs),
 	}
+	if len(output.Metadata.Warnings) > 0 {
+		result["warnings"] = output.Metadata.Warnings
+	}
+	if opts.verbosity == verbositySummary {
+		result["summary"] = compactSummary(output.Summary)
+	} else {
+		result["summary"] = output.Summary
+		result["rescored_runs"] = output.Metadata.RescoredRuns
+		if output.Metadata.Usage != nil {
+			result["usage"] = output.Metadata.Usage
+		}
+	}
+	files := []string{scoresFile}
+
+	if opts.format == scorer.FormatJUnit {
+		junitFile := scorer.JUnitFilePath(resultsFile)
+		if err := scorer.WriteJUnitFile(junitFile, "", []*scorer.ScoreOutput{output}); err != nil {
+			return toolErrorFrom(err, codeInternal), nil
+		}
+		result["junit_file"] = junitFile
+		files = append(files, junitFile)
+	}
 
 	data, err := json.MarshalIndent(result, "", "  ")
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err)), nil
+		return toolErrorf(codeInternal, "failed to marshal result: %v", err), nil
 	}
-	return mcp.NewToolResultText(string(data)), nil
+	return runFilesResult(string(data), opts.verbosity, opts.outputDir, files...), nil
 }
 
 // scoreByRunID finds all .txt result files in a run directory and scores each one.
-func scoreByRunID(ctx context.Context, s *scorer.Scorer, runID, runPath string) (*mcp.CallToolResult, error) {
+// Progress is reported per repetition, counting each results file as one unit.
+func scoreByRunID(ctx context.Context, s *scorer.Scorer, runID, runPath string, opts scoreOptions, notify progressNotifier) (*mcp.CallToolResult, error) {
 	entries, err := os.ReadDir(runPath)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("run %q not found: %v", runID, err)), nil
+		return toolErrorFromf(runNotFound(err), codeInternal, "run %q not found: %v", runID, err), nil
 	}
 
 	// Find result files (*.txt, excluding score files).
@@ -106,45 +280,94 @@ func scoreByRunID(ctx context.Context, s *scorer.Scorer, runID, runPath string)
 	}
 
 	if len(resultFiles) == 0 {
-		return mcp.NewToolResultError(fmt.Sprintf("no result files found in run %q", runID)), nil
+		return toolErrorf(codeNotFound, "no result files found in run %q", runID), nil
 	}
 
 	// Score each result file.
 	type fileScore struct {
-		ResultsFile string      `json:"results_file"`
-		ScoresFile  string      `json:"scores_file"`
-		Summary     interface{} `json:"summary"`
-		Runs        int         `json:"runs"`
+		ResultsFile  string             `json:"results_file"`
+		ScoresFile   string             `json:"scores_file"`
+		Summary      interface{}        `json:"summary"`
+		Runs         int                `json:"runs"`
+		RescoredRuns []int              `json:"rescored_runs,omitempty"`
+		Usage        *scorer.TokenUsage `json:"usage,omitempty"`
+		Warnings     []string           `json:"warnings,omitempty"`
 	}
 
 	var scored []fileScore
-	for _, rf := range resultFiles {
-		output, err := s.ScoreFile(ctx, rf)
+	var outputs []*scorer.ScoreOutput
+	var files []string
+	var usage scorer.TokenUsage
+	totalFiles := float64(len(resultFiles))
+	for i, rf := range resultFiles {
+		s.SetProgressFunc(func(file string, completed, total int) {
+			notify(float64(i)+float64(completed)/float64(total), totalFiles,
+				fmt.Sprintf("scored repetition %d/%d of %s (file %d/%d)", completed, total, filepath.Base(file), i+1, len(resultFiles)))
+		})
+		output, err := scoreFile(ctx, s, rf, opts.rescore)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("scoring failed for %s: %v", rf, err)), nil
+			return toolErrorFromf(err, codeLLMError, "scoring failed for %s: %v", rf, err), nil
 		}
 
 		scoresFile, err := scorer.WriteScoreFile(output, rf)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("failed to write scores for %s: %v", rf, err)), nil
+			return toolErrorFromf(err, codeInternal, "failed to write scores for %s: %v", rf, err), nil
 		}
+		recordHistory(rf, scoresFile, output, opts.registry)
+		outputs = append(outputs, output)
+		notify(float64(i+1), totalFiles, fmt.Sprintf("scored %s", filepath.Base(rf)))
 
-		scored = append(scored, fileScore{
-			ResultsFile: rf,
-			ScoresFile:  scoresFile,
-			Summary:     output.Summary,
-			Runs:        len(output.Runs),
-		})
+		score := fileScore{
+			ResultsFile:  rf,
+			ScoresFile:   scoresFile,
+			Summary:      output.Summary,
+			Runs:         len(output.Runs),
+			RescoredRuns: output.Metadata.RescoredRuns,
+			Usage:        output.Metadata.Usage,
+			Warnings:     output.Metadata.Warnings,
+		}
+		if opts.verbosity == verbositySummary {
+			score = fileScore{ResultsFile: rf, ScoresFile: scoresFile, Summary: compactSummary(output.Summary), Runs: len(output.Runs), Warnings: output.Metadata.Warnings}
+		}
+		scored = append(scored, score)
+		files = append(files, scoresFile)
+		usage.Add(output.Metadata.Usage)
 	}
 
 	result := map[string]interface{}{
 		"run_id": runID,
 		"scored": scored,
 	}
+	if usage.Calls > 0 && opts.verbosity != verbositySummary {
+		result["usage"] = usage
+	}
+
+	if opts.format == scorer.FormatJUnit {
+		junitFile := joinRunFile(runPath, "junit.xml")
+		if err := scorer.WriteJUnitFile(junitFile, runID, outputs); err != nil {
+			return toolErrorFrom(err, codeInternal), nil
+		}
+		result["junit_file"] = junitFile
+		files = append(files, junitFile)
+	}
 
 	data, err := json.MarshalIndent(result, "", "  ")
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err)), nil
+		return toolErrorf(codeInternal, "failed to marshal result: %v", err), nil
+	}
+	return runFilesResult(string(data), opts.verbosity, opts.outputDir, files...), nil
+}
+
+// recordHistory appends the score summary to the suite/model score history.
+// Failures are logged but do not fail scoring.
+func recordHistory(resultsFile, scoresFile string, output *scorer.ScoreOutput, registry *identity.Registry) {
+	entry, err := history.Record(resultsFile, scoresFile, output, registry)
+	if err != nil {
+		slog.Warn("failed to record score history", "results_file", resultsFile, "error", err)
+		return
+	}
+	if entry.SuiteChanged {
+		slog.Warn("test suite content changed since the previous scored run; scores are not directly comparable",
+			"suite", entry.Suite, "model", entry.Model, "suite_version", entry.SuiteVersion, "suite_hash", entry.SuiteHash)
 	}
-	return mcp.NewToolResultText(string(data)), nil
 }
@@ -0,0 +1,143 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"github.com/giantswarm/llm-testing/internal/server"
+)
+
+// latest is the value of an identifier argument that refers to the working
+// context of the client session.
+const latest = "latest"
+
+// latestArgs are the arguments that accept "latest", with what they refer
+// to and how it is found in the working context.
+var latestArgs = map[string]struct {
+	what  string
+	value func(server.WorkingContext) string
+}{
+	"run_id":     {"run", func(wc server.WorkingContext) string { return wc.RunID }},
+	"model":      {"deployed model", func(wc server.WorkingContext) string { return wc.Model }},
+	"model_name": {"deployed model", func(wc server.WorkingContext) string { return wc.Model }},
+	"test_suite": {"test suite", func(wc server.WorkingContext) string { return wc.TestSuite }},
+}
+
+// SessionOptions return the server options that keep the working context of
+// each client session in sc.Sessions: the run, deployed model, and test
+// suite it last worked on. Its calls may then pass "latest" as run_id,
+// model, model_name, or test_suite, except for the run_id of
+// delete_results, which must be explicit. The connected sessions are
+// recorded too, for notifications to them.
+func SessionOptions(sc *server.ServerContext) []mcpserver.ServerOption {
+	hooks := &mcpserver.Hooks{}
+	hooks.AddOnRegisterSession(func(_ context.Context, session mcpserver.ClientSession) {
+		sc.Sessions.Connect(session.SessionID())
+	})
+	hooks.AddOnUnregisterSession(func(_ context.Context, session mcpserver.ClientSession) {
+		sc.Sessions.Forget(session.SessionID())
+	})
+	return []mcpserver.ServerOption{
+		mcpserver.WithHooks(hooks),
+		mcpserver.WithToolHandlerMiddleware(func(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+			return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				id := sessionID(ctx)
+				args, err := resolveLatest(request.Params.Name, request.GetArguments(), sc.Sessions.Get(id))
+				if err != nil {
+					return toolErrorFrom(err, codeInvalidArgument), nil
+				}
+				request.Params.Arguments = args
+
+				result, err := next(ctx, request)
+				if err == nil && result != nil && !result.IsError {
+					sc.Sessions.Update(id, func(wc *server.WorkingContext) {
+						recordWorkingContext(wc, request.Params.Name, args, result)
+					})
+				}
+				return result, err
+			}
+		}),
+	}
+}
+
+// sessionID returns the ID of the client session of a tool call, or "" if
+// it has none, e.g. in tests.
+func sessionID(ctx context.Context) string {
+	if session := mcpserver.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
+
+// resolveLatest returns the arguments of a call of tool with "latest"
+// replaced by the identifiers of the working context.
+func resolveLatest(tool string, args map[string]interface{}, wc server.WorkingContext) (map[string]interface{}, error) {
+	resolved, cloned := args, false
+	for name, arg := range latestArgs {
+		if v, _ := args[name].(string); v != latest {
+			continue
+		}
+		if tool == "delete_results" && name == "run_id" {
+			return nil, fmt.Errorf("delete_results requires an explicit run_id")
+		}
+		value := arg.value(wc)
+		if value == "" {
+			return nil, fmt.Errorf("%s: no %s in this session yet to refer to as %q", name, arg.what, latest)
+		}
+		if !cloned {
+			resolved, cloned = maps.Clone(args), true
+		}
+		resolved[name] = value
+	}
+	return resolved, nil
+}
+
+// recordWorkingContext records in wc what a successful call of tool with
+// args worked on: the test suite it was given, the model deploy_model or
+// evaluate_model deployed, and the run it started, returned, or was given.
+func recordWorkingContext(wc *server.WorkingContext, tool string, args map[string]interface{}, result *mcp.CallToolResult) {
+	if suite, _ := args["test_suite"].(string); suite != "" {
+		wc.TestSuite = suite
+	}
+	switch tool {
+	case "deploy_model":
+		if model, _ := args["model_name"].(string); model != "" {
+			wc.Model = model
+		}
+	case "evaluate_model":
+		if model, _ := args["model"].(string); model != "" {
+			wc.Model = model
+		}
+	case "delete_results":
+		if runID, _ := args["run_id"].(string); runID == wc.RunID {
+			wc.RunID = ""
+		}
+		return
+	}
+
+	var run struct {
+		RunID string `json:"run_id"`
+	}
+	if err := json.Unmarshal([]byte(toolResultText(result)), &run); err != nil || run.RunID == "" {
+		run.RunID, _ = args["run_id"].(string)
+	}
+	if run.RunID != "" {
+		wc.RunID = run.RunID
+	}
+}
+
+func handleGetSessionContext(ctx context.Context, _ mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	if sc.Sessions == nil {
+		return toolError(codeNotConfigured, "session contexts are not enabled on this server"), nil
+	}
+	data, err := json.MarshalIndent(sc.Sessions.Get(sessionID(ctx)), "", "  ")
+	if err != nil {
+		return toolErrorf(codeInternal, "failed to marshal session context: %v", err), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
@@ -0,0 +1,105 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/llm-testing/internal/jobs"
+	"github.com/giantswarm/llm-testing/internal/server"
+)
+
+// cancelRunArgs are the arguments of cancel_run.
+type cancelRunArgs struct {
+	RunID string `json:"run_id" jsonschema_description:"Run ID returned by run_test_suite, or listed by get_run_status, or 'latest' for the last run of this session"`
+}
+
+// runStatusArgs are the arguments of get_run_status.
+type runStatusArgs struct {
+	RunID string `json:"run_id,omitempty" jsonschema_description:"Run ID returned by run_test_suite, or 'latest' for the last run of this session (default: list all runs)"`
+}
+
+func handleGetRunStatus(_ context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	if sc.Jobs == nil {
+		return toolError(codeNotConfigured, "async runs are not enabled on this server"), nil
+	}
+
+	args, err := bindArgs[runStatusArgs](request)
+	if err != nil {
+		return toolErrorFrom(err, codeInvalidArgument), nil
+	}
+
+	var status any
+	if args.RunID != "" {
+		job, err := sc.Jobs.Get(args.RunID)
+		if err != nil {
+			return toolErrorFrom(err, codeInvalidArgument), nil
+		}
+		status = job.Status()
+	} else {
+		status = sc.Jobs.List()
+	}
+
+	data, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		return toolErrorf(codeInternal, "failed to marshal run status: %v", err), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+func handleCancelRun(_ context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	if sc.Jobs == nil {
+		return toolError(codeNotConfigured, "async runs are not enabled on this server"), nil
+	}
+	args, err := bindArgs[cancelRunArgs](request)
+	if err != nil {
+		return toolErrorFrom(err, codeInvalidArgument), nil
+	}
+	runID := args.RunID
+
+	if _, err := sc.Jobs.Cancel(runID); err != nil {
+		return toolErrorFrom(err, codeConflict), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Cancelling run %s. Deployed models are torn down; use get_run_status to see when it has stopped.", runID)), nil
+}
+
+// listJobsArgs are the arguments of list_jobs.
+type listJobsArgs struct {
+	State string `json:"state,omitempty" jsonschema:"enum=queued,enum=running,enum=succeeded,enum=failed,enum=cancelled" jsonschema_description:"Only list runs in this state (default: all)"`
+}
+
+func handleListJobs(_ context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	if sc.Jobs == nil {
+		return toolError(codeNotConfigured, "async runs are not enabled on this server"), nil
+	}
+
+	args, err := bindArgs[listJobsArgs](request)
+	if err != nil {
+		return toolErrorFrom(err, codeInvalidArgument), nil
+	}
+
+	listed := make([]jobs.Status, 0)
+	counts := map[jobs.State]int{}
+	for _, status := range sc.Jobs.List() {
+		counts[status.State]++
+		if args.State != "" && status.State != jobs.State(args.State) {
+			continue
+		}
+		// Summaries are left to get_run_status.
+		status.Result = nil
+		listed = append(listed, status)
+	}
+
+	data, err := json.MarshalIndent(map[string]interface{}{
+		"max_concurrent_runs": sc.Jobs.MaxConcurrent(),
+		"running":             counts[jobs.StateRunning],
+		"queued":              counts[jobs.StateQueued],
+		"jobs":                listed,
+	}, "", "  ")
+	if err != nil {
+		return toolErrorf(codeInternal, "failed to marshal jobs: %v", err), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
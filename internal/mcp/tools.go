@@ -6,7 +6,8 @@ import (
 	"github.com/giantswarm/llm-testing/internal/server"
 )
 
-// RegisterTools registers all MCP tools with the server.
+// RegisterTools registers all MCP tools, and the resources of run and suite
+// files, with the server.
 func RegisterTools(s *mcpserver.MCPServer, sc *server.ServerContext) error {
 	if err := registerTestSuiteTools(s, sc); err != nil {
 		return err
@@ -14,5 +15,6 @@ func RegisterTools(s *mcpserver.MCPServer, sc *server.ServerContext) error {
 	if err := registerModelTools(s, sc); err != nil {
 		return err
 	}
+	registerResources(s, sc)
 	return nil
 }
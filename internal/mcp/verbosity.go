@@ -0,0 +1,115 @@
+package mcp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/llm-testing/internal/scorer"
+)
+
+// Verbosity levels of the tools that return run files, such as scores. At
+// summary verbosity the result is a compact summary, and the files are
+// linked as results:// resources for the client to read when needed; at
+// normal verbosity the result is complete and the files are linked; at full
+// verbosity the files are embedded as well.
+const (
+	verbositySummary = "summary"
+	verbosityNormal  = "normal"
+	verbosityFull    = "full"
+)
+
+// validateVerbosity returns the verbosity, normal if not set.
+func validateVerbosity(verbosity string) (string, error) {
+	switch verbosity {
+	case "":
+		return verbosityNormal, nil
+	case verbositySummary, verbosityNormal, verbosityFull:
+		return verbosity, nil
+	default:
+		return "", fmt.Errorf("invalid verbosity %q: must be one of summary, normal, full", verbosity)
+	}
+}
+
+// runFilesResult returns a result with text, followed by the run files at
+// paths: linked, or embedded at full verbosity. Files outside the run
+// directories of outputDir are left out, as they are not resources.
+func runFilesResult(text, verbosity, outputDir string, paths ...string) *mcp.CallToolResult {
+	result := mcp.NewToolResultText(text)
+	for _, path := range paths {
+		uri, ok := runFileURI(outputDir, path)
+		if !ok {
+			continue
+		}
+		name := filepath.Base(path)
+		if verbosity == verbosityFull {
+			if data, err := os.ReadFile(path); err == nil {
+				result.Content = append(result.Content, mcp.NewEmbeddedResource(mcp.TextResourceContents{
+					URI:      uri,
+					MIMEType: mimeType(name),
+					Text:     string(data),
+				}))
+				continue
+			}
+		}
+		result.Content = append(result.Content, mcp.NewResourceLink(uri, name, runFileDescription(name), mimeType(name)))
+	}
+	return result
+}
+
+// runFileURI returns the results:// URI of a file directly in a run
+// directory of outputDir.
+func runFileURI(outputDir, path string) (string, bool) {
+	base, err := filepath.Abs(outputDir)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(base, path)
+	if err != nil {
+		return "", false
+	}
+	runID, name, ok := strings.Cut(filepath.ToSlash(rel), "/")
+	if !ok || runID == ".." || strings.Contains(name, "/") {
+		return "", false
+	}
+	return "results://" + runID + "/" + name, true
+}
+
+// runFileDescription describes a run file by its name.
+func runFileDescription(name string) string {
+	switch {
+	case name == "resultset.json":
+		return "Run metadata"
+	case name == "junit.xml" || strings.HasSuffix(name, "_junit.xml"):
+		return "JUnit report"
+	case strings.HasSuffix(name, "_scores.json"):
+		return "Scores of " + strings.TrimSuffix(name, "_scores.json")
+	case strings.HasSuffix(name, ".txt"):
+		return "Results of " + strings.TrimSuffix(name, ".txt")
+	default:
+		return ""
+	}
+}
+
+// compactSummary returns the headline figures of a score summary, without
+// the unstable questions.
+func compactSummary(summary scorer.Summary) map[string]interface{} {
+	compact := map[string]interface{}{
+		"mean_percentage": summary.MeanPercent,
+		"mean_correct":    summary.MeanCorrect,
+		"all_runs_parsed": summary.AllRunsParsed,
+	}
+	if summary.MeanWeightedPercent != nil {
+		compact["mean_weighted_percentage"] = summary.MeanWeightedPercent
+	}
+	if summary.HallucinationRate != nil {
+		compact["hallucination_rate"] = summary.HallucinationRate
+	}
+	if len(summary.UnstableQuestions) > 0 {
+		compact["unstable_questions"] = len(summary.UnstableQuestions)
+	}
+	return compact
+}
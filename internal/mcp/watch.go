@@ -0,0 +1,75 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"github.com/giantswarm/llm-testing/internal/server"
+	"github.com/giantswarm/llm-testing/internal/testsuite"
+)
+
+// WatchSuites watches the server's external suites directory until ctx is
+// done. Changes are logged, suites that no longer load are reported, and
+// connected clients are sent a log message notification at the level they
+// set, so that agents know to call list_test_suites again.
+func WatchSuites(ctx context.Context, srv *mcpserver.MCPServer, sc *server.ServerContext) error {
+	return testsuite.Watch(ctx, sc.SuitesDir, func(change testsuite.SuitesChange) {
+		slog.Info("test suites reloaded", "dir", sc.SuitesDir,
+			"added", change.Added, "changed", change.Changed, "removed", change.Removed)
+
+		var broken []string
+		for _, name := range append(change.Added, change.Changed...) {
+			if _, err := testsuite.Load(name, sc.SuitesDir); err != nil {
+				slog.Warn("test suite fails to load after change", "suite", name, "error", err)
+				broken = append(broken, name)
+			}
+		}
+		notifySuitesChange(srv, sc.Sessions, change, broken)
+	})
+}
+
+// notifySuitesChange sends a log message about a change to the connected
+// sessions whose logging level (set with logging/setLevel) admits it:
+// warning if some suites are broken, else info.
+func notifySuitesChange(srv *mcpserver.MCPServer, sessions *server.Sessions, change testsuite.SuitesChange, broken []string) {
+	level := mcp.LoggingLevelInfo
+	if len(broken) > 0 {
+		level = mcp.LoggingLevelWarning
+	}
+	notification := mcp.NewLoggingMessageNotification(level, "llm-testing", map[string]any{
+		"message": describeSuitesChange(change, broken),
+		"added":   change.Added,
+		"changed": change.Changed,
+		"removed": change.Removed,
+		"broken":  broken,
+	})
+	for _, id := range sessions.Connected() {
+		if err := srv.SendLogMessageToSpecificClient(id, notification); err != nil {
+			slog.Debug("test suites change not sent to session", "session", id, "error", err)
+		}
+	}
+}
+
+// describeSuitesChange summarizes a change for clients.
+func describeSuitesChange(change testsuite.SuitesChange, broken []string) string {
+	var parts []string
+	for _, p := range []struct {
+		verb  string
+		names []string
+	}{
+		{"added", change.Added},
+		{"changed", change.Changed},
+		{"removed", change.Removed},
+		{"failing to load", broken},
+	} {
+		if len(p.names) > 0 {
+			parts = append(parts, fmt.Sprintf("%s: %s", p.verb, strings.Join(p.names, ", ")))
+		}
+	}
+	return "Test suites changed (" + strings.Join(parts, "; ") + ")"
+}
@@ -0,0 +1,80 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Annotation is a free-form finding attached to a run after it completed,
+// e.g. the analysis an agent produced after scoring.
+type Annotation struct {
+	Timestamp string `json:"timestamp"`
+	Author    string `json:"author,omitempty"`
+	Model     string `json:"model,omitempty"` // model the finding is about; empty for the whole run
+	Text      string `json:"text"`
+}
+
+// metadataMu serializes read-modify-write updates of resultset.json files.
+var metadataMu sync.Mutex
+
+// AddAnnotation appends an annotation to the run metadata in runDir and
+// returns all annotations of the run. If the annotation names a model, it
+// must be part of the run.
+func AddAnnotation(runDir string, a Annotation) ([]Annotation, error) {
+	metadataMu.Lock()
+	defer metadataMu.Unlock()
+
+	path := filepath.Join(runDir, "resultset.json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run metadata: %w", err)
+	}
+
+	var metadata map[string]json.RawMessage
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse run metadata: %w", err)
+	}
+
+	if a.Model != "" {
+		var models []struct {
+			ModelName string `json:"model_name"`
+		}
+		_ = json.Unmarshal(metadata["models"], &models)
+		found := false
+		for _, m := range models {
+			if m.ModelName == a.Model {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("model %q is not part of this run", a.Model)
+		}
+	}
+
+	var annotations []Annotation
+	if raw, ok := metadata["annotations"]; ok {
+		if err := json.Unmarshal(raw, &annotations); err != nil {
+			return nil, fmt.Errorf("failed to parse run annotations: %w", err)
+		}
+	}
+	annotations = append(annotations, a)
+
+	raw, err := json.Marshal(annotations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal run annotations: %w", err)
+	}
+	metadata["annotations"] = raw
+
+	out, err := json.MarshalIndent(metadata, "", "    ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal run metadata: %w", err)
+	}
+	if err := os.WriteFile(path, out, 0o644); err != nil {
+		return nil, fmt.Errorf("failed to write run metadata: %w", err)
+	}
+	return annotations, nil
+}
@@ -0,0 +1,50 @@
+package runner
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddAnnotation(t *testing.T) {
+	runDir := t.TempDir()
+	metadata := `{"id": "run-1", "suite": "test", "models": [{"model_name": "mistral-7b"}]}`
+	require.NoError(t, os.WriteFile(filepath.Join(runDir, "resultset.json"), []byte(metadata), 0o644))
+
+	_, err := AddAnnotation(runDir, Annotation{Text: "Networking answers are weak."})
+	require.NoError(t, err)
+
+	annotations, err := AddAnnotation(runDir, Annotation{Model: "mistral-7b", Author: "alice", Text: "Hallucinates kubectl flags."})
+	require.NoError(t, err)
+	require.Len(t, annotations, 2)
+	assert.Equal(t, "mistral-7b", annotations[1].Model)
+
+	data, err := os.ReadFile(filepath.Join(runDir, "resultset.json"))
+	require.NoError(t, err)
+
+	var stored struct {
+		ID          string       `json:"id"`
+		Annotations []Annotation `json:"annotations"`
+	}
+	require.NoError(t, json.Unmarshal(data, &stored))
+	assert.Equal(t, "run-1", stored.ID)
+	assert.Len(t, stored.Annotations, 2)
+	assert.Equal(t, "Networking answers are weak.", stored.Annotations[0].Text)
+}
+
+func TestAddAnnotationUnknownModel(t *testing.T) {
+	runDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(runDir, "resultset.json"), []byte(`{"models": []}`), 0o644))
+
+	_, err := AddAnnotation(runDir, Annotation{Model: "llama", Text: "x"})
+	assert.ErrorContains(t, err, "not part of this run")
+}
+
+func TestAddAnnotationMissingRun(t *testing.T) {
+	_, err := AddAnnotation(t.TempDir(), Annotation{Text: "x"})
+	assert.ErrorContains(t, err, "failed to read run metadata")
+}
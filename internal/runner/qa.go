@@ -24,14 +24,17 @@ func (s *QAStrategy) LoadQuestions(suite *testsuite.TestSuite) ([]testsuite.Ques
 	return suite.Questions, nil
 }
 
-func (s *QAStrategy) Execute(ctx context.Context, client llm.Client, model string, question testsuite.Question, systemPrompt string, temperature float64) (*testsuite.Result, error) {
+func (s *QAStrategy) Execute(ctx context.Context, client llm.Client, model testsuite.Model, question testsuite.Question, prompt Prompt) (*testsuite.Result, error) {
 	start := time.Now()
 
 	resp, err := client.ChatCompletion(ctx, llm.ChatRequest{
-		Model:         model,
-		SystemMessage: systemPrompt,
-		UserMessage:   question.QuestionText,
-		Temperature:   llm.Float64Ptr(temperature),
+		Model:           model.Name,
+		SystemMessage:   prompt.SystemMessage,
+		Messages:        prompt.Messages(),
+		UserMessage:     question.QuestionText,
+		Temperature:     llm.Float64Ptr(model.Temperature),
+		ReasoningEffort: model.ReasoningEffort,
+		ExtraParams:     model.ExtraParams,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get completion for question %s: %w", question.ID, err)
@@ -41,6 +44,10 @@ func (s *QAStrategy) Execute(ctx context.Context, client llm.Client, model strin
 		Question: question,
 		Answer:   resp.Content,
 		Duration: time.Since(start),
+
+		PromptTokens:     resp.Usage.PromptTokens,
+		CompletionTokens: resp.Usage.CompletionTokens,
+		FinishReason:     resp.FinishReason,
 	}, nil
 }
 
@@ -49,8 +56,16 @@ func (s *QAStrategy) FormatResults(results []*testsuite.Result) string {
 	for _, r := range results {
 		fmt.Fprintf(&b, "---\n")
 		fmt.Fprintf(&b, "NO. %s - %s\n", r.Question.ID, r.Question.Section)
+		if r.Question.Difficulty != "" {
+			fmt.Fprintf(&b, "DIFFICULTY: %s\n", r.Question.Difficulty)
+		}
+		if len(r.Question.Tags) > 0 {
+			fmt.Fprintf(&b, "TAGS: %s\n", strings.Join(r.Question.Tags, ", "))
+		}
 		fmt.Fprintf(&b, "QUESTION: %s\n", r.Question.QuestionText)
-		fmt.Fprintf(&b, "EXPECTED ANSWER: %s\n", r.Question.ExpectedAnswer)
+		if r.Question.ExpectedAnswer != "" {
+			fmt.Fprintf(&b, "EXPECTED ANSWER: %s\n", r.Question.ExpectedAnswer)
+		}
 		fmt.Fprintf(&b, "ACTUAL ANSWER: %s\n", r.Answer)
 	}
 	return b.String()
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/giantswarm/llm-testing/internal/llm"
 	"github.com/giantswarm/llm-testing/internal/testsuite"
 	"github.com/giantswarm/llm-testing/internal/testutil"
 )
@@ -70,7 +71,7 @@ func TestQAStrategyExecute(t *testing.T) {
 		ExpectedAnswer: "Smallest deployable unit",
 	}
 
-	result, err := s.Execute(context.Background(), client, "test-model", question, "You are helpful.", 0.0)
+	result, err := s.Execute(context.Background(), client, testsuite.Model{Name: "test-model"}, question, Prompt{SystemMessage: "You are helpful."})
 	require.NoError(t, err)
 	assert.Equal(t, "42", result.Question.ID)
 	assert.Equal(t, "mock answer for: What is a Pod?", result.Answer)
@@ -86,7 +87,48 @@ func TestQAStrategyExecutePassesSystemPrompt(t *testing.T) {
 		QuestionText: "test",
 	}
 
-	_, err := s.Execute(context.Background(), client, "model", question, "custom system prompt", 0.5)
+	_, err := s.Execute(context.Background(), client, testsuite.Model{Name: "model", Temperature: 0.5}, question, Prompt{SystemMessage: "custom system prompt"})
 	require.NoError(t, err)
 	assert.Equal(t, "custom system prompt", client.LastRequest.SystemMessage)
 }
+
+func TestQAStrategyExecuteSendsExamples(t *testing.T) {
+	s := &QAStrategy{}
+	client := &testutil.MockLLMClient{}
+	prompt := Prompt{
+		SystemMessage: "Answer with a kubectl command.",
+		Examples: []testsuite.Example{
+			{Question: "List pods?", Answer: "kubectl get pods"},
+			{Question: "List nodes?", Answer: "kubectl get nodes"},
+		},
+	}
+
+	_, err := s.Execute(context.Background(), client, testsuite.Model{Name: "model"}, testsuite.Question{ID: "1", QuestionText: "List services?"}, prompt)
+	require.NoError(t, err)
+	assert.Equal(t, []llm.Message{
+		{Role: llm.RoleSystem, Content: "Answer with a kubectl command."},
+		{Role: llm.RoleUser, Content: "List pods?"},
+		{Role: llm.RoleAssistant, Content: "kubectl get pods"},
+		{Role: llm.RoleUser, Content: "List nodes?"},
+		{Role: llm.RoleAssistant, Content: "kubectl get nodes"},
+		{Role: llm.RoleUser, Content: "List services?"},
+	}, client.LastRequest.Conversation())
+}
+
+func TestQAStrategyExecutePassesModelSettings(t *testing.T) {
+	s := &QAStrategy{}
+	client := &testutil.MockLLMClient{}
+	model := testsuite.Model{
+		Name:            "deepseek-r1",
+		Temperature:     0.6,
+		ReasoningEffort: llm.ReasoningEffortHigh,
+		ExtraParams:     map[string]any{"top_k": 20},
+	}
+
+	_, err := s.Execute(context.Background(), client, model, testsuite.Question{ID: "1", QuestionText: "test"}, Prompt{})
+	require.NoError(t, err)
+	assert.Equal(t, "deepseek-r1", client.LastRequest.Model)
+	assert.Equal(t, 0.6, *client.LastRequest.Temperature)
+	assert.Equal(t, llm.ReasoningEffortHigh, client.LastRequest.ReasoningEffort)
+	assert.Equal(t, map[string]any{"top_k": 20}, client.LastRequest.ExtraParams)
+}
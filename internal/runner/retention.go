@@ -0,0 +1,127 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DeletedRun is a run removed from the output directory.
+type DeletedRun struct {
+	ID        string    `json:"run_id"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ParseAge parses an age such as "30d", "36h", or "90m": a number of days,
+// or a Go duration.
+func ParseAge(s string) (time.Duration, error) {
+	var age time.Duration
+	var err error
+	if days, ok := strings.CutSuffix(strings.TrimSpace(s), "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		age = time.Duration(n) * 24 * time.Hour
+	} else {
+		age, err = time.ParseDuration(s)
+	}
+	if err != nil || age <= 0 {
+		return 0, fmt.Errorf("invalid age %q: must be a positive number of days (e.g. '30d') or a duration (e.g. '12h')", s)
+	}
+	return age, nil
+}
+
+// DeleteRun removes the directory of a completed run, i.e. one with a
+// resultset.json, and returns the run. Other directories, e.g. of runs in
+// progress or the score history, are left alone. With dryRun, the run is
+// only returned.
+func DeleteRun(runDir string, dryRun bool) (DeletedRun, error) {
+	run := DeletedRun{ID: filepath.Base(runDir)}
+	data, err := os.ReadFile(filepath.Join(runDir, "resultset.json"))
+	if err != nil {
+		return run, fmt.Errorf("%s is not a completed test run: %w", run.ID, err)
+	}
+	var metadata struct {
+		Timestamp time.Time `json:"timestamp"`
+	}
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return run, fmt.Errorf("failed to parse run metadata of %s: %w", run.ID, err)
+	}
+	run.Timestamp = metadata.Timestamp
+
+	if !dryRun {
+		if err := os.RemoveAll(runDir); err != nil {
+			return run, fmt.Errorf("failed to delete run %s: %w", run.ID, err)
+		}
+	}
+	return run, nil
+}
+
+// DeleteRunsOlderThan removes the completed runs in outputDir that started
+// more than age ago, oldest first, and returns them. With dryRun, the runs
+// are only returned.
+func DeleteRunsOlderThan(outputDir string, age time.Duration, dryRun bool) ([]DeletedRun, error) {
+	entries, err := os.ReadDir(outputDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read results directory: %w", err)
+	}
+
+	cutoff := time.Now().Add(-age)
+	var expired []DeletedRun
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		run, err := DeleteRun(filepath.Join(outputDir, e.Name()), true)
+		if err != nil {
+			continue
+		}
+		if !run.Timestamp.IsZero() && run.Timestamp.Before(cutoff) {
+			expired = append(expired, run)
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool { return expired[i].Timestamp.Before(expired[j].Timestamp) })
+
+	if dryRun {
+		return expired, nil
+	}
+	deleted := make([]DeletedRun, 0, len(expired))
+	for _, run := range expired {
+		if _, err := DeleteRun(filepath.Join(outputDir, run.ID), false); err != nil {
+			return deleted, err
+		}
+		deleted = append(deleted, run)
+	}
+	return deleted, nil
+}
+
+// RunRetention deletes the runs in outputDir older than retention every
+// interval until ctx is done.
+func RunRetention(ctx context.Context, outputDir string, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := DeleteRunsOlderThan(outputDir, retention, false)
+		if err != nil {
+			slog.Warn("failed to delete expired test runs", "error", err)
+		}
+		for _, run := range deleted {
+			slog.Info("deleted expired test run", "run_id", run.ID, "timestamp", run.Timestamp)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
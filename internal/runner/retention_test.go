@@ -0,0 +1,64 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeRun(t *testing.T, outputDir, id string, started time.Time) {
+	t.Helper()
+	dir := filepath.Join(outputDir, id)
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	metadata := fmt.Sprintf(`{"id": %q, "timestamp": %q}`, id, started.Format(time.RFC3339))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "resultset.json"), []byte(metadata), 0o644))
+}
+
+func TestParseAge(t *testing.T) {
+	age, err := ParseAge("30d")
+	require.NoError(t, err)
+	assert.Equal(t, 30*24*time.Hour, age)
+
+	age, err = ParseAge("12h")
+	require.NoError(t, err)
+	assert.Equal(t, 12*time.Hour, age)
+
+	for _, invalid := range []string{"", "0d", "-1d", "d", "soon"} {
+		_, err := ParseAge(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestDeleteRunsOlderThan(t *testing.T) {
+	outputDir := t.TempDir()
+	now := time.Now()
+	writeRun(t, outputDir, "old", now.Add(-40*24*time.Hour))
+	writeRun(t, outputDir, "older", now.Add(-50*24*time.Hour))
+	writeRun(t, outputDir, "recent", now.Add(-time.Hour))
+	// Directories without a resultset.json, e.g. the score history or a run
+	// in progress, are never deleted.
+	require.NoError(t, os.MkdirAll(filepath.Join(outputDir, "history"), 0o755))
+
+	deleted, err := DeleteRunsOlderThan(outputDir, 30*24*time.Hour, true)
+	require.NoError(t, err)
+	require.Len(t, deleted, 2)
+	assert.Equal(t, "older", deleted[0].ID)
+	assert.DirExists(t, filepath.Join(outputDir, "older"))
+
+	deleted, err = DeleteRunsOlderThan(outputDir, 30*24*time.Hour, false)
+	require.NoError(t, err)
+	assert.Len(t, deleted, 2)
+	assert.NoDirExists(t, filepath.Join(outputDir, "old"))
+	assert.NoDirExists(t, filepath.Join(outputDir, "older"))
+	assert.DirExists(t, filepath.Join(outputDir, "recent"))
+	assert.DirExists(t, filepath.Join(outputDir, "history"))
+
+	_, err = DeleteRun(filepath.Join(outputDir, "history"), false)
+	assert.ErrorContains(t, err, "history is not a completed test run")
+	assert.DirExists(t, filepath.Join(outputDir, "history"))
+}
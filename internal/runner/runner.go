@@ -2,16 +2,22 @@ package runner
 
 import (
 	"context"
+	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"math"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+
 	"github.com/giantswarm/llm-testing/internal/llm"
 	"github.com/giantswarm/llm-testing/internal/testsuite"
+	"github.com/giantswarm/llm-testing/internal/tracing"
 )
 
 // ProgressFunc is called to report progress during test execution.
@@ -24,18 +30,42 @@ type ProgressFunc func(model string, questionIndex, totalQuestions int)
 // pointing to its endpoint.
 type ClientForModelFunc func(ctx context.Context, model testsuite.Model) (llm.Client, error)
 
+// diagnosticError is implemented by errors carrying a post-mortem report,
+// such as kserve.DeployError.
+type diagnosticError interface {
+	error
+	DiagnosticReport() string
+}
+
 // AfterModelFunc is called after a model's evaluation completes (or fails).
 // Use this to tear down resources like KServe InferenceServices.
 type AfterModelFunc func(ctx context.Context, model testsuite.Model) error
 
+// DeploymentFunc returns a record of how a model was deployed for the run,
+// or nil if it was not deployed. It is called after the model's client was
+// obtained, and the record is saved as JSON in the run directory.
+type DeploymentFunc func(ctx context.Context, model testsuite.Model) interface{}
+
+// JudgeFunc evaluates a single result as soon as it is produced and reports
+// whether the answer is correct. Setting it enables the interleaved run+score mode.
+type JudgeFunc func(ctx context.Context, result *testsuite.Result) (bool, error)
+
+// ScoreProgressFunc is called after each judged answer with the model's running accuracy.
+type ScoreProgressFunc func(model string, score testsuite.LiveScore)
+
 // Runner orchestrates the execution of test suites.
 type Runner struct {
 	client         llm.Client         // default client (used when clientForModel is nil)
 	clientForModel ClientForModelFunc // optional: per-model client factory (deploy + endpoint discovery)
 	afterModel     AfterModelFunc     // optional: called after each model (teardown)
+	deployment     DeploymentFunc     // optional: record of each model's deployment
 	strategy       EvaluationStrategy
 	outputDir      string
 	progress       ProgressFunc
+	judge          JudgeFunc         // optional: judge each answer immediately
+	scoreProgress  ScoreProgressFunc // optional: running accuracy while judging
+	transcripts    bool              // record LLM requests in transcripts/
+	runID          string            // optional: ID of the run instead of a generated one
 }
 
 // NewRunner creates a new test runner with a default LLM client.
@@ -52,6 +82,18 @@ func (r *Runner) SetProgressFunc(fn ProgressFunc) {
 	r.progress = fn
 }
 
+// SetJudgeFunc enables judging each answer immediately after it is produced.
+// The running accuracy is reported via the ScoreProgressFunc and recorded
+// in the model's LiveScore.
+func (r *Runner) SetJudgeFunc(fn JudgeFunc) {
+	r.judge = fn
+}
+
+// SetScoreProgressFunc sets the running accuracy callback used while judging.
+func (r *Runner) SetScoreProgressFunc(fn ScoreProgressFunc) {
+	r.scoreProgress = fn
+}
+
 // SetClientForModelFunc sets the per-model client factory.
 // When set, this is called before each model's evaluation to obtain
 // a client configured for that model's endpoint.
@@ -66,10 +108,85 @@ func (r *Runner) SetAfterModelFunc(fn AfterModelFunc) {
 	r.afterModel = fn
 }
 
+// SetTranscripts records the LLM requests of each question, and of judging
+// its answer, in the transcripts/ directory of the run. The clients must
+// log their requests with llm.TranscriptLogger.
+func (r *Runner) SetTranscripts(enabled bool) {
+	r.transcripts = enabled
+}
+
+// SetDeploymentFunc sets the deployment record callback. Records are saved
+// to <model>_deployment.json in the run directory.
+func (r *Runner) SetDeploymentFunc(fn DeploymentFunc) {
+	r.deployment = fn
+}
+
+// SetRunID sets the ID, and so the output directory, of the next run, e.g.
+// to report it before the run starts. By default it is NewRunID of the
+// suite name and start time.
+func (r *Runner) SetRunID(id string) {
+	r.runID = id
+}
+
+// NewRunID returns the ID of a run of the named suite started at t. A random
+// suffix tells apart the runs started within the same second.
+func NewRunID(suiteName string, t time.Time) string {
+	suffix := make([]byte, 3)
+	_, _ = rand.Read(suffix)
+	return fmt.Sprintf("%s_%s-%x", strings.ReplaceAll(suiteName, " ", "_"), t.Format("20060102-150405"), suffix)
+}
+
+// writeDeployment saves the deployment record of a model to
+// <model>_deployment.json in the run directory and returns its path.
+func writeDeployment(outputPath, modelName string, record interface{}) (string, error) {
+	data, err := json.MarshalIndent(record, "", "    ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal deployment record: %w", err)
+	}
+	path := filepath.Join(outputPath, sanitizeFilename(modelName)+"_deployment.json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", fmt.Errorf("failed to write deployment record: %w", err)
+	}
+	return path, nil
+}
+
+// writeDiagnostics saves the diagnostic report of a failed model preparation
+// to <model>_diagnostics.log in the run directory.
+func writeDiagnostics(outputPath, modelName string, err error) {
+	var diagErr diagnosticError
+	if !errors.As(err, &diagErr) {
+		return
+	}
+	report := diagErr.DiagnosticReport()
+	if report == "" {
+		return
+	}
+	path := filepath.Join(outputPath, sanitizeFilename(modelName)+"_diagnostics.log")
+	if err := os.WriteFile(path, []byte(report), 0o644); err != nil {
+		slog.Warn("failed to write diagnostics", "model", modelName, "error", err)
+		return
+	}
+	slog.Info("deployment diagnostics written", "model", modelName, "path", path)
+}
+
 // Run executes a test suite for the given models and writes results.
 // Models are processed sequentially -- important for GPU memory constraints
 // when models are deployed/torn down via KServe between evaluations.
 func (r *Runner) Run(ctx context.Context, suite *testsuite.TestSuite, models []testsuite.Model) (*testsuite.TestRun, error) {
+	ctx, span := tracing.Start(ctx, "test_run",
+		tracing.Suite.String(suite.Name),
+		attribute.Int("llm_testing.models", len(models)),
+	)
+	run, err := r.run(ctx, suite, models)
+	if run != nil {
+		span.SetAttributes(tracing.RunID.String(run.ID), attribute.Bool("llm_testing.cancelled", run.Cancelled))
+	}
+	tracing.End(span, err)
+	return run, err
+}
+
+// run executes Run in the span of the test run.
+func (r *Runner) run(ctx context.Context, suite *testsuite.TestSuite, models []testsuite.Model) (*testsuite.TestRun, error) {
 	if len(models) == 0 {
 		return nil, fmt.Errorf("no models specified for test run")
 	}
@@ -80,8 +197,10 @@ func (r *Runner) Run(ctx context.Context, suite *testsuite.TestSuite, models []t
 	}
 
 	timestamp := time.Now()
-	sanitizedName := strings.ReplaceAll(suite.Name, " ", "_")
-	runID := fmt.Sprintf("%s_%s", sanitizedName, timestamp.Format("20060102-150405"))
+	runID := r.runID
+	if runID == "" {
+		runID = NewRunID(suite.Name, timestamp)
+	}
 
 	// Create output directory.
 	outputPath := filepath.Join(r.outputDir, runID)
@@ -90,36 +209,78 @@ func (r *Runner) Run(ctx context.Context, suite *testsuite.TestSuite, models []t
 	}
 
 	run := &testsuite.TestRun{
-		ID:        runID,
-		Suite:     suite.Name,
-		Timestamp: timestamp,
-		Models:    make([]testsuite.ModelRun, 0, len(models)),
+		ID:           runID,
+		Suite:        suite.Name,
+		Timestamp:    timestamp,
+		Models:       make([]testsuite.ModelRun, 0, len(models)),
+		SuiteVersion: suite.Version,
+		SuiteHash:    suite.ContentHash(),
+		Profile:      suite.Profile,
+		Examples:     len(suite.Examples),
+		Blind:        suite.Blind,
+	}
+	if !suite.Weights.IsZero() {
+		run.Weights = &suite.Weights
+	}
+	if run.Blind {
+		if err := testsuite.WriteAnswerKey(outputPath, questions); err != nil {
+			return nil, err
+		}
 	}
 
-	systemPrompt := suite.Prompt.SystemMessage
+	prompt := Prompt{SystemMessage: suite.Prompt.SystemMessage, Examples: suite.Examples}
 
 	for _, model := range models {
 		// Check for context cancellation between models.
 		if err := ctx.Err(); err != nil {
 			slog.Warn("test run cancelled before model evaluation", "model", model.Name)
+			run.Cancelled = true
 			break
 		}
 
+		ctx, modelSpan := tracing.Start(ctx, "test_run.model", tracing.Model.String(model.Name))
+
 		// Determine the LLM client for this model.
 		client := r.client
 		if r.clientForModel != nil {
 			var err error
-			client, err = r.clientForModel(ctx, model)
+			prepareCtx, prepareSpan := tracing.Start(ctx, "test_run.prepare_model", tracing.Model.String(model.Name))
+			client, err = r.clientForModel(prepareCtx, model)
+			tracing.End(prepareSpan, err)
+			if err != nil && ctx.Err() != nil {
+				// Cancelled while preparing, e.g. deploying, the model: keep
+				// the results of the models before it.
+				slog.Warn("test run cancelled while preparing model", "model", model.Name, "error", err)
+				if r.afterModel != nil {
+					_ = r.afterModel(ctx, model)
+				}
+				run.Cancelled = true
+				tracing.End(modelSpan, err)
+				break
+			}
 			if err != nil {
 				slog.Error("failed to get client for model", "model", model.Name, "error", err)
+				writeDiagnostics(outputPath, model.Name, err)
 				// If we have an afterModel hook, call it to clean up.
 				if r.afterModel != nil {
 					_ = r.afterModel(ctx, model)
 				}
+				tracing.End(modelSpan, err)
 				return nil, fmt.Errorf("failed to prepare model %s: %w", model.Name, err)
 			}
 		}
 
+		var deploymentFile string
+		if r.deployment != nil {
+			if record := r.deployment(ctx, model); record != nil {
+				path, err := writeDeployment(outputPath, model.Name, record)
+				if err != nil {
+					slog.Warn("failed to save deployment record", "model", model.Name, "error", err)
+				}
+				deploymentFile = path
+			}
+		}
+
 		slog.Info("running test suite",
 			"model", model.Name,
 			"questions", len(questions),
@@ -128,6 +289,11 @@ func (r *Runner) Run(ctx context.Context, suite *testsuite.TestSuite, models []t
 
 		modelStart := time.Now()
 		var results []*testsuite.Result
+		tracker := &failureTracker{retriesLeft: model.MaxRetries, errors: make(map[string]int)}
+		var liveScore *testsuite.LiveScore
+		if r.judge != nil {
+			liveScore = &testsuite.LiveScore{}
+		}
 
 		for i, q := range questions {
 			// Check for context cancellation between questions.
@@ -140,37 +306,67 @@ func (r *Runner) Run(ctx context.Context, suite *testsuite.TestSuite, models []t
 				r.progress(model.Name, i+1, len(questions))
 			}
 
-			result, err := r.strategy.Execute(ctx, client, model.Name, q, systemPrompt, model.Temperature)
+			// Questions are bulk traffic, sharing the rate limits of a
+			// provider with judges by priority (see llm.RateLimiter).
+			qctx := r.transcript(llm.WithPriority(ctx, llm.PriorityBulk), outputPath, model.Name, q.ID)
+			qctx, questionSpan := tracing.Start(qctx, "test_run.question", tracing.QuestionID.String(q.ID))
+			result, err := r.executeWithRetry(qctx, client, model, q, prompt, tracker)
+			tracing.End(questionSpan, err)
 			if err != nil {
 				slog.Error("question execution failed",
 					"question_id", q.ID,
+					"error_class", llm.ClassifyError(err),
 					"error", err,
 				)
 				// Continue with next question on error.
 				continue
 			}
 			results = append(results, result)
+
+			if r.judge != nil {
+				r.judgeResult(r.transcript(ctx, outputPath, model.Name, q.ID+"-judge"), model.Name, result, liveScore)
+			}
+		}
+		if ctx.Err() != nil {
+			run.Cancelled = true
 		}
 
 		// Write results file.
-		output := r.strategy.FormatResults(results)
+		formatted := results
+		if run.Blind {
+			formatted = withoutExpectedAnswers(results)
+		}
+		output := r.strategy.FormatResults(formatted)
 		safeModelName := sanitizeFilename(model.Name)
 		resultsFile := filepath.Join(outputPath, fmt.Sprintf("%s.txt", safeModelName))
 		if err := os.WriteFile(resultsFile, []byte(output), 0o644); err != nil {
+			tracing.End(modelSpan, err)
 			return nil, fmt.Errorf("failed to write results for model %s: %w", model.Name, err)
 		}
+		if err := testsuite.WriteQuestionRecords(resultsFile, results); err != nil {
+			slog.Warn("failed to write question records", "model", model.Name, "error", err)
+		}
 
 		modelRun := testsuite.ModelRun{
 			ModelName:   model.Name,
+			ModelURI:    model.ModelURI,
 			Duration:    time.Since(modelStart),
 			ResultsFile: resultsFile,
 			Results:     results,
+			Errors:      tracker.errors,
+			Retries:     tracker.retries,
+			LiveScore:   liveScore,
+			Usage:       tokenUsage(results),
+
+			DeploymentFile: deploymentFile,
 		}
 		run.Models = append(run.Models, modelRun)
 
 		slog.Info("model evaluation complete",
 			"model", model.Name,
 			"questions_answered", len(results),
+			"errors", modelRun.Errors,
+			"retries", modelRun.Retries,
 			"duration", modelRun.Duration,
 		)
 
@@ -181,6 +377,7 @@ func (r *Runner) Run(ctx context.Context, suite *testsuite.TestSuite, models []t
 				// Continue with next model; don't fail the entire run.
 			}
 		}
+		tracing.End(modelSpan, nil)
 	}
 
 	run.Duration = time.Since(timestamp)
@@ -193,6 +390,104 @@ func (r *Runner) Run(ctx context.Context, suite *testsuite.TestSuite, models []t
 	return run, nil
 }
 
+// transcript returns the context of a request recorded as name in the
+// model's transcripts, if transcripts are enabled.
+func (r *Runner) transcript(ctx context.Context, outputPath, model, name string) context.Context {
+	if !r.transcripts {
+		return ctx
+	}
+	return llm.WithTranscript(ctx, filepath.Join(outputPath, "transcripts", sanitizeFilename(model)), sanitizeFilename(name))
+}
+
+// tokenUsage sums the token usage of answers, or returns nil if the API
+// reported none.
+func tokenUsage(results []*testsuite.Result) *testsuite.TokenUsage {
+	var usage testsuite.TokenUsage
+	for _, r := range results {
+		usage.PromptTokens += r.PromptTokens
+		usage.CompletionTokens += r.CompletionTokens
+		if r.FinishReason == llm.FinishReasonLength {
+			usage.Truncated++
+		}
+	}
+	if usage == (testsuite.TokenUsage{}) {
+		return nil
+	}
+	usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	return &usage
+}
+
+// judgeResult judges a single answer, updates the running score, and reports it.
+// Judge failures are counted but never abort the run.
+func (r *Runner) judgeResult(ctx context.Context, model string, result *testsuite.Result, score *testsuite.LiveScore) {
+	correct, err := r.judge(ctx, result)
+	if err != nil {
+		slog.Warn("judging answer failed", "model", model, "question_id", result.Question.ID, "error", err)
+		score.Failed++
+	} else {
+		result.Verdict = &correct
+		score.Judged++
+		if correct {
+			score.Correct++
+		}
+		score.Percent = math.Round(float64(score.Correct)/float64(score.Judged)*10000) / 100
+	}
+
+	if r.scoreProgress != nil {
+		r.scoreProgress(model, *score)
+	}
+}
+
+// failureTracker records per-model failures and the remaining retry budget.
+type failureTracker struct {
+	retriesLeft int
+	retries     int
+	errors      map[string]int
+}
+
+// executeWithRetry runs a single question, retrying transient failures
+// (timeouts and 5xx responses) while the model's retry budget lasts.
+// Questions that ultimately fail are counted by error class.
+func (r *Runner) executeWithRetry(ctx context.Context, client llm.Client, model testsuite.Model, q testsuite.Question, prompt Prompt, tracker *failureTracker) (*testsuite.Result, error) {
+	for {
+		result, err := r.strategy.Execute(ctx, client, model, q, prompt)
+		if err == nil {
+			return result, nil
+		}
+
+		class := llm.ClassifyError(err)
+		if class.Retryable() && tracker.retriesLeft > 0 && ctx.Err() == nil {
+			tracker.retriesLeft--
+			tracker.retries++
+			slog.Warn("retrying question after transient failure",
+				"model", model.Name,
+				"question_id", q.ID,
+				"error_class", class,
+				"retries_left", tracker.retriesLeft,
+			)
+			continue
+		}
+
+		// An answer cut short by cancelling the run is not the model's error.
+		if ctx.Err() == nil {
+			tracker.errors[string(class)]++
+		}
+		return nil, err
+	}
+}
+
+// withoutExpectedAnswers returns copies of results without their expected
+// answers, for the results files of blind runs.
+func withoutExpectedAnswers(results []*testsuite.Result) []*testsuite.Result {
+	withheld := make([]*testsuite.Result, len(results))
+	for i, r := range results {
+		c := *r
+		c.Question.ExpectedAnswer = ""
+		withheld[i] = &c
+	}
+	return withheld
+}
+
 // sanitizeFilename replaces characters unsafe for filenames with underscores.
 func sanitizeFilename(name string) string {
 	replacer := strings.NewReplacer(
@@ -212,11 +507,26 @@ func sanitizeFilename(name string) string {
 func writeRunMetadata(outputPath string, run *testsuite.TestRun) error {
 	models := make([]map[string]interface{}, 0, len(run.Models))
 	for _, m := range run.Models {
-		models = append(models, map[string]interface{}{
+		entry := map[string]interface{}{
 			"model_name":   m.ModelName,
 			"duration":     m.Duration.Seconds(),
 			"results_file": m.ResultsFile,
-		})
+			"errors":       m.Errors,
+			"retries":      m.Retries,
+		}
+		if m.ModelURI != "" {
+			entry["model_uri"] = m.ModelURI
+		}
+		if m.LiveScore != nil {
+			entry["live_score"] = m.LiveScore
+		}
+		if m.DeploymentFile != "" {
+			entry["deployment_file"] = m.DeploymentFile
+		}
+		if m.Usage != nil {
+			entry["usage"] = m.Usage
+		}
+		models = append(models, entry)
 	}
 
 	metadata := map[string]interface{}{
@@ -226,6 +536,27 @@ func writeRunMetadata(outputPath string, run *testsuite.TestRun) error {
 		"full_duration": run.Duration.Seconds(),
 		"models":        models,
 	}
+	if run.Weights != nil {
+		metadata["weights"] = run.Weights
+	}
+	if run.SuiteVersion != "" {
+		metadata["suite_version"] = run.SuiteVersion
+	}
+	if run.SuiteHash != "" {
+		metadata["suite_hash"] = run.SuiteHash
+	}
+	if run.Profile != "" {
+		metadata["profile"] = run.Profile
+	}
+	if run.Examples > 0 {
+		metadata["examples"] = run.Examples
+	}
+	if run.Blind {
+		metadata["blind"] = true
+	}
+	if run.Cancelled {
+		metadata["cancelled"] = true
+	}
 
 	data, err := json.MarshalIndent(metadata, "", "    ")
 	if err != nil {
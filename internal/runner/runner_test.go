@@ -2,14 +2,20 @@ package runner
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/sashabaranov/go-openai"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/giantswarm/llm-testing/internal/llm"
 	"github.com/giantswarm/llm-testing/internal/testsuite"
 	"github.com/giantswarm/llm-testing/internal/testutil"
 )
@@ -56,6 +62,80 @@ func TestRunnerExecutesSuite(t *testing.T) {
 	assert.FileExists(t, metadataFile)
 }
 
+func TestRunnerTokenUsage(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	client := &testutil.MockLLMClient{
+		Usage:        llm.Usage{PromptTokens: 100, CompletionTokens: 20, TotalTokens: 120},
+		FinishReason: llm.FinishReasonLength,
+	}
+	strategy, _ := GetStrategy("qa")
+	r := NewRunner(client, strategy, tmpDir)
+
+	suite := &testsuite.TestSuite{
+		Name:     "usage",
+		Strategy: "qa",
+		Questions: []testsuite.Question{
+			{ID: "1", Section: "S", QuestionText: "Q1?", ExpectedAnswer: "A"},
+			{ID: "2", Section: "S", QuestionText: "Q2?", ExpectedAnswer: "A"},
+		},
+	}
+
+	run, err := r.Run(context.Background(), suite, []testsuite.Model{{Name: "m"}})
+	require.NoError(t, err)
+
+	require.NotNil(t, run.Models[0].Usage)
+	assert.Equal(t, testsuite.TokenUsage{PromptTokens: 200, CompletionTokens: 40, TotalTokens: 240, Truncated: 2}, *run.Models[0].Usage)
+	assert.Equal(t, llm.FinishReasonLength, run.Models[0].Results[0].FinishReason)
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, run.ID, "resultset.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"total_tokens": 240`)
+}
+
+func TestRunnerTranscripts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`)
+	}))
+	defer srv.Close()
+
+	tmpDir := t.TempDir()
+	client := llm.NewOpenAIClient(llm.WithBaseURL(srv.URL), llm.WithRequestLogger(llm.TranscriptLogger))
+	strategy, _ := GetStrategy("qa")
+	r := NewRunner(client, strategy, tmpDir)
+	r.SetTranscripts(true)
+	r.SetJudgeFunc(func(ctx context.Context, result *testsuite.Result) (bool, error) {
+		_, err := client.ChatCompletion(ctx, llm.ChatRequest{Model: "judge", UserMessage: "judge"})
+		return true, err
+	})
+
+	suite := &testsuite.TestSuite{
+		Name:      "transcripts",
+		Strategy:  "qa",
+		Questions: []testsuite.Question{{ID: "1", Section: "S", QuestionText: "Q?", ExpectedAnswer: "A"}},
+	}
+	run, err := r.Run(context.Background(), suite, []testsuite.Model{{Name: "org/model"}})
+	require.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, run.ID, "transcripts", "org_model")
+	assert.FileExists(t, filepath.Join(dir, "1.json"))
+	assert.FileExists(t, filepath.Join(dir, "1-judge.json"))
+}
+
+func TestRunnerNoTokenUsage(t *testing.T) {
+	strategy, _ := GetStrategy("qa")
+	r := NewRunner(&testutil.MockLLMClient{}, strategy, t.TempDir())
+	suite := &testsuite.TestSuite{
+		Name:      "usage",
+		Strategy:  "qa",
+		Questions: []testsuite.Question{{ID: "1", Section: "S", QuestionText: "Q?", ExpectedAnswer: "A"}},
+	}
+
+	run, err := r.Run(context.Background(), suite, []testsuite.Model{{Name: "m"}})
+	require.NoError(t, err)
+	assert.Nil(t, run.Models[0].Usage, "servers that report no usage leave it unset")
+}
+
 func TestRunnerMultipleModels(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -160,6 +240,92 @@ func TestRunnerContextCancellation(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestRunnerCancelledRunKeepsPartialResults(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	strategy, _ := GetStrategy("qa")
+	r := NewRunner(&testutil.MockLLMClient{DefaultResponse: "answer"}, strategy, tmpDir)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r.SetProgressFunc(func(model string, idx, total int) {
+		if idx == 3 {
+			cancel()
+		}
+	})
+	var teardownCalls []string
+	r.SetAfterModelFunc(func(ctx context.Context, model testsuite.Model) error {
+		teardownCalls = append(teardownCalls, model.Name)
+		return nil
+	})
+
+	suite := &testsuite.TestSuite{
+		Name:     "cancelled",
+		Strategy: "qa",
+		Questions: []testsuite.Question{
+			{ID: "1", Section: "S", QuestionText: "Q1", ExpectedAnswer: "A1"},
+			{ID: "2", Section: "S", QuestionText: "Q2", ExpectedAnswer: "A2"},
+			{ID: "3", Section: "S", QuestionText: "Q3", ExpectedAnswer: "A3"},
+			{ID: "4", Section: "S", QuestionText: "Q4", ExpectedAnswer: "A4"},
+		},
+	}
+
+	run, err := r.Run(ctx, suite, []testsuite.Model{{Name: "model-a"}, {Name: "model-b"}})
+	require.NoError(t, err)
+	assert.True(t, run.Cancelled)
+	require.Len(t, run.Models, 1)
+	assert.Len(t, run.Models[0].Results, 3)
+	assert.Equal(t, []string{"model-a"}, teardownCalls)
+
+	content, err := os.ReadFile(run.Models[0].ResultsFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "Q2")
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, run.ID, "resultset.json"))
+	require.NoError(t, err)
+	var meta struct {
+		Cancelled bool `json:"cancelled"`
+	}
+	require.NoError(t, json.Unmarshal(data, &meta))
+	assert.True(t, meta.Cancelled)
+}
+
+func TestRunnerCancelledWhilePreparingModel(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	strategy, _ := GetStrategy("qa")
+	r := NewRunner(&testutil.MockLLMClient{}, strategy, tmpDir)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r.SetClientForModelFunc(func(ctx context.Context, model testsuite.Model) (llm.Client, error) {
+		if model.Name == "model-b" {
+			cancel()
+			return nil, fmt.Errorf("failed to deploy model %q: %w", model.Name, ctx.Err())
+		}
+		return &testutil.MockLLMClient{}, nil
+	})
+	var teardownCalls []string
+	r.SetAfterModelFunc(func(ctx context.Context, model testsuite.Model) error {
+		teardownCalls = append(teardownCalls, model.Name)
+		return nil
+	})
+
+	suite := &testsuite.TestSuite{
+		Name:      "cancelled",
+		Strategy:  "qa",
+		Questions: []testsuite.Question{{ID: "1", Section: "S", QuestionText: "Q", ExpectedAnswer: "A"}},
+	}
+
+	run, err := r.Run(ctx, suite, []testsuite.Model{{Name: "model-a"}, {Name: "model-b"}})
+	require.NoError(t, err)
+	assert.True(t, run.Cancelled)
+	require.Len(t, run.Models, 1)
+	assert.Equal(t, "model-a", run.Models[0].ModelName)
+	assert.Equal(t, []string{"model-a", "model-b"}, teardownCalls)
+	assert.FileExists(t, filepath.Join(tmpDir, run.ID, "resultset.json"))
+}
+
 func TestRunnerDefaultFilename(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -191,6 +357,15 @@ func TestRunnerDefaultFilename(t *testing.T) {
 	assert.Contains(t, string(content), "NO. 1")
 }
 
+func TestNewRunID(t *testing.T) {
+	started := time.Date(2026, 2, 10, 12, 0, 0, 0, time.UTC)
+
+	id := NewRunID("Kubernetes CKA", started)
+	assert.Regexp(t, `^Kubernetes_CKA_20260210-120000-[0-9a-f]{6}$`, id)
+	// Runs started within the same second get different IDs.
+	assert.NotEqual(t, id, NewRunID("Kubernetes CKA", started))
+}
+
 func TestRunnerAfterModelHook(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -222,3 +397,262 @@ func TestRunnerAfterModelHook(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, []string{"model-a", "model-b"}, teardownCalls)
 }
+
+type diagnosedError struct{ report string }
+
+func (e *diagnosedError) Error() string            { return "not ready" }
+func (e *diagnosedError) DiagnosticReport() string { return e.report }
+
+func TestRunnerWritesDiagnosticsOnPrepareFailure(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	strategy, _ := GetStrategy("qa")
+	r := NewRunner(&testutil.MockLLMClient{}, strategy, tmpDir)
+	r.SetClientForModelFunc(func(ctx context.Context, model testsuite.Model) (llm.Client, error) {
+		return nil, fmt.Errorf("failed to deploy model %q: %w", model.Name, &diagnosedError{report: "Warning BackOff"})
+	})
+
+	suite := &testsuite.TestSuite{
+		Name:      "diag",
+		Strategy:  "qa",
+		Questions: []testsuite.Question{{ID: "1", Section: "S", QuestionText: "Q", ExpectedAnswer: "A"}},
+	}
+
+	_, err := r.Run(context.Background(), suite, []testsuite.Model{{Name: "org/model"}})
+	require.Error(t, err)
+
+	matches, err := filepath.Glob(filepath.Join(tmpDir, "*", "*_diagnostics.log"))
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	data, err := os.ReadFile(matches[0])
+	require.NoError(t, err)
+	assert.Equal(t, "Warning BackOff", string(data))
+}
+
+func TestRunnerWritesDeploymentRecord(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	strategy, _ := GetStrategy("qa")
+	r := NewRunner(&testutil.MockLLMClient{DefaultResponse: "A"}, strategy, tmpDir)
+	r.SetDeploymentFunc(func(_ context.Context, model testsuite.Model) interface{} {
+		if model.Name != "deployed" {
+			return nil
+		}
+		return map[string]string{"runtime": "kserve-vllm"}
+	})
+
+	suite := &testsuite.TestSuite{
+		Name:      "deployment",
+		Strategy:  "qa",
+		Questions: []testsuite.Question{{ID: "1", Section: "S", QuestionText: "Q", ExpectedAnswer: "A"}},
+	}
+
+	run, err := r.Run(context.Background(), suite, []testsuite.Model{{Name: "deployed"}, {Name: "external"}})
+	require.NoError(t, err)
+	require.Len(t, run.Models, 2)
+	assert.Empty(t, run.Models[1].DeploymentFile)
+
+	path := run.Models[0].DeploymentFile
+	assert.Equal(t, filepath.Join(tmpDir, run.ID, "deployed_deployment.json"), path)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"runtime": "kserve-vllm"}`, string(data))
+
+	metadata, err := os.ReadFile(filepath.Join(tmpDir, run.ID, "resultset.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(metadata), `"deployment_file": "`+path+`"`)
+}
+
+func TestRunnerRetriesAndClassifiesErrors(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	client := &testutil.MockLLMClient{
+		Errors: []error{
+			&openai.APIError{HTTPStatusCode: 503, Message: "overloaded"},
+			&openai.APIError{HTTPStatusCode: 503, Message: "overloaded"},
+			&openai.APIError{HTTPStatusCode: 400, Message: "This model's maximum context length is 4096 tokens"},
+		},
+	}
+	strategy, _ := GetStrategy("qa")
+	r := NewRunner(client, strategy, tmpDir)
+
+	suite := &testsuite.TestSuite{
+		Name:     "retries",
+		Strategy: "qa",
+		Prompt:   testsuite.Prompt{SystemMessage: "test"},
+		Questions: []testsuite.Question{
+			{ID: "1", Section: "S", QuestionText: "Q1", ExpectedAnswer: "A1"},
+			{ID: "2", Section: "S", QuestionText: "Q2", ExpectedAnswer: "A2"},
+			{ID: "3", Section: "S", QuestionText: "Q3", ExpectedAnswer: "A3"},
+		},
+	}
+
+	// Budget of one retry: Q1 fails with 503, is retried, fails again and is
+	// counted. Q2 fails with a non-retryable context-length error. Q3 succeeds.
+	models := []testsuite.Model{{Name: "flaky", MaxRetries: 1}}
+
+	run, err := r.Run(context.Background(), suite, models)
+	require.NoError(t, err)
+
+	m := run.Models[0]
+	assert.Len(t, m.Results, 1)
+	assert.Equal(t, 1, m.Retries)
+	assert.Equal(t, map[string]int{"5xx": 1, "context_length": 1}, m.Errors)
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, run.ID, "resultset.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"context_length": 1`)
+}
+
+func TestRunnerJudgesAnswersImmediately(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	client := &testutil.MockLLMClient{}
+	strategy, _ := GetStrategy("qa")
+	r := NewRunner(client, strategy, tmpDir)
+
+	r.SetJudgeFunc(func(_ context.Context, result *testsuite.Result) (bool, error) {
+		if result.Question.ID == "3" {
+			return false, fmt.Errorf("judge unavailable")
+		}
+		return result.Question.ID == "1", nil
+	})
+
+	var updates []testsuite.LiveScore
+	r.SetScoreProgressFunc(func(_ string, score testsuite.LiveScore) {
+		updates = append(updates, score)
+	})
+
+	suite := &testsuite.TestSuite{
+		Name:     "judge",
+		Strategy: "qa",
+		Prompt:   testsuite.Prompt{SystemMessage: "test"},
+		Questions: []testsuite.Question{
+			{ID: "1", Section: "S", QuestionText: "Q1", ExpectedAnswer: "A1"},
+			{ID: "2", Section: "S", QuestionText: "Q2", ExpectedAnswer: "A2"},
+			{ID: "3", Section: "S", QuestionText: "Q3", ExpectedAnswer: "A3"},
+		},
+	}
+
+	run, err := r.Run(context.Background(), suite, []testsuite.Model{{Name: "m"}})
+	require.NoError(t, err)
+
+	require.Len(t, updates, 3)
+	assert.Equal(t, testsuite.LiveScore{Judged: 1, Correct: 1, Percent: 100}, updates[0])
+	assert.Equal(t, testsuite.LiveScore{Judged: 2, Correct: 1, Percent: 50}, updates[1])
+	assert.Equal(t, 1, updates[2].Failed)
+
+	m := run.Models[0]
+	require.NotNil(t, m.LiveScore)
+	assert.Equal(t, 2, m.LiveScore.Judged)
+	assert.Equal(t, 1, m.LiveScore.Correct)
+	require.NotNil(t, m.Results[0].Verdict)
+	assert.True(t, *m.Results[0].Verdict)
+	assert.Nil(t, m.Results[2].Verdict)
+
+	records, err := testsuite.ReadQuestionRecords(m.ResultsFile)
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	require.NotNil(t, records["2"].Verdict)
+	assert.False(t, *records["2"].Verdict)
+	assert.Nil(t, records["3"].Verdict)
+}
+
+func TestRunnerRecordsSuiteWeights(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	strategy, err := GetStrategy("qa")
+	require.NoError(t, err)
+	r := NewRunner(&testutil.MockLLMClient{DefaultResponse: "answer"}, strategy, tmpDir)
+
+	suite := &testsuite.TestSuite{
+		Name:     "weighted",
+		Strategy: "qa",
+		Weights:  testsuite.Weights{Sections: map[string]float64{"Troubleshooting": 2}},
+		Questions: []testsuite.Question{
+			{ID: "1", Section: "Troubleshooting", QuestionText: "Q", ExpectedAnswer: "A"},
+		},
+	}
+
+	run, err := r.Run(context.Background(), suite, []testsuite.Model{{Name: "m"}})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, run.ID, "resultset.json"))
+	require.NoError(t, err)
+
+	var meta struct {
+		Weights *testsuite.Weights `json:"weights"`
+	}
+	require.NoError(t, json.Unmarshal(data, &meta))
+	require.NotNil(t, meta.Weights)
+	assert.Equal(t, 2.0, meta.Weights.For("1", "Troubleshooting"))
+}
+
+func TestRunnerRecordsSuiteMetadata(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	strategy, err := GetStrategy("qa")
+	require.NoError(t, err)
+	r := NewRunner(&testutil.MockLLMClient{DefaultResponse: "answer"}, strategy, tmpDir)
+
+	suite := &testsuite.TestSuite{
+		Name:      "versioned",
+		Version:   "2",
+		Strategy:  "qa",
+		Profiles:  map[string]testsuite.Prompt{"concise": {SystemMessage: "Be brief."}},
+		Examples:  []testsuite.Example{{Question: "Q0", Answer: "A0"}},
+		Questions: []testsuite.Question{{ID: "1", Section: "S", QuestionText: "Q", ExpectedAnswer: "A"}},
+	}
+	require.NoError(t, suite.SelectProfile("concise"))
+
+	run, err := r.Run(context.Background(), suite, []testsuite.Model{{Name: "m"}})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, run.ID, "resultset.json"))
+	require.NoError(t, err)
+
+	var meta struct {
+		SuiteVersion string `json:"suite_version"`
+		SuiteHash    string `json:"suite_hash"`
+		Profile      string `json:"profile"`
+		Examples     int    `json:"examples"`
+	}
+	require.NoError(t, json.Unmarshal(data, &meta))
+	assert.Equal(t, "2", meta.SuiteVersion)
+	assert.Equal(t, suite.ContentHash(), meta.SuiteHash)
+	assert.Equal(t, "concise", meta.Profile)
+	assert.Equal(t, "concise", run.Profile)
+	assert.Equal(t, 1, meta.Examples)
+}
+
+func TestRunnerBlindWritesAnswerKey(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	strategy, err := GetStrategy("qa")
+	require.NoError(t, err)
+	r := NewRunner(&testutil.MockLLMClient{DefaultResponse: "answer"}, strategy, tmpDir)
+
+	suite := &testsuite.TestSuite{
+		Name:     "secret",
+		Strategy: "qa",
+		Blind:    true,
+		Questions: []testsuite.Question{
+			{ID: "1", Section: "S", QuestionText: "Q1", ExpectedAnswer: "secret answer 1"},
+			{ID: "2", Section: "S", QuestionText: "Q2", ExpectedAnswer: "secret answer 2"},
+		},
+	}
+
+	run, err := r.Run(context.Background(), suite, []testsuite.Model{{Name: "m"}})
+	require.NoError(t, err)
+	assert.True(t, run.Blind)
+
+	results, err := os.ReadFile(run.Models[0].ResultsFile)
+	require.NoError(t, err)
+	assert.NotContains(t, string(results), "EXPECTED ANSWER")
+	assert.NotContains(t, string(results), "secret answer")
+	assert.Equal(t, "secret answer 1", run.Models[0].Results[0].Question.ExpectedAnswer, "in-memory results keep the answers")
+
+	key, err := testsuite.ReadAnswerKey(filepath.Join(tmpDir, run.ID))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"1": "secret answer 1", "2": "secret answer 2"}, key)
+}
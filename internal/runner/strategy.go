@@ -2,6 +2,7 @@ package runner
 
 import (
 	"context"
+	"io/fs"
 
 	"github.com/giantswarm/llm-testing/internal/llm"
 	"github.com/giantswarm/llm-testing/internal/testsuite"
@@ -17,12 +18,35 @@ type EvaluationStrategy interface {
 	LoadQuestions(suite *testsuite.TestSuite) ([]testsuite.Question, error)
 
 	// Execute runs a single question against the LLM and returns the result.
-	Execute(ctx context.Context, client llm.Client, model string, question testsuite.Question, systemPrompt string, temperature float64) (*testsuite.Result, error)
+	// The request settings come from the model, e.g. its temperature.
+	Execute(ctx context.Context, client llm.Client, model testsuite.Model, question testsuite.Question, prompt Prompt) (*testsuite.Result, error)
 
 	// FormatResults converts results into the output text format.
 	FormatResults(results []*testsuite.Result) string
 }
 
+// Prompt is what precedes each question: the system prompt and few-shot
+// examples.
+type Prompt struct {
+	SystemMessage string
+	Examples      []testsuite.Example
+}
+
+// Messages returns the examples as pairs of user and assistant messages.
+func (p Prompt) Messages() []llm.Message {
+	if len(p.Examples) == 0 {
+		return nil
+	}
+	messages := make([]llm.Message, 0, 2*len(p.Examples))
+	for _, ex := range p.Examples {
+		messages = append(messages,
+			llm.Message{Role: llm.RoleUser, Content: ex.Question},
+			llm.Message{Role: llm.RoleAssistant, Content: ex.Answer},
+		)
+	}
+	return messages
+}
+
 // GetStrategy returns an EvaluationStrategy for the given strategy name.
 func GetStrategy(name string) (EvaluationStrategy, error) {
 	switch name {
@@ -41,3 +65,16 @@ type UnsupportedStrategyError struct {
 func (e *UnsupportedStrategyError) Error() string {
 	return "unsupported evaluation strategy: " + e.Name
 }
+
+// ValidateSuite validates the test suite in fsys (see testsuite.Validate)
+// and checks that its strategy is supported.
+func ValidateSuite(fsys fs.FS) *testsuite.ValidationReport {
+	report := testsuite.Validate(fsys)
+	if report.Strategy == "" {
+		return report // config.yaml could not be read
+	}
+	if _, err := GetStrategy(report.Strategy); err != nil {
+		report.Add("config.yaml", 0, testsuite.SeverityError, "%v", err)
+	}
+	return report
+}
@@ -0,0 +1,88 @@
+package scorer
+
+import (
+	"math"
+
+	"github.com/giantswarm/llm-testing/internal/testsuite"
+)
+
+// GroupScore is the score of the questions sharing a section, difficulty, or tag.
+type GroupScore struct {
+	Correct int     `json:"correct"`
+	Total   int     `json:"total"`
+	Percent float64 `json:"percentage"`
+
+	// Weighted totals are set when rubric weights are configured.
+	WeightedCorrect *float64 `json:"weighted_correct,omitempty"`
+	WeightedTotal   *float64 `json:"weighted_total,omitempty"`
+	WeightedPercent *float64 `json:"weighted_percentage,omitempty"`
+}
+
+// Breakdown holds the scores of a per-question run grouped by question metadata.
+// Questions without a difficulty or tags are left out of those groups.
+type Breakdown struct {
+	Sections     map[string]*GroupScore `json:"sections,omitempty"`
+	Difficulties map[string]*GroupScore `json:"difficulties,omitempty"`
+	Tags         map[string]*GroupScore `json:"tags,omitempty"`
+}
+
+// breakdown groups verdicts by section, difficulty, and tag. Unjudged
+// questions count towards the totals but not the correct answers, as in
+// applyWeights. Weighted totals are computed when weights is not nil.
+func breakdown(verdicts []QuestionVerdict, weights *testsuite.Weights) *Breakdown {
+	if len(verdicts) == 0 {
+		return nil
+	}
+	b := &Breakdown{}
+	for _, q := range verdicts {
+		correct := q.Correct != nil && *q.Correct
+		var w *float64
+		if weights != nil {
+			v := weights.For(q.ID, q.Section)
+			w = &v
+		}
+		addToGroup(&b.Sections, q.Section, correct, w)
+		if q.Difficulty != "" {
+			addToGroup(&b.Difficulties, q.Difficulty, correct, w)
+		}
+		for _, tag := range q.Tags {
+			addToGroup(&b.Tags, tag, correct, w)
+		}
+	}
+	for _, groups := range []map[string]*GroupScore{b.Sections, b.Difficulties, b.Tags} {
+		for _, g := range groups {
+			g.Percent = math.Round(float64(g.Correct)/float64(g.Total)*10000) / 100
+			if g.WeightedTotal != nil && *g.WeightedTotal > 0 {
+				pct := math.Round(*g.WeightedCorrect / *g.WeightedTotal * 10000) / 100
+				g.WeightedPercent = &pct
+			}
+		}
+	}
+	return b
+}
+
+// addToGroup counts one answer towards the named group, creating the map
+// and group as needed. weight is nil when no rubric weights are configured.
+func addToGroup(groups *map[string]*GroupScore, name string, correct bool, weight *float64) {
+	if *groups == nil {
+		*groups = make(map[string]*GroupScore)
+	}
+	g, ok := (*groups)[name]
+	if !ok {
+		g = &GroupScore{}
+		if weight != nil {
+			g.WeightedCorrect, g.WeightedTotal = new(float64), new(float64)
+		}
+		(*groups)[name] = g
+	}
+	g.Total++
+	if correct {
+		g.Correct++
+	}
+	if weight != nil {
+		*g.WeightedTotal += *weight
+		if correct {
+			*g.WeightedCorrect += *weight
+		}
+	}
+}
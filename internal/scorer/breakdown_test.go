@@ -0,0 +1,72 @@
+package scorer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/giantswarm/llm-testing/internal/testsuite"
+	"github.com/giantswarm/llm-testing/internal/testutil"
+)
+
+func TestBreakdown(t *testing.T) {
+	yes, no := true, false
+	verdicts := []QuestionVerdict{
+		{ID: "1", Section: "Basics", Difficulty: "easy", Tags: []string{"networking"}, Correct: &yes},
+		{ID: "2", Section: "Basics", Difficulty: "hard", Tags: []string{"networking", "storage"}, Correct: &no},
+		{ID: "3", Section: "Storage", Tags: []string{"storage"}, Correct: nil},
+	}
+
+	b := breakdown(verdicts, nil)
+	require.NotNil(t, b)
+	assert.Equal(t, &GroupScore{Correct: 1, Total: 2, Percent: 50}, b.Sections["Basics"])
+	assert.Equal(t, &GroupScore{Correct: 0, Total: 1, Percent: 0}, b.Sections["Storage"])
+	assert.Len(t, b.Difficulties, 2)
+	assert.Equal(t, 100.0, b.Difficulties["easy"].Percent)
+	assert.Equal(t, &GroupScore{Correct: 1, Total: 2, Percent: 50}, b.Tags["networking"])
+	assert.Equal(t, &GroupScore{Correct: 0, Total: 2, Percent: 0}, b.Tags["storage"])
+	assert.Nil(t, b.Tags["networking"].WeightedCorrect)
+
+	weighted := breakdown(verdicts, &testsuite.Weights{Questions: map[string]float64{"1": 3}})
+	g := weighted.Sections["Basics"]
+	require.NotNil(t, g.WeightedCorrect)
+	assert.Equal(t, 3.0, *g.WeightedCorrect)
+	assert.Equal(t, 4.0, *g.WeightedTotal)
+	assert.Equal(t, 75.0, *g.WeightedPercent)
+}
+
+func TestScorePerQuestionBreakdownUsesMetadata(t *testing.T) {
+	content := `---
+NO. 1 - Basics
+DIFFICULTY: easy
+TAGS: networking, storage
+QUESTION: Q1
+EXPECTED ANSWER: A1
+ACTUAL ANSWER: A1
+---
+NO. 2 - Basics
+QUESTION: Q2
+EXPECTED ANSWER: A2
+ACTUAL ANSWER: wrong
+`
+	client := &testutil.MockLLMClient{
+		Responses: map[string]string{
+			"---\nNO. 1 - Basics\nDIFFICULTY: easy\nTAGS: networking, storage\nQUESTION: Q1\nEXPECTED ANSWER: A1\nACTUAL ANSWER: A1\n": "CORRECT",
+			"---\nNO. 2 - Basics\nQUESTION: Q2\nEXPECTED ANSWER: A2\nACTUAL ANSWER: wrong\n":                                           "INCORRECT",
+		},
+	}
+	s := NewScorer(client, Config{Repetitions: 1, Mode: ModePerQuestion})
+
+	output, err := s.Score(context.Background(), content, "file.txt")
+	require.NoError(t, err)
+
+	run := output.Runs[0]
+	assert.Equal(t, "easy", run.Questions[0].Difficulty)
+	assert.Equal(t, []string{"networking", "storage"}, run.Questions[0].Tags)
+	require.NotNil(t, run.Breakdown)
+	assert.Equal(t, &GroupScore{Correct: 1, Total: 2, Percent: 50}, run.Breakdown.Sections["Basics"])
+	assert.Equal(t, &GroupScore{Correct: 1, Total: 1, Percent: 100}, run.Breakdown.Difficulties["easy"])
+	assert.Equal(t, &GroupScore{Correct: 1, Total: 1, Percent: 100}, run.Breakdown.Tags["storage"])
+}
@@ -0,0 +1,87 @@
+package scorer
+
+import "fmt"
+
+// Consensus rules for combining the votes of several judges into one verdict.
+const (
+	// ConsensusMajority marks an answer correct when more than half of the judges agree.
+	ConsensusMajority = "majority"
+	// ConsensusUnanimous marks an answer correct only when every judge agrees.
+	ConsensusUnanimous = "unanimous"
+	// ConsensusWeighted weighs each vote by the judge's calibration accuracy.
+	ConsensusWeighted = "weighted"
+)
+
+// Judge is a model used for per-question judging.
+type Judge struct {
+	Model string `json:"model"`
+	// Weight is the judge's calibration accuracy (0-1) used by ConsensusWeighted.
+	// Zero means 1.
+	Weight float64 `json:"weight,omitempty"`
+}
+
+// JudgeVote is a single judge's verdict on one answer.
+type JudgeVote struct {
+	Model   string `json:"model"`
+	Correct *bool  `json:"correct"`
+	Error   string `json:"error,omitempty"`
+}
+
+// QuestionVerdict is the final verdict on one answer together with the
+// individual judge votes it was derived from.
+type QuestionVerdict struct {
+	ID         string      `json:"id"`
+	Section    string      `json:"section,omitempty"`
+	Difficulty string      `json:"difficulty,omitempty"`
+	Tags       []string    `json:"tags,omitempty"`
+	Correct    *bool       `json:"correct"` // nil when no judge produced a verdict
+	Votes      []JudgeVote `json:"votes"`
+}
+
+// ValidateConsensus returns an error if rule is not empty and not a supported consensus rule.
+func ValidateConsensus(rule string) error {
+	switch rule {
+	case "", ConsensusMajority, ConsensusUnanimous, ConsensusWeighted:
+		return nil
+	default:
+		return fmt.Errorf("invalid consensus rule %q (supported: majority, unanimous, weighted)", rule)
+	}
+}
+
+// consensus combines judge votes into a final verdict. Votes without a verdict
+// (judge errors) are ignored; nil is returned when no judge produced one.
+func consensus(rule string, votes []JudgeVote, weights map[string]float64) *bool {
+	var correctWeight, totalWeight float64
+	correctVotes, validVotes := 0, 0
+
+	for _, v := range votes {
+		if v.Correct == nil {
+			continue
+		}
+		w := weights[v.Model]
+		if w <= 0 {
+			w = 1
+		}
+		validVotes++
+		totalWeight += w
+		if *v.Correct {
+			correctVotes++
+			correctWeight += w
+		}
+	}
+
+	if validVotes == 0 {
+		return nil
+	}
+
+	var result bool
+	switch rule {
+	case ConsensusUnanimous:
+		result = correctVotes == validVotes
+	case ConsensusWeighted:
+		result = correctWeight > totalWeight/2
+	default:
+		result = correctVotes*2 > validVotes
+	}
+	return &result
+}
@@ -0,0 +1,56 @@
+package scorer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func vote(model string, correct bool) JudgeVote {
+	return JudgeVote{Model: model, Correct: &correct}
+}
+
+func TestConsensus(t *testing.T) {
+	votes := []JudgeVote{vote("a", true), vote("b", true), vote("c", false)}
+	weights := map[string]float64{"a": 0.2, "b": 0.2, "c": 0.9}
+
+	tests := []struct {
+		rule string
+		want bool
+	}{
+		{rule: ConsensusMajority, want: true},
+		{rule: "", want: true},
+		{rule: ConsensusUnanimous, want: false},
+		{rule: ConsensusWeighted, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			got := consensus(tt.rule, votes, weights)
+			require.NotNil(t, got)
+			assert.Equal(t, tt.want, *got)
+		})
+	}
+}
+
+func TestConsensusIgnoresFailedVotes(t *testing.T) {
+	votes := []JudgeVote{vote("a", true), {Model: "b", Error: "timeout"}}
+	got := consensus(ConsensusUnanimous, votes, nil)
+	require.NotNil(t, got)
+	assert.True(t, *got)
+
+	assert.Nil(t, consensus(ConsensusMajority, []JudgeVote{{Model: "a", Error: "timeout"}}, nil))
+}
+
+func TestConsensusMajorityTie(t *testing.T) {
+	got := consensus(ConsensusMajority, []JudgeVote{vote("a", true), vote("b", false)}, nil)
+	require.NotNil(t, got)
+	assert.False(t, *got)
+}
+
+func TestValidateConsensus(t *testing.T) {
+	assert.NoError(t, ValidateConsensus(""))
+	assert.NoError(t, ValidateConsensus(ConsensusWeighted))
+	assert.Error(t, ValidateConsensus("plurality"))
+}
@@ -0,0 +1,115 @@
+package scorer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/giantswarm/llm-testing/internal/testsuite"
+)
+
+// HallucinationReport is the result of the hallucination check pass over all
+// answers of a results file.
+type HallucinationReport struct {
+	Model        string              `json:"model"`
+	Checked      int                 `json:"checked"`
+	Hallucinated int                 `json:"hallucinated"`
+	Rate         float64             `json:"rate"` // percentage of checked answers that were hallucinated
+	Questions    []HallucinationFlag `json:"questions"`
+
+	// Usage counts the judge tokens spent on the check, including those of
+	// reused flags.
+	Usage *TokenUsage `json:"usage,omitempty"`
+}
+
+// HallucinationFlag is the hallucination verdict on a single answer.
+type HallucinationFlag struct {
+	ID           string `json:"id"`
+	Section      string `json:"section,omitempty"`
+	Hallucinated *bool  `json:"hallucinated"` // nil when the check failed
+	Details      string `json:"details,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+var hallucinationPattern = regexp.MustCompile(`(?i)\b(HALLUCINATED|GROUNDED)\b`)
+
+// checkHallucinations runs the hallucination check on every result. Questions
+// already checked successfully in previous are reused.
+func (s *Scorer) checkHallucinations(ctx context.Context, results []testsuite.Result, previous *HallucinationReport) *HallucinationReport {
+	done := make(map[string]HallucinationFlag)
+	if previous != nil {
+		for _, f := range previous.Questions {
+			if f.Hallucinated != nil {
+				done[f.ID] = f
+			}
+		}
+	}
+
+	report := &HallucinationReport{
+		Model:     s.config.Model,
+		Questions: make([]HallucinationFlag, 0, len(results)),
+	}
+	var usage TokenUsage
+	if previous != nil {
+		usage.Add(previous.Usage)
+	}
+	for i := range results {
+		flag, ok := done[results[i].Question.ID]
+		if !ok {
+			flag = s.checkHallucination(ctx, &usage, &results[i])
+		}
+		report.Questions = append(report.Questions, flag)
+
+		if flag.Hallucinated == nil {
+			continue
+		}
+		report.Checked++
+		if *flag.Hallucinated {
+			report.Hallucinated++
+		}
+	}
+
+	report.Usage = &usage
+
+	if report.Checked > 0 {
+		report.Rate = math.Round(float64(report.Hallucinated)/float64(report.Checked)*10000) / 100
+	}
+	slog.Info("hallucination check complete",
+		"checked", report.Checked,
+		"hallucinated", report.Hallucinated,
+		"rate", report.Rate,
+	)
+	return report
+}
+
+func (s *Scorer) checkHallucination(ctx context.Context, usage *TokenUsage, result *testsuite.Result) HallucinationFlag {
+	flag := HallucinationFlag{ID: result.Question.ID, Section: result.Question.Section}
+
+	text, err := s.complete(ctx, usage, s.config.Model, HallucinationCheckPrompt, formatResult(result))
+	if err == nil {
+		var hallucinated bool
+		hallucinated, err = parseHallucination(text)
+		if err == nil {
+			flag.Hallucinated = &hallucinated
+			if hallucinated {
+				flag.Details = verdictDetails(text) // the fabricated items
+			}
+			return flag
+		}
+	}
+
+	slog.Warn("hallucination check failed", "question_id", result.Question.ID, "error", err)
+	flag.Error = err.Error()
+	return flag
+}
+
+func parseHallucination(text string) (bool, error) {
+	match := hallucinationPattern.FindString(text)
+	if match == "" {
+		return false, fmt.Errorf("could not parse hallucination verdict from output: %q", text)
+	}
+	return strings.EqualFold(match, "HALLUCINATED"), nil
+}
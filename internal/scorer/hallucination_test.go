@@ -0,0 +1,113 @@
+package scorer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/giantswarm/llm-testing/internal/testutil"
+)
+
+const hallucinationContent = `---
+NO. 1 - S
+QUESTION: Q1
+EXPECTED ANSWER: A1
+ACTUAL ANSWER: kubectl frobnicate
+---
+NO. 2 - S
+QUESTION: Q2
+EXPECTED ANSWER: A2
+ACTUAL ANSWER: A2
+`
+
+func TestScoreWithHallucinationCheck(t *testing.T) {
+	client := &testutil.MockLLMClient{
+		Responses: map[string]string{
+			hallucinationContent: "1 out of 2 answers are correct.",
+			"---\nNO. 1 - S\nQUESTION: Q1\nEXPECTED ANSWER: A1\nACTUAL ANSWER: kubectl frobnicate\n": "HALLUCINATED\n- kubectl frobnicate",
+			"---\nNO. 2 - S\nQUESTION: Q2\nEXPECTED ANSWER: A2\nACTUAL ANSWER: A2\n":                 "GROUNDED",
+		},
+	}
+	s := NewScorer(client, Config{Repetitions: 2, HallucinationCheck: true})
+
+	output, err := s.Score(context.Background(), hallucinationContent, "file.txt")
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, client.Calls) // 2 aggregate runs + 2 hallucination checks
+	assert.True(t, output.Metadata.HallucinationCheck)
+
+	report := output.Hallucinations
+	require.NotNil(t, report)
+	assert.Equal(t, 2, report.Checked)
+	assert.Equal(t, 1, report.Hallucinated)
+	assert.Equal(t, 50.0, report.Rate)
+	require.Len(t, report.Questions, 2)
+	assert.True(t, *report.Questions[0].Hallucinated)
+	assert.Equal(t, "- kubectl frobnicate", report.Questions[0].Details)
+	assert.False(t, *report.Questions[1].Hallucinated)
+
+	require.NotNil(t, output.Summary.HallucinationRate)
+	assert.Equal(t, 50.0, *output.Summary.HallucinationRate)
+}
+
+func TestScoreWithoutHallucinationCheck(t *testing.T) {
+	client := &testutil.MockLLMClient{DefaultResponse: "2 out of 2 answers are correct."}
+	s := NewScorer(client, Config{Repetitions: 1})
+
+	output, err := s.Score(context.Background(), hallucinationContent, "file.txt")
+	require.NoError(t, err)
+	assert.Nil(t, output.Hallucinations)
+	assert.Nil(t, output.Summary.HallucinationRate)
+}
+
+func TestHallucinationCheckFailuresAreExcluded(t *testing.T) {
+	client := &testutil.MockLLMClient{
+		DefaultResponse: "GROUNDED",
+		Errors:          []error{errors.New("timeout")},
+	}
+	s := NewScorer(client, Config{HallucinationCheck: true})
+
+	report := s.checkHallucinations(context.Background(), parseResults(hallucinationContent), nil)
+	assert.Equal(t, 1, report.Checked)
+	assert.Equal(t, 0.0, report.Rate)
+	assert.Nil(t, report.Questions[0].Hallucinated)
+	assert.Contains(t, report.Questions[0].Error, "timeout")
+}
+
+func TestRescoreReusesHallucinationVerdicts(t *testing.T) {
+	hallucinated := true
+	previous := &ScoreOutput{
+		Hallucinations: &HallucinationReport{
+			Questions: []HallucinationFlag{
+				{ID: "1", Section: "S", Hallucinated: &hallucinated},
+				{ID: "2", Section: "S", Error: "timeout"},
+			},
+		},
+	}
+	client := &testutil.MockLLMClient{DefaultResponse: "GROUNDED"}
+	s := NewScorer(client, Config{HallucinationCheck: true})
+
+	output, err := s.Rescore(context.Background(), hallucinationContent, "file.txt", previous)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, client.Calls) // only question 2 is checked again
+	assert.Equal(t, 2, output.Hallucinations.Checked)
+	assert.Equal(t, 1, output.Hallucinations.Hallucinated)
+	assert.Equal(t, 50.0, *output.Summary.HallucinationRate)
+}
+
+func TestParseHallucination(t *testing.T) {
+	got, err := parseHallucination("hallucinated: uses --force-sync")
+	require.NoError(t, err)
+	assert.True(t, got)
+
+	got, err = parseHallucination("GROUNDED")
+	require.NoError(t, err)
+	assert.False(t, got)
+
+	_, err = parseHallucination("not sure")
+	assert.Error(t, err)
+}
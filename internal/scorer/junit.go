@@ -0,0 +1,177 @@
+package scorer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Output formats for scores.
+const (
+	FormatJSON  = "json"
+	FormatJUnit = "junit"
+)
+
+// ValidateFormat returns an error if format is not empty and not a supported output format.
+func ValidateFormat(format string) error {
+	switch format {
+	case "", FormatJSON, FormatJUnit:
+		return nil
+	default:
+		return fmt.Errorf("invalid format %q (supported: json, junit)", format)
+	}
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr,omitempty"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Timestamp  string          `xml:"timestamp,attr,omitempty"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Cases      []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Error     *junitFailure `xml:"error,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// JUnitReport converts per-question verdicts into JUnit XML with one
+// <testsuite> per scored results file (i.e. per model) and one <testcase>
+// per question. A question passes when it was judged correct in more than
+// half of the repetitions that produced a verdict; questions without any
+// verdict are reported as errors. Scores without per-question verdicts
+// (aggregate mode) cannot be converted.
+func JUnitReport(name string, outputs []*ScoreOutput) ([]byte, error) {
+	report := junitTestSuites{Name: name}
+
+	for _, output := range outputs {
+		suite, err := junitSuite(output)
+		if err != nil {
+			return nil, err
+		}
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Errors += suite.Errors
+		report.Suites = append(report.Suites, suite)
+	}
+
+	data, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JUnit report: %w", err)
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+func junitSuite(output *ScoreOutput) (junitTestSuite, error) {
+	model := strings.TrimSuffix(filepath.Base(output.Metadata.ResultsFile), ".txt")
+
+	type tally struct {
+		section         string
+		correct, judged int
+		errors          []string
+	}
+	var order []string
+	byID := make(map[string]*tally)
+	for _, run := range output.Runs {
+		for _, q := range run.Questions {
+			t, ok := byID[q.ID]
+			if !ok {
+				t = &tally{section: q.Section}
+				byID[q.ID] = t
+				order = append(order, q.ID)
+			}
+			if q.Correct == nil {
+				for _, v := range q.Votes {
+					if v.Error != "" {
+						t.errors = append(t.errors, v.Error)
+					}
+				}
+				continue
+			}
+			t.judged++
+			if *q.Correct {
+				t.correct++
+			}
+		}
+	}
+	if len(order) == 0 {
+		return junitTestSuite{}, fmt.Errorf("no per-question verdicts for %s (JUnit output requires mode %q)", model, ModePerQuestion)
+	}
+
+	suite := junitTestSuite{
+		Name:      model,
+		Timestamp: output.Metadata.Timestamp,
+		Properties: []junitProperty{
+			{Name: "scoring_model", Value: output.Metadata.ScoringModel},
+			{Name: "repetitions", Value: fmt.Sprintf("%d", len(output.Runs))},
+		},
+	}
+	if output.Summary.MeanPercent != nil {
+		suite.Properties = append(suite.Properties, junitProperty{
+			Name: "mean_percentage", Value: fmt.Sprintf("%.2f", *output.Summary.MeanPercent),
+		})
+	}
+
+	for _, id := range order {
+		t := byID[id]
+		tc := junitTestCase{Name: "NO. " + id, ClassName: model + "." + t.section}
+		switch {
+		case t.judged == 0:
+			tc.Error = &junitFailure{
+				Message: "no verdict in any repetition",
+				Body:    strings.Join(t.errors, "\n"),
+			}
+			suite.Errors++
+		case t.correct*2 <= t.judged:
+			tc.Failure = &junitFailure{
+				Message: fmt.Sprintf("judged incorrect: correct in %d of %d repetitions", t.correct, t.judged),
+			}
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	suite.Tests = len(suite.Cases)
+	return suite, nil
+}
+
+// JUnitFilePath returns the JUnit XML file path for a results file.
+func JUnitFilePath(resultsFile string) string {
+	return strings.TrimSuffix(resultsFile, ".txt") + "_junit.xml"
+}
+
+// WriteJUnitFile writes a JUnit report for the given score outputs to path.
+func WriteJUnitFile(path, name string, outputs []*ScoreOutput) error {
+	data, err := JUnitReport(name, outputs)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package scorer
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func junitVerdict(id, section string, correct *bool) QuestionVerdict {
+	v := QuestionVerdict{ID: id, Section: section, Correct: correct}
+	if correct == nil {
+		v.Votes = []JudgeVote{{Model: "judge", Error: "timeout"}}
+	}
+	return v
+}
+
+func TestJUnitReport(t *testing.T) {
+	yes, no := true, false
+	pct := 50.0
+	output := &ScoreOutput{
+		Metadata: ScoreMetadata{ResultsFile: "/results/run/mistral-7b.txt", ScoringModel: "judge", Timestamp: "2026-01-01T00:00:00Z"},
+		Runs: []RunScore{
+			{Questions: []QuestionVerdict{junitVerdict("1", "Basics", &yes), junitVerdict("2", "Basics", &no), junitVerdict("3", "Net", nil)}},
+			{Questions: []QuestionVerdict{junitVerdict("1", "Basics", &yes), junitVerdict("2", "Basics", &yes), junitVerdict("3", "Net", nil)}},
+		},
+		Summary: Summary{MeanPercent: &pct},
+	}
+
+	data, err := JUnitReport("run-1", []*ScoreOutput{output})
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "<?xml")
+
+	var report junitTestSuites
+	require.NoError(t, xml.Unmarshal(data, &report))
+
+	assert.Equal(t, "run-1", report.Name)
+	assert.Equal(t, 3, report.Tests)
+	assert.Equal(t, 1, report.Failures)
+	assert.Equal(t, 1, report.Errors)
+
+	require.Len(t, report.Suites, 1)
+	suite := report.Suites[0]
+	assert.Equal(t, "mistral-7b", suite.Name)
+	assert.Contains(t, suite.Properties, junitProperty{Name: "mean_percentage", Value: "50.00"})
+
+	require.Len(t, suite.Cases, 3)
+	assert.Equal(t, "NO. 1", suite.Cases[0].Name)
+	assert.Equal(t, "mistral-7b.Basics", suite.Cases[0].ClassName)
+	assert.Nil(t, suite.Cases[0].Failure)
+
+	// A 1-1 split is not a majority, so the question fails.
+	require.NotNil(t, suite.Cases[1].Failure)
+	assert.Contains(t, suite.Cases[1].Failure.Message, "correct in 1 of 2")
+
+	require.NotNil(t, suite.Cases[2].Error)
+	assert.Contains(t, suite.Cases[2].Error.Body, "timeout")
+}
+
+func TestJUnitReportRequiresPerQuestionVerdicts(t *testing.T) {
+	output := &ScoreOutput{Metadata: ScoreMetadata{ResultsFile: "model.txt"}, Runs: []RunScore{{}}}
+
+	_, err := JUnitReport("", []*ScoreOutput{output})
+	assert.ErrorContains(t, err, "requires mode")
+}
+
+func TestWriteJUnitFile(t *testing.T) {
+	yes := true
+	output := &ScoreOutput{
+		Metadata: ScoreMetadata{ResultsFile: "model.txt"},
+		Runs:     []RunScore{{Questions: []QuestionVerdict{junitVerdict("1", "S", &yes)}}},
+	}
+
+	resultsFile := filepath.Join(t.TempDir(), "model.txt")
+	path := JUnitFilePath(resultsFile)
+	assert.Equal(t, "model_junit.xml", filepath.Base(path))
+
+	require.NoError(t, WriteJUnitFile(path, "", []*ScoreOutput{output}))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `<testcase name="NO. 1" classname="model.S">`)
+}
+
+func TestValidateFormat(t *testing.T) {
+	assert.NoError(t, ValidateFormat(""))
+	assert.NoError(t, ValidateFormat(FormatJUnit))
+	assert.Error(t, ValidateFormat("xml"))
+}
@@ -0,0 +1,79 @@
+package scorer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/giantswarm/llm-testing/internal/testsuite"
+)
+
+// LintFinding is the judge's verdict on whether an expected answer is
+// likely to produce unstable verdicts.
+type LintFinding struct {
+	ID        string `json:"id"`
+	Ambiguous *bool  `json:"ambiguous"` // nil when the check failed
+	Details   string `json:"details,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+var lintPattern = regexp.MustCompile(`(?i)\b(AMBIGUOUS|CLEAR)\b`)
+
+// LintAnswers asks the judge model whether each question's expected answer is
+// likely to produce unstable verdicts: too short, one of several valid
+// phrasings, or dependent on environment-specific details.
+func (s *Scorer) LintAnswers(ctx context.Context, questions []testsuite.Question) []LintFinding {
+	findings := make([]LintFinding, 0, len(questions))
+	for i := range questions {
+		findings = append(findings, s.lintAnswer(ctx, &questions[i]))
+	}
+	return findings
+}
+
+func (s *Scorer) lintAnswer(ctx context.Context, q *testsuite.Question) LintFinding {
+	finding := LintFinding{ID: q.ID}
+
+	content := fmt.Sprintf("QUESTION: %s\nEXPECTED ANSWER: %s\n", q.QuestionText, q.ExpectedAnswer)
+	text, err := s.complete(ctx, nil, s.config.Model, AnswerLintPrompt, content)
+	if err == nil {
+		match := lintPattern.FindString(text)
+		if match != "" {
+			ambiguous := strings.EqualFold(match, "AMBIGUOUS")
+			finding.Ambiguous = &ambiguous
+			if ambiguous {
+				finding.Details = verdictDetails(text)
+			}
+			return finding
+		}
+		err = fmt.Errorf("could not parse lint verdict from output: %q", text)
+	}
+
+	slog.Warn("answer lint failed", "question_id", q.ID, "error", err)
+	finding.Error = err.Error()
+	return finding
+}
+
+// LintSuite lints the expected answers of the questions in a validation
+// report, adding a warning for each ambiguous answer at its line.
+func (s *Scorer) LintSuite(ctx context.Context, report *testsuite.ValidationReport) {
+	summary := &testsuite.LintSummary{Model: s.config.Model}
+	for _, f := range s.LintAnswers(ctx, report.ValidatedQuestions()) {
+		switch {
+		case f.Ambiguous == nil:
+			summary.Failed++
+		case *f.Ambiguous:
+			summary.Checked++
+			summary.Flagged++
+			msg := "expected answer is likely to produce unstable verdicts"
+			if f.Details != "" {
+				msg += ": " + strings.Join(strings.Fields(f.Details), " ")
+			}
+			report.AddForQuestion(f.ID, testsuite.SeverityWarning, "%s", msg)
+		default:
+			summary.Checked++
+		}
+	}
+	report.Lint = summary
+}
@@ -0,0 +1,69 @@
+package scorer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/giantswarm/llm-testing/internal/testsuite"
+	"github.com/giantswarm/llm-testing/internal/testutil"
+)
+
+func TestLintAnswers(t *testing.T) {
+	client := &testutil.MockLLMClient{
+		Responses: map[string]string{
+			"QUESTION: How do you list pods?\nEXPECTED ANSWER: kubectl get pods\n":   "CLEAR",
+			"QUESTION: Which port does the API server use?\nEXPECTED ANSWER: 6443\n": "AMBIGUOUS\nThe port depends on the cluster.\nAccept any configured secure port.",
+			"QUESTION: What is etcd?\nEXPECTED ANSWER: a store\n":                    "not sure",
+		},
+		Errors: []error{errors.New("timeout")},
+	}
+	s := NewScorer(client, Config{Model: "judge"})
+	questions := []testsuite.Question{
+		{ID: "0", QuestionText: "Unreachable?", ExpectedAnswer: "yes"},
+		{ID: "1", QuestionText: "How do you list pods?", ExpectedAnswer: "kubectl get pods"},
+		{ID: "2", QuestionText: "Which port does the API server use?", ExpectedAnswer: "6443"},
+		{ID: "3", QuestionText: "What is etcd?", ExpectedAnswer: "a store"},
+	}
+
+	findings := s.LintAnswers(context.Background(), questions)
+	require.Len(t, findings, 4)
+	assert.Nil(t, findings[0].Ambiguous)
+	assert.Contains(t, findings[0].Error, "timeout")
+	require.NotNil(t, findings[1].Ambiguous)
+	assert.False(t, *findings[1].Ambiguous)
+	require.NotNil(t, findings[2].Ambiguous)
+	assert.True(t, *findings[2].Ambiguous)
+	assert.Equal(t, "The port depends on the cluster.\nAccept any configured secure port.", findings[2].Details)
+	assert.Nil(t, findings[3].Ambiguous)
+	assert.Contains(t, findings[3].Error, "could not parse")
+	assert.Equal(t, AnswerLintPrompt, client.LastRequest.SystemMessage)
+}
+
+func TestLintSuite(t *testing.T) {
+	fsys := fstest.MapFS{
+		"config.yaml":   {Data: []byte("name: Lint\nprompt:\n  system_message: Answer.\n")},
+		"questions.csv": {Data: []byte("ID,Section,Question,ExpectedAnswer\n1,Basics,How do you list pods?,kubectl get pods\n2,Basics,Which port?,6443\n")},
+	}
+	report := testsuite.Validate(fsys)
+	require.True(t, report.Valid)
+
+	client := &testutil.MockLLMClient{
+		Responses:       map[string]string{"QUESTION: Which port?\nEXPECTED ANSWER: 6443\n": "AMBIGUOUS\nDepends on the cluster."},
+		DefaultResponse: "CLEAR",
+	}
+	NewScorer(client, Config{Model: "judge"}).LintSuite(context.Background(), report)
+
+	assert.True(t, report.Valid, "lint findings are warnings")
+	assert.Equal(t, &testsuite.LintSummary{Model: "judge", Checked: 2, Flagged: 1}, report.Lint)
+	assert.Equal(t, []testsuite.ValidationIssue{{
+		File:     "questions.csv",
+		Line:     3,
+		Severity: testsuite.SeverityWarning,
+		Message:  "expected answer is likely to produce unstable verdicts: Depends on the cluster.",
+	}}, report.Issues)
+}
@@ -25,3 +25,36 @@ complete -F __start_kubectl k
 Example output:
 
 58 out of 100 answers are correct.`
+
+// QuestionEvaluationPrompt is the system prompt used to judge a single answer,
+// e.g. when answers are scored immediately as they are produced.
+const QuestionEvaluationPrompt = `You are a research assistant, evaluating the response to a single exam question on Kubernetes.
+
+The user submits a question and answers, both the expected answer as well as the actual answer provided by a candidate.
+
+Your task is to evaluate whether the actual answer is correct or not. Correct means that the answer contains the necessary information. A correct answer is not necessarily identical to the expected answer.
+
+Respond with exactly one word: CORRECT or INCORRECT.`
+
+// HallucinationCheckPrompt is the system prompt used to check a single answer
+// for fabricated content, independently of whether it is correct.
+const HallucinationCheckPrompt = `You are a research assistant, checking the response to a single exam question on Kubernetes for hallucinations.
+
+The user submits a question and answers, both the expected answer as well as the actual answer provided by a candidate.
+
+Your task is not to judge correctness. Only check whether the actual answer contains fabricated content: resource kinds, fields, command-line flags, kubectl subcommands, or API versions that do not exist in Kubernetes or its standard tooling. An incomplete or wrong answer that only uses real names is not a hallucination.
+
+Respond with HALLUCINATED or GROUNDED on the first line. If HALLUCINATED, list the fabricated items on the following lines.`
+
+// AnswerLintPrompt is the system prompt used to check a single expected
+// answer for ambiguity that makes judges' verdicts unstable.
+const AnswerLintPrompt = `You are a research assistant, reviewing the expected answer to a single exam question before the question is used to test candidates.
+
+The user submits a question and its expected answer. A judge will later compare candidates' answers against the expected answer and decide whether they are correct.
+
+Your task is not to check whether the expected answer is correct. Only check whether it is likely to make the judge's verdicts unstable, because it is:
+- too short or vague to tell a correct answer from a partially correct one,
+- one of multiple valid phrasings or solutions without saying that others are accepted,
+- dependent on environment-specific details, such as names, versions, or defaults of a particular cluster or tool release.
+
+Respond with CLEAR or AMBIGUOUS on the first line. If AMBIGUOUS, explain the problem and suggest a clearer expected answer on the following lines.`
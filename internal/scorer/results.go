@@ -0,0 +1,77 @@
+package scorer
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/giantswarm/llm-testing/internal/testsuite"
+)
+
+// resultHeaderPattern matches the start of a result block in a QA results file.
+var resultHeaderPattern = regexp.MustCompile(`(?m)^---\nNO\. (.*?) - (.*)$`)
+
+// parseResults splits a QA results file into its individual results.
+// Answers may span multiple lines; each answer runs until the next result block.
+func parseResults(content string) []testsuite.Result {
+	headers := resultHeaderPattern.FindAllStringSubmatchIndex(content, -1)
+
+	results := make([]testsuite.Result, 0, len(headers))
+	for i, h := range headers {
+		end := len(content)
+		if i+1 < len(headers) {
+			end = headers[i+1][0]
+		}
+		body := content[h[1]:end]
+
+		// The optional metadata lines precede the question.
+		meta := body
+		if j := strings.Index(body, "QUESTION: "); j >= 0 {
+			meta = body[:j]
+		}
+		// Results of blind runs have no expected answer.
+		questionEnd := "\nEXPECTED ANSWER: "
+		if j := strings.Index(body, "\nACTUAL ANSWER: "); j >= 0 && !strings.Contains(body[:j], questionEnd) {
+			questionEnd = "\nACTUAL ANSWER: "
+		}
+
+		results = append(results, testsuite.Result{
+			Question: testsuite.Question{
+				ID:             content[h[2]:h[3]],
+				Section:        content[h[4]:h[5]],
+				QuestionText:   field(body, "QUESTION: ", questionEnd),
+				ExpectedAnswer: field(body, "\nEXPECTED ANSWER: ", "\nACTUAL ANSWER: "),
+				Difficulty:     field(meta, "\nDIFFICULTY: ", "\n"),
+				Tags:           testsuite.ParseTags(field(meta, "\nTAGS: ", "\n")),
+			},
+			Answer: field(body, "\nACTUAL ANSWER: ", ""),
+		})
+	}
+	return results
+}
+
+// ReadResults reads the individual results of a QA results file.
+func ReadResults(resultsFile string) ([]testsuite.Result, error) {
+	data, err := os.ReadFile(resultsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read results file: %w", err)
+	}
+	return parseResults(string(data)), nil
+}
+
+// field returns the text between start and end markers (or the end of body
+// when end is empty), trimmed of surrounding whitespace.
+func field(body, start, end string) string {
+	i := strings.Index(body, start)
+	if i < 0 {
+		return ""
+	}
+	rest := body[i+len(start):]
+	if end != "" {
+		if j := strings.Index(rest, end); j >= 0 {
+			rest = rest[:j]
+		}
+	}
+	return strings.TrimSpace(rest)
+}
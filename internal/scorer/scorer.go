@@ -3,26 +3,92 @@ package scorer
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"math"
 	"os"
+	"path/filepath"
 	"regexp"
 	"slices"
 	"strconv"
 	"strings"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/giantswarm/llm-testing/internal/llm"
+	"github.com/giantswarm/llm-testing/internal/testsuite"
+	"github.com/giantswarm/llm-testing/internal/tracing"
 )
 
 // DefaultScoringModel is the default model used for LLM-as-judge scoring.
 const DefaultScoringModel = "claude-sonnet-4-5-20250514"
 
+// Scoring modes.
+const (
+	// ModeAggregate asks the judge to count correct answers across the whole results file.
+	ModeAggregate = "aggregate"
+	// ModePerQuestion judges each answer separately, optionally with several judges.
+	ModePerQuestion = "per_question"
+)
+
+// ValidateMode returns an error if mode is not empty and not a supported scoring mode.
+func ValidateMode(mode string) error {
+	switch mode {
+	case "", ModeAggregate, ModePerQuestion:
+		return nil
+	default:
+		return fmt.Errorf("invalid scoring mode %q (supported: aggregate, per_question)", mode)
+	}
+}
+
 // Config holds scoring configuration.
 type Config struct {
 	Model       string
 	Repetitions int
+
+	// Temperature overrides the judge temperature. nil means 0 (deterministic judging).
+	Temperature *float64
+
+	// MaxTokens caps the judge's output length. Zero means "use server default".
+	MaxTokens int
+
+	// ReasoningEffort sets the thinking budget for judges that support it
+	// (see llm.ReasoningEffortLow etc.). Empty means "use server default".
+	ReasoningEffort string
+	// Mode selects aggregate (default) or per-question scoring.
+	Mode string
+
+	// Judges are the models voting on each answer in per-question mode.
+	// Empty means a single judge using Model.
+	Judges []Judge
+
+	// Consensus is the rule combining judge votes (default: majority).
+	Consensus string
+
+	// Weights are rubric weights used to compute weighted totals in
+	// per-question mode. When nil, the weights recorded in the run's
+	// resultset.json (if any) are used by ScoreFile and RescoreFile.
+	// Aggregate mode cannot apply them and warns in the score metadata.
+	Weights *testsuite.Weights
+
+	// HallucinationCheck enables a second pass in which Model checks each
+	// answer for fabricated resource names, flags, or API versions.
+	HallucinationCheck bool
+
+	// Prices, when set, are used to compute the cost of the judge calls.
+	Prices PriceTable
+
+	// Clients reach the judge models, by name, that the scorer's client
+	// does not, e.g. those of another provider.
+	Clients map[string]llm.Client
+
+	// AnswerKey holds the expected answers of a blind run by question ID,
+	// joined with the results in per-question mode. When nil, the answer
+	// key in the run directory (if any) is used by ScoreFile and RescoreFile.
+	AnswerKey map[string]string
 }
 
 // RunScore represents the parsed result of a single scoring run.
@@ -32,6 +98,20 @@ type RunScore struct {
 	Percent   *float64 `json:"percentage"`
 	RawOutput string   `json:"raw_output"`
 	ParseErr  string   `json:"parse_error,omitempty"`
+
+	// Questions holds per-question verdicts and judge votes in per-question mode.
+	Questions []QuestionVerdict `json:"questions,omitempty"`
+
+	// Weighted totals are set in per-question mode when rubric weights are configured.
+	WeightedCorrect *float64 `json:"weighted_correct,omitempty"`
+	WeightedTotal   *float64 `json:"weighted_total,omitempty"`
+	WeightedPercent *float64 `json:"weighted_percentage,omitempty"`
+
+	// Breakdown groups the per-question verdicts by section, difficulty, and tag.
+	Breakdown *Breakdown `json:"breakdown,omitempty"`
+
+	// Usage counts the judge tokens spent on this repetition.
+	Usage *TokenUsage `json:"usage,omitempty"`
 }
 
 // ScoreOutput is the full structured scoring output.
@@ -39,6 +119,9 @@ type ScoreOutput struct {
 	Metadata ScoreMetadata `json:"metadata"`
 	Runs     []RunScore    `json:"runs"`
 	Summary  Summary       `json:"summary"`
+
+	// Hallucinations is set when the hallucination check pass is enabled.
+	Hallucinations *HallucinationReport `json:"hallucinations,omitempty"`
 }
 
 // ScoreMetadata holds information about the scoring run.
@@ -47,6 +130,29 @@ type ScoreMetadata struct {
 	ResultsFile  string `json:"results_file"`
 	ScoringModel string `json:"scoring_model"`
 	Repetitions  int    `json:"repetitions"`
+
+	Temperature     float64 `json:"temperature"`
+	MaxTokens       int     `json:"max_tokens,omitempty"`
+	ReasoningEffort string  `json:"reasoning_effort,omitempty"`
+
+	Mode      string  `json:"mode"`
+	Judges    []Judge `json:"judges,omitempty"`
+	Consensus string  `json:"consensus,omitempty"`
+
+	Weights *testsuite.Weights `json:"weights,omitempty"`
+
+	HallucinationCheck bool `json:"hallucination_check,omitempty"`
+
+	// Warnings are caveats of the scores, e.g. rubric weights of the run
+	// that the mode could not apply.
+	Warnings []string `json:"warnings,omitempty"`
+
+	// RescoredRuns lists the repetitions (1-based) re-run by a rescore.
+	RescoredRuns []int `json:"rescored_runs,omitempty"`
+
+	// Usage totals the judge tokens (and cost) of all repetitions and the
+	// hallucination check.
+	Usage *TokenUsage `json:"usage,omitempty"`
 }
 
 // Summary holds aggregate statistics from multiple scoring runs.
@@ -57,12 +163,38 @@ type Summary struct {
 	MaxCorrect    *int     `json:"max_correct"`
 	Variance      *float64 `json:"variance"`
 	AllRunsParsed bool     `json:"all_runs_parsed"`
+
+	// Weighted totals are set when runs carry rubric-weighted scores.
+	MeanWeightedCorrect *float64 `json:"mean_weighted_correct,omitempty"`
+	MeanWeightedPercent *float64 `json:"mean_weighted_percentage,omitempty"`
+	WeightedTotal       *float64 `json:"weighted_total,omitempty"`
+
+	// HallucinationRate is the percentage of answers flagged as hallucinated
+	// by the hallucination check, when enabled.
+	HallucinationRate *float64 `json:"hallucination_rate,omitempty"`
+
+	// UnstableQuestions lists questions whose per-question verdict changed
+	// between repetitions, hinting at ambiguous expected answers.
+	UnstableQuestions []UnstableQuestion `json:"unstable_questions,omitempty"`
 }
 
+// UnstableQuestion is a question the judge flipped on between repetitions.
+type UnstableQuestion struct {
+	ID          string `json:"id"`
+	Section     string `json:"section,omitempty"`
+	CorrectRuns int    `json:"correct_runs"`
+	JudgedRuns  int    `json:"judged_runs"`
+}
+
+// ProgressFunc is called after each scoring repetition of a results file
+// with the number of completed and total repetitions.
+type ProgressFunc func(resultsFile string, completed, total int)
+
 // Scorer evaluates test results using an LLM as judge.
 type Scorer struct {
-	client llm.Client
-	config Config
+	client   llm.Client
+	config   Config
+	progress ProgressFunc // optional
 }
 
 // NewScorer creates a new Scorer.
@@ -73,9 +205,34 @@ func NewScorer(client llm.Client, config Config) *Scorer {
 	if config.Model == "" {
 		config.Model = DefaultScoringModel
 	}
+	if config.Temperature == nil {
+		config.Temperature = llm.Float64Ptr(0)
+	}
+	if config.Mode == "" {
+		config.Mode = ModeAggregate
+	}
+	if config.Mode == ModePerQuestion {
+		if len(config.Judges) == 0 {
+			config.Judges = []Judge{{Model: config.Model}}
+		}
+		if config.Consensus == "" {
+			config.Consensus = ConsensusMajority
+		}
+	}
 	return &Scorer{client: client, config: config}
 }
 
+// SetProgressFunc sets the callback reporting completed repetitions.
+func (s *Scorer) SetProgressFunc(fn ProgressFunc) {
+	s.progress = fn
+}
+
+func (s *Scorer) reportProgress(resultsFile string, completed, total int) {
+	if s.progress != nil {
+		s.progress(resultsFile, completed, total)
+	}
+}
+
 // ScoreFile reads a results file and scores it.
 func (s *Scorer) ScoreFile(ctx context.Context, resultsFile string) (*ScoreOutput, error) {
 	content, err := os.ReadFile(resultsFile)
@@ -83,58 +240,352 @@ func (s *Scorer) ScoreFile(ctx context.Context, resultsFile string) (*ScoreOutpu
 		return nil, fmt.Errorf("failed to read results file: %w", err)
 	}
 
-	return s.Score(ctx, string(content), resultsFile)
+	return s.withRunWeights(resultsFile).withAnswerKey(resultsFile).Score(ctx, string(content), resultsFile)
 }
 
 // Score evaluates the given results content.
 func (s *Scorer) Score(ctx context.Context, content string, resultsFile string) (*ScoreOutput, error) {
+	ctx, span := s.startSpan(ctx, "score", resultsFile)
+	output, err := s.score(ctx, content, resultsFile)
+	tracing.End(span, err)
+	return output, err
+}
+
+// score executes Score in the span of the scoring.
+func (s *Scorer) score(ctx context.Context, content string, resultsFile string) (*ScoreOutput, error) {
+	output := s.newOutput(resultsFile)
+
+	results, err := s.prepare(content, resultsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < s.config.Repetitions; i++ {
+		output.Runs = append(output.Runs, s.scoreRun(ctx, content, results, i))
+		s.reportProgress(resultsFile, i+1, s.config.Repetitions)
+	}
+
+	output.Summary = calculateStatistics(output.Runs)
+
+	if err := s.hallucinationPass(ctx, output, content, nil); err != nil {
+		return nil, err
+	}
+	output.Metadata.Usage = totalUsage(output)
+
+	return output, nil
+}
+
+// RescoreFile re-runs only the failed repetitions recorded in the existing
+// scores file of resultsFile. When no scores file exists yet, the results
+// file is scored from scratch.
+func (s *Scorer) RescoreFile(ctx context.Context, resultsFile string) (*ScoreOutput, error) {
+	previous, err := ReadScoreFile(resultsFile)
+	if errors.Is(err, os.ErrNotExist) {
+		slog.Info("no previous scores found, scoring from scratch", "results_file", resultsFile)
+		return s.ScoreFile(ctx, resultsFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(resultsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read results file: %w", err)
+	}
+
+	return s.withRunWeights(resultsFile).withAnswerKey(resultsFile).Rescore(ctx, string(content), resultsFile, previous)
+}
+
+// withRunWeights returns a scorer using the rubric weights recorded in the
+// resultset.json next to resultsFile, unless weights are already configured.
+func (s *Scorer) withRunWeights(resultsFile string) *Scorer {
+	if s.config.Weights != nil {
+		return s
+	}
+	weights, err := readRunWeights(resultsFile)
+	if err != nil {
+		slog.Warn("failed to read run weights", "results_file", resultsFile, "error", err)
+		return s
+	}
+	if weights == nil {
+		return s
+	}
+	scoped := *s
+	scoped.config.Weights = weights
+	return &scoped
+}
+
+// withAnswerKey returns a scorer using the answer key in the run directory
+// of resultsFile, unless an answer key is already configured.
+func (s *Scorer) withAnswerKey(resultsFile string) *Scorer {
+	if s.config.AnswerKey != nil {
+		return s
+	}
+	key, err := testsuite.ReadAnswerKey(filepath.Dir(resultsFile))
+	if err != nil {
+		slog.Warn("failed to read answer key", "results_file", resultsFile, "error", err)
+		return s
+	}
+	if key == nil {
+		return s
+	}
+	scoped := *s
+	scoped.config.AnswerKey = key
+	return &scoped
+}
+
+// readRunWeights returns the weights recorded in the run metadata, or nil if
+// the run has none.
+func readRunWeights(resultsFile string) (*testsuite.Weights, error) {
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(resultsFile), "resultset.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var meta struct {
+		Weights *testsuite.Weights `json:"weights"`
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("failed to parse run metadata: %w", err)
+	}
+	return meta.Weights, nil
+}
+
+// Rescore re-runs the repetitions of a previous score output that have a
+// parse error, keeping all successful repetitions, and recomputes the summary.
+// The repetitions are re-run with the scoring model, mode, judges, and
+// consensus of previous, so that they are comparable to the kept ones. The
+// indices (1-based) of re-run repetitions are recorded in the metadata.
+func (s *Scorer) Rescore(ctx context.Context, content, resultsFile string, previous *ScoreOutput) (*ScoreOutput, error) {
+	s = s.withSettingsOf(previous)
+	ctx, span := s.startSpan(ctx, "rescore", resultsFile)
+	output, err := s.rescore(ctx, content, resultsFile, previous)
+	tracing.End(span, err)
+	return output, err
+}
+
+// withSettingsOf returns a scorer judging like the scoring of previous.
+// Score files from before the settings were recorded, without a scoring
+// model, leave the settings of s.
+func (s *Scorer) withSettingsOf(previous *ScoreOutput) *Scorer {
+	meta := previous.Metadata
+	if meta.ScoringModel == "" {
+		return s
+	}
+	if meta.ScoringModel != s.config.Model || meta.Mode != s.config.Mode ||
+		!slices.Equal(meta.Judges, s.config.Judges) || meta.Consensus != s.config.Consensus {
+		slog.Warn("rescoring with the settings of the previous scoring",
+			"results_file", meta.ResultsFile,
+			"scoring_model", meta.ScoringModel,
+			"mode", meta.Mode,
+			"judges", len(meta.Judges),
+		)
+	}
+	scoped := *s
+	scoped.config.Model, scoped.config.Mode = meta.ScoringModel, meta.Mode
+	scoped.config.Judges, scoped.config.Consensus = meta.Judges, meta.Consensus
+	return &scoped
+}
+
+// rescore executes Rescore in the span of the scoring.
+func (s *Scorer) rescore(ctx context.Context, content, resultsFile string, previous *ScoreOutput) (*ScoreOutput, error) {
+	output := s.newOutput(resultsFile)
+	output.Metadata.Repetitions = len(previous.Runs)
+	output.Runs = slices.Clone(previous.Runs)
+
+	var failed []int
+	for i, run := range output.Runs {
+		if run.ParseErr != "" {
+			failed = append(failed, i)
+		}
+	}
+
+	var results []testsuite.Result
+	for n, i := range failed {
+		if results == nil {
+			var err error
+			if results, err = s.prepare(content, resultsFile); err != nil {
+				return nil, err
+			}
+		}
+		output.Runs[i] = s.scoreRun(ctx, content, results, i)
+		output.Metadata.RescoredRuns = append(output.Metadata.RescoredRuns, i+1)
+		s.reportProgress(resultsFile, n+1, len(failed))
+	}
+
+	output.Summary = calculateStatistics(output.Runs)
+
+	if err := s.hallucinationPass(ctx, output, content, previous.Hallucinations); err != nil {
+		return nil, err
+	}
+	output.Metadata.Usage = totalUsage(output)
+
+	return output, nil
+}
+
+// hallucinationPass runs the hallucination check when enabled and records
+// its rate in the summary. Answers checked successfully in previous are reused.
+func (s *Scorer) hallucinationPass(ctx context.Context, output *ScoreOutput, content string, previous *HallucinationReport) error {
+	if !s.config.HallucinationCheck {
+		output.Hallucinations = previous
+	} else {
+		results := s.parseResults(content)
+		if len(results) == 0 {
+			return fmt.Errorf("no results found in %s", output.Metadata.ResultsFile)
+		}
+		output.Hallucinations = s.checkHallucinations(ctx, results, previous)
+	}
+
+	if output.Hallucinations != nil && output.Hallucinations.Checked > 0 {
+		rate := output.Hallucinations.Rate
+		output.Summary.HallucinationRate = &rate
+	}
+	return nil
+}
+
+func (s *Scorer) newOutput(resultsFile string) *ScoreOutput {
 	output := &ScoreOutput{
 		Metadata: ScoreMetadata{
 			Timestamp:    time.Now().Format(time.RFC3339),
 			ResultsFile:  resultsFile,
 			ScoringModel: s.config.Model,
 			Repetitions:  s.config.Repetitions,
+
+			Temperature:     *s.config.Temperature,
+			MaxTokens:       s.config.MaxTokens,
+			ReasoningEffort: s.config.ReasoningEffort,
+
+			Mode:      s.config.Mode,
+			Judges:    s.config.Judges,
+			Consensus: s.config.Consensus,
+			Weights:   s.config.Weights,
+
+			HallucinationCheck: s.config.HallucinationCheck,
 		},
 		Runs: make([]RunScore, 0, s.config.Repetitions),
 	}
+	// Aggregate mode scores the run as a whole, so it cannot weigh questions.
+	if s.config.Mode != ModePerQuestion && s.config.Weights != nil && !s.config.Weights.IsZero() {
+		output.Metadata.Weights = nil
+		output.Metadata.Warnings = append(output.Metadata.Warnings, fmt.Sprintf(
+			"the run has rubric weights, which are only applied in %s mode; the scores are unweighted", ModePerQuestion))
+	}
+	return output
+}
 
-	for i := 0; i < s.config.Repetitions; i++ {
-		slog.Info("scoring run",
-			"run", i+1,
-			"total", s.config.Repetitions,
-		)
+// prepare parses the individual results needed for per-question mode.
+// It returns nil in aggregate mode, which sends the whole content at once
+// and therefore cannot score blind runs.
+func (s *Scorer) prepare(content, resultsFile string) ([]testsuite.Result, error) {
+	if s.config.Mode != ModePerQuestion {
+		if s.config.AnswerKey != nil {
+			return nil, fmt.Errorf("%s is from a blind run and can only be scored in %s mode", resultsFile, ModePerQuestion)
+		}
+		return nil, nil
+	}
+	results := s.parseResults(content)
+	if len(results) == 0 {
+		return nil, fmt.Errorf("no results found in %s", resultsFile)
+	}
+	return results, nil
+}
 
-		resultText, err := s.evaluate(ctx, content)
-		if err != nil {
-			slog.Error("scoring run failed", "run", i+1, "error", err)
-			output.Runs = append(output.Runs, RunScore{
-				RawOutput: "",
-				ParseErr:  err.Error(),
-			})
-			continue
+// parseResults parses the results in content, joining the expected answers
+// of blind runs from the answer key.
+func (s *Scorer) parseResults(content string) []testsuite.Result {
+	results := parseResults(content)
+	if s.config.AnswerKey == nil {
+		return results
+	}
+	for i := range results {
+		if results[i].Question.ExpectedAnswer == "" {
+			results[i].Question.ExpectedAnswer = s.config.AnswerKey[results[i].Question.ID]
 		}
+	}
+	return results
+}
 
-		parsed := parseScore(resultText)
-		output.Runs = append(output.Runs, parsed)
+// startSpan starts the span of scoring a results file.
+func (s *Scorer) startSpan(ctx context.Context, name, resultsFile string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, name,
+		tracing.File.String(resultsFile),
+		attribute.String("llm_testing.scoring_model", s.config.Model),
+		attribute.String("llm_testing.scoring_mode", s.config.Mode),
+	)
+}
 
-		if parsed.Correct != nil {
-			slog.Info("score parsed",
-				"run", i+1,
-				"correct", *parsed.Correct,
-				"total", *parsed.Total,
-				"percentage", *parsed.Percent,
-			)
+// scoreRun performs scoring repetition i (0-based) and records its judge usage.
+func (s *Scorer) scoreRun(ctx context.Context, content string, results []testsuite.Result, i int) RunScore {
+	ctx, span := tracing.Start(ctx, "score.repetition", tracing.Repetition.Int(i+1))
+	var usage TokenUsage
+	run := s.judgeRun(ctx, &usage, content, results, i)
+	run.Usage = &usage
+
+	var err error
+	if run.ParseErr != "" {
+		err = errors.New(run.ParseErr)
+	}
+	tracing.End(span, err)
+	return run
+}
+
+// judgeRun judges repetition i, adding the judge usage to usage.
+func (s *Scorer) judgeRun(ctx context.Context, usage *TokenUsage, content string, results []testsuite.Result, i int) RunScore {
+	slog.Info("scoring run",
+		"run", i+1,
+		"total", s.config.Repetitions,
+		"mode", s.config.Mode,
+	)
+
+	if s.config.Mode == ModePerQuestion {
+		return s.scorePerQuestion(ctx, usage, results)
+	}
+
+	resultText, err := s.evaluate(ctx, usage, content)
+	if err != nil {
+		slog.Error("scoring run failed", "run", i+1, "error", err)
+		return RunScore{
+			RawOutput: "",
+			ParseErr:  err.Error(),
 		}
 	}
 
-	output.Summary = calculateStatistics(output.Runs)
+	parsed := parseScore(resultText)
+	if parsed.Correct != nil {
+		slog.Info("score parsed",
+			"run", i+1,
+			"correct", *parsed.Correct,
+			"total", *parsed.Total,
+			"percentage", *parsed.Percent,
+		)
+	}
+	return parsed
+}
 
-	return output, nil
+// ScoreFilePath returns the path of the scores file belonging to a results file.
+func ScoreFilePath(resultsFile string) string {
+	return strings.TrimSuffix(resultsFile, ".txt") + "_scores.json"
+}
+
+// ReadScoreFile reads the existing scores file belonging to a results file.
+func ReadScoreFile(resultsFile string) (*ScoreOutput, error) {
+	data, err := os.ReadFile(ScoreFilePath(resultsFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scores file: %w", err)
+	}
+
+	var output ScoreOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, fmt.Errorf("failed to parse scores file: %w", err)
+	}
+	return &output, nil
 }
 
 // WriteScoreFile writes the score output as JSON next to the results file.
 func WriteScoreFile(output *ScoreOutput, resultsFile string) (string, error) {
-	scoresFile := strings.TrimSuffix(resultsFile, ".txt") + "_scores.json"
+	scoresFile := ScoreFilePath(resultsFile)
 
 	data, err := json.MarshalIndent(output, "", "  ")
 	if err != nil {
@@ -148,17 +599,126 @@ func WriteScoreFile(output *ScoreOutput, resultsFile string) (string, error) {
 	return scoresFile, nil
 }
 
-func (s *Scorer) evaluate(ctx context.Context, content string) (string, error) {
+// JudgeResult evaluates a single answer and reports whether it is correct.
+func (s *Scorer) JudgeResult(ctx context.Context, result *testsuite.Result) (bool, error) {
+	return s.judgeWith(ctx, nil, s.config.Model, result)
+}
+
+func (s *Scorer) judgeWith(ctx context.Context, usage *TokenUsage, model string, result *testsuite.Result) (bool, error) {
+	text, err := s.complete(ctx, usage, model, QuestionEvaluationPrompt, formatResult(result))
+	if err != nil {
+		return false, err
+	}
+	return parseVerdict(text)
+}
+
+// scorePerQuestion runs one scoring repetition in per-question mode: every
+// judge votes on every answer and the votes are combined by the consensus rule.
+// The judge usage is added to usage.
+func (s *Scorer) scorePerQuestion(ctx context.Context, usage *TokenUsage, results []testsuite.Result) RunScore {
+	weights := make(map[string]float64, len(s.config.Judges))
+	for _, j := range s.config.Judges {
+		weights[j.Model] = j.Weight
+	}
+
+	verdicts := make([]QuestionVerdict, 0, len(results))
+	correct, judged := 0, 0
+	for i := range results {
+		votes := make([]JudgeVote, 0, len(s.config.Judges))
+		for _, j := range s.config.Judges {
+			vote := JudgeVote{Model: j.Model}
+			ok, err := s.judgeWith(ctx, usage, j.Model, &results[i])
+			if err != nil {
+				slog.Warn("judge failed", "judge", j.Model, "question_id", results[i].Question.ID, "error", err)
+				vote.Error = err.Error()
+			} else {
+				vote.Correct = &ok
+			}
+			votes = append(votes, vote)
+		}
+
+		verdict := consensus(s.config.Consensus, votes, weights)
+		if verdict != nil {
+			judged++
+			if *verdict {
+				correct++
+			}
+		}
+		verdicts = append(verdicts, QuestionVerdict{
+			ID:         results[i].Question.ID,
+			Section:    results[i].Question.Section,
+			Difficulty: results[i].Question.Difficulty,
+			Tags:       results[i].Question.Tags,
+			Correct:    verdict,
+			Votes:      votes,
+		})
+	}
+
+	run := RunScore{Questions: verdicts}
+	if judged < len(results) {
+		run.ParseErr = fmt.Sprintf("%d of %d questions could not be judged", len(results)-judged, len(results))
+	}
+	total := len(results)
+	pct := math.Round(float64(correct)/float64(total)*10000) / 100
+	run.Correct, run.Total, run.Percent = &correct, &total, &pct
+
+	var rubric *testsuite.Weights
+	if s.config.Weights != nil && !s.config.Weights.IsZero() {
+		rubric = s.config.Weights
+		applyWeights(&run, *rubric)
+	}
+	run.Breakdown = breakdown(run.Questions, rubric)
+	return run
+}
+
+// applyWeights computes the rubric-weighted totals of a per-question run.
+// Unjudged questions count towards the total but not the correct points.
+func applyWeights(run *RunScore, weights testsuite.Weights) {
+	var correct, total float64
+	for _, q := range run.Questions {
+		w := weights.For(q.ID, q.Section)
+		total += w
+		if q.Correct != nil && *q.Correct {
+			correct += w
+		}
+	}
+	var pct float64
+	if total > 0 {
+		pct = math.Round(correct/total*10000) / 100
+	}
+	run.WeightedCorrect, run.WeightedTotal, run.WeightedPercent = &correct, &total, &pct
+}
+
+func (s *Scorer) evaluate(ctx context.Context, usage *TokenUsage, content string) (string, error) {
+	return s.complete(ctx, usage, s.config.Model, EvaluationPrompt, content)
+}
+
+// complete sends content to the judge model and returns its answer. The
+// tokens of the call are added to usage (optional), which belongs to the
+// caller, so that concurrent calls do not share it.
+func (s *Scorer) complete(ctx context.Context, usage *TokenUsage, model, systemPrompt, content string) (string, error) {
+	req := llm.ChatRequest{
+		Model:           model,
+		SystemMessage:   systemPrompt,
+		UserMessage:     content,
+		Temperature:     s.config.Temperature,
+		MaxTokens:       s.config.MaxTokens,
+		ReasoningEffort: s.config.ReasoningEffort,
+	}
+
+	client := s.client
+	if c, ok := s.config.Clients[model]; ok {
+		client = c
+	}
+
 	// Try streaming first.
-	stream, err := s.client.ChatCompletionStream(ctx, llm.ChatRequest{
-		Model:         s.config.Model,
-		SystemMessage: EvaluationPrompt,
-		UserMessage:   content,
-		Temperature:   llm.Float64Ptr(0),
-	})
+	stream, err := client.ChatCompletionStream(ctx, req)
 	if err == nil {
 		result, streamErr := llm.CollectStream(stream)
 		if streamErr == nil {
+			if usage != nil {
+				usage.record(model, stream.Usage(), s.config.Prices)
+			}
 			return result, nil
 		}
 		slog.Warn("streaming evaluation failed, falling back to non-streaming", "error", streamErr)
@@ -167,19 +727,64 @@ func (s *Scorer) evaluate(ctx context.Context, content string) (string, error) {
 	}
 
 	// Fallback to non-streaming.
-	resp, err := s.client.ChatCompletion(ctx, llm.ChatRequest{
-		Model:         s.config.Model,
-		SystemMessage: EvaluationPrompt,
-		UserMessage:   content,
-		Temperature:   llm.Float64Ptr(0),
-	})
+	resp, err := client.ChatCompletion(ctx, req)
 	if err != nil {
 		return "", fmt.Errorf("evaluation failed: %w", err)
 	}
+	if usage != nil {
+		usage.record(model, resp.Usage, s.config.Prices)
+	}
 
 	return resp.Content, nil
 }
 
+// formatResult renders a single result in the same layout as the QA results file.
+func formatResult(r *testsuite.Result) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "---\n")
+	fmt.Fprintf(&b, "NO. %s - %s\n", r.Question.ID, r.Question.Section)
+	if r.Question.Difficulty != "" {
+		fmt.Fprintf(&b, "DIFFICULTY: %s\n", r.Question.Difficulty)
+	}
+	if len(r.Question.Tags) > 0 {
+		fmt.Fprintf(&b, "TAGS: %s\n", strings.Join(r.Question.Tags, ", "))
+	}
+	fmt.Fprintf(&b, "QUESTION: %s\n", r.Question.QuestionText)
+	fmt.Fprintf(&b, "EXPECTED ANSWER: %s\n", r.Question.ExpectedAnswer)
+	fmt.Fprintf(&b, "ACTUAL ANSWER: %s\n", r.Answer)
+	return b.String()
+}
+
+var (
+	verdictPattern     = regexp.MustCompile(`(?i)\b(INCORRECT|CORRECT)\b`)
+	upperVerdictTokens = regexp.MustCompile(`\b(INCORRECT|CORRECT)\b`)
+)
+
+// parseVerdict returns the verdict of a judge. Upper-case verdicts, as the
+// prompts ask for, are told apart from the words in prose around them: the
+// last one is the conclusion of judges that reason before answering, e.g.
+// "The term 'correct' is used loosely here ... INCORRECT", while prose
+// after it, e.g. "INCORRECT\nThe answer is not correct.", is ignored.
+// Without one, the first verdict in any case is taken.
+func parseVerdict(text string) (bool, error) {
+	if matches := upperVerdictTokens.FindAllString(text, -1); len(matches) > 0 {
+		return matches[len(matches)-1] == "CORRECT", nil
+	}
+	match := verdictPattern.FindString(text)
+	if match == "" {
+		return false, fmt.Errorf("could not parse verdict from output: %q", text)
+	}
+	return strings.EqualFold(match, "CORRECT"), nil
+}
+
+// verdictDetails returns what a judge wrote after its verdict line, e.g. the
+// fabricated items of a hallucination check or the reason an answer is
+// ambiguous.
+func verdictDetails(text string) string {
+	_, rest, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	return strings.TrimSpace(rest)
+}
+
 var scorePattern = regexp.MustCompile(`(\d+)\s+out\s+of\s+(\d+)`)
 
 func parseScore(text string) RunScore {
@@ -209,12 +814,16 @@ func parseScore(text string) RunScore {
 func calculateStatistics(runs []RunScore) Summary {
 	var correctValues []int
 	var percentValues []float64
+	allParsed := true
 
 	for _, r := range runs {
 		if r.Correct != nil {
 			correctValues = append(correctValues, *r.Correct)
 			percentValues = append(percentValues, *r.Percent)
 		}
+		if r.Correct == nil || r.ParseErr != "" {
+			allParsed = false
+		}
 	}
 
 	if len(correctValues) == 0 {
@@ -227,14 +836,66 @@ func calculateStatistics(runs []RunScore) Summary {
 	maxC := slices.Max(correctValues)
 	variance := varianceFloat(correctValues, meanCorrect)
 
-	return Summary{
-		MeanCorrect:   &meanCorrect,
-		MeanPercent:   &meanPercent,
-		MinCorrect:    &minC,
-		MaxCorrect:    &maxC,
-		Variance:      &variance,
-		AllRunsParsed: len(correctValues) == len(runs),
+	summary := Summary{
+		MeanCorrect:       &meanCorrect,
+		MeanPercent:       &meanPercent,
+		MinCorrect:        &minC,
+		MaxCorrect:        &maxC,
+		Variance:          &variance,
+		AllRunsParsed:     allParsed,
+		UnstableQuestions: findUnstableQuestions(runs),
+	}
+
+	var weightedCorrect, weightedPercent []float64
+	for _, r := range runs {
+		if r.WeightedCorrect != nil {
+			weightedCorrect = append(weightedCorrect, *r.WeightedCorrect)
+			weightedPercent = append(weightedPercent, *r.WeightedPercent)
+			summary.WeightedTotal = r.WeightedTotal
+		}
+	}
+	if len(weightedCorrect) > 0 {
+		meanWeighted := meanFloat(weightedCorrect)
+		meanWeightedPercent := meanFloat(weightedPercent)
+		summary.MeanWeightedCorrect = &meanWeighted
+		summary.MeanWeightedPercent = &meanWeightedPercent
+	}
+
+	return summary
+}
+
+// findUnstableQuestions returns the questions that were judged correct in some
+// repetitions and incorrect in others, in the order they appear in the results.
+func findUnstableQuestions(runs []RunScore) []UnstableQuestion {
+	var order []string
+	byID := make(map[string]*UnstableQuestion)
+
+	for _, r := range runs {
+		for _, q := range r.Questions {
+			if q.Correct == nil {
+				continue
+			}
+			u, ok := byID[q.ID]
+			if !ok {
+				u = &UnstableQuestion{ID: q.ID, Section: q.Section}
+				byID[q.ID] = u
+				order = append(order, q.ID)
+			}
+			u.JudgedRuns++
+			if *q.Correct {
+				u.CorrectRuns++
+			}
+		}
+	}
+
+	var unstable []UnstableQuestion
+	for _, id := range order {
+		u := byID[id]
+		if u.CorrectRuns > 0 && u.CorrectRuns < u.JudgedRuns {
+			unstable = append(unstable, *u)
+		}
 	}
+	return unstable
 }
 
 func meanInt(vals []int) float64 {
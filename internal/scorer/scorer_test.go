@@ -4,11 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/giantswarm/llm-testing/internal/llm"
+	"github.com/giantswarm/llm-testing/internal/testsuite"
 	"github.com/giantswarm/llm-testing/internal/testutil"
 )
 
@@ -169,6 +172,22 @@ func TestScorerScore(t *testing.T) {
 	assert.InDelta(t, 0.0, *output.Summary.Variance, 0.01)
 }
 
+func TestScorerReportsProgress(t *testing.T) {
+	client := &testutil.MockLLMClient{DefaultResponse: "72 out of 100"}
+	s := NewScorer(client, Config{Repetitions: 3})
+
+	var completed []int
+	s.SetProgressFunc(func(resultsFile string, done, total int) {
+		assert.Equal(t, "test.txt", resultsFile)
+		assert.Equal(t, 3, total)
+		completed = append(completed, done)
+	})
+
+	_, err := s.Score(context.Background(), "test content", "test.txt")
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, completed)
+}
+
 func TestScorerDefaultRepetitions(t *testing.T) {
 	s := NewScorer(&testutil.MockLLMClient{DefaultResponse: "50 out of 100"}, Config{})
 	assert.Equal(t, 3, s.config.Repetitions)
@@ -280,3 +299,401 @@ func TestScorerHandlesParseFailure(t *testing.T) {
 	assert.Nil(t, output.Summary.MeanCorrect)
 	assert.False(t, output.Summary.AllRunsParsed)
 }
+
+func TestScorerPassesJudgeParameters(t *testing.T) {
+	client := &testutil.MockLLMClient{DefaultResponse: "50 out of 100"}
+
+	s := NewScorer(client, Config{
+		Model:           "judge",
+		Repetitions:     1,
+		Temperature:     llm.Float64Ptr(0.3),
+		MaxTokens:       512,
+		ReasoningEffort: llm.ReasoningEffortHigh,
+	})
+
+	output, err := s.Score(context.Background(), "content", "file.txt")
+	require.NoError(t, err)
+
+	require.NotNil(t, client.LastRequest.Temperature)
+	assert.InDelta(t, 0.3, *client.LastRequest.Temperature, 0.001)
+	assert.Equal(t, 512, client.LastRequest.MaxTokens)
+	assert.Equal(t, llm.ReasoningEffortHigh, client.LastRequest.ReasoningEffort)
+
+	assert.InDelta(t, 0.3, output.Metadata.Temperature, 0.001)
+	assert.Equal(t, 512, output.Metadata.MaxTokens)
+	assert.Equal(t, llm.ReasoningEffortHigh, output.Metadata.ReasoningEffort)
+}
+
+func TestScorerDefaultTemperature(t *testing.T) {
+	client := &testutil.MockLLMClient{DefaultResponse: "50 out of 100"}
+	s := NewScorer(client, Config{Repetitions: 1})
+
+	_, err := s.Score(context.Background(), "content", "file.txt")
+	require.NoError(t, err)
+
+	require.NotNil(t, client.LastRequest.Temperature)
+	assert.Zero(t, *client.LastRequest.Temperature)
+	assert.Zero(t, client.LastRequest.MaxTokens)
+	assert.Empty(t, client.LastRequest.ReasoningEffort)
+}
+
+func TestParseVerdict(t *testing.T) {
+	tests := []struct {
+		input   string
+		correct bool
+		hasErr  bool
+	}{
+		{input: "CORRECT", correct: true},
+		{input: "incorrect.", correct: false},
+		{input: "The answer is INCORRECT because it omits the flag.", correct: false},
+		{input: "Verdict: Correct", correct: true},
+		{input: "The expected answer is partly correct, but the flag is wrong.\nINCORRECT", correct: false},
+		{input: "Incorrect at first glance, yet equivalent.\nVERDICT: CORRECT", correct: true},
+		{input: "INCORRECT\nThe answer is not correct.", correct: false},
+		{input: "CORRECT\nIt is not incorrect to omit the namespace.", correct: true},
+		{input: "incorrect\nthe answer is not correct", correct: false},
+		{input: "I am not sure.", hasErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			correct, err := parseVerdict(tt.input)
+			if tt.hasErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.correct, correct)
+		})
+	}
+}
+
+func TestJudgeResult(t *testing.T) {
+	client := &testutil.MockLLMClient{DefaultResponse: "CORRECT"}
+	s := NewScorer(client, Config{Model: "judge", Repetitions: 1})
+
+	correct, err := s.JudgeResult(context.Background(), &testsuite.Result{
+		Question: testsuite.Question{ID: "7", Section: "Pods", QuestionText: "List pods?", ExpectedAnswer: "kubectl get pods"},
+		Answer:   "kubectl get po",
+	})
+	require.NoError(t, err)
+	assert.True(t, correct)
+
+	assert.Equal(t, QuestionEvaluationPrompt, client.LastRequest.SystemMessage)
+	assert.Contains(t, client.LastRequest.UserMessage, "NO. 7 - Pods")
+	assert.Contains(t, client.LastRequest.UserMessage, "ACTUAL ANSWER: kubectl get po")
+}
+
+func TestScorerUsesClientsOfJudges(t *testing.T) {
+	client := &testutil.MockLLMClient{DefaultResponse: "INCORRECT"}
+	other := &testutil.MockLLMClient{DefaultResponse: "CORRECT"}
+	s := NewScorer(client, Config{Model: "other-judge", Repetitions: 1, Clients: map[string]llm.Client{"other-judge": other}})
+
+	correct, err := s.JudgeResult(context.Background(), &testsuite.Result{
+		Question: testsuite.Question{ID: "7", QuestionText: "List pods?", ExpectedAnswer: "kubectl get pods"},
+		Answer:   "kubectl get po",
+	})
+	require.NoError(t, err)
+	assert.True(t, correct)
+	assert.Equal(t, 1, other.Calls)
+	assert.Zero(t, client.Calls)
+}
+
+func TestParseResults(t *testing.T) {
+	content := `---
+NO. 1 - Setup & Aliases
+QUESTION: What is the alias?
+EXPECTED ANSWER: alias k=kubectl
+ACTUAL ANSWER: alias k=kubectl
+---
+NO. 2 - Pods
+QUESTION: How to list pods?
+EXPECTED ANSWER: kubectl get pods
+ACTUAL ANSWER: ` + "```bash" + `
+kubectl get pods
+` + "```" + `
+`
+	results := parseResults(content)
+	require.Len(t, results, 2)
+	assert.Equal(t, "1", results[0].Question.ID)
+	assert.Equal(t, "Setup & Aliases", results[0].Question.Section)
+	assert.Equal(t, "What is the alias?", results[0].Question.QuestionText)
+	assert.Equal(t, "alias k=kubectl", results[0].Question.ExpectedAnswer)
+	assert.Equal(t, "alias k=kubectl", results[0].Answer)
+	assert.Equal(t, "2", results[1].Question.ID)
+	assert.Contains(t, results[1].Answer, "kubectl get pods")
+}
+
+func TestScorerPerQuestionMode(t *testing.T) {
+	content := `---
+NO. 1 - S
+QUESTION: Q1
+EXPECTED ANSWER: A1
+ACTUAL ANSWER: A1
+---
+NO. 2 - S
+QUESTION: Q2
+EXPECTED ANSWER: A2
+ACTUAL ANSWER: wrong
+`
+	client := &testutil.MockLLMClient{
+		Responses: map[string]string{
+			"---\nNO. 1 - S\nQUESTION: Q1\nEXPECTED ANSWER: A1\nACTUAL ANSWER: A1\n":    "CORRECT",
+			"---\nNO. 2 - S\nQUESTION: Q2\nEXPECTED ANSWER: A2\nACTUAL ANSWER: wrong\n": "INCORRECT",
+		},
+	}
+
+	s := NewScorer(client, Config{
+		Repetitions: 2,
+		Mode:        ModePerQuestion,
+		Judges:      []Judge{{Model: "judge-a"}, {Model: "judge-b", Weight: 0.8}},
+		Consensus:   ConsensusUnanimous,
+	})
+
+	output, err := s.Score(context.Background(), content, "file.txt")
+	require.NoError(t, err)
+
+	assert.Equal(t, ModePerQuestion, output.Metadata.Mode)
+	assert.Equal(t, ConsensusUnanimous, output.Metadata.Consensus)
+	assert.Equal(t, 8, client.Calls) // 2 questions x 2 judges x 2 repetitions
+
+	require.Len(t, output.Runs, 2)
+	run := output.Runs[0]
+	require.NotNil(t, run.Correct)
+	assert.Equal(t, 1, *run.Correct)
+	assert.Equal(t, 2, *run.Total)
+	require.Len(t, run.Questions, 2)
+	assert.Len(t, run.Questions[0].Votes, 2)
+	require.NotNil(t, run.Questions[0].Correct)
+	assert.True(t, *run.Questions[0].Correct)
+	assert.False(t, *run.Questions[1].Correct)
+	assert.True(t, output.Summary.AllRunsParsed)
+}
+
+func TestScoreFileAppliesRunWeights(t *testing.T) {
+	dir := t.TempDir()
+	content := `---
+NO. 1 - Basics
+QUESTION: Q1
+EXPECTED ANSWER: A1
+ACTUAL ANSWER: wrong
+---
+NO. 2 - Troubleshooting
+QUESTION: Q2
+EXPECTED ANSWER: A2
+ACTUAL ANSWER: A2
+`
+	resultsFile := filepath.Join(dir, "model.txt")
+	require.NoError(t, os.WriteFile(resultsFile, []byte(content), 0o644))
+	meta := `{"id": "run", "weights": {"sections": {"Troubleshooting": 2}}}`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "resultset.json"), []byte(meta), 0o644))
+
+	client := &testutil.MockLLMClient{
+		Responses: map[string]string{
+			"---\nNO. 1 - Basics\nQUESTION: Q1\nEXPECTED ANSWER: A1\nACTUAL ANSWER: wrong\n":       "INCORRECT",
+			"---\nNO. 2 - Troubleshooting\nQUESTION: Q2\nEXPECTED ANSWER: A2\nACTUAL ANSWER: A2\n": "CORRECT",
+		},
+	}
+	s := NewScorer(client, Config{Repetitions: 2, Mode: ModePerQuestion})
+
+	output, err := s.ScoreFile(context.Background(), resultsFile)
+	require.NoError(t, err)
+
+	require.NotNil(t, output.Metadata.Weights)
+	run := output.Runs[0]
+	assert.Equal(t, 1, *run.Correct)
+	require.NotNil(t, run.WeightedCorrect)
+	assert.Equal(t, 2.0, *run.WeightedCorrect)
+	assert.Equal(t, 3.0, *run.WeightedTotal)
+	assert.Equal(t, 66.67, *run.WeightedPercent)
+
+	assert.Equal(t, 50.0, *output.Summary.MeanPercent)
+	require.NotNil(t, output.Summary.MeanWeightedPercent)
+	assert.Equal(t, 66.67, *output.Summary.MeanWeightedPercent)
+	assert.Equal(t, 2.0, *output.Summary.MeanWeightedCorrect)
+	assert.Equal(t, 3.0, *output.Summary.WeightedTotal)
+}
+
+func TestScoreFileWarnsOfRunWeightsInAggregateMode(t *testing.T) {
+	dir := t.TempDir()
+	resultsFile := filepath.Join(dir, "model.txt")
+	require.NoError(t, os.WriteFile(resultsFile, []byte("---\nNO. 1 - Basics\nQUESTION: Q1\nEXPECTED ANSWER: A1\nACTUAL ANSWER: A1\n"), 0o644))
+	meta := `{"id": "run", "weights": {"sections": {"Basics": 2}}}`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "resultset.json"), []byte(meta), 0o644))
+
+	client := &testutil.MockLLMClient{DefaultResponse: "1 out of 1"}
+	output, err := NewScorer(client, Config{Repetitions: 1}).ScoreFile(context.Background(), resultsFile)
+	require.NoError(t, err)
+
+	assert.Nil(t, output.Metadata.Weights, "the weights were not applied")
+	require.Len(t, output.Metadata.Warnings, 1)
+	assert.Contains(t, output.Metadata.Warnings[0], "only applied in per_question mode")
+	assert.Nil(t, output.Summary.MeanWeightedPercent)
+}
+
+func TestScoreFileJoinsAnswerKeyOfBlindRun(t *testing.T) {
+	dir := t.TempDir()
+	content := "---\nNO. 1 - Basics\nQUESTION: Q1\nACTUAL ANSWER: wrong\n---\nNO. 2 - Basics\nQUESTION: Q2\nACTUAL ANSWER: A2\n"
+	resultsFile := filepath.Join(dir, "model.txt")
+	require.NoError(t, os.WriteFile(resultsFile, []byte(content), 0o644))
+	require.NoError(t, testsuite.WriteAnswerKey(dir, []testsuite.Question{
+		{ID: "1", ExpectedAnswer: "A1"},
+		{ID: "2", ExpectedAnswer: "A2"},
+	}))
+
+	client := &testutil.MockLLMClient{
+		Responses: map[string]string{
+			"---\nNO. 1 - Basics\nQUESTION: Q1\nEXPECTED ANSWER: A1\nACTUAL ANSWER: wrong\n": "INCORRECT",
+			"---\nNO. 2 - Basics\nQUESTION: Q2\nEXPECTED ANSWER: A2\nACTUAL ANSWER: A2\n":    "CORRECT",
+		},
+	}
+	output, err := NewScorer(client, Config{Repetitions: 1, Mode: ModePerQuestion}).ScoreFile(context.Background(), resultsFile)
+	require.NoError(t, err)
+	assert.Equal(t, 1, *output.Runs[0].Correct)
+	assert.Equal(t, 2, *output.Runs[0].Total)
+
+	_, err = NewScorer(client, Config{Repetitions: 1}).ScoreFile(context.Background(), resultsFile)
+	assert.ErrorContains(t, err, "blind run and can only be scored in per_question mode")
+}
+
+func TestScorerWithoutWeightsOmitsWeightedTotals(t *testing.T) {
+	content := "---\nNO. 1 - S\nQUESTION: Q1\nEXPECTED ANSWER: A1\nACTUAL ANSWER: A1\n"
+	client := &testutil.MockLLMClient{DefaultResponse: "CORRECT"}
+	s := NewScorer(client, Config{Repetitions: 1, Mode: ModePerQuestion})
+
+	output, err := s.Score(context.Background(), content, "file.txt")
+	require.NoError(t, err)
+	assert.Nil(t, output.Runs[0].WeightedCorrect)
+	assert.Nil(t, output.Summary.MeanWeightedPercent)
+}
+
+func TestScorerPerQuestionDefaultsToSingleJudge(t *testing.T) {
+	s := NewScorer(&testutil.MockLLMClient{}, Config{Model: "judge", Mode: ModePerQuestion})
+	assert.Equal(t, []Judge{{Model: "judge"}}, s.config.Judges)
+	assert.Equal(t, ConsensusMajority, s.config.Consensus)
+}
+
+func TestRescoreOnlyFailedRuns(t *testing.T) {
+	c1, t1, p1 := 70, 100, 70.0
+	previous := &ScoreOutput{
+		Runs: []RunScore{
+			{Correct: &c1, Total: &t1, Percent: &p1, RawOutput: "70 out of 100"},
+			{RawOutput: "no idea", ParseErr: "Could not parse score from output"},
+			{ParseErr: "evaluation failed: timeout"},
+		},
+	}
+
+	client := &testutil.MockLLMClient{DefaultResponse: "76 out of 100 answers are correct."}
+	s := NewScorer(client, Config{Model: "judge", Repetitions: 5})
+
+	output, err := s.Rescore(context.Background(), "content", "file.txt", previous)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, client.Calls)
+	assert.Equal(t, []int{2, 3}, output.Metadata.RescoredRuns)
+	assert.Equal(t, 3, output.Metadata.Repetitions)
+	require.Len(t, output.Runs, 3)
+	assert.Equal(t, 70, *output.Runs[0].Correct)
+	assert.Equal(t, 76, *output.Runs[1].Correct)
+	assert.Equal(t, 76, *output.Runs[2].Correct)
+	assert.True(t, output.Summary.AllRunsParsed)
+	assert.InDelta(t, 74.0, *output.Summary.MeanCorrect, 0.01)
+
+	// The previous output is left untouched.
+	assert.NotEmpty(t, previous.Runs[1].ParseErr)
+}
+
+func TestRescoreUsesPreviousSettings(t *testing.T) {
+	c1, t1, p1 := 70, 100, 70.0
+	previous := &ScoreOutput{
+		Metadata: ScoreMetadata{ScoringModel: "old-judge", Mode: ModeAggregate},
+		Runs: []RunScore{
+			{Correct: &c1, Total: &t1, Percent: &p1, RawOutput: "70 out of 100"},
+			{ParseErr: "evaluation failed: timeout"},
+		},
+	}
+
+	client := &testutil.MockLLMClient{DefaultResponse: "76 out of 100 answers are correct."}
+	s := NewScorer(client, Config{Model: "new-judge", Mode: ModePerQuestion, Judges: []Judge{{Model: "a"}, {Model: "b"}}})
+
+	output, err := s.Rescore(context.Background(), "content", "file.txt", previous)
+	require.NoError(t, err)
+	assert.Equal(t, "old-judge", client.LastRequest.Model)
+	assert.Equal(t, "old-judge", output.Metadata.ScoringModel)
+	assert.Equal(t, ModeAggregate, output.Metadata.Mode)
+	assert.Empty(t, output.Metadata.Judges)
+	assert.Equal(t, 76, *output.Runs[1].Correct)
+}
+
+func TestRescoreFileWithoutPreviousScores(t *testing.T) {
+	resultsFile := t.TempDir() + "/model.txt"
+	require.NoError(t, os.WriteFile(resultsFile, []byte("content"), 0o644))
+
+	client := &testutil.MockLLMClient{DefaultResponse: "10 out of 10"}
+	s := NewScorer(client, Config{Repetitions: 2})
+
+	output, err := s.RescoreFile(context.Background(), resultsFile)
+	require.NoError(t, err)
+	assert.Len(t, output.Runs, 2)
+	assert.Empty(t, output.Metadata.RescoredRuns)
+}
+
+func TestRescoreFileMergesExistingScores(t *testing.T) {
+	resultsFile := t.TempDir() + "/model.txt"
+	require.NoError(t, os.WriteFile(resultsFile, []byte("content"), 0o644))
+
+	c1, t1, p1 := 5, 10, 50.0
+	_, err := WriteScoreFile(&ScoreOutput{
+		Runs: []RunScore{
+			{Correct: &c1, Total: &t1, Percent: &p1},
+			{ParseErr: "failed"},
+		},
+	}, resultsFile)
+	require.NoError(t, err)
+
+	client := &testutil.MockLLMClient{DefaultResponse: "7 out of 10"}
+	s := NewScorer(client, Config{Repetitions: 3})
+
+	output, err := s.RescoreFile(context.Background(), resultsFile)
+	require.NoError(t, err)
+	assert.Equal(t, 1, client.Calls)
+	assert.Equal(t, []int{2}, output.Metadata.RescoredRuns)
+	assert.Equal(t, 5, *output.Runs[0].Correct)
+	assert.Equal(t, 7, *output.Runs[1].Correct)
+}
+
+func TestFindUnstableQuestions(t *testing.T) {
+	yes, no := true, false
+	c, total, pct := 1, 2, 50.0
+	runs := []RunScore{
+		{Correct: &c, Total: &total, Percent: &pct, Questions: []QuestionVerdict{
+			{ID: "1", Section: "S", Correct: &yes},
+			{ID: "2", Section: "S", Correct: &no},
+			{ID: "3", Section: "S", Correct: &yes},
+		}},
+		{Correct: &c, Total: &total, Percent: &pct, Questions: []QuestionVerdict{
+			{ID: "1", Section: "S", Correct: &yes},
+			{ID: "2", Section: "S", Correct: &yes},
+			{ID: "3", Section: "S", Correct: nil},
+		}},
+		{Correct: &c, Total: &total, Percent: &pct, Questions: []QuestionVerdict{
+			{ID: "1", Section: "S", Correct: &yes},
+			{ID: "2", Section: "S", Correct: &no},
+			{ID: "3", Section: "S", Correct: &yes},
+		}},
+	}
+
+	stats := calculateStatistics(runs)
+	assert.Equal(t, []UnstableQuestion{
+		{ID: "2", Section: "S", CorrectRuns: 1, JudgedRuns: 3},
+	}, stats.UnstableQuestions)
+}
+
+func TestFindUnstableQuestionsAggregateMode(t *testing.T) {
+	c, total, pct := 1, 2, 50.0
+	stats := calculateStatistics([]RunScore{
+		{Correct: &c, Total: &total, Percent: &pct},
+		{Correct: &c, Total: &total, Percent: &pct},
+	})
+	assert.Empty(t, stats.UnstableQuestions)
+}
@@ -0,0 +1,134 @@
+package scorer
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/giantswarm/llm-testing/internal/llm"
+)
+
+// Price is the cost of a judge model in USD per million tokens.
+type Price struct {
+	Prompt     float64 `yaml:"prompt" json:"prompt"`
+	Completion float64 `yaml:"completion" json:"completion"`
+}
+
+// PriceTable maps judge model names to their prices.
+type PriceTable map[string]Price
+
+type priceFile struct {
+	Prices PriceTable `yaml:"prices"`
+}
+
+// LoadPriceTable reads judge prices from a YAML file of the form:
+//
+//	prices:
+//	  gpt-5:
+//	    prompt: 1.25      # USD per million prompt tokens
+//	    completion: 10.0  # USD per million completion tokens
+func LoadPriceTable(path string) (PriceTable, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read price table: %w", err)
+	}
+
+	var f priceFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse price table: %w", err)
+	}
+	for model, p := range f.Prices {
+		if p.Prompt < 0 || p.Completion < 0 {
+			return nil, fmt.Errorf("price for model %q must not be negative", model)
+		}
+	}
+	if f.Prices == nil {
+		f.Prices = PriceTable{}
+	}
+	return f.Prices, nil
+}
+
+// TokenUsage counts the judge calls and tokens spent on scoring.
+type TokenUsage struct {
+	Calls            int `json:"calls"`
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+
+	// Cost is the price in USD, set only when a price table is configured.
+	// Calls to models missing from the table are listed in UnpricedModels
+	// and not included in the cost.
+	Cost           *float64 `json:"cost_usd,omitempty"`
+	UnpricedModels []string `json:"unpriced_models,omitempty"`
+}
+
+// record adds a single judge call to u.
+func (u *TokenUsage) record(model string, usage llm.Usage, prices PriceTable) {
+	u.Calls++
+	u.PromptTokens += usage.PromptTokens
+	u.CompletionTokens += usage.CompletionTokens
+	u.TotalTokens += usage.PromptTokens + usage.CompletionTokens
+
+	if prices == nil {
+		return
+	}
+	var cost float64
+	if u.Cost != nil {
+		cost = *u.Cost
+	}
+	if p, ok := prices[model]; ok {
+		cost += (float64(usage.PromptTokens)*p.Prompt + float64(usage.CompletionTokens)*p.Completion) / 1e6
+	} else if !slices.Contains(u.UnpricedModels, model) {
+		u.UnpricedModels = append(u.UnpricedModels, model)
+	}
+	cost = roundCost(cost)
+	u.Cost = &cost
+}
+
+// Add adds the counts of o to u.
+func (u *TokenUsage) Add(o *TokenUsage) {
+	if o == nil {
+		return
+	}
+	u.Calls += o.Calls
+	u.PromptTokens += o.PromptTokens
+	u.CompletionTokens += o.CompletionTokens
+	u.TotalTokens += o.TotalTokens
+	if o.Cost != nil {
+		cost := *o.Cost
+		if u.Cost != nil {
+			cost += *u.Cost
+		}
+		cost = roundCost(cost)
+		u.Cost = &cost
+	}
+	for _, m := range o.UnpricedModels {
+		if !slices.Contains(u.UnpricedModels, m) {
+			u.UnpricedModels = append(u.UnpricedModels, m)
+		}
+	}
+}
+
+// totalUsage sums the usage of all repetitions and the hallucination check.
+// It returns nil when no usage was recorded, e.g. for score files written
+// before usage was tracked.
+func totalUsage(output *ScoreOutput) *TokenUsage {
+	var total TokenUsage
+	for _, run := range output.Runs {
+		total.Add(run.Usage)
+	}
+	if output.Hallucinations != nil {
+		total.Add(output.Hallucinations.Usage)
+	}
+	if total.Calls == 0 {
+		return nil
+	}
+	return &total
+}
+
+func roundCost(v float64) float64 {
+	return math.Round(v*1e6) / 1e6
+}
@@ -0,0 +1,146 @@
+package scorer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/giantswarm/llm-testing/internal/llm"
+	"github.com/giantswarm/llm-testing/internal/testsuite"
+	"github.com/giantswarm/llm-testing/internal/testutil"
+)
+
+func TestScorerRecordsUsage(t *testing.T) {
+	client := &testutil.MockLLMClient{
+		DefaultResponse: "72 out of 100",
+		Usage:           llm.Usage{PromptTokens: 1000, CompletionTokens: 200},
+	}
+	s := NewScorer(client, Config{Model: "judge", Repetitions: 3})
+
+	output, err := s.Score(context.Background(), "content", "test.txt")
+	require.NoError(t, err)
+
+	for _, run := range output.Runs {
+		require.NotNil(t, run.Usage)
+		assert.Equal(t, TokenUsage{Calls: 1, PromptTokens: 1000, CompletionTokens: 200, TotalTokens: 1200}, *run.Usage)
+	}
+	require.NotNil(t, output.Metadata.Usage)
+	assert.Equal(t, 3, output.Metadata.Usage.Calls)
+	assert.Equal(t, 3600, output.Metadata.Usage.TotalTokens)
+	assert.Nil(t, output.Metadata.Usage.Cost, "no cost without a price table")
+}
+
+// statelessClient answers every request alike and is safe for concurrent use.
+type statelessClient struct {
+	content string
+	usage   llm.Usage
+}
+
+func (c statelessClient) ChatCompletion(context.Context, llm.ChatRequest) (*llm.ChatResponse, error) {
+	return &llm.ChatResponse{Content: c.content, Usage: c.usage}, nil
+}
+
+func (c statelessClient) ChatCompletionStream(context.Context, llm.ChatRequest) (*llm.StreamReader, error) {
+	return nil, errors.New("streaming not supported")
+}
+
+func TestScorerRecordsUsageOfConcurrentScorings(t *testing.T) {
+	client := statelessClient{content: "CORRECT", usage: llm.Usage{PromptTokens: 10, CompletionTokens: 1}}
+	s := NewScorer(client, Config{Model: "judge", Repetitions: 2, Mode: ModePerQuestion})
+	content := "---\nNO. 1 - S\nQUESTION: Q1\nEXPECTED ANSWER: A1\nACTUAL ANSWER: A1\n" +
+		"---\nNO. 2 - S\nQUESTION: Q2\nEXPECTED ANSWER: A2\nACTUAL ANSWER: A2\n"
+
+	var wg sync.WaitGroup
+	outputs := make([]*ScoreOutput, 8)
+	for i := range outputs {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			outputs[i], _ = s.Score(context.Background(), content, "test.txt")
+		}()
+		go func() {
+			defer wg.Done()
+			_, _ = s.JudgeResult(context.Background(), &testsuite.Result{Answer: "A1"})
+		}()
+	}
+	wg.Wait()
+
+	for _, output := range outputs {
+		require.NotNil(t, output)
+		for _, run := range output.Runs {
+			require.NotNil(t, run.Usage)
+			assert.Equal(t, TokenUsage{Calls: 2, PromptTokens: 20, CompletionTokens: 2, TotalTokens: 22}, *run.Usage)
+		}
+	}
+}
+
+func TestScorerComputesCost(t *testing.T) {
+	client := &testutil.MockLLMClient{
+		DefaultResponse: "CORRECT",
+		Usage:           llm.Usage{PromptTokens: 1_000_000, CompletionTokens: 100_000},
+	}
+	s := NewScorer(client, Config{
+		Repetitions: 1,
+		Mode:        ModePerQuestion,
+		Judges:      []Judge{{Model: "priced"}, {Model: "free"}},
+		Prices:      PriceTable{"priced": {Prompt: 2, Completion: 10}},
+
+		HallucinationCheck: true,
+	})
+
+	output, err := s.Score(context.Background(), hallucinationContent, "test.txt")
+	require.NoError(t, err)
+
+	require.NotNil(t, output.Runs[0].Usage)
+	require.NotNil(t, output.Runs[0].Usage.Cost)
+	assert.InDelta(t, 6.0, *output.Runs[0].Usage.Cost, 1e-9) // 2 questions x $3
+	assert.Equal(t, []string{"free"}, output.Runs[0].Usage.UnpricedModels)
+
+	// The hallucination check uses the default scoring model, which has no price.
+	require.NotNil(t, output.Hallucinations.Usage)
+	assert.Equal(t, 2, output.Hallucinations.Usage.Calls)
+
+	total := output.Metadata.Usage
+	require.NotNil(t, total)
+	assert.Equal(t, 6, total.Calls)
+	assert.InDelta(t, 6.0, *total.Cost, 1e-9)
+	assert.ElementsMatch(t, []string{"free", DefaultScoringModel}, total.UnpricedModels)
+}
+
+func TestRescoreKeepsUsageOfSuccessfulRuns(t *testing.T) {
+	client := &testutil.MockLLMClient{
+		DefaultResponse: "50 out of 100",
+		Usage:           llm.Usage{PromptTokens: 10, CompletionTokens: 5},
+	}
+	s := NewScorer(client, Config{Repetitions: 2})
+
+	previous := &ScoreOutput{Runs: []RunScore{
+		{Usage: &TokenUsage{Calls: 1, PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}},
+		{ParseErr: "timeout"},
+	}}
+
+	output, err := s.Rescore(context.Background(), "content", "test.txt", previous)
+	require.NoError(t, err)
+	require.NotNil(t, output.Metadata.Usage)
+	assert.Equal(t, 2, output.Metadata.Usage.Calls)
+	assert.Equal(t, 30, output.Metadata.Usage.TotalTokens)
+}
+
+func TestLoadPriceTable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prices.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("prices:\n  gpt-5:\n    prompt: 1.25\n    completion: 10\n"), 0o644))
+
+	prices, err := LoadPriceTable(path)
+	require.NoError(t, err)
+	assert.Equal(t, PriceTable{"gpt-5": {Prompt: 1.25, Completion: 10}}, prices)
+
+	require.NoError(t, os.WriteFile(path, []byte("prices:\n  gpt-5:\n    prompt: -1\n"), 0o644))
+	_, err = LoadPriceTable(path)
+	assert.ErrorContains(t, err, "must not be negative")
+}
@@ -0,0 +1,38 @@
+package server
+
+import (
+	"context"
+	"slices"
+
+	oauth "github.com/giantswarm/mcp-oauth"
+)
+
+// AccessPolicy decides which clients may call the tools that change state,
+// such as deploying models, running tests, or deleting results.
+type AccessPolicy struct {
+	// ReadOnly denies the tools to all clients.
+	ReadOnly bool
+
+	// WriterGroups, if set, allows the tools only to OAuth users in one of
+	// these groups, as listed in the groups claim of their token
+	// (OAuthConfig.GroupsClaim).
+	WriterGroups []string
+}
+
+// CanWrite reports whether the client of a request with ctx may call tools
+// that change state.
+func (p AccessPolicy) CanWrite(ctx context.Context) bool {
+	if p.ReadOnly {
+		return false
+	}
+	if len(p.WriterGroups) == 0 {
+		return true
+	}
+	user, ok := oauth.UserInfoFromContext(ctx)
+	if !ok || user == nil {
+		return false
+	}
+	return slices.ContainsFunc(user.Groups, func(group string) bool {
+		return slices.Contains(p.WriterGroups, group)
+	})
+}
@@ -0,0 +1,28 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	oauth "github.com/giantswarm/mcp-oauth"
+	"github.com/giantswarm/mcp-oauth/providers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessPolicyCanWrite(t *testing.T) {
+	admin := oauth.ContextWithUserInfo(context.Background(), &providers.UserInfo{Groups: []string{"viewers", "llm-admins"}})
+	viewer := oauth.ContextWithUserInfo(context.Background(), &providers.UserInfo{Groups: []string{"viewers"}})
+	anonymous := context.Background()
+
+	open := AccessPolicy{}
+	assert.True(t, open.CanWrite(anonymous))
+	assert.True(t, open.CanWrite(viewer))
+
+	readOnly := AccessPolicy{ReadOnly: true, WriterGroups: []string{"llm-admins"}}
+	assert.False(t, readOnly.CanWrite(admin))
+
+	groups := AccessPolicy{WriterGroups: []string{"llm-admins"}}
+	assert.True(t, groups.CanWrite(admin))
+	assert.False(t, groups.CanWrite(viewer))
+	assert.False(t, groups.CanWrite(anonymous))
+}
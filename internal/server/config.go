@@ -0,0 +1,130 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigEnvPrefix prefixes the environment variables that set flags of the
+// serve command, e.g. LLM_TESTING_HTTP_ADDR for --http-addr.
+const ConfigEnvPrefix = "LLM_TESTING_"
+
+// fallbackEnv lists further environment variables that flags already fall
+// back to, so that they take precedence over the configuration file too.
+var fallbackEnv = map[string]string{
+	"hf-token":          "HF_TOKEN",
+	"webhook-secret":    "WEBHOOK_SECRET",
+	"dex-issuer-url":    "DEX_ISSUER_URL",
+	"dex-client-id":     "DEX_CLIENT_ID",
+	"dex-client-secret": "DEX_CLIENT_SECRET",
+
+	"oauth-issuer-url":    "DEX_ISSUER_URL",
+	"oauth-client-id":     "DEX_CLIENT_ID",
+	"oauth-client-secret": "DEX_CLIENT_SECRET",
+}
+
+// LoadConfigFile reads a YAML server configuration file and returns its
+// settings keyed by flag name. Keys are flag names without the leading
+// dashes; keys in a section are prefixed with its name, so that
+//
+//	oauth:
+//	  base-url: https://llm-testing.example.com
+//
+// sets --oauth-base-url. Lists set repeatable flags. Environment variables
+// in the file are expanded, e.g. ${DEX_CLIENT_SECRET}; "$$" is a literal "$".
+func LoadConfigFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	expanded := os.Expand(string(data), func(name string) string {
+		if name == "$" {
+			return "$"
+		}
+		return os.Getenv(name)
+	})
+
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal([]byte(expanded), &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	settings := make(map[string]string)
+	if err := flattenConfig("", raw, settings); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return settings, nil
+}
+
+// flattenConfig adds the settings of a section to settings.
+func flattenConfig(prefix string, section map[string]interface{}, settings map[string]string) error {
+	for key, value := range section {
+		name := prefix + key
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if err := flattenConfig(name+"-", v, settings); err != nil {
+				return err
+			}
+		case []interface{}:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				switch item.(type) {
+				case map[string]interface{}, []interface{}:
+					return fmt.Errorf("%s: list items must be plain values", name)
+				}
+				items = append(items, fmt.Sprint(item))
+			}
+			settings[name] = strings.Join(items, ",")
+		case nil:
+			// An empty key leaves the flag at its default.
+		default:
+			settings[name] = fmt.Sprint(v)
+		}
+	}
+	return nil
+}
+
+// ApplyConfig sets the flags that were not given on the command line from
+// their environment variable (ConfigEnvPrefix and the flag name in upper
+// case with underscores), or else from settings. Settings for unknown flags
+// are an error, to catch typos.
+func ApplyConfig(flags *pflag.FlagSet, settings map[string]string, lookupEnv func(string) (string, bool)) error {
+	for name := range settings {
+		if flags.Lookup(name) == nil {
+			return fmt.Errorf("unknown setting %q in config file", name)
+		}
+	}
+
+	var err error
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if err != nil || flag.Changed {
+			return
+		}
+		envName := EnvName(flag.Name)
+		if value, ok := lookupEnv(envName); ok {
+			if setErr := flags.Set(flag.Name, value); setErr != nil {
+				err = fmt.Errorf("invalid %s: %w", envName, setErr)
+			}
+			return
+		}
+		if fallback, ok := fallbackEnv[flag.Name]; ok {
+			if _, ok := lookupEnv(fallback); ok {
+				return
+			}
+		}
+		if value, ok := settings[flag.Name]; ok {
+			if setErr := flags.Set(flag.Name, value); setErr != nil {
+				err = fmt.Errorf("invalid %s %q in config file: %w", flag.Name, value, setErr)
+			}
+		}
+	})
+	return err
+}
+
+// EnvName returns the environment variable that sets a flag.
+func EnvName(flag string) string {
+	return ConfigEnvPrefix + strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfigFile(t *testing.T) {
+	t.Setenv("TEST_DEX_SECRET", "s3cret")
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+transport: streamable-http
+http:
+  addr: ":9090"
+enable-oauth: true
+oauth:
+  writer-groups: [llm-admins, ml-team]
+dex:
+  client-secret: ${TEST_DEX_SECRET}
+webhook-secret: pa$$word
+max-concurrent-runs: 2
+model-ttl:
+`), 0o644))
+
+	settings, err := LoadConfigFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"transport":           "streamable-http",
+		"http-addr":           ":9090",
+		"enable-oauth":        "true",
+		"oauth-writer-groups": "llm-admins,ml-team",
+		"dex-client-secret":   "s3cret",
+		"webhook-secret":      "pa$word",
+		"max-concurrent-runs": "2",
+	}, settings)
+
+	require.NoError(t, os.WriteFile(path, []byte("oauth:\n  writer-groups: [{name: a}]\n"), 0o644))
+	_, err = LoadConfigFile(path)
+	assert.ErrorContains(t, err, "oauth-writer-groups: list items must be plain values")
+}
+
+func TestApplyConfig(t *testing.T) {
+	newFlags := func() (*pflag.FlagSet, *string, *time.Duration, *[]string, *string) {
+		flags := pflag.NewFlagSet("serve", pflag.ContinueOnError)
+		addr := flags.String("http-addr", ":8080", "")
+		ttl := flags.Duration("model-ttl", 0, "")
+		groups := flags.StringSlice("oauth-writer-groups", nil, "")
+		secret := flags.String("webhook-secret", "", "")
+		return flags, addr, ttl, groups, secret
+	}
+	settings := map[string]string{
+		"http-addr":           ":9090",
+		"model-ttl":           "1h",
+		"oauth-writer-groups": "llm-admins,ml-team",
+		"webhook-secret":      "from-file",
+	}
+	env := map[string]string{}
+	lookupEnv := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	// The file sets flags left at their defaults.
+	flags, addr, ttl, groups, secret := newFlags()
+	require.NoError(t, ApplyConfig(flags, settings, lookupEnv))
+	assert.Equal(t, ":9090", *addr)
+	assert.Equal(t, time.Hour, *ttl)
+	assert.Equal(t, []string{"llm-admins", "ml-team"}, *groups)
+	assert.Equal(t, "from-file", *secret)
+
+	// Flags beat environment variables, which beat the file; so do the
+	// variables flags already fall back to.
+	env["LLM_TESTING_HTTP_ADDR"] = ":7070"
+	env["LLM_TESTING_MODEL_TTL"] = "2h"
+	env["WEBHOOK_SECRET"] = "from-env"
+	flags, addr, ttl, _, secret = newFlags()
+	require.NoError(t, flags.Parse([]string{"--http-addr", ":6060"}))
+	require.NoError(t, ApplyConfig(flags, settings, lookupEnv))
+	assert.Equal(t, ":6060", *addr)
+	assert.Equal(t, 2*time.Hour, *ttl)
+	assert.Empty(t, *secret)
+
+	flags, _, _, _, _ = newFlags()
+	err := ApplyConfig(flags, map[string]string{"http-adr": ":9090"}, lookupEnv)
+	assert.ErrorContains(t, err, `unknown setting "http-adr" in config file`)
+
+	delete(env, "LLM_TESTING_MODEL_TTL")
+	flags, _, _, _, _ = newFlags()
+	err = ApplyConfig(flags, map[string]string{"model-ttl": "soon"}, lookupEnv)
+	assert.ErrorContains(t, err, `invalid model-ttl "soon" in config file`)
+}
@@ -1,8 +1,14 @@
 package server
 
 import (
+	"time"
+
+	"github.com/giantswarm/llm-testing/internal/identity"
+	"github.com/giantswarm/llm-testing/internal/jobs"
 	"github.com/giantswarm/llm-testing/internal/kserve"
 	"github.com/giantswarm/llm-testing/internal/llm"
+	"github.com/giantswarm/llm-testing/internal/scorer"
+	"github.com/giantswarm/llm-testing/internal/testsuite"
 )
 
 // ServerContext holds shared dependencies for MCP tool handlers.
@@ -10,8 +16,75 @@ type ServerContext struct {
 	KServeManager *kserve.Manager
 	LLMClient     llm.Client
 	LLMAPIKey     string
+	LLMAPIKeyFile string // file holding the API key instead of LLMAPIKey
 	Namespace     string
 	OutputDir     string
 	SuitesDir     string // external test suites directory (optional)
 	ScoringModel  string // default model for LLM-as-judge scoring
+
+	// LLMOptions are applied to every LLM client created for a test run,
+	// e.g. a request logger.
+	LLMOptions []llm.Option
+
+	// DebugLLM records the LLM requests of test runs in their transcripts/
+	// directory; LLMOptions must then log with llm.TranscriptLogger.
+	DebugLLM bool
+
+	// LLMCache answers repeated deterministic requests of the models under
+	// test from disk (optional; nil disables caching).
+	LLMCache *llm.Cache
+
+	// CircuitBreakerFailures is the number of consecutive endpoint failures
+	// after which the requests of a tested model fail fast for
+	// CircuitBreakerCooldown; zero disables the circuit breaker.
+	CircuitBreakerFailures int
+	CircuitBreakerCooldown time.Duration
+
+	// Providers resolves the provider of a model to its endpoint and
+	// credentials (optional; nil knows only the provider types).
+	Providers *llm.Registry
+
+	// ModelRegistry resolves model aliases to canonical IDs (optional).
+	ModelRegistry *identity.Registry
+
+	// ModelAliases name models with their provider and deployment settings,
+	// accepted wherever tools take a model name (optional).
+	ModelAliases testsuite.ModelAliases
+
+	// ModelMetadata sizes hf:// models to recommend a GPU count, and picks the
+	// GGUF file of CPU backends, on deploy (optional).
+	ModelMetadata kserve.ModelMetadataSource
+	GPUMemoryGiB  float64 // memory per GPU used for sizing; zero disables recommendations
+
+	// GPUCapacityCheck is how deployments react to insufficient free GPUs.
+	GPUCapacityCheck kserve.CapacityCheck
+
+	// EndpointURLMode selects how deployed models are reached: cluster-local
+	// when running in-cluster, external otherwise, or port-forward.
+	EndpointURLMode kserve.URLMode
+
+	// HFTokenSecret is the default Secret holding a HuggingFace token for deployments (optional).
+	HFTokenSecret string
+
+	// AllowPrivateURLs lets generate_suite fetch documents from loopback,
+	// private, and link-local addresses.
+	AllowPrivateURLs bool
+
+	// Jobs runs the test runs started with async run_test_suite calls
+	// (optional; nil disables async runs).
+	Jobs *jobs.Manager
+
+	// JudgePrices prices judge tokens to report the cost of scoring (optional).
+	JudgePrices scorer.PriceTable
+
+	// Access restricts the tools that change state to some or no clients.
+	Access AccessPolicy
+
+	// Drain lets tool calls in flight finish on shutdown (optional; nil
+	// cancels them right away).
+	Drain *Drain
+
+	// Sessions keeps what each client session last worked on, which its
+	// tool calls refer to as "latest" (optional; nil disables "latest").
+	Sessions *Sessions
 }
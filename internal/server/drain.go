@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrDraining is returned for work started while the server drains.
+var ErrDraining = errors.New("the server is shutting down")
+
+// Drain lets the tool calls and runs in flight finish when the server shuts
+// down, while it refuses new ones, and cancels those left at the end of the
+// drain period.
+type Drain struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu       sync.Mutex
+	draining bool
+	active   int
+	idle     chan struct{}
+}
+
+// NewDrain returns a drain that admits work until Start is called.
+func NewDrain() *Drain {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Drain{ctx: ctx, cancel: cancel, idle: make(chan struct{})}
+}
+
+// Context returns a context that is cancelled at the end of the drain
+// period, for work not registered with Begin, e.g. background runs.
+func (d *Drain) Context() context.Context {
+	return d.ctx
+}
+
+// Begin registers work, such as a tool call, with ctx. The returned context
+// is also cancelled at the end of the drain period, and done must be called
+// once the work finished. Begin fails with ErrDraining once the drain
+// started. A nil Drain admits all work.
+func (d *Drain) Begin(ctx context.Context) (context.Context, func(), error) {
+	if d == nil {
+		return ctx, func() {}, nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.draining {
+		return nil, nil, ErrDraining
+	}
+	d.active++
+
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(d.ctx, cancel)
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() {
+			stop()
+			cancel()
+			d.mu.Lock()
+			defer d.mu.Unlock()
+			d.active--
+			if d.draining && d.active == 0 {
+				close(d.idle)
+			}
+		})
+	}, nil
+}
+
+// Draining reports whether the drain started.
+func (d *Drain) Draining() bool {
+	if d == nil {
+		return false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.draining
+}
+
+// Start starts the drain: new work is refused from now on.
+func (d *Drain) Start() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.draining {
+		return
+	}
+	d.draining = true
+	if d.active == 0 {
+		close(d.idle)
+	}
+}
+
+// Wait waits until the work in flight finished after Start, or ctx is done.
+func (d *Drain) Wait(ctx context.Context) error {
+	select {
+	case <-d.idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Cancel ends the drain period: the work left is cancelled.
+func (d *Drain) Cancel() {
+	d.cancel()
+}
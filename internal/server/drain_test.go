@@ -0,0 +1,46 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrain(t *testing.T) {
+	d := NewDrain()
+	_, doneFirst, err := d.Begin(context.Background())
+	require.NoError(t, err)
+	second, doneSecond, err := d.Begin(context.Background())
+	require.NoError(t, err)
+	assert.False(t, d.Draining())
+
+	d.Start()
+	assert.True(t, d.Draining())
+	_, _, err = d.Begin(context.Background())
+	assert.ErrorIs(t, err, ErrDraining)
+
+	// Wait returns once the work in flight finished, or gives up.
+	doneFirst()
+	doneFirst()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, d.Wait(ctx), context.DeadlineExceeded)
+	assert.NoError(t, second.Err())
+
+	// The work left is cancelled at the end of the drain period.
+	d.Cancel()
+	<-second.Done()
+	<-d.Context().Done()
+	doneSecond()
+	assert.NoError(t, d.Wait(context.Background()))
+
+	var none *Drain
+	ctx, done, err := none.Begin(context.Background())
+	require.NoError(t, err)
+	done()
+	assert.NoError(t, ctx.Err())
+	assert.False(t, none.Draining())
+}
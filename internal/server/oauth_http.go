@@ -9,10 +9,12 @@ import (
 	"time"
 
 	oauth "github.com/giantswarm/mcp-oauth"
-	"github.com/giantswarm/mcp-oauth/providers/dex"
 	oauthserver "github.com/giantswarm/mcp-oauth/server"
+	"github.com/giantswarm/mcp-oauth/storage"
 	"github.com/giantswarm/mcp-oauth/storage/memory"
+	valkeystore "github.com/giantswarm/mcp-oauth/storage/valkey"
 	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/valkey-io/valkey-go"
 )
 
 const (
@@ -30,20 +32,48 @@ type OAuthConfig struct {
 	// BaseURL is the server's public base URL (e.g. https://llm-testing.example.com).
 	BaseURL string
 
-	// Provider is the OAuth provider name.
-	// Currently only "dex" is supported. This field is reserved for future
-	// provider implementations (e.g. "okta", "auth0") and is recorded in
-	// server metadata for operational visibility.
+	// Provider is the OAuth provider name: "dex" (default), "okta", "auth0",
+	// "entra", or "oidc" for any other OIDC issuer.
 	Provider string
 
-	// DexIssuerURL is the Dex OIDC issuer URL.
-	DexIssuerURL string
+	// IssuerURL is the OIDC issuer URL.
+	IssuerURL string
 
-	// DexClientID is the Dex OAuth client ID.
-	DexClientID string
+	// ClientID is the OAuth client ID.
+	ClientID string
 
-	// DexClientSecret is the Dex OAuth client secret.
-	DexClientSecret string
+	// ClientSecret is the OAuth client secret.
+	ClientSecret string
+
+	// Scopes are requested when users log in (default: the provider's
+	// scopes, e.g. openid, profile, email, and offline_access).
+	Scopes []string
+
+	// GroupsClaim is the token or userinfo claim listing the groups of a
+	// user, which WriterGroups of the AccessPolicy refer to (default:
+	// "groups").
+	GroupsClaim string
+
+	// Audiences are accepted in JWT access tokens (default: the client ID,
+	// and api://default for Okta). For Auth0, the first audience is also
+	// requested when users log in.
+	Audiences []string
+
+	// StateStore is the Redis or Valkey server that keeps the OAuth clients,
+	// flows, and tokens, so that they survive restarts and are shared by
+	// the replicas of the server (default: in memory).
+	StateStore *valkey.ClientOption
+
+	// StateKeyPrefix prefixes the keys in StateStore (default:
+	// DefaultStateKeyPrefix).
+	StateKeyPrefix string
+}
+
+// oauthStore keeps the OAuth clients, flows, and tokens.
+type oauthStore interface {
+	storage.TokenStore
+	storage.ClientStore
+	storage.FlowStore
 }
 
 // OAuthHTTPServer wraps an MCP server with OAuth 2.1 authentication.
@@ -53,38 +83,33 @@ type OAuthHTTPServer struct {
 	oauthHandler *oauth.Handler
 	httpServer   *http.Server
 	mcpEndpoint  string
+	routes       map[string]http.Handler
+	closeStore   func()
 }
 
 // NewOAuthHTTPServer creates a new OAuth-enabled HTTP server for MCP.
 func NewOAuthHTTPServer(mcpSrv *mcpserver.MCPServer, mcpEndpoint string, cfg OAuthConfig) (*OAuthHTTPServer, error) {
-	if cfg.Provider != "" && cfg.Provider != OAuthProviderDex {
-		return nil, fmt.Errorf("unsupported OAuth provider %q (supported: %s)", cfg.Provider, OAuthProviderDex)
-	}
-
 	if err := validateHTTPSRequirement(cfg.BaseURL); err != nil {
 		return nil, fmt.Errorf("OAuth base URL validation failed: %w", err)
 	}
 
-	// Create Dex provider.
+	// Create OIDC provider.
 	callbackURL := cfg.BaseURL + "/oauth/callback"
-	dexProvider, err := dex.NewProvider(&dex.Config{
-		IssuerURL:    cfg.DexIssuerURL,
-		ClientID:     cfg.DexClientID,
-		ClientSecret: cfg.DexClientSecret,
-		RedirectURL:  callbackURL,
-	})
+	provider, err := newOIDCProvider(cfg, callbackURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Dex provider: %w", err)
+		return nil, err
 	}
 
-	// Create in-memory storage (sufficient for single-instance deployment).
-	store := memory.New()
+	store, closeStore, err := newOAuthStore(cfg)
+	if err != nil {
+		return nil, err
+	}
 
 	logger := slog.Default()
 
 	// Create OAuth server.
 	oauthSrv, err := oauth.NewServer(
-		dexProvider,
+		provider,
 		store,
 		store,
 		store,
@@ -96,6 +121,7 @@ func NewOAuthHTTPServer(mcpSrv *mcpserver.MCPServer, mcpEndpoint string, cfg OAu
 		logger,
 	)
 	if err != nil {
+		closeStore()
 		return nil, fmt.Errorf("failed to create OAuth server: %w", err)
 	}
 
@@ -106,9 +132,46 @@ func NewOAuthHTTPServer(mcpSrv *mcpserver.MCPServer, mcpEndpoint string, cfg OAu
 		oauthServer:  oauthSrv,
 		oauthHandler: oauthHandler,
 		mcpEndpoint:  mcpEndpoint,
+		closeStore:   closeStore,
 	}, nil
 }
 
+// newOAuthStore returns the OAuth store of cfg and a function that closes it.
+func newOAuthStore(cfg OAuthConfig) (oauthStore, func(), error) {
+	if cfg.StateStore == nil {
+		// In-memory storage is sufficient for a single replica.
+		return memory.New(), func() {}, nil
+	}
+	opt := cfg.StateStore
+	if len(opt.InitAddress) == 0 {
+		return nil, nil, fmt.Errorf("state store address is required")
+	}
+	prefix := cfg.StateKeyPrefix
+	if prefix == "" {
+		prefix = DefaultStateKeyPrefix
+	}
+	store, err := valkeystore.New(valkeystore.Config{
+		Address:   opt.InitAddress[0],
+		Password:  opt.Password,
+		DB:        opt.SelectDB,
+		TLS:       opt.TLSConfig,
+		KeyPrefix: prefix + "oauth:",
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open OAuth state store: %w", err)
+	}
+	return store, store.Close, nil
+}
+
+// Handle registers an additional route that is not protected by OAuth, such
+// as an endpoint with its own authentication. It must be called before Start.
+func (s *OAuthHTTPServer) Handle(pattern string, handler http.Handler) {
+	if s.routes == nil {
+		s.routes = make(map[string]http.Handler)
+	}
+	s.routes[pattern] = handler
+}
+
 // Start starts the OAuth-enabled HTTP server.
 func (s *OAuthHTTPServer) Start(addr string) error {
 	mux := http.NewServeMux()
@@ -129,6 +192,10 @@ func (s *OAuthHTTPServer) Start(addr string) error {
 	)
 	mux.Handle(s.mcpEndpoint, s.oauthHandler.ValidateToken(mcpHandler))
 
+	for pattern, handler := range s.routes {
+		mux.Handle(pattern, handler)
+	}
+
 	// Health check (unauthenticated).
 	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -153,6 +220,9 @@ func (s *OAuthHTTPServer) Shutdown(ctx context.Context) error {
 			slog.Error("failed to shutdown OAuth server", "error", err)
 		}
 	}
+	if s.closeStore != nil {
+		s.closeStore()
+	}
 	if s.httpServer != nil {
 		return s.httpServer.Shutdown(ctx)
 	}
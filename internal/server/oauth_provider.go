@@ -0,0 +1,316 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/mcp-oauth/providers"
+	"github.com/giantswarm/mcp-oauth/providers/dex"
+	"github.com/giantswarm/mcp-oauth/providers/oidc"
+)
+
+// OAuth providers. Okta, Auth0, and Entra ID are OIDC providers with
+// presets for their scopes and tokens; "oidc" is any other OIDC issuer.
+const (
+	OAuthProviderOIDC  = "oidc"
+	OAuthProviderOkta  = "okta"
+	OAuthProviderAuth0 = "auth0"
+	OAuthProviderEntra = "entra"
+
+	// DefaultGroupsClaim is the token claim listing a user's groups.
+	DefaultGroupsClaim = "groups"
+
+	oauthRequestTimeout = 30 * time.Second
+
+	// maxUserInfoSize limits the userinfo responses read from a provider.
+	maxUserInfoSize = 1 << 20
+
+	// clientIDPlaceholder in the scopes and audiences of a preset is
+	// replaced by the client ID.
+	clientIDPlaceholder = "{client_id}"
+)
+
+// oauthPreset holds the defaults of an OAuth provider. Its scopes and
+// audiences may refer to the client ID as clientIDPlaceholder.
+type oauthPreset struct {
+	// scopes are requested unless OAuthConfig.Scopes is set.
+	scopes []string
+
+	// audiences are accepted in access tokens besides the client ID unless
+	// OAuthConfig.Audiences is set.
+	audiences []string
+
+	// audienceParam sends the first audience as the audience parameter of
+	// authorization requests, which makes Auth0 issue JWT access tokens.
+	audienceParam bool
+}
+
+// oauthPresets maps the supported OAuth providers to their defaults.
+var oauthPresets = map[string]oauthPreset{
+	OAuthProviderDex: {
+		scopes: []string{"openid", "profile", "email", "groups", "offline_access"},
+	},
+	OAuthProviderOIDC: {
+		scopes: []string{"openid", "profile", "email", "offline_access"},
+	},
+	OAuthProviderOkta: {
+		// Okta's default authorization server issues access tokens for
+		// api://default.
+		scopes:    []string{"openid", "profile", "email", "groups", "offline_access"},
+		audiences: []string{"api://default"},
+	},
+	OAuthProviderAuth0: {
+		scopes:        []string{"openid", "profile", "email", "offline_access"},
+		audienceParam: true,
+	},
+	OAuthProviderEntra: {
+		// Entra ID issues JWT access tokens only for an API of the
+		// application; v2.0 tokens have the client ID as their audience,
+		// v1.0 tokens the application ID URI.
+		scopes:    []string{"openid", "profile", "email", "offline_access", "api://" + clientIDPlaceholder + "/.default"},
+		audiences: []string{"api://" + clientIDPlaceholder},
+	},
+}
+
+// defaults returns the scopes and token audiences of cfg, with those of the
+// preset for the ones cfg does not set.
+func (p oauthPreset) defaults(cfg OAuthConfig) (scopes, audiences []string) {
+	withClientID := func(values []string) []string {
+		out := make([]string, len(values))
+		for i, v := range values {
+			out[i] = strings.ReplaceAll(v, clientIDPlaceholder, cfg.ClientID)
+		}
+		return out
+	}
+	scopes = cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = withClientID(p.scopes)
+	}
+	audiences = cfg.Audiences
+	if len(audiences) == 0 {
+		audiences = append([]string{cfg.ClientID}, withClientID(p.audiences)...)
+	}
+	return scopes, audiences
+}
+
+// OAuthProviders returns the names of the supported OAuth providers.
+func OAuthProviders() []string {
+	names := make([]string, 0, len(oauthPresets))
+	for name := range oauthPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// oidcProvider authenticates users with an OIDC issuer. It uses the Dex
+// provider, which works with any issuer that supports OIDC discovery, for
+// the authorization code flow, and validates access tokens itself: JWT
+// access tokens of the issuer by their signature against its JWKS, other
+// tokens at its userinfo endpoint. Either way, the user's groups are read
+// from the configured claim.
+type oidcProvider struct {
+	*dex.Provider
+
+	name          string
+	issuerURL     string
+	groupsClaim   string
+	audiences     []string
+	audienceParam string
+
+	discovery  *oidc.DiscoveryClient
+	jwks       *oidc.JWKSClient
+	httpClient *http.Client
+}
+
+// newOIDCProvider creates the provider of cfg, which redirects users back to
+// redirectURL after they logged in.
+func newOIDCProvider(cfg OAuthConfig, redirectURL string) (*oidcProvider, error) {
+	name := cfg.Provider
+	if name == "" {
+		name = OAuthProviderDex
+	}
+	preset, ok := oauthPresets[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported OAuth provider %q (supported: %s)", cfg.Provider, strings.Join(OAuthProviders(), ", "))
+	}
+
+	scopes, audiences := preset.defaults(cfg)
+	groupsClaim := cfg.GroupsClaim
+	if groupsClaim == "" {
+		groupsClaim = DefaultGroupsClaim
+	}
+
+	httpClient := &http.Client{Timeout: oauthRequestTimeout}
+	base, err := dex.NewProvider(&dex.Config{
+		IssuerURL:      cfg.IssuerURL,
+		ClientID:       cfg.ClientID,
+		ClientSecret:   cfg.ClientSecret,
+		RedirectURL:    redirectURL,
+		Scopes:         scopes,
+		HTTPClient:     httpClient,
+		RequestTimeout: oauthRequestTimeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s provider: %w", name, err)
+	}
+
+	p := &oidcProvider{
+		Provider:    base,
+		name:        name,
+		issuerURL:   cfg.IssuerURL,
+		groupsClaim: groupsClaim,
+		audiences:   audiences,
+		discovery:   oidc.NewDiscoveryClient(httpClient, 0, nil),
+		jwks:        oidc.NewJWKSClientWithOptions(oidc.JWKSClientOptions{HTTPClient: httpClient}),
+		httpClient:  httpClient,
+	}
+	if preset.audienceParam && len(cfg.Audiences) > 0 {
+		p.audienceParam = cfg.Audiences[0]
+	}
+	return p, nil
+}
+
+// Name returns the name of the provider.
+func (p *oidcProvider) Name() string {
+	return p.name
+}
+
+// AuthorizationURL returns the URL that logs a user in at the issuer.
+func (p *oidcProvider) AuthorizationURL(state, codeChallenge, codeChallengeMethod string, scopes []string, opts *providers.AuthorizationURLOptions) string {
+	if p.audienceParam != "" {
+		withAudience := providers.AuthorizationURLOptions{Extra: map[string]string{}}
+		if opts != nil {
+			withAudience = *opts
+			withAudience.Extra = maps.Clone(opts.Extra)
+			if withAudience.Extra == nil {
+				withAudience.Extra = map[string]string{}
+			}
+		}
+		withAudience.Extra["audience"] = p.audienceParam
+		opts = &withAudience
+	}
+	return p.Provider.AuthorizationURL(state, codeChallenge, codeChallengeMethod, scopes, opts)
+}
+
+// ValidateToken validates an access token of the issuer and returns its user.
+func (p *oidcProvider) ValidateToken(ctx context.Context, accessToken string) (*providers.UserInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, oauthRequestTimeout)
+	defer cancel()
+
+	doc, err := p.discovery.Discover(ctx, p.issuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("OIDC discovery failed: %w", err)
+	}
+	if issuer, ok := p.jwtIssuer(accessToken); ok && doc.JWKSUri != "" {
+		if _, err := oidc.ValidateIDToken(ctx, accessToken, p.jwks, doc.JWKSUri, issuer, p.audiences); err != nil {
+			return nil, fmt.Errorf("invalid access token: %w", err)
+		}
+		// The signature of the claims was verified above.
+		claims, err := oidc.ParseUnverifiedClaims(accessToken)
+		if err != nil {
+			return nil, fmt.Errorf("invalid access token: %w", err)
+		}
+		return userInfoFromClaims(claims, p.groupsClaim)
+	}
+
+	if doc.UserInfoEndpoint == "" {
+		return nil, fmt.Errorf("userinfo endpoint not available in discovery document")
+	}
+	claims, err := p.fetchUserInfo(ctx, doc.UserInfoEndpoint, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	return userInfoFromClaims(claims, p.groupsClaim)
+}
+
+// jwtIssuer returns the issuer of token, if it is a JWT issued by the
+// provider's issuer. Issuers differing only in a trailing slash match.
+func (p *oidcProvider) jwtIssuer(token string) (string, bool) {
+	if !oidc.IsJWT(token) {
+		return "", false
+	}
+	claims, err := oidc.ParseUnverifiedClaims(token)
+	if err != nil {
+		return "", false
+	}
+	issuer, _ := claims["iss"].(string)
+	if issuer == "" || strings.TrimSuffix(issuer, "/") != strings.TrimSuffix(p.issuerURL, "/") {
+		return "", false
+	}
+	return issuer, true
+}
+
+// fetchUserInfo returns the claims of the user of accessToken from the
+// issuer's userinfo endpoint.
+func (p *oidcProvider) fetchUserInfo(ctx context.Context, endpoint, accessToken string) (map[string]interface{}, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create userinfo request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user info: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("userinfo request failed with status %d", resp.StatusCode)
+	}
+	var claims map[string]interface{}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxUserInfoSize)).Decode(&claims); err != nil {
+		return nil, fmt.Errorf("failed to decode user info: %w", err)
+	}
+	return claims, nil
+}
+
+// userInfoFromClaims returns the user described by the claims of a token or
+// userinfo response, with the groups listed in groupsClaim. The claim may
+// hold a list of groups or a single one.
+func userInfoFromClaims(claims map[string]interface{}, groupsClaim string) (*providers.UserInfo, error) {
+	str := func(name string) string {
+		value, _ := claims[name].(string)
+		return value
+	}
+	user := &providers.UserInfo{
+		ID:         str("sub"),
+		Email:      str("email"),
+		Name:       str("name"),
+		GivenName:  str("given_name"),
+		FamilyName: str("family_name"),
+		Picture:    str("picture"),
+		Locale:     str("locale"),
+	}
+	if user.ID == "" {
+		return nil, fmt.Errorf("user info has no subject")
+	}
+	user.EmailVerified, _ = claims["email_verified"].(bool)
+
+	switch groups := claims[groupsClaim].(type) {
+	case nil:
+	case string:
+		user.Groups = []string{groups}
+	case []interface{}:
+		for _, group := range groups {
+			name, ok := group.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid %s claim: groups must be strings", groupsClaim)
+			}
+			user.Groups = append(user.Groups, name)
+		}
+	default:
+		return nil, fmt.Errorf("invalid %s claim: must be a list of groups", groupsClaim)
+	}
+	if err := oidc.ValidateGroups(user.Groups); err != nil {
+		return nil, fmt.Errorf("invalid %s claim: %w", groupsClaim, err)
+	}
+	return user, nil
+}
@@ -0,0 +1,209 @@
+package server
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/mcp-oauth/providers"
+	"github.com/giantswarm/mcp-oauth/providers/oidc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserInfoFromClaims(t *testing.T) {
+	user, err := userInfoFromClaims(map[string]interface{}{
+		"sub":            "u1",
+		"email":          "ada@example.com",
+		"email_verified": true,
+		"groups":         []interface{}{"llm-admins", "viewers"},
+	}, "groups")
+	require.NoError(t, err)
+	assert.Equal(t, &providers.UserInfo{ID: "u1", Email: "ada@example.com", EmailVerified: true, Groups: []string{"llm-admins", "viewers"}}, user)
+
+	user, err = userInfoFromClaims(map[string]interface{}{"sub": "u1", "https://example.com/roles": "llm-admins"}, "https://example.com/roles")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"llm-admins"}, user.Groups)
+
+	user, err = userInfoFromClaims(map[string]interface{}{"sub": "u1"}, "groups")
+	require.NoError(t, err)
+	assert.Empty(t, user.Groups)
+
+	_, err = userInfoFromClaims(map[string]interface{}{"email": "ada@example.com"}, "groups")
+	assert.ErrorContains(t, err, "no subject")
+	_, err = userInfoFromClaims(map[string]interface{}{"sub": "u1", "groups": []interface{}{1}}, "groups")
+	assert.ErrorContains(t, err, "groups must be strings")
+	_, err = userInfoFromClaims(map[string]interface{}{"sub": "u1", "groups": map[string]interface{}{}}, "groups")
+	assert.ErrorContains(t, err, "must be a list of groups")
+}
+
+func TestNewOIDCProviderErrors(t *testing.T) {
+	_, err := newOIDCProvider(OAuthConfig{Provider: "keycloak"}, "https://llm-testing.example.com/oauth/callback")
+	assert.ErrorContains(t, err, `unsupported OAuth provider "keycloak" (supported: auth0, dex, entra, oidc, okta)`)
+
+	_, err = newOIDCProvider(OAuthConfig{Provider: OAuthProviderOkta, ClientID: "llm-testing", ClientSecret: "s3cret"}, "https://llm-testing.example.com/oauth/callback")
+	assert.ErrorContains(t, err, "failed to create okta provider: issuer URL is required")
+}
+
+// newTestIssuer starts an OIDC issuer at path of a test server and returns
+// a provider for it, which reads groups from the roles claim, with a
+// function signing the claims of its JWTs.
+func newTestIssuer(t *testing.T, path string, audiences ...string) (*oidcProvider, func(map[string]interface{}) string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	srv := httptest.NewTLSServer(mux)
+	t.Cleanup(srv.Close)
+	mux.HandleFunc(path+"/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 srv.URL + path,
+			"authorization_endpoint": srv.URL + "/auth",
+			"token_endpoint":         srv.URL + "/token",
+			"userinfo_endpoint":      srv.URL + "/userinfo",
+			"jwks_uri":               srv.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "k1",
+			"alg": "RS256",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer opaque-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"sub": "u2", "roles": []string{"viewers"}})
+	})
+
+	p := &oidcProvider{
+		name:        OAuthProviderEntra,
+		issuerURL:   srv.URL + path,
+		groupsClaim: "roles",
+		audiences:   audiences,
+		discovery:   oidc.NewTestDiscoveryClient(srv.Client(), 0, nil),
+		jwks:        oidc.NewJWKSClientWithOptions(oidc.JWKSClientOptions{HTTPClient: srv.Client(), AllowPrivateIP: true}),
+		httpClient:  srv.Client(),
+	}
+	sign := func(claims map[string]interface{}) string {
+		header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": "k1"})
+		payload, _ := json.Marshal(claims)
+		signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+		digest := sha256.Sum256([]byte(signed))
+		signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+		return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+	}
+	return p, sign
+}
+
+func TestOIDCProviderValidateToken(t *testing.T) {
+	p, sign := newTestIssuer(t, "", "llm-testing")
+	claims := func(audience string) map[string]interface{} {
+		return map[string]interface{}{
+			"iss":   p.issuerURL + "/",
+			"sub":   "u1",
+			"aud":   audience,
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"iat":   time.Now().Unix(),
+			"roles": []string{"llm-admins"},
+		}
+	}
+	ctx := context.Background()
+
+	// JWT access tokens of the issuer are verified against its JWKS.
+	user, err := p.ValidateToken(ctx, sign(claims("llm-testing")))
+	require.NoError(t, err)
+	assert.Equal(t, "u1", user.ID)
+	assert.Equal(t, []string{"llm-admins"}, user.Groups)
+
+	_, err = p.ValidateToken(ctx, sign(claims("other-app")))
+	assert.ErrorContains(t, err, "audience mismatch")
+
+	forged := sign(claims("llm-testing"))
+	forged = forged[:len(forged)-4] + "AAAA"
+	_, err = p.ValidateToken(ctx, forged)
+	assert.ErrorContains(t, err, "invalid access token")
+
+	// Other tokens are validated at the userinfo endpoint.
+	user, err = p.ValidateToken(ctx, "opaque-token")
+	require.NoError(t, err)
+	assert.Equal(t, "u2", user.ID)
+	assert.Equal(t, []string{"viewers"}, user.Groups)
+
+	_, err = p.ValidateToken(ctx, "revoked-token")
+	assert.ErrorContains(t, err, "userinfo request failed with status 401")
+}
+
+func TestEntraPresetDefaults(t *testing.T) {
+	const clientID = "0b6f5c2e-1a2b-4c3d-9e8f-123456789abc"
+	scopes, audiences := oauthPresets[OAuthProviderEntra].defaults(OAuthConfig{ClientID: clientID})
+	assert.Equal(t, []string{"openid", "profile", "email", "offline_access", "api://" + clientID + "/.default"}, scopes)
+	assert.Equal(t, []string{clientID, "api://" + clientID}, audiences)
+
+	// Explicit scopes and audiences replace the preset's.
+	scopes, audiences = oauthPresets[OAuthProviderEntra].defaults(OAuthConfig{ClientID: clientID, Scopes: []string{"openid"}, Audiences: []string{"api://llm-testing"}})
+	assert.Equal(t, []string{"openid"}, scopes)
+	assert.Equal(t, []string{"api://llm-testing"}, audiences)
+}
+
+func TestOIDCProviderValidatesEntraTokens(t *testing.T) {
+	const clientID = "0b6f5c2e-1a2b-4c3d-9e8f-123456789abc"
+	_, audiences := oauthPresets[OAuthProviderEntra].defaults(OAuthConfig{ClientID: clientID})
+	p, sign := newTestIssuer(t, "/72f988bf-86f1-41af-91ab-2d7cd011db47/v2.0", audiences...)
+
+	// A v2.0 access token for the application's API, with its app roles.
+	user, err := p.ValidateToken(context.Background(), sign(map[string]interface{}{
+		"iss":   p.issuerURL,
+		"aud":   clientID,
+		"ver":   "2.0",
+		"tid":   "72f988bf-86f1-41af-91ab-2d7cd011db47",
+		"oid":   "5c3d8a8e-9f6b-4c0e-8b1a-2f3e4d5c6b7a",
+		"sub":   "AAAAAAAAAAAAAAAAAAAAAIkzqFVrSaSaFHy782bbtaQ",
+		"scp":   "access_as_user",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"iat":   time.Now().Unix(),
+		"name":  "Ada Lovelace",
+		"roles": []string{"llm-admins"},
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "AAAAAAAAAAAAAAAAAAAAAIkzqFVrSaSaFHy782bbtaQ", user.ID)
+	assert.Equal(t, "Ada Lovelace", user.Name)
+	assert.Equal(t, []string{"llm-admins"}, user.Groups)
+
+	// v1.0 tokens name the API by its application ID URI.
+	_, err = p.ValidateToken(context.Background(), sign(map[string]interface{}{
+		"iss": p.issuerURL,
+		"aud": "api://" + clientID,
+		"sub": "u1",
+		"exp": time.Now().Add(time.Hour).Unix(),
+		"iat": time.Now().Unix(),
+	}))
+	require.NoError(t, err)
+
+	// Tokens for Microsoft Graph are not for this server.
+	_, err = p.ValidateToken(context.Background(), sign(map[string]interface{}{
+		"iss": p.issuerURL,
+		"aud": "00000003-0000-0000-c000-000000000000",
+		"sub": "u1",
+		"exp": time.Now().Add(time.Hour).Unix(),
+		"iat": time.Now().Unix(),
+	}))
+	assert.ErrorContains(t, err, "audience mismatch")
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/llm-testing/internal/llm"
+)
+
+// ReadyPath is the path of the readiness endpoint.
+const ReadyPath = "/readyz"
+
+// readyCheckTimeout bounds each readiness check, below the timeout of the
+// Helm chart's readiness probe.
+const readyCheckTimeout = 4 * time.Second
+
+// Readiness check states.
+const (
+	CheckOK      = "ok"
+	CheckFailed  = "failed"
+	CheckSkipped = "skipped"
+)
+
+// ReadyCheck is the outcome of a readiness check.
+type ReadyCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Readiness is the outcome of all readiness checks.
+type Readiness struct {
+	Ready  bool         `json:"ready"`
+	Checks []ReadyCheck `json:"checks"`
+}
+
+// CheckReady checks the dependencies of the server: that the KServe
+// InferenceService CRD is available, if model management is enabled; that
+// the default LLM endpoint answers, if its client can list models; and that
+// the output directory is writable. The checks run concurrently. A server
+// that drains on shutdown is not ready.
+func (sc *ServerContext) CheckReady(ctx context.Context) Readiness {
+	checks := []struct {
+		name string
+		fn   func(context.Context) (bool, error)
+	}{
+		{"kserve", sc.checkKServe},
+		{"llm_endpoint", sc.checkLLMEndpoint},
+		{"output_dir", sc.checkOutputDir},
+	}
+
+	readiness := Readiness{Ready: true, Checks: make([]ReadyCheck, len(checks))}
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, readyCheckTimeout)
+			defer cancel()
+			result := ReadyCheck{Name: check.name, Status: CheckOK}
+			checked, err := check.fn(ctx)
+			switch {
+			case err != nil:
+				result.Status, result.Error = CheckFailed, err.Error()
+			case !checked:
+				result.Status = CheckSkipped
+			}
+			readiness.Checks[i] = result
+		}()
+	}
+	wg.Wait()
+	if sc.Drain.Draining() {
+		readiness.Checks = append(readiness.Checks, ReadyCheck{Name: "shutdown", Status: CheckFailed, Error: ErrDraining.Error()})
+	}
+
+	for _, check := range readiness.Checks {
+		if check.Status == CheckFailed {
+			readiness.Ready = false
+		}
+	}
+	return readiness
+}
+
+func (sc *ServerContext) checkKServe(ctx context.Context) (bool, error) {
+	if sc.KServeManager == nil {
+		return false, nil
+	}
+	return true, sc.KServeManager.CheckCRDAvailable(ctx)
+}
+
+func (sc *ServerContext) checkLLMEndpoint(ctx context.Context) (bool, error) {
+	if _, ok := sc.LLMClient.(llm.ModelLister); !ok {
+		return false, nil
+	}
+	if _, err := llm.ListModels(ctx, sc.LLMClient); err != nil {
+		return true, fmt.Errorf("LLM endpoint is not reachable: %w", err)
+	}
+	return true, nil
+}
+
+func (sc *ServerContext) checkOutputDir(context.Context) (bool, error) {
+	if err := os.MkdirAll(sc.OutputDir, 0o755); err != nil {
+		return true, fmt.Errorf("output directory is not writable: %w", err)
+	}
+	f, err := os.CreateTemp(sc.OutputDir, ".readyz-*")
+	if err != nil {
+		return true, fmt.Errorf("output directory is not writable: %w", err)
+	}
+	_ = f.Close()
+	return true, os.Remove(f.Name())
+}
+
+// ReadyHandler serves the readiness checks of sc as JSON, with status 200
+// when all passed and 503 otherwise.
+func ReadyHandler(sc *ServerContext) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		readiness := sc.CheckReady(r.Context())
+		w.Header().Set("Content-Type", "application/json")
+		if !readiness.Ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(readiness)
+	})
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/giantswarm/llm-testing/internal/llm"
+	"github.com/giantswarm/llm-testing/internal/testutil"
+)
+
+func TestReadyHandler(t *testing.T) {
+	var down atomic.Bool
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`{"object":"list","data":[{"id":"judge","object":"model"}]}`))
+	}))
+	defer endpoint.Close()
+
+	sc := &ServerContext{
+		LLMClient: llm.NewOpenAIClient(llm.WithBaseURL(endpoint.URL), llm.WithAPIKey("test")),
+		OutputDir: filepath.Join(t.TempDir(), "results"),
+	}
+	ready := func() (int, Readiness) {
+		rec := httptest.NewRecorder()
+		ReadyHandler(sc).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, ReadyPath, nil))
+		var readiness Readiness
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &readiness))
+		return rec.Code, readiness
+	}
+
+	code, readiness := ready()
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, Readiness{Ready: true, Checks: []ReadyCheck{
+		{Name: "kserve", Status: CheckSkipped},
+		{Name: "llm_endpoint", Status: CheckOK},
+		{Name: "output_dir", Status: CheckOK},
+	}}, readiness)
+	entries, err := os.ReadDir(sc.OutputDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	down.Store(true)
+	code, readiness = ready()
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.False(t, readiness.Ready)
+	assert.Equal(t, CheckFailed, readiness.Checks[1].Status)
+	assert.Contains(t, readiness.Checks[1].Error, "LLM endpoint is not reachable")
+
+	// Clients that cannot list models are not checked.
+	sc.LLMClient = &testutil.MockLLMClient{}
+	sc.OutputDir = filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(sc.OutputDir, nil, 0o644))
+	code, readiness = ready()
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, CheckSkipped, readiness.Checks[1].Status)
+	assert.Equal(t, CheckFailed, readiness.Checks[2].Status)
+	assert.Contains(t, readiness.Checks[2].Error, "output directory is not writable")
+}
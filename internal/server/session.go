@@ -0,0 +1,105 @@
+package server
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// sessionIdleTTL is how long the working context of a client session is
+// kept after its last change, for sessions that end without unregistering.
+const sessionIdleTTL = 24 * time.Hour
+
+// WorkingContext is what a client session last worked on, so that its
+// tool calls can refer to it as "latest" instead of repeating identifiers.
+type WorkingContext struct {
+	RunID     string `json:"run_id,omitempty"`
+	Model     string `json:"model,omitempty"`      // last deployed model
+	TestSuite string `json:"test_suite,omitempty"` // last chosen suite
+}
+
+// Sessions keeps the working contexts of the client sessions, and which
+// sessions are connected.
+type Sessions struct {
+	mu        sync.Mutex
+	contexts  map[string]*sessionEntry
+	connected map[string]struct{}
+}
+
+type sessionEntry struct {
+	wc      WorkingContext
+	updated time.Time
+}
+
+// NewSessions returns an empty store of working contexts.
+func NewSessions() *Sessions {
+	return &Sessions{contexts: map[string]*sessionEntry{}, connected: map[string]struct{}{}}
+}
+
+// Connect records that a session connected.
+func (s *Sessions) Connect(id string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.connected[id] = struct{}{}
+}
+
+// Connected returns the IDs of the connected sessions, sorted.
+func (s *Sessions) Connected() []string {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Sorted(maps.Keys(s.connected))
+}
+
+// Get returns the working context of a session. A nil Sessions keeps none.
+func (s *Sessions) Get(id string) WorkingContext {
+	if s == nil {
+		return WorkingContext{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry, ok := s.contexts[id]; ok {
+		return entry.wc
+	}
+	return WorkingContext{}
+}
+
+// Update changes the working context of a session with fn. Sessions idle
+// for longer than a day are forgotten on the way.
+func (s *Sessions) Update(id string, fn func(*WorkingContext)) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for other, entry := range s.contexts {
+		if now.Sub(entry.updated) > sessionIdleTTL {
+			delete(s.contexts, other)
+		}
+	}
+	entry, ok := s.contexts[id]
+	if !ok {
+		entry = &sessionEntry{}
+		s.contexts[id] = entry
+	}
+	fn(&entry.wc)
+	entry.updated = now
+}
+
+// Forget drops the working context of a session that ended.
+func (s *Sessions) Forget(id string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.contexts, id)
+	delete(s.connected, id)
+}
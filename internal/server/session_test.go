@@ -0,0 +1,41 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessions(t *testing.T) {
+	s := NewSessions()
+	assert.Equal(t, WorkingContext{}, s.Get("a"))
+
+	s.Update("a", func(wc *WorkingContext) { wc.RunID = "run-1" })
+	s.Update("a", func(wc *WorkingContext) { wc.Model = "m" })
+	s.Update("b", func(wc *WorkingContext) { wc.TestSuite = "suite" })
+	assert.Equal(t, WorkingContext{RunID: "run-1", Model: "m"}, s.Get("a"))
+	assert.Equal(t, WorkingContext{TestSuite: "suite"}, s.Get("b"))
+
+	s.Forget("a")
+	assert.Equal(t, WorkingContext{}, s.Get("a"))
+
+	// Idle sessions are forgotten when another one changes.
+	s.contexts["b"].updated = time.Now().Add(-2 * sessionIdleTTL)
+	s.Update("c", func(wc *WorkingContext) { wc.RunID = "run-2" })
+	assert.Equal(t, WorkingContext{}, s.Get("b"))
+
+	s.Connect("d")
+	s.Connect("c")
+	assert.Equal(t, []string{"c", "d"}, s.Connected())
+	s.Forget("d")
+	assert.Equal(t, []string{"c"}, s.Connected())
+
+	// A nil Sessions keeps nothing.
+	var none *Sessions
+	none.Update("a", func(wc *WorkingContext) { wc.RunID = "run-1" })
+	none.Connect("a")
+	none.Forget("a")
+	assert.Equal(t, WorkingContext{}, none.Get("a"))
+	assert.Empty(t, none.Connected())
+}
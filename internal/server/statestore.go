@@ -0,0 +1,43 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/valkey-io/valkey-go"
+)
+
+// DefaultStateKeyPrefix prefixes the keys of the server state in a state
+// store.
+const DefaultStateKeyPrefix = "llm-testing:"
+
+// ParseStateStore parses the URL of a state store, which keeps the OAuth
+// state and jobs of the server replicas. Redis and Valkey are supported,
+// with redis:// or rediss:// (TLS) URLs, e.g.
+// redis://:password@redis:6379/0. An empty URL returns nil: the state is
+// kept in memory.
+func ParseStateStore(rawURL string) (*valkey.ClientOption, error) {
+	if rawURL == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		// The error of url.Parse quotes the URL, which may hold a password.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("invalid state store URL: %w", err)
+	}
+	switch u.Scheme {
+	case "redis", "rediss":
+	default:
+		return nil, fmt.Errorf("unsupported state store %q (supported: redis://, rediss://)", u.Scheme+"://")
+	}
+	opt, err := valkey.ParseURL(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid state store URL: %w", err)
+	}
+	return &opt, nil
+}
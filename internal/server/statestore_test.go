@@ -0,0 +1,32 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStateStore(t *testing.T) {
+	opt, err := ParseStateStore("")
+	require.NoError(t, err)
+	assert.Nil(t, opt)
+
+	opt, err = ParseStateStore("redis://:s3cret@redis:6379/2")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"redis:6379"}, opt.InitAddress)
+	assert.Equal(t, "s3cret", opt.Password)
+	assert.Equal(t, 2, opt.SelectDB)
+	assert.Nil(t, opt.TLSConfig)
+
+	opt, err = ParseStateStore("rediss://redis.example.com:6380")
+	require.NoError(t, err)
+	assert.NotNil(t, opt.TLSConfig)
+
+	_, err = ParseStateStore("postgres://db/llm-testing")
+	assert.ErrorContains(t, err, `unsupported state store "postgres://" (supported: redis://, rediss://)`)
+
+	_, err = ParseStateStore("redis://:s3cret@redis:port")
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "s3cret")
+}
@@ -0,0 +1,110 @@
+package testsuite
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ModelAliases map short names, e.g. judge-default or small-gpu, to models
+// with their provider and deployment settings, so that clients can name a
+// model without knowing how it is reached or deployed. A nil ModelAliases
+// has no aliases.
+type ModelAliases map[string]Model
+
+type aliasesFile struct {
+	Aliases map[string]Model `yaml:"aliases"`
+}
+
+// LoadModelAliases reads model aliases from a YAML file of the form:
+//
+//	aliases:
+//	  judge-default:
+//	    name: claude-sonnet-4-5
+//	    provider: anthropic
+//	  small-gpu:
+//	    name: qwen2.5-7b-instruct
+//	    model_uri: hf://Qwen/Qwen2.5-7B-Instruct
+//	    gpu_count: 1
+//	    runtime_args: ["--max-model-len=8192"]
+//
+// An alias without a name names the model itself.
+func LoadModelAliases(path string) (ModelAliases, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read model aliases: %w", err)
+	}
+
+	var f aliasesFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse model aliases: %w", err)
+	}
+
+	aliases := make(ModelAliases, len(f.Aliases))
+	for alias, model := range f.Aliases {
+		if strings.TrimSpace(alias) == "" {
+			return nil, fmt.Errorf("model alias without name")
+		}
+		if model.Name == "" {
+			model.Name = alias
+		}
+		aliases[alias] = model
+	}
+	return aliases, nil
+}
+
+// Lookup returns a copy of the model an alias stands for.
+func (a ModelAliases) Lookup(alias string) (Model, bool) {
+	model, ok := a[alias]
+	if !ok {
+		return Model{}, false
+	}
+	// Copy through JSON, so that callers cannot change the maps and slices
+	// of the alias.
+	var copied Model
+	data, err := json.Marshal(model)
+	if err != nil || json.Unmarshal(data, &copied) != nil {
+		return model, true
+	}
+	return copied, true
+}
+
+// ModelName returns the name of the model an alias stands for, or name if
+// it is not an alias.
+func (a ModelAliases) ModelName(name string) string {
+	if model, ok := a[name]; ok {
+		return model.Name
+	}
+	return name
+}
+
+// DecodeModels decodes a JSON array of models. A model whose name is an
+// alias gets the settings of the alias, unless it sets them itself, and the
+// name of the aliased model.
+func (a ModelAliases) DecodeModels(data []byte) ([]Model, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	models := make([]Model, 0, len(raw))
+	for _, item := range raw {
+		var named struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(item, &named); err != nil {
+			return nil, err
+		}
+		model, aliased := a.Lookup(named.Name)
+		if err := json.Unmarshal(item, &model); err != nil {
+			return nil, err
+		}
+		if aliased {
+			model.Name = a.ModelName(named.Name)
+		}
+		models = append(models, model)
+	}
+	return models, nil
+}
@@ -0,0 +1,77 @@
+package testsuite
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadModelAliases(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "aliases.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`aliases:
+  judge-default:
+    name: claude-sonnet-4-5
+    provider: anthropic
+  small-gpu:
+    model_uri: hf://Qwen/Qwen2.5-7B-Instruct
+    gpu_count: 1
+    runtime_args: ["--max-model-len=8192"]
+    tolerations:
+      - key: nvidia.com/gpu
+        operator: Exists
+`), 0o644))
+
+	aliases, err := LoadModelAliases(path)
+	require.NoError(t, err)
+	require.Len(t, aliases, 2)
+	assert.Equal(t, "claude-sonnet-4-5", aliases.ModelName("judge-default"))
+	assert.Equal(t, "gpt-4o", aliases.ModelName("gpt-4o"))
+
+	// An alias without a name names the model itself.
+	model, ok := aliases.Lookup("small-gpu")
+	require.True(t, ok)
+	assert.Equal(t, "small-gpu", model.Name)
+	assert.Equal(t, 1, model.GPUCount)
+	assert.Equal(t, "nvidia.com/gpu", model.Tolerations[0].Key)
+
+	// Lookup returns a copy.
+	model.RuntimeArgs[0] = "--changed"
+	again, _ := aliases.Lookup("small-gpu")
+	assert.Equal(t, []string{"--max-model-len=8192"}, again.RuntimeArgs)
+
+	_, err = LoadModelAliases(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read model aliases")
+}
+
+func TestModelAliasesDecodeModels(t *testing.T) {
+	aliases := ModelAliases{
+		"small-gpu": {Name: "qwen2.5-7b", ModelURI: "hf://Qwen/Qwen2.5-7B-Instruct", GPUCount: 1, Env: map[string]string{"A": "1"}},
+	}
+
+	models, err := aliases.DecodeModels([]byte(`[
+		{"name": "small-gpu", "gpu_count": 2, "env": {"B": "2"}},
+		{"name": "gpt-4o", "provider": "openai"}
+	]`))
+	require.NoError(t, err)
+	require.Len(t, models, 2)
+	assert.Equal(t, Model{
+		Name:     "qwen2.5-7b",
+		ModelURI: "hf://Qwen/Qwen2.5-7B-Instruct",
+		GPUCount: 2,
+		Env:      map[string]string{"A": "1", "B": "2"},
+	}, models[0])
+	assert.Equal(t, Model{Name: "gpt-4o", Provider: "openai"}, models[1])
+	// The alias is unchanged.
+	assert.Equal(t, map[string]string{"A": "1"}, aliases["small-gpu"].Env)
+
+	var none ModelAliases
+	models, err = none.DecodeModels([]byte(`[{"name": "small-gpu"}]`))
+	require.NoError(t, err)
+	assert.Equal(t, []Model{{Name: "small-gpu"}}, models)
+
+	_, err = aliases.DecodeModels([]byte(`{"name": "small-gpu"}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,47 @@
+package testsuite
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// AnswerKeyFile is the file in a run directory holding the expected answers
+// of a blind run, keyed by question ID.
+const AnswerKeyFile = "answer_key.json"
+
+// WriteAnswerKey writes the expected answers of questions to the answer key
+// file in dir.
+func WriteAnswerKey(dir string, questions []Question) error {
+	key := make(map[string]string, len(questions))
+	for _, q := range questions {
+		key[q.ID] = q.ExpectedAnswer
+	}
+	data, err := json.MarshalIndent(key, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal answer key: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, AnswerKeyFile), data, 0o600); err != nil {
+		return fmt.Errorf("failed to write answer key: %w", err)
+	}
+	return nil
+}
+
+// ReadAnswerKey reads the answer key file in dir. It returns nil if the
+// directory has none, i.e. the run was not blind.
+func ReadAnswerKey(dir string) (map[string]string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, AnswerKeyFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read answer key: %w", err)
+	}
+	var key map[string]string
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, fmt.Errorf("failed to parse answer key: %w", err)
+	}
+	return key, nil
+}
@@ -7,9 +7,13 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"maps"
+	"math"
 	"os"
 	"path"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -18,24 +22,218 @@ import (
 //go:embed all:testdata
 var embeddedSuites embed.FS
 
+// ErrNotFound is matched by the errors for test suites that do not exist.
+var ErrNotFound = errors.New("test suite not found")
+
+// notFoundError reports a test suite that does not exist.
+type notFoundError struct {
+	name   string
+	reason string
+}
+
+func (e *notFoundError) Error() string {
+	if e.reason != "" {
+		return fmt.Sprintf("test suite %q not found: %s", e.name, e.reason)
+	}
+	return fmt.Sprintf("test suite %q not found", e.name)
+}
+
+func (e *notFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
 // Load loads a test suite by name, searching first in the external directory
-// (if provided), then in the embedded test suites.
+// (if provided), then in the embedded test suites. The suites it extends or
+// includes questions from are searched the same way.
 func Load(name string, externalDir string) (*TestSuite, error) {
+	suite, err := load(name, externalDir, nil)
+	if err != nil {
+		return nil, err
+	}
+	if suite.Strategy == "" {
+		suite.Strategy = "qa"
+	}
+	if err := suite.Weights.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid weights for suite %q: %w", name, err)
+	}
+	suite.contentHash = suite.hash()
+	return suite, nil
+}
+
+// load loads a suite and composes it with the suites it extends or
+// includes. loading holds the suites being loaded, to detect cycles.
+func load(name string, externalDir string, loading []string) (*TestSuite, error) {
+	if slices.Contains(loading, name) {
+		return nil, fmt.Errorf("test suite %q: cycle in extends or include_questions: %s", name, strings.Join(append(loading, name), " -> "))
+	}
+	fsys, err := Open(name, externalDir)
+	if err != nil {
+		return nil, err
+	}
+	suite, err := loadFromFS(fsys, name)
+	if err != nil || !suite.composed() {
+		return suite, err
+	}
+	return compose(suite, externalDir, append(loading, name))
+}
+
+// compose merges a suite into the suite it extends, and adds the questions
+// of the suites it includes. Set fields of the suite override inherited
+// ones; weights and prompt profiles are merged like questions, and the
+// suite's own examples replace inherited ones.
+func compose(suite *TestSuite, externalDir string, loading []string) (*TestSuite, error) {
+	name := loading[len(loading)-1]
+	merged := &TestSuite{}
+	if suite.Extends != "" {
+		base, err := load(suite.Extends, externalDir, loading)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load suite %q extended by %q: %w", suite.Extends, name, err)
+		}
+		merged = base
+	}
+
+	for _, include := range suite.IncludeQuestions {
+		included, err := load(include, externalDir, loading)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load suite %q included by %q: %w", include, name, err)
+		}
+		merged.Questions = mergeQuestions(merged.Questions, included.Questions)
+		merged.Weights = mergeWeights(merged.Weights, included.Weights)
+		merged.Blind = merged.Blind || included.Blind
+	}
+	merged.Questions = mergeQuestions(merged.Questions, suite.Questions)
+	merged.Weights = mergeWeights(merged.Weights, suite.Weights)
+	if len(suite.Profiles) > 0 {
+		profiles := maps.Clone(merged.Profiles)
+		if profiles == nil {
+			profiles = make(map[string]Prompt, len(suite.Profiles))
+		}
+		maps.Copy(profiles, suite.Profiles)
+		merged.Profiles = profiles
+	}
+	if len(suite.Examples) > 0 {
+		merged.Examples = suite.Examples
+	}
+	merged.Blind = merged.Blind || suite.Blind
+
+	for _, f := range []struct{ dst, src *string }{
+		{&merged.Name, &suite.Name},
+		{&merged.Description, &suite.Description},
+		{&merged.Version, &suite.Version},
+		{&merged.Strategy, &suite.Strategy},
+		{&merged.QuestionsFile, &suite.QuestionsFile},
+		{&merged.Prompt.Role, &suite.Prompt.Role},
+		{&merged.Prompt.SystemMessage, &suite.Prompt.SystemMessage},
+	} {
+		if *f.src != "" {
+			*f.dst = *f.src
+		}
+	}
+	merged.Extends = suite.Extends
+	merged.IncludeQuestions = suite.IncludeQuestions
+	return merged, nil
+}
+
+// mergeQuestions appends questions to qs, replacing those with the same ID
+// in place.
+func mergeQuestions(qs, questions []Question) []Question {
+	index := make(map[string]int, len(qs))
+	for i, q := range qs {
+		index[q.ID] = i
+	}
+	for _, q := range questions {
+		if i, ok := index[q.ID]; ok {
+			qs[i] = q
+			continue
+		}
+		index[q.ID] = len(qs)
+		qs = append(qs, q)
+	}
+	return qs
+}
+
+// mergeWeights returns the weights of w overridden by those of other.
+func mergeWeights(w, other Weights) Weights {
+	merged := Weights{Sections: maps.Clone(w.Sections), Questions: maps.Clone(w.Questions)}
+	for section, v := range other.Sections {
+		if merged.Sections == nil {
+			merged.Sections = make(map[string]float64)
+		}
+		merged.Sections[section] = v
+	}
+	for id, v := range other.Questions {
+		if merged.Questions == nil {
+			merged.Questions = make(map[string]float64)
+		}
+		merged.Questions[id] = v
+	}
+	return merged
+}
+
+// Open returns the files of a test suite by name, searching like Load.
+func Open(name string, externalDir string) (fs.FS, error) {
+	if !fs.ValidPath(name) || name == "." {
+		return nil, &notFoundError{name: name, reason: "invalid name"}
+	}
+
 	// Try external directory first.
 	if externalDir != "" {
 		path := filepath.Join(externalDir, name)
 		if info, err := os.Stat(path); err == nil && info.IsDir() {
-			return loadFromFS(os.DirFS(path), name)
+			return os.DirFS(path), nil
 		}
 	}
 
 	// Fall back to embedded test suites.
 	// Use path.Join (not filepath.Join) because embed.FS always uses forward slashes.
-	subFS, err := fs.Sub(embeddedSuites, path.Join("testdata", name))
+	dir := path.Join("testdata", name)
+	if info, err := fs.Stat(embeddedSuites, dir); err != nil || !info.IsDir() {
+		return nil, &notFoundError{name: name}
+	}
+	return fs.Sub(embeddedSuites, dir)
+}
+
+// Export copies the files of the named suite to dir, e.g. to customize an
+// embedded suite. The suite is copied as is: a suite extending or including
+// others still refers to them by name. Existing files are only replaced if
+// force is set.
+func Export(name, externalDir, dir string, force bool) error {
+	fsys, err := Open(name, externalDir)
 	if err != nil {
-		return nil, fmt.Errorf("test suite %q not found: %w", name, err)
+		return err
 	}
-	return loadFromFS(subFS, name)
+	if !force {
+		err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(p))); err == nil {
+				return fmt.Errorf("%s already exists (use --force to overwrite)", filepath.Join(dir, filepath.FromSlash(p)))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, filepath.FromSlash(p))
+		if d.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", p, err)
+		}
+		if err := os.WriteFile(target, data, 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", target, err)
+		}
+		return nil
+	})
 }
 
 // List returns the names of all available test suites.
@@ -81,27 +279,47 @@ func loadFromFS(fsys fs.FS, name string) (*TestSuite, error) {
 		return nil, fmt.Errorf("failed to parse config.yaml for suite %q: %w", name, err)
 	}
 
-	if suite.Strategy == "" {
-		suite.Strategy = "qa"
-	}
-	if suite.QuestionsFile == "" {
-		suite.QuestionsFile = "questions.csv"
+	// Composed suites need no questions of their own.
+	file := suite.QuestionsFile
+	if file == "" {
+		file = "questions.csv"
+		if _, err := fs.Stat(fsys, file); suite.composed() && errors.Is(err, fs.ErrNotExist) {
+			return &suite, nil
+		}
+		suite.QuestionsFile = file
 	}
 
 	// Load questions CSV.
-	questions, err := loadQuestionsFromFS(fsys, suite.QuestionsFile)
+	questions, csvWeights, err := loadQuestionsFromFS(fsys, file, suite.Columns)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load questions for suite %q: %w", name, err)
 	}
 	suite.Questions = questions
 
+	// Per-question weights in config.yaml take precedence over the CSV Weight column.
+	for id, w := range csvWeights {
+		if _, ok := suite.Weights.Questions[id]; ok {
+			continue
+		}
+		if suite.Weights.Questions == nil {
+			suite.Weights.Questions = make(map[string]float64)
+		}
+		suite.Weights.Questions[id] = w
+	}
+
 	return &suite, nil
 }
 
-func loadQuestionsFromFS(fsys fs.FS, filename string) ([]Question, error) {
+// loadQuestionsFromFS reads the questions CSV. An optional Weight column
+// provides per-question rubric weights, returned keyed by question ID, and
+// optional Difficulty and Tags columns the questions' metadata. Columns are
+// found by the names in columns.
+func loadQuestionsFromFS(fsys fs.FS, filename string, columns Columns) ([]Question, map[string]float64, error) {
+	columns = columns.withDefaults()
+
 	f, err := fsys.Open(filename)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open %s: %w", filename, err)
+		return nil, nil, fmt.Errorf("failed to open %s: %w", filename, err)
 	}
 	defer func() { _ = f.Close() }()
 
@@ -112,7 +330,7 @@ func loadQuestionsFromFS(fsys fs.FS, filename string) ([]Question, error) {
 	// Read header.
 	header, err := reader.Read()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+		return nil, nil, fmt.Errorf("failed to read CSV header: %w", err)
 	}
 
 	colIndex := make(map[string]int)
@@ -121,9 +339,9 @@ func loadQuestionsFromFS(fsys fs.FS, filename string) ([]Question, error) {
 	}
 
 	// Validate required columns.
-	for _, required := range []string{"ID", "Section", "Question", "ExpectedAnswer"} {
+	for _, required := range columns.required() {
 		if _, ok := colIndex[required]; !ok {
-			return nil, fmt.Errorf("missing required CSV column: %s", required)
+			return nil, nil, fmt.Errorf("missing required CSV column: %s", required)
 		}
 	}
 
@@ -135,26 +353,53 @@ func loadQuestionsFromFS(fsys fs.FS, filename string) ([]Question, error) {
 		}
 	}
 
+	weightCol, hasWeights := colIndex[columns.Weight]
+	difficultyCol, hasDifficulty := colIndex[columns.Difficulty]
+	tagsCol, hasTags := colIndex[columns.Tags]
+
 	var questions []Question
+	var weights map[string]float64
 	for lineNum := 2; ; lineNum++ { // lineNum starts at 2 (1-indexed, after header).
 		record, err := reader.Read()
 		if errors.Is(err, io.EOF) {
 			break
 		}
 		if err != nil {
-			return nil, fmt.Errorf("failed to read CSV row %d: %w", lineNum, err)
+			return nil, nil, fmt.Errorf("failed to read CSV row %d: %w", lineNum, err)
 		}
 		if len(record) < minCols {
-			return nil, fmt.Errorf("CSV row %d has %d columns, expected at least %d", lineNum, len(record), minCols)
+			return nil, nil, fmt.Errorf("CSV row %d has %d columns, expected at least %d", lineNum, len(record), minCols)
 		}
 
-		questions = append(questions, Question{
-			ID:             record[colIndex["ID"]],
-			Section:        record[colIndex["Section"]],
-			QuestionText:   record[colIndex["Question"]],
-			ExpectedAnswer: record[colIndex["ExpectedAnswer"]],
-		})
+		q := Question{
+			ID:             record[colIndex[columns.ID]],
+			Section:        record[colIndex[columns.Section]],
+			QuestionText:   record[colIndex[columns.Question]],
+			ExpectedAnswer: record[colIndex[columns.ExpectedAnswer]],
+		}
+		if hasDifficulty {
+			q.Difficulty = strings.TrimSpace(record[difficultyCol])
+		}
+		if hasTags {
+			q.Tags = ParseTags(record[tagsCol])
+		}
+		questions = append(questions, q)
+
+		if hasWeights {
+			raw := strings.TrimSpace(record[weightCol])
+			if raw == "" {
+				continue
+			}
+			w, err := strconv.ParseFloat(raw, 64)
+			if err != nil || math.IsNaN(w) || math.IsInf(w, 0) {
+				return nil, nil, fmt.Errorf("CSV row %d has invalid weight %q", lineNum, raw)
+			}
+			if weights == nil {
+				weights = make(map[string]float64)
+			}
+			weights[q.ID] = w
+		}
 	}
 
-	return questions, nil
+	return questions, weights, nil
 }
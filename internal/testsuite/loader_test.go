@@ -3,6 +3,7 @@ package testsuite
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -43,7 +44,7 @@ func TestLoadNonexistentSuite(t *testing.T) {
 func TestListEmbeddedSuites(t *testing.T) {
 	names, err := List("")
 	require.NoError(t, err)
-	assert.Contains(t, names, "kubernetes-cka-v2")
+	assert.Subset(t, names, []string{"kubernetes-cka-v2", "kubernetes-cks", "kubernetes-troubleshooting", "helm-gitops"})
 }
 
 func TestSuiteDefaults(t *testing.T) {
@@ -142,3 +143,273 @@ prompt:
 	assert.Equal(t, "99", suite.Version)
 	assert.Len(t, suite.Questions, 1)
 }
+
+func TestLoadSuiteWeights(t *testing.T) {
+	tmpDir := t.TempDir()
+	suiteDir := filepath.Join(tmpDir, "weighted")
+	require.NoError(t, os.MkdirAll(suiteDir, 0o755))
+
+	config := `name: Weighted
+weights:
+  sections:
+    Troubleshooting: 2
+  questions:
+    "3": 5
+`
+	require.NoError(t, os.WriteFile(filepath.Join(suiteDir, "config.yaml"), []byte(config), 0o644))
+
+	csv := `ID,Section,Question,ExpectedAnswer,Weight
+1,Basics,Q1,A1,
+2,Troubleshooting,Q2,A2,
+3,Basics,Q3,A3,4
+4,Basics,Q4,A4,0.5
+`
+	require.NoError(t, os.WriteFile(filepath.Join(suiteDir, "questions.csv"), []byte(csv), 0o644))
+
+	suite, err := Load("weighted", tmpDir)
+	require.NoError(t, err)
+
+	w := suite.Weights
+	assert.Equal(t, 1.0, w.For("1", "Basics"))
+	assert.Equal(t, 2.0, w.For("2", "Troubleshooting"))
+	assert.Equal(t, 5.0, w.For("3", "Basics")) // config.yaml overrides the CSV column
+	assert.Equal(t, 0.5, w.For("4", "Basics"))
+}
+
+func TestLoadSuiteQuestionMetadata(t *testing.T) {
+	dir := t.TempDir()
+	writeSuite(t, dir, "tagged", "name: Tagged\n", `ID,Section,Question,ExpectedAnswer,Difficulty,Tags
+1,Basics,Q1,A1,easy,"networking, storage"
+2,Basics,Q2,A2, hard ,storage;security
+3,Basics,Q3,A3,,
+`)
+
+	suite, err := Load("tagged", dir)
+	require.NoError(t, err)
+	require.Len(t, suite.Questions, 3)
+	assert.Equal(t, "easy", suite.Questions[0].Difficulty)
+	assert.Equal(t, []string{"networking", "storage"}, suite.Questions[0].Tags)
+	assert.Equal(t, "hard", suite.Questions[1].Difficulty)
+	assert.Equal(t, []string{"storage", "security"}, suite.Questions[1].Tags)
+	assert.Empty(t, suite.Questions[2].Tags)
+
+	require.NoError(t, suite.SelectTags([]string{"Networking", "security"}))
+	require.Len(t, suite.Questions, 2)
+	assert.Equal(t, "1", suite.Questions[0].ID)
+	assert.Equal(t, "2", suite.Questions[1].ID)
+
+	assert.Error(t, suite.SelectTags([]string{"gpu"}))
+	assert.Len(t, suite.Questions, 2, "a failed selection leaves the questions unchanged")
+}
+
+func TestContentHash(t *testing.T) {
+	suite, err := Load("kubernetes-cka-v2", "")
+	require.NoError(t, err)
+
+	hash := suite.ContentHash()
+	assert.True(t, strings.HasPrefix(hash, "sha256:"))
+
+	again, err := Load("kubernetes-cka-v2", "")
+	require.NoError(t, err)
+	again.Version = "changed"
+	again.Description = "changed"
+	assert.Equal(t, hash, again.ContentHash(), "metadata does not affect the hash")
+
+	// Selections for a run do not change the hash of the loaded suite.
+	again.Questions, again.Examples = again.Questions[:1], nil
+	assert.Equal(t, hash, again.ContentHash())
+
+	edited, err := Load("kubernetes-cka-v2", "")
+	require.NoError(t, err)
+	edited.Questions[0].ExpectedAnswer += " (updated)"
+	assert.NotEqual(t, hash, edited.hash())
+}
+
+func TestLoadSuiteInvalidWeights(t *testing.T) {
+	tmpDir := t.TempDir()
+	suiteDir := filepath.Join(tmpDir, "bad")
+	require.NoError(t, os.MkdirAll(suiteDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(suiteDir, "config.yaml"), []byte("name: Bad\n"), 0o644))
+
+	csv := "ID,Section,Question,ExpectedAnswer,Weight\n1,Basics,Q1,A1,heavy\n"
+	require.NoError(t, os.WriteFile(filepath.Join(suiteDir, "questions.csv"), []byte(csv), 0o644))
+	_, err := Load("bad", tmpDir)
+	assert.ErrorContains(t, err, "invalid weight")
+
+	csv = "ID,Section,Question,ExpectedAnswer,Weight\n1,Basics,Q1,A1,-1\n"
+	require.NoError(t, os.WriteFile(filepath.Join(suiteDir, "questions.csv"), []byte(csv), 0o644))
+	_, err = Load("bad", tmpDir)
+	assert.ErrorContains(t, err, "must not be negative")
+
+	csv = "ID,Section,Question,ExpectedAnswer,Weight\n1,Basics,Q1,A1,NaN\n"
+	require.NoError(t, os.WriteFile(filepath.Join(suiteDir, "questions.csv"), []byte(csv), 0o644))
+	_, err = Load("bad", tmpDir)
+	assert.ErrorContains(t, err, `invalid weight "NaN"`)
+
+	csv = "ID,Section,Question,ExpectedAnswer\n1,Basics,Q1,A1\n"
+	require.NoError(t, os.WriteFile(filepath.Join(suiteDir, "questions.csv"), []byte(csv), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(suiteDir, "config.yaml"), []byte("name: Bad\nweights:\n  sections:\n    Basics: .inf\n"), 0o644))
+	_, err = Load("bad", tmpDir)
+	assert.ErrorContains(t, err, `weight for section "Basics" must be a finite number`)
+}
+
+func TestOpenInvalidName(t *testing.T) {
+	for _, name := range []string{"", ".", "../etc", "/abs"} {
+		_, err := Open(name, t.TempDir())
+		assert.ErrorContains(t, err, "invalid name", name)
+	}
+}
+
+// writeSuite writes a suite's config.yaml and, unless empty, questions.csv.
+func writeSuite(t *testing.T, dir, name, config, questions string) {
+	t.Helper()
+	suiteDir := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(suiteDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(suiteDir, "config.yaml"), []byte(config), 0o644))
+	if questions != "" {
+		require.NoError(t, os.WriteFile(filepath.Join(suiteDir, "questions.csv"), []byte(questions), 0o644))
+	}
+}
+
+func TestLoadExtends(t *testing.T) {
+	dir := t.TempDir()
+	writeSuite(t, dir, "cka-plus", `name: CKA Plus
+extends: kubernetes-cka-v2
+weights:
+  questions:
+    "100": 2
+`, "ID,Section,Question,ExpectedAnswer\n1,Setup & Aliases,Replaced?,yes\n101,Extra,New?,yes\n")
+
+	suite, err := Load("cka-plus", dir)
+	require.NoError(t, err)
+	assert.Equal(t, "CKA Plus", suite.Name)
+	assert.Equal(t, "2", suite.Version, "inherited")
+	assert.Equal(t, "qa", suite.Strategy)
+	assert.Contains(t, suite.Prompt.SystemMessage, "CKA")
+	require.Len(t, suite.Questions, 101)
+	assert.Equal(t, "Replaced?", suite.Questions[0].QuestionText, "own questions replace inherited ones in place")
+	assert.Equal(t, "101", suite.Questions[100].ID)
+	assert.Equal(t, 2.0, suite.Weights.For("100", ""))
+}
+
+func TestLoadIncludeQuestions(t *testing.T) {
+	dir := t.TempDir()
+	writeSuite(t, dir, "a", "name: A\n", "ID,Section,Question,ExpectedAnswer,Weight\na1,S,From A?,yes,3\nshared,S,From A?,yes,\n")
+	writeSuite(t, dir, "b", "name: B\n", "ID,Section,Question,ExpectedAnswer\nb1,S,From B?,yes\nshared,S,From B?,yes\n")
+	writeSuite(t, dir, "ab", `name: A and B
+prompt:
+  system_message: Answer briefly.
+include_questions: [a, b]
+`, "")
+
+	suite, err := Load("ab", dir)
+	require.NoError(t, err)
+	assert.Equal(t, "A and B", suite.Name)
+	assert.Equal(t, "Answer briefly.", suite.Prompt.SystemMessage)
+	var ids []string
+	for _, q := range suite.Questions {
+		ids = append(ids, q.ID)
+	}
+	assert.Equal(t, []string{"a1", "shared", "b1"}, ids)
+	assert.Equal(t, "From B?", suite.Questions[1].QuestionText, "de-duplicated by ID")
+	assert.Equal(t, 3.0, suite.Weights.For("a1", "S"), "CSV weights of included suites apply")
+}
+
+func TestLoadCompositionErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeSuite(t, dir, "x", "name: X\nextends: y\n", "")
+	writeSuite(t, dir, "y", "name: Y\ninclude_questions: [x]\n", "")
+	writeSuite(t, dir, "orphan", "name: Orphan\nextends: missing\n", "")
+
+	_, err := Load("x", dir)
+	assert.ErrorContains(t, err, "cycle in extends or include_questions: x -> y -> x")
+
+	_, err = Load("orphan", dir)
+	assert.ErrorContains(t, err, `test suite "missing" not found`)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = Load("../x", dir)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestLoadPromptProfiles(t *testing.T) {
+	dir := t.TempDir()
+	writeSuite(t, dir, "base", `name: Base
+prompt:
+  role: assistant
+  system_message: Answer in detail.
+profiles:
+  concise:
+    system_message: Answer in one sentence.
+  cot:
+    system_message: Think step by step.
+`, "ID,Section,Question,ExpectedAnswer\n1,S,Q?,A\n")
+	writeSuite(t, dir, "child", `name: Child
+extends: base
+profiles:
+  cot:
+    role: expert
+    system_message: Reason before answering.
+`, "")
+
+	suite, err := Load("child", dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"concise", "cot"}, suite.ProfileNames())
+
+	require.NoError(t, suite.SelectProfile(""))
+	assert.Equal(t, "Answer in detail.", suite.Prompt.SystemMessage)
+	assert.Empty(t, suite.Profile)
+
+	hash := suite.ContentHash()
+	require.NoError(t, suite.SelectProfile("cot"))
+	assert.Equal(t, "cot", suite.Profile)
+	assert.Equal(t, Prompt{Role: "expert", SystemMessage: "Reason before answering."}, suite.Prompt, "own profiles replace inherited ones")
+	assert.Equal(t, hash, suite.ContentHash(), "the profile is recorded separately")
+
+	suite, err = Load("child", dir)
+	require.NoError(t, err)
+	require.NoError(t, suite.SelectProfile("concise"))
+	assert.Equal(t, Prompt{Role: "assistant", SystemMessage: "Answer in one sentence."}, suite.Prompt, "empty profile fields keep the default")
+
+	assert.ErrorContains(t, suite.SelectProfile("verbose"), `no prompt profile "verbose" (available: concise, cot)`)
+
+	suite.Profiles["expert"] = Prompt{Role: "expert"}
+	assert.ErrorContains(t, suite.SelectProfile("expert"), `prompt profile "expert" of test suite "Child" has no system_message`)
+}
+
+func TestExport(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "my-cks")
+	require.NoError(t, Export("kubernetes-cks", "", dir, false))
+
+	exported, err := Load("my-cks", filepath.Dir(dir))
+	require.NoError(t, err)
+	embedded, err := Load("kubernetes-cks", "")
+	require.NoError(t, err)
+	assert.Equal(t, embedded.ContentHash(), exported.ContentHash())
+
+	assert.ErrorContains(t, Export("kubernetes-cks", "", dir, false), "already exists")
+	assert.NoError(t, Export("kubernetes-cks", "", dir, true))
+	assert.ErrorContains(t, Export("missing", "", dir, false), "not found")
+}
+
+func TestLoadColumnMapping(t *testing.T) {
+	dir := t.TempDir()
+	writeSuite(t, dir, "bank", `name: Bank
+columns:
+  id: "No."
+  section: Topic
+  question: Task
+  expected_answer: Solution
+  weight: Points
+`, "No.,Topic,Task,Solution,Points,Difficulty\n7,Pods,List pods?,kubectl get pods,2,easy\n")
+
+	suite, err := Load("bank", dir)
+	require.NoError(t, err)
+	require.Len(t, suite.Questions, 1)
+	assert.Equal(t, Question{ID: "7", Section: "Pods", QuestionText: "List pods?", ExpectedAnswer: "kubectl get pods", Difficulty: "easy"}, suite.Questions[0])
+	assert.Equal(t, 2.0, suite.Weights.For("7", "Pods"))
+
+	writeSuite(t, dir, "unmapped", "name: Unmapped\ncolumns:\n  question: Task\n", "ID,Section,Question,ExpectedAnswer\n1,S,Q?,A\n")
+	_, err = Load("unmapped", dir)
+	assert.ErrorContains(t, err, "missing required CSV column: Task")
+}
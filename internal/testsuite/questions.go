@@ -0,0 +1,74 @@
+package testsuite
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// QuestionRecord records how a model answered one question: details of the
+// answer that the results file does not hold.
+type QuestionRecord struct {
+	ID string `json:"id"`
+	// Duration is the time taken to answer, in seconds.
+	Duration         float64 `json:"duration"`
+	PromptTokens     int     `json:"prompt_tokens,omitempty"`
+	CompletionTokens int     `json:"completion_tokens,omitempty"`
+	FinishReason     string  `json:"finish_reason,omitempty"`
+	// Verdict is set when the answer was judged during the run.
+	Verdict *bool `json:"verdict,omitempty"`
+}
+
+// QuestionsFilePath returns the path of the question records belonging to a
+// results file.
+func QuestionsFilePath(resultsFile string) string {
+	return strings.TrimSuffix(resultsFile, ".txt") + "_questions.json"
+}
+
+// WriteQuestionRecords writes the records of results next to the results
+// file they were written to.
+func WriteQuestionRecords(resultsFile string, results []*Result) error {
+	records := make([]QuestionRecord, 0, len(results))
+	for _, r := range results {
+		records = append(records, QuestionRecord{
+			ID:               r.Question.ID,
+			Duration:         r.Duration.Seconds(),
+			PromptTokens:     r.PromptTokens,
+			CompletionTokens: r.CompletionTokens,
+			FinishReason:     r.FinishReason,
+			Verdict:          r.Verdict,
+		})
+	}
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal question records: %w", err)
+	}
+	if err := os.WriteFile(QuestionsFilePath(resultsFile), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write question records: %w", err)
+	}
+	return nil
+}
+
+// ReadQuestionRecords reads the question records belonging to a results
+// file, keyed by question ID. It returns nil if there are none, e.g. for
+// runs recorded before they were introduced.
+func ReadQuestionRecords(resultsFile string) (map[string]QuestionRecord, error) {
+	data, err := os.ReadFile(QuestionsFilePath(resultsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read question records: %w", err)
+	}
+	var records []QuestionRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to parse question records: %w", err)
+	}
+	byID := make(map[string]QuestionRecord, len(records))
+	for _, r := range records {
+		byID[r.ID] = r
+	}
+	return byID, nil
+}
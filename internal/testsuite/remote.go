@@ -0,0 +1,282 @@
+package testsuite
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// gitPrefix marks a suites source as a git repository, as in Terraform
+// module sources.
+const gitPrefix = "git::"
+
+// commitRef matches refs naming a commit rather than a branch or tag.
+var commitRef = regexp.MustCompile(`^[0-9a-f]{40}([0-9a-f]{24})?$`)
+
+// IsRemote reports whether an external suites directory is a git or HTTPS
+// URL to be fetched with Fetch rather than a local path.
+func IsRemote(source string) bool {
+	return strings.HasPrefix(source, gitPrefix) || strings.HasPrefix(source, "https://") || strings.HasPrefix(source, "http://")
+}
+
+// remoteSource is a parsed remote suites directory.
+type remoteSource struct {
+	git      bool
+	url      string // without subdir, ref, and checksum
+	subdir   string // within the repository or archive
+	ref      string // git branch, tag, or commit
+	checksum string // hex SHA-256 of the archive
+}
+
+// parseRemote parses git::<url>[//<subdir>][?ref=<ref>] and
+// https://<url>[//<subdir>]?checksum=sha256:<hex>.
+func parseRemote(source string) (*remoteSource, error) {
+	src := &remoteSource{git: strings.HasPrefix(source, gitPrefix)}
+	raw := strings.TrimPrefix(source, gitPrefix)
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid suites URL %q: %w", source, err)
+	}
+	if before, after, ok := strings.Cut(u.Path, "//"); ok {
+		u.Path, src.subdir = before, after
+		if !filepath.IsLocal(src.subdir) {
+			return nil, fmt.Errorf("invalid suites URL %q: subdirectory %q leaves the repository", source, src.subdir)
+		}
+	}
+	query := u.Query()
+
+	if src.git {
+		src.ref = query.Get("ref")
+		query.Del("ref")
+	} else {
+		if u.Scheme != "https" {
+			return nil, fmt.Errorf("invalid suites URL %q: only HTTPS is supported", source)
+		}
+		sum := query.Get("checksum")
+		query.Del("checksum")
+		sum = strings.TrimPrefix(sum, "sha256:")
+		if sum == "" {
+			return nil, fmt.Errorf("suites URL %q needs a checksum, e.g. ?checksum=sha256:<hex>", source)
+		}
+		if _, err := hex.DecodeString(sum); err != nil || len(sum) != 2*sha256.Size {
+			return nil, fmt.Errorf("invalid suites URL %q: checksum must be a hex SHA-256", source)
+		}
+		src.checksum = strings.ToLower(sum)
+	}
+	u.RawQuery = query.Encode()
+	src.url = u.String()
+	return src, nil
+}
+
+// immutable reports whether the source always has the same contents, so
+// that a cached copy never needs to be fetched again.
+func (s *remoteSource) immutable() bool {
+	return !s.git || commitRef.MatchString(s.ref)
+}
+
+// Fetch returns a local directory with the suites of a remote source,
+// fetched into cacheDir. Git sources are cloned with the git command;
+// HTTPS sources are tarballs (optionally gzipped) verified against their
+// checksum. Sources pinned to content (a checksum or a commit ref) are
+// fetched once; others are fetched again on every call, falling back to the
+// cached copy if that fails. A local path is returned unchanged.
+func Fetch(ctx context.Context, source, cacheDir string) (string, error) {
+	if !IsRemote(source) {
+		return source, nil
+	}
+	src, err := parseRemote(source)
+	if err != nil {
+		return "", err
+	}
+
+	key := sha256.Sum256([]byte(source))
+	dest := filepath.Join(cacheDir, hex.EncodeToString(key[:8]))
+	cached := false
+	if info, err := os.Stat(dest); err == nil && info.IsDir() {
+		cached = true
+	}
+
+	if !cached || !src.immutable() {
+		if err := fetchRemote(ctx, src, cacheDir, dest); err != nil {
+			if !cached {
+				return "", err
+			}
+			slog.Warn("failed to update remote test suites, using cached copy", "source", source, "error", err)
+		}
+	}
+
+	dir := filepath.Join(dest, src.subdir)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("suites URL %q: no directory %q", source, src.subdir)
+	}
+	return dir, nil
+}
+
+// fetchRemote downloads a source into a temporary directory and moves it
+// to dest, so that a failed fetch leaves the cached copy intact.
+func fetchRemote(ctx context.Context, src *remoteSource, cacheDir, dest string) error {
+	if err := os.MkdirAll(cacheDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create suites cache directory: %w", err)
+	}
+	tmp, err := os.MkdirTemp(cacheDir, ".fetch-")
+	if err != nil {
+		return fmt.Errorf("failed to create suites cache directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+
+	if src.git {
+		err = cloneGit(ctx, src, tmp)
+	} else {
+		err = downloadTarball(ctx, src, tmp)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(dest); err != nil {
+		return fmt.Errorf("failed to replace cached suites: %w", err)
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		return fmt.Errorf("failed to replace cached suites: %w", err)
+	}
+	return nil
+}
+
+// cloneGit fetches a single commit of a repository into dir. Fetching by
+// ref rather than cloning works for commits as well as branches and tags.
+func cloneGit(ctx context.Context, src *remoteSource, dir string) error {
+	ref := src.ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"fetch", "--quiet", "--depth=1", src.url, ref},
+		{"checkout", "--quiet", "--detach", "FETCH_HEAD"},
+	} {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			if errors.Is(err, exec.ErrNotFound) {
+				return fmt.Errorf("git suites URLs need the git command: %w", err)
+			}
+			return fmt.Errorf("git %s %s failed: %w: %s", args[0], src.url, err, bytes.TrimSpace(out))
+		}
+	}
+	return os.RemoveAll(filepath.Join(dir, ".git"))
+}
+
+// downloadTarball downloads a tarball, verifies its checksum, and extracts
+// it into dir.
+func downloadTarball(ctx context.Context, src *remoteSource, dir string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src.url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to download suites: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download suites: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download suites from %s: %s", src.url, resp.Status)
+	}
+
+	// Verify before extracting anything.
+	f, err := os.CreateTemp(dir, ".download-")
+	if err != nil {
+		return fmt.Errorf("failed to download suites: %w", err)
+	}
+	defer func() { _ = f.Close(); _ = os.Remove(f.Name()) }()
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(f, h), resp.Body); err != nil {
+		return fmt.Errorf("failed to download suites: %w", err)
+	}
+	if sum := hex.EncodeToString(h.Sum(nil)); sum != src.checksum {
+		return fmt.Errorf("checksum mismatch for %s: got sha256:%s, want sha256:%s", src.url, sum, src.checksum)
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to extract suites: %w", err)
+	}
+	if err := extractTar(f, dir); err != nil {
+		return fmt.Errorf("failed to extract suites from %s: %w", src.url, err)
+	}
+	return nil
+}
+
+// extractTar extracts the directories and regular files of a tarball,
+// gzipped or not, into dir.
+func extractTar(r io.Reader, dir string) error {
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = gz.Close() }()
+		r = gz
+	} else {
+		r = br
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := filepath.FromSlash(strings.TrimPrefix(hdr.Name, "./"))
+		if name == "" || name == "." {
+			continue
+		}
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("entry %q leaves the archive", hdr.Name)
+		}
+		target := filepath.Join(dir, name)
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(out, tr)
+			if cerr := out.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				return err
+			}
+		default:
+			// Links and special files have no place in a test suite.
+		}
+	}
+}
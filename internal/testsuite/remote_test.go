@@ -0,0 +1,145 @@
+package testsuite
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// suiteTarball returns a gzipped tarball of files and its checksum.
+func suiteTarball(t *testing.T, files map[string]string) ([]byte, string) {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	sum := sha256.Sum256(buf.Bytes())
+	return buf.Bytes(), hex.EncodeToString(sum[:])
+}
+
+const remoteConfig = "name: Remote\nprompt:\n  system_message: Answer.\n"
+const remoteQuestions = "ID,Section,Question,ExpectedAnswer\n1,a,q,a\n"
+
+func TestFetchTarball(t *testing.T) {
+	tarball, sum := suiteTarball(t, map[string]string{
+		"repo-v1/suites/remote/config.yaml":   remoteConfig,
+		"repo-v1/suites/remote/questions.csv": remoteQuestions,
+	})
+	requests := 0
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write(tarball)
+	}))
+	defer srv.Close()
+	defer func(c *http.Client) { http.DefaultClient = c }(http.DefaultClient)
+	http.DefaultClient = srv.Client()
+
+	cacheDir := t.TempDir()
+	source := srv.URL + "/suites.tar.gz//repo-v1/suites?checksum=sha256:" + sum
+	dir, err := Fetch(context.Background(), source, cacheDir)
+	require.NoError(t, err)
+
+	suite, err := Load("remote", dir)
+	require.NoError(t, err)
+	assert.Equal(t, "Remote", suite.Name)
+
+	// Checksummed tarballs are fetched only once.
+	again, err := Fetch(context.Background(), source, cacheDir)
+	require.NoError(t, err)
+	assert.Equal(t, dir, again)
+	assert.Equal(t, 1, requests)
+
+	_, err = Fetch(context.Background(), srv.URL+"/suites.tar.gz?checksum=sha256:"+hex.EncodeToString(make([]byte, 32)), cacheDir)
+	assert.ErrorContains(t, err, "checksum mismatch")
+}
+
+func TestFetchTarballUnsafe(t *testing.T) {
+	tarball, sum := suiteTarball(t, map[string]string{"../escape": "x"})
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(tarball)
+	}))
+	defer srv.Close()
+	defer func(c *http.Client) { http.DefaultClient = c }(http.DefaultClient)
+	http.DefaultClient = srv.Client()
+
+	cacheDir := t.TempDir()
+	_, err := Fetch(context.Background(), srv.URL+"/suites.tar?checksum="+sum, cacheDir)
+	assert.ErrorContains(t, err, "leaves the archive")
+	assert.NoFileExists(t, filepath.Join(filepath.Dir(cacheDir), "escape"))
+}
+
+func TestFetchGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "suites", "remote"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "suites", "remote", "config.yaml"), []byte(remoteConfig), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "suites", "remote", "questions.csv"), []byte(remoteQuestions), 0o644))
+	for _, args := range [][]string{
+		{"init", "--quiet", "--initial-branch=main"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "suites"},
+		{"tag", "v1"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	dir, err := Fetch(context.Background(), "git::file://"+filepath.ToSlash(repo)+"//suites?ref=v1", t.TempDir())
+	require.NoError(t, err)
+	suite, err := Load("remote", dir)
+	require.NoError(t, err)
+	assert.Equal(t, "Remote", suite.Name)
+	assert.NoDirExists(t, filepath.Join(filepath.Dir(dir), ".git"))
+
+	_, err = Fetch(context.Background(), "git::file://"+filepath.ToSlash(repo)+"?ref=missing", t.TempDir())
+	assert.ErrorContains(t, err, "git fetch")
+}
+
+func TestParseRemote(t *testing.T) {
+	src, err := parseRemote("git::https://github.com/org/suites.git//llm/suites?ref=v1.2.0")
+	require.NoError(t, err)
+	assert.Equal(t, &remoteSource{git: true, url: "https://github.com/org/suites.git", subdir: "llm/suites", ref: "v1.2.0"}, src)
+	assert.False(t, src.immutable())
+
+	src, err = parseRemote("git::https://github.com/org/suites.git?ref=0123456789abcdef0123456789abcdef01234567")
+	require.NoError(t, err)
+	assert.True(t, src.immutable())
+
+	for source, want := range map[string]string{
+		"https://example.com/suites.tar.gz":                     "needs a checksum",
+		"https://example.com/suites.tar.gz?checksum=sha256:abc": "must be a hex SHA-256",
+		"http://example.com/suites.tar.gz?checksum=sha256:abc":  "only HTTPS",
+		"git::https://example.com/repo.git//../etc":             "leaves the repository",
+	} {
+		_, err := parseRemote(source)
+		assert.ErrorContains(t, err, want, source)
+	}
+}
+
+func TestFetchLocal(t *testing.T) {
+	dir, err := Fetch(context.Background(), "/srv/suites", "")
+	require.NoError(t, err)
+	assert.Equal(t, "/srv/suites", dir)
+}
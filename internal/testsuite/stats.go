@@ -0,0 +1,99 @@
+package testsuite
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+	"unicode/utf8"
+)
+
+// Stats summarizes a suite's questions to estimate the cost of running it.
+type Stats struct {
+	Sections []SectionCount `json:"sections"`
+
+	// MeanQuestionChars and MeanAnswerChars are the mean lengths of the
+	// questions and expected answers in characters.
+	MeanQuestionChars int `json:"mean_question_chars"`
+	MeanAnswerChars   int `json:"mean_expected_answer_chars"`
+
+	// PromptTokens estimates the prompt tokens sent per model in a run: the
+	// system message, examples, and question of every question.
+	PromptTokens int `json:"estimated_prompt_tokens"`
+}
+
+// SectionCount is the number of questions in a section.
+type SectionCount struct {
+	Section   string `json:"section"`
+	Questions int    `json:"questions"`
+}
+
+// Stats returns the statistics of the suite's questions. Sections are listed
+// in the order they first appear in.
+func (s *TestSuite) Stats() Stats {
+	var stats Stats
+	if len(s.Questions) == 0 {
+		return stats
+	}
+
+	prefix := estimateTokens(s.Prompt.SystemMessage)
+	for _, ex := range s.Examples {
+		prefix += estimateTokens(ex.Question) + estimateTokens(ex.Answer)
+	}
+
+	index := make(map[string]int)
+	var questionChars, answerChars int
+	for _, q := range s.Questions {
+		i, ok := index[q.Section]
+		if !ok {
+			i = len(stats.Sections)
+			index[q.Section] = i
+			stats.Sections = append(stats.Sections, SectionCount{Section: q.Section})
+		}
+		stats.Sections[i].Questions++
+
+		questionChars += utf8.RuneCountInString(q.QuestionText)
+		answerChars += utf8.RuneCountInString(q.ExpectedAnswer)
+		stats.PromptTokens += prefix + estimateTokens(q.QuestionText)
+	}
+	stats.MeanQuestionChars = questionChars / len(s.Questions)
+	stats.MeanAnswerChars = answerChars / len(s.Questions)
+	return stats
+}
+
+// LastModified returns the time the newest file of the named suite in the
+// external directory was modified. It is zero for embedded suites.
+func LastModified(name, externalDir string) (time.Time, error) {
+	if externalDir == "" {
+		return time.Time{}, nil
+	}
+	dir := filepath.Join(externalDir, name)
+	info, err := os.Stat(dir)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && !info.IsDir()) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var latest time.Time
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+		return nil
+	})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read suite %q: %w", name, err)
+	}
+	return latest, nil
+}
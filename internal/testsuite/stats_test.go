@@ -0,0 +1,49 @@
+package testsuite
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats(t *testing.T) {
+	suite := &TestSuite{
+		Prompt:   Prompt{SystemMessage: "12345678"}, // 2 tokens
+		Examples: []Example{{Question: "1234", Answer: "1234"}},
+		Questions: []Question{
+			{ID: "1", Section: "Pods", QuestionText: "12345678", ExpectedAnswer: "1234"},
+			{ID: "2", Section: "Nodes", QuestionText: "1234", ExpectedAnswer: "12"},
+			{ID: "3", Section: "Pods", QuestionText: "123456789012", ExpectedAnswer: "123456"},
+		},
+	}
+
+	stats := suite.Stats()
+	assert.Equal(t, []SectionCount{{Section: "Pods", Questions: 2}, {Section: "Nodes", Questions: 1}}, stats.Sections)
+	assert.Equal(t, 8, stats.MeanQuestionChars)
+	assert.Equal(t, 4, stats.MeanAnswerChars)
+	assert.Equal(t, 3*4+2+1+3, stats.PromptTokens, "system message and example per question")
+
+	assert.Zero(t, (&TestSuite{}).Stats())
+}
+
+func TestLastModified(t *testing.T) {
+	dir := t.TempDir()
+	writeSuite(t, dir, "mine", "name: Mine\n", "ID,Section,Question,ExpectedAnswer\n1,S,Q?,A\n")
+	modified := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, p := range []string{filepath.Join(dir, "mine"), filepath.Join(dir, "mine", "config.yaml")} {
+		require.NoError(t, os.Chtimes(p, modified.Add(-time.Hour), modified.Add(-time.Hour)))
+	}
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "mine", "questions.csv"), modified, modified))
+
+	got, err := LastModified("mine", dir)
+	require.NoError(t, err)
+	assert.True(t, modified.Equal(got), "newest file: %v", got)
+
+	got, err = LastModified("kubernetes-cka-v2", dir)
+	require.NoError(t, err)
+	assert.True(t, got.IsZero(), "embedded suites have no modification time")
+}
@@ -1,6 +1,19 @@
 package testsuite
 
-import "time"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+)
 
 // TestSuite represents a loaded test suite with its configuration and questions.
 // Models are NOT part of the suite -- they are provided at runtime by the user or agent.
@@ -10,17 +23,270 @@ type TestSuite struct {
 	Version       string     `yaml:"version"`
 	Strategy      string     `yaml:"strategy"` // e.g. "qa" (default)
 	QuestionsFile string     `yaml:"questions_file"`
+	Columns       Columns    `yaml:"columns"`
 	Prompt        Prompt     `yaml:"prompt"`
+	Weights       Weights    `yaml:"weights"`
 	Questions     []Question `yaml:"-"` // loaded separately from CSV
+
+	// Extends names a suite whose configuration and questions this suite
+	// inherits, and IncludeQuestions suites whose questions it adds.
+	// Questions are merged by ID: the suite's own replace inherited and
+	// included ones, and included ones replace inherited ones.
+	Extends          string   `yaml:"extends"`
+	IncludeQuestions []string `yaml:"include_questions"`
+
+	// Profiles are named prompts that replace Prompt when selected for a
+	// run, so that one question bank serves different evaluation intents.
+	// Profile is the name of the selected profile, if any.
+	Profiles map[string]Prompt `yaml:"profiles"`
+	Profile  string            `yaml:"-"`
+
+	// Examples are question and answer pairs sent as few-shot messages
+	// before each question.
+	Examples []Example `yaml:"examples"`
+
+	// Blind keeps the expected answers out of the results files: they are
+	// written to a separate answer key (see AnswerKeyFile) and joined with
+	// the answers locally when scoring per question, so that no external
+	// judge receives the whole key.
+	Blind bool `yaml:"blind"`
+
+	// contentHash is the ContentHash of the suite as loaded, before tags,
+	// a profile, or examples were selected for a run.
+	contentHash string
+}
+
+// composed reports whether the suite is built from other suites.
+func (s *TestSuite) composed() bool {
+	return s.Extends != "" || len(s.IncludeQuestions) > 0
+}
+
+// ProfileNames returns the names of the suite's prompt profiles, sorted.
+func (s *TestSuite) ProfileNames() []string {
+	names := slices.Collect(maps.Keys(s.Profiles))
+	slices.Sort(names)
+	return names
+}
+
+// SelectProfile replaces the suite's prompt with the named profile. The
+// profile must have a system message, since the role is not sent to the
+// model; an empty role keeps the suite's default. An empty name keeps the
+// default prompt.
+func (s *TestSuite) SelectProfile(name string) error {
+	if name == "" {
+		return nil
+	}
+	profile, ok := s.Profiles[name]
+	if !ok {
+		if len(s.Profiles) == 0 {
+			return fmt.Errorf("test suite %q has no prompt profiles", s.Name)
+		}
+		return fmt.Errorf("test suite %q has no prompt profile %q (available: %s)", s.Name, name, strings.Join(s.ProfileNames(), ", "))
+	}
+	if strings.TrimSpace(profile.SystemMessage) == "" {
+		return fmt.Errorf("prompt profile %q of test suite %q has no system_message", name, s.Name)
+	}
+	if profile.Role != "" {
+		s.Prompt.Role = profile.Role
+	}
+	s.Prompt.SystemMessage = profile.SystemMessage
+	s.Profile = name
+	return nil
+}
+
+// ContentHash returns a SHA-256 hash of what the suite asks and how answers
+// are weighted: its strategy, prompts, weights, questions, and examples.
+// Runs with the same hash are comparable even if the suite's version was not
+// bumped. The hash of a loaded suite is that of all its content: selecting
+// tags, a profile, or no examples for a run does not change it.
+func (s *TestSuite) ContentHash() string {
+	if s.contentHash != "" {
+		return s.contentHash
+	}
+	return s.hash()
+}
+
+// hash computes the ContentHash of the suite's current content.
+func (s *TestSuite) hash() string {
+	data, err := json.Marshal(struct {
+		Strategy  string
+		Prompt    Prompt
+		Profiles  map[string]Prompt `json:",omitempty"`
+		Weights   Weights
+		Questions []Question
+		Examples  []Example `json:",omitempty"`
+	}{s.Strategy, s.Prompt, s.Profiles, s.Weights, s.Questions, s.Examples})
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
 }
 
 // Model defines a model to test. Models are specified at runtime, not in suite config.
 // When ModelURI is set, the model can be deployed via KServe InferenceService.
 type Model struct {
-	Name        string  `json:"name"`
-	Temperature float64 `json:"temperature"`
-	ModelURI    string  `json:"model_uri,omitempty"` // KServe storage URI (e.g. "hf://org/model")
-	GPUCount    int     `json:"gpu_count,omitempty"` // GPU count for KServe deployment
+	Name        string  `json:"name" yaml:"name"`
+	Temperature float64 `json:"temperature" yaml:"temperature"`
+	ModelURI    string  `json:"model_uri,omitempty" yaml:"model_uri"`     // KServe storage URI (hf://, pvc://, or s3://)
+	GPUCount    int     `json:"gpu_count,omitempty" yaml:"gpu_count"`     // GPU count for KServe deployment
+	Runtime     string  `json:"runtime,omitempty" yaml:"runtime"`         // KServe serving runtime for deployment
+	MaxRetries  int     `json:"max_retries,omitempty" yaml:"max_retries"` // retry budget for transient failures across the whole run
+
+	// ReasoningEffort sets the thinking budget of reasoning models (low,
+	// medium, or high), and ExtraParams are added to the payload of every
+	// request, e.g. {"chat_template_kwargs": {"enable_thinking": true}}.
+	ReasoningEffort string         `json:"reasoning_effort,omitempty" yaml:"reasoning_effort"`
+	ExtraParams     map[string]any `json:"extra_params,omitempty" yaml:"extra_params"`
+
+	// Resources for the KServe predictor as Kubernetes quantities.
+	CPURequest    string `json:"cpu,omitempty" yaml:"cpu"`
+	CPULimit      string `json:"cpu_limit,omitempty" yaml:"cpu_limit"`
+	MemoryRequest string `json:"memory,omitempty" yaml:"memory"`
+	MemoryLimit   string `json:"memory_limit,omitempty" yaml:"memory_limit"`
+	ShmSize       string `json:"shm_size,omitempty" yaml:"shm_size"`
+
+	// Credentials for the KServe predictor: a Secret holding a HuggingFace
+	// token for gated models, the pod's service account, and image pull Secrets.
+	HFTokenSecret      string   `json:"hf_token_secret,omitempty" yaml:"hf_token_secret"`
+	HFTokenSecretKey   string   `json:"hf_token_secret_key,omitempty" yaml:"hf_token_secret_key"`
+	ServiceAccountName string   `json:"service_account,omitempty" yaml:"service_account"`
+	ImagePullSecrets   []string `json:"image_pull_secrets,omitempty" yaml:"image_pull_secrets"`
+	StorageKey         string   `json:"storage_key,omitempty" yaml:"storage_key"` // storage-config Secret entry for s3:// URIs
+
+	// Scheduling constraints for the KServe predictor pod, e.g. to pin large
+	// models to a GPU type.
+	NodeSelector     map[string]string   `json:"node_selector,omitempty" yaml:"node_selector"`
+	Tolerations      []corev1.Toleration `json:"tolerations,omitempty" yaml:"tolerations"`
+	Affinity         *corev1.Affinity    `json:"affinity,omitempty" yaml:"affinity"`
+	RuntimeClassName string              `json:"runtime_class_name,omitempty" yaml:"runtime_class_name"`
+
+	// RuntimeArgs are additional arguments for the serving runtime, e.g.
+	// "--quantization=awq".
+	RuntimeArgs []string `json:"runtime_args,omitempty" yaml:"runtime_args"`
+
+	// Provider names the provider the model is reached through: openai
+	// (default), a provider type (anthropic, gemini, vertexai, azure), or an entry
+	// of the provider registry with its own endpoint and credentials. Models
+	// of a provider other than openai are never deployed.
+	Provider string `json:"provider,omitempty" yaml:"provider"`
+
+	// Backend is the model server to deploy with: vllm (default), or ollama
+	// or llamacpp for GGUF models on CPU-only clusters.
+	Backend string `json:"backend,omitempty" yaml:"backend"`
+
+	// GGUFFile is the GGUF file of the hf:// repository that ollama or
+	// llamacpp serves, e.g. "model-Q4_K_M.gguf" (default: the best
+	// quantization of the repository).
+	GGUFFile string `json:"gguf_file,omitempty" yaml:"gguf_file"`
+
+	// Quantization is a vLLM quantization preset: awq, gptq, fp8, or
+	// bitsandbytes.
+	Quantization string `json:"quantization,omitempty" yaml:"quantization"`
+
+	// ArgsPreset is a curated set of vLLM runtime args: long-context,
+	// throughput, or low-latency.
+	ArgsPreset string `json:"args_preset,omitempty" yaml:"args_preset"`
+
+	// Env sets environment variables on the model container, e.g.
+	// VLLM_ATTENTION_BACKEND; raw_deployment deploys in KServe's
+	// RawDeployment mode instead of as a Knative service.
+	Env           map[string]string `json:"env,omitempty" yaml:"env"`
+	RawDeployment bool              `json:"raw_deployment,omitempty" yaml:"raw_deployment"`
+
+	// Parallelism for models that need several GPUs or nodes: GPUs per node,
+	// and nodes given either directly or as workers besides the head node.
+	TensorParallelSize   int `json:"tensor_parallel_size,omitempty" yaml:"tensor_parallel_size"`
+	PipelineParallelSize int `json:"pipeline_parallel_size,omitempty" yaml:"pipeline_parallel_size"`
+	WorkerCount          int `json:"worker_count,omitempty" yaml:"worker_count"`
+
+	// Autoscaling of the KServe predictor; a min_replicas of 0 lets an idle
+	// deployment scale to zero.
+	MinReplicas *int   `json:"min_replicas,omitempty" yaml:"min_replicas"`
+	MaxReplicas int    `json:"max_replicas,omitempty" yaml:"max_replicas"`
+	ScaleTarget int    `json:"scale_target,omitempty" yaml:"scale_target"`
+	ScaleMetric string `json:"scale_metric,omitempty" yaml:"scale_metric"`
+
+	// Namespace overrides the namespace the model is deployed in.
+	Namespace string `json:"namespace,omitempty" yaml:"namespace"`
+
+	// Recreate deletes an existing InferenceService of the same name before
+	// deploying, instead of updating it in place.
+	Recreate bool `json:"recreate,omitempty" yaml:"recreate"`
+
+	// ProbeCompletion requests a one-token completion from the deployed
+	// model before it is tested, in addition to listing its models.
+	ProbeCompletion bool `json:"probe_completion,omitempty" yaml:"probe_completion"`
+}
+
+// UnmarshalYAML decodes a model through its JSON representation, so that the
+// Kubernetes types (tolerations, affinity) use their usual camelCase keys.
+func (m *Model) UnmarshalYAML(value *yaml.Node) error {
+	var raw map[string]interface{}
+	if err := value.Decode(&raw); err != nil {
+		return err
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return fmt.Errorf("failed to convert model: %w", err)
+	}
+	type plain Model
+	if err := json.Unmarshal(data, (*plain)(m)); err != nil {
+		return fmt.Errorf("invalid model: %w", err)
+	}
+	return nil
+}
+
+// Weights assigns rubric points to questions for weighted scoring.
+// A question's weight is looked up by ID, then by section; unlisted questions are worth 1 point.
+type Weights struct {
+	Sections  map[string]float64 `yaml:"sections" json:"sections,omitempty"`
+	Questions map[string]float64 `yaml:"questions" json:"questions,omitempty"`
+}
+
+// IsZero reports whether no weights are configured.
+func (w Weights) IsZero() bool {
+	return len(w.Sections) == 0 && len(w.Questions) == 0
+}
+
+// For returns the weight of the question with the given ID and section.
+func (w Weights) For(id, section string) float64 {
+	if v, ok := w.Questions[id]; ok {
+		return v
+	}
+	if v, ok := w.Sections[section]; ok {
+		return v
+	}
+	return 1
+}
+
+// Validate returns an error if any weight is negative, NaN, or infinite.
+func (w Weights) Validate() error {
+	for id, v := range w.Questions {
+		if msg := weightProblem(v); msg != "" {
+			return fmt.Errorf("weight for question %q %s", id, msg)
+		}
+	}
+	for section, v := range w.Sections {
+		if msg := weightProblem(v); msg != "" {
+			return fmt.Errorf("weight for section %q %s", section, msg)
+		}
+	}
+	return nil
+}
+
+// weightProblem describes what is wrong with a weight, or returns "" if
+// it is valid. NaN and infinite weights would make scores meaningless and
+// cannot be written to JSON results.
+func weightProblem(w float64) string {
+	switch {
+	case math.IsNaN(w) || math.IsInf(w, 0):
+		return "must be a finite number"
+	case w < 0:
+		return "must not be negative"
+	default:
+		return ""
+	}
 }
 
 // Prompt defines system prompt configuration for a test suite.
@@ -29,12 +295,115 @@ type Prompt struct {
 	SystemMessage string `yaml:"system_message"`
 }
 
+// Columns maps the fields of a question to the column names of the
+// questions CSV, so that question banks can be used without renaming their
+// headers. Empty fields keep the default names.
+type Columns struct {
+	ID             string `yaml:"id"`
+	Section        string `yaml:"section"`
+	Question       string `yaml:"question"`
+	ExpectedAnswer string `yaml:"expected_answer"`
+	Weight         string `yaml:"weight"`
+	Difficulty     string `yaml:"difficulty"`
+	Tags           string `yaml:"tags"`
+}
+
+// withDefaults returns the columns with the default names filled in.
+func (c Columns) withDefaults() Columns {
+	for _, f := range []struct {
+		name *string
+		def  string
+	}{
+		{&c.ID, "ID"},
+		{&c.Section, "Section"},
+		{&c.Question, "Question"},
+		{&c.ExpectedAnswer, "ExpectedAnswer"},
+		{&c.Weight, "Weight"},
+		{&c.Difficulty, "Difficulty"},
+		{&c.Tags, "Tags"},
+	} {
+		if *f.name == "" {
+			*f.name = f.def
+		}
+	}
+	return c
+}
+
+// required returns the names of the columns every questions CSV needs.
+func (c Columns) required() []string {
+	return []string{c.ID, c.Section, c.Question, c.ExpectedAnswer}
+}
+
+// Example is a few-shot question with the answer the model is shown.
+type Example struct {
+	Question string `yaml:"question" json:"question"`
+	Answer   string `yaml:"answer" json:"answer"`
+}
+
 // Question represents a single test question.
 type Question struct {
 	ID             string
 	Section        string
 	QuestionText   string
 	ExpectedAnswer string
+
+	// Difficulty (e.g. easy, medium, hard) and Tags are optional metadata
+	// for breaking down scores and selecting questions.
+	Difficulty string
+	Tags       []string
+}
+
+// HasTag reports whether the question has any of tags, ignoring case.
+func (q Question) HasTag(tags ...string) bool {
+	for _, t := range q.Tags {
+		for _, want := range tags {
+			if strings.EqualFold(t, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FilterByTags returns the questions having any of tags, or all questions
+// if tags is empty.
+func FilterByTags(questions []Question, tags []string) []Question {
+	if len(tags) == 0 {
+		return questions
+	}
+	var filtered []Question
+	for _, q := range questions {
+		if q.HasTag(tags...) {
+			filtered = append(filtered, q)
+		}
+	}
+	return filtered
+}
+
+// SelectTags narrows the suite to the questions having any of tags. It is a
+// no-op if tags is empty, and an error if no question has any of them.
+func (s *TestSuite) SelectTags(tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	selected := FilterByTags(s.Questions, tags)
+	if len(selected) == 0 {
+		return fmt.Errorf("no questions tagged %s", strings.Join(tags, ", "))
+	}
+	s.Questions = selected
+	return nil
+}
+
+// ParseTags splits a comma- or semicolon-separated list of tags, dropping
+// empty ones.
+func ParseTags(s string) []string {
+	var tags []string
+	for _, t := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ';' }) {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
 }
 
 // Result represents the result of running a single question against a model.
@@ -42,6 +411,32 @@ type Result struct {
 	Question Question
 	Answer   string
 	Duration time.Duration
+
+	// Verdict is set when the answer was judged immediately after being produced.
+	Verdict *bool
+
+	// Token usage and finish reason of the answer as reported by the API.
+	PromptTokens     int
+	CompletionTokens int
+	FinishReason     string
+}
+
+// TokenUsage is the token usage of a model's answers in a test run.
+type TokenUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+
+	// Truncated counts answers cut off at the token limit.
+	Truncated int `json:"truncated,omitempty"`
+}
+
+// LiveScore is the running accuracy of a model whose answers are judged as they arrive.
+type LiveScore struct {
+	Judged  int     `json:"judged"`
+	Correct int     `json:"correct"`
+	Failed  int     `json:"failed,omitempty"` // answers the judge could not evaluate
+	Percent float64 `json:"percentage"`
 }
 
 // TestRun represents metadata and results for a complete test execution.
@@ -51,12 +446,47 @@ type TestRun struct {
 	Timestamp time.Time     `json:"timestamp"`
 	Duration  time.Duration `json:"duration"`
 	Models    []ModelRun    `json:"models"`
+
+	// Weights are the suite's rubric weights, recorded so that scoring can apply them.
+	Weights *Weights `json:"weights,omitempty"`
+
+	// SuiteVersion and SuiteHash identify the suite content the run was
+	// executed against (see TestSuite.ContentHash).
+	SuiteVersion string `json:"suite_version,omitempty"`
+	SuiteHash    string `json:"suite_hash,omitempty"`
+
+	// Profile is the prompt profile the suite was run with, if any.
+	Profile string `json:"profile,omitempty"`
+
+	// Examples is the number of few-shot examples sent before each question.
+	Examples int `json:"examples,omitempty"`
+
+	// Blind is set if the expected answers were written to an answer key
+	// instead of the results files.
+	Blind bool `json:"blind,omitempty"`
+
+	// Cancelled is set if the run was cancelled before all models answered
+	// all questions; the results are those written until then.
+	Cancelled bool `json:"cancelled,omitempty"`
 }
 
 // ModelRun holds results for a single model within a test run.
 type ModelRun struct {
 	ModelName   string        `json:"model_name"`
+	ModelURI    string        `json:"model_uri,omitempty"`
 	Duration    time.Duration `json:"duration"`
 	ResultsFile string        `json:"results_file"`
 	Results     []*Result     `json:"-"`
+
+	// Errors counts questions that failed, keyed by llm.ErrorClass.
+	Errors map[string]int `json:"errors,omitempty"`
+	// Retries is the number of retry attempts spent from the model's budget.
+	Retries int `json:"retries,omitempty"`
+	// LiveScore is set when answers were judged during the run.
+	LiveScore *LiveScore `json:"live_score,omitempty"`
+	// Usage is the token usage of the answers, if the API reported it.
+	Usage *TokenUsage `json:"usage,omitempty"`
+	// DeploymentFile records how the model was served, if it was deployed
+	// for the run.
+	DeploymentFile string `json:"deployment_file,omitempty"`
 }
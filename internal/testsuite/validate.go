@@ -0,0 +1,456 @@
+package testsuite
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Severities of a ValidationIssue.
+const (
+	SeverityError   = "error"   // the suite cannot be loaded or run as intended
+	SeverityWarning = "warning" // the suite loads, but probably not as intended
+)
+
+// longPromptTokens is the estimated prompt size above which a question is
+// reported, since small context windows of self-hosted models truncate it.
+const longPromptTokens = 4096
+
+// ValidationIssue is a problem of a test suite file; Line is 1-based, or 0
+// if the problem concerns the whole file.
+type ValidationIssue struct {
+	File     string `json:"file"`
+	Line     int    `json:"line,omitempty"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+func (i ValidationIssue) String() string {
+	if i.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s: %s", i.File, i.Line, i.Severity, i.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", i.File, i.Severity, i.Message)
+}
+
+// PromptEstimate estimates the prompt sizes of a suite's questions, with
+// the system message, at about four characters per token.
+type PromptEstimate struct {
+	MeanTokens    int    `json:"mean_tokens"`
+	MaxTokens     int    `json:"max_tokens"`
+	MaxQuestionID string `json:"max_question_id,omitempty"`
+}
+
+// ValidationReport is the outcome of Validate.
+type ValidationReport struct {
+	Valid     bool              `json:"valid"` // no issue is an error
+	Name      string            `json:"name,omitempty"`
+	Strategy  string            `json:"strategy,omitempty"`
+	Questions int               `json:"questions"`
+	Prompts   PromptEstimate    `json:"prompt_tokens"`
+	Issues    []ValidationIssue `json:"issues"`
+
+	// Lint is set when the expected answers were linted by a judge model.
+	Lint *LintSummary `json:"lint,omitempty"`
+
+	// questions are the questions read, with the file and line they start on.
+	questions     []validatedQuestion
+	questionsFile string
+}
+
+// LintSummary counts the outcome of linting the expected answers of a suite
+// for ambiguity; the flagged answers are reported as warnings.
+type LintSummary struct {
+	Model   string `json:"model"`
+	Checked int    `json:"checked"`
+	Flagged int    `json:"flagged"`
+	Failed  int    `json:"failed,omitempty"` // answers the judge could not lint
+}
+
+// ValidatedQuestions returns the questions read from the suite's questions file.
+func (r *ValidationReport) ValidatedQuestions() []Question {
+	questions := make([]Question, 0, len(r.questions))
+	for _, q := range r.questions {
+		questions = append(questions, q.Question)
+	}
+	return questions
+}
+
+// AddForQuestion records an issue of the question with the given ID, at the
+// line of the questions file it starts on.
+func (r *ValidationReport) AddForQuestion(id, severity, format string, args ...any) {
+	for _, q := range r.questions {
+		if q.ID == id {
+			r.Add(r.questionsFile, q.line, severity, format, args...)
+			return
+		}
+	}
+	r.Add(r.questionsFile, 0, severity, "question %s: "+format, append([]any{id}, args...)...)
+}
+
+// Add records an issue.
+func (r *ValidationReport) Add(file string, line int, severity, format string, args ...any) {
+	r.Issues = append(r.Issues, ValidationIssue{File: file, Line: line, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	if severity == SeverityError {
+		r.Valid = false
+	}
+}
+
+// Errors returns the number of issues that are errors.
+func (r *ValidationReport) Errors() int {
+	n := 0
+	for _, i := range r.Issues {
+		if i.Severity == SeverityError {
+			n++
+		}
+	}
+	return n
+}
+
+// Validate checks the test suite in fsys more thoroughly than Load, and
+// reports all problems found instead of the first: the schema of
+// config.yaml, the questions file and its integrity (duplicate IDs, empty
+// questions and answers, encoding issues), weights of unknown questions,
+// and the estimated prompt lengths. The strategy is not checked against
+// the supported ones, and suites extended or included are not loaded.
+func Validate(fsys fs.FS) *ValidationReport {
+	r := &ValidationReport{Valid: true, Issues: []ValidationIssue{}}
+
+	data, err := fs.ReadFile(fsys, "config.yaml")
+	if err != nil {
+		r.Add("config.yaml", 0, SeverityError, "cannot read: %v", err)
+		return r
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		r.Add("config.yaml", yamlErrorLine(err.Error()), SeverityError, "%s", yamlErrorMessage(err.Error()))
+		return r
+	}
+
+	var suite TestSuite
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&suite); err != nil && !errors.Is(err, io.EOF) {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			r.Add("config.yaml", yamlErrorLine(err.Error()), SeverityError, "%s", yamlErrorMessage(err.Error()))
+			return r
+		}
+		for _, msg := range typeErr.Errors {
+			r.Add("config.yaml", yamlErrorLine(msg), SeverityError, "%s", yamlErrorMessage(msg))
+		}
+	}
+
+	// Inherited fields, questions, and weights are checked when the suites
+	// extended or included are validated.
+	r.Name = suite.Name
+	if strings.TrimSpace(suite.Name) == "" && suite.Extends == "" {
+		r.Add("config.yaml", 0, SeverityWarning, "name is empty")
+	}
+	r.Strategy = suite.Strategy
+	if r.Strategy == "" {
+		r.Strategy = "qa"
+	}
+	if strings.TrimSpace(suite.Prompt.SystemMessage) == "" && suite.Extends == "" {
+		r.Add("config.yaml", keyLine(&root, "prompt"), SeverityWarning, "prompt.system_message is empty, so questions are asked without instructions")
+	}
+	for _, name := range suite.ProfileNames() {
+		if strings.TrimSpace(suite.Profiles[name].SystemMessage) == "" {
+			r.Add("config.yaml", keyLine(&root, "profiles", name), SeverityError, "profile %q needs a system_message; its role is not sent to the model", name)
+		}
+	}
+	for i, ex := range suite.Examples {
+		if strings.TrimSpace(ex.Question) == "" || strings.TrimSpace(ex.Answer) == "" {
+			r.Add("config.yaml", keyLine(&root, "examples", strconv.Itoa(i)), SeverityError, "example %d needs a question and an answer", i+1)
+		}
+	}
+	for id, w := range suite.Weights.Questions {
+		if msg := weightProblem(w); msg != "" {
+			r.Add("config.yaml", keyLine(&root, "weights", "questions", id), SeverityError, "weight for question %q %s", id, msg)
+		}
+	}
+	for section, w := range suite.Weights.Sections {
+		if msg := weightProblem(w); msg != "" {
+			r.Add("config.yaml", keyLine(&root, "weights", "sections", section), SeverityError, "weight for section %q %s", section, msg)
+		}
+	}
+
+	file := suite.QuestionsFile
+	if file == "" {
+		file = "questions.csv"
+	}
+	questionsData, err := fs.ReadFile(fsys, file)
+	if suite.QuestionsFile == "" && suite.composed() && errors.Is(err, fs.ErrNotExist) {
+		return r
+	}
+	if err != nil {
+		r.Add("config.yaml", keyLine(&root, "questions_file"), SeverityError, "cannot read questions file %s: %v", file, err)
+		return r
+	}
+	questions := validateQuestions(r, file, questionsData, suite.Columns.withDefaults())
+	r.Questions = len(questions)
+	r.questions, r.questionsFile = questions, file
+
+	ids := make(map[string]bool, len(questions))
+	sections := make(map[string]bool)
+	examples := make(map[string]int, len(suite.Examples))
+	for i, ex := range suite.Examples {
+		examples[normalizeQuestion(ex.Question)] = i
+	}
+	for _, q := range questions {
+		ids[q.ID] = true
+		sections[q.Section] = true
+		if i, ok := examples[normalizeQuestion(q.QuestionText)]; ok {
+			r.Add(file, q.line, SeverityWarning, "question is also example %d, which gives its answer away", i+1)
+		}
+	}
+	for id := range suite.Weights.Questions {
+		if !ids[id] && !suite.composed() {
+			r.Add("config.yaml", keyLine(&root, "weights", "questions", id), SeverityWarning, "weight for unknown question %q", id)
+		}
+	}
+	for section := range suite.Weights.Sections {
+		if !sections[section] && !suite.composed() {
+			r.Add("config.yaml", keyLine(&root, "weights", "sections", section), SeverityWarning, "weight for unknown section %q", section)
+		}
+	}
+
+	prefix := suite.Prompt.SystemMessage
+	for _, ex := range suite.Examples {
+		prefix += "\n" + ex.Question + "\n" + ex.Answer
+	}
+	estimatePrompts(r, prefix, questions, file)
+	return r
+}
+
+// normalizeQuestion returns the question for comparison, ignoring case and
+// surrounding whitespace.
+func normalizeQuestion(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// validatedQuestion is a question with the line it starts on.
+type validatedQuestion struct {
+	Question
+	line int
+}
+
+// validateQuestions checks the questions CSV and returns its questions.
+func validateQuestions(r *ValidationReport, file string, data []byte, columns Columns) []validatedQuestion {
+	if bytes.HasPrefix(data, []byte("\xef\xbb\xbf")) {
+		r.Add(file, 1, SeverityError, "file starts with a UTF-8 byte order mark, which hides the first column name")
+		data = data[3:]
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		r.Add(file, 1, SeverityError, "cannot read header: %v", err)
+		return nil
+	}
+	colIndex := make(map[string]int)
+	minCols := 0
+	for i, col := range header {
+		colIndex[strings.TrimSpace(col)] = i
+		minCols = i + 1
+	}
+	missing := false
+	for _, required := range columns.required() {
+		if _, ok := colIndex[required]; !ok {
+			r.Add(file, 1, SeverityError, "missing required column %s", required)
+			missing = true
+		}
+	}
+	if missing {
+		return nil
+	}
+	weightCol, hasWeights := colIndex[columns.Weight]
+
+	var questions []validatedQuestion
+	firstLine := make(map[string]int)
+	rows := 0
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			r.Add(file, parseErr.Line, SeverityError, "%v", parseErr.Err)
+			continue
+		}
+		if err != nil {
+			r.Add(file, 0, SeverityError, "%v", err)
+			break
+		}
+		rows++
+		line, _ := reader.FieldPos(0)
+		if len(record) < minCols {
+			r.Add(file, line, SeverityError, "row has %d columns, expected %d", len(record), minCols)
+			continue
+		}
+
+		q := validatedQuestion{
+			Question: Question{
+				ID:             record[colIndex[columns.ID]],
+				Section:        record[colIndex[columns.Section]],
+				QuestionText:   record[colIndex[columns.Question]],
+				ExpectedAnswer: record[colIndex[columns.ExpectedAnswer]],
+			},
+			line: line,
+		}
+		switch id := strings.TrimSpace(q.ID); {
+		case id == "":
+			r.Add(file, line, SeverityError, "empty ID")
+		case firstLine[id] > 0:
+			r.Add(file, line, SeverityError, "duplicate ID %q (first on line %d)", id, firstLine[id])
+		default:
+			firstLine[id] = line
+			if id != q.ID {
+				r.Add(file, line, SeverityWarning, "ID %q has surrounding whitespace", q.ID)
+			}
+		}
+		if strings.TrimSpace(q.QuestionText) == "" {
+			r.Add(file, line, SeverityError, "empty question")
+		}
+		if strings.TrimSpace(q.ExpectedAnswer) == "" {
+			r.Add(file, line, SeverityError, "empty expected answer")
+		}
+		if hasWeights {
+			if raw := strings.TrimSpace(record[weightCol]); raw != "" {
+				if w, err := strconv.ParseFloat(raw, 64); err != nil {
+					r.Add(file, line, SeverityError, "invalid weight %q", raw)
+				} else if msg := weightProblem(w); msg != "" {
+					r.Add(file, line, SeverityError, "weight %s", msg)
+				}
+			}
+		}
+		for _, field := range record {
+			if msg := encodingIssue(field); msg != "" {
+				r.Add(file, line, SeverityWarning, "%s", msg)
+				break
+			}
+		}
+		questions = append(questions, q)
+	}
+	if rows == 0 {
+		r.Add(file, 0, SeverityError, "no questions")
+	}
+	return questions
+}
+
+// encodingIssue describes a sign of a broken encoding in s, or returns "".
+func encodingIssue(s string) string {
+	if !utf8.ValidString(s) {
+		return "invalid UTF-8; save the file as UTF-8"
+	}
+	if strings.ContainsRune(s, utf8.RuneError) {
+		return "replacement character (U+FFFD) from an earlier encoding conversion"
+	}
+	if strings.Contains(s, "Ã") || strings.Contains(s, "â€") {
+		return "likely mojibake (UTF-8 read as Latin-1), e.g. \"Ã\" or \"â€\""
+	}
+	for _, c := range s {
+		if unicode.IsControl(c) && c != '\n' && c != '\r' && c != '\t' {
+			return fmt.Sprintf("control character %U", c)
+		}
+	}
+	return ""
+}
+
+// estimatePrompts estimates the prompt sizes of the questions, each preceded
+// by prefix (the system message and examples), and reports long ones.
+func estimatePrompts(r *ValidationReport, prefix string, questions []validatedQuestion, file string) {
+	if len(questions) == 0 {
+		return
+	}
+	total := 0
+	for _, q := range questions {
+		tokens := estimateTokens(prefix) + estimateTokens(q.QuestionText)
+		total += tokens
+		if tokens > r.Prompts.MaxTokens {
+			r.Prompts.MaxTokens = tokens
+			r.Prompts.MaxQuestionID = q.ID
+		}
+		if tokens > longPromptTokens {
+			r.Add(file, q.line, SeverityWarning, "prompt of about %d tokens may exceed the context window of small models", tokens)
+		}
+	}
+	r.Prompts.MeanTokens = total / len(questions)
+}
+
+// estimateTokens estimates the tokens of s at four characters per token.
+func estimateTokens(s string) int {
+	return (utf8.RuneCountInString(s) + 3) / 4
+}
+
+var yamlLinePattern = regexp.MustCompile(`line (\d+): `)
+
+// yamlErrorLine returns the line number in a yaml.v3 error message, or 0.
+func yamlErrorLine(msg string) int {
+	m := yamlLinePattern.FindStringSubmatch(msg)
+	if m == nil {
+		return 0
+	}
+	line, _ := strconv.Atoi(m[1])
+	return line
+}
+
+// yamlErrorMessage strips the prefix and line number of a yaml.v3 error.
+func yamlErrorMessage(msg string) string {
+	msg = strings.TrimPrefix(msg, "yaml: ")
+	if loc := yamlLinePattern.FindStringIndex(msg); loc != nil && loc[0] == 0 {
+		msg = msg[loc[1]:]
+	}
+	return msg
+}
+
+// keyLine returns the line of the key at path in a YAML document, or of
+// its closest existing parent, or 0. Items of sequences are addressed by
+// their index.
+func keyLine(doc *yaml.Node, path ...string) int {
+	node := doc
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	line := 0
+	for _, key := range path {
+		if node.Kind == yaml.SequenceNode {
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node.Content) {
+				break
+			}
+			node = node.Content[i]
+			line = node.Line
+			continue
+		}
+		if node.Kind != yaml.MappingNode {
+			break
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				line = node.Content[i].Line
+				next = node.Content[i+1]
+				break
+			}
+		}
+		if next == nil {
+			break
+		}
+		node = next
+	}
+	return line
+}
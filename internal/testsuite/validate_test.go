@@ -0,0 +1,175 @@
+package testsuite
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateEmbeddedSuite(t *testing.T) {
+	fsys, err := Open("kubernetes-cka-v2", "")
+	require.NoError(t, err)
+
+	report := Validate(fsys)
+	assert.True(t, report.Valid, "%v", report.Issues)
+	assert.Empty(t, report.Issues)
+	assert.Equal(t, "Kubernetes CKA", report.Name)
+	assert.Equal(t, "qa", report.Strategy)
+	assert.Equal(t, 100, report.Questions)
+	assert.Positive(t, report.Prompts.MeanTokens)
+	assert.GreaterOrEqual(t, report.Prompts.MaxTokens, report.Prompts.MeanTokens)
+}
+
+func TestValidateBundledSuites(t *testing.T) {
+	for _, name := range []string{"kubernetes-cks", "kubernetes-troubleshooting", "helm-gitops"} {
+		fsys, err := Open(name, "")
+		require.NoError(t, err)
+		report := Validate(fsys)
+		assert.Empty(t, report.Issues, name)
+		assert.Positive(t, report.Questions, name)
+	}
+}
+
+func TestValidateQuestions(t *testing.T) {
+	fsys := fstest.MapFS{
+		"config.yaml": {Data: []byte(`name: Broken
+prompt:
+  system_message: Answer briefly.
+weights:
+  questions:
+    "9": 2
+`)},
+		"questions.csv": {Data: []byte("ID,Section,Question,ExpectedAnswer\n" +
+			"1,Pods,What is a Pod?,A group of containers\n" +
+			"1,Pods,What is a Node?,A machine\n" +
+			"2,Pods,\"A question\nover two lines\",\n" +
+			",Pods,What is etcd?,A key-value store\n" +
+			"3,Pods,What is kube-proxy?\n" +
+			"4,Pods,Wie heiÃŸt das?,So\n" +
+			"5,Pods," + strings.Repeat("long ", 4000) + ",yes\n")},
+	}
+
+	report := Validate(fsys)
+	assert.False(t, report.Valid)
+	assert.Equal(t, 6, report.Questions)
+	assert.Equal(t, "5", report.Prompts.MaxQuestionID)
+	assert.Equal(t, []ValidationIssue{
+		{File: "questions.csv", Line: 3, Severity: SeverityError, Message: `duplicate ID "1" (first on line 2)`},
+		{File: "questions.csv", Line: 4, Severity: SeverityError, Message: "empty expected answer"},
+		{File: "questions.csv", Line: 6, Severity: SeverityError, Message: "empty ID"},
+		{File: "questions.csv", Line: 7, Severity: SeverityError, Message: "row has 3 columns, expected 4"},
+		{File: "questions.csv", Line: 8, Severity: SeverityWarning, Message: `likely mojibake (UTF-8 read as Latin-1), e.g. "Ã" or "â€"`},
+		{File: "config.yaml", Line: 6, Severity: SeverityWarning, Message: `weight for unknown question "9"`},
+		{File: "questions.csv", Line: 9, Severity: SeverityWarning, Message: "prompt of about 5004 tokens may exceed the context window of small models"},
+	}, report.Issues)
+}
+
+func TestValidateConfig(t *testing.T) {
+	report := Validate(fstest.MapFS{
+		"config.yaml": {Data: []byte("name: Typo\nquestion_file: qs.csv\nweights:\n  sections:\n    Pods: -1\n")},
+	})
+	assert.False(t, report.Valid)
+	assert.Equal(t, []ValidationIssue{
+		{File: "config.yaml", Line: 2, Severity: SeverityError, Message: "field question_file not found in type testsuite.TestSuite"},
+		{File: "config.yaml", Severity: SeverityWarning, Message: "prompt.system_message is empty, so questions are asked without instructions"},
+		{File: "config.yaml", Line: 5, Severity: SeverityError, Message: `weight for section "Pods" must not be negative`},
+		{File: "config.yaml", Severity: SeverityError, Message: "cannot read questions file questions.csv: open questions.csv: file does not exist"},
+	}, report.Issues)
+
+	report = Validate(fstest.MapFS{"config.yaml": {Data: []byte("name: [unclosed\n")}})
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, SeverityError, report.Issues[0].Severity)
+	assert.Equal(t, 1, report.Issues[0].Line)
+
+	report = Validate(fstest.MapFS{})
+	assert.Contains(t, report.Issues[0].Message, "cannot read")
+}
+
+func TestValidateProfiles(t *testing.T) {
+	report := Validate(fstest.MapFS{
+		"config.yaml":   {Data: []byte("name: P\nprompt:\n  system_message: Answer.\nprofiles:\n  expert:\n    role: expert\n")},
+		"questions.csv": {Data: []byte("ID,Section,Question,ExpectedAnswer\n1,S,Q?,A\n")},
+	})
+	assert.False(t, report.Valid)
+	assert.Equal(t, []ValidationIssue{
+		{File: "config.yaml", Line: 5, Severity: SeverityError, Message: `profile "expert" needs a system_message; its role is not sent to the model`},
+	}, report.Issues)
+}
+
+func TestValidateNonFiniteWeights(t *testing.T) {
+	report := Validate(fstest.MapFS{
+		"config.yaml":   {Data: []byte("name: W\nprompt:\n  system_message: Answer.\nweights:\n  questions:\n    \"1\": .nan\n")},
+		"questions.csv": {Data: []byte("ID,Section,Question,ExpectedAnswer,Weight\n1,S,Q?,A,\n2,S,Q?,A,Inf\n")},
+	})
+	assert.False(t, report.Valid)
+	assert.Equal(t, []ValidationIssue{
+		{File: "config.yaml", Line: 6, Severity: SeverityError, Message: `weight for question "1" must be a finite number`},
+		{File: "questions.csv", Line: 3, Severity: SeverityError, Message: "weight must be a finite number"},
+	}, report.Issues)
+}
+
+func TestValidateQuestionsHeader(t *testing.T) {
+	report := Validate(fstest.MapFS{
+		"config.yaml":   {Data: []byte("name: BOM\nprompt:\n  system_message: hi\n")},
+		"questions.csv": {Data: []byte("\xef\xbb\xbfID,Section,Question\n1,a,b\n")},
+	})
+	assert.Equal(t, []ValidationIssue{
+		{File: "questions.csv", Line: 1, Severity: SeverityError, Message: "file starts with a UTF-8 byte order mark, which hides the first column name"},
+		{File: "questions.csv", Line: 1, Severity: SeverityError, Message: "missing required column ExpectedAnswer"},
+	}, report.Issues)
+}
+
+func TestValidateColumnMapping(t *testing.T) {
+	report := Validate(fstest.MapFS{
+		"config.yaml":   {Data: []byte("name: Bank\nprompt:\n  system_message: hi\ncolumns:\n  id: No.\n  question: Task\n")},
+		"questions.csv": {Data: []byte("No.,Section,Task,ExpectedAnswer\n1,S,Q?,A\n1,S,Q2?,A\n")},
+	})
+	assert.Equal(t, 2, report.Questions)
+	assert.Equal(t, []ValidationIssue{
+		{File: "questions.csv", Line: 3, Severity: SeverityError, Message: `duplicate ID "1" (first on line 2)`},
+	}, report.Issues)
+
+	report = Validate(fstest.MapFS{
+		"config.yaml":   {Data: []byte("name: Bank\nprompt:\n  system_message: hi\ncolumns:\n  question: Task\n")},
+		"questions.csv": {Data: []byte("ID,Section,Question,ExpectedAnswer\n1,S,Q?,A\n")},
+	})
+	assert.Equal(t, []ValidationIssue{
+		{File: "questions.csv", Line: 1, Severity: SeverityError, Message: "missing required column Task"},
+	}, report.Issues)
+}
+
+func TestValidateComposedSuite(t *testing.T) {
+	report := Validate(fstest.MapFS{
+		"config.yaml": {Data: []byte(`extends: kubernetes-cka-v2
+include_questions: [kubernetes-cks]
+weights:
+  questions:
+    "42": 2
+`)},
+	})
+	assert.True(t, report.Valid)
+	assert.Empty(t, report.Issues, "inherited fields, questions, and weights are not checked")
+	assert.Zero(t, report.Questions)
+}
+
+func TestValidateExamples(t *testing.T) {
+	report := Validate(fstest.MapFS{
+		"config.yaml": {Data: []byte(`name: Examples
+prompt:
+  system_message: Answer with a kubectl command.
+examples:
+  - question: List pods?
+    answer: kubectl get pods
+  - question: List nodes?
+`)},
+		"questions.csv": {Data: []byte("ID,Section,Question,ExpectedAnswer\n1,S,list pods?,kubectl get pods\n2,S,List services?,kubectl get svc\n")},
+	})
+	assert.Equal(t, []ValidationIssue{
+		{File: "config.yaml", Line: 7, Severity: SeverityError, Message: "example 2 needs a question and an answer"},
+		{File: "questions.csv", Line: 2, Severity: SeverityWarning, Message: "question is also example 1, which gives its answer away"},
+	}, report.Issues)
+	assert.Greater(t, report.Prompts.MeanTokens, 20, "examples count towards the prompt size")
+}
@@ -0,0 +1,137 @@
+package testsuite
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is how long Watch waits for further changes before
+// reporting them, so that a suite copied file by file is reported once.
+const watchDebounce = 500 * time.Millisecond
+
+// SuitesChange lists the names of suites added, changed, and removed in an
+// external suites directory.
+type SuitesChange struct {
+	Added   []string
+	Changed []string
+	Removed []string
+}
+
+// Watch watches the external suites directory dir until ctx is done, and
+// calls onChange after suites were added, changed, or removed and the
+// changes have settled. Subdirectories created later are watched as well.
+func Watch(ctx context.Context, dir string, onChange func(SuitesChange)) error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create watcher: %w", err)
+	}
+	defer w.Close()
+	if err := watchTree(w, dir); err != nil {
+		return err
+	}
+
+	known, err := List(dir)
+	if err != nil {
+		return err
+	}
+	touched := make(map[string]bool)
+	var settled <-chan time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-w.Events:
+			if !ok {
+				return nil
+			}
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := watchTree(w, event.Name); err != nil {
+						slog.Warn("failed to watch new test suite directory", "path", event.Name, "error", err)
+					}
+				}
+			}
+			if name := suiteOf(dir, event.Name); name != "" {
+				touched[name] = true
+			}
+			settled = time.After(watchDebounce)
+
+		case err, ok := <-w.Errors:
+			if !ok {
+				return nil
+			}
+			slog.Warn("error watching test suites directory", "dir", dir, "error", err)
+
+		case <-settled:
+			settled = nil
+			names, err := List(dir)
+			if err != nil {
+				slog.Warn("failed to list test suites after change", "dir", dir, "error", err)
+				continue
+			}
+			change := diffSuites(known, names, touched)
+			known, touched = names, make(map[string]bool)
+			if len(change.Added)+len(change.Changed)+len(change.Removed) > 0 {
+				onChange(change)
+			}
+		}
+	}
+}
+
+// watchTree adds dir and its subdirectories to the watcher.
+func watchTree(w *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if err := w.Add(path); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", path, err)
+		}
+		return nil
+	})
+}
+
+// suiteOf returns the name of the suite directory in dir that path is in,
+// or "" if path is not inside one.
+func suiteOf(dir, path string) string {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return ""
+	}
+	name, _, _ := strings.Cut(filepath.ToSlash(rel), "/")
+	return name
+}
+
+// diffSuites compares the suite lists before and after a change. Suites in
+// both lists are changed if a file of theirs was touched.
+func diffSuites(before, after []string, touched map[string]bool) SuitesChange {
+	var change SuitesChange
+	for _, name := range after {
+		switch {
+		case !slices.Contains(before, name):
+			change.Added = append(change.Added, name)
+		case touched[name]:
+			change.Changed = append(change.Changed, name)
+		}
+	}
+	for _, name := range before {
+		if !slices.Contains(after, name) {
+			change.Removed = append(change.Removed, name)
+		}
+	}
+	return change
+}
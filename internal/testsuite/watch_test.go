@@ -0,0 +1,55 @@
+package testsuite
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	writeSuite(t, dir, "existing", "name: Existing\n", "ID,Section,Question,ExpectedAnswer\n1,S,Q?,A\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan SuitesChange, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- Watch(ctx, dir, func(c SuitesChange) { changes <- c })
+	}()
+
+	next := func() SuitesChange {
+		t.Helper()
+		select {
+		case c := <-changes:
+			return c
+		case <-time.After(5 * time.Second):
+			t.Fatal("no change reported")
+			return SuitesChange{}
+		}
+	}
+
+	// Give the watcher time to start before changing the directory.
+	time.Sleep(100 * time.Millisecond)
+	writeSuite(t, dir, "added", "name: Added\n", "ID,Section,Question,ExpectedAnswer\n1,S,Q?,A\n")
+	assert.Equal(t, SuitesChange{Added: []string{"added"}}, next())
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "added", "questions.csv"), []byte("ID,Section,Question,ExpectedAnswer\n1,S,Q2?,A\n"), 0o644))
+	assert.Equal(t, SuitesChange{Changed: []string{"added"}}, next(), "files in new suite directories are watched")
+
+	require.NoError(t, os.RemoveAll(filepath.Join(dir, "existing")))
+	assert.Equal(t, SuitesChange{Removed: []string{"existing"}}, next())
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestWatchMissingDirectory(t *testing.T) {
+	err := Watch(context.Background(), filepath.Join(t.TempDir(), "missing"), func(SuitesChange) {})
+	assert.Error(t, err)
+}
@@ -16,26 +16,40 @@ type MockLLMClient struct {
 	// DefaultResponse is returned when no matching key is found in Responses.
 	DefaultResponse string
 
+	// Usage and FinishReason are reported with every successful response.
+	Usage        llm.Usage
+	FinishReason string
+
 	// Calls tracks the number of ChatCompletion invocations.
 	Calls int
 
 	// LastRequest stores the most recent ChatRequest for inspection.
 	LastRequest llm.ChatRequest
+
+	// Errors is a queue of errors returned by successive ChatCompletion calls
+	// before the mock falls back to canned responses.
+	Errors []error
 }
 
 func (m *MockLLMClient) ChatCompletion(_ context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
 	m.Calls++
 	m.LastRequest = req
 
+	if len(m.Errors) > 0 {
+		err := m.Errors[0]
+		m.Errors = m.Errors[1:]
+		return nil, err
+	}
+
 	if resp, ok := m.Responses[req.UserMessage]; ok {
-		return &llm.ChatResponse{Content: resp}, nil
+		return &llm.ChatResponse{Content: resp, Usage: m.Usage, FinishReason: m.FinishReason}, nil
 	}
 
 	if m.DefaultResponse != "" {
-		return &llm.ChatResponse{Content: m.DefaultResponse}, nil
+		return &llm.ChatResponse{Content: m.DefaultResponse, Usage: m.Usage, FinishReason: m.FinishReason}, nil
 	}
 
-	return &llm.ChatResponse{Content: "mock response"}, nil
+	return &llm.ChatResponse{Content: "mock response", Usage: m.Usage, FinishReason: m.FinishReason}, nil
 }
 
 func (m *MockLLMClient) ChatCompletionStream(_ context.Context, _ llm.ChatRequest) (*llm.StreamReader, error) {
@@ -0,0 +1,115 @@
+// Package tracing exports OpenTelemetry traces of test runs, model
+// deployments, LLM requests, and scoring, so that the time of a slow run
+// can be attributed to deployment, inference, or judging.
+//
+// Tracing is configured by the standard OpenTelemetry environment
+// variables. It is enabled when an OTLP endpoint is set
+// (OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT) or
+// OTEL_TRACES_EXPORTER is "otlp", and disabled by OTEL_SDK_DISABLED=true or
+// OTEL_TRACES_EXPORTER=none. Spans are exported via OTLP over HTTP; the
+// exporter, sampler (OTEL_TRACES_SAMPLER), and resource (OTEL_SERVICE_NAME,
+// OTEL_RESOURCE_ATTRIBUTES) read their further settings from the
+// environment.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName names the tracer of this module.
+const instrumentationName = "github.com/giantswarm/llm-testing"
+
+// serviceName is the default service name of exported spans.
+const serviceName = "llm-testing"
+
+// Attributes of the spans of test runs, deployments, and scoring.
+const (
+	RunID      = attribute.Key("llm_testing.run_id")
+	Suite      = attribute.Key("llm_testing.suite")
+	Model      = attribute.Key("llm_testing.model")
+	QuestionID = attribute.Key("llm_testing.question_id")
+	Namespace  = attribute.Key("llm_testing.namespace")
+	File       = attribute.Key("llm_testing.results_file")
+	Repetition = attribute.Key("llm_testing.repetition")
+)
+
+// Enabled reports whether the environment configures trace export.
+func Enabled(getenv func(string) string) bool {
+	if strings.EqualFold(getenv("OTEL_SDK_DISABLED"), "true") {
+		return false
+	}
+	switch strings.ToLower(getenv("OTEL_TRACES_EXPORTER")) {
+	case "none":
+		return false
+	case "otlp":
+		return true
+	}
+	return getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// Setup installs the global tracer provider if the environment enables
+// tracing, and returns a function that flushes and stops it. Without
+// tracing, spans are not recorded and the returned function does nothing.
+func Setup(ctx context.Context, version string) (func(context.Context) error, error) {
+	if !Enabled(os.Getenv) {
+		return func(context.Context) error { return nil }, nil
+	}
+	for _, key := range []string{"OTEL_EXPORTER_OTLP_PROTOCOL", "OTEL_EXPORTER_OTLP_TRACES_PROTOCOL"} {
+		if protocol := os.Getenv(key); protocol != "" && protocol != "http/protobuf" {
+			slog.Warn("only the http/protobuf OTLP protocol is supported; exporting traces with it", "variable", key, "protocol", protocol)
+		}
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+	// Attributes from OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES take
+	// precedence over the defaults.
+	res, err := resource.Merge(
+		resource.NewSchemaless(
+			attribute.String("service.name", serviceName),
+			attribute.String("service.version", version),
+		),
+		resource.Environment(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	slog.Info("exporting traces via OTLP")
+	return provider.Shutdown, nil
+}
+
+// Start starts a span as a child of the span in ctx, if any.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err, if not nil, as the error of span and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
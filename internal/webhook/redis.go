@@ -0,0 +1,37 @@
+package webhook
+
+import (
+	"context"
+	"time"
+
+	"github.com/valkey-io/valkey-go"
+)
+
+// RedisReplayCache is a ReplayCache in Redis or Valkey, shared by the
+// replicas of a server, so that a trigger is accepted by one replica only.
+type RedisReplayCache struct {
+	client valkey.Client
+	prefix string
+}
+
+var _ ReplayCache = (*RedisReplayCache)(nil)
+
+// NewRedisReplayCache returns a replay cache whose keys start with prefix.
+func NewRedisReplayCache(client valkey.Client, prefix string) *RedisReplayCache {
+	return &RedisReplayCache{client: client, prefix: prefix}
+}
+
+// Add records signature for ttl. It reports false if the signature was
+// recorded already.
+func (c *RedisReplayCache) Add(ctx context.Context, signature string, ttl time.Duration) (bool, error) {
+	err := c.client.Do(ctx, c.client.B().Set().Key(c.prefix+signature).Value("seen").Nx().Ex(ttl).Build()).Error()
+	if valkey.IsValkeyNil(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Remove forgets signature.
+func (c *RedisReplayCache) Remove(ctx context.Context, signature string) error {
+	return c.client.Do(ctx, c.client.B().Del().Key(c.prefix+signature).Build()).Error()
+}
@@ -0,0 +1,313 @@
+// Package webhook receives signed HTTP triggers from external systems (CI,
+// model registry promotion events) and starts named run templates.
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/giantswarm/llm-testing/internal/testsuite"
+)
+
+const (
+	// TriggerPath is the HTTP path of the trigger endpoint.
+	TriggerPath = "/hooks/trigger"
+
+	// SignatureHeader carries the hex-encoded HMAC-SHA256 of the timestamp
+	// and the request body, joined by ".", prefixed with "sha256=".
+	SignatureHeader = "X-Signature-256"
+
+	// TimestampHeader carries the time the request was signed at, in Unix
+	// seconds.
+	TimestampHeader = "X-Signature-Timestamp"
+
+	// MaxSignatureAge is how far the timestamp of a trigger may be from the
+	// server's time. Older triggers are refused as stale, and the signatures
+	// of accepted ones are remembered for as long, to refuse replays.
+	MaxSignatureAge = 5 * time.Minute
+
+	maxBodyBytes = 1 << 20
+)
+
+// ErrUnavailable marks failures to start a run that are temporary, e.g.
+// while the server shuts down; the trigger may be sent again later.
+var ErrUnavailable = errors.New("runs cannot be started right now")
+
+// Template is a named, preconfigured test run.
+type Template struct {
+	Name         string            `yaml:"-" json:"name"`
+	Suite        string            `yaml:"test_suite" json:"test_suite"`
+	Models       []testsuite.Model `yaml:"models" json:"models"`
+	Endpoint     string            `yaml:"endpoint" json:"endpoint,omitempty"`
+	Deploy       *bool             `yaml:"deploy" json:"deploy,omitempty"`
+	Judge        bool              `yaml:"judge" json:"judge,omitempty"`
+	ScoringModel string            `yaml:"scoring_model" json:"scoring_model,omitempty"`
+	Profile      string            `yaml:"profile" json:"profile,omitempty"`
+	Examples     *bool             `yaml:"examples" json:"examples,omitempty"`
+	Blind        bool              `yaml:"blind" json:"blind,omitempty"`
+}
+
+type templatesFile struct {
+	Templates map[string]Template `yaml:"templates"`
+}
+
+// LoadTemplates reads run templates from a YAML file of the form:
+//
+//	templates:
+//	  nightly-cka:
+//	    test_suite: kubernetes-cka-v2
+//	    models:
+//	      - name: mistral-7b
+//	        model_uri: hf://mistralai/Mistral-7B-Instruct-v0.3
+func LoadTemplates(path string) (map[string]Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run templates: %w", err)
+	}
+
+	var f templatesFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse run templates: %w", err)
+	}
+
+	for name, t := range f.Templates {
+		if t.Suite == "" {
+			return nil, fmt.Errorf("run template %q has no test_suite", name)
+		}
+		t.Name = name
+		f.Templates[name] = t
+	}
+	return f.Templates, nil
+}
+
+// TriggerRequest is the body of a trigger call.
+type TriggerRequest struct {
+	Template string `json:"template"`
+
+	// Models optionally replaces the template's models, e.g. with a newly
+	// published model version.
+	Models []testsuite.Model `json:"models,omitempty"`
+}
+
+// StartFunc submits a run for the given template, e.g. to the job queue of
+// the server, and returns its ID without waiting for it.
+type StartFunc func(ctx context.Context, t Template) (runID string, err error)
+
+// ReplayCache remembers the signatures of accepted triggers, so that each
+// is accepted only once.
+type ReplayCache interface {
+	// Add records signature for ttl. It reports false if the signature was
+	// recorded already.
+	Add(ctx context.Context, signature string, ttl time.Duration) (bool, error)
+
+	// Remove forgets signature, e.g. of a trigger whose run failed to
+	// start, so that it may be sent again.
+	Remove(ctx context.Context, signature string) error
+}
+
+// Handler serves the trigger endpoint.
+type Handler struct {
+	secret    []byte
+	templates map[string]Template
+	start     StartFunc
+	seen      ReplayCache
+}
+
+// NewHandler creates a trigger handler. It remembers the signatures of
+// accepted triggers in memory; see SetReplayCache.
+func NewHandler(secret string, templates map[string]Template, start StartFunc) *Handler {
+	return &Handler{
+		secret:    []byte(secret),
+		templates: templates,
+		start:     start,
+		seen:      newMemoryReplayCache(),
+	}
+}
+
+// SetReplayCache remembers the signatures of accepted triggers in cache,
+// e.g. one shared by the replicas of the server.
+func (h *Handler) SetReplayCache(cache ReplayCache) {
+	h.seen = cache
+}
+
+// ServeHTTP verifies the request signature and submits a run of the
+// requested template.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodyBytes+1))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "failed to read body"})
+		return
+	}
+	if len(body) > maxBodyBytes {
+		writeJSON(w, http.StatusRequestEntityTooLarge, map[string]string{"error": "body too large"})
+		return
+	}
+
+	signature, timestamp := r.Header.Get(SignatureHeader), r.Header.Get(TimestampHeader)
+	if !VerifySignature(h.secret, timestamp, body, signature) {
+		slog.Warn("rejected webhook trigger with invalid signature", "remote_addr", r.RemoteAddr)
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid signature"})
+		return
+	}
+	if signedAt, _ := strconv.ParseInt(timestamp, 10, 64); time.Since(time.Unix(signedAt, 0)).Abs() > MaxSignatureAge {
+		slog.Warn("rejected stale webhook trigger", "remote_addr", r.RemoteAddr, "timestamp", timestamp)
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "stale signature: the timestamp is more than " + MaxSignatureAge.String() + " off"})
+		return
+	}
+
+	var req TriggerRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid JSON: %v", err)})
+		return
+	}
+
+	t, ok := h.templates[req.Template]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": fmt.Sprintf("unknown run template %q", req.Template)})
+		return
+	}
+	if len(req.Models) > 0 {
+		t.Models = req.Models
+	}
+	if len(t.Models) == 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "run template has no models and none were provided"})
+		return
+	}
+
+	// Stale triggers are refused, so signatures need to be remembered only
+	// while their timestamp is current.
+	key := replayKey(signature)
+	fresh, err := h.seen.Add(r.Context(), key, 2*MaxSignatureAge)
+	if err != nil {
+		slog.Error("failed to record webhook trigger", "error", err)
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "failed to record trigger"})
+		return
+	}
+	if !fresh {
+		slog.Warn("rejected replayed webhook trigger", "remote_addr", r.RemoteAddr)
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "trigger was already accepted"})
+		return
+	}
+
+	triggeredAt := time.Now().UTC()
+	runID, err := h.start(r.Context(), t)
+	if err != nil {
+		slog.Error("failed to start triggered run", "template", t.Name, "error", err)
+		// The trigger was not accepted, so it may be sent again.
+		if err := h.seen.Remove(context.WithoutCancel(r.Context()), key); err != nil {
+			slog.Warn("failed to forget webhook trigger", "error", err)
+		}
+		status := http.StatusUnprocessableEntity
+		if errors.Is(err, ErrUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
+		writeJSON(w, status, map[string]string{"error": fmt.Sprintf("failed to start run: %v", err)})
+		return
+	}
+	slog.Info("submitted triggered run", "template", t.Name, "suite", t.Suite, "models", len(t.Models), "run_id", runID)
+
+	writeJSON(w, http.StatusAccepted, map[string]interface{}{
+		"status":       "accepted",
+		"run_id":       runID,
+		"template":     t.Name,
+		"test_suite":   t.Suite,
+		"models":       t.Models,
+		"triggered_at": triggeredAt.Format(time.RFC3339),
+	})
+}
+
+// Sign returns the signature header value for body signed at timestamp,
+// in Unix seconds.
+func Sign(secret []byte, timestamp string, body []byte) string {
+	return "sha256=" + hex.EncodeToString(signatureMAC(secret, timestamp, body))
+}
+
+// VerifySignature reports whether signature is the valid HMAC-SHA256 of
+// timestamp and body. An empty secret or timestamp never verifies.
+func VerifySignature(secret []byte, timestamp string, body []byte, signature string) bool {
+	if len(secret) == 0 || timestamp == "" {
+		return false
+	}
+	hexSig, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(hexSig)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(got, signatureMAC(secret, timestamp, body))
+}
+
+// replayKey returns the canonical form of a verified signature, so that a
+// replay with the hex digits in another case is recognized.
+func replayKey(signature string) string {
+	return strings.ToLower(strings.TrimPrefix(signature, "sha256="))
+}
+
+func signatureMAC(secret []byte, timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// memoryReplayCache is a ReplayCache of one server replica.
+type memoryReplayCache struct {
+	mu   sync.Mutex
+	seen map[string]time.Time // signature to expiry
+}
+
+func newMemoryReplayCache() *memoryReplayCache {
+	return &memoryReplayCache{seen: map[string]time.Time{}}
+}
+
+func (c *memoryReplayCache) Add(_ context.Context, signature string, ttl time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for s, expiry := range c.seen {
+		if now.After(expiry) {
+			delete(c.seen, s)
+		}
+	}
+	if _, ok := c.seen[signature]; ok {
+		return false, nil
+	}
+	c.seen[signature] = now.Add(ttl)
+	return true, nil
+}
+
+func (c *memoryReplayCache) Remove(_ context.Context, signature string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.seen, signature)
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,229 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/giantswarm/llm-testing/internal/testsuite"
+)
+
+const testSecret = "s3cret"
+
+func newTestHandler(started chan<- Template) *Handler {
+	templates := map[string]Template{
+		"nightly": {
+			Name:   "nightly",
+			Suite:  "kubernetes-cka-v2",
+			Models: []testsuite.Model{{Name: "mistral-7b"}},
+		},
+		"empty": {Name: "empty", Suite: "kubernetes-cka-v2"},
+	}
+	return NewHandler(testSecret, templates, func(_ context.Context, t Template) (string, error) {
+		started <- t
+		return "run-1", nil
+	})
+}
+
+// signed returns the timestamp and signature headers of body signed now.
+func signed(body string) (string, string) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	return timestamp, Sign([]byte(testSecret), timestamp, []byte(body))
+}
+
+func trigger(h http.Handler, body, timestamp, signature string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, TriggerPath, strings.NewReader(body))
+	if signature != "" {
+		req.Header.Set(SignatureHeader, signature)
+	}
+	if timestamp != "" {
+		req.Header.Set(TimestampHeader, timestamp)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestTriggerStartsTemplate(t *testing.T) {
+	started := make(chan Template, 1)
+	h := newTestHandler(started)
+
+	body := `{"template": "nightly"}`
+	timestamp, signature := signed(body)
+	rec := trigger(h, body, timestamp, signature)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"status":"accepted"`)
+	assert.Contains(t, rec.Body.String(), `"run_id":"run-1"`)
+
+	tmpl := <-started
+	assert.Equal(t, "nightly", tmpl.Name)
+	assert.Equal(t, "mistral-7b", tmpl.Models[0].Name)
+}
+
+func TestTriggerOverridesModels(t *testing.T) {
+	started := make(chan Template, 1)
+	h := newTestHandler(started)
+
+	body := `{"template": "empty", "models": [{"name": "llama", "model_uri": "hf://meta-llama/Llama-3.1-8B@abc"}]}`
+	timestamp, signature := signed(body)
+	rec := trigger(h, body, timestamp, signature)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+
+	tmpl := <-started
+	require.Len(t, tmpl.Models, 1)
+	assert.Equal(t, "hf://meta-llama/Llama-3.1-8B@abc", tmpl.Models[0].ModelURI)
+}
+
+func TestTriggerRejectsInvalidRequests(t *testing.T) {
+	h := newTestHandler(make(chan Template, 1))
+
+	body := `{"template": "nightly"}`
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	stale := strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10)
+	sign := func(timestamp, body string) string { return Sign([]byte(testSecret), timestamp, []byte(body)) }
+	tests := []struct {
+		name      string
+		body      string
+		timestamp string
+		signature string
+		status    int
+	}{
+		{"missing signature", body, now, "", http.StatusUnauthorized},
+		{"missing timestamp", body, "", sign("", body), http.StatusUnauthorized},
+		{"wrong secret", body, now, Sign([]byte("other"), now, []byte(body)), http.StatusUnauthorized},
+		{"tampered body", `{"template": "empty"}`, now, sign(now, body), http.StatusUnauthorized},
+		{"tampered timestamp", body, now, sign(stale, body), http.StatusUnauthorized},
+		{"stale timestamp", body, stale, sign(stale, body), http.StatusUnauthorized},
+		{"malformed signature", body, now, "sha256=zz", http.StatusUnauthorized},
+		{"unknown template", `{"template": "x"}`, now, sign(now, `{"template": "x"}`), http.StatusNotFound},
+		{"no models", `{"template": "empty"}`, now, sign(now, `{"template": "empty"}`), http.StatusBadRequest},
+		{"invalid JSON", `{`, now, sign(now, `{`), http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := trigger(h, tt.body, tt.timestamp, tt.signature)
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+}
+
+func TestTriggerRejectsReplays(t *testing.T) {
+	started := make(chan Template, 2)
+	h := newTestHandler(started)
+
+	body := `{"template": "nightly"}`
+	timestamp, signature := signed(body)
+	require.Equal(t, http.StatusAccepted, trigger(h, body, timestamp, signature).Code)
+	assert.Equal(t, http.StatusConflict, trigger(h, body, timestamp, signature).Code)
+	assert.Equal(t, http.StatusConflict, trigger(h, body, timestamp, "sha256="+strings.ToUpper(strings.TrimPrefix(signature, "sha256="))).Code, "re-cased signature")
+	assert.Len(t, started, 1)
+}
+
+func TestTriggerReportsStartFailures(t *testing.T) {
+	templates := map[string]Template{"nightly": {Name: "nightly", Suite: "s", Models: []testsuite.Model{{Name: "m"}}}}
+	var startErr error
+	h := NewHandler(testSecret, templates, func(context.Context, Template) (string, error) {
+		return "", startErr
+	})
+
+	body := `{"template": "nightly"}`
+	startErr = fmt.Errorf("%w: the server is shutting down", ErrUnavailable)
+	timestamp, signature := signed(body)
+	assert.Equal(t, http.StatusServiceUnavailable, trigger(h, body, timestamp, signature).Code)
+
+	// A trigger whose run failed to start may be retried.
+	startErr = nil
+	assert.Equal(t, http.StatusAccepted, trigger(h, body, timestamp, signature).Code)
+	assert.Equal(t, http.StatusConflict, trigger(h, body, timestamp, signature).Code)
+
+	startErr = errors.New("test suite not found")
+	timestamp, signature = signed(body + " ")
+	rec := trigger(h, body+" ", timestamp, signature)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Contains(t, rec.Body.String(), "test suite not found")
+}
+
+func TestTriggerRequiresPost(t *testing.T) {
+	h := newTestHandler(make(chan Template, 1))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, TriggerPath, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestVerifySignatureEmptySecret(t *testing.T) {
+	body := []byte("{}")
+	assert.False(t, VerifySignature(nil, "1700000000", body, Sign(nil, "1700000000", body)))
+}
+
+func TestLoadTemplates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "templates.yaml")
+	content := `templates:
+  nightly-cka:
+    test_suite: kubernetes-cka-v2
+    judge: true
+    deploy: false
+    models:
+      - name: mistral-7b
+        model_uri: hf://mistralai/Mistral-7B-Instruct-v0.3
+        gpu_count: 2
+        node_selector:
+          nvidia.com/gpu.product: NVIDIA-L4
+        tolerations:
+          - key: nvidia.com/gpu
+            operator: Exists
+            effect: NoSchedule
+        affinity:
+          nodeAffinity:
+            requiredDuringSchedulingIgnoredDuringExecution:
+              nodeSelectorTerms:
+                - matchExpressions:
+                    - key: topology.kubernetes.io/zone
+                      operator: In
+                      values: [eu-west-1a]
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	templates, err := LoadTemplates(path)
+	require.NoError(t, err)
+
+	tmpl, ok := templates["nightly-cka"]
+	require.True(t, ok)
+	assert.Equal(t, "nightly-cka", tmpl.Name)
+	assert.Equal(t, "kubernetes-cka-v2", tmpl.Suite)
+	assert.True(t, tmpl.Judge)
+	require.NotNil(t, tmpl.Deploy)
+	assert.False(t, *tmpl.Deploy)
+	require.Len(t, tmpl.Models, 1)
+	assert.Equal(t, "hf://mistralai/Mistral-7B-Instruct-v0.3", tmpl.Models[0].ModelURI)
+	assert.Equal(t, 2, tmpl.Models[0].GPUCount)
+	assert.Equal(t, map[string]string{"nvidia.com/gpu.product": "NVIDIA-L4"}, tmpl.Models[0].NodeSelector)
+	require.Len(t, tmpl.Models[0].Tolerations, 1)
+	assert.Equal(t, "nvidia.com/gpu", tmpl.Models[0].Tolerations[0].Key)
+	assert.Equal(t, corev1.TaintEffectNoSchedule, tmpl.Models[0].Tolerations[0].Effect)
+	require.NotNil(t, tmpl.Models[0].Affinity)
+	terms := tmpl.Models[0].Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	require.Len(t, terms, 1)
+	assert.Equal(t, []string{"eu-west-1a"}, terms[0].MatchExpressions[0].Values)
+}
+
+func TestLoadTemplatesRequiresSuite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "templates.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("templates:\n  broken:\n    judge: true\n"), 0o644))
+
+	_, err := LoadTemplates(path)
+	assert.ErrorContains(t, err, "no test_suite")
+}